@@ -0,0 +1,224 @@
+// Command migrate-storage copies files from the local uploads directory into the
+// configured S3-compatible bucket and rewrites the file URLs stored in MongoDB.
+//
+// Usage:
+//
+//	STORAGE_TYPE=s3 STORAGE_S3_BUCKET=animalsys go run ./cmd/migrate-storage -dry-run
+//	STORAGE_TYPE=s3 STORAGE_S3_BUCKET=animalsys go run ./cmd/migrate-storage
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/config"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func main() {
+	fromPath := flag.String("from-path", "", "local uploads directory (defaults to STORAGE_LOCAL_PATH)")
+	fromURL := flag.String("from-url", "/uploads", "URL prefix the local files were served from")
+	dryRun := flag.Bool("dry-run", false, "report what would change without uploading or updating records")
+	skipFiles := flag.Bool("skip-files", false, "only rewrite stored URLs (files were copied already)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+	if cfg.Storage.Type != "s3" {
+		log.Fatal("STORAGE_TYPE must be set to s3 to migrate uploads")
+	}
+	if *fromPath == "" {
+		*fromPath = cfg.Storage.LocalPath
+	}
+
+	ctx := context.Background()
+
+	target, err := storage.NewS3Backend(ctx, storage.S3Options{
+		Endpoint:       cfg.Storage.S3Endpoint,
+		Region:         cfg.Storage.S3Region,
+		Bucket:         cfg.Storage.S3Bucket,
+		AccessKey:      cfg.Storage.S3AccessKey,
+		SecretKey:      cfg.Storage.S3SecretKey,
+		PublicPrefixes: storage.PublicFolders,
+	})
+	if err != nil {
+		log.Fatal("Failed to connect to S3 storage:", err)
+	}
+
+	db, err := mongodb.Connect(ctx, cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Disconnect(ctx)
+
+	if !*skipFiles {
+		copied, err := copyFiles(ctx, storage.NewLocalBackend(*fromPath), target, *dryRun)
+		if err != nil {
+			log.Fatal("Failed to copy files:", err)
+		}
+		log.Printf("Copied %d files from %s to bucket %s\n", copied, *fromPath, cfg.Storage.S3Bucket)
+	}
+
+	rewriter := &urlRewriter{
+		from: strings.TrimSuffix(*fromURL, "/") + "/",
+		to:   strings.TrimSuffix(cfg.Storage.BaseURL, "/") + "/",
+	}
+
+	updated, err := rewriter.rewriteDatabase(ctx, db, *dryRun)
+	if err != nil {
+		log.Fatal("Failed to rewrite file URLs:", err)
+	}
+	log.Printf("Rewrote file URLs in %d records (%s -> %s)\n", updated, rewriter.from, rewriter.to)
+
+	if *dryRun {
+		log.Println("Dry run, nothing was changed")
+	}
+}
+
+// copyFiles uploads every local file to the target bucket, keeping the relative path as the key
+func copyFiles(ctx context.Context, source *storage.LocalBackend, target storage.Backend, dryRun bool) (int, error) {
+	copied := 0
+
+	err := source.Walk(func(key string, info os.FileInfo) error {
+		if dryRun {
+			log.Println("would copy", key)
+			copied++
+			return nil
+		}
+
+		file, _, err := source.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		contentType := mime.TypeByExtension(filepath.Ext(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		if err := target.Put(ctx, key, file, info.Size(), contentType); err != nil {
+			return err
+		}
+
+		copied++
+		return nil
+	})
+
+	return copied, err
+}
+
+// urlRewriter replaces the local URL prefix in stored string values
+type urlRewriter struct {
+	from string
+	to   string
+}
+
+// rewriteDatabase walks every collection except the audit log and updates records holding local file URLs
+func (r *urlRewriter) rewriteDatabase(ctx context.Context, db *mongodb.Database, dryRun bool) (int, error) {
+	names, err := db.DB.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, name := range names {
+		if name == mongodb.Collections.AuditLogs || strings.HasPrefix(name, "system.") {
+			continue
+		}
+
+		collection := db.Collection(name)
+		cursor, err := collection.Find(ctx, bson.M{})
+		if err != nil {
+			return updated, err
+		}
+
+		for cursor.Next(ctx) {
+			var doc bson.D
+			if err := cursor.Decode(&doc); err != nil {
+				cursor.Close(ctx)
+				return updated, err
+			}
+
+			rewritten, changed := r.rewrite(doc)
+			if !changed {
+				continue
+			}
+
+			updated++
+			if dryRun {
+				log.Printf("would update %s %v\n", name, documentID(doc))
+				continue
+			}
+
+			if _, err := collection.ReplaceOne(ctx, bson.M{"_id": documentID(doc)}, rewritten); err != nil {
+				cursor.Close(ctx)
+				return updated, err
+			}
+		}
+
+		if err := cursor.Err(); err != nil {
+			cursor.Close(ctx)
+			return updated, err
+		}
+		cursor.Close(ctx)
+	}
+
+	return updated, nil
+}
+
+// rewrite returns a copy of the value with every local URL replaced
+func (r *urlRewriter) rewrite(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, r.from) {
+			return r.to + strings.TrimPrefix(v, r.from), true
+		}
+		return v, false
+	case bson.D:
+		out := make(bson.D, len(v))
+		changed := false
+		for i, elem := range v {
+			rewritten, c := r.rewrite(elem.Value)
+			out[i] = bson.E{Key: elem.Key, Value: rewritten}
+			changed = changed || c
+		}
+		return out, changed
+	case primitive.A:
+		out := make(primitive.A, len(v))
+		changed := false
+		for i, item := range v {
+			rewritten, c := r.rewrite(item)
+			out[i] = rewritten
+			changed = changed || c
+		}
+		return out, changed
+	default:
+		return v, false
+	}
+}
+
+// documentID returns the _id value of a decoded record
+func documentID(doc bson.D) interface{} {
+	for _, elem := range doc {
+		if elem.Key == "_id" {
+			return elem.Value
+		}
+	}
+	return nil
+}
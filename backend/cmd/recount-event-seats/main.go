@@ -0,0 +1,160 @@
+// Command recount-event-seats recomputes the seat and waitlist counters of events from
+// their registrations. Registrations used to take one seat each; they now take one seat
+// for the attendee plus one per guest, so counters of events created before that change
+// undercount the seats taken.
+//
+// Usage:
+//
+//	go run ./cmd/recount-event-seats -dry-run
+//	go run ./cmd/recount-event-seats
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/config"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// counts holds the seats taken and the registrations waiting for a seat on an event
+type counts struct {
+	Seats      int
+	Waitlisted int
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without updating events")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	ctx := context.Background()
+
+	db, err := mongodb.Connect(ctx, cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Disconnect(ctx)
+
+	byEvent, err := countRegistrations(ctx, db)
+	if err != nil {
+		log.Fatal("Failed to count registrations:", err)
+	}
+
+	updated, err := updateEvents(ctx, db, byEvent, *dryRun)
+	if err != nil {
+		log.Fatal("Failed to update events:", err)
+	}
+	log.Printf("Recounted the seats of %d events\n", updated)
+
+	if *dryRun {
+		log.Println("Dry run, nothing was changed")
+	}
+}
+
+// countRegistrations sums the seats of the registrations holding a place and counts the
+// waitlisted ones, per event
+func countRegistrations(ctx context.Context, db *mongodb.Database) (map[primitive.ObjectID]counts, error) {
+	holding := bson.A{
+		entities.AttendanceStatusRegistered,
+		entities.AttendanceStatusConfirmed,
+		entities.AttendanceStatusAttended,
+		entities.AttendanceStatusNoShow,
+	}
+	pipeline := bson.A{
+		bson.M{"$group": bson.M{
+			"_id": "$event_id",
+			"seats": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$status", holding}},
+				bson.M{"$add": bson.A{1, bson.M{"$ifNull": bson.A{"$number_of_guests", 0}}}},
+				0,
+			}}},
+			"waitlisted": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$status", entities.AttendanceStatusWaitlisted}},
+				1,
+				0,
+			}}},
+		}},
+	}
+
+	cursor, err := db.Collection(mongodb.Collections.EventAttendances).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	byEvent := make(map[primitive.ObjectID]counts)
+	for cursor.Next(ctx) {
+		var row struct {
+			EventID    primitive.ObjectID `bson:"_id"`
+			Seats      int                `bson:"seats"`
+			Waitlisted int                `bson:"waitlisted"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		byEvent[row.EventID] = counts{Seats: row.Seats, Waitlisted: row.Waitlisted}
+	}
+	return byEvent, cursor.Err()
+}
+
+// updateEvents sets the counters of every event whose stored ones differ from the recount
+func updateEvents(ctx context.Context, db *mongodb.Database, byEvent map[primitive.ObjectID]counts, dryRun bool) (int, error) {
+	collection := db.Collection(mongodb.Collections.Events)
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var event struct {
+			ID           primitive.ObjectID `bson:"_id"`
+			Registration struct {
+				CurrentCount  int `bson:"current_count"`
+				WaitlistCount int `bson:"waitlist_count"`
+			} `bson:"registration"`
+		}
+		if err := cursor.Decode(&event); err != nil {
+			return updated, err
+		}
+
+		recount := byEvent[event.ID]
+		if recount.Seats == event.Registration.CurrentCount && recount.Waitlisted == event.Registration.WaitlistCount {
+			continue
+		}
+
+		updated++
+		if dryRun {
+			log.Printf("would set event %s to %d seats (was %d) and %d waitlisted (was %d)\n",
+				event.ID.Hex(), recount.Seats, event.Registration.CurrentCount, recount.Waitlisted, event.Registration.WaitlistCount)
+			continue
+		}
+
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": event.ID}, bson.M{"$set": bson.M{
+			"registration.current_count":  recount.Seats,
+			"registration.waitlist_count": recount.Waitlisted,
+			"updated_at":                  time.Now(),
+		}})
+		if err != nil {
+			return updated, err
+		}
+	}
+
+	return updated, cursor.Err()
+}
@@ -19,31 +19,48 @@ import (
 	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
 	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb/repositories"
 	"github.com/sainaif/animalsys/backend/internal/infrastructure/logger"
+	adopterUC "github.com/sainaif/animalsys/backend/internal/usecase/adopter"
 	adoptionUC "github.com/sainaif/animalsys/backend/internal/usecase/adoption"
 	animalUC "github.com/sainaif/animalsys/backend/internal/usecase/animal"
+	appointmentUC "github.com/sainaif/animalsys/backend/internal/usecase/appointment"
+	billingUC "github.com/sainaif/animalsys/backend/internal/usecase/billing"
 	auditlogUC "github.com/sainaif/animalsys/backend/internal/usecase/auditlog"
 	authUC "github.com/sainaif/animalsys/backend/internal/usecase/auth"
 	campaignUC "github.com/sainaif/animalsys/backend/internal/usecase/campaign"
 	communicationUC "github.com/sainaif/animalsys/backend/internal/usecase/communication"
 	contactUC "github.com/sainaif/animalsys/backend/internal/usecase/contact"
+	contractUC "github.com/sainaif/animalsys/backend/internal/usecase/contract"
 	dashboardUC "github.com/sainaif/animalsys/backend/internal/usecase/dashboard"
 	documentUC "github.com/sainaif/animalsys/backend/internal/usecase/document"
 	donationUC "github.com/sainaif/animalsys/backend/internal/usecase/donation"
 	donorUC "github.com/sainaif/animalsys/backend/internal/usecase/donor"
 	eventUC "github.com/sainaif/animalsys/backend/internal/usecase/event"
+	followupUC "github.com/sainaif/animalsys/backend/internal/usecase/followup"
 	inventoryUC "github.com/sainaif/animalsys/backend/internal/usecase/inventory"
+	labUC "github.com/sainaif/animalsys/backend/internal/usecase/lab"
 	medicalUC "github.com/sainaif/animalsys/backend/internal/usecase/medical"
 	monitoringUC "github.com/sainaif/animalsys/backend/internal/usecase/monitoring"
 	notificationUC "github.com/sainaif/animalsys/backend/internal/usecase/notification"
+	outcomeUC "github.com/sainaif/animalsys/backend/internal/usecase/outcome"
+	packetUC "github.com/sainaif/animalsys/backend/internal/usecase/packet"
+	quarantineUC "github.com/sainaif/animalsys/backend/internal/usecase/quarantine"
+	sterilizationUC "github.com/sainaif/animalsys/backend/internal/usecase/sterilization"
 	partnerUC "github.com/sainaif/animalsys/backend/internal/usecase/partner"
 	reportUC "github.com/sainaif/animalsys/backend/internal/usecase/report"
+	searchUC "github.com/sainaif/animalsys/backend/internal/usecase/search"
 	settingsUC "github.com/sainaif/animalsys/backend/internal/usecase/settings"
 	stockUC "github.com/sainaif/animalsys/backend/internal/usecase/stock"
 	taskUC "github.com/sainaif/animalsys/backend/internal/usecase/task"
+	ticketingUC "github.com/sainaif/animalsys/backend/internal/usecase/ticketing"
 	transferUC "github.com/sainaif/animalsys/backend/internal/usecase/transfer"
 	userUC "github.com/sainaif/animalsys/backend/internal/usecase/user"
 	veterinaryUC "github.com/sainaif/animalsys/backend/internal/usecase/veterinary"
+	vitalsUC "github.com/sainaif/animalsys/backend/internal/usecase/vitals"
 	volunteerUC "github.com/sainaif/animalsys/backend/internal/usecase/volunteer"
+	"github.com/sainaif/animalsys/backend/pkg/microchip"
+	"github.com/sainaif/animalsys/backend/pkg/payment"
+	"github.com/sainaif/animalsys/backend/pkg/scanner"
+	"github.com/sainaif/animalsys/backend/pkg/scheduler"
 	"github.com/sainaif/animalsys/backend/pkg/security"
 	"github.com/sainaif/animalsys/backend/pkg/storage"
 )
@@ -95,6 +112,7 @@ func main() {
 	volunteerRepo := repositories.NewVolunteerRepository(db)
 	contactRepo := repositories.NewContactRepository(db)
 	eventAttendanceRepo := repositories.NewEventAttendanceRepository(db)
+	eventSeriesRepo := repositories.NewEventSeriesRepository(db)
 	volunteerAssignmentRepo := repositories.NewVolunteerAssignmentRepository(db)
 	communicationTemplateRepo := repositories.NewCommunicationTemplateRepository(db)
 	communicationRepo := repositories.NewCommunicationRepository(db)
@@ -111,6 +129,21 @@ func main() {
 	medicalConditionRepo := repositories.NewMedicalConditionRepository(db)
 	medicationRepo := repositories.NewMedicationRepository(db)
 	treatmentPlanRepo := repositories.NewTreatmentPlanRepository(db)
+	medicationDoseRepo := repositories.NewMedicationDoseRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	vaccinationProtocolRepo := repositories.NewVaccinationProtocolRepository(db)
+	vaccinationScheduleRepo := repositories.NewVaccinationScheduleRepository(db)
+	vitalSignRepo := repositories.NewVitalSignRepository(db)
+	labAnalyteRepo := repositories.NewLabAnalyteRepository(db)
+	labPanelRepo := repositories.NewLabPanelRepository(db)
+	clinicScheduleRepo := repositories.NewClinicScheduleRepository(db)
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(db)
+	clinicInvoiceRepo := repositories.NewClinicInvoiceRepository(db)
+	quarantineRepo := repositories.NewQuarantineRepository(db)
+	outbreakRepo := repositories.NewOutbreakRepository(db)
+	contractTemplateRepo := repositories.NewContractTemplateRepository(db)
+	contractRepo := repositories.NewContractRepository(db)
+	adopterRepo := repositories.NewAdopterRepository(db)
 
 	// Ensure database indexes
 	if err := userRepo.EnsureIndexes(ctx); err != nil {
@@ -155,6 +188,9 @@ func main() {
 	if err := eventAttendanceRepo.EnsureIndexes(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to create event attendance indexes")
 	}
+	if err := eventSeriesRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create event series indexes")
+	}
 	if err := volunteerAssignmentRepo.EnsureIndexes(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to create volunteer assignment indexes")
 	}
@@ -200,9 +236,54 @@ func main() {
 	if err := medicationRepo.EnsureIndexes(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to create medication indexes")
 	}
+	if err := medicationDoseRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create medication dose indexes")
+	}
 	if err := treatmentPlanRepo.EnsureIndexes(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to create treatment plan indexes")
 	}
+	if err := searchRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create search indexes")
+	}
+	if err := vaccinationProtocolRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create vaccination protocol indexes")
+	}
+	if err := vaccinationScheduleRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create vaccination schedule indexes")
+	}
+	if err := vitalSignRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create vital sign indexes")
+	}
+	if err := labAnalyteRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create lab analyte indexes")
+	}
+	if err := labPanelRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create lab panel indexes")
+	}
+	if err := clinicScheduleRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create clinic schedule indexes")
+	}
+	if err := calendarFeedRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create calendar feed indexes")
+	}
+	if err := clinicInvoiceRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create clinic invoice indexes")
+	}
+	if err := quarantineRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create quarantine indexes")
+	}
+	if err := outbreakRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create outbreak indexes")
+	}
+	if err := contractTemplateRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create contract template indexes")
+	}
+	if err := contractRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create contract indexes")
+	}
+	if err := adopterRepo.EnsureIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to create adopter indexes")
+	}
 
 	// Initialize security services
 	jwtService := security.NewJWTService(
@@ -212,13 +293,99 @@ func main() {
 	)
 	passwordService := security.NewPasswordService()
 
-	// Initialize storage service
+	// Initialize storage backend and service
+	var storageBackend storage.Backend
+	switch cfg.Storage.Type {
+	case "s3":
+		s3Backend, err := storage.NewS3Backend(ctx, storage.S3Options{
+			Endpoint:       cfg.Storage.S3Endpoint,
+			Region:         cfg.Storage.S3Region,
+			Bucket:         cfg.Storage.S3Bucket,
+			AccessKey:      cfg.Storage.S3AccessKey,
+			SecretKey:      cfg.Storage.S3SecretKey,
+			PublicPrefixes: storage.PublicFolders,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect to S3 storage")
+		}
+		storageBackend = s3Backend
+	case "local":
+		storageBackend = storage.NewLocalBackend(cfg.Storage.LocalPath)
+	default:
+		log.Warn().
+			Str("type", cfg.Storage.Type).
+			Msg("Unknown storage type, falling back to local storage")
+		storageBackend = storage.NewLocalBackend(cfg.Storage.LocalPath)
+	}
+
 	storageService := storage.NewStorageService(
-		cfg.Storage.LocalPath,
+		storageBackend,
 		cfg.Storage.BaseURL,
 		cfg.Storage.MaxFileSize,
 	)
 
+	// Initialize malware scanner
+	var malwareScanner scanner.Scanner
+	switch cfg.Scanner.Type {
+	case "clamav":
+		malwareScanner = scanner.NewClamAVScanner(cfg.Scanner.ClamAVAddress, cfg.Scanner.Timeout)
+	case "fake":
+		if cfg.Environment == "production" {
+			log.Fatal().Msg("The fake malware scanner cannot be used in production; set MALWARE_SCANNER=clamav")
+		}
+		malwareScanner = scanner.NewFakeScanner()
+	default:
+		if cfg.Environment == "production" {
+			log.Fatal().Str("scanner", cfg.Scanner.Type).Msg("Unknown malware scanner")
+		}
+		log.Warn().
+			Str("scanner", cfg.Scanner.Type).
+			Msg("Unknown malware scanner, falling back to fake scanner")
+		malwareScanner = scanner.NewFakeScanner()
+	}
+
+	// Initialize microchip registry client
+	var chipRegistry microchip.Registry
+	switch cfg.Microchip.Registry {
+	case "local":
+		if cfg.Environment == "production" {
+			log.Fatal().Msg("The in-memory microchip registry cannot be used in production; set MICROCHIP_REGISTRY=http and the registry API")
+		}
+		chipRegistry = microchip.NewLocalRegistry()
+	case "http":
+		if cfg.Microchip.APIURL == "" || cfg.Microchip.APIKey == "" {
+			log.Fatal().Msg("MICROCHIP_REGISTRY_API_URL and MICROCHIP_REGISTRY_API_KEY are required for the http microchip registry")
+		}
+		chipRegistry = microchip.NewHTTPRegistry(cfg.Microchip.Name, cfg.Microchip.APIURL, cfg.Microchip.APIKey)
+	default:
+		log.Fatal().Str("registry", cfg.Microchip.Registry).Msg("Unknown microchip registry")
+	}
+
+	// Initialize payment gateway
+	var paymentGateway payment.Gateway
+	switch cfg.Payment.Provider {
+	case "stripe":
+		if cfg.Payment.SecretKey == "" {
+			log.Warn().Msg("PAYMENT_SECRET_KEY is not set, falling back to fake payment gateway")
+			paymentGateway = payment.NewFakeGateway()
+			break
+		}
+		if cfg.Payment.WebhookSecret == "" {
+			log.Fatal().Msg("PAYMENT_WEBHOOK_SECRET must be set to verify Stripe webhooks")
+		}
+		paymentGateway = payment.NewStripeGateway(cfg.Payment.SecretKey, cfg.Payment.WebhookSecret)
+	case "fake":
+		if cfg.Environment == "production" {
+			log.Warn().Msg("Using the fake payment gateway in production, tickets are not charged")
+		}
+		paymentGateway = payment.NewFakeGateway()
+	default:
+		log.Warn().
+			Str("provider", cfg.Payment.Provider).
+			Msg("Unknown payment provider, falling back to fake gateway")
+		paymentGateway = payment.NewFakeGateway()
+	}
+
 	// Initialize use cases
 	authUseCase := authUC.NewAuthUseCase(
 		userRepo,
@@ -231,22 +398,122 @@ func main() {
 		auditLogRepo,
 		passwordService,
 	)
-	animalUseCase := animalUC.NewAnimalUseCase(
+	notificationUseCase := notificationUC.NewNotificationUseCase(
+		notificationRepo,
+		auditLogRepo,
+	)
+	vitalsUseCase := vitalsUC.NewVitalsUseCase(
+		vitalSignRepo,
 		animalRepo,
+		taskRepo,
+		userRepo,
+		veterinaryVisitRepo,
+		volunteerRepo,
+		volunteerAssignmentRepo,
+		auditLogRepo,
+		notificationUseCase,
+		cfg.Vitals.WeightLossAlertPercent,
+		cfg.Vitals.WeightLossWindow,
+	)
+	inventoryUseCase := inventoryUC.NewInventoryUseCase(
+		inventoryRepo,
+		stockTransactionRepo,
 		auditLogRepo,
-		storageService,
 	)
 	veterinaryUseCase := veterinaryUC.NewVeterinaryUseCase(
 		veterinaryVisitRepo,
 		vaccinationRepo,
 		animalRepo,
 		auditLogRepo,
+		vaccinationProtocolRepo,
+		vaccinationScheduleRepo,
+		inventoryUseCase,
+		vitalsUseCase,
+	)
+	if err := veterinaryUseCase.EnsureDefaultProtocols(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to install default vaccination protocols")
+	}
+	labUseCase := labUC.NewLabUseCase(
+		labPanelRepo,
+		labAnalyteRepo,
+		animalRepo,
+		veterinaryVisitRepo,
+		documentRepo,
+		partnerRepo,
+		auditLogRepo,
+	)
+	if err := labUseCase.EnsureDefaultAnalytes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to install default lab analytes")
+	}
+	appointmentUseCase := appointmentUC.NewAppointmentUseCase(
+		veterinaryVisitRepo,
+		clinicScheduleRepo,
+		calendarFeedRepo,
+		partnerRepo,
+		animalRepo,
+		taskRepo,
+		auditLogRepo,
+	)
+	billingUseCase := billingUC.NewBillingUseCase(
+		clinicInvoiceRepo,
+		partnerRepo,
+		veterinaryVisitRepo,
+		donationRepo,
+		animalRepo,
+		auditLogRepo,
+	)
+	quarantineUseCase := quarantineUC.NewQuarantineUseCase(
+		quarantineRepo,
+		outbreakRepo,
+		animalRepo,
+		taskRepo,
+		auditLogRepo,
+	)
+	packetUseCase := packetUC.NewPacketUseCase(
+		animalRepo,
+		veterinaryVisitRepo,
+		vaccinationRepo,
+		medicalConditionRepo,
+		medicationRepo,
+		treatmentPlanRepo,
+		documentRepo,
+		settingsRepo,
+		auditLogRepo,
+		storageService,
+	)
+	animalUseCase := animalUC.NewAnimalUseCase(
+		animalRepo,
+		auditLogRepo,
+		storageService,
+		chipRegistry,
+		veterinaryUseCase,
+		vitalsUseCase,
+		quarantineUseCase,
+	)
+	adopterUseCase := adopterUC.NewAdopterUseCase(
+		adopterRepo,
+		adoptionApplicationRepo,
+		adoptionRepo,
+		contactRepo,
+		auditLogRepo,
+	)
+	communicationUseCase := communicationUC.NewCommunicationUseCase(
+		communicationRepo,
+		communicationTemplateRepo,
+		auditLogRepo,
 	)
 	adoptionUseCase := adoptionUC.NewAdoptionUseCase(
 		adoptionApplicationRepo,
 		adoptionRepo,
 		animalRepo,
 		auditLogRepo,
+		animalUseCase,
+		packetUseCase,
+		adopterUseCase,
+		settingsRepo,
+		taskRepo,
+		communicationUseCase,
+		quarantineUseCase,
 	)
 	donorUseCase := donorUC.NewDonorUseCase(
 		donorRepo,
@@ -269,21 +536,81 @@ func main() {
 		eventAttendanceRepo,
 		volunteerRepo,
 		auditLogRepo,
+		animalRepo,
+		quarantineUseCase,
+		communicationUseCase,
+		storageService,
+		cfg.Events.ReminderOffsets,
 	)
+	eventSeriesUseCase := eventUC.NewSeriesUseCase(eventUseCase, eventSeriesRepo, cfg.Events.SeriesHorizon)
 	volunteerUseCase := volunteerUC.NewVolunteerUseCase(
 		volunteerRepo,
 		volunteerAssignmentRepo,
 		auditLogRepo,
 	)
 	contactUseCase := contactUC.NewUseCase(contactRepo)
-	communicationUseCase := communicationUC.NewCommunicationUseCase(
-		communicationRepo,
+	sterilizationUseCase := sterilizationUC.NewSterilizationUseCase(
+		adoptionRepo,
+		adoptionApplicationRepo,
+		animalRepo,
+		veterinaryVisitRepo,
+		documentRepo,
+		taskRepo,
+		auditLogRepo,
+		communicationUseCase,
+	)
+	contractUseCase := contractUC.NewContractUseCase(
+		contractRepo,
+		contractTemplateRepo,
+		adoptionRepo,
+		adoptionApplicationRepo,
+		animalRepo,
+		documentRepo,
+		settingsRepo,
+		auditLogRepo,
+		storageService,
+		communicationUseCase,
+		strings.TrimRight(cfg.Server.PublicURL, "/")+"/contracts/sign",
+	)
+	followUpUseCase := followupUC.NewFollowUpUseCase(
+		adoptionRepo,
+		adoptionApplicationRepo,
+		animalRepo,
 		communicationTemplateRepo,
+		settingsRepo,
+		taskRepo,
 		auditLogRepo,
+		storageService,
+		cfg.Storage.PresignExpiry,
+		communicationUseCase,
+		strings.TrimRight(cfg.Server.PublicURL, "/")+"/follow-ups",
 	)
-	notificationUseCase := notificationUC.NewNotificationUseCase(
-		notificationRepo,
+	ticketUseCase := ticketingUC.NewTicketUseCase(
+		eventRepo,
+		eventAttendanceRepo,
+		auditLogRepo,
+		storageService,
+		eventUseCase,
+		paymentGateway,
+		security.NewTicketSigner(cfg.Tickets.SigningKey),
+		communicationUseCase,
+		strings.TrimRight(cfg.Server.PublicURL, "/")+"/tickets",
+		cfg.Payment.Currency,
+		cfg.Tickets.PaymentWindow,
+	)
+	eventOutcomeUseCase := outcomeUC.NewOutcomeUseCase(
+		eventRepo,
+		eventAttendanceRepo,
+		donationRepo,
+		adoptionRepo,
+		animalRepo,
+		volunteerAssignmentRepo,
+		volunteerRepo,
 		auditLogRepo,
+		communicationUseCase,
+		strings.TrimRight(cfg.Server.PublicURL, "/")+"/event-feedback",
+		cfg.Events.FeedbackDelay,
+		cfg.Events.FeedbackLinkValidity,
 	)
 	reportUseCase := reportUC.NewReportUseCase(
 		reportRepo,
@@ -307,6 +634,10 @@ func main() {
 	documentUseCase := documentUC.NewDocumentUseCase(
 		documentRepo,
 		auditLogRepo,
+		settingsRepo,
+		storageService,
+		malwareScanner,
+		cfg.Storage.PresignExpiry,
 	)
 	partnerUseCase := partnerUC.NewPartnerUseCase(
 		partnerRepo,
@@ -317,11 +648,8 @@ func main() {
 		animalRepo,
 		partnerRepo,
 		auditLogRepo,
-	)
-	inventoryUseCase := inventoryUC.NewInventoryUseCase(
-		inventoryRepo,
-		stockTransactionRepo,
-		auditLogRepo,
+		packetUseCase,
+		quarantineUseCase,
 	)
 	stockTransactionUseCase := stockUC.NewStockTransactionUseCase(
 		stockTransactionRepo,
@@ -346,7 +674,12 @@ func main() {
 		treatmentPlanRepo,
 		animalRepo,
 		auditLogRepo,
+		medicationDoseRepo,
+		userRepo,
+		notificationUseCase,
+		inventoryUseCase,
 	)
+	searchUseCase := searchUC.NewSearchUseCase(searchRepo)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authUseCase)
@@ -379,6 +712,20 @@ func main() {
 	monitoringHandler := handlers.NewMonitoringHandler(monitoringUseCase)
 	medicalHandler := handlers.NewMedicalHandler(medicalUseCase)
 	batchHandler := handlers.NewBatchHandler()
+	searchHandler := handlers.NewSearchHandler(searchUseCase)
+	vitalsHandler := handlers.NewVitalsHandler(vitalsUseCase)
+	labHandler := handlers.NewLabHandler(labUseCase)
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentUseCase)
+	billingHandler := handlers.NewBillingHandler(billingUseCase)
+	packetHandler := handlers.NewPacketHandler(packetUseCase)
+	quarantineHandler := handlers.NewQuarantineHandler(quarantineUseCase)
+	sterilizationHandler := handlers.NewSterilizationHandler(sterilizationUseCase)
+	contractHandler := handlers.NewContractHandler(contractUseCase)
+	adopterHandler := handlers.NewAdopterHandler(adopterUseCase)
+	followUpHandler := handlers.NewFollowUpHandler(followUpUseCase)
+	ticketHandler := handlers.NewTicketHandler(ticketUseCase)
+	eventSeriesHandler := handlers.NewEventSeriesHandler(eventSeriesUseCase)
+	eventOutcomeHandler := handlers.NewEventOutcomeHandler(eventOutcomeUseCase)
 
 	// Set Gin mode
 	if cfg.Environment == "production" {
@@ -397,7 +744,7 @@ func main() {
 	router.GET("/health", healthCheckHandler(db))
 
 	// Setup API routes
-	routes.SetupRoutes(router, authHandler, userHandler, animalHandler, veterinaryHandler, adoptionHandler, donorHandler, donationHandler, campaignHandler, eventHandler, volunteerHandler, contactHandler, communicationHandler, notificationHandler, reportHandler, dashboardHandler, settingsHandler, taskHandler, documentHandler, partnerHandler, transferHandler, inventoryHandler, stockTransactionHandler, auditLogHandler, monitoringHandler, medicalHandler, batchHandler, jwtService, userRepo)
+	routes.SetupRoutes(router, authHandler, userHandler, animalHandler, veterinaryHandler, adoptionHandler, donorHandler, donationHandler, campaignHandler, eventHandler, volunteerHandler, contactHandler, communicationHandler, notificationHandler, reportHandler, dashboardHandler, settingsHandler, taskHandler, documentHandler, partnerHandler, transferHandler, inventoryHandler, stockTransactionHandler, auditLogHandler, monitoringHandler, medicalHandler, batchHandler, searchHandler, vitalsHandler, labHandler, appointmentHandler, billingHandler, packetHandler, quarantineHandler, sterilizationHandler, contractHandler, adopterHandler, followUpHandler, ticketHandler, eventSeriesHandler, eventOutcomeHandler, jwtService, userRepo)
 
 	// Create server
 	srv := &http.Server{
@@ -408,6 +755,25 @@ func main() {
 		MaxHeaderBytes: 1 << 20, // 1 MB
 	}
 
+	// Start background jobs
+	jobs := scheduler.New(5*time.Minute, func(job string, err error) {
+		log.Error().Err(err).Str("job", job).Msg("Background job failed")
+	})
+	jobs.Every("vaccination-compliance", cfg.Jobs.VaccinationComplianceInterval, veterinaryUseCase.RefreshVaccinationCompliance)
+	jobs.Every("medication-doses", cfg.Jobs.MedicationDoseInterval, medicalUseCase.ProcessMedicationSchedules)
+	jobs.Every("quarantine-releases", cfg.Jobs.QuarantineReleaseInterval, quarantineUseCase.ProcessDueReleases)
+	jobs.Every("sterilization-compliance", cfg.Jobs.SterilizationComplianceInterval, sterilizationUseCase.ProcessCompliance)
+	jobs.Every("adoption-trial-ends", cfg.Jobs.TrialEndInterval, adoptionUseCase.ProcessTrialEnds)
+	jobs.Every("adoption-follow-ups", cfg.Jobs.FollowUpInterval, followUpUseCase.ProcessDueFollowUps)
+	jobs.Every("event-reminders", cfg.Jobs.EventReminderInterval, eventUseCase.SendDueReminders)
+	jobs.Every("event-unpaid-tickets", cfg.Jobs.UnpaidTicketInterval, ticketUseCase.ExpireUnpaidRegistrations)
+	jobs.Every("event-series", cfg.Jobs.EventSeriesInterval, eventSeriesUseCase.GenerateOccurrences)
+	jobs.Every("event-outcomes", cfg.Jobs.EventOutcomeInterval, eventOutcomeUseCase.ProcessCompletedEvents)
+	if cfg.Jobs.Enabled {
+		jobs.Start(context.Background())
+		defer jobs.Stop()
+	}
+
 	// Start server in goroutine
 	go func() {
 		log.Info().
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/adopter"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdopterHandler serves the adopter records matched from adoption applicants
+type AdopterHandler struct {
+	adopterUseCase *adopter.AdopterUseCase
+	validate       *validator.Validate
+}
+
+// NewAdopterHandler creates a new adopter handler
+func NewAdopterHandler(adopterUseCase *adopter.AdopterUseCase) *AdopterHandler {
+	return &AdopterHandler{
+		adopterUseCase: adopterUseCase,
+		validate:       validator.New(),
+	}
+}
+
+// ListAdopters lists adopters
+func (h *AdopterHandler) ListAdopters(c *gin.Context) {
+	var req adopter.ListAdoptersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adopters, total, err := h.adopterUseCase.ListAdopters(c.Request.Context(), &req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"adopters": adopters,
+		"total":    total,
+	})
+}
+
+// GetAdopter returns an adopter
+func (h *AdopterHandler) GetAdopter(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adopter ID"})
+		return
+	}
+
+	result, err := h.adopterUseCase.GetAdopter(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetHistory returns an adopter with their applications and adoptions
+func (h *AdopterHandler) GetHistory(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adopter ID"})
+		return
+	}
+
+	history, err := h.adopterUseCase.GetHistory(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// UpdateAdopter corrects an adopter's details
+func (h *AdopterHandler) UpdateAdopter(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adopter ID"})
+		return
+	}
+
+	var req adopter.UpdateAdopterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.adopterUseCase.UpdateAdopter(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SetDoNotAdopt flags a person do-not-adopt
+func (h *AdopterHandler) SetDoNotAdopt(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adopter ID"})
+		return
+	}
+
+	var req adopter.DoNotAdoptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.adopterUseCase.SetDoNotAdopt(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ClearDoNotAdopt removes the do-not-adopt flag
+func (h *AdopterHandler) ClearDoNotAdopt(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adopter ID"})
+		return
+	}
+
+	result, err := h.adopterUseCase.ClearDoNotAdopt(c.Request.Context(), id, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
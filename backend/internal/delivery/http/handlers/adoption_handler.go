@@ -202,6 +202,7 @@ func (h *AdoptionHandler) DeleteApplication(c *gin.Context) {
 // @Param status query string false "Filter by status"
 // @Param applicant_email query string false "Filter by applicant email"
 // @Param applicant_name query string false "Filter by applicant name"
+// @Param adopter_id query string false "Filter by adopter ID"
 // @Param limit query int false "Limit" default(20)
 // @Param offset query int false "Offset" default(0)
 // @Success 200 {object} map[string]interface{}
@@ -588,41 +589,41 @@ func (h *AdoptionHandler) GetAdoptionStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// GetContracts gets all adoption contracts
-func (h *AdoptionHandler) GetContracts(c *gin.Context) {
-	// Return mock contracts list
-	c.JSON(http.StatusOK, gin.H{
-		"contracts": []interface{}{},
-		"total":     0,
-	})
-}
-
-// ScheduleVisit schedules a visit for an adoption application
-func (h *AdoptionHandler) ScheduleVisit(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Visit scheduled"})
-}
-
-// RecordHomeVisit records a home visit for an adoption application
-func (h *AdoptionHandler) RecordHomeVisit(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Home visit recorded"})
-}
-
-// GetVisits gets all visits for an adoption application
-func (h *AdoptionHandler) GetVisits(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"visits": []interface{}{}, "total": 0})
-}
-
-// ApproveApplication approves an adoption application
-func (h *AdoptionHandler) ApproveApplication(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Application approved"})
-}
-
 // FinalizeAdoption finalizes an adoption
+// @Summary Finalize Adoption
+// @Description Complete a pending adoption and register the adopter with the microchip registry
+// @Tags adoptions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Adoption ID"
+// @Success 200 {object} entities.Adoption
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Router /adoptions/{id}/finalize [post]
 func (h *AdoptionHandler) FinalizeAdoption(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Adoption finalized"})
-}
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idParam := c.Param("id")
+	adoptionID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+
+	adoption, err := h.adoptionUseCase.FinalizeAdoption(c.Request.Context(), adoptionID, *userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
 
-// RejectApplication rejects an adoption application
-func (h *AdoptionHandler) RejectApplication(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Application rejected"})
+	c.JSON(http.StatusOK, adoption)
 }
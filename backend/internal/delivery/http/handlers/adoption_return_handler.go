@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/adoption"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ======================== ADOPTION RETURN HANDLERS ========================
+
+// ReturnAdoption records the return of an adopted animal
+func (h *AdoptionHandler) ReturnAdoption(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+
+	var req adoption.ReturnAdoptionRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	result, err := h.adoptionUseCase.ReturnAdoption(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetReturnPolicy returns the adoption return policy
+func (h *AdoptionHandler) GetReturnPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.adoptionUseCase.GetReturnPolicy(c.Request.Context()))
+}
+
+// UpdateReturnPolicy replaces the adoption return policy
+func (h *AdoptionHandler) UpdateReturnPolicy(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req entities.AdoptionReturnPolicy
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	policy, err := h.adoptionUseCase.UpdateReturnPolicy(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// GetReturnReport reports returns by reason and refund in a period; defaults to the
+// current year
+func (h *AdoptionHandler) GetReturnReport(c *gin.Context) {
+	var from, to *time.Time
+	if !parseDateRange(c, &from, &to) {
+		return
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := time.Date(to.Year(), time.January, 1, 0, 0, 0, 0, to.Location())
+		from = &start
+	}
+
+	report, err := h.adoptionUseCase.GetReturnReport(c.Request.Context(), *from, *to)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
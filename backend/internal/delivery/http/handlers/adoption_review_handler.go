@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/adoption"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ======================== APPLICATION REVIEW HANDLERS ========================
+
+// applicationParams returns the current user and the application ID of the request
+func applicationParams(c *gin.Context) (*primitive.ObjectID, primitive.ObjectID, bool) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application ID"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	return userID, id, true
+}
+
+// bindReviewRequest binds and validates a JSON request body
+func (h *AdoptionHandler) bindReviewRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// GetReview returns an application with its progress through the review pipeline
+func (h *AdoptionHandler) GetReview(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application ID"})
+		return
+	}
+
+	application, err := h.adoptionUseCase.GetReview(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// UpdateStage assigns a review stage, ticks its checklist and records notes
+func (h *AdoptionHandler) UpdateStage(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req adoption.UpdateStageRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	application, err := h.adoptionUseCase.UpdateStage(c.Request.Context(), id, entities.ReviewStageKey(c.Param("stage")), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// CompleteStage records the outcome of the current review stage
+func (h *AdoptionHandler) CompleteStage(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req adoption.CompleteStageRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	application, err := h.adoptionUseCase.CompleteStage(c.Request.Context(), id, entities.ReviewStageKey(c.Param("stage")), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// UpdateReference records the result of contacting a reference
+func (h *AdoptionHandler) UpdateReference(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reference index"})
+		return
+	}
+
+	var req adoption.UpdateReferenceRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	application, err := h.adoptionUseCase.UpdateReference(c.Request.Context(), id, index, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// ScheduleVisit schedules a home visit or meet and greet for an adoption application
+func (h *AdoptionHandler) ScheduleVisit(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req adoption.ScheduleVisitRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	visit, err := h.adoptionUseCase.ScheduleVisit(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, visit)
+}
+
+// RecordHomeVisit records the findings and outcome of a visit for an adoption application
+func (h *AdoptionHandler) RecordHomeVisit(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req adoption.RecordVisitRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	application, err := h.adoptionUseCase.RecordVisit(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// GetVisits gets all visits for an adoption application
+func (h *AdoptionHandler) GetVisits(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid application ID"})
+		return
+	}
+
+	visits, err := h.adoptionUseCase.GetVisits(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"visits": visits,
+		"total":  len(visits),
+	})
+}
+
+// ApproveApplication approves an adoption application that passed every review stage
+func (h *AdoptionHandler) ApproveApplication(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req adoption.ApproveApplicationRequest
+	if c.Request.ContentLength > 0 && !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	application, err := h.adoptionUseCase.ApproveApplication(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// RejectApplication rejects an adoption application and emails the reason to the applicant
+func (h *AdoptionHandler) RejectApplication(c *gin.Context) {
+	userID, id, ok := applicationParams(c)
+	if !ok {
+		return
+	}
+
+	var req adoption.RejectApplicationRequest
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	application, err := h.adoptionUseCase.RejectApplication(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// GetReviewConfig returns the review pipeline configuration
+func (h *AdoptionHandler) GetReviewConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.adoptionUseCase.GetReviewConfig(c.Request.Context()))
+}
+
+// UpdateReviewConfig replaces the review pipeline configuration
+func (h *AdoptionHandler) UpdateReviewConfig(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req entities.ApplicationReviewConfig
+	if !h.bindReviewRequest(c, &req) {
+		return
+	}
+
+	config, err := h.adoptionUseCase.UpdateReviewConfig(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// GetReviewReport reports the stage timings of applications submitted in a period; defaults
+// to the last 90 days
+func (h *AdoptionHandler) GetReviewReport(c *gin.Context) {
+	var from, to *time.Time
+	if !parseDateRange(c, &from, &to) {
+		return
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := to.AddDate(0, 0, -90)
+		from = &start
+	}
+
+	report, err := h.adoptionUseCase.GetReviewReport(c.Request.Context(), *from, *to)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
 	"github.com/sainaif/animalsys/backend/internal/usecase/animal"
 	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/microchip"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -287,9 +288,104 @@ func (h *AnimalHandler) UploadAnimalImages(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "images uploaded successfully"})
 }
 
+// ReorderAnimalImages changes the display order of an animal's images
+// @Summary Reorder Animal Images
+// @Description Set the display order of an animal's images; the first image becomes the primary image
+// @Tags animals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Animal ID"
+// @Param request body map[string][]string true "Ordered image IDs"
+// @Success 200 {object} entities.AnimalImages
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Router /animals/{id}/images/order [put]
+func (h *AnimalHandler) ReorderAnimalImages(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idParam := c.Param("id")
+	animalID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	var req struct {
+		ImageIDs []string `json:"image_ids" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := h.animalUseCase.ReorderAnimalImages(c.Request.Context(), animalID, req.ImageIDs, *userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+// DeleteAnimalImage deletes an image from an animal
+// @Summary Delete Animal Image
+// @Description Delete an image and all of its generated sizes from an animal
+// @Tags animals
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Animal ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} entities.AnimalImages
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Router /animals/{id}/images/{imageId} [delete]
+func (h *AnimalHandler) DeleteAnimalImage(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idParam := c.Param("id")
+	animalID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	images, err := h.animalUseCase.DeleteAnimalImage(c.Request.Context(), animalID, c.Param("imageId"), *userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
 // AddDailyNote adds a daily note to an animal
 // @Summary Add Daily Note
-// @Description Add a daily observation note to an animal
+// @Description Add a daily observation note to an animal, optionally with measured weight and vitals
 // @Tags animals
 // @Security BearerAuth
 // @Accept json
@@ -316,7 +412,8 @@ func (h *AnimalHandler) AddDailyNote(c *gin.Context) {
 	}
 
 	var req struct {
-		Note string `json:"note" validate:"required"`
+		Note   string               `json:"note" validate:"required"`
+		Vitals *entities.VitalSigns `json:"vitals,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -329,7 +426,7 @@ func (h *AnimalHandler) AddDailyNote(c *gin.Context) {
 		return
 	}
 
-	if err := h.animalUseCase.AddDailyNote(c.Request.Context(), animalID, req.Note, *userID); err != nil {
+	if err := h.animalUseCase.AddDailyNote(c.Request.Context(), animalID, req.Note, req.Vitals, *userID); err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
 		} else {
@@ -383,3 +480,106 @@ func (h *AnimalHandler) GetSpecies(c *gin.Context) {
 	species := h.animalUseCase.GetSpeciesByCategory(entities.AnimalCategory(category))
 	c.JSON(http.StatusOK, species)
 }
+
+// CheckMicrochip checks a microchip number at intake
+// @Summary Check Microchip
+// @Description Validate a microchip number and report animals or registry owners already linked to it
+// @Tags animals
+// @Security BearerAuth
+// @Produce json
+// @Param number path string true "Microchip number"
+// @Success 200 {object} animal.MicrochipCheckResponse
+// @Failure 401 {object} errors.AppError
+// @Router /animals/microchip/{number}/check [get]
+func (h *AnimalHandler) CheckMicrochip(c *gin.Context) {
+	response, err := h.animalUseCase.CheckMicrochip(c.Request.Context(), c.Param("number"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// LookupMicrochipOwner looks up the registered owner of a microchip
+// @Summary Lookup Microchip Owner
+// @Description Look up the owner registered for a microchip in the registry
+// @Tags animals
+// @Security BearerAuth
+// @Produce json
+// @Param number path string true "Microchip number"
+// @Success 200 {object} microchip.Registration
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Router /animals/microchip/{number}/owner [get]
+func (h *AnimalHandler) LookupMicrochipOwner(c *gin.Context) {
+	registration, err := h.animalUseCase.LookupMicrochipOwner(c.Request.Context(), c.Param("number"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, registration)
+}
+
+// RegisterMicrochipOwner registers an owner for an animal's microchip
+// @Summary Register Microchip Owner
+// @Description Register a new owner for the animal's microchip in the registry
+// @Tags animals
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Animal ID"
+// @Param request body microchip.Owner true "Owner details"
+// @Success 200 {object} entities.Animal
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Router /animals/{id}/microchip/registration [post]
+func (h *AnimalHandler) RegisterMicrochipOwner(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	idParam := c.Param("id")
+	animalID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	var req microchip.Owner
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedAnimal, err := h.animalUseCase.RegisterMicrochipOwner(c.Request.Context(), animalID, req, *userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			c.JSON(appErr.Code, gin.H{"error": appErr.Message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedAnimal)
+}
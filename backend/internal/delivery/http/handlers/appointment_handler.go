@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/appointment"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AppointmentHandler serves partner clinic schedules, appointments and calendar feeds
+type AppointmentHandler struct {
+	appointmentUseCase *appointment.AppointmentUseCase
+	validate           *validator.Validate
+}
+
+// NewAppointmentHandler creates a new appointment handler
+func NewAppointmentHandler(appointmentUseCase *appointment.AppointmentUseCase) *AppointmentHandler {
+	return &AppointmentHandler{
+		appointmentUseCase: appointmentUseCase,
+		validate:           validator.New(),
+	}
+}
+
+// GetSchedule gets the appointment schedule of a partner clinic
+func (h *AppointmentHandler) GetSchedule(c *gin.Context) {
+	partnerID, err := primitive.ObjectIDFromHex(c.Param("partnerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid partner ID"})
+		return
+	}
+
+	schedule, err := h.appointmentUseCase.GetSchedule(c.Request.Context(), partnerID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule sets the working hours, visit durations and capacity of a partner clinic
+func (h *AppointmentHandler) UpdateSchedule(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	partnerID, err := primitive.ObjectIDFromHex(c.Param("partnerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid partner ID"})
+		return
+	}
+
+	var req appointment.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.appointmentUseCase.UpdateSchedule(c.Request.Context(), partnerID, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetAvailableSlots lists the free appointment start times of a clinic on a day
+func (h *AppointmentHandler) GetAvailableSlots(c *gin.Context) {
+	partnerID, err := primitive.ObjectIDFromHex(c.Param("partnerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid partner ID"})
+		return
+	}
+
+	date := c.Query("date")
+	if date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required"})
+		return
+	}
+	visitType := entities.VisitType(c.DefaultQuery("visit_type", string(entities.VisitTypeCheckup)))
+
+	slots, err := h.appointmentUseCase.GetAvailableSlots(c.Request.Context(), partnerID, date, visitType)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// ListAppointments lists booked appointments
+func (h *AppointmentHandler) ListAppointments(c *gin.Context) {
+	req := &appointment.ListAppointmentsRequest{Limit: 100}
+
+	if !setObjectIDFilter(c, "partner_id", &req.PartnerID) ||
+		!setObjectIDFilter(c, "animal_id", &req.AnimalID) ||
+		!setObjectIDFilter(c, "staff_id", &req.StaffID) {
+		return
+	}
+	if status := c.Query("status"); status != "" {
+		req.Statuses = strings.Split(status, ",")
+	}
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		req.From = &date
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		endOfDay := date.Add(24*time.Hour - time.Nanosecond)
+		req.To = &endOfDay
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		req.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil && offset > 0 {
+		req.Offset = offset
+	}
+
+	visits, total, err := h.appointmentUseCase.ListAppointments(c.Request.Context(), req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appointments": visits,
+		"total":        total,
+		"limit":        req.Limit,
+		"offset":       req.Offset,
+	})
+}
+
+// BookAppointment books an appointment at a partner clinic
+func (h *AppointmentHandler) BookAppointment(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req appointment.BookAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	visit, err := h.appointmentUseCase.BookAppointment(c.Request.Context(), &req, *userID)
+	if err != nil {
+		handleAppointmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, visit)
+}
+
+// CheckConflicts reports what a booking would collide with, without booking it
+func (h *AppointmentHandler) CheckConflicts(c *gin.Context) {
+	var req appointment.BookAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conflicts, err := h.appointmentUseCase.CheckConflicts(c.Request.Context(), &req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"available": len(conflicts) == 0,
+		"conflicts": conflicts,
+	})
+}
+
+// RescheduleAppointment moves an appointment to another time
+func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+		return
+	}
+
+	var req appointment.RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	visit, err := h.appointmentUseCase.RescheduleAppointment(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		handleAppointmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, visit)
+}
+
+// CancelAppointment cancels an appointment and its transport task
+func (h *AppointmentHandler) CancelAppointment(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	visit, err := h.appointmentUseCase.CancelAppointment(c.Request.Context(), id, req.Reason, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, visit)
+}
+
+// ListCalendarFeeds lists the calendar feeds of the current user
+func (h *AppointmentHandler) ListCalendarFeeds(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	feeds, err := h.appointmentUseCase.ListFeeds(c.Request.Context(), *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	items := make([]gin.H, len(feeds))
+	for i, feed := range feeds {
+		items[i] = gin.H{"feed": feed, "url": calendarFeedURL(c, feed.Token)}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feeds": items})
+}
+
+// CreateCalendarFeed creates an iCalendar subscription URL for a clinic or for the current user
+func (h *AppointmentHandler) CreateCalendarFeed(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req appointment.CreateFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.appointmentUseCase.CreateFeed(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"feed": feed, "url": calendarFeedURL(c, feed.Token)})
+}
+
+// RevokeCalendarFeed deletes a calendar feed of the current user
+func (h *AppointmentHandler) RevokeCalendarFeed(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid feed ID"})
+		return
+	}
+
+	if err := h.appointmentUseCase.RevokeFeed(c.Request.Context(), id, *userID); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "calendar feed revoked successfully"})
+}
+
+// GetCalendarFeed serves a calendar feed to calendar apps; the token authenticates the request
+func (h *AppointmentHandler) GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	content, err := h.appointmentUseCase.RenderFeed(c.Request.Context(), token)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", content)
+}
+
+// handleAppointmentError responds with the conflict list when a booking collides
+func handleAppointmentError(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		if conflictErr, ok := appErr.Err.(*appointment.ConflictError); ok {
+			c.JSON(appErr.Code, gin.H{
+				"error":     appErr.Message,
+				"conflicts": conflictErr.Conflicts,
+			})
+			return
+		}
+	}
+	HandleError(c, err)
+}
+
+// calendarFeedURL returns the subscription URL of a calendar feed
+func calendarFeedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	return scheme + "://" + c.Request.Host + "/api/v1/public/calendar/" + token + ".ics"
+}
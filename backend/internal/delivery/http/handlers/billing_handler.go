@@ -0,0 +1,467 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/billing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BillingHandler serves clinic invoices, balances, medical fund charges and expenditure reports
+type BillingHandler struct {
+	billingUseCase *billing.BillingUseCase
+	validate       *validator.Validate
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(billingUseCase *billing.BillingUseCase) *BillingHandler {
+	return &BillingHandler{
+		billingUseCase: billingUseCase,
+		validate:       validator.New(),
+	}
+}
+
+// ListInvoices lists clinic invoices
+func (h *BillingHandler) ListInvoices(c *gin.Context) {
+	filter := &repositories.ClinicInvoiceFilter{
+		SortOrder: c.Query("sort_order"),
+		Limit:     50,
+	}
+
+	if !setObjectIDFilter(c, "partner_id", &filter.PartnerID) ||
+		!setObjectIDFilter(c, "animal_id", &filter.AnimalID) ||
+		!setObjectIDFilter(c, "visit_id", &filter.VisitID) {
+		return
+	}
+	if status := c.Query("status"); status != "" {
+		filter.Statuses = strings.Split(status, ",")
+	}
+	if unmatched, err := strconv.ParseBool(c.Query("unmatched")); err == nil {
+		filter.Unmatched = &unmatched
+	}
+	if !parseDateRange(c, &filter.From, &filter.To) {
+		return
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	invoices, total, err := h.billingUseCase.ListInvoices(c.Request.Context(), filter)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invoices": invoices,
+		"total":    total,
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	})
+}
+
+// GetInvoice gets a clinic invoice by ID
+func (h *BillingHandler) GetInvoice(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	invoice, err := h.billingUseCase.GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// CreateInvoice records an invoice from a partner clinic
+func (h *BillingHandler) CreateInvoice(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req billing.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.billingUseCase.CreateInvoice(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+// UpdateInvoice corrects a clinic invoice
+func (h *BillingHandler) UpdateInvoice(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var req billing.UpdateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.billingUseCase.UpdateInvoice(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// DeleteInvoice deletes an invoice entered by mistake
+func (h *BillingHandler) DeleteInvoice(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	if err := h.billingUseCase.DeleteInvoice(c.Request.Context(), id, *userID); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invoice deleted successfully"})
+}
+
+// VoidInvoice voids an invoice the clinic withdrew
+func (h *BillingHandler) VoidInvoice(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	invoice, err := h.billingUseCase.VoidInvoice(c.Request.Context(), id, req.Reason, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// RecordPayment records a payment of a clinic invoice
+func (h *BillingHandler) RecordPayment(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var req billing.RecordPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.billingUseCase.RecordPayment(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// DeletePayment removes a payment recorded by mistake
+func (h *BillingHandler) DeletePayment(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	paymentID, err := primitive.ObjectIDFromHex(c.Param("paymentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	invoice, err := h.billingUseCase.DeletePayment(c.Request.Context(), id, paymentID, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// MatchLine links an invoice line to a visit; an empty visit_id unlinks it
+func (h *BillingHandler) MatchLine(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	lineID, err := primitive.ObjectIDFromHex(c.Param("lineId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid line ID"})
+		return
+	}
+
+	var req struct {
+		VisitID string `json:"visit_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var visitID *primitive.ObjectID
+	if req.VisitID != "" {
+		id, err := primitive.ObjectIDFromHex(req.VisitID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid visit ID"})
+			return
+		}
+		visitID = &id
+	}
+
+	invoice, err := h.billingUseCase.MatchLine(c.Request.Context(), id, lineID, visitID, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// ChargeToMedicalFund pays part of an invoice from restricted medical donations
+func (h *BillingHandler) ChargeToMedicalFund(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+
+	var req billing.ChargeFundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoice, err := h.billingUseCase.ChargeToMedicalFund(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// ReleaseFundCharge removes a medical fund charge from an invoice
+func (h *BillingHandler) ReleaseFundCharge(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice ID"})
+		return
+	}
+	chargeID, err := primitive.ObjectIDFromHex(c.Param("chargeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid charge ID"})
+		return
+	}
+
+	invoice, err := h.billingUseCase.ReleaseFundCharge(c.Request.Context(), id, chargeID, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetClinicBalances lists the outstanding balance of every clinic
+func (h *BillingHandler) GetClinicBalances(c *gin.Context) {
+	balances, err := h.billingUseCase.GetClinicBalances(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balances": balances})
+}
+
+// GetClinicStatement gets the outstanding balance and unpaid invoices of a clinic
+func (h *BillingHandler) GetClinicStatement(c *gin.Context) {
+	partnerID, err := primitive.ObjectIDFromHex(c.Param("partnerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid partner ID"})
+		return
+	}
+
+	statement, err := h.billingUseCase.GetClinicStatement(c.Request.Context(), partnerID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// GetMedicalFund lists the restricted medical donations with the amounts spent and left
+func (h *BillingHandler) GetMedicalFund(c *gin.Context) {
+	fund, err := h.billingUseCase.GetMedicalFund(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fund)
+}
+
+// GetAnimalExpenditure gets the medical costs of an animal
+func (h *BillingHandler) GetAnimalExpenditure(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	expenditure, err := h.billingUseCase.GetAnimalExpenditure(c.Request.Context(), animalID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expenditure)
+}
+
+// GetExpenditureReport reports medical spending by month and by animal; defaults to the last 12 months
+func (h *BillingHandler) GetExpenditureReport(c *gin.Context) {
+	var from, to *time.Time
+	if !parseDateRange(c, &from, &to) {
+		return
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -11, 0)
+		from = &start
+	}
+
+	report, err := h.billingUseCase.GetExpenditureReport(c.Request.Context(), *from, *to)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseDateRange parses the optional from and to query parameters (YYYY-MM-DD), responding with 400 when invalid
+func parseDateRange(c *gin.Context, from, to **time.Time) bool {
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return false
+		}
+		*from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return false
+		}
+		endOfDay := date.Add(24*time.Hour - time.Nanosecond)
+		*to = &endOfDay
+	}
+	return true
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/contract"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContractHandler serves adoption contract templates, generation and electronic signing
+type ContractHandler struct {
+	contractUseCase *contract.ContractUseCase
+	validate        *validator.Validate
+}
+
+// NewContractHandler creates a new contract handler
+func NewContractHandler(contractUseCase *contract.ContractUseCase) *ContractHandler {
+	return &ContractHandler{
+		contractUseCase: contractUseCase,
+		validate:        validator.New(),
+	}
+}
+
+// CreateTemplate saves a new version of the contract template in a language
+func (h *ContractHandler) CreateTemplate(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req contract.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.contractUseCase.CreateTemplate(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates lists the saved contract template versions, optionally in one language
+func (h *ContractHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.contractUseCase.ListTemplates(c.Request.Context(), c.Query("lang"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates":    templates,
+		"placeholders": contract.Placeholders,
+	})
+}
+
+// GetTemplate returns a contract template version
+func (h *ContractHandler) GetTemplate(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid template ID"})
+		return
+	}
+
+	template, err := h.contractUseCase.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// GenerateContract generates the contract of an adoption and issues its signing link
+func (h *ContractHandler) GenerateContract(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+
+	var req contract.GenerateContractRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	issued, err := h.contractUseCase.GenerateContract(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, issued)
+}
+
+// ListContracts lists adoption contracts
+func (h *ContractHandler) ListContracts(c *gin.Context) {
+	var req contract.ListContractsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contracts, total, err := h.contractUseCase.ListContracts(c.Request.Context(), &req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contracts": contracts,
+		"total":     total,
+	})
+}
+
+// GetContract returns a contract with its signing audit trail
+func (h *ContractHandler) GetContract(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contract ID"})
+		return
+	}
+
+	result, err := h.contractUseCase.GetContract(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RenewLink issues a new signing link for a contract waiting for a signature
+func (h *ContractHandler) RenewLink(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contract ID"})
+		return
+	}
+
+	var req contract.RenewLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	issued, err := h.contractUseCase.RenewLink(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, issued)
+}
+
+// VoidContract voids a contract
+func (h *ContractHandler) VoidContract(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contract ID"})
+		return
+	}
+
+	var req contract.VoidContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.contractUseCase.VoidContract(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// VerifyContract checks a signed contract for tampering; a copy of the PDF can be uploaded as "file" to compare
+func (h *ContractHandler) VerifyContract(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid contract ID"})
+		return
+	}
+
+	var file io.Reader
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		opened, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+		defer opened.Close()
+		file = opened
+	}
+
+	result, err := h.contractUseCase.VerifyContract(c.Request.Context(), id, file)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ViewSigningContract shows the contract of a signing link; the token in the URL authenticates the adopter
+func (h *ContractHandler) ViewSigningContract(c *gin.Context) {
+	view, err := h.contractUseCase.ViewContract(c.Request.Context(), c.Param("token"), signingClient(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+// SignContract records the adopter's signature through a signing link
+func (h *ContractHandler) SignContract(c *gin.Context) {
+	var req contract.SignContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	receipt, err := h.contractUseCase.SignContract(c.Request.Context(), c.Param("token"), &req, signingClient(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// signingClient identifies the adopter's browser for the signing audit trail
+func signingClient(c *gin.Context) contract.Client {
+	return contract.Client{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
@@ -43,6 +45,41 @@ func (h *DocumentHandler) CreateDocument(c *gin.Context) {
 	c.JSON(http.StatusCreated, docReq)
 }
 
+// UploadDocument uploads a file and creates a new document
+// @Summary Upload document
+// @Tags documents
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Document file"
+// @Param title formData string true "Title"
+// @Param type formData string true "Document type"
+// @Success 201 {object} entities.Document
+// @Router /documents/upload [post]
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	var req document.UploadDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	doc, err := h.documentUseCase.UploadDocument(c.Request.Context(), &req, file, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, doc)
+}
+
 // GetDocument gets a document by ID
 func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	idParam := c.Param("id")
@@ -260,18 +297,59 @@ func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
 
 	userID := c.MustGet("user_id").(primitive.ObjectID)
 
-	doc, err := h.documentUseCase.DownloadDocument(c.Request.Context(), id, userID)
+	download, err := h.documentUseCase.DownloadDocument(c.Request.Context(), id, userID)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	// In a real application, you would serve the actual file here
-	// For now, we return the document metadata
-	c.JSON(http.StatusOK, gin.H{
-		"document":     doc,
-		"download_url": doc.FileURL, // Use the document's file URL
-	})
+	// Without a pre-signed URL the file is streamed through the API
+	if download.DownloadURL == "" {
+		download.DownloadURL = strings.TrimSuffix(c.Request.URL.Path, "/download") + "/file"
+	}
+
+	c.JSON(http.StatusOK, download)
+}
+
+// StreamDocument streams the document file, supporting range requests
+// @Summary Stream document file
+// @Tags documents
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Document ID"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Router /documents/{id}/file [get]
+func (h *DocumentHandler) StreamDocument(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	// Only count the request that starts the download, not the follow-up ranges
+	rangeHeader := c.GetHeader("Range")
+	recordDownload := rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+
+	file, err := h.documentUseCase.OpenDocument(c.Request.Context(), id, userID, recordDownload)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+	defer file.Content.Close()
+
+	doc := file.Document
+	c.Header("Content-Type", doc.MimeType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	if doc.Checksum != "" {
+		c.Header("ETag", `"`+doc.Checksum+`"`)
+	}
+
+	http.ServeContent(c.Writer, c.Request, doc.FileName, file.Info.LastModified, file.Content)
 }
 
 // CreateNewVersion creates a new version of a document
@@ -283,18 +361,15 @@ func (h *DocumentHandler) CreateNewVersion(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		FilePath string `json:"file_path" binding:"required"`
-		FileSize int64  `json:"file_size" binding:"required"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
 		return
 	}
 
 	userID := c.MustGet("user_id").(primitive.ObjectID)
 
-	newVersion, err := h.documentUseCase.CreateNewVersion(c.Request.Context(), id, req.FilePath, req.FileSize, userID)
+	newVersion, err := h.documentUseCase.CreateNewVersion(c.Request.Context(), id, file, userID)
 	if err != nil {
 		HandleError(c, err)
 		return
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/usecase/document"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,7 +21,7 @@ import (
 func TestDocumentHandler_ArchiveDocument(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	docUseCase := document.NewDocumentUseCase(mockDocRepo, mockAuditLogRepo)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, nil, nil, 0)
 	handler := NewDocumentHandler(docUseCase)
 
 	gin.SetMode(gin.TestMode)
@@ -48,7 +50,7 @@ func TestDocumentHandler_ArchiveDocument(t *testing.T) {
 func TestDocumentHandler_UnarchiveDocument(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	docUseCase := document.NewDocumentUseCase(mockDocRepo, mockAuditLogRepo)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, nil, nil, 0)
 	handler := NewDocumentHandler(docUseCase)
 
 	gin.SetMode(gin.TestMode)
@@ -76,7 +78,7 @@ func TestDocumentHandler_UnarchiveDocument(t *testing.T) {
 func TestDocumentHandler_ShareDocument(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	docUseCase := document.NewDocumentUseCase(mockDocRepo, mockAuditLogRepo)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, nil, nil, 0)
 	handler := NewDocumentHandler(docUseCase)
 
 	gin.SetMode(gin.TestMode)
@@ -107,7 +109,7 @@ func TestDocumentHandler_ShareDocument(t *testing.T) {
 func TestDocumentHandler_SearchDocuments(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockDocRepo := new(mocks.DocumentRepository)
-		docUseCase := document.NewDocumentUseCase(mockDocRepo, nil)
+		docUseCase := document.NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 		handler := NewDocumentHandler(docUseCase)
 
 		router := setupRouter()
@@ -128,7 +130,7 @@ func TestDocumentHandler_SearchDocuments(t *testing.T) {
 
 	t.Run("missing q", func(t *testing.T) {
 		mockDocRepo := new(mocks.DocumentRepository)
-		docUseCase := document.NewDocumentUseCase(mockDocRepo, nil)
+		docUseCase := document.NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 		handler := NewDocumentHandler(docUseCase)
 
 		router := setupRouter()
@@ -144,7 +146,7 @@ func TestDocumentHandler_SearchDocuments(t *testing.T) {
 
 func TestDocumentHandler_GetDocumentsByEntity(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
-	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 	handler := NewDocumentHandler(docUseCase)
 
 	router := setupRouter()
@@ -162,7 +164,7 @@ func TestDocumentHandler_GetDocumentsByEntity(t *testing.T) {
 
 func TestDocumentHandler_GetDocumentsByType(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
-	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 	handler := NewDocumentHandler(docUseCase)
 
 	router := setupRouter()
@@ -180,7 +182,7 @@ func TestDocumentHandler_GetDocumentsByType(t *testing.T) {
 
 func TestDocumentHandler_GetDocumentsByCategory(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
-	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 	handler := NewDocumentHandler(docUseCase)
 
 	router := setupRouter()
@@ -195,3 +197,43 @@ func TestDocumentHandler_GetDocumentsByCategory(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestDocumentHandler_StreamDocument_Range(t *testing.T) {
+	mockDocRepo := new(mocks.DocumentRepository)
+	storageService := storage.NewStorageService(storage.NewLocalBackend(t.TempDir()), "/uploads", 1<<20)
+	docUseCase := document.NewDocumentUseCase(mockDocRepo, nil, nil, storageService, nil, 0)
+	handler := NewDocumentHandler(docUseCase)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	uploaderID := primitive.NewObjectID()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uploaderID)
+	})
+	router.GET("/documents/:id/file", handler.StreamDocument)
+
+	url, err := storageService.SaveFile(context.Background(), []byte("0123456789"), "documents/test", "file.txt")
+	assert.NoError(t, err)
+
+	docID := primitive.NewObjectID()
+	doc := &entities.Document{
+		ID:         docID,
+		UploadedBy: uploaderID,
+		FileName:   "file.txt",
+		MimeType:   "text/plain",
+		FileURL:    url,
+		ScanStatus: entities.DocumentScanStatusClean,
+	}
+	mockDocRepo.On("FindByID", mock.Anything, docID).Return(doc, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/documents/"+docID.Hex()+"/file", nil)
+	req.Header.Set("Range", "bytes=4-6")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "456", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "file.txt")
+	mockDocRepo.AssertNotCalled(t, "IncrementDownloadCount", mock.Anything, mock.Anything)
+}
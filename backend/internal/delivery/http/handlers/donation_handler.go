@@ -212,6 +212,7 @@ func (h *DonationHandler) DeleteDonation(c *gin.Context) {
 // @Produce json
 // @Param donor_id query string false "Donor ID"
 // @Param campaign_id query string false "Campaign ID"
+// @Param event_id query string false "Event ID"
 // @Param type query string false "Donation type"
 // @Param status query string false "Donation status"
 // @Param limit query int false "Limit"
@@ -241,6 +242,13 @@ func (h *DonationHandler) ListDonations(c *gin.Context) {
 		}
 	}
 
+	// Parse event ID
+	if eventIDStr := c.Query("event_id"); eventIDStr != "" {
+		if eventID, err := primitive.ObjectIDFromHex(eventIDStr); err == nil {
+			filter.EventID = &eventID
+		}
+	}
+
 	// Parse is_recurring
 	if recurringStr := c.Query("is_recurring"); recurringStr != "" {
 		if recurring, err := strconv.ParseBool(recurringStr); err == nil {
@@ -168,6 +168,13 @@ func (h *EventHandler) ListEvents(c *gin.Context) {
 		}
 	}
 
+	// Parse series
+	if seriesStr := c.Query("series_id"); seriesStr != "" {
+		if seriesID, err := primitive.ObjectIDFromHex(seriesStr); err == nil {
+			filter.SeriesID = &seriesID
+		}
+	}
+
 	// Parse start date
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
 		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
@@ -446,6 +453,84 @@ func (h *EventHandler) UnassignVolunteer(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Volunteer unassigned successfully"})
 }
 
+// AssignAnimal brings an animal to an event
+// @Summary Assign animal to event
+// @Tags events
+// @Param id path string true "Event ID"
+// @Param animal_id body map[string]string true "Animal ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /events/{id}/assign-animal [post]
+func (h *EventHandler) AssignAnimal(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req struct {
+		AnimalID string `json:"animal_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	animalID, err := primitive.ObjectIDFromHex(req.AnimalID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid animal ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	if err := h.eventUseCase.AssignAnimal(c.Request.Context(), id, animalID, userID); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Animal assigned successfully"})
+}
+
+// UnassignAnimal removes an animal from an event
+// @Summary Unassign animal from event
+// @Tags events
+// @Param id path string true "Event ID"
+// @Param animal_id body map[string]string true "Animal ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /events/{id}/unassign-animal [post]
+func (h *EventHandler) UnassignAnimal(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req struct {
+		AnimalID string `json:"animal_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	animalID, err := primitive.ObjectIDFromHex(req.AnimalID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid animal ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	if err := h.eventUseCase.UnassignAnimal(c.Request.Context(), id, animalID, userID); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Animal unassigned successfully"})
+}
+
 // GetEventStatistics gets event statistics
 // @Summary Get event statistics
 // @Tags events
@@ -503,6 +588,30 @@ func (h *EventHandler) RegisterForEvent(c *gin.Context) {
 	c.JSON(http.StatusCreated, attendance)
 }
 
+// CancelRegistration cancels a registration for an event and gives its seats to the waitlist
+func (h *EventHandler) CancelRegistration(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+	attendanceID, err := primitive.ObjectIDFromHex(c.Param("attendanceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid registration ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	attendance, err := h.eventUseCase.CancelRegistration(c.Request.Context(), id, attendanceID, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
+
 // GetEventRegistrations gets all registrations for an event
 func (h *EventHandler) GetEventRegistrations(c *gin.Context) {
 	idParam := c.Param("id")
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/usecase/outcome"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventOutcomeHandler serves event outcomes, ROI reports and feedback surveys
+type EventOutcomeHandler struct {
+	outcomeUseCase *outcome.OutcomeUseCase
+	validate       *validator.Validate
+}
+
+// NewEventOutcomeHandler creates a new event outcome handler
+func NewEventOutcomeHandler(outcomeUseCase *outcome.OutcomeUseCase) *EventOutcomeHandler {
+	return &EventOutcomeHandler{
+		outcomeUseCase: outcomeUseCase,
+		validate:       validator.New(),
+	}
+}
+
+// GetEventReport reports the costs of an event against the funds raised and adoptions
+func (h *EventOutcomeHandler) GetEventReport(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	report, err := h.outcomeUseCase.GetEventReport(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RefreshOutcomes computes the funds raised, adoptions and volunteer hours of an event now
+func (h *EventOutcomeHandler) RefreshOutcomes(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	event, err := h.outcomeUseCase.RefreshOutcomes(c.Request.Context(), id, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// SendFeedbackRequests sends the feedback survey of a completed event to its attendees
+func (h *EventOutcomeHandler) SendFeedbackRequests(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	count, err := h.outcomeUseCase.SendFeedbackRequests(c.Request.Context(), id, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Feedback requests sent successfully",
+		"recipients_count": count,
+	})
+}
+
+// ViewFeedback opens a feedback survey through the link sent to the attendee
+func (h *EventOutcomeHandler) ViewFeedback(c *gin.Context) {
+	view, err := h.outcomeUseCase.ViewFeedback(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+// SubmitFeedback records the attendee's rating of an event
+func (h *EventOutcomeHandler) SubmitFeedback(c *gin.Context) {
+	var req outcome.SubmitFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.outcomeUseCase.SubmitFeedback(c.Request.Context(), c.Param("token"), &req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Thank you for your feedback"})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/usecase/event"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventSeriesHandler handles recurring events and their occurrences
+type EventSeriesHandler struct {
+	seriesUseCase *event.SeriesUseCase
+}
+
+// NewEventSeriesHandler creates a new event series handler
+func NewEventSeriesHandler(seriesUseCase *event.SeriesUseCase) *EventSeriesHandler {
+	return &EventSeriesHandler{seriesUseCase: seriesUseCase}
+}
+
+// CreateSeries creates a series and generates its occurrences
+func (h *EventSeriesHandler) CreateSeries(c *gin.Context) {
+	var series entities.EventSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	occurrences, err := h.seriesUseCase.CreateSeries(c.Request.Context(), &series, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"series": series, "occurrences": occurrences})
+}
+
+// ListSeries lists series
+func (h *EventSeriesHandler) ListSeries(c *gin.Context) {
+	filter := &repositories.EventSeriesFilter{
+		Type:   c.Query("type"),
+		Status: c.Query("status"),
+		Search: c.Query("search"),
+		Limit:  20,
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil {
+		filter.Offset = offset
+	}
+
+	series, total, err := h.seriesUseCase.ListSeries(c.Request.Context(), filter)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"series": series,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// GetSeries gets a series by ID
+func (h *EventSeriesHandler) GetSeries(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	series, err := h.seriesUseCase.GetSeries(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// UpdateSeries changes a series and its upcoming occurrences, or with ?from_event_id=
+// only that occurrence and the following ones
+func (h *EventSeriesHandler) UpdateSeries(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	var fromEventID *primitive.ObjectID
+	if from := c.Query("from_event_id"); from != "" {
+		eventID, err := primitive.ObjectIDFromHex(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+		fromEventID = &eventID
+	}
+
+	var update entities.EventSeries
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	series, occurrences, err := h.seriesUseCase.UpdateSeries(c.Request.Context(), id, &update, fromEventID, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series, "occurrences": occurrences})
+}
+
+// CancelSeries cancels a series and its upcoming occurrences
+func (h *EventSeriesHandler) CancelSeries(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	cancelled, err := h.seriesUseCase.CancelSeries(c.Request.Context(), id, userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Series cancelled successfully", "occurrences_cancelled": cancelled})
+}
+
+// ListOccurrences lists the occurrences of a series (?upcoming=true for the ones to come)
+func (h *EventSeriesHandler) ListOccurrences(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	var from *time.Time
+	if upcoming, _ := strconv.ParseBool(c.Query("upcoming")); upcoming {
+		now := time.Now()
+		from = &now
+	}
+	limit := int64(50)
+	if l, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil {
+		limit = l
+	}
+	offset := int64(0)
+	if o, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil {
+		offset = o
+	}
+
+	occurrences, total, err := h.seriesUseCase.ListOccurrences(c.Request.Context(), id, from, limit, offset)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"occurrences": occurrences,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetSeriesStatistics returns the attendance across the occurrences of a series
+func (h *EventSeriesHandler) GetSeriesStatistics(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	stats, err := h.seriesUseCase.GetSeriesStatistics(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
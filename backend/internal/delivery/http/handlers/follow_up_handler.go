@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/followup"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FollowUpHandler serves post-adoption follow-up surveys
+type FollowUpHandler struct {
+	followUpUseCase *followup.FollowUpUseCase
+	validate        *validator.Validate
+}
+
+// NewFollowUpHandler creates a new follow-up handler
+func NewFollowUpHandler(followUpUseCase *followup.FollowUpUseCase) *FollowUpHandler {
+	return &FollowUpHandler{
+		followUpUseCase: followUpUseCase,
+		validate:        validator.New(),
+	}
+}
+
+// SendFollowUp sends the survey of a follow-up to the adopter, or sends it again with a new link
+func (h *FollowUpHandler) SendFollowUp(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid follow-up index"})
+		return
+	}
+
+	sent, err := h.followUpUseCase.SendFollowUp(c.Request.Context(), id, index, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sent)
+}
+
+// GetPhotos returns links to the photos the adopter uploaded with the survey of a follow-up
+func (h *FollowUpHandler) GetPhotos(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid follow-up index"})
+		return
+	}
+
+	photos, err := h.followUpUseCase.GetPhotos(c.Request.Context(), id, index)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, photos)
+}
+
+// GetSettings returns the follow-up survey settings
+func (h *FollowUpHandler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, h.followUpUseCase.GetSettings(c.Request.Context()))
+}
+
+// UpdateSettings replaces the follow-up survey settings
+func (h *FollowUpHandler) UpdateSettings(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req entities.FollowUpSurveySettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.followUpUseCase.UpdateSettings(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetResponseReport reports the response rate of follow-up surveys; defaults to the last 90 days
+func (h *FollowUpHandler) GetResponseReport(c *gin.Context) {
+	var from, to *time.Time
+	if !parseDateRange(c, &from, &to) {
+		return
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := to.AddDate(0, 0, -90)
+		from = &start
+	}
+
+	report, err := h.followUpUseCase.GetResponseReport(c.Request.Context(), *from, *to)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ViewSurvey opens a follow-up survey through the link sent to the adopter
+func (h *FollowUpHandler) ViewSurvey(c *gin.Context) {
+	view, err := h.followUpUseCase.ViewSurvey(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, view)
+}
+
+// UploadSurveyPhotos adds photos uploaded as "photos" to a follow-up survey
+func (h *FollowUpHandler) UploadSurveyPhotos(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "photos must be uploaded as multipart form data"})
+		return
+	}
+
+	photos, err := h.followUpUseCase.UploadPhotos(c.Request.Context(), c.Param("token"), form.File["photos"])
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"photos": photos})
+}
+
+// SubmitSurvey records the adopter's answers to a follow-up survey
+func (h *FollowUpHandler) SubmitSurvey(c *gin.Context) {
+	var req followup.SubmitSurveyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	receipt, err := h.followUpUseCase.SubmitSurvey(c.Request.Context(), c.Param("token"), &req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
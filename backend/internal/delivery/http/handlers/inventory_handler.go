@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
@@ -262,10 +263,12 @@ func (h *InventoryHandler) AddStock(c *gin.Context) {
 	}
 
 	var req struct {
-		Quantity  float64 `json:"quantity" binding:"required,gt=0"`
-		UnitCost  float64 `json:"unit_cost" binding:"required,gte=0"`
-		Reference string  `json:"reference"`
-		Notes     string  `json:"notes"`
+		Quantity       float64    `json:"quantity" binding:"required,gt=0"`
+		UnitCost       float64    `json:"unit_cost" binding:"required,gte=0"`
+		LotNumber      string     `json:"lot_number"`
+		ExpirationDate *time.Time `json:"expiration_date"`
+		Reference      string     `json:"reference"`
+		Notes          string     `json:"notes"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -274,7 +277,7 @@ func (h *InventoryHandler) AddStock(c *gin.Context) {
 
 	userID := c.MustGet("user_id").(primitive.ObjectID)
 
-	if err := h.inventoryUseCase.AddStock(c.Request.Context(), id, req.Quantity, req.UnitCost, userID, req.Reference, req.Notes); err != nil {
+	if err := h.inventoryUseCase.AddStockLot(c.Request.Context(), id, req.Quantity, req.UnitCost, req.LotNumber, req.ExpirationDate, userID, req.Reference, req.Notes); err != nil {
 		HandleError(c, err)
 		return
 	}
@@ -411,3 +414,39 @@ func (h *InventoryHandler) GetInventoryHistory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"history": history, "total": total})
 }
+
+// GetAnimalCostOfCare gets the cost of inventory consumed for an animal
+func (h *InventoryHandler) GetAnimalCostOfCare(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid animal ID"})
+		return
+	}
+
+	var from, to *time.Time
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = &parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		endOfDay := parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		to = &endOfDay
+	}
+
+	costOfCare, err := h.inventoryUseCase.GetAnimalCostOfCare(c.Request.Context(), animalID, from, to)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, costOfCare)
+}
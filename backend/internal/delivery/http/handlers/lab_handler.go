@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/lab"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LabHandler serves lab panels, analyte definitions and result imports
+type LabHandler struct {
+	labUseCase *lab.LabUseCase
+	validate   *validator.Validate
+}
+
+// NewLabHandler creates a new lab handler
+func NewLabHandler(labUseCase *lab.LabUseCase) *LabHandler {
+	return &LabHandler{
+		labUseCase: labUseCase,
+		validate:   validator.New(),
+	}
+}
+
+// ListPanels lists lab panels
+func (h *LabHandler) ListPanels(c *gin.Context) {
+	filter := &repositories.LabPanelFilter{
+		SortOrder: c.Query("sort_order"),
+		Limit:     50,
+	}
+
+	if !setObjectIDFilter(c, "animal_id", &filter.AnimalID) ||
+		!setObjectIDFilter(c, "visit_id", &filter.VisitID) ||
+		!setObjectIDFilter(c, "partner_id", &filter.PartnerID) {
+		return
+	}
+	h.listPanels(c, filter)
+}
+
+// ListAnimalPanels lists the lab panels of an animal
+func (h *LabHandler) ListAnimalPanels(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	h.listPanels(c, &repositories.LabPanelFilter{
+		AnimalID:  &animalID,
+		SortOrder: c.Query("sort_order"),
+		Limit:     50,
+	})
+}
+
+func (h *LabHandler) listPanels(c *gin.Context, filter *repositories.LabPanelFilter) {
+	if analytes := c.Query("analytes"); analytes != "" {
+		filter.AnalyteCodes = strings.Split(analytes, ",")
+	}
+	if abnormal, err := strconv.ParseBool(c.Query("abnormal")); err == nil {
+		filter.Abnormal = &abnormal
+	}
+	if critical, err := strconv.ParseBool(c.Query("critical")); err == nil {
+		filter.Critical = &critical
+	}
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.From = &date
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		endOfDay := date.Add(24*time.Hour - time.Nanosecond)
+		filter.To = &endOfDay
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	panels, total, err := h.labUseCase.ListPanels(c.Request.Context(), filter)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"panels": panels,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// CompareAnalytes compares analytes of an animal across panels
+func (h *LabHandler) CompareAnalytes(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	var codes []string
+	for _, code := range strings.Split(c.Query("analytes"), ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+
+	comparisons, err := h.labUseCase.CompareAnalytes(c.Request.Context(), animalID, codes)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"analytes": comparisons})
+}
+
+// GetPanel gets a lab panel by ID
+func (h *LabHandler) GetPanel(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid panel ID"})
+		return
+	}
+
+	panel, err := h.labUseCase.GetPanel(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, panel)
+}
+
+// CreatePanel records a lab panel
+func (h *LabHandler) CreatePanel(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req lab.CreatePanelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	panel, err := h.labUseCase.CreatePanel(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, panel)
+}
+
+// UpdatePanel updates a lab panel
+func (h *LabHandler) UpdatePanel(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid panel ID"})
+		return
+	}
+
+	var req lab.UpdatePanelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	panel, err := h.labUseCase.UpdatePanel(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, panel)
+}
+
+// DeletePanel deletes a lab panel
+func (h *LabHandler) DeletePanel(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid panel ID"})
+		return
+	}
+
+	if err := h.labUseCase.DeletePanel(c.Request.Context(), id, *userID); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "lab panel deleted successfully"})
+}
+
+// AttachDocument links an uploaded lab report PDF to a panel
+func (h *LabHandler) AttachDocument(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid panel ID"})
+		return
+	}
+
+	var req struct {
+		DocumentID string `json:"document_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	documentID, err := primitive.ObjectIDFromHex(req.DocumentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document ID"})
+		return
+	}
+
+	panel, err := h.labUseCase.AttachDocument(c.Request.Context(), id, documentID, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, panel)
+}
+
+// ImportResults imports lab results from an uploaded CSV or HL7 file
+func (h *LabHandler) ImportResults(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req lab.ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer file.Close()
+	req.FileName = fileHeader.Filename
+
+	result, err := h.labUseCase.ImportResults(c.Request.Context(), &req, file, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListAnalytes lists the analyte definitions
+func (h *LabHandler) ListAnalytes(c *gin.Context) {
+	activeOnly := c.Query("active") == "true"
+
+	analytes, err := h.labUseCase.ListAnalytes(c.Request.Context(), activeOnly)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"analytes": analytes})
+}
+
+// CreateAnalyte defines a new analyte
+func (h *LabHandler) CreateAnalyte(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req lab.CreateAnalyteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analyte, err := h.labUseCase.CreateAnalyte(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, analyte)
+}
+
+// UpdateAnalyte updates an analyte definition
+func (h *LabHandler) UpdateAnalyte(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid analyte ID"})
+		return
+	}
+
+	var req lab.UpdateAnalyteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analyte, err := h.labUseCase.UpdateAnalyte(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analyte)
+}
+
+// setObjectIDFilter parses an optional ObjectID query parameter, responding with 400 when invalid
+func setObjectIDFilter(c *gin.Context, name string, target **primitive.ObjectID) bool {
+	value := c.Query(name)
+	if value == "" {
+		return true
+	}
+
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return false
+	}
+	*target = &id
+	return true
+}
@@ -429,9 +429,122 @@ func (h *MedicalHandler) RecordMedicationAdministration(c *gin.Context) {
 		return
 	}
 
+	var req medical.AdministerMedicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := middleware.GetUserFromContext(c)
+
+	dose, err := h.medicalUseCase.RecordMedicationAdministration(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	message := "Administration recorded successfully"
+	if dose.Status == entities.DoseStatusAwaitingWitness {
+		message = "Administration recorded, witness sign-off required"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"dose":    dose,
+	})
+}
+
+// GetDosesDueNow lists doses that are overdue or due within the window
+func (h *MedicalHandler) GetDosesDueNow(c *gin.Context) {
+	minutes, err := strconv.Atoi(c.DefaultQuery("window", "60"))
+	if err != nil || minutes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid window"})
+		return
+	}
+
+	doses, err := h.medicalUseCase.GetDosesDueNow(c.Request.Context(), time.Duration(minutes)*time.Minute)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  doses,
+		"total": len(doses),
+	})
+}
+
+// GetShiftDoses lists the dose slots of a shift
+func (h *MedicalHandler) GetShiftDoses(c *gin.Context) {
+	day := time.Now()
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		day = parsed
+	}
+
+	shift := entities.MedicationShift(c.Query("shift"))
+	if shift == "" {
+		shift = entities.ShiftFor(time.Now())
+	}
+
+	var animalID *primitive.ObjectID
+	if animalIDParam := c.Query("animal_id"); animalIDParam != "" {
+		id, err := primitive.ObjectIDFromHex(animalIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid animal ID"})
+			return
+		}
+		animalID = &id
+	}
+
+	doses, err := h.medicalUseCase.GetShiftDoses(c.Request.Context(), day, shift, animalID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"shift": shift,
+		"data":  doses,
+		"total": len(doses),
+	})
+}
+
+// WitnessDose records the second sign-off of a controlled substance dose
+func (h *MedicalHandler) WitnessDose(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dose ID"})
+		return
+	}
+
+	userID, _ := middleware.GetUserFromContext(c)
+
+	dose, err := h.medicalUseCase.WitnessDose(c.Request.Context(), id, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dose)
+}
+
+// SkipDose marks a dose as not given
+func (h *MedicalHandler) SkipDose(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dose ID"})
+		return
+	}
+
 	var req struct {
-		DosageGiven string `json:"dosage_given" binding:"required"`
-		Notes       string `json:"notes"`
+		Reason string `json:"reason" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -441,20 +554,13 @@ func (h *MedicalHandler) RecordMedicationAdministration(c *gin.Context) {
 
 	userID, _ := middleware.GetUserFromContext(c)
 
-	log := entities.AdministrationLog{
-		AdministeredAt: time.Now(),
-		AdministeredBy: *userID,
-		DosageGiven:    req.DosageGiven,
-		Notes:          req.Notes,
-	}
-
-	err = h.medicalUseCase.RecordMedicationAdministration(c.Request.Context(), id, log)
+	dose, err := h.medicalUseCase.SkipDose(c.Request.Context(), id, req.Reason, *userID)
 	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Administration recorded successfully"})
+	c.JSON(http.StatusOK, dose)
 }
 
 // RefillMedication processes a medication refill
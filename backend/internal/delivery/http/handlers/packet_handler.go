@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/packet"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PacketHandler serves printable medical history and adoption packets
+type PacketHandler struct {
+	packetUseCase *packet.PacketUseCase
+	validate      *validator.Validate
+}
+
+// NewPacketHandler creates a new packet handler
+func NewPacketHandler(packetUseCase *packet.PacketUseCase) *PacketHandler {
+	return &PacketHandler{
+		packetUseCase: packetUseCase,
+		validate:      validator.New(),
+	}
+}
+
+// GetPacket renders the medical packet of an animal as a PDF without storing it
+func (h *PacketHandler) GetPacket(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	req := packet.PacketRequest{Language: c.Query("lang")}
+	if sections := c.Query("sections"); sections != "" {
+		for _, section := range strings.Split(sections, ",") {
+			req.Sections = append(req.Sections, packet.Section(strings.TrimSpace(section)))
+		}
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.packetUseCase.RenderPacket(c.Request.Context(), animalID, &req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": result.FileName}))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", result.Content)
+}
+
+// CreatePacket generates the medical packet of an animal and stores it as a document
+func (h *PacketHandler) CreatePacket(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	// The body is optional; an empty one generates the full packet in the default language
+	var req packet.PacketRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, err := h.packetUseCase.CreatePacket(c.Request.Context(), animalID, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
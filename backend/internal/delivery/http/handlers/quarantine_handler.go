@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/quarantine"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuarantineHandler serves quarantine periods and infectious disease outbreaks
+type QuarantineHandler struct {
+	quarantineUseCase *quarantine.QuarantineUseCase
+	validate          *validator.Validate
+}
+
+// NewQuarantineHandler creates a new quarantine handler
+func NewQuarantineHandler(quarantineUseCase *quarantine.QuarantineUseCase) *QuarantineHandler {
+	return &QuarantineHandler{
+		quarantineUseCase: quarantineUseCase,
+		validate:          validator.New(),
+	}
+}
+
+// GetAnimalQuarantine gets the active quarantine of an animal
+func (h *QuarantineHandler) GetAnimalQuarantine(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	result, err := h.quarantineUseCase.GetActiveQuarantine(c.Request.Context(), animalID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// StartQuarantine puts an animal in quarantine
+func (h *QuarantineHandler) StartQuarantine(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	var req quarantine.StartQuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.quarantineUseCase.StartQuarantine(c.Request.Context(), animalID, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListQuarantines lists quarantines
+func (h *QuarantineHandler) ListQuarantines(c *gin.Context) {
+	filter := &repositories.QuarantineFilter{
+		Status: c.Query("status"),
+		Reason: c.Query("reason"),
+		Limit:  50,
+	}
+
+	if !setObjectIDFilter(c, "animal_id", &filter.AnimalID) ||
+		!setObjectIDFilter(c, "outbreak_id", &filter.OutbreakID) {
+		return
+	}
+	if due, err := strconv.ParseBool(c.Query("due")); err == nil && due {
+		now := time.Now()
+		filter.Status = string(entities.QuarantineStatusActive)
+		filter.EndsBefore = &now
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	quarantines, total, err := h.quarantineUseCase.ListQuarantines(c.Request.Context(), filter)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quarantines": quarantines,
+		"total":       total,
+		"limit":       filter.Limit,
+		"offset":      filter.Offset,
+	})
+}
+
+// GetQuarantine gets a quarantine by ID
+func (h *QuarantineHandler) GetQuarantine(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quarantine ID"})
+		return
+	}
+
+	result, err := h.quarantineUseCase.GetQuarantine(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExtendQuarantine changes the planned release date of a quarantine
+func (h *QuarantineHandler) ExtendQuarantine(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quarantine ID"})
+		return
+	}
+
+	var req quarantine.ExtendQuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.quarantineUseCase.ExtendQuarantine(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ReleaseQuarantine releases an animal from quarantine
+func (h *QuarantineHandler) ReleaseQuarantine(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quarantine ID"})
+		return
+	}
+
+	// The body is optional
+	var req quarantine.ReleaseQuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.quarantineUseCase.ReleaseQuarantine(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListOutbreaks lists outbreaks
+func (h *QuarantineHandler) ListOutbreaks(c *gin.Context) {
+	filter := &repositories.OutbreakFilter{
+		Disease: c.Query("disease"),
+		Status:  c.Query("status"),
+		Limit:   50,
+	}
+
+	if !setObjectIDFilter(c, "animal_id", &filter.AnimalID) {
+		return
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	outbreaks, total, err := h.quarantineUseCase.ListOutbreaks(c.Request.Context(), filter)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"outbreaks": outbreaks,
+		"total":     total,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	})
+}
+
+// GetOutbreak gets an outbreak by ID
+func (h *QuarantineHandler) GetOutbreak(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid outbreak ID"})
+		return
+	}
+
+	outbreak, err := h.quarantineUseCase.GetOutbreak(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, outbreak)
+}
+
+// DeclareOutbreak declares an outbreak and traces exposed animals
+func (h *QuarantineHandler) DeclareOutbreak(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req quarantine.DeclareOutbreakRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outbreak, err := h.quarantineUseCase.DeclareOutbreak(c.Request.Context(), &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, outbreak)
+}
+
+// AddOutbreakCases adds cases to an outbreak and traces their contacts
+func (h *QuarantineHandler) AddOutbreakCases(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid outbreak ID"})
+		return
+	}
+
+	var req quarantine.AddOutbreakCasesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outbreak, err := h.quarantineUseCase.AddOutbreakCases(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, outbreak)
+}
+
+// ResolveOutbreak closes an outbreak
+func (h *QuarantineHandler) ResolveOutbreak(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid outbreak ID"})
+		return
+	}
+
+	// The body is optional
+	var req quarantine.ResolveOutbreakRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outbreak, err := h.quarantineUseCase.ResolveOutbreak(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, outbreak)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/search"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// searchPermissions maps each searchable entity type to the permission needed to see it
+var searchPermissions = map[entities.SearchEntityType]middleware.Permission{
+	entities.SearchEntityAnimal:    middleware.PermissionViewAnimals,
+	entities.SearchEntityDonor:     middleware.PermissionViewDonors,
+	entities.SearchEntityVolunteer: middleware.PermissionViewVolunteers,
+	entities.SearchEntityContact:   middleware.PermissionViewContacts,
+	entities.SearchEntityPartner:   middleware.PermissionViewPartners,
+	entities.SearchEntityDocument:  middleware.PermissionViewDocuments,
+	entities.SearchEntityEvent:     middleware.PermissionViewEvents,
+}
+
+// SearchHandler handles the global search endpoint
+type SearchHandler struct {
+	searchUseCase *search.SearchUseCase
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchUseCase *search.SearchUseCase) *SearchHandler {
+	return &SearchHandler{
+		searchUseCase: searchUseCase,
+	}
+}
+
+// Search runs a global search across the records the user is allowed to view
+// @Summary Global search
+// @Tags search
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Search text (at least 2 characters)"
+// @Param type query string false "Entity types, comma separated (animal, donor, volunteer, contact, partner, document, event)"
+// @Param status query string false "Statuses, comma separated"
+// @Param tags query string false "Tags, comma separated"
+// @Param limit query int false "Limit (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} entities.SearchResults
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	value, _ := c.Get("user")
+	user, ok := value.(*entities.User)
+	if !ok {
+		HandleError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	req := &search.SearchRequest{
+		Query:    c.Query("q"),
+		Types:    queryList(c, "type"),
+		Statuses: queryList(c, "status"),
+		Tags:     queryList(c, "tags"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		req.Limit, _ = strconv.Atoi(limit)
+	}
+	if offset := c.Query("offset"); offset != "" {
+		req.Offset, _ = strconv.Atoi(offset)
+	}
+
+	var allowed []entities.SearchEntityType
+	for _, entityType := range entities.SearchEntityTypes {
+		if middleware.HasPermission(user.Role, searchPermissions[entityType]) {
+			allowed = append(allowed, entityType)
+		}
+	}
+
+	results, err := h.searchUseCase.Search(c.Request.Context(), req, allowed, user.ID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// queryList reads a query parameter given either repeated or comma separated
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/sterilization"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SterilizationHandler serves spay/neuter compliance of adoptions
+type SterilizationHandler struct {
+	sterilizationUseCase *sterilization.SterilizationUseCase
+	validate             *validator.Validate
+}
+
+// NewSterilizationHandler creates a new sterilization handler
+func NewSterilizationHandler(sterilizationUseCase *sterilization.SterilizationUseCase) *SterilizationHandler {
+	return &SterilizationHandler{
+		sterilizationUseCase: sterilizationUseCase,
+		validate:             validator.New(),
+	}
+}
+
+// RecordSterilization links the spay/neuter visit or certificate to an adoption
+func (h *SterilizationHandler) RecordSterilization(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+
+	var req sterilization.RecordSterilizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.sterilizationUseCase.RecordSterilization(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// WaiveSterilization waives the spay/neuter requirement of an adoption
+func (h *SterilizationHandler) WaiveSterilization(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid adoption ID"})
+		return
+	}
+
+	var req sterilization.WaiveSterilizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.sterilizationUseCase.WaiveSterilization(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetComplianceReport reports spay/neuter compliance of adoptions; defaults to the current year
+func (h *SterilizationHandler) GetComplianceReport(c *gin.Context) {
+	var from, to *time.Time
+	if !parseDateRange(c, &from, &to) {
+		return
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := time.Date(to.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		from = &start
+	}
+
+	report, err := h.sterilizationUseCase.GetComplianceReport(c.Request.Context(), *from, *to)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
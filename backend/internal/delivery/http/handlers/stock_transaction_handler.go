@@ -72,6 +72,13 @@ func (h *StockTransactionHandler) ListStockTransactions(c *gin.Context) {
 		}
 	}
 
+	if animalIDStr := c.Query("animal_id"); animalIDStr != "" {
+		animalID, err := primitive.ObjectIDFromHex(animalIDStr)
+		if err == nil {
+			filter.AnimalID = &animalID
+		}
+	}
+
 	filter.DateFrom = c.Query("date_from")
 	filter.DateTo = c.Query("date_to")
 
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/ticketing"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxWebhookSize limits the payment notifications read into memory
+const maxWebhookSize = 1 << 20
+
+// TicketHandler serves public event registration, tickets and check-in
+type TicketHandler struct {
+	ticketUseCase *ticketing.TicketUseCase
+	validate      *validator.Validate
+}
+
+// NewTicketHandler creates a new ticket handler
+func NewTicketHandler(ticketUseCase *ticketing.TicketUseCase) *TicketHandler {
+	return &TicketHandler{
+		ticketUseCase: ticketUseCase,
+		validate:      validator.New(),
+	}
+}
+
+// ListPublicEvents lists the upcoming public events
+func (h *TicketHandler) ListPublicEvents(c *gin.Context) {
+	events, err := h.ticketUseCase.ListPublicEvents(c.Request.Context())
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// GetPublicEvent returns a public event
+func (h *TicketHandler) GetPublicEvent(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	event, err := h.ticketUseCase.GetPublicEvent(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// Register registers a member of the public for a public event
+func (h *TicketHandler) Register(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	var req ticketing.PublicRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	registration, err := h.ticketUseCase.Register(c.Request.Context(), id, &req)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, registration)
+}
+
+// ViewTicket opens a ticket through its code
+func (h *TicketHandler) ViewTicket(c *gin.Context) {
+	ticket, err := h.ticketUseCase.ViewTicket(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, ticket)
+}
+
+// GetTicketQRCode returns the QR code of a ticket as a PNG image
+func (h *TicketHandler) GetTicketQRCode(c *gin.Context) {
+	image, err := h.ticketUseCase.TicketQRCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+// Checkout opens a new checkout to pay for a ticket
+func (h *TicketHandler) Checkout(c *gin.Context) {
+	checkout, err := h.ticketUseCase.Checkout(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checkout_url": checkout.URL, "expires_at": checkout.ExpiresAt})
+}
+
+// PaymentWebhook receives the payment notifications of the payment gateway
+func (h *TicketHandler) PaymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.ticketUseCase.HandlePaymentNotification(c.Request.Context(), payload, c.Request.Header); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// CheckIn checks in a ticket scanned at the door of an event
+func (h *TicketHandler) CheckIn(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	var req ticketing.CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attendance, err := h.ticketUseCase.CheckIn(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
+
+// SyncCheckIns checks in the tickets scanned while a scanner was offline
+func (h *TicketHandler) SyncCheckIns(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	var req ticketing.SyncCheckInsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.ticketUseCase.SyncCheckIns(c.Request.Context(), id, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCheckInList downloads the attendee list of an event for offline check-in,
+// as JSON or with ?format=csv as a spreadsheet
+func (h *TicketHandler) GetCheckInList(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+
+	list, err := h.ticketUseCase.CheckInList(c.Request.Context(), id)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, gin.H{"attendees": list, "total": len(list)})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "attendees-" + id.Hex() + ".csv"}))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"attendance_id", "ticket_hash", "name", "email", "seats", "status", "paid", "checked_in_at"})
+	for _, entry := range list {
+		checkedInAt := ""
+		if entry.CheckedInAt != nil {
+			checkedInAt = entry.CheckedInAt.Format(time.RFC3339)
+		}
+		_ = w.Write([]string{
+			entry.AttendanceID.Hex(),
+			entry.TicketHash,
+			entry.Name,
+			entry.Email,
+			strconv.Itoa(entry.Seats),
+			string(entry.Status),
+			strconv.FormatBool(entry.Paid),
+			checkedInAt,
+		})
+	}
+	w.Flush()
+}
+
+// SendTicket emails the ticket of a registration to the attendee again
+func (h *TicketHandler) SendTicket(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	eventID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event ID"})
+		return
+	}
+	attendanceID, err := primitive.ObjectIDFromHex(c.Param("attendanceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration ID"})
+		return
+	}
+
+	attendance, err := h.ticketUseCase.SendTicket(c.Request.Context(), eventID, attendanceID, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/veterinary"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListProtocols lists vaccination protocols.
+func (h *VeterinaryHandler) ListProtocols(c *gin.Context) {
+	var req veterinary.ListProtocolsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	protocols, err := h.useCase.ListProtocols(c.Request.Context(), &req)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"protocols": protocols,
+		"total":     len(protocols),
+	})
+}
+
+// GetProtocol returns a single vaccination protocol.
+func (h *VeterinaryHandler) GetProtocol(c *gin.Context) {
+	protocolID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid protocol ID"})
+		return
+	}
+
+	protocol, err := h.useCase.GetProtocolByID(c.Request.Context(), protocolID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, protocol)
+}
+
+// CreateProtocol creates a vaccination protocol.
+func (h *VeterinaryHandler) CreateProtocol(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req veterinary.CreateProtocolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	protocol, err := h.useCase.CreateProtocol(c.Request.Context(), &req, *userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, protocol)
+}
+
+// UpdateProtocol updates a vaccination protocol.
+func (h *VeterinaryHandler) UpdateProtocol(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	protocolID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid protocol ID"})
+		return
+	}
+
+	var req veterinary.UpdateProtocolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	protocol, err := h.useCase.UpdateProtocol(c.Request.Context(), protocolID, &req, *userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, protocol)
+}
+
+// DeleteProtocol deletes a vaccination protocol.
+func (h *VeterinaryHandler) DeleteProtocol(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	protocolID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid protocol ID"})
+		return
+	}
+
+	if err := h.useCase.DeleteProtocol(c.Request.Context(), protocolID, *userID); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "protocol deleted"})
+}
+
+// GetVaccinationSchedule returns the vaccination schedule and compliance status of an animal.
+func (h *VeterinaryHandler) GetVaccinationSchedule(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	compliance, err := h.useCase.GetVaccinationCompliance(c.Request.Context(), animalID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, compliance)
+}
+
+// RegenerateVaccinationSchedule rebuilds the pending doses of an animal from the current protocols.
+func (h *VeterinaryHandler) RegenerateVaccinationSchedule(c *gin.Context) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	compliance, err := h.useCase.RegenerateVaccinationSchedule(c.Request.Context(), animalID, *userID)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, compliance)
+}
+
+// GetScheduledVaccinations lists protocol doses across animals that are overdue or due within provided days.
+func (h *VeterinaryHandler) GetScheduledVaccinations(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days parameter"})
+		return
+	}
+
+	items, err := h.useCase.GetScheduledVaccinations(c.Request.Context(), days)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedule": items,
+		"total":    len(items),
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/middleware"
+	"github.com/sainaif/animalsys/backend/internal/usecase/vitals"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VitalsHandler serves weight and vital-sign time series
+type VitalsHandler struct {
+	vitalsUseCase *vitals.VitalsUseCase
+	validate      *validator.Validate
+}
+
+// NewVitalsHandler creates a new vitals handler
+func NewVitalsHandler(vitalsUseCase *vitals.VitalsUseCase) *VitalsHandler {
+	return &VitalsHandler{
+		vitalsUseCase: vitalsUseCase,
+		validate:      validator.New(),
+	}
+}
+
+// ListReadings lists the weight and vitals readings of an animal
+func (h *VitalsHandler) ListReadings(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	filter := &repositories.VitalSignFilter{
+		AnimalID:  &animalID,
+		Source:    c.Query("source"),
+		Metric:    c.Query("metric"),
+		SortOrder: c.Query("sort_order"),
+		Limit:     50,
+	}
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		filter.From = &date
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		endOfDay := date.Add(24*time.Hour - time.Nanosecond)
+		filter.To = &endOfDay
+	}
+	if limit, err := strconv.ParseInt(c.Query("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	if offset, err := strconv.ParseInt(c.Query("offset"), 10, 64); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+
+	readings, total, err := h.vitalsUseCase.ListReadings(c.Request.Context(), filter)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"readings": readings,
+		"total":    total,
+		"limit":    filter.Limit,
+		"offset":   filter.Offset,
+	})
+}
+
+// GetTrend returns the trend of one metric with its species reference range
+func (h *VitalsHandler) GetTrend(c *gin.Context) {
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	metric := entities.VitalMetric(c.DefaultQuery("metric", string(entities.VitalMetricWeight)))
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "90"))
+
+	trend, err := h.vitalsUseCase.GetTrend(c.Request.Context(), animalID, metric, days)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trend)
+}
+
+// RecordReading records weight and vitals for an animal
+func (h *VitalsHandler) RecordReading(c *gin.Context) {
+	h.record(c, h.vitalsUseCase.RecordReading)
+}
+
+// RecordFosterCheckIn records weight and vitals reported by the animal's foster
+func (h *VitalsHandler) RecordFosterCheckIn(c *gin.Context) {
+	h.record(c, h.vitalsUseCase.RecordFosterCheckIn)
+}
+
+func (h *VitalsHandler) record(c *gin.Context, record func(ctx context.Context, animalID primitive.ObjectID, req *vitals.RecordReadingRequest, userID primitive.ObjectID) (*entities.VitalSignReading, error)) {
+	userID, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	animalID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid animal ID"})
+		return
+	}
+
+	var req vitals.RecordReadingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reading, err := record(c.Request.Context(), animalID, &req, *userID)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reading)
+}
+
+// GetReferenceRanges returns the normal vital ranges per species
+func (h *VitalsHandler) GetReferenceRanges(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reference_ranges": h.vitalsUseCase.GetReferenceRanges()})
+}
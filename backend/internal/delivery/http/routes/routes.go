@@ -37,6 +37,20 @@ func SetupRoutes(
 	monitoringHandler *handlers.MonitoringHandler,
 	medicalHandler *handlers.MedicalHandler,
 	batchHandler *handlers.BatchHandler,
+	searchHandler *handlers.SearchHandler,
+	vitalsHandler *handlers.VitalsHandler,
+	labHandler *handlers.LabHandler,
+	appointmentHandler *handlers.AppointmentHandler,
+	billingHandler *handlers.BillingHandler,
+	packetHandler *handlers.PacketHandler,
+	quarantineHandler *handlers.QuarantineHandler,
+	sterilizationHandler *handlers.SterilizationHandler,
+	contractHandler *handlers.ContractHandler,
+	adopterHandler *handlers.AdopterHandler,
+	followUpHandler *handlers.FollowUpHandler,
+	ticketHandler *handlers.TicketHandler,
+	eventSeriesHandler *handlers.EventSeriesHandler,
+	eventOutcomeHandler *handlers.EventOutcomeHandler,
 	jwtService *security.JWTService,
 	userRepo repositories.UserRepository,
 ) {
@@ -56,12 +70,42 @@ func SetupRoutes(
 		}
 
 		public.POST("/public/donations", donationHandler.CreatePublicDonation)
+
+		// iCalendar subscriptions; the secret token in the URL authenticates calendar apps
+		public.GET("/public/calendar/:token", appointmentHandler.GetCalendarFeed)
+
+		// Adoption contract signing; the token in the link sent to the adopter authenticates them
+		public.GET("/public/contracts/:token", contractHandler.ViewSigningContract)
+		public.POST("/public/contracts/:token/sign", contractHandler.SignContract)
+
+		// Post-adoption follow-up surveys; the token in the link sent to the adopter authenticates them
+		public.GET("/public/follow-ups/:token", followUpHandler.ViewSurvey)
+		public.POST("/public/follow-ups/:token/photos", followUpHandler.UploadSurveyPhotos)
+		public.POST("/public/follow-ups/:token", followUpHandler.SubmitSurvey)
+
+		// Public event registration; the signed code of a ticket authenticates its holder
+		public.GET("/public/events", ticketHandler.ListPublicEvents)
+		public.GET("/public/events/:id", ticketHandler.GetPublicEvent)
+		public.POST("/public/events/:id/register", ticketHandler.Register)
+		public.GET("/public/tickets/:code", ticketHandler.ViewTicket)
+		public.GET("/public/tickets/:code/qr", ticketHandler.GetTicketQRCode)
+		public.POST("/public/tickets/:code/checkout", ticketHandler.Checkout)
+
+		// Event feedback surveys; the token in the link sent to the attendee authenticates them
+		public.GET("/public/event-feedback/:token", eventOutcomeHandler.ViewFeedback)
+		public.POST("/public/event-feedback/:token", eventOutcomeHandler.SubmitFeedback)
+
+		// Payment gateway notifications; authenticated by the signature of the gateway
+		public.POST("/public/payments/webhook", ticketHandler.PaymentWebhook)
 	}
 
 	// Protected routes (authentication required)
 	protected := router.Group("/api/v1")
 	protected.Use(middleware.AuthMiddleware(jwtService, userRepo))
 	{
+		// Global search (results are filtered by the permissions of the user)
+		protected.GET("/search", searchHandler.Search)
+
 		// Auth routes (protected)
 		auth := protected.Group("/auth")
 		{
@@ -136,6 +180,17 @@ func SetupRoutes(
 				animalHandler.GetAnimal,
 			)
 
+			// Microchip checks and registry lookups
+			animals.GET("/microchip/:number/check",
+				middleware.RequirePermission(middleware.PermissionViewAnimals),
+				animalHandler.CheckMicrochip,
+			)
+
+			animals.GET("/microchip/:number/owner",
+				middleware.RequirePermission(middleware.PermissionViewAnimals),
+				animalHandler.LookupMicrochipOwner,
+			)
+
 			// Create animal (employees and above)
 			animals.POST("",
 				middleware.RequirePermission(middleware.PermissionCreateAnimals),
@@ -160,12 +215,28 @@ func SetupRoutes(
 				animalHandler.UploadAnimalImages,
 			)
 
+			animals.PUT("/:id/images/order",
+				middleware.RequirePermission(middleware.PermissionUpdateAnimals),
+				animalHandler.ReorderAnimalImages,
+			)
+
+			animals.DELETE("/:id/images/:imageId",
+				middleware.RequirePermission(middleware.PermissionUpdateAnimals),
+				animalHandler.DeleteAnimalImage,
+			)
+
 			// Add daily note (employees and above)
 			animals.POST("/:id/notes",
 				middleware.RequirePermission(middleware.PermissionUpdateAnimals),
 				animalHandler.AddDailyNote,
 			)
 
+			// Register microchip owner (employees and above)
+			animals.POST("/:id/microchip/registration",
+				middleware.RequirePermission(middleware.PermissionUpdateAnimals),
+				animalHandler.RegisterMicrochipOwner,
+			)
+
 			// Animal-specific veterinary routes
 			animals.GET("/:id/visits",
 				middleware.RequirePermission(middleware.PermissionViewVeterinary),
@@ -176,6 +247,82 @@ func SetupRoutes(
 				middleware.RequirePermission(middleware.PermissionViewVeterinary),
 				veterinaryHandler.GetVaccinationsByAnimal,
 			)
+
+			animals.GET("/:id/vaccination-schedule",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				veterinaryHandler.GetVaccinationSchedule,
+			)
+
+			animals.POST("/:id/vaccination-schedule/regenerate",
+				middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+				veterinaryHandler.RegenerateVaccinationSchedule,
+			)
+
+			animals.GET("/:id/cost-of-care",
+				middleware.RequirePermission(middleware.PermissionViewInventory),
+				inventoryHandler.GetAnimalCostOfCare,
+			)
+
+			// Weight and vital-sign trends
+			animals.GET("/:id/vitals",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				vitalsHandler.ListReadings,
+			)
+
+			animals.GET("/:id/vitals/trend",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				vitalsHandler.GetTrend,
+			)
+
+			animals.POST("/:id/vitals",
+				middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+				vitalsHandler.RecordReading,
+			)
+
+			// Fosters report weight and vitals; the use case checks the fostering assignment
+			animals.POST("/:id/foster-check-ins",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				vitalsHandler.RecordFosterCheckIn,
+			)
+
+			// Lab panels
+			animals.GET("/:id/lab-panels",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				labHandler.ListAnimalPanels,
+			)
+
+			animals.GET("/:id/lab-panels/compare",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				labHandler.CompareAnalytes,
+			)
+
+			// Medical costs from clinic invoices and visits
+			animals.GET("/:id/medical-expenditure",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				billingHandler.GetAnimalExpenditure,
+			)
+
+			// Printable medical history and adoption packet
+			animals.GET("/:id/medical-packet",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				packetHandler.GetPacket,
+			)
+
+			animals.POST("/:id/medical-packet",
+				middleware.RequirePermission(middleware.PermissionCreateDocuments),
+				packetHandler.CreatePacket,
+			)
+
+			// Quarantine
+			animals.GET("/:id/quarantine",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				quarantineHandler.GetAnimalQuarantine,
+			)
+
+			animals.POST("/:id/quarantine",
+				middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+				quarantineHandler.StartQuarantine,
+			)
 		}
 
 		// Veterinary management routes
@@ -233,6 +380,11 @@ func SetupRoutes(
 					veterinaryHandler.GetDueVaccinations,
 				)
 
+				vaccinations.GET("/scheduled",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					veterinaryHandler.GetScheduledVaccinations,
+				)
+
 				vaccinations.GET("/:id",
 					middleware.RequirePermission(middleware.PermissionViewVeterinary),
 					veterinaryHandler.GetVaccination,
@@ -251,6 +403,299 @@ func SetupRoutes(
 				)
 			}
 
+			veterinary.GET("/vitals/reference-ranges",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				vitalsHandler.GetReferenceRanges,
+			)
+
+			// Lab panel routes
+			labPanels := veterinary.Group("/lab-panels")
+			{
+				labPanels.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					labHandler.ListPanels,
+				)
+
+				labPanels.GET("/:id",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					labHandler.GetPanel,
+				)
+
+				labPanels.POST("",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					labHandler.CreatePanel,
+				)
+
+				// Import a CSV or HL7 results file from a partner clinic or lab
+				labPanels.POST("/import",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					labHandler.ImportResults,
+				)
+
+				labPanels.PUT("/:id",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					labHandler.UpdatePanel,
+				)
+
+				labPanels.POST("/:id/document",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					labHandler.AttachDocument,
+				)
+
+				labPanels.DELETE("/:id",
+					middleware.RequirePermission(middleware.PermissionDeleteVeterinary),
+					labHandler.DeletePanel,
+				)
+			}
+
+			// Lab analyte and reference range routes
+			labAnalytes := veterinary.Group("/lab-analytes")
+			{
+				labAnalytes.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					labHandler.ListAnalytes,
+				)
+
+				labAnalytes.POST("",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					labHandler.CreateAnalyte,
+				)
+
+				labAnalytes.PUT("/:id",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					labHandler.UpdateAnalyte,
+				)
+			}
+
+			// Partner clinic schedule routes
+			clinics := veterinary.Group("/clinics/:partnerId")
+			{
+				clinics.GET("/schedule",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.GetSchedule,
+				)
+
+				clinics.PUT("/schedule",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					appointmentHandler.UpdateSchedule,
+				)
+
+				clinics.GET("/slots",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.GetAvailableSlots,
+				)
+
+				clinics.GET("/balance",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					billingHandler.GetClinicStatement,
+				)
+			}
+
+			// Clinic appointment routes
+			appointments := veterinary.Group("/appointments")
+			{
+				appointments.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.ListAppointments,
+				)
+
+				appointments.POST("",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					appointmentHandler.BookAppointment,
+				)
+
+				appointments.POST("/check",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.CheckConflicts,
+				)
+
+				appointments.PUT("/:id/reschedule",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					appointmentHandler.RescheduleAppointment,
+				)
+
+				appointments.POST("/:id/cancel",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					appointmentHandler.CancelAppointment,
+				)
+			}
+
+			// Calendar feed subscription routes
+			calendarFeeds := veterinary.Group("/calendar-feeds")
+			{
+				calendarFeeds.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.ListCalendarFeeds,
+				)
+
+				calendarFeeds.POST("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.CreateCalendarFeed,
+				)
+
+				calendarFeeds.DELETE("/:id",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					appointmentHandler.RevokeCalendarFeed,
+				)
+			}
+
+			// Clinic invoice routes
+			invoices := veterinary.Group("/invoices")
+			{
+				invoices.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					billingHandler.ListInvoices,
+				)
+
+				invoices.GET("/:id",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					billingHandler.GetInvoice,
+				)
+
+				invoices.POST("",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					billingHandler.CreateInvoice,
+				)
+
+				invoices.PUT("/:id",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					billingHandler.UpdateInvoice,
+				)
+
+				invoices.DELETE("/:id",
+					middleware.RequirePermission(middleware.PermissionDeleteVeterinary),
+					billingHandler.DeleteInvoice,
+				)
+
+				invoices.POST("/:id/void",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					billingHandler.VoidInvoice,
+				)
+
+				invoices.PUT("/:id/lines/:lineId/match",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					billingHandler.MatchLine,
+				)
+
+				invoices.POST("/:id/payments",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					billingHandler.RecordPayment,
+				)
+
+				invoices.DELETE("/:id/payments/:paymentId",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					billingHandler.DeletePayment,
+				)
+
+				// Spending restricted donations is a donations decision
+				invoices.POST("/:id/fund-charges",
+					middleware.RequirePermission(middleware.PermissionUpdateDonations),
+					billingHandler.ChargeToMedicalFund,
+				)
+
+				invoices.DELETE("/:id/fund-charges/:chargeId",
+					middleware.RequirePermission(middleware.PermissionUpdateDonations),
+					billingHandler.ReleaseFundCharge,
+				)
+			}
+
+			veterinary.GET("/clinic-balances",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				billingHandler.GetClinicBalances,
+			)
+
+			veterinary.GET("/medical-fund",
+				middleware.RequirePermission(middleware.PermissionViewDonations),
+				billingHandler.GetMedicalFund,
+			)
+
+			veterinary.GET("/expenditures",
+				middleware.RequirePermission(middleware.PermissionViewReports),
+				billingHandler.GetExpenditureReport,
+			)
+
+			// Quarantine routes
+			quarantines := veterinary.Group("/quarantines")
+			{
+				quarantines.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					quarantineHandler.ListQuarantines,
+				)
+
+				quarantines.GET("/:id",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					quarantineHandler.GetQuarantine,
+				)
+
+				quarantines.PUT("/:id/extend",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					quarantineHandler.ExtendQuarantine,
+				)
+
+				quarantines.POST("/:id/release",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					quarantineHandler.ReleaseQuarantine,
+				)
+			}
+
+			// Outbreak routes
+			outbreaks := veterinary.Group("/outbreaks")
+			{
+				outbreaks.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					quarantineHandler.ListOutbreaks,
+				)
+
+				outbreaks.GET("/:id",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					quarantineHandler.GetOutbreak,
+				)
+
+				outbreaks.POST("",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					quarantineHandler.DeclareOutbreak,
+				)
+
+				outbreaks.POST("/:id/cases",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					quarantineHandler.AddOutbreakCases,
+				)
+
+				outbreaks.POST("/:id/resolve",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					quarantineHandler.ResolveOutbreak,
+				)
+			}
+
+			// Vaccination protocol routes
+			protocols := veterinary.Group("/vaccination-protocols")
+			{
+				protocols.GET("",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					veterinaryHandler.ListProtocols,
+				)
+
+				protocols.GET("/:id",
+					middleware.RequirePermission(middleware.PermissionViewVeterinary),
+					veterinaryHandler.GetProtocol,
+				)
+
+				protocols.POST("",
+					middleware.RequirePermission(middleware.PermissionCreateVeterinary),
+					veterinaryHandler.CreateProtocol,
+				)
+
+				protocols.PUT("/:id",
+					middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+					veterinaryHandler.UpdateProtocol,
+				)
+
+				protocols.DELETE("/:id",
+					middleware.RequirePermission(middleware.PermissionDeleteVeterinary),
+					veterinaryHandler.DeleteProtocol,
+				)
+			}
+
 			// Veterinary records routes
 			veterinary.GET("/records",
 				middleware.RequirePermission(middleware.PermissionViewVeterinary),
@@ -302,6 +747,24 @@ func SetupRoutes(
 					adoptionHandler.DeleteApplication,
 				)
 
+				// Review pipeline of the application
+				applications.GET("/:id/review",
+					middleware.RequirePermission(middleware.PermissionViewAdoptions),
+					adoptionHandler.GetReview,
+				)
+				applications.PUT("/:id/review/stages/:stage",
+					middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+					adoptionHandler.UpdateStage,
+				)
+				applications.POST("/:id/review/stages/:stage/complete",
+					middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+					adoptionHandler.CompleteStage,
+				)
+				applications.PUT("/:id/references/:index",
+					middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+					adoptionHandler.UpdateReference,
+				)
+
 				// Schedule visit for application
 				applications.POST("/:id/schedule-visit",
 					middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
@@ -349,9 +812,86 @@ func SetupRoutes(
 				adoptionHandler.GetPendingFollowUps,
 			)
 
+			// Follow-up survey response rates and the messages carrying the survey link
+			adoptions.GET("/follow-ups/report",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				followUpHandler.GetResponseReport,
+			)
+			adoptions.GET("/follow-ups/settings",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				followUpHandler.GetSettings,
+			)
+			adoptions.PUT("/follow-ups/settings",
+				middleware.RequirePermission(middleware.PermissionUpdateSettings),
+				followUpHandler.UpdateSettings,
+			)
+
+			// Application review pipeline configuration and stage timings
+			adoptions.GET("/review-pipeline",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adoptionHandler.GetReviewConfig,
+			)
+			adoptions.PUT("/review-pipeline",
+				middleware.RequirePermission(middleware.PermissionUpdateSettings),
+				adoptionHandler.UpdateReviewConfig,
+			)
+			adoptions.GET("/review-pipeline/report",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adoptionHandler.GetReviewReport,
+			)
+
+			// Return policy and return analytics
+			adoptions.GET("/return-policy",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adoptionHandler.GetReturnPolicy,
+			)
+			adoptions.PUT("/return-policy",
+				middleware.RequirePermission(middleware.PermissionUpdateSettings),
+				adoptionHandler.UpdateReturnPolicy,
+			)
+			adoptions.GET("/returns/report",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adoptionHandler.GetReturnReport,
+			)
+
+			// Adoption contracts and their templates
 			adoptions.GET("/contracts",
 				middleware.RequirePermission(middleware.PermissionViewAdoptions),
-				adoptionHandler.GetContracts,
+				contractHandler.ListContracts,
+			)
+			adoptions.GET("/contracts/:id",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				contractHandler.GetContract,
+			)
+			adoptions.POST("/contracts/:id/renew",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				contractHandler.RenewLink,
+			)
+			adoptions.POST("/contracts/:id/void",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				contractHandler.VoidContract,
+			)
+			adoptions.POST("/contracts/:id/verify",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				contractHandler.VerifyContract,
+			)
+			adoptions.GET("/contract-templates",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				contractHandler.ListTemplates,
+			)
+			adoptions.GET("/contract-templates/:id",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				contractHandler.GetTemplate,
+			)
+			adoptions.POST("/contract-templates",
+				middleware.RequirePermission(middleware.PermissionUpdateSettings),
+				contractHandler.CreateTemplate,
+			)
+
+			// Spay/neuter compliance report for the municipal contract
+			adoptions.GET("/sterilization/report",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				sterilizationHandler.GetComplianceReport,
 			)
 
 			adoptions.GET("/:id",
@@ -382,6 +922,40 @@ func SetupRoutes(
 				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
 				adoptionHandler.FinalizeAdoption,
 			)
+
+			// Send the survey of a follow-up to the adopter
+			adoptions.POST("/:id/follow-ups/:index/send",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				followUpHandler.SendFollowUp,
+			)
+
+			// Links to the photos the adopter uploaded with a follow-up survey
+			adoptions.GET("/:id/follow-ups/:index/photos",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				followUpHandler.GetPhotos,
+			)
+
+			// Record the return of the animal
+			adoptions.POST("/:id/return",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				adoptionHandler.ReturnAdoption,
+			)
+
+			// Generate the contract and its signing link
+			adoptions.POST("/:id/contract",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				contractHandler.GenerateContract,
+			)
+
+			// Spay/neuter of animals adopted intact
+			adoptions.POST("/:id/sterilization",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				sterilizationHandler.RecordSterilization,
+			)
+			adoptions.POST("/:id/sterilization/waive",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				sterilizationHandler.WaiveSterilization,
+			)
 		}
 
 		// Animal-specific adoption routes
@@ -395,6 +969,35 @@ func SetupRoutes(
 			adoptionHandler.GetAdoptionByAnimal,
 		)
 
+		// Adopter records matched from applicants
+		adopters := protected.Group("/adopters")
+		{
+			adopters.GET("",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adopterHandler.ListAdopters,
+			)
+			adopters.GET("/:id",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adopterHandler.GetAdopter,
+			)
+			adopters.GET("/:id/history",
+				middleware.RequirePermission(middleware.PermissionViewAdoptions),
+				adopterHandler.GetHistory,
+			)
+			adopters.PUT("/:id",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				adopterHandler.UpdateAdopter,
+			)
+			adopters.POST("/:id/do-not-adopt",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				adopterHandler.SetDoNotAdopt,
+			)
+			adopters.DELETE("/:id/do-not-adopt",
+				middleware.RequirePermission(middleware.PermissionUpdateAdoptions),
+				adopterHandler.ClearDoNotAdopt,
+			)
+		}
+
 		// Donor management routes
 		donors := protected.Group("/donors")
 		{
@@ -764,12 +1367,29 @@ func SetupRoutes(
 				eventHandler.UnassignVolunteer,
 			)
 
+			// Animals brought to the event; quarantined animals are refused
+			events.POST("/:id/assign-animal",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventHandler.AssignAnimal,
+			)
+
+			events.POST("/:id/unassign-animal",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventHandler.UnassignAnimal,
+			)
+
 			// Register for event
 			events.POST("/:id/register",
 				middleware.RequirePermission(middleware.PermissionUpdateEvents),
 				eventHandler.RegisterForEvent,
 			)
 
+			// Cancel a registration; frees its seats for the waitlist
+			events.POST("/:id/registrations/:attendanceId/cancel",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventHandler.CancelRegistration,
+			)
+
 			// Get event registrations
 			events.GET("/:id/registrations",
 				middleware.RequirePermission(middleware.PermissionViewEvents),
@@ -799,6 +1419,90 @@ func SetupRoutes(
 				middleware.RequirePermission(middleware.PermissionViewEvents),
 				eventHandler.GetEventAttendance,
 			)
+
+			// Email the ticket of a registration again
+			events.POST("/:id/registrations/:attendanceId/ticket",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				ticketHandler.SendTicket,
+			)
+
+			// Check in a scanned ticket
+			events.POST("/:id/check-in",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				ticketHandler.CheckIn,
+			)
+
+			// Sync the check-ins of a scanner that was offline
+			events.POST("/:id/check-in/sync",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				ticketHandler.SyncCheckIns,
+			)
+
+			// Download the attendee list for offline check-in (?format=csv for a spreadsheet)
+			events.GET("/:id/check-in/list",
+				middleware.RequirePermission(middleware.PermissionViewEvents),
+				ticketHandler.GetCheckInList,
+			)
+
+			// Costs against funds raised, adoptions, attendance and feedback
+			events.GET("/:id/report",
+				middleware.RequirePermission(middleware.PermissionViewEvents),
+				eventOutcomeHandler.GetEventReport,
+			)
+
+			// Compute the funds raised, adoptions and volunteer hours now
+			events.POST("/:id/outcomes/refresh",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventOutcomeHandler.RefreshOutcomes,
+			)
+
+			// Send the feedback survey to the attendees now
+			events.POST("/:id/feedback-requests",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventOutcomeHandler.SendFeedbackRequests,
+			)
+		}
+
+		// Recurring events
+		eventSeries := protected.Group("/event-series")
+		{
+			eventSeries.GET("",
+				middleware.RequirePermission(middleware.PermissionViewEvents),
+				eventSeriesHandler.ListSeries,
+			)
+
+			eventSeries.GET("/:id",
+				middleware.RequirePermission(middleware.PermissionViewEvents),
+				eventSeriesHandler.GetSeries,
+			)
+
+			eventSeries.GET("/:id/occurrences",
+				middleware.RequirePermission(middleware.PermissionViewEvents),
+				eventSeriesHandler.ListOccurrences,
+			)
+
+			eventSeries.GET("/:id/statistics",
+				middleware.RequirePermission(middleware.PermissionViewEvents),
+				eventSeriesHandler.GetSeriesStatistics,
+			)
+
+			// Create a series with its occurrences
+			eventSeries.POST("",
+				middleware.RequirePermission(middleware.PermissionCreateEvents),
+				eventSeriesHandler.CreateSeries,
+			)
+
+			// Change the series, or this occurrence and the following ones (?from_event_id=)
+			eventSeries.PUT("/:id",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventSeriesHandler.UpdateSeries,
+			)
+
+			// End the series and cancel its upcoming occurrences
+			eventSeries.POST("/:id/cancel",
+				middleware.RequirePermission(middleware.PermissionUpdateEvents),
+				eventSeriesHandler.CancelSeries,
+			)
 		}
 
 		// Volunteer management routes
@@ -1542,12 +2246,24 @@ func SetupRoutes(
 				documentHandler.DownloadDocument,
 			)
 
+			// Stream document file (supports range requests)
+			documents.GET("/:id/file",
+				middleware.RequirePermission(middleware.PermissionViewDocuments),
+				documentHandler.StreamDocument,
+			)
+
 			// Create document
 			documents.POST("",
 				middleware.RequirePermission(middleware.PermissionCreateDocuments),
 				documentHandler.CreateDocument,
 			)
 
+			// Upload document file
+			documents.POST("/upload",
+				middleware.RequirePermission(middleware.PermissionCreateDocuments),
+				documentHandler.UploadDocument,
+			)
+
 			// Create new version
 			documents.POST("/:id/versions",
 				middleware.RequirePermission(middleware.PermissionUpdateDocuments),
@@ -2154,6 +2870,30 @@ func SetupRoutes(
 				medicalHandler.GetExpiringSoonMedications,
 			)
 
+			// Get doses due now
+			medications.GET("/due-now",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				medicalHandler.GetDosesDueNow,
+			)
+
+			// Get dose slots of a shift
+			medications.GET("/doses",
+				middleware.RequirePermission(middleware.PermissionViewVeterinary),
+				medicalHandler.GetShiftDoses,
+			)
+
+			// Witness a controlled substance dose
+			medications.POST("/doses/:id/witness",
+				middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+				medicalHandler.WitnessDose,
+			)
+
+			// Skip a dose
+			medications.POST("/doses/:id/skip",
+				middleware.RequirePermission(middleware.PermissionUpdateVeterinary),
+				medicalHandler.SkipDose,
+			)
+
 			// Get medication by ID
 			medications.GET("/:id",
 				middleware.RequirePermission(middleware.PermissionViewVeterinary),
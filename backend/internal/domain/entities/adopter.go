@@ -0,0 +1,100 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// phoneKeyDigits is the number of trailing digits compared when matching phone numbers,
+// so that numbers written with and without the country code match
+const phoneKeyDigits = 9
+
+// DoNotAdoptFlag blocks a person from adopting
+type DoNotAdoptFlag struct {
+	Reason string             `json:"reason" bson:"reason"`
+	SetBy  primitive.ObjectID `json:"set_by" bson:"set_by"`
+	SetAt  time.Time          `json:"set_at" bson:"set_at"`
+}
+
+// Adopter is a person who applied to adopt or adopted an animal. Applicants are matched
+// to an existing adopter by email or phone so that one person has one record.
+type Adopter struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	FirstName string              `json:"first_name" bson:"first_name"`
+	LastName  string              `json:"last_name" bson:"last_name"`
+	Email     string              `json:"email,omitempty" bson:"email,omitempty"`
+	Phone     string              `json:"phone,omitempty" bson:"phone,omitempty"`
+	EmailKey  string              `json:"-" bson:"email_key,omitempty"`
+	PhoneKey  string              `json:"-" bson:"phone_key,omitempty"`
+	Address   *AddressInfo        `json:"address,omitempty" bson:"address,omitempty"`
+	ContactID *primitive.ObjectID `json:"contact_id,omitempty" bson:"contact_id,omitempty"`
+
+	DoNotAdopt *DoNotAdoptFlag `json:"do_not_adopt,omitempty" bson:"do_not_adopt,omitempty"`
+
+	Notes     string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NewAdopter creates an adopter from the applicant of an application
+func NewAdopter(applicant ApplicantInfo, address AddressInfo, createdBy primitive.ObjectID) *Adopter {
+	now := time.Now()
+	adopter := &Adopter{
+		ID:        primitive.NewObjectID(),
+		FirstName: strings.TrimSpace(applicant.FirstName),
+		LastName:  strings.TrimSpace(applicant.LastName),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	adopter.SetEmail(applicant.Email)
+	adopter.SetPhone(applicant.Phone)
+	if address != (AddressInfo{}) {
+		adopter.Address = &address
+	}
+	return adopter
+}
+
+// FullName returns the first and last name
+func (a *Adopter) FullName() string {
+	return strings.TrimSpace(a.FirstName + " " + a.LastName)
+}
+
+// SetEmail sets the email and its matching key
+func (a *Adopter) SetEmail(email string) {
+	a.Email = strings.TrimSpace(email)
+	a.EmailKey = NormalizeEmail(email)
+}
+
+// SetPhone sets the phone and its matching key
+func (a *Adopter) SetPhone(phone string) {
+	a.Phone = strings.TrimSpace(phone)
+	a.PhoneKey = NormalizePhone(phone)
+}
+
+// IsBlocked reports whether the person is flagged do-not-adopt
+func (a *Adopter) IsBlocked() bool {
+	return a.DoNotAdopt != nil
+}
+
+// NormalizeEmail returns the key emails are matched on
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone returns the key phone numbers are matched on: the last nine digits
+func NormalizePhone(phone string) string {
+	digits := make([]byte, 0, len(phone))
+	for i := 0; i < len(phone); i++ {
+		if phone[i] >= '0' && phone[i] <= '9' {
+			digits = append(digits, phone[i])
+		}
+	}
+	if len(digits) > phoneKeyDigits {
+		digits = digits[len(digits)-phoneKeyDigits:]
+	}
+	return string(digits)
+}
@@ -31,9 +31,19 @@ const (
 type FollowUpSchedule struct {
 	ScheduledDate time.Time          `json:"scheduled_date" bson:"scheduled_date"`
 	CompletedDate *time.Time         `json:"completed_date,omitempty" bson:"completed_date,omitempty"`
-	Type          string             `json:"type" bson:"type"` // phone, email, visit
+	Type          string             `json:"type" bson:"type"` // phone, email, visit, survey
 	Notes         string             `json:"notes,omitempty" bson:"notes,omitempty"`
 	CompletedBy   *primitive.ObjectID `json:"completed_by,omitempty" bson:"completed_by,omitempty"`
+
+	// Survey sent to the adopter when the follow-up is due
+	SentAt           *time.Time          `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+	Channel          TemplateType        `json:"channel,omitempty" bson:"channel,omitempty"`
+	CommunicationID  *primitive.ObjectID `json:"communication_id,omitempty" bson:"communication_id,omitempty"`
+	TokenHash        string              `json:"-" bson:"token_hash,omitempty"`
+	TokenExpiresAt   *time.Time          `json:"token_expires_at,omitempty" bson:"token_expires_at,omitempty"`
+	Photos           []string            `json:"photos,omitempty" bson:"photos,omitempty"` // Uploaded by the adopter with the survey
+	Survey           *FollowUpSurvey     `json:"survey,omitempty" bson:"survey,omitempty"`
+	EscalationTaskID *primitive.ObjectID `json:"escalation_task_id,omitempty" bson:"escalation_task_id,omitempty"`
 }
 
 // AdoptionContract represents contract details
@@ -44,6 +54,10 @@ type AdoptionContract struct {
 	WitnessName      string     `json:"witness_name,omitempty" bson:"witness_name,omitempty"`
 	WitnessSignature string     `json:"witness_signature,omitempty" bson:"witness_signature,omitempty"`
 	Terms            []string   `json:"terms,omitempty" bson:"terms,omitempty"`
+
+	// The electronically signed contract and its PDF, when signed through a signing link
+	ContractID *primitive.ObjectID `json:"contract_id,omitempty" bson:"contract_id,omitempty"`
+	DocumentID *primitive.ObjectID `json:"document_id,omitempty" bson:"document_id,omitempty"`
 }
 
 // Adoption represents a completed or in-progress adoption
@@ -53,11 +67,12 @@ type Adoption struct {
 	// References
 	ApplicationID primitive.ObjectID  `json:"application_id" bson:"application_id"`
 	AnimalID      primitive.ObjectID  `json:"animal_id" bson:"animal_id"`
-	AdopterID     primitive.ObjectID  `json:"adopter_id" bson:"adopter_id"` // User ID of adopter
+	AdopterID     primitive.ObjectID  `json:"adopter_id" bson:"adopter_id"` // Adopter record of the applicant
 
 	// Adoption Details
 	Status       AdoptionStatus `json:"status" bson:"status"`
 	AdoptionDate time.Time      `json:"adoption_date" bson:"adoption_date"`
+	CompletedDate *time.Time    `json:"completed_date,omitempty" bson:"completed_date,omitempty"` // When the adoption was finalized
 	TrialPeriod  bool           `json:"trial_period" bson:"trial_period"`
 	TrialEndDate *time.Time     `json:"trial_end_date,omitempty" bson:"trial_end_date,omitempty"`
 
@@ -76,6 +91,9 @@ type Adoption struct {
 	AgreesToMedicalCare   bool             `json:"agrees_to_medical_care" bson:"agrees_to_medical_care"`
 	AgreesToFollowUp      bool             `json:"agrees_to_follow_up" bson:"agrees_to_follow_up"`
 
+	// Spay/neuter compliance, set when the animal is adopted intact
+	Sterilization *SterilizationRequirement `json:"sterilization,omitempty" bson:"sterilization,omitempty"`
+
 	// Follow-up Information
 	FollowUpSchedule []FollowUpSchedule `json:"follow_up_schedule,omitempty" bson:"follow_up_schedule,omitempty"`
 	NextFollowUpDate *time.Time         `json:"next_follow_up_date,omitempty" bson:"next_follow_up_date,omitempty"`
@@ -84,6 +102,10 @@ type Adoption struct {
 	ReturnDate   *time.Time `json:"return_date,omitempty" bson:"return_date,omitempty"`
 	ReturnReason string     `json:"return_reason,omitempty" bson:"return_reason,omitempty"`
 	ReturnNotes  string     `json:"return_notes,omitempty" bson:"return_notes,omitempty"`
+	Return       *AdoptionReturn `json:"return,omitempty" bson:"return,omitempty"` // Structured return record
+
+	// Staff task asking to finalize or return the animal when the trial ends
+	TrialTaskID *primitive.ObjectID `json:"trial_task_id,omitempty" bson:"trial_task_id,omitempty"`
 
 	// Additional Information
 	Notes       string   `json:"notes,omitempty" bson:"notes,omitempty"`
@@ -99,8 +99,21 @@ type Reference struct {
 	Email        string `json:"email,omitempty" bson:"email,omitempty"`
 	Contacted    bool   `json:"contacted" bson:"contacted"`
 	Notes        string `json:"notes,omitempty" bson:"notes,omitempty"`
+	ContactedAt  *time.Time          `json:"contacted_at,omitempty" bson:"contacted_at,omitempty"`
+	ContactedBy  *primitive.ObjectID `json:"contacted_by,omitempty" bson:"contacted_by,omitempty"`
+	Outcome      ReferenceOutcome    `json:"outcome,omitempty" bson:"outcome,omitempty"`
 }
 
+// ReferenceOutcome represents what a reference said about the applicant
+type ReferenceOutcome string
+
+const (
+	ReferenceOutcomePositive    ReferenceOutcome = "positive"
+	ReferenceOutcomeNeutral     ReferenceOutcome = "neutral"
+	ReferenceOutcomeNegative    ReferenceOutcome = "negative"
+	ReferenceOutcomeUnreachable ReferenceOutcome = "unreachable"
+)
+
 // AdoptionApplication represents an adoption application
 type AdoptionApplication struct {
 	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -109,8 +122,9 @@ type AdoptionApplication struct {
 	AnimalID primitive.ObjectID `json:"animal_id" bson:"animal_id"`
 
 	// Applicant Information
-	Applicant ApplicantInfo `json:"applicant" bson:"applicant"`
-	Address   AddressInfo   `json:"address" bson:"address"`
+	Applicant ApplicantInfo       `json:"applicant" bson:"applicant"`
+	Address   AddressInfo         `json:"address" bson:"address"`
+	AdopterID *primitive.ObjectID `json:"adopter_id,omitempty" bson:"adopter_id,omitempty"` // Person record matched from the applicant
 
 	// Housing Information
 	Housing HousingInfo `json:"housing" bson:"housing"`
@@ -166,6 +180,10 @@ type AdoptionApplication struct {
 	InterviewDate   *time.Time          `json:"interview_date,omitempty" bson:"interview_date,omitempty"`
 	InterviewNotes  string              `json:"interview_notes,omitempty" bson:"interview_notes,omitempty"`
 
+	// Review Pipeline
+	Review *ApplicationReview `json:"review,omitempty" bson:"review,omitempty"`
+	Visits []ApplicationVisit `json:"visits,omitempty" bson:"visits,omitempty"`
+
 	// Additional Information
 	AdditionalInfo string   `json:"additional_info,omitempty" bson:"additional_info,omitempty"`
 	Attachments    []string `json:"attachments,omitempty" bson:"attachments,omitempty"` // URLs to uploaded documents
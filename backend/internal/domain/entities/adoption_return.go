@@ -0,0 +1,102 @@
+package entities
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReturnReasonCategory classifies why an adopted animal was returned
+type ReturnReasonCategory string
+
+const (
+	ReturnReasonBehavior         ReturnReasonCategory = "behavior"
+	ReturnReasonHealth           ReturnReasonCategory = "health"
+	ReturnReasonAllergies        ReturnReasonCategory = "allergies"
+	ReturnReasonHousing          ReturnReasonCategory = "housing"
+	ReturnReasonIncompatiblePets ReturnReasonCategory = "incompatible_pets"
+	ReturnReasonChildren         ReturnReasonCategory = "children"
+	ReturnReasonTime             ReturnReasonCategory = "time"
+	ReturnReasonFinancial        ReturnReasonCategory = "financial"
+	ReturnReasonOwnerHealth      ReturnReasonCategory = "owner_health"
+	ReturnReasonOther            ReturnReasonCategory = "other"
+)
+
+// RefundDecision records what happened to the adoption fee on return
+type RefundDecision string
+
+const (
+	RefundFull      RefundDecision = "full"
+	RefundPartial   RefundDecision = "partial"
+	RefundForfeited RefundDecision = "forfeited"
+	RefundNone      RefundDecision = "none" // Nothing was paid
+)
+
+// AdoptionReturn records the return of an adopted animal
+type AdoptionReturn struct {
+	ReturnDate        time.Time            `json:"return_date" bson:"return_date"`
+	Category          ReturnReasonCategory `json:"category" bson:"category"`
+	Details           string               `json:"details,omitempty" bson:"details,omitempty"`
+	AnimalCondition   string               `json:"animal_condition,omitempty" bson:"animal_condition,omitempty"`
+	DuringTrial       bool                 `json:"during_trial" bson:"during_trial"`
+	DaysAfterAdoption int                  `json:"days_after_adoption" bson:"days_after_adoption"`
+	Refund            RefundDecision       `json:"refund" bson:"refund"`
+	RefundAmount      float64              `json:"refund_amount" bson:"refund_amount"`
+	ForfeitedAmount   float64              `json:"forfeited_amount" bson:"forfeited_amount"`
+	RefundRule        string               `json:"refund_rule" bson:"refund_rule"` // The policy rule that decided the refund
+	RecordedBy        primitive.ObjectID   `json:"recorded_by" bson:"recorded_by"`
+}
+
+// AdoptionReturnPolicy decides the fee refund of returned animals and what happens when a
+// trial ends. It puts into effect the return terms of the adoption policy text.
+type AdoptionReturnPolicy struct {
+	TrialRefundPercent float64 `json:"trial_refund_percent" bson:"trial_refund_percent" validate:"min=0,max=100"` // Returns during the trial
+	RefundWindowDays   int     `json:"refund_window_days" bson:"refund_window_days" validate:"min=0"`             // Returns within this many days of adoption
+	RefundPercent      float64 `json:"refund_percent" bson:"refund_percent" validate:"min=0,max=100"`             // Refund within the window; later returns forfeit the fee
+	TrialAutoFinalize  bool    `json:"trial_auto_finalize" bson:"trial_auto_finalize"`                            // Finalize at the trial end instead of asking staff
+}
+
+// DefaultAdoptionReturnPolicy returns the policy used until one is configured
+func DefaultAdoptionReturnPolicy() *AdoptionReturnPolicy {
+	return &AdoptionReturnPolicy{
+		TrialRefundPercent: 100,
+		RefundWindowDays:   14,
+		RefundPercent:      50,
+	}
+}
+
+// Refund decides the refund of the amount paid for an animal returned after the given
+// number of days, and returns the decision, the refunded amount and the rule applied
+func (p *AdoptionReturnPolicy) Refund(amountPaid float64, duringTrial bool, days int) (RefundDecision, float64, string) {
+	if amountPaid <= 0 {
+		return RefundNone, 0, "no fee was paid"
+	}
+
+	percent, rule := 0.0, "returned after the refund window"
+	switch {
+	case duringTrial:
+		percent, rule = p.TrialRefundPercent, "returned during the trial"
+	case days <= p.RefundWindowDays:
+		percent, rule = p.RefundPercent, "returned within the refund window"
+	}
+
+	amount := math.Round(amountPaid*percent) / 100
+	switch {
+	case amount <= 0:
+		return RefundForfeited, 0, rule
+	case amount >= amountPaid:
+		return RefundFull, amountPaid, rule
+	default:
+		return RefundPartial, amount, rule
+	}
+}
+
+// ShelterStay is an earlier stay of the animal at the shelter
+type ShelterStay struct {
+	IntakeDate   time.Time           `json:"intake_date" bson:"intake_date"`
+	IntakeReason string              `json:"intake_reason,omitempty" bson:"intake_reason,omitempty"`
+	OutcomeDate  time.Time           `json:"outcome_date" bson:"outcome_date"`
+	Outcome      AnimalStatus        `json:"outcome" bson:"outcome"`
+	AdoptionID   *primitive.ObjectID `json:"adoption_id,omitempty" bson:"adoption_id,omitempty"`
+}
@@ -77,14 +77,143 @@ type AnimalImages struct {
 	Primary     string   `json:"primary" bson:"primary"`                         // Main profile image
 	Gallery     []string `json:"gallery,omitempty" bson:"gallery,omitempty"`     // Additional images
 	Thumbnails  []string `json:"thumbnails,omitempty" bson:"thumbnails,omitempty"` // Thumbnail versions
+	Items       []AnimalImage `json:"items,omitempty" bson:"items,omitempty"`  // Processed images in display order
+}
+
+// AnimalImage represents a processed photo and its generated sizes
+type AnimalImage struct {
+	ID         string             `json:"id" bson:"id"`
+	Sizes      map[string]string  `json:"sizes" bson:"sizes"` // Size name -> URL
+	Width      int                `json:"width,omitempty" bson:"width,omitempty"`
+	Height     int                `json:"height,omitempty" bson:"height,omitempty"`
+	UploadedBy primitive.ObjectID `json:"uploaded_by,omitempty" bson:"uploaded_by,omitempty"`
+	UploadedAt time.Time          `json:"uploaded_at" bson:"uploaded_at"`
+}
+
+// URLs returns every stored file URL of the image
+func (i *AnimalImage) URLs() []string {
+	urls := make([]string, 0, len(i.Sizes))
+	for _, url := range i.Sizes {
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// EnsureItems wraps legacy primary and gallery URLs as items so they can be reordered and deleted.
+// Legacy thumbnails were stored in the same order as the images, so each goes with the image at its index.
+func (a *AnimalImages) EnsureItems() {
+	if len(a.Items) > 0 {
+		return
+	}
+
+	legacy := a.Gallery
+	if a.Primary != "" {
+		legacy = append([]string{a.Primary}, a.Gallery...)
+	}
+	for idx, url := range legacy {
+		sizes := map[string]string{"large": url}
+		if idx < len(a.Thumbnails) && a.Thumbnails[idx] != "" {
+			sizes["thumbnail"] = a.Thumbnails[idx]
+		}
+		a.Items = append(a.Items, AnimalImage{
+			ID:    primitive.NewObjectID().Hex(),
+			Sizes: sizes,
+		})
+	}
+}
+
+// SyncURLs rebuilds the primary, gallery and thumbnail URLs from the ordered items
+func (a *AnimalImages) SyncURLs() {
+	a.Primary = ""
+	a.Gallery = nil
+	a.Thumbnails = nil
+
+	for idx, item := range a.Items {
+		url := item.Sizes["large"]
+		if idx == 0 {
+			a.Primary = url
+		} else {
+			a.Gallery = append(a.Gallery, url)
+		}
+		if thumbnail, ok := item.Sizes["thumbnail"]; ok {
+			a.Thumbnails = append(a.Thumbnails, thumbnail)
+		}
+	}
+}
+
+// Reorder puts the items in the given order; every existing image ID must be listed exactly once
+func (a *AnimalImages) Reorder(ids []string) bool {
+	if len(ids) != len(a.Items) {
+		return false
+	}
+
+	byID := make(map[string]AnimalImage, len(a.Items))
+	for _, item := range a.Items {
+		byID[item.ID] = item
+	}
+
+	ordered := make([]AnimalImage, 0, len(ids))
+	for _, id := range ids {
+		item, ok := byID[id]
+		if !ok {
+			return false
+		}
+		ordered = append(ordered, item)
+		delete(byID, id)
+	}
+
+	a.Items = ordered
+	a.SyncURLs()
+	return true
+}
+
+// Remove removes an image by ID and returns it
+func (a *AnimalImages) Remove(id string) (*AnimalImage, bool) {
+	for idx, item := range a.Items {
+		if item.ID == id {
+			a.Items = append(a.Items[:idx], a.Items[idx+1:]...)
+			a.SyncURLs()
+			return &item, true
+		}
+	}
+	return nil, false
+}
+
+// AllURLs returns every file URL referenced by the images, including legacy fields
+func (a *AnimalImages) AllURLs() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+
+	add(a.Primary)
+	for _, url := range a.Gallery {
+		add(url)
+	}
+	for _, url := range a.Thumbnails {
+		add(url)
+	}
+	for _, item := range a.Items {
+		for _, url := range item.URLs() {
+			add(url)
+		}
+	}
+	return urls
 }
 
 // MedicalInfo holds medical information about the animal
 type MedicalInfo struct {
 	Vaccinated       bool      `json:"vaccinated" bson:"vaccinated"`
+	VaccinationStatus VaccinationStatus `json:"vaccination_status,omitempty" bson:"vaccination_status,omitempty"` // current, due or overdue against the vaccination protocols
+	NextVaccinationDue *time.Time `json:"next_vaccination_due,omitempty" bson:"next_vaccination_due,omitempty"`
 	Sterilized       bool      `json:"sterilized" bson:"sterilized"`
 	Microchipped     bool      `json:"microchipped" bson:"microchipped"`
-	MicrochipNumber  string    `json:"microchip_number,omitempty" bson:"microchip_number,omitempty"`
+	MicrochipNumber  string    `json:"microchip_number,omitempty" bson:"microchip_number,omitempty"` // ISO 11784/11785, normalized
+	MicrochipRegistration *MicrochipRegistration `json:"microchip_registration,omitempty" bson:"microchip_registration,omitempty"`
 	HealthStatus     string    `json:"health_status" bson:"health_status"` // healthy, sick, injured, recovering
 	Medications      []string  `json:"medications,omitempty" bson:"medications,omitempty"`
 	Allergies        []string  `json:"allergies,omitempty" bson:"allergies,omitempty"`
@@ -93,6 +222,16 @@ type MedicalInfo struct {
 	NextVetVisit     *time.Time `json:"next_vet_visit,omitempty" bson:"next_vet_visit,omitempty"`
 }
 
+// MicrochipRegistration records the owner registration held by a microchip registry
+type MicrochipRegistration struct {
+	Registry     string    `json:"registry" bson:"registry"`
+	Reference    string    `json:"reference,omitempty" bson:"reference,omitempty"`
+	OwnerName    string    `json:"owner_name" bson:"owner_name"`
+	OwnerEmail   string    `json:"owner_email,omitempty" bson:"owner_email,omitempty"`
+	OwnerPhone   string    `json:"owner_phone,omitempty" bson:"owner_phone,omitempty"`
+	RegisteredAt time.Time `json:"registered_at" bson:"registered_at"`
+}
+
 // BehaviorInfo holds behavioral information
 type BehaviorInfo struct {
 	Temperament      []Temperament `json:"temperament" bson:"temperament"`
@@ -111,6 +250,15 @@ type ShelterInfo struct {
 	Location         string                `json:"location" bson:"location"` // cage/kennel number or area
 	AssignedCaretaker *primitive.ObjectID  `json:"assigned_caretaker,omitempty" bson:"assigned_caretaker,omitempty"` // User ID
 	DailyNotes       []DailyNote          `json:"daily_notes,omitempty" bson:"daily_notes,omitempty"`
+	LocationHistory  []LocationStay       `json:"location_history,omitempty" bson:"location_history,omitempty"` // Housing moves, used for exposure tracing
+	PreviousStays    []ShelterStay        `json:"previous_stays,omitempty" bson:"previous_stays,omitempty"` // Earlier stays ended by an adoption
+}
+
+// LocationStay records a period the animal was housed at one location
+type LocationStay struct {
+	Location string     `json:"location" bson:"location"`
+	From     time.Time  `json:"from" bson:"from"`
+	To       *time.Time `json:"to,omitempty" bson:"to,omitempty"` // nil while the animal is still there
 }
 
 // DailyNote represents a daily observation or note about the animal
@@ -118,6 +266,7 @@ type DailyNote struct {
 	Date      time.Time            `json:"date" bson:"date"`
 	Note      string               `json:"note" bson:"note"`
 	CreatedBy primitive.ObjectID   `json:"created_by" bson:"created_by"` // User ID
+	Vitals    *VitalSigns          `json:"vitals,omitempty" bson:"vitals,omitempty"` // Weight and vitals measured during care
 }
 
 // AdoptionInfo holds adoption-related information
@@ -176,6 +325,20 @@ func (a *Animal) IsAdopted() bool {
 	return a.Status == AnimalStatusAdopted
 }
 
+// IsQuarantined checks if the animal is in quarantine
+func (a *Animal) IsQuarantined() bool {
+	return a.Status == AnimalStatusQuarantine
+}
+
+// IsInCare checks if the animal is still cared for by the shelter
+func (a *Animal) IsInCare() bool {
+	switch a.Status {
+	case AnimalStatusAdopted, AnimalStatusTransferred, AnimalStatusDeceased:
+		return false
+	}
+	return true
+}
+
 // CanBeModified checks if the animal record can be modified
 func (a *Animal) CanBeModified() bool {
 	return a.Status != AnimalStatusDeceased
@@ -234,3 +397,75 @@ func (a *Animal) MarkAsAdopted(adopterID primitive.ObjectID, adoptionDate time.T
 	a.Adoption.AdoptionDate = &adoptionDate
 	a.Adoption.AdopterID = &adopterID
 }
+
+// Readmit starts a new stay at the shelter for an animal coming back from an adoption.
+// The ended stay is kept in the previous stays and the adopter is cleared.
+func (a *Animal) Readmit(reason string, adoption *Adoption, location string, at time.Time) {
+	a.Shelter.PreviousStays = append(a.Shelter.PreviousStays, ShelterStay{
+		IntakeDate:   a.intakeTime(),
+		IntakeReason: a.Shelter.IntakeReason,
+		OutcomeDate:  adoption.AdoptionDate,
+		Outcome:      AnimalStatusAdopted,
+		AdoptionID:   &adoption.ID,
+	})
+
+	// The animal left its housing when it was adopted
+	history := a.LocationStays()
+	if n := len(history); n > 0 && history[n-1].To == nil {
+		left := adoption.AdoptionDate
+		history[n-1].To = &left
+	}
+	a.Shelter.LocationHistory = history
+	a.Shelter.Location = ""
+
+	a.Shelter.IntakeDate = at
+	a.Shelter.IntakeReason = reason
+	a.Adoption.AdoptionDate = nil
+	a.Adoption.AdopterID = nil
+	a.Status = AnimalStatusAvailable
+	if location != "" {
+		a.MoveTo(location, at)
+	}
+}
+
+// MoveTo moves the animal to another housing location and records the move
+func (a *Animal) MoveTo(location string, at time.Time) {
+	if location == a.Shelter.Location {
+		return
+	}
+
+	history := a.Shelter.LocationHistory
+	if len(history) == 0 && a.Shelter.Location != "" {
+		// Records from before the history was kept start with the current location
+		history = append(history, LocationStay{Location: a.Shelter.Location, From: a.intakeTime()})
+	}
+	if n := len(history); n > 0 && history[n-1].To == nil {
+		history[n-1].To = &at
+	}
+	if location != "" {
+		history = append(history, LocationStay{Location: location, From: at})
+	}
+
+	a.Shelter.LocationHistory = history
+	a.Shelter.Location = location
+}
+
+// LocationStays returns where the animal has been housed. Animals without a
+// recorded history have been at their current location since intake.
+func (a *Animal) LocationStays() []LocationStay {
+	if len(a.Shelter.LocationHistory) > 0 {
+		return a.Shelter.LocationHistory
+	}
+	if a.Shelter.Location == "" {
+		return nil
+	}
+	return []LocationStay{{Location: a.Shelter.Location, From: a.intakeTime()}}
+}
+
+// intakeTime returns when the animal arrived, falling back to when it was recorded
+func (a *Animal) intakeTime() time.Time {
+	if !a.Shelter.IntakeDate.IsZero() {
+		return a.Shelter.IntakeDate
+	}
+	return a.CreatedAt
+}
@@ -0,0 +1,359 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewStageKey identifies a stage of the application review pipeline
+type ReviewStageKey string
+
+const (
+	ReviewStageScreening            ReviewStageKey = "screening"
+	ReviewStageReferenceCheck       ReviewStageKey = "reference_check"
+	ReviewStageLandlordVerification ReviewStageKey = "landlord_verification"
+	ReviewStageHomeVisit            ReviewStageKey = "home_visit"
+	ReviewStageMeetAndGreet         ReviewStageKey = "meet_and_greet"
+	ReviewStageDecision             ReviewStageKey = "decision"
+)
+
+// ReviewStageKeys lists the stages in pipeline order
+var ReviewStageKeys = []ReviewStageKey{
+	ReviewStageScreening,
+	ReviewStageReferenceCheck,
+	ReviewStageLandlordVerification,
+	ReviewStageHomeVisit,
+	ReviewStageMeetAndGreet,
+	ReviewStageDecision,
+}
+
+// ReviewStageStatus represents the progress of a review stage
+type ReviewStageStatus string
+
+const (
+	ReviewStagePending    ReviewStageStatus = "pending"
+	ReviewStageInProgress ReviewStageStatus = "in_progress"
+	ReviewStagePassed     ReviewStageStatus = "passed"
+	ReviewStageFailed     ReviewStageStatus = "failed"
+	ReviewStageSkipped    ReviewStageStatus = "skipped"
+)
+
+// IsFinished reports whether the stage has an outcome
+func (s ReviewStageStatus) IsFinished() bool {
+	return s == ReviewStagePassed || s == ReviewStageFailed || s == ReviewStageSkipped
+}
+
+// ReviewStageConfig configures a stage of the review pipeline
+type ReviewStageConfig struct {
+	Key             ReviewStageKey      `json:"key" bson:"key" validate:"required,oneof=screening reference_check landlord_verification home_visit meet_and_greet decision"`
+	Name            string              `json:"name" bson:"name" validate:"required"`
+	Enabled         bool                `json:"enabled" bson:"enabled"`
+	Checklist       []string            `json:"checklist,omitempty" bson:"checklist,omitempty"`
+	SLAHours        int                 `json:"sla_hours" bson:"sla_hours" validate:"min=0"` // 0 means no deadline
+	DefaultAssignee *primitive.ObjectID `json:"default_assignee,omitempty" bson:"default_assignee,omitempty"`
+}
+
+// RejectionReasonTemplate is a rejection reason with the message sent to the applicant.
+// Subject and body may use {{first_name}}, {{applicant_name}}, {{animal_name}}, {{organization}} and {{notes}}.
+type RejectionReasonTemplate struct {
+	Code    string `json:"code" bson:"code" validate:"required"`
+	Label   string `json:"label" bson:"label" validate:"required"`
+	Subject string `json:"subject" bson:"subject" validate:"required"`
+	Body    string `json:"body" bson:"body" validate:"required"`
+}
+
+// ApplicationReviewConfig configures the application review pipeline
+type ApplicationReviewConfig struct {
+	Stages           []ReviewStageConfig       `json:"stages" bson:"stages" validate:"required,min=1,dive"`
+	RejectionReasons []RejectionReasonTemplate `json:"rejection_reasons" bson:"rejection_reasons" validate:"required,min=1,dive"`
+}
+
+// Stage returns the configuration of a stage, or nil
+func (c *ApplicationReviewConfig) Stage(key ReviewStageKey) *ReviewStageConfig {
+	for i := range c.Stages {
+		if c.Stages[i].Key == key {
+			return &c.Stages[i]
+		}
+	}
+	return nil
+}
+
+// Reason returns the rejection reason with the code, or nil
+func (c *ApplicationReviewConfig) Reason(code string) *RejectionReasonTemplate {
+	for i := range c.RejectionReasons {
+		if c.RejectionReasons[i].Code == code {
+			return &c.RejectionReasons[i]
+		}
+	}
+	return nil
+}
+
+// DefaultApplicationReviewConfig returns the pipeline used until one is configured
+func DefaultApplicationReviewConfig() *ApplicationReviewConfig {
+	return &ApplicationReviewConfig{
+		Stages: []ReviewStageConfig{
+			{Key: ReviewStageScreening, Name: "Screening", Enabled: true, SLAHours: 48,
+				Checklist: []string{"Application complete", "Applicant is an adult", "Household suits the animal"}},
+			{Key: ReviewStageReferenceCheck, Name: "Reference check", Enabled: true, SLAHours: 72,
+				Checklist: []string{"References contacted", "Veterinarian contacted"}},
+			{Key: ReviewStageLandlordVerification, Name: "Landlord verification", Enabled: true, SLAHours: 72,
+				Checklist: []string{"Landlord contacted", "Pets allowed by the lease"}},
+			{Key: ReviewStageHomeVisit, Name: "Home visit", Enabled: true, SLAHours: 168,
+				Checklist: []string{"Safe living space", "Secure fencing or balcony", "Space for the animal"}},
+			{Key: ReviewStageMeetAndGreet, Name: "Meet and greet", Enabled: true, SLAHours: 168,
+				Checklist: []string{"Household met the animal", "Resident pets met the animal"}},
+			{Key: ReviewStageDecision, Name: "Decision", Enabled: true, SLAHours: 48},
+		},
+		RejectionReasons: []RejectionReasonTemplate{
+			{
+				Code:    "housing_unsuitable",
+				Label:   "Housing not suitable for the animal",
+				Subject: "Your application to adopt {{animal_name}}",
+				Body: "Dear {{first_name}},\n\nthank you for applying to adopt {{animal_name}}. After reviewing your application we have decided " +
+					"that your home is not a good match for {{animal_name}}'s needs, so we cannot continue with this adoption.\n\n{{notes}}\n\n" +
+					"We encourage you to look at our other animals.\n\n{{organization}}",
+			},
+			{
+				Code:    "landlord_refused",
+				Label:   "Landlord did not allow pets",
+				Subject: "Your application to adopt {{animal_name}}",
+				Body: "Dear {{first_name}},\n\nthank you for applying to adopt {{animal_name}}. We were unable to confirm that your landlord " +
+					"allows pets, so we cannot continue with this adoption.\n\n{{notes}}\n\n" +
+					"If your situation changes, you are welcome to apply again.\n\n{{organization}}",
+			},
+			{
+				Code:    "references_unsatisfactory",
+				Label:   "References could not be confirmed",
+				Subject: "Your application to adopt {{animal_name}}",
+				Body: "Dear {{first_name}},\n\nthank you for applying to adopt {{animal_name}}. We were unable to complete the reference check, " +
+					"so we cannot continue with this adoption.\n\n{{notes}}\n\n{{organization}}",
+			},
+			{
+				Code:    "not_a_match",
+				Label:   "Not the right match for the animal",
+				Subject: "Your application to adopt {{animal_name}}",
+				Body: "Dear {{first_name}},\n\nthank you for applying to adopt {{animal_name}}. After meeting you we believe that " +
+					"{{animal_name}} would not be the right match for your household.\n\n{{notes}}\n\n" +
+					"We would be happy to help you find another animal.\n\n{{organization}}",
+			},
+			{
+				Code:    "animal_unavailable",
+				Label:   "Animal no longer available",
+				Subject: "Your application to adopt {{animal_name}}",
+				Body: "Dear {{first_name}},\n\nthank you for applying to adopt {{animal_name}}. Unfortunately {{animal_name}} is no longer " +
+					"available for adoption.\n\n{{notes}}\n\nWe encourage you to look at our other animals.\n\n{{organization}}",
+			},
+			{
+				Code:    "other",
+				Label:   "Other",
+				Subject: "Your application to adopt {{animal_name}}",
+				Body: "Dear {{first_name}},\n\nthank you for applying to adopt {{animal_name}}. We are sorry to let you know that we cannot " +
+					"continue with your application.\n\n{{notes}}\n\n{{organization}}",
+			},
+		},
+	}
+}
+
+// ReviewStage is the progress of an application through a stage
+type ReviewStage struct {
+	Key         ReviewStageKey      `json:"key" bson:"key"`
+	Name        string              `json:"name" bson:"name"`
+	Status      ReviewStageStatus   `json:"status" bson:"status"`
+	AssigneeID  *primitive.ObjectID `json:"assignee_id,omitempty" bson:"assignee_id,omitempty"`
+	Checklist   []ChecklistItem     `json:"checklist,omitempty" bson:"checklist,omitempty"`
+	Notes       string              `json:"notes,omitempty" bson:"notes,omitempty"`
+	SLAHours    int                 `json:"sla_hours" bson:"sla_hours"`
+	StartedAt   *time.Time          `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	DueAt       *time.Time          `json:"due_at,omitempty" bson:"due_at,omitempty"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	CompletedBy *primitive.ObjectID `json:"completed_by,omitempty" bson:"completed_by,omitempty"`
+	SLABreached bool                `json:"sla_breached" bson:"sla_breached"`
+}
+
+// ChecklistComplete reports whether every checklist item is done
+func (s *ReviewStage) ChecklistComplete() bool {
+	for _, item := range s.Checklist {
+		if !item.IsCompleted {
+			return false
+		}
+	}
+	return true
+}
+
+// IsOverdue reports whether the stage is past its deadline without an outcome
+func (s *ReviewStage) IsOverdue(now time.Time) bool {
+	return s.Status == ReviewStageInProgress && s.DueAt != nil && now.After(*s.DueAt)
+}
+
+// Duration returns the time spent in the stage so far
+func (s *ReviewStage) Duration(now time.Time) time.Duration {
+	if s.StartedAt == nil {
+		return 0
+	}
+	end := now
+	if s.CompletedAt != nil {
+		end = *s.CompletedAt
+	}
+	return end.Sub(*s.StartedAt)
+}
+
+// start makes the stage the current one and sets its deadline
+func (s *ReviewStage) start(now time.Time) {
+	s.Status = ReviewStageInProgress
+	s.StartedAt = &now
+	if s.SLAHours > 0 {
+		due := now.Add(time.Duration(s.SLAHours) * time.Hour)
+		s.DueAt = &due
+	}
+}
+
+// ApplicationReview tracks an application through the review pipeline
+type ApplicationReview struct {
+	Stages         []ReviewStage  `json:"stages" bson:"stages"`
+	CurrentStage   ReviewStageKey `json:"current_stage,omitempty" bson:"current_stage,omitempty"` // Empty once decided
+	StartedAt      time.Time      `json:"started_at" bson:"started_at"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	RejectionCode  string         `json:"rejection_code,omitempty" bson:"rejection_code,omitempty"`
+	RejectionNotes string         `json:"rejection_notes,omitempty" bson:"rejection_notes,omitempty"`
+}
+
+// NewApplicationReview creates the review of an application from the enabled stages of the
+// pipeline; the first stage starts at the given time
+func NewApplicationReview(config *ApplicationReviewConfig, startedAt time.Time) *ApplicationReview {
+	review := &ApplicationReview{StartedAt: startedAt}
+	for _, key := range ReviewStageKeys {
+		stage := config.Stage(key)
+		if stage == nil || (!stage.Enabled && key != ReviewStageDecision) {
+			continue
+		}
+		item := ReviewStage{
+			Key:        key,
+			Name:       stage.Name,
+			Status:     ReviewStagePending,
+			AssigneeID: stage.DefaultAssignee,
+			SLAHours:   stage.SLAHours,
+		}
+		for _, text := range stage.Checklist {
+			item.Checklist = append(item.Checklist, ChecklistItem{ID: primitive.NewObjectID().Hex(), Text: text})
+		}
+		review.Stages = append(review.Stages, item)
+	}
+	if len(review.Stages) > 0 {
+		review.Stages[0].start(startedAt)
+		review.CurrentStage = review.Stages[0].Key
+	}
+	return review
+}
+
+// Stage returns the progress of a stage, or nil if it is not part of the review
+func (r *ApplicationReview) Stage(key ReviewStageKey) *ReviewStage {
+	for i := range r.Stages {
+		if r.Stages[i].Key == key {
+			return &r.Stages[i]
+		}
+	}
+	return nil
+}
+
+// Current returns the stage in progress, or nil once the review is decided
+func (r *ApplicationReview) Current() *ReviewStage {
+	if r.CurrentStage == "" {
+		return nil
+	}
+	return r.Stage(r.CurrentStage)
+}
+
+// Finish records the outcome of a stage and, when it is the current one, starts the next
+// pending stage. It returns the new current stage, or nil when the review is decided.
+func (r *ApplicationReview) Finish(key ReviewStageKey, status ReviewStageStatus, userID primitive.ObjectID, now time.Time) *ReviewStage {
+	stage := r.Stage(key)
+	if stage == nil {
+		return r.Current()
+	}
+	if stage.StartedAt == nil {
+		stage.StartedAt = &now
+	}
+	stage.Status = status
+	stage.CompletedAt = &now
+	stage.CompletedBy = &userID
+	stage.SLABreached = stage.DueAt != nil && now.After(*stage.DueAt)
+
+	if key != r.CurrentStage {
+		return r.Current()
+	}
+	for i := range r.Stages {
+		if r.Stages[i].Status == ReviewStagePending {
+			r.Stages[i].start(now)
+			r.CurrentStage = r.Stages[i].Key
+			return &r.Stages[i]
+		}
+	}
+	r.CurrentStage = ""
+	r.CompletedAt = &now
+	return nil
+}
+
+// SkipRemaining skips every stage without an outcome except the decision, which becomes current
+func (r *ApplicationReview) SkipRemaining(userID primitive.ObjectID, now time.Time) {
+	for i := range r.Stages {
+		stage := &r.Stages[i]
+		if stage.Key == ReviewStageDecision || stage.Status.IsFinished() {
+			continue
+		}
+		if stage.Status == ReviewStageInProgress {
+			stage.CompletedAt = &now
+			stage.CompletedBy = &userID
+		}
+		stage.Status = ReviewStageSkipped
+	}
+	if decision := r.Stage(ReviewStageDecision); decision != nil && !decision.Status.IsFinished() {
+		if decision.Status == ReviewStagePending {
+			decision.start(now)
+		}
+		r.CurrentStage = ReviewStageDecision
+	}
+}
+
+// ApplicationVisitType represents the kind of visit during the review
+type ApplicationVisitType string
+
+const (
+	ApplicationVisitHome         ApplicationVisitType = "home_visit"
+	ApplicationVisitMeetAndGreet ApplicationVisitType = "meet_and_greet"
+)
+
+// Stage returns the review stage the visit belongs to
+func (t ApplicationVisitType) Stage() ReviewStageKey {
+	if t == ApplicationVisitMeetAndGreet {
+		return ReviewStageMeetAndGreet
+	}
+	return ReviewStageHomeVisit
+}
+
+// ApplicationVisitStatus represents the state of a visit
+type ApplicationVisitStatus string
+
+const (
+	ApplicationVisitScheduled ApplicationVisitStatus = "scheduled"
+	ApplicationVisitCompleted ApplicationVisitStatus = "completed"
+	ApplicationVisitCancelled ApplicationVisitStatus = "cancelled"
+)
+
+// ApplicationVisit is a home visit or meet and greet scheduled during the review
+type ApplicationVisit struct {
+	ID          primitive.ObjectID     `json:"id" bson:"id"`
+	Type        ApplicationVisitType   `json:"type" bson:"type"`
+	Status      ApplicationVisitStatus `json:"status" bson:"status"`
+	ScheduledAt time.Time              `json:"scheduled_at" bson:"scheduled_at"`
+	StaffID     *primitive.ObjectID    `json:"staff_id,omitempty" bson:"staff_id,omitempty"`
+	Location    string                 `json:"location,omitempty" bson:"location,omitempty"`
+	Notes       string                 `json:"notes,omitempty" bson:"notes,omitempty"`
+	VisitedAt   *time.Time             `json:"visited_at,omitempty" bson:"visited_at,omitempty"`
+	Outcome     ReviewStageStatus      `json:"outcome,omitempty" bson:"outcome,omitempty"` // passed or failed
+	Findings    []ChecklistItem        `json:"findings,omitempty" bson:"findings,omitempty"`
+	Report      string                 `json:"report,omitempty" bson:"report,omitempty"`
+	RecordedBy  *primitive.ObjectID    `json:"recorded_by,omitempty" bson:"recorded_by,omitempty"`
+	CreatedBy   primitive.ObjectID     `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time              `json:"created_at" bson:"created_at"`
+}
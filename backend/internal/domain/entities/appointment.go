@@ -0,0 +1,258 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultVisitDurations are the appointment lengths in minutes used when a
+// clinic schedule does not set one for the visit type
+var DefaultVisitDurations = map[VisitType]int{
+	VisitTypeCheckup:     30,
+	VisitTypeVaccination: 15,
+	VisitTypeEmergency:   60,
+	VisitTypeSurgery:     120,
+	VisitTypeDental:      90,
+	VisitTypeSpayNeuter:  120,
+	VisitTypeFollowUp:    20,
+	VisitTypeTreatment:   30,
+	VisitTypeDiagnostic:  45,
+}
+
+const (
+	// DefaultSlotIntervalMinutes is the spacing of offered appointment start times
+	DefaultSlotIntervalMinutes = 15
+
+	// DefaultTransportLeadMinutes is how long before the appointment the animal leaves the shelter
+	DefaultTransportLeadMinutes = 30
+)
+
+// ClinicWorkingHours is an opening window of a clinic on a weekday, in the clinic's time zone
+type ClinicWorkingHours struct {
+	Weekday time.Weekday `json:"weekday" bson:"weekday" validate:"min=0,max=6"` // 0 = Sunday
+	Open    string       `json:"open" bson:"open" validate:"required"`          // "08:00"
+	Close   string       `json:"close" bson:"close" validate:"required"`        // "16:00"
+}
+
+// ClinicSchedule holds the appointment calendar settings of a partner veterinary clinic
+type ClinicSchedule struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PartnerID primitive.ObjectID `json:"partner_id" bson:"partner_id"`
+
+	// Timezone is an IANA zone name, e.g. "Europe/Warsaw"; working hours are local to it
+	Timezone     string               `json:"timezone" bson:"timezone"`
+	WorkingHours []ClinicWorkingHours `json:"working_hours" bson:"working_hours"`
+	ClosedDates  []string             `json:"closed_dates,omitempty" bson:"closed_dates,omitempty"` // "2006-01-02"
+
+	// VisitDurations overrides DefaultVisitDurations, in minutes
+	VisitDurations map[VisitType]int `json:"visit_durations,omitempty" bson:"visit_durations,omitempty"`
+
+	// Capacity is the number of appointments the clinic takes at the same time
+	Capacity             int `json:"capacity" bson:"capacity"`
+	SlotIntervalMinutes  int `json:"slot_interval_minutes" bson:"slot_interval_minutes"`
+	TransportLeadMinutes int `json:"transport_lead_minutes" bson:"transport_lead_minutes"`
+
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NewClinicSchedule creates a schedule with the defaults and no working hours
+func NewClinicSchedule(partnerID primitive.ObjectID) *ClinicSchedule {
+	return &ClinicSchedule{
+		PartnerID:            partnerID,
+		Timezone:             "UTC",
+		WorkingHours:         []ClinicWorkingHours{},
+		Capacity:             1,
+		SlotIntervalMinutes:  DefaultSlotIntervalMinutes,
+		TransportLeadMinutes: DefaultTransportLeadMinutes,
+	}
+}
+
+// Location returns the clinic's time zone, UTC if it is not set or unknown
+func (s *ClinicSchedule) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Validate checks the time zone and working hours
+func (s *ClinicSchedule) Validate() error {
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("unknown time zone %q", s.Timezone)
+		}
+	}
+	for _, hours := range s.WorkingHours {
+		open, err := clockOffset(hours.Open)
+		if err != nil {
+			return err
+		}
+		closing, err := clockOffset(hours.Close)
+		if err != nil {
+			return err
+		}
+		if closing <= open {
+			return fmt.Errorf("closing time %s is not after opening time %s on %s", hours.Close, hours.Open, hours.Weekday)
+		}
+	}
+	for _, date := range s.ClosedDates {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid closed date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	for visitType, minutes := range s.VisitDurations {
+		if minutes <= 0 {
+			return fmt.Errorf("duration for %s must be positive", visitType)
+		}
+	}
+	return nil
+}
+
+// DurationFor returns the appointment length of a visit type
+func (s *ClinicSchedule) DurationFor(visitType VisitType) time.Duration {
+	if minutes, ok := s.VisitDurations[visitType]; ok && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	if minutes, ok := DefaultVisitDurations[visitType]; ok {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
+// WindowsOn returns the opening windows of the clinic on the local day of t
+func (s *ClinicSchedule) WindowsOn(t time.Time) [][2]time.Time {
+	location := s.Location()
+	local := t.In(location)
+	day := local.Format("2006-01-02")
+	for _, closed := range s.ClosedDates {
+		if closed == day {
+			return nil
+		}
+	}
+
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	var windows [][2]time.Time
+	for _, hours := range s.WorkingHours {
+		if hours.Weekday != local.Weekday() {
+			continue
+		}
+		open, err := clockOffset(hours.Open)
+		if err != nil {
+			continue
+		}
+		closing, err := clockOffset(hours.Close)
+		if err != nil {
+			continue
+		}
+		windows = append(windows, [2]time.Time{midnight.Add(open), midnight.Add(closing)})
+	}
+	return windows
+}
+
+// IsOpen reports whether an appointment from start to end fits in one opening window
+func (s *ClinicSchedule) IsOpen(start, end time.Time) bool {
+	for _, window := range s.WindowsOn(start) {
+		if !start.Before(window[0]) && !end.After(window[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// clockOffset parses "HH:MM" into the offset from midnight
+func clockOffset(value string) (time.Duration, error) {
+	hour, minute, err := parseClock(value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// AppointmentConflictType identifies what an appointment collides with
+type AppointmentConflictType string
+
+const (
+	ConflictClinicClosed   AppointmentConflictType = "clinic_closed"
+	ConflictClinicCapacity AppointmentConflictType = "clinic_capacity"
+	ConflictAnimal         AppointmentConflictType = "animal"
+	ConflictStaff          AppointmentConflictType = "staff"
+)
+
+// AppointmentConflict describes why an appointment cannot be booked as requested
+type AppointmentConflict struct {
+	Type    AppointmentConflictType `json:"type"`
+	VisitID *primitive.ObjectID     `json:"visit_id,omitempty"`
+	UserID  *primitive.ObjectID     `json:"user_id,omitempty"`
+	Start   *time.Time              `json:"start,omitempty"`
+	End     *time.Time              `json:"end,omitempty"`
+	Message string                  `json:"message"`
+}
+
+// AppointmentWindow returns the start and end of a scheduled visit
+func (v *VeterinaryVisit) AppointmentWindow() (time.Time, time.Time) {
+	start := v.VisitDate
+	if v.ScheduledDate != nil {
+		start = *v.ScheduledDate
+	}
+	if v.AppointmentEnd != nil {
+		return start, *v.AppointmentEnd
+	}
+	duration := time.Duration(v.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		duration = 30 * time.Minute
+	}
+	return start, start.Add(duration)
+}
+
+// BlocksCalendar reports whether the visit occupies its appointment slot
+func (v *VeterinaryVisit) BlocksCalendar() bool {
+	return v.Status == VisitStatusScheduled || v.Status == VisitStatusInProgress
+}
+
+// Overlaps reports whether the visit's appointment overlaps the period
+func (v *VeterinaryVisit) Overlaps(start, end time.Time) bool {
+	visitStart, visitEnd := v.AppointmentWindow()
+	return visitStart.Before(end) && start.Before(visitEnd)
+}
+
+// HasStaff reports whether the user is assigned to the appointment
+func (v *VeterinaryVisit) HasStaff(userID primitive.ObjectID) bool {
+	for _, id := range v.AssignedStaff {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarFeedScope is what a calendar feed contains
+type CalendarFeedScope string
+
+const (
+	CalendarFeedScopeClinic CalendarFeedScope = "clinic" // appointments at one partner clinic
+	CalendarFeedScopeUser   CalendarFeedScope = "user"   // appointments the user is assigned to
+)
+
+// CalendarFeed is a secret iCalendar subscription URL. Calendar apps cannot
+// send credentials, so the token in the URL is the authentication.
+type CalendarFeed struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Token     string              `json:"token" bson:"token"`
+	Scope     CalendarFeedScope   `json:"scope" bson:"scope"`
+	Name      string              `json:"name" bson:"name"`
+	PartnerID *primitive.ObjectID `json:"partner_id,omitempty" bson:"partner_id,omitempty"`
+	UserID    primitive.ObjectID  `json:"user_id" bson:"user_id"` // owner; for user feeds also the subject
+
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty" bson:"last_accessed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" bson:"updated_at"`
+}
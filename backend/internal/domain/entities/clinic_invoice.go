@@ -0,0 +1,196 @@
+package entities
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MedicalDesignation is the donation designation that invoices can be charged against
+const MedicalDesignation = "medical"
+
+// ClinicInvoiceStatus represents the payment status of a clinic invoice
+type ClinicInvoiceStatus string
+
+const (
+	ClinicInvoiceStatusOpen          ClinicInvoiceStatus = "open"
+	ClinicInvoiceStatusPartiallyPaid ClinicInvoiceStatus = "partially_paid"
+	ClinicInvoiceStatusPaid          ClinicInvoiceStatus = "paid"
+	ClinicInvoiceStatusVoid          ClinicInvoiceStatus = "void"
+)
+
+// InvoiceMatchStatus tells how an invoice line reconciles with the visit records
+type InvoiceMatchStatus string
+
+const (
+	InvoiceMatchUnmatched InvoiceMatchStatus = "unmatched" // no visit found
+	InvoiceMatchMatched   InvoiceMatchStatus = "matched"   // amount agrees with the visit cost
+	InvoiceMatchMismatch  InvoiceMatchStatus = "mismatch"  // visit found, recorded cost differs
+)
+
+// ClinicInvoiceLine is a billed service, ideally matched to a veterinary visit
+type ClinicInvoiceLine struct {
+	ID          primitive.ObjectID  `json:"id" bson:"id"`
+	Description string              `json:"description" bson:"description"`
+	AnimalID    *primitive.ObjectID `json:"animal_id,omitempty" bson:"animal_id,omitempty"`
+	VisitID     *primitive.ObjectID `json:"visit_id,omitempty" bson:"visit_id,omitempty"`
+	ServiceDate *time.Time          `json:"service_date,omitempty" bson:"service_date,omitempty"`
+
+	Quantity           float64 `json:"quantity" bson:"quantity"`
+	UnitPrice          float64 `json:"unit_price" bson:"unit_price"`                   // clinic list price
+	DiscountPercentage float64 `json:"discount_percentage" bson:"discount_percentage"` // partner discount applied to the line
+	Amount             float64 `json:"amount" bson:"amount"`                           // after discount
+
+	MatchStatus  InvoiceMatchStatus `json:"match_status" bson:"match_status"`
+	ExpectedCost float64            `json:"expected_cost,omitempty" bson:"expected_cost,omitempty"` // visit cost when the line was matched
+}
+
+// GrossAmount returns the line total before the discount
+func (l *ClinicInvoiceLine) GrossAmount() float64 {
+	return roundMoney(l.Quantity * l.UnitPrice)
+}
+
+// Calculate sets the discounted amount of the line
+func (l *ClinicInvoiceLine) Calculate() {
+	if l.Quantity == 0 {
+		l.Quantity = 1
+	}
+	l.Amount = roundMoney(l.GrossAmount() * (1 - l.DiscountPercentage/100))
+}
+
+// ClinicInvoicePayment is a payment made towards a clinic invoice
+type ClinicInvoicePayment struct {
+	ID         primitive.ObjectID `json:"id" bson:"id"`
+	Amount     float64            `json:"amount" bson:"amount"`
+	PaidAt     time.Time          `json:"paid_at" bson:"paid_at"`
+	Method     PaymentMethodType  `json:"method" bson:"method"`
+	Reference  string             `json:"reference,omitempty" bson:"reference,omitempty"` // bank transfer title, check number
+	Notes      string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	RecordedBy primitive.ObjectID `json:"recorded_by" bson:"recorded_by"`
+}
+
+// MedicalFundCharge is part of an invoice paid from a restricted medical donation
+type MedicalFundCharge struct {
+	ID         primitive.ObjectID `json:"id" bson:"id"`
+	DonationID primitive.ObjectID `json:"donation_id" bson:"donation_id"`
+	Amount     float64            `json:"amount" bson:"amount"`
+	ChargedAt  time.Time          `json:"charged_at" bson:"charged_at"`
+	ChargedBy  primitive.ObjectID `json:"charged_by" bson:"charged_by"`
+}
+
+// ClinicInvoice is an invoice received from a partner veterinary clinic
+type ClinicInvoice struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PartnerID     primitive.ObjectID `json:"partner_id" bson:"partner_id"`
+	ClinicName    string             `json:"clinic_name" bson:"clinic_name"`
+	InvoiceNumber string             `json:"invoice_number" bson:"invoice_number"` // clinic's own number
+	InvoiceDate   time.Time          `json:"invoice_date" bson:"invoice_date"`
+	DueDate       *time.Time         `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	Currency      string             `json:"currency" bson:"currency"`
+
+	Lines         []ClinicInvoiceLine `json:"lines" bson:"lines"`
+	Subtotal      float64             `json:"subtotal" bson:"subtotal"` // before discounts
+	DiscountTotal float64             `json:"discount_total" bson:"discount_total"`
+	Total         float64             `json:"total" bson:"total"`
+
+	Payments   []ClinicInvoicePayment `json:"payments,omitempty" bson:"payments,omitempty"`
+	AmountPaid float64                `json:"amount_paid" bson:"amount_paid"`
+	Balance    float64                `json:"balance" bson:"balance"`
+	Status     ClinicInvoiceStatus    `json:"status" bson:"status"`
+
+	FundCharges   []MedicalFundCharge `json:"fund_charges,omitempty" bson:"fund_charges,omitempty"`
+	ChargedToFund float64             `json:"charged_to_fund" bson:"charged_to_fund"`
+
+	DocumentID *primitive.ObjectID `json:"document_id,omitempty" bson:"document_id,omitempty"` // scanned invoice
+	Notes      string              `json:"notes,omitempty" bson:"notes,omitempty"`
+
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Recalculate updates the totals, balance and status from the lines and payments
+func (i *ClinicInvoice) Recalculate() {
+	i.Subtotal, i.Total = 0, 0
+	for idx := range i.Lines {
+		i.Lines[idx].Calculate()
+		i.Subtotal += i.Lines[idx].GrossAmount()
+		i.Total += i.Lines[idx].Amount
+	}
+	i.Subtotal = roundMoney(i.Subtotal)
+	i.Total = roundMoney(i.Total)
+	i.DiscountTotal = roundMoney(i.Subtotal - i.Total)
+
+	i.AmountPaid = 0
+	for _, payment := range i.Payments {
+		i.AmountPaid += payment.Amount
+	}
+	i.AmountPaid = roundMoney(i.AmountPaid)
+	i.Balance = roundMoney(i.Total - i.AmountPaid)
+
+	i.ChargedToFund = 0
+	for _, charge := range i.FundCharges {
+		i.ChargedToFund += charge.Amount
+	}
+	i.ChargedToFund = roundMoney(i.ChargedToFund)
+
+	if i.Status == ClinicInvoiceStatusVoid {
+		return
+	}
+	switch {
+	case i.AmountPaid <= 0:
+		i.Status = ClinicInvoiceStatusOpen
+	case i.Balance > 0:
+		i.Status = ClinicInvoiceStatusPartiallyPaid
+	default:
+		i.Status = ClinicInvoiceStatusPaid
+	}
+}
+
+// IsOverdue checks if the invoice has a balance past its due date
+func (i *ClinicInvoice) IsOverdue(now time.Time) bool {
+	return i.Status != ClinicInvoiceStatusVoid && i.Balance > 0 && i.DueDate != nil && i.DueDate.Before(now)
+}
+
+// VisitPaymentStatus returns the value for VeterinaryVisit.PaymentStatus of visits billed on the invoice
+func (i *ClinicInvoice) VisitPaymentStatus() string {
+	switch i.Status {
+	case ClinicInvoiceStatusPaid:
+		return "paid"
+	case ClinicInvoiceStatusPartiallyPaid:
+		return "partial"
+	default:
+		return "pending"
+	}
+}
+
+// Line returns the line with the ID
+func (i *ClinicInvoice) Line(id primitive.ObjectID) *ClinicInvoiceLine {
+	for idx := range i.Lines {
+		if i.Lines[idx].ID == id {
+			return &i.Lines[idx]
+		}
+	}
+	return nil
+}
+
+// IsMedicalFund reports whether the donation can pay for veterinary care
+func (d *Donation) IsMedicalFund() bool {
+	return d.Restricted && d.Status == DonationStatusCompleted &&
+		strings.EqualFold(strings.TrimSpace(d.Designation), MedicalDesignation)
+}
+
+// FundAmount returns the amount of the donation available to spend
+func (d *Donation) FundAmount() float64 {
+	if d.NetAmount > 0 {
+		return d.NetAmount
+	}
+	return d.Amount
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,237 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContractConsentKey identifies a consent the adopter gives when signing; each maps to an AgreesTo* flag of the adoption
+type ContractConsentKey string
+
+const (
+	ContractConsentReturnPolicy ContractConsentKey = "return_policy"
+	ContractConsentSpayNeuter   ContractConsentKey = "spay_neuter"
+	ContractConsentMedicalCare  ContractConsentKey = "medical_care"
+	ContractConsentFollowUp     ContractConsentKey = "follow_up"
+)
+
+// ContractStatus represents the signing state of a contract
+type ContractStatus string
+
+const (
+	ContractStatusPending ContractStatus = "pending" // waiting for the adopter's signature
+	ContractStatusSigned  ContractStatus = "signed"
+	ContractStatusVoided  ContractStatus = "voided"
+)
+
+// SignatureMethod is how the adopter signed
+type SignatureMethod string
+
+const (
+	SignatureMethodTyped SignatureMethod = "typed"
+	SignatureMethodDrawn SignatureMethod = "drawn"
+)
+
+// ContractEventAction is a step of the signing audit trail
+type ContractEventAction string
+
+const (
+	ContractEventCreated ContractEventAction = "created"
+	ContractEventSent    ContractEventAction = "sent"
+	ContractEventViewed  ContractEventAction = "viewed"
+	ContractEventSigned  ContractEventAction = "signed"
+	ContractEventVoided  ContractEventAction = "voided"
+)
+
+// ContractSection is a numbered part of the contract text
+type ContractSection struct {
+	Heading string `json:"heading" bson:"heading"`
+	Body    string `json:"body" bson:"body"`
+}
+
+// ContractConsent is a checkbox the adopter ticks when signing
+type ContractConsent struct {
+	Key      ContractConsentKey `json:"key" bson:"key" validate:"required,oneof=return_policy spay_neuter medical_care follow_up"`
+	Text     string             `json:"text" bson:"text" validate:"required"`
+	Required bool               `json:"required" bson:"required"`
+}
+
+// ContractTemplate is the text adoption contracts are generated from. Templates are
+// kept per language and never edited; a change is saved as the next version.
+type ContractTemplate struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Language string             `json:"language" bson:"language"`
+	Version  int                `json:"version" bson:"version"`
+	Title    string             `json:"title" bson:"title"`
+	// Sections and consents may use placeholders such as {{adopter_name}} and {{animal_name}}
+	Sections []ContractSection `json:"sections" bson:"sections"`
+	Consents []ContractConsent `json:"consents" bson:"consents"`
+	Notes    string            `json:"notes,omitempty" bson:"notes,omitempty"` // what changed in this version
+
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ContractParties is the data of the adopter, animal and fee printed in a contract
+type ContractParties struct {
+	Organization    string    `json:"organization,omitempty" bson:"organization,omitempty"`
+	AdopterName     string    `json:"adopter_name" bson:"adopter_name"`
+	AdopterEmail    string    `json:"adopter_email,omitempty" bson:"adopter_email,omitempty"`
+	AdopterPhone    string    `json:"adopter_phone,omitempty" bson:"adopter_phone,omitempty"`
+	AdopterAddress  string    `json:"adopter_address,omitempty" bson:"adopter_address,omitempty"`
+	AnimalName      string    `json:"animal_name" bson:"animal_name"`
+	Species         string    `json:"species,omitempty" bson:"species,omitempty"`
+	Breed           string    `json:"breed,omitempty" bson:"breed,omitempty"`
+	MicrochipNumber string    `json:"microchip_number,omitempty" bson:"microchip_number,omitempty"`
+	AdoptionDate    time.Time `json:"adoption_date" bson:"adoption_date"`
+	AdoptionFee     float64   `json:"adoption_fee" bson:"adoption_fee"`
+}
+
+// ContractSignature records how and by whom a contract was signed
+type ContractSignature struct {
+	Method    SignatureMethod      `json:"method" bson:"method"`
+	Name      string               `json:"name" bson:"name"`                                 // typed or printed name of the signer
+	ImageHash string               `json:"image_hash,omitempty" bson:"image_hash,omitempty"` // SHA-256 of a drawn signature
+	Consents  []ContractConsentKey `json:"consents" bson:"consents"`                         // consents ticked
+	SignedAt  time.Time            `json:"signed_at" bson:"signed_at"`
+	IPAddress string               `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
+	UserAgent string               `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+}
+
+// ContractEvent is an entry of the signing audit trail
+type ContractEvent struct {
+	Action    ContractEventAction `json:"action" bson:"action"`
+	At        time.Time           `json:"at" bson:"at"`
+	UserID    *primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"` // staff member; empty for the adopter
+	IPAddress string              `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
+	UserAgent string              `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	Details   string              `json:"details,omitempty" bson:"details,omitempty"`
+}
+
+// Contract is an adoption contract generated from a template and signed by the adopter through a tokenized link
+type Contract struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AdoptionID    primitive.ObjectID `json:"adoption_id" bson:"adoption_id"`
+	ApplicationID primitive.ObjectID `json:"application_id" bson:"application_id"`
+	AnimalID      primitive.ObjectID `json:"animal_id" bson:"animal_id"`
+
+	// The template version the text was generated from; empty for the built-in text
+	TemplateID      *primitive.ObjectID `json:"template_id,omitempty" bson:"template_id,omitempty"`
+	TemplateVersion int                 `json:"template_version" bson:"template_version"`
+	Language        string              `json:"language" bson:"language"`
+
+	Title    string            `json:"title" bson:"title"`
+	Parties  ContractParties   `json:"parties" bson:"parties"`
+	Sections []ContractSection `json:"sections" bson:"sections"`
+	Consents []ContractConsent `json:"consents" bson:"consents"`
+	// ContentHash fingerprints the text above when it was generated; the signed PDF prints it
+	ContentHash string `json:"content_hash" bson:"content_hash"`
+
+	Status ContractStatus `json:"status" bson:"status"`
+
+	// Only a hash of the signing token is stored; the token itself is in the link sent to the adopter
+	TokenHash      string    `json:"-" bson:"token_hash"`
+	TokenExpiresAt time.Time `json:"token_expires_at" bson:"token_expires_at"`
+
+	Signature    *ContractSignature  `json:"signature,omitempty" bson:"signature,omitempty"`
+	DocumentID   *primitive.ObjectID `json:"document_id,omitempty" bson:"document_id,omitempty"`     // the signed PDF
+	DocumentHash string              `json:"document_hash,omitempty" bson:"document_hash,omitempty"` // SHA-256 of the signed PDF
+	VoidReason   string              `json:"void_reason,omitempty" bson:"void_reason,omitempty"`
+
+	Events []ContractEvent `json:"events" bson:"events"`
+
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// HashToken returns the stored form of a signing token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsExpired checks if the signing link can no longer be used
+func (c *Contract) IsExpired(now time.Time) bool {
+	return c.Status == ContractStatusPending && now.After(c.TokenExpiresAt)
+}
+
+// AddEvent appends an entry to the audit trail
+func (c *Contract) AddEvent(event ContractEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	c.Events = append(c.Events, event)
+}
+
+// ComputeContentHash fingerprints the generated text, parties and consents
+func (c *Contract) ComputeContentHash() string {
+	var b strings.Builder
+	write := func(values ...string) {
+		for _, value := range values {
+			b.WriteString(value)
+			b.WriteByte(0)
+		}
+	}
+
+	write(c.ID.Hex(), c.AdoptionID.Hex(), c.Language, c.Title)
+	p := c.Parties
+	write(p.Organization, p.AdopterName, p.AdopterEmail, p.AdopterPhone, p.AdopterAddress,
+		p.AnimalName, p.Species, p.Breed, p.MicrochipNumber,
+		p.AdoptionDate.UTC().Format(time.RFC3339), strconv.FormatFloat(p.AdoptionFee, 'f', 2, 64))
+	for _, section := range c.Sections {
+		write(section.Heading, section.Body)
+	}
+	for _, consent := range c.Consents {
+		required := "optional"
+		if consent.Required {
+			required = "required"
+		}
+		write(string(consent.Key), consent.Text, required)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsIntact checks that the text still matches the hash taken when it was generated
+func (c *Contract) IsIntact() bool {
+	return c.ContentHash != "" && c.ContentHash == c.ComputeContentHash()
+}
+
+// Sign records the signature and closes the signing link
+func (c *Contract) Sign(signature ContractSignature) {
+	c.Status = ContractStatusSigned
+	c.Signature = &signature
+	c.TokenHash = ""
+	c.AddEvent(ContractEvent{
+		Action:    ContractEventSigned,
+		At:        signature.SignedAt,
+		IPAddress: signature.IPAddress,
+		UserAgent: signature.UserAgent,
+		Details:   string(signature.Method) + " signature by " + signature.Name,
+	})
+}
+
+// Void cancels a contract that was not signed, or withdraws a signed one
+func (c *Contract) Void(reason string, userID *primitive.ObjectID) {
+	c.Status = ContractStatusVoided
+	c.VoidReason = reason
+	c.TokenHash = ""
+	c.AddEvent(ContractEvent{Action: ContractEventVoided, UserID: userID, Details: reason})
+}
+
+// HasConsent checks if the signer ticked a consent
+func (s *ContractSignature) HasConsent(key ContractConsentKey) bool {
+	for _, consent := range s.Consents {
+		if consent == key {
+			return true
+		}
+	}
+	return false
+}
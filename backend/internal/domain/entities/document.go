@@ -23,6 +23,16 @@ const (
 	DocumentTypeOther          DocumentType = "other"
 )
 
+// DocumentScanStatus represents the malware scan state of a document file
+type DocumentScanStatus string
+
+const (
+	DocumentScanStatusPending  DocumentScanStatus = "pending"
+	DocumentScanStatusClean    DocumentScanStatus = "clean"
+	DocumentScanStatusInfected DocumentScanStatus = "infected"
+	DocumentScanStatusSkipped  DocumentScanStatus = "skipped" // Stored while no scanner was configured
+)
+
 // Document represents a file/document in the system
 type Document struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -36,6 +46,13 @@ type Document struct {
 	MimeType     string `json:"mime_type" bson:"mime_type"`
 	FileURL      string `json:"file_url" bson:"file_url"`
 	ThumbnailURL string `json:"thumbnail_url,omitempty" bson:"thumbnail_url,omitempty"`
+	Checksum     string `json:"checksum,omitempty" bson:"checksum,omitempty"` // SHA-256, hex encoded
+
+	// Malware scanning
+	ScanStatus    DocumentScanStatus `json:"scan_status,omitempty" bson:"scan_status,omitempty"`
+	ScanSignature string             `json:"scan_signature,omitempty" bson:"scan_signature,omitempty"`
+	ScannedBy     string             `json:"scanned_by,omitempty" bson:"scanned_by,omitempty"`
+	ScannedAt     *time.Time         `json:"scanned_at,omitempty" bson:"scanned_at,omitempty"`
 
 	// Related entities
 	RelatedEntity   string              `json:"related_entity,omitempty" bson:"related_entity,omitempty"`         // "animal", "adoption", "donor", etc.
@@ -149,6 +166,32 @@ func (d *Document) HasAccess(userID primitive.ObjectID) bool {
 	return false
 }
 
+// CanDownload checks if a user may download the file.
+// Confidential documents are only available to the uploader and the access list, even when public.
+func (d *Document) CanDownload(userID primitive.ObjectID) bool {
+	if !d.IsConfidential {
+		return d.HasAccess(userID)
+	}
+
+	if d.UploadedBy == userID {
+		return true
+	}
+
+	for _, id := range d.AccessibleBy {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAvailable reports whether the file passed the malware scan.
+// Documents stored before scanning was introduced have no scan status.
+func (d *Document) IsAvailable() bool {
+	return d.ScanStatus == "" || d.ScanStatus == DocumentScanStatusClean || d.ScanStatus == DocumentScanStatusSkipped
+}
+
 // CheckExpiration checks and updates expiration status
 func (d *Document) CheckExpiration() {
 	if d.ExpiresAt != nil && time.Now().After(*d.ExpiresAt) {
@@ -111,6 +111,7 @@ type Donation struct {
 	CampaignName string              `json:"campaign_name,omitempty" bson:"campaign_name,omitempty"`
 	Designation  string              `json:"designation,omitempty" bson:"designation,omitempty"` // e.g., "general", "medical", "building"
 	Restricted   bool                `json:"restricted" bson:"restricted"` // Restricted to specific use
+	EventID      *primitive.ObjectID `json:"event_id,omitempty" bson:"event_id,omitempty"` // Event the donation was made at
 
 	// Payment Information
 	Payment      PaymentInfo `json:"payment" bson:"payment"`
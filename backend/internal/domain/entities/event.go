@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -43,13 +44,41 @@ type EventLocation struct {
 	Longitude float64 `json:"longitude,omitempty" bson:"longitude,omitempty"`
 }
 
+// String returns the address on one line, e.g. for calendar invites
+func (l EventLocation) String() string {
+	var parts []string
+	for _, part := range []string{l.Name, l.Address, l.City, l.ZipCode, l.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// EventReminder records a reminder sent to the attendees and volunteers of an event
+type EventReminder struct {
+	OffsetMinutes int       `json:"offset_minutes" bson:"offset_minutes"` // Minutes before the start
+	SentAt        time.Time `json:"sent_at" bson:"sent_at"`
+	Recipients    int       `json:"recipients" bson:"recipients"`
+}
+
+// EventCost is an expense of running an event, e.g. a venue fee or printed materials
+type EventCost struct {
+	Description string  `json:"description" bson:"description"`
+	Category    string  `json:"category,omitempty" bson:"category,omitempty"` // e.g. "venue", "marketing", "supplies"
+	Amount      float64 `json:"amount" bson:"amount"`
+}
+
 // EventRegistration represents registration requirements
 type EventRegistration struct {
 	Required       bool      `json:"required" bson:"required"`
 	MaxAttendees   int       `json:"max_attendees,omitempty" bson:"max_attendees,omitempty"`
 	CurrentCount   int       `json:"current_count" bson:"current_count"`
-	RegistrationFee float64  `json:"registration_fee,omitempty" bson:"registration_fee,omitempty"`
+	RegistrationFee float64  `json:"registration_fee,omitempty" bson:"registration_fee,omitempty"` // Per seat
+	Currency       string     `json:"currency,omitempty" bson:"currency,omitempty"`                  // Of the fee; defaults to the payment currency
 	Deadline       *time.Time `json:"deadline,omitempty" bson:"deadline,omitempty"`
+	Waitlist       bool       `json:"waitlist" bson:"waitlist"`             // Queue registrations once the event is full
+	WaitlistCount  int        `json:"waitlist_count" bson:"waitlist_count"` // Registrations waiting for a seat
 }
 
 // Event represents a foundation event
@@ -86,6 +115,7 @@ type Event struct {
 	ContactPhone      string               `json:"contact_phone,omitempty" bson:"contact_phone,omitempty"`
 	RequiredVolunteers int                 `json:"required_volunteers" bson:"required_volunteers"`
 	AssignedVolunteers []primitive.ObjectID `json:"assigned_volunteers,omitempty" bson:"assigned_volunteers,omitempty"`
+	Animals            []primitive.ObjectID `json:"animals,omitempty" bson:"animals,omitempty"` // Animals brought to the event, e.g. for adoption days
 
 	// Campaign Association
 	CampaignID *primitive.ObjectID `json:"campaign_id,omitempty" bson:"campaign_id,omitempty"` // Link to fundraising campaign
@@ -95,10 +125,25 @@ type Event struct {
 	VolunteerCount     int     `json:"volunteer_count" bson:"volunteer_count"`
 	FundsRaised        float64 `json:"funds_raised,omitempty" bson:"funds_raised,omitempty"`
 	AnimalsAdopted     int     `json:"animals_adopted,omitempty" bson:"animals_adopted,omitempty"`
+	VolunteerHours     float64 `json:"volunteer_hours,omitempty" bson:"volunteer_hours,omitempty"`
+	OutcomesUpdatedAt  *time.Time `json:"outcomes_updated_at,omitempty" bson:"outcomes_updated_at,omitempty"` // Outcomes last computed from donations, adoptions and volunteer hours
+
+	// Budget
+	Costs []EventCost `json:"costs,omitempty" bson:"costs"`
 
 	// Additional Information
 	Notes string `json:"notes,omitempty" bson:"notes,omitempty"`
 
+	// Invitations
+	Sequence      int             `json:"sequence" bson:"sequence"` // Revision of the calendar invite, raised with every change sent to attendees
+	RemindersSent []EventReminder `json:"reminders_sent,omitempty" bson:"reminders_sent,omitempty"`
+	FeedbackRequestedAt *time.Time `json:"feedback_requested_at,omitempty" bson:"feedback_requested_at,omitempty"` // Feedback surveys sent to the attendees
+
+	// Series
+	SeriesID       *primitive.ObjectID `json:"series_id,omitempty" bson:"series_id,omitempty"`
+	OccurrenceDate *time.Time          `json:"occurrence_date,omitempty" bson:"occurrence_date,omitempty"` // Start the recurrence rule gave the occurrence
+	Detached       bool                `json:"detached,omitempty" bson:"detached"`                         // Edited on its own; changes to the series leave it alone
+
 	// Metadata
 	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
 	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
@@ -126,6 +171,58 @@ func (e *Event) IsActive() bool {
 	return true
 }
 
+// EndTime returns when the event ends: its end date, or the start plus its duration
+func (e *Event) EndTime() time.Time {
+	if e.EndDate != nil {
+		return *e.EndDate
+	}
+	return e.StartDate.Add(time.Duration(e.Duration) * time.Minute)
+}
+
+// DisplayName returns the name of the event used in messages: the English one, or the
+// Polish one when there is none
+func (e *Event) DisplayName() string {
+	if e.Name.English != "" {
+		return e.Name.English
+	}
+	return e.Name.Polish
+}
+
+// TotalCost sums the costs of the event
+func (e *Event) TotalCost() float64 {
+	total := 0.0
+	for _, cost := range e.Costs {
+		total += cost.Amount
+	}
+	return total
+}
+
+// DueReminder returns the reminder offset to send now: the shortest offset whose time
+// has come, unless it was sent already. Longer offsets missed in the meantime, e.g.
+// for events scheduled at short notice, are skipped.
+func (e *Event) DueReminder(now time.Time, offsets []time.Duration) (time.Duration, bool) {
+	if !now.Before(e.StartDate) {
+		return 0, false
+	}
+
+	var due time.Duration
+	found := false
+	for _, offset := range offsets {
+		if !now.Before(e.StartDate.Add(-offset)) && (!found || offset < due) {
+			due, found = offset, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	for _, reminder := range e.RemindersSent {
+		if time.Duration(reminder.OffsetMinutes)*time.Minute == due {
+			return 0, false
+		}
+	}
+	return due, true
+}
+
 // IsFull checks if the event has reached max capacity
 func (e *Event) IsFull() bool {
 	if !e.Registration.Required || e.Registration.MaxAttendees == 0 {
@@ -134,6 +231,11 @@ func (e *Event) IsFull() bool {
 	return e.Registration.CurrentCount >= e.Registration.MaxAttendees
 }
 
+// RegistrationClosed checks if the registration deadline has passed
+func (e *Event) RegistrationClosed(now time.Time) bool {
+	return e.Registration.Deadline != nil && now.After(*e.Registration.Deadline)
+}
+
 // NeedsVolunteers checks if more volunteers are needed
 func (e *Event) NeedsVolunteers() bool {
 	return len(e.AssignedVolunteers) < e.RequiredVolunteers
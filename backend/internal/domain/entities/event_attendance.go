@@ -26,6 +26,7 @@ const (
 	AttendanceStatusAttended   AttendanceStatus = "attended"
 	AttendanceStatusNoShow     AttendanceStatus = "no_show"
 	AttendanceStatusCancelled  AttendanceStatus = "cancelled"
+	AttendanceStatusWaitlisted AttendanceStatus = "waitlisted"
 )
 
 // EventAttendance represents an attendee's registration and attendance for an event
@@ -51,6 +52,7 @@ type EventAttendance struct {
 	RegistrationDate time.Time        `json:"registration_date" bson:"registration_date"`
 	ConfirmationDate *time.Time       `json:"confirmation_date,omitempty" bson:"confirmation_date,omitempty"`
 	CancellationDate *time.Time       `json:"cancellation_date,omitempty" bson:"cancellation_date,omitempty"`
+	PromotedDate     *time.Time       `json:"promoted_date,omitempty" bson:"promoted_date,omitempty"` // Moved off the waitlist
 
 	// Payment (if registration has a fee)
 	RegistrationFee     float64 `json:"registration_fee" bson:"registration_fee"`
@@ -58,6 +60,9 @@ type EventAttendance struct {
 	PaymentDate         *time.Time `json:"payment_date,omitempty" bson:"payment_date,omitempty"`
 	PaymentMethod       string  `json:"payment_method,omitempty" bson:"payment_method,omitempty"`
 	TransactionID       string  `json:"transaction_id,omitempty" bson:"transaction_id,omitempty"`
+	CheckoutSessionID   string     `json:"checkout_session_id,omitempty" bson:"checkout_session_id,omitempty"` // Open checkout of the payment gateway
+	PaymentDueAt        *time.Time `json:"payment_due_at,omitempty" bson:"payment_due_at,omitempty"`           // Unpaid online registrations are cancelled after this
+	TicketSentAt        *time.Time `json:"ticket_sent_at,omitempty" bson:"ticket_sent_at,omitempty"`
 
 	// Attendance Tracking
 	CheckInTime  *time.Time `json:"check_in_time,omitempty" bson:"check_in_time,omitempty"`
@@ -73,6 +78,9 @@ type EventAttendance struct {
 	Rating   int    `json:"rating,omitempty" bson:"rating,omitempty"`           // 1-5 stars
 	Feedback string `json:"feedback,omitempty" bson:"feedback,omitempty"`
 	FeedbackDate *time.Time `json:"feedback_date,omitempty" bson:"feedback_date,omitempty"`
+	FeedbackRequestedAt    *time.Time `json:"feedback_requested_at,omitempty" bson:"feedback_requested_at,omitempty"` // Survey link sent to the attendee
+	FeedbackTokenHash      string     `json:"-" bson:"feedback_token_hash,omitempty"`
+	FeedbackTokenExpiresAt *time.Time `json:"feedback_token_expires_at,omitempty" bson:"feedback_token_expires_at,omitempty"`
 
 	// Metadata
 	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
@@ -108,6 +116,32 @@ func (ea *EventAttendance) Cancel() {
 	ea.Status = AttendanceStatusCancelled
 }
 
+// Waitlist queues the registration until a seat frees up
+func (ea *EventAttendance) Waitlist() {
+	ea.Status = AttendanceStatusWaitlisted
+}
+
+// Promote gives a waitlisted registration its seats
+func (ea *EventAttendance) Promote() {
+	now := time.Now()
+	ea.PromotedDate = &now
+	ea.Status = AttendanceStatusRegistered
+}
+
+// Seats returns the number of seats the registration takes: the attendee and their guests
+func (ea *EventAttendance) Seats() int {
+	return 1 + ea.NumberOfGuests
+}
+
+// HoldsSeats checks if the registration counts against the event capacity
+func (ea *EventAttendance) HoldsSeats() bool {
+	switch ea.Status {
+	case AttendanceStatusRegistered, AttendanceStatusConfirmed, AttendanceStatusAttended:
+		return true
+	}
+	return false
+}
+
 // MarkNoShow marks the attendee as no-show
 func (ea *EventAttendance) MarkNoShow() {
 	ea.Status = AttendanceStatusNoShow
@@ -140,6 +174,16 @@ func (ea *EventAttendance) SubmitFeedback(rating int, feedback string) {
 	ea.FeedbackDate = &now
 }
 
+// HasFeedback reports whether the attendee rated the event
+func (ea *EventAttendance) HasFeedback() bool {
+	return ea.FeedbackDate != nil
+}
+
+// FeedbackLinkOpen reports whether the feedback survey link of the attendee can still be used
+func (ea *EventAttendance) FeedbackLinkOpen(now time.Time) bool {
+	return !ea.HasFeedback() && ea.FeedbackTokenExpiresAt != nil && now.Before(*ea.FeedbackTokenExpiresAt)
+}
+
 // IsPaid checks if the registration fee has been paid
 func (ea *EventAttendance) IsPaid() bool {
 	if ea.RegistrationFee == 0 {
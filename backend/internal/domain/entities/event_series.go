@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventSeriesStatus represents the status of an event series
+type EventSeriesStatus string
+
+const (
+	EventSeriesStatusActive    EventSeriesStatus = "active"
+	EventSeriesStatusCancelled EventSeriesStatus = "cancelled"
+)
+
+// EventSeries is a recurring event, e.g. a weekly adoption day. Its occurrences are
+// regular events generated from the template below and the recurrence rule.
+type EventSeries struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+
+	// Template
+	Name        MultilingualName  `json:"name" bson:"name"`
+	Description MultilingualName  `json:"description" bson:"description"`
+	Type        EventType         `json:"type" bson:"type"`
+	Status      EventSeriesStatus `json:"status" bson:"status"`
+
+	// Schedule
+	Recurrence string    `json:"recurrence" bson:"recurrence"`                 // RRULE, e.g. "FREQ=WEEKLY;BYDAY=SA;COUNT=12"
+	StartDate  time.Time `json:"start_date" bson:"start_date"`                 // First occurrence; later ones start at the same local time
+	Timezone   string    `json:"timezone,omitempty" bson:"timezone,omitempty"` // IANA name, e.g. "Europe/Warsaw"; UTC if empty
+	Duration   int       `json:"duration" bson:"duration"`                     // Duration in minutes
+
+	// Location
+	Location    EventLocation `json:"location" bson:"location"`
+	VirtualLink string        `json:"virtual_link,omitempty" bson:"virtual_link,omitempty"`
+
+	// Registration settings shared by the occurrences; the counts are kept per occurrence
+	Registration             EventRegistration `json:"registration" bson:"registration"`
+	RegistrationCloseMinutes int               `json:"registration_close_minutes,omitempty" bson:"registration_close_minutes,omitempty"` // Registration closes this long before each occurrence
+
+	// Details
+	ImageURL string   `json:"image_url,omitempty" bson:"image_url,omitempty"`
+	Tags     []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	Public   bool     `json:"public" bson:"public"`
+	Featured bool     `json:"featured" bson:"featured"`
+
+	// Organization
+	Organizer          primitive.ObjectID  `json:"organizer" bson:"organizer"`
+	ContactEmail       string              `json:"contact_email,omitempty" bson:"contact_email,omitempty"`
+	ContactPhone       string              `json:"contact_phone,omitempty" bson:"contact_phone,omitempty"`
+	RequiredVolunteers int                 `json:"required_volunteers" bson:"required_volunteers"`
+	CampaignID         *primitive.ObjectID `json:"campaign_id,omitempty" bson:"campaign_id,omitempty"`
+
+	// Generation
+	GeneratedUntil time.Time           `json:"generated_until" bson:"generated_until"`         // Occurrences exist up to here
+	ParentID       *primitive.ObjectID `json:"parent_id,omitempty" bson:"parent_id,omitempty"` // Series this one was split off by a "this and following" change
+
+	// Metadata
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// TimeLocation returns the time zone the occurrences are scheduled in, UTC if it is not
+// set or unknown
+func (s *EventSeries) TimeLocation() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// NewOccurrence creates the occurrence of the series starting at start
+func (s *EventSeries) NewOccurrence(start time.Time, createdBy primitive.ObjectID) *Event {
+	occurrence := NewEvent(s.Name, s.Type, start, s.Organizer, createdBy)
+	occurrence.Status = EventStatusScheduled
+	occurrence.SeriesID = &s.ID
+	occurrence.OccurrenceDate = &start
+	s.ApplyTo(occurrence)
+	return occurrence
+}
+
+// ApplyTo copies the template of the series to an occurrence. The start of the
+// occurrence, its status, counts, assignments and notes are left as they are.
+func (s *EventSeries) ApplyTo(occurrence *Event) {
+	occurrence.Name = s.Name
+	occurrence.Description = s.Description
+	occurrence.Type = s.Type
+	occurrence.Duration = s.Duration
+	occurrence.EndDate = nil
+	occurrence.Location = s.Location
+	occurrence.VirtualLink = s.VirtualLink
+	occurrence.ImageURL = s.ImageURL
+	occurrence.Tags = s.Tags
+	occurrence.Public = s.Public
+	occurrence.Featured = s.Featured
+	occurrence.Organizer = s.Organizer
+	occurrence.ContactEmail = s.ContactEmail
+	occurrence.ContactPhone = s.ContactPhone
+	occurrence.RequiredVolunteers = s.RequiredVolunteers
+	occurrence.CampaignID = s.CampaignID
+
+	registration := s.Registration
+	registration.CurrentCount = occurrence.Registration.CurrentCount
+	registration.WaitlistCount = occurrence.Registration.WaitlistCount
+	registration.Deadline = nil
+	if s.RegistrationCloseMinutes > 0 {
+		deadline := occurrence.StartDate.Add(-time.Duration(s.RegistrationCloseMinutes) * time.Minute)
+		registration.Deadline = &deadline
+	}
+	occurrence.Registration = registration
+}
@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FollowUpTypeSurvey is the type of follow-ups answered by the adopter through a survey link
+const FollowUpTypeSurvey = "survey"
+
+// FollowUpSettling is how the adopter says the animal is settling in
+type FollowUpSettling string
+
+const (
+	FollowUpSettlingVeryWell   FollowUpSettling = "very_well"
+	FollowUpSettlingWell       FollowUpSettling = "well"
+	FollowUpSettlingStruggling FollowUpSettling = "struggling"
+	FollowUpSettlingNotSettled FollowUpSettling = "not_settled"
+)
+
+// FollowUpSurvey holds the adopter's answers to a follow-up survey
+type FollowUpSurvey struct {
+	Settling          FollowUpSettling `json:"settling" bson:"settling"`
+	EatingWell        bool             `json:"eating_well" bson:"eating_well"`
+	HealthConcerns    string           `json:"health_concerns,omitempty" bson:"health_concerns,omitempty"`
+	BehaviorConcerns  string           `json:"behavior_concerns,omitempty" bson:"behavior_concerns,omitempty"`
+	ConsideringReturn bool             `json:"considering_return" bson:"considering_return"`
+	Comments          string           `json:"comments,omitempty" bson:"comments,omitempty"`
+	SubmittedAt       time.Time        `json:"submitted_at" bson:"submitted_at"`
+	Concerns          []string         `json:"concerns,omitempty" bson:"concerns,omitempty"` // Why the answers were escalated to staff
+}
+
+// Assess returns the answers staff should look into, if any
+func (s *FollowUpSurvey) Assess() []string {
+	var concerns []string
+	switch s.Settling {
+	case FollowUpSettlingStruggling:
+		concerns = append(concerns, "The animal is struggling to settle in")
+	case FollowUpSettlingNotSettled:
+		concerns = append(concerns, "The animal is not settling in")
+	}
+	if !s.EatingWell {
+		concerns = append(concerns, "The animal is not eating well")
+	}
+	if text := strings.TrimSpace(s.HealthConcerns); text != "" {
+		concerns = append(concerns, "Health: "+text)
+	}
+	if text := strings.TrimSpace(s.BehaviorConcerns); text != "" {
+		concerns = append(concerns, "Behavior: "+text)
+	}
+	if s.ConsideringReturn {
+		concerns = append(concerns, "The adopter is considering returning the animal")
+	}
+	return concerns
+}
+
+// FollowUpSurveySettings configures the messages carrying the survey link
+type FollowUpSurveySettings struct {
+	EmailTemplateID *primitive.ObjectID `json:"email_template_id,omitempty" bson:"email_template_id,omitempty"` // Communication template; the built-in message is used when empty
+	SMSTemplateID   *primitive.ObjectID `json:"sms_template_id,omitempty" bson:"sms_template_id,omitempty"`
+	LinkValidDays   int                 `json:"link_valid_days" bson:"link_valid_days" validate:"min=1,max=90"`
+}
+
+// DefaultFollowUpSurveySettings returns the settings used until they are configured
+func DefaultFollowUpSurveySettings() *FollowUpSurveySettings {
+	return &FollowUpSurveySettings{LinkValidDays: 14}
+}
+
+// IsAnswered reports whether the adopter submitted the survey of the follow-up
+func (f *FollowUpSchedule) IsAnswered() bool {
+	return f.Survey != nil
+}
+
+// IsDue reports whether the survey of the follow-up should be sent
+func (f *FollowUpSchedule) IsDue(now time.Time) bool {
+	return f.CompletedDate == nil && f.SentAt == nil && !f.ScheduledDate.After(now)
+}
+
+// LinkOpen reports whether the survey link of the follow-up can still be used
+func (f *FollowUpSchedule) LinkOpen(now time.Time) bool {
+	return f.CompletedDate == nil && f.TokenExpiresAt != nil && now.Before(*f.TokenExpiresAt)
+}
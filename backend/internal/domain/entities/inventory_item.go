@@ -1,6 +1,8 @@
 package entities
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -83,7 +85,10 @@ type InventoryItem struct {
 
 	// Expiration
 	HasExpiration bool       `json:"has_expiration" bson:"has_expiration"`
-	ExpirationDate *time.Time `json:"expiration_date,omitempty" bson:"expiration_date,omitempty"`
+	ExpirationDate *time.Time `json:"expiration_date,omitempty" bson:"expiration_date,omitempty"` // earliest lot expiry when lots are tracked
+
+	// Lots received with a lot number or expiry; stock not covered by lots is untracked
+	Lots []StockLot `json:"lots,omitempty" bson:"lots,omitempty"`
 
 	// Tracking
 	IsActive      bool   `json:"is_active" bson:"is_active"`
@@ -211,3 +216,202 @@ func (i *InventoryItem) GetStockPercentage() float64 {
 	}
 	return (i.CurrentStock / i.MaximumStock) * 100
 }
+
+// StockLot is a received batch of an item with its own lot number and expiry
+type StockLot struct {
+	LotNumber      string     `json:"lot_number,omitempty" bson:"lot_number,omitempty"`
+	ExpirationDate *time.Time `json:"expiration_date,omitempty" bson:"expiration_date,omitempty"`
+	Quantity       float64    `json:"quantity" bson:"quantity"`
+	UnitCost       float64    `json:"unit_cost" bson:"unit_cost"`
+	ReceivedAt     time.Time  `json:"received_at" bson:"received_at"`
+}
+
+// IsExpired checks if the lot is past its expiry
+func (l *StockLot) IsExpired(now time.Time) bool {
+	return l.ExpirationDate != nil && now.After(*l.ExpirationDate)
+}
+
+// LotConsumption is the quantity taken from one lot.
+// An empty lot number means untracked stock.
+type LotConsumption struct {
+	LotNumber      string
+	ExpirationDate *time.Time
+	Quantity       float64
+	UnitCost       float64
+}
+
+// AddLot records a received lot, merging it with an existing lot of the same number
+func (i *InventoryItem) AddLot(lot StockLot) {
+	if lot.LotNumber != "" {
+		for idx := range i.Lots {
+			if i.Lots[idx].LotNumber == lot.LotNumber {
+				i.Lots[idx].Quantity += lot.Quantity
+				i.syncLotExpiration()
+				return
+			}
+		}
+	}
+	i.Lots = append(i.Lots, lot)
+	i.syncLotExpiration()
+}
+
+// ConsumeFEFO removes stock first-expired-first-out. Expired lots are skipped,
+// untracked stock is used after all lots. When lotNumber is set only that lot is used.
+func (i *InventoryItem) ConsumeFEFO(quantity float64, lotNumber string, now time.Time) ([]LotConsumption, error) {
+	if i.CurrentStock < quantity {
+		return nil, fmt.Errorf("insufficient stock of %s", i.Name)
+	}
+
+	lots := make([]int, 0, len(i.Lots))
+	tracked := 0.0
+	for idx, lot := range i.Lots {
+		tracked += lot.Quantity
+		if lot.Quantity <= 0 || lot.IsExpired(now) {
+			continue
+		}
+		if lotNumber != "" && lot.LotNumber != lotNumber {
+			continue
+		}
+		lots = append(lots, idx)
+	}
+
+	if lotNumber != "" && len(lots) == 0 {
+		return nil, fmt.Errorf("lot %s of %s is not in stock or has expired", lotNumber, i.Name)
+	}
+
+	sort.SliceStable(lots, func(a, b int) bool {
+		la, lb := i.Lots[lots[a]], i.Lots[lots[b]]
+		switch {
+		case la.ExpirationDate == nil && lb.ExpirationDate == nil:
+			return la.ReceivedAt.Before(lb.ReceivedAt)
+		case la.ExpirationDate == nil:
+			return false
+		case lb.ExpirationDate == nil:
+			return true
+		case !la.ExpirationDate.Equal(*lb.ExpirationDate):
+			return la.ExpirationDate.Before(*lb.ExpirationDate)
+		}
+		return la.ReceivedAt.Before(lb.ReceivedAt)
+	})
+
+	var consumed []LotConsumption
+	remaining := quantity
+	for _, idx := range lots {
+		if remaining <= 0 {
+			break
+		}
+		lot := i.Lots[idx]
+		take := lot.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		consumed = append(consumed, LotConsumption{
+			LotNumber:      lot.LotNumber,
+			ExpirationDate: lot.ExpirationDate,
+			Quantity:       take,
+			UnitCost:       lot.UnitCost,
+		})
+		remaining -= take
+	}
+
+	if untracked := i.CurrentStock - tracked; remaining > 0 && lotNumber == "" && untracked > 0 {
+		take := untracked
+		if take > remaining {
+			take = remaining
+		}
+		consumed = append(consumed, LotConsumption{Quantity: take, UnitCost: i.UnitCost})
+		remaining -= take
+	}
+
+	if remaining > 1e-9 {
+		return nil, fmt.Errorf("insufficient usable stock of %s: %.2f %s missing", i.Name, remaining, i.Unit)
+	}
+
+	for _, c := range consumed {
+		if c.LotNumber == "" && c.ExpirationDate == nil {
+			continue
+		}
+		i.takeFromLot(c)
+	}
+	i.RemoveStock(quantity)
+	i.syncLotExpiration()
+
+	return consumed, nil
+}
+
+// ReconcileLots shrinks the lots after a count correction so they never hold more than
+// the current stock, dropping the earliest expiring lots first
+func (i *InventoryItem) ReconcileLots() {
+	excess := -i.CurrentStock
+	for _, lot := range i.Lots {
+		excess += lot.Quantity
+	}
+	if excess <= 0 {
+		return
+	}
+
+	sort.SliceStable(i.Lots, func(a, b int) bool {
+		ea, eb := i.Lots[a].ExpirationDate, i.Lots[b].ExpirationDate
+		if ea == nil || eb == nil {
+			return eb == nil && ea != nil
+		}
+		return ea.Before(*eb)
+	})
+
+	kept := i.Lots[:0]
+	for _, lot := range i.Lots {
+		if excess > 0 {
+			take := lot.Quantity
+			if take > excess {
+				take = excess
+			}
+			lot.Quantity -= take
+			excess -= take
+		}
+		if lot.Quantity > 0 {
+			kept = append(kept, lot)
+		}
+	}
+	i.Lots = kept
+	i.syncLotExpiration()
+}
+
+func (i *InventoryItem) takeFromLot(c LotConsumption) {
+	for idx := range i.Lots {
+		lot := &i.Lots[idx]
+		if lot.LotNumber == c.LotNumber && sameExpiry(lot.ExpirationDate, c.ExpirationDate) && lot.Quantity >= c.Quantity {
+			lot.Quantity -= c.Quantity
+			if lot.Quantity <= 0 {
+				i.Lots = append(i.Lots[:idx], i.Lots[idx+1:]...)
+			}
+			return
+		}
+	}
+}
+
+func sameExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// syncLotExpiration keeps the item expiry at the earliest expiry of the lots in stock
+func (i *InventoryItem) syncLotExpiration() {
+	var earliest *time.Time
+	for _, lot := range i.Lots {
+		if lot.Quantity <= 0 || lot.ExpirationDate == nil {
+			continue
+		}
+		if earliest == nil || lot.ExpirationDate.Before(*earliest) {
+			expiry := *lot.ExpirationDate
+			earliest = &expiry
+		}
+	}
+
+	if earliest != nil {
+		i.HasExpiration = true
+		i.ExpirationDate = earliest
+	}
+	i.CheckExpiration()
+}
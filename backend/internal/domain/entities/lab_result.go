@@ -0,0 +1,300 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LabFlag marks a lab value against its reference range
+type LabFlag string
+
+const (
+	LabFlagNormal       LabFlag = "normal"
+	LabFlagLow          LabFlag = "low"
+	LabFlagHigh         LabFlag = "high"
+	LabFlagCriticalLow  LabFlag = "critical_low"
+	LabFlagCriticalHigh LabFlag = "critical_high"
+)
+
+// IsAbnormal checks if the flag is outside the normal range
+func (f LabFlag) IsAbnormal() bool {
+	return f != "" && f != LabFlagNormal
+}
+
+// IsCritical checks if the flag is a critical value
+func (f LabFlag) IsCritical() bool {
+	return f == LabFlagCriticalLow || f == LabFlagCriticalHigh
+}
+
+// LabPanelStatus represents the reporting state of a lab panel
+type LabPanelStatus string
+
+const (
+	LabPanelStatusPreliminary LabPanelStatus = "preliminary"
+	LabPanelStatusFinal       LabPanelStatus = "final"
+	LabPanelStatusCorrected   LabPanelStatus = "corrected"
+)
+
+// LabResultSource identifies how a panel was entered
+type LabResultSource string
+
+const (
+	LabResultSourceManual LabResultSource = "manual"
+	LabResultSourceCSV    LabResultSource = "csv"
+	LabResultSourceHL7    LabResultSource = "hl7"
+)
+
+// LabAnalyte defines a measured quantity, e.g. ALT or hematocrit, with its
+// reference ranges per species
+type LabAnalyte struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+
+	Code     string            `json:"code" bson:"code"` // e.g. "ALT", matched case-insensitively
+	Name     string            `json:"name" bson:"name"`
+	Unit     string            `json:"unit" bson:"unit"`
+	Category string            `json:"category,omitempty" bson:"category,omitempty"` // "hematology", "chemistry", "electrolytes", ...
+	Ranges   []LabSpeciesRange `json:"ranges,omitempty" bson:"ranges,omitempty"`
+	Active   bool              `json:"active" bson:"active"`
+
+	// Metadata
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// LabSpeciesRange is the reference range of an analyte for one species, in the analyte unit.
+// Critical limits are optional.
+type LabSpeciesRange struct {
+	Species      string   `json:"species" bson:"species"`
+	Low          *float64 `json:"low,omitempty" bson:"low,omitempty"`
+	High         *float64 `json:"high,omitempty" bson:"high,omitempty"`
+	CriticalLow  *float64 `json:"critical_low,omitempty" bson:"critical_low,omitempty"`
+	CriticalHigh *float64 `json:"critical_high,omitempty" bson:"critical_high,omitempty"`
+}
+
+// NormalizeAnalyteCode returns the code analytes are matched on
+func NormalizeAnalyteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// RangeFor returns the reference range of a species, or nil if the analyte has none
+func (a *LabAnalyte) RangeFor(species string) *LabSpeciesRange {
+	species = NormalizeSpecies(species)
+	for i := range a.Ranges {
+		if a.Ranges[i].Species == species {
+			return &a.Ranges[i]
+		}
+	}
+	return nil
+}
+
+// LabResultValue is one analyte result of a panel. Numeric results carry Value,
+// qualitative results (e.g. "negative") carry Text.
+type LabResultValue struct {
+	AnalyteCode string   `json:"analyte_code" bson:"analyte_code"`
+	Name        string   `json:"name,omitempty" bson:"name,omitempty"`
+	Value       *float64 `json:"value,omitempty" bson:"value,omitempty"`
+	Text        string   `json:"text,omitempty" bson:"text,omitempty"`
+	Unit        string   `json:"unit,omitempty" bson:"unit,omitempty"`
+
+	// Reference range used for the flag, from the lab report or the analyte definition
+	ReferenceLow  *float64 `json:"reference_low,omitempty" bson:"reference_low,omitempty"`
+	ReferenceHigh *float64 `json:"reference_high,omitempty" bson:"reference_high,omitempty"`
+	CriticalLow   *float64 `json:"critical_low,omitempty" bson:"critical_low,omitempty"`
+	CriticalHigh  *float64 `json:"critical_high,omitempty" bson:"critical_high,omitempty"`
+
+	// Flag is computed from the range, or taken from the lab when there is no range
+	Flag  LabFlag `json:"flag,omitempty" bson:"flag,omitempty"`
+	Notes string  `json:"notes,omitempty" bson:"notes,omitempty"`
+}
+
+// ApplyRange fills in the reference range from an analyte definition where
+// the lab did not report one. Ranges in another unit are not applied.
+func (r *LabResultValue) ApplyRange(analyte *LabAnalyte, species string) {
+	if r.Name == "" {
+		r.Name = analyte.Name
+	}
+	if r.Unit == "" {
+		r.Unit = analyte.Unit
+	}
+	if !strings.EqualFold(r.Unit, analyte.Unit) {
+		return
+	}
+
+	rng := analyte.RangeFor(species)
+	if rng == nil {
+		return
+	}
+	if r.ReferenceLow == nil && r.ReferenceHigh == nil {
+		r.ReferenceLow = rng.Low
+		r.ReferenceHigh = rng.High
+	}
+	if r.CriticalLow == nil {
+		r.CriticalLow = rng.CriticalLow
+	}
+	if r.CriticalHigh == nil {
+		r.CriticalHigh = rng.CriticalHigh
+	}
+}
+
+// Evaluate sets the flag from the reference and critical limits. Results
+// without a numeric value or a range keep the flag reported by the lab.
+func (r *LabResultValue) Evaluate() {
+	if r.Value == nil {
+		return
+	}
+	if r.ReferenceLow == nil && r.ReferenceHigh == nil && r.CriticalLow == nil && r.CriticalHigh == nil {
+		return
+	}
+
+	value := *r.Value
+	switch {
+	case r.CriticalLow != nil && value <= *r.CriticalLow:
+		r.Flag = LabFlagCriticalLow
+	case r.CriticalHigh != nil && value >= *r.CriticalHigh:
+		r.Flag = LabFlagCriticalHigh
+	case r.ReferenceLow != nil && value < *r.ReferenceLow:
+		r.Flag = LabFlagLow
+	case r.ReferenceHigh != nil && value > *r.ReferenceHigh:
+		r.Flag = LabFlagHigh
+	default:
+		r.Flag = LabFlagNormal
+	}
+}
+
+// LabPanel is a set of lab results reported together, e.g. a CBC or a chemistry panel
+type LabPanel struct {
+	ID       primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	AnimalID primitive.ObjectID  `json:"animal_id" bson:"animal_id"`
+	VisitID  *primitive.ObjectID `json:"visit_id,omitempty" bson:"visit_id,omitempty"`
+	Species  string              `json:"species,omitempty" bson:"species,omitempty"`
+
+	Name            string              `json:"name" bson:"name"` // e.g. "CBC", "Chemistry 17"
+	LabName         string              `json:"lab_name,omitempty" bson:"lab_name,omitempty"`
+	PartnerID       *primitive.ObjectID `json:"partner_id,omitempty" bson:"partner_id,omitempty"` // Partner clinic or lab
+	AccessionNumber string              `json:"accession_number,omitempty" bson:"accession_number,omitempty"`
+	Status          LabPanelStatus      `json:"status" bson:"status"`
+	Source          LabResultSource     `json:"source" bson:"source"`
+
+	CollectedAt time.Time  `json:"collected_at" bson:"collected_at"`
+	ReportedAt  *time.Time `json:"reported_at,omitempty" bson:"reported_at,omitempty"`
+
+	Results []LabResultValue `json:"results" bson:"results"`
+
+	// Summary of the flags, kept in sync with the results
+	AbnormalCount int  `json:"abnormal_count" bson:"abnormal_count"`
+	HasCritical   bool `json:"has_critical" bson:"has_critical"`
+
+	// Lab report PDF
+	DocumentID *primitive.ObjectID `json:"document_id,omitempty" bson:"document_id,omitempty"`
+
+	Notes string `json:"notes,omitempty" bson:"notes,omitempty"`
+
+	// Metadata
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Evaluate flags the results against the analyte definitions and updates the summary.
+// analytes is keyed by normalized analyte code.
+func (p *LabPanel) Evaluate(analytes map[string]*LabAnalyte) {
+	p.AbnormalCount = 0
+	p.HasCritical = false
+
+	for i := range p.Results {
+		result := &p.Results[i]
+		result.AnalyteCode = NormalizeAnalyteCode(result.AnalyteCode)
+		if analyte, ok := analytes[result.AnalyteCode]; ok {
+			result.ApplyRange(analyte, p.Species)
+		}
+		result.Evaluate()
+
+		if result.Flag.IsAbnormal() {
+			p.AbnormalCount++
+		}
+		if result.Flag.IsCritical() {
+			p.HasCritical = true
+		}
+	}
+}
+
+// Result returns the result of an analyte, or nil if the panel did not measure it
+func (p *LabPanel) Result(code string) *LabResultValue {
+	code = NormalizeAnalyteCode(code)
+	for i := range p.Results {
+		if p.Results[i].AnalyteCode == code {
+			return &p.Results[i]
+		}
+	}
+	return nil
+}
+
+func labRange(low, high float64) LabSpeciesRange {
+	return LabSpeciesRange{Low: &low, High: &high}
+}
+
+func (r LabSpeciesRange) forSpecies(species string) LabSpeciesRange {
+	r.Species = species
+	return r
+}
+
+func (r LabSpeciesRange) critical(low, high *float64) LabSpeciesRange {
+	r.CriticalLow = low
+	r.CriticalHigh = high
+	return r
+}
+
+func labLimit(value float64) *float64 {
+	return &value
+}
+
+// DefaultLabAnalytes returns common hematology and chemistry analytes with
+// adult dog and cat reference ranges. Labs and analyzers differ, so the
+// ranges are meant to be adjusted to the lab in use.
+func DefaultLabAnalytes() []*LabAnalyte {
+	analyte := func(code, name, unit, category string, ranges ...LabSpeciesRange) *LabAnalyte {
+		return &LabAnalyte{Code: code, Name: name, Unit: unit, Category: category, Ranges: ranges, Active: true}
+	}
+
+	return []*LabAnalyte{
+		analyte("WBC", "White blood cells", "10^9/L", "hematology",
+			labRange(5.05, 16.76).forSpecies("dog"), labRange(2.87, 17.02).forSpecies("cat")),
+		analyte("RBC", "Red blood cells", "10^12/L", "hematology",
+			labRange(5.65, 8.87).forSpecies("dog"), labRange(6.54, 12.2).forSpecies("cat")),
+		analyte("HGB", "Hemoglobin", "g/dL", "hematology",
+			labRange(13.1, 20.5).forSpecies("dog"), labRange(9.8, 16.2).forSpecies("cat")),
+		analyte("HCT", "Hematocrit", "%", "hematology",
+			labRange(37.3, 61.7).forSpecies("dog").critical(labLimit(20), nil),
+			labRange(30.3, 52.3).forSpecies("cat").critical(labLimit(15), nil)),
+		analyte("PLT", "Platelets", "10^9/L", "hematology",
+			labRange(148, 484).forSpecies("dog").critical(labLimit(50), nil),
+			labRange(151, 600).forSpecies("cat").critical(labLimit(50), nil)),
+		analyte("GLU", "Glucose", "mg/dL", "chemistry",
+			labRange(74, 143).forSpecies("dog").critical(labLimit(40), labLimit(500)),
+			labRange(71, 159).forSpecies("cat").critical(labLimit(40), labLimit(500))),
+		analyte("BUN", "Blood urea nitrogen", "mg/dL", "chemistry",
+			labRange(7, 27).forSpecies("dog"), labRange(16, 36).forSpecies("cat")),
+		analyte("CREA", "Creatinine", "mg/dL", "chemistry",
+			labRange(0.5, 1.8).forSpecies("dog"), labRange(0.8, 2.4).forSpecies("cat")),
+		analyte("ALT", "Alanine aminotransferase", "U/L", "chemistry",
+			labRange(10, 125).forSpecies("dog"), labRange(12, 130).forSpecies("cat")),
+		analyte("ALKP", "Alkaline phosphatase", "U/L", "chemistry",
+			labRange(23, 212).forSpecies("dog"), labRange(14, 111).forSpecies("cat")),
+		analyte("TP", "Total protein", "g/dL", "chemistry",
+			labRange(5.2, 8.2).forSpecies("dog"), labRange(5.7, 8.9).forSpecies("cat")),
+		analyte("ALB", "Albumin", "g/dL", "chemistry",
+			labRange(2.3, 4.0).forSpecies("dog"), labRange(2.2, 4.0).forSpecies("cat")),
+		analyte("CA", "Calcium", "mg/dL", "chemistry",
+			labRange(7.9, 12.0).forSpecies("dog"), labRange(7.8, 11.3).forSpecies("cat")),
+		analyte("NA", "Sodium", "mmol/L", "electrolytes",
+			labRange(144, 160).forSpecies("dog"), labRange(150, 165).forSpecies("cat")),
+		analyte("K", "Potassium", "mmol/L", "electrolytes",
+			labRange(3.5, 5.8).forSpecies("dog").critical(labLimit(2.5), labLimit(7.5)),
+			labRange(3.5, 5.8).forSpecies("cat").critical(labLimit(2.5), labLimit(7.5))),
+	}
+}
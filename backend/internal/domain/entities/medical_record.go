@@ -55,6 +55,8 @@ type Medication struct {
 	Dosage             string              `bson:"dosage" json:"dosage"`
 	Unit               string              `bson:"unit" json:"unit"` // mg, ml, tablets, etc.
 	Frequency          string              `bson:"frequency" json:"frequency"`
+	Schedule           *DoseSchedule       `bson:"schedule,omitempty" json:"schedule,omitempty"`
+	ControlledSubstance bool               `bson:"controlled_substance" json:"controlled_substance"` // requires a witness sign-off
 	Route              MedicationRoute     `bson:"route" json:"route"`
 	StartDate          time.Time           `bson:"start_date" json:"start_date"`
 	EndDate            *time.Time          `bson:"end_date,omitempty" json:"end_date,omitempty"`
@@ -66,6 +68,8 @@ type Medication struct {
 	LastRefillDate     *time.Time          `bson:"last_refill_date,omitempty" json:"last_refill_date,omitempty"`
 	NextRefillDue      *time.Time          `bson:"next_refill_due,omitempty" json:"next_refill_due,omitempty"`
 	Cost               float64             `bson:"cost" json:"cost"`
+	InventoryItemID    *primitive.ObjectID `bson:"inventory_item_id,omitempty" json:"inventory_item_id,omitempty"` // stock taken on each administration
+	QuantityPerDose    float64             `bson:"quantity_per_dose,omitempty" json:"quantity_per_dose,omitempty"` // stock units per dose, defaults to 1
 	Notes              string              `bson:"notes" json:"notes"`
 	AdministrationLogs []AdministrationLog `bson:"administration_logs" json:"administration_logs"`
 	CreatedAt          time.Time           `bson:"created_at" json:"created_at"`
@@ -103,6 +107,11 @@ type AdministrationLog struct {
 	AdministeredBy primitive.ObjectID `bson:"administered_by" json:"administered_by"` // User ID
 	DosageGiven    string             `bson:"dosage_given" json:"dosage_given"`
 	Notes          string             `bson:"notes" json:"notes"`
+	DoseID         *primitive.ObjectID `bson:"dose_id,omitempty" json:"dose_id,omitempty"`
+	ScheduledFor   *time.Time          `bson:"scheduled_for,omitempty" json:"scheduled_for,omitempty"`
+	WitnessedBy    *primitive.ObjectID `bson:"witnessed_by,omitempty" json:"witnessed_by,omitempty"` // second sign-off for controlled substances
+	WitnessedAt    *time.Time          `bson:"witnessed_at,omitempty" json:"witnessed_at,omitempty"`
+	LotNumber      string              `bson:"lot_number,omitempty" json:"lot_number,omitempty"`
 }
 
 // TreatmentPlan represents a comprehensive treatment plan for a condition
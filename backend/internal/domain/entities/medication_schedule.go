@@ -0,0 +1,337 @@
+package entities
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DoseScheduleType represents how the doses of a medication are timed
+type DoseScheduleType string
+
+const (
+	DoseScheduleFixedTimes DoseScheduleType = "fixed_times" // given at set times of day
+	DoseScheduleInterval   DoseScheduleType = "interval"    // given every N hours from the start date
+	DoseSchedulePRN        DoseScheduleType = "prn"         // given as needed, never scheduled
+	DoseScheduleTapering   DoseScheduleType = "tapering"    // dosage and times change in steps
+)
+
+// DoseSchedule is the structured dosing schedule of a medication
+type DoseSchedule struct {
+	Type          DoseScheduleType `bson:"type" json:"type"`
+	TimesOfDay    []string         `bson:"times_of_day,omitempty" json:"times_of_day,omitempty"` // HH:MM, for fixed_times
+	IntervalHours int              `bson:"interval_hours,omitempty" json:"interval_hours,omitempty"`
+
+	// PRN limits
+	MinHoursBetween int `bson:"min_hours_between,omitempty" json:"min_hours_between,omitempty"`
+	MaxDailyDoses   int `bson:"max_daily_doses,omitempty" json:"max_daily_doses,omitempty"`
+
+	TaperSteps []TaperStep `bson:"taper_steps,omitempty" json:"taper_steps,omitempty"`
+}
+
+// TaperStep is one step of a tapering schedule, starting when the previous step ends
+type TaperStep struct {
+	Days       int      `bson:"days" json:"days"`
+	Dosage     string   `bson:"dosage" json:"dosage"`
+	TimesOfDay []string `bson:"times_of_day" json:"times_of_day"`
+}
+
+// PlannedDose is a dose time produced by a schedule
+type PlannedDose struct {
+	At     time.Time
+	Dosage string
+}
+
+// Validate checks that the schedule is complete for its type
+func (s *DoseSchedule) Validate() error {
+	switch s.Type {
+	case DoseScheduleFixedTimes:
+		if len(s.TimesOfDay) == 0 {
+			return fmt.Errorf("times_of_day is required for fixed_times schedules")
+		}
+		return validateTimesOfDay(s.TimesOfDay)
+	case DoseScheduleInterval:
+		if s.IntervalHours <= 0 {
+			return fmt.Errorf("interval_hours must be positive for interval schedules")
+		}
+	case DoseSchedulePRN:
+		if s.MinHoursBetween < 0 || s.MaxDailyDoses < 0 {
+			return fmt.Errorf("prn limits cannot be negative")
+		}
+	case DoseScheduleTapering:
+		if len(s.TaperSteps) == 0 {
+			return fmt.Errorf("taper_steps are required for tapering schedules")
+		}
+		for i, step := range s.TaperSteps {
+			if step.Days <= 0 {
+				return fmt.Errorf("taper step %d must last at least one day", i+1)
+			}
+			if len(step.TimesOfDay) == 0 {
+				return fmt.Errorf("taper step %d needs times_of_day", i+1)
+			}
+			if err := validateTimesOfDay(step.TimesOfDay); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown schedule type %q", s.Type)
+	}
+	return nil
+}
+
+// IsPRN reports whether doses are given only as needed
+func (s *DoseSchedule) IsPRN() bool {
+	return s != nil && s.Type == DoseSchedulePRN
+}
+
+// DosesBetween returns the planned doses of a medication in [from, to).
+// Times of day are interpreted in the location of from.
+func (m *Medication) DosesBetween(from, to time.Time) []PlannedDose {
+	s := m.Schedule
+	if s == nil || !to.After(from) {
+		return nil
+	}
+
+	if from.Before(m.StartDate) {
+		from = m.StartDate
+	}
+	if m.EndDate != nil && m.EndDate.Before(to) {
+		to = *m.EndDate
+	}
+	if !to.After(from) {
+		return nil
+	}
+
+	var doses []PlannedDose
+	switch s.Type {
+	case DoseScheduleFixedTimes:
+		for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+			doses = appendDailyDoses(doses, day, s.TimesOfDay, m.Dosage, from, to)
+		}
+	case DoseScheduleInterval:
+		interval := time.Duration(s.IntervalHours) * time.Hour
+		at := m.StartDate
+		if at.Before(from) {
+			steps := (from.Sub(at) + interval - 1) / interval
+			at = at.Add(steps * interval)
+		}
+		for ; at.Before(to); at = at.Add(interval) {
+			doses = append(doses, PlannedDose{At: at, Dosage: m.Dosage})
+		}
+	case DoseScheduleTapering:
+		stepStart := startOfDay(m.StartDate.In(from.Location()))
+		for _, step := range s.TaperSteps {
+			stepEnd := stepStart.AddDate(0, 0, step.Days)
+			for day := stepStart; day.Before(stepEnd) && day.Before(to); day = day.AddDate(0, 0, 1) {
+				doses = appendDailyDoses(doses, day, step.TimesOfDay, step.Dosage, from, to)
+			}
+			stepStart = stepEnd
+		}
+	}
+
+	sort.Slice(doses, func(i, j int) bool { return doses[i].At.Before(doses[j].At) })
+	return doses
+}
+
+func appendDailyDoses(doses []PlannedDose, day time.Time, times []string, dosage string, from, to time.Time) []PlannedDose {
+	for _, clock := range times {
+		hour, minute, err := parseClock(clock)
+		if err != nil {
+			continue
+		}
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+		if !at.Before(from) && at.Before(to) {
+			doses = append(doses, PlannedDose{At: at, Dosage: dosage})
+		}
+	}
+	return doses
+}
+
+func validateTimesOfDay(times []string) error {
+	for _, clock := range times {
+		if _, _, err := parseClock(clock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseClock(clock string) (int, int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+var everyHoursPattern = regexp.MustCompile(`(?:every|q)\s*(\d+)\s*(?:h|hr|hrs|hours?)\b`)
+
+// ParseFrequency derives a dose schedule from common free-text frequencies
+// such as "twice daily", "every 8 hours", "BID" or "as needed".
+// It returns nil when the text is not recognized.
+func ParseFrequency(frequency string) *DoseSchedule {
+	f := strings.ToLower(strings.TrimSpace(frequency))
+	if f == "" {
+		return nil
+	}
+
+	if match := everyHoursPattern.FindStringSubmatch(f); match != nil {
+		hours, _ := strconv.Atoi(match[1])
+		if hours > 0 {
+			return &DoseSchedule{Type: DoseScheduleInterval, IntervalHours: hours}
+		}
+	}
+
+	switch {
+	case strings.Contains(f, "as needed") || f == "prn":
+		return &DoseSchedule{Type: DoseSchedulePRN}
+	case strings.Contains(f, "four times") || f == "qid":
+		return &DoseSchedule{Type: DoseScheduleFixedTimes, TimesOfDay: []string{"06:00", "12:00", "18:00", "22:00"}}
+	case strings.Contains(f, "three times") || f == "tid":
+		return &DoseSchedule{Type: DoseScheduleFixedTimes, TimesOfDay: []string{"08:00", "14:00", "20:00"}}
+	case strings.Contains(f, "twice") || f == "bid":
+		return &DoseSchedule{Type: DoseScheduleFixedTimes, TimesOfDay: []string{"08:00", "20:00"}}
+	case strings.Contains(f, "once") || strings.Contains(f, "daily") || f == "sid":
+		return &DoseSchedule{Type: DoseScheduleFixedTimes, TimesOfDay: []string{"08:00"}}
+	}
+	return nil
+}
+
+// MedicationShift represents a kennel staff shift
+type MedicationShift string
+
+const (
+	ShiftMorning MedicationShift = "morning" // 06:00 - 14:00
+	ShiftEvening MedicationShift = "evening" // 14:00 - 22:00
+	ShiftNight   MedicationShift = "night"   // 22:00 - 06:00
+)
+
+// ShiftFor returns the shift a time falls into
+func ShiftFor(t time.Time) MedicationShift {
+	switch hour := t.Hour(); {
+	case hour >= 6 && hour < 14:
+		return ShiftMorning
+	case hour >= 14 && hour < 22:
+		return ShiftEvening
+	default:
+		return ShiftNight
+	}
+}
+
+// ShiftWindow returns the time range of a shift starting on the given day.
+// The night shift runs into the following morning.
+func ShiftWindow(day time.Time, shift MedicationShift) (time.Time, time.Time, error) {
+	day = startOfDay(day)
+	switch shift {
+	case ShiftMorning:
+		return day.Add(6 * time.Hour), day.Add(14 * time.Hour), nil
+	case ShiftEvening:
+		return day.Add(14 * time.Hour), day.Add(22 * time.Hour), nil
+	case ShiftNight:
+		return day.Add(22 * time.Hour), day.AddDate(0, 0, 1).Add(6 * time.Hour), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown shift %q", shift)
+}
+
+// MedicationDoseStatus represents the state of a dose slot
+type MedicationDoseStatus string
+
+const (
+	DoseStatusScheduled       MedicationDoseStatus = "scheduled"
+	DoseStatusAwaitingWitness MedicationDoseStatus = "awaiting_witness" // controlled substance given, second sign-off missing
+	DoseStatusGiven           MedicationDoseStatus = "given"
+	DoseStatusMissed          MedicationDoseStatus = "missed"
+	DoseStatusSkipped         MedicationDoseStatus = "skipped"
+)
+
+const (
+	// MedicationDoseGracePeriod is how late a dose may be given before it counts as missed
+	MedicationDoseGracePeriod = time.Hour
+	// MedicationEscalationInterval is the time between escalation levels of a missed dose
+	MedicationEscalationInterval = 2 * time.Hour
+	// MedicationMaxEscalationLevel is the last escalation level of a missed dose
+	MedicationMaxEscalationLevel = 2
+)
+
+// MedicationDose is a single dose slot in the medication administration record
+type MedicationDose struct {
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	MedicationID   primitive.ObjectID   `bson:"medication_id" json:"medication_id"`
+	AnimalID       primitive.ObjectID   `bson:"animal_id" json:"animal_id"`
+	AnimalName     string               `bson:"animal_name,omitempty" json:"animal_name,omitempty"`
+	Location       string               `bson:"location,omitempty" json:"location,omitempty"`
+	MedicationName string               `bson:"medication_name" json:"medication_name"`
+	Dosage         string               `bson:"dosage" json:"dosage"`
+	Unit           string               `bson:"unit" json:"unit"`
+	Route          MedicationRoute      `bson:"route" json:"route"`
+	Controlled     bool                 `bson:"controlled" json:"controlled"`
+	PRN            bool                 `bson:"prn" json:"prn"`
+	ScheduledFor   time.Time            `bson:"scheduled_for" json:"scheduled_for"`
+	Shift          MedicationShift      `bson:"shift" json:"shift"`
+	Status         MedicationDoseStatus `bson:"status" json:"status"`
+
+	AdministeredAt *time.Time          `bson:"administered_at,omitempty" json:"administered_at,omitempty"`
+	AdministeredBy *primitive.ObjectID `bson:"administered_by,omitempty" json:"administered_by,omitempty"`
+	DosageGiven    string              `bson:"dosage_given,omitempty" json:"dosage_given,omitempty"`
+	WitnessedBy    *primitive.ObjectID `bson:"witnessed_by,omitempty" json:"witnessed_by,omitempty"`
+	WitnessedAt    *time.Time          `bson:"witnessed_at,omitempty" json:"witnessed_at,omitempty"`
+	LotNumber      string              `bson:"lot_number,omitempty" json:"lot_number,omitempty"`
+	Notes          string              `bson:"notes,omitempty" json:"notes,omitempty"`
+
+	EscalationLevel int        `bson:"escalation_level" json:"escalation_level"`
+	LastEscalatedAt *time.Time `bson:"last_escalated_at,omitempty" json:"last_escalated_at,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// NewMedicationDose creates a scheduled dose slot for a medication
+func NewMedicationDose(medication *Medication, at time.Time, dosage string) *MedicationDose {
+	now := time.Now()
+	return &MedicationDose{
+		MedicationID:   medication.ID,
+		AnimalID:       medication.AnimalID,
+		MedicationName: medication.MedicationName,
+		Dosage:         dosage,
+		Unit:           medication.Unit,
+		Route:          medication.Route,
+		Controlled:     medication.ControlledSubstance,
+		ScheduledFor:   at,
+		Shift:          ShiftFor(at),
+		Status:         DoseStatusScheduled,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// IsOpen reports whether the dose still has to be given
+func (d *MedicationDose) IsOpen() bool {
+	return d.Status == DoseStatusScheduled || d.Status == DoseStatusMissed
+}
+
+// IsLate reports whether the dose is past its grace period
+func (d *MedicationDose) IsLate(now time.Time) bool {
+	return d.IsOpen() && now.After(d.ScheduledFor.Add(MedicationDoseGracePeriod))
+}
+
+// EscalationDue returns the escalation level a late dose should be at
+func (d *MedicationDose) EscalationDue(now time.Time) int {
+	if !d.IsLate(now) {
+		return 0
+	}
+	late := now.Sub(d.ScheduledFor.Add(MedicationDoseGracePeriod))
+	level := 1 + int(late/MedicationEscalationInterval)
+	if level > MedicationMaxEscalationLevel {
+		level = MedicationMaxEscalationLevel
+	}
+	return level
+}
@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutbreakDisease represents an infectious disease the shelter responds to
+type OutbreakDisease string
+
+const (
+	DiseaseParvovirus    OutbreakDisease = "parvovirus"
+	DiseasePanleukopenia OutbreakDisease = "panleukopenia"
+	DiseaseURI           OutbreakDisease = "uri" // upper respiratory infection
+)
+
+// OutbreakStatus represents the status of an outbreak
+type OutbreakStatus string
+
+const (
+	OutbreakStatusActive   OutbreakStatus = "active"
+	OutbreakStatusResolved OutbreakStatus = "resolved"
+)
+
+// DiseaseProfile describes how an outbreak of a disease is contained
+type DiseaseProfile struct {
+	Name           string
+	Species        []string // species that can catch the disease
+	ExposureDays   int      // how far back contacts are traced
+	QuarantineDays int      // quarantine after the last contact
+	Isolation      []string // checklist for isolating an animal
+	Testing        []string // checklist for testing an animal
+	Cleaning       []string // checklist for decontaminating a location
+}
+
+// DiseaseProfiles holds the containment profile of each disease
+var DiseaseProfiles = map[OutbreakDisease]DiseaseProfile{
+	DiseaseParvovirus: {
+		Name:           "Canine parvovirus",
+		Species:        []string{"dog"},
+		ExposureDays:   14,
+		QuarantineDays: 14,
+		Isolation: []string{
+			"Move to the isolation ward",
+			"Use dedicated gown, gloves and shoe covers",
+			"Handle last on care rounds",
+			"Use separate bowls, bedding and cleaning tools",
+		},
+		Testing: []string{
+			"Fecal parvovirus antigen test",
+			"Record temperature twice daily",
+			"Watch for vomiting, diarrhea and lethargy",
+		},
+		Cleaning: []string{
+			"Remove and discard bedding and toys",
+			"Clean surfaces of organic matter",
+			"Disinfect with accelerated hydrogen peroxide or bleach 1:32, 10 minutes contact time",
+			"Disinfect shared tools, bowls and footwear",
+		},
+	},
+	DiseasePanleukopenia: {
+		Name:           "Feline panleukopenia",
+		Species:        []string{"cat"},
+		ExposureDays:   14,
+		QuarantineDays: 14,
+		Isolation: []string{
+			"Move to the isolation ward",
+			"Use dedicated gown, gloves and shoe covers",
+			"Handle last on care rounds",
+			"Use separate litter boxes, bowls and bedding",
+		},
+		Testing: []string{
+			"Fecal parvovirus antigen test",
+			"Complete blood count (white cell count)",
+			"Watch appetite, vomiting and diarrhea",
+		},
+		Cleaning: []string{
+			"Remove and discard bedding, litter and toys",
+			"Clean surfaces of organic matter",
+			"Disinfect with accelerated hydrogen peroxide or bleach 1:32, 10 minutes contact time",
+			"Disinfect carriers and shared tools",
+		},
+	},
+	DiseaseURI: {
+		Name:           "Upper respiratory infection",
+		Species:        []string{"cat", "dog"},
+		ExposureDays:   10,
+		QuarantineDays: 10,
+		Isolation: []string{
+			"Move to housing without shared air space",
+			"Change gloves between animals",
+			"Use separate bowls and bedding",
+		},
+		Testing: []string{
+			"Check daily for sneezing and nasal or eye discharge",
+			"Record temperature and appetite daily",
+			"Respiratory PCR panel if symptoms appear",
+		},
+		Cleaning: []string{
+			"Wash bedding and bowls",
+			"Disinfect surfaces and cages",
+			"Ventilate the room",
+		},
+	},
+}
+
+// Affects checks if the disease can spread to the species
+func (p DiseaseProfile) Affects(species string) bool {
+	for _, s := range p.Species {
+		if strings.EqualFold(s, strings.TrimSpace(species)) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutbreakCase is an animal confirmed or suspected to have the disease
+type OutbreakCase struct {
+	AnimalID   primitive.ObjectID `json:"animal_id" bson:"animal_id"`
+	AnimalName string             `json:"animal_name" bson:"animal_name"`
+	Locations  []string           `json:"locations" bson:"locations"` // where it was housed during the exposure window
+	AddedAt    time.Time          `json:"added_at" bson:"added_at"`
+}
+
+// OutbreakExposure is an animal housed with a case at the same time
+type OutbreakExposure struct {
+	AnimalID     primitive.ObjectID   `json:"animal_id" bson:"animal_id"`
+	AnimalName   string               `json:"animal_name" bson:"animal_name"`
+	Species      string               `json:"species" bson:"species"`
+	Locations    []string             `json:"locations" bson:"locations"` // shared locations
+	FirstContact time.Time            `json:"first_contact" bson:"first_contact"`
+	LastContact  time.Time            `json:"last_contact" bson:"last_contact"`
+	InCare       bool                 `json:"in_care" bson:"in_care"` // false when already adopted or transferred
+	QuarantineID *primitive.ObjectID  `json:"quarantine_id,omitempty" bson:"quarantine_id,omitempty"`
+	TaskIDs      []primitive.ObjectID `json:"task_ids,omitempty" bson:"task_ids,omitempty"`
+}
+
+// Outbreak is a declared infectious disease outbreak with its cases and traced exposures
+type Outbreak struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Disease      OutbreakDisease    `json:"disease" bson:"disease"`
+	Status       OutbreakStatus     `json:"status" bson:"status"`
+	DeclaredAt   time.Time          `json:"declared_at" bson:"declared_at"`
+	ExposureFrom time.Time          `json:"exposure_from" bson:"exposure_from"` // start of the traced window
+
+	Cases     []OutbreakCase       `json:"cases" bson:"cases"`
+	Locations []string             `json:"locations" bson:"locations"` // locations housing a case during the window
+	Exposures []OutbreakExposure   `json:"exposures" bson:"exposures"`
+	TaskIDs   []primitive.ObjectID `json:"task_ids,omitempty" bson:"task_ids,omitempty"` // all generated tasks
+
+	Notes           string              `json:"notes,omitempty" bson:"notes,omitempty"`
+	ResolvedAt      *time.Time          `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	ResolvedBy      *primitive.ObjectID `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolutionNotes string              `json:"resolution_notes,omitempty" bson:"resolution_notes,omitempty"`
+
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// IsActive checks if the outbreak is still being contained
+func (o *Outbreak) IsActive() bool {
+	return o.Status == OutbreakStatusActive
+}
+
+// HasCase checks if the animal is a case of the outbreak
+func (o *Outbreak) HasCase(animalID primitive.ObjectID) bool {
+	for _, c := range o.Cases {
+		if c.AnimalID == animalID {
+			return true
+		}
+	}
+	return false
+}
+
+// Exposure returns the traced exposure of the animal
+func (o *Outbreak) Exposure(animalID primitive.ObjectID) *OutbreakExposure {
+	for i := range o.Exposures {
+		if o.Exposures[i].AnimalID == animalID {
+			return &o.Exposures[i]
+		}
+	}
+	return nil
+}
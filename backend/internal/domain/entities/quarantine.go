@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuarantineReason represents why an animal was quarantined
+type QuarantineReason string
+
+const (
+	QuarantineReasonIntake   QuarantineReason = "intake"   // new arrival of unknown health
+	QuarantineReasonTransfer QuarantineReason = "transfer" // arrived from a partner organization
+	QuarantineReasonExposure QuarantineReason = "exposure" // housed with an infectious case
+	QuarantineReasonIllness  QuarantineReason = "illness"  // confirmed or suspected infectious case
+	QuarantineReasonBite     QuarantineReason = "bite"     // rabies observation after a bite
+	QuarantineReasonOther    QuarantineReason = "other"
+)
+
+// QuarantineStatus represents the status of a quarantine period
+type QuarantineStatus string
+
+const (
+	QuarantineStatusActive   QuarantineStatus = "active"
+	QuarantineStatusReleased QuarantineStatus = "released"
+)
+
+// DefaultQuarantineDays returns the usual length of a quarantine for the reason
+func DefaultQuarantineDays(reason QuarantineReason) int {
+	if reason == QuarantineReasonBite {
+		return 10
+	}
+	return 14
+}
+
+// Quarantine is a period during which an animal is kept apart and cannot be
+// adopted, transferred out or taken to events
+type Quarantine struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	AnimalID   primitive.ObjectID  `json:"animal_id" bson:"animal_id"`
+	Reason     QuarantineReason    `json:"reason" bson:"reason"`
+	Disease    string              `json:"disease,omitempty" bson:"disease,omitempty"`
+	OutbreakID *primitive.ObjectID `json:"outbreak_id,omitempty" bson:"outbreak_id,omitempty"`
+	TransferID *primitive.ObjectID `json:"transfer_id,omitempty" bson:"transfer_id,omitempty"`
+
+	StartDate time.Time        `json:"start_date" bson:"start_date"`
+	EndDate   time.Time        `json:"end_date" bson:"end_date"` // planned release
+	Location  string           `json:"location,omitempty" bson:"location,omitempty"`
+	Status    QuarantineStatus `json:"status" bson:"status"`
+
+	PreviousStatus AnimalStatus        `json:"previous_status" bson:"previous_status"` // restored on release
+	ReleaseTaskID  *primitive.ObjectID `json:"release_task_id,omitempty" bson:"release_task_id,omitempty"`
+	ReleasedAt     *time.Time          `json:"released_at,omitempty" bson:"released_at,omitempty"`
+	ReleasedBy     *primitive.ObjectID `json:"released_by,omitempty" bson:"released_by,omitempty"`
+	ReleaseNotes   string              `json:"release_notes,omitempty" bson:"release_notes,omitempty"`
+	Notes          string              `json:"notes,omitempty" bson:"notes,omitempty"`
+
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NewQuarantine creates a new active quarantine
+func NewQuarantine(animalID primitive.ObjectID, reason QuarantineReason, start, end time.Time, createdBy primitive.ObjectID) *Quarantine {
+	now := time.Now()
+	return &Quarantine{
+		ID:        primitive.NewObjectID(),
+		AnimalID:  animalID,
+		Reason:    reason,
+		StartDate: start,
+		EndDate:   end,
+		Status:    QuarantineStatusActive,
+		CreatedBy: createdBy,
+		UpdatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// IsActive checks if the quarantine is still in force
+func (q *Quarantine) IsActive() bool {
+	return q.Status == QuarantineStatusActive
+}
+
+// IsDue checks if the quarantine has reached its planned release date
+func (q *Quarantine) IsDue(now time.Time) bool {
+	return q.IsActive() && !q.EndDate.After(now)
+}
+
+// Release ends the quarantine
+func (q *Quarantine) Release(userID primitive.ObjectID, notes string) {
+	now := time.Now()
+	q.Status = QuarantineStatusReleased
+	q.ReleasedAt = &now
+	q.ReleasedBy = &userID
+	q.ReleaseNotes = notes
+	q.UpdatedBy = userID
+	q.UpdatedAt = now
+}
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchEntityType identifies the kind of record returned by the global search
+type SearchEntityType string
+
+const (
+	SearchEntityAnimal    SearchEntityType = "animal"
+	SearchEntityDonor     SearchEntityType = "donor"
+	SearchEntityVolunteer SearchEntityType = "volunteer"
+	SearchEntityContact   SearchEntityType = "contact"
+	SearchEntityPartner   SearchEntityType = "partner"
+	SearchEntityDocument  SearchEntityType = "document"
+	SearchEntityEvent     SearchEntityType = "event"
+)
+
+// SearchEntityTypes lists every searchable entity type
+var SearchEntityTypes = []SearchEntityType{
+	SearchEntityAnimal,
+	SearchEntityDonor,
+	SearchEntityVolunteer,
+	SearchEntityContact,
+	SearchEntityPartner,
+	SearchEntityDocument,
+	SearchEntityEvent,
+}
+
+// SearchHit is a single record matched by the global search
+type SearchHit struct {
+	Type      SearchEntityType   `json:"type"`
+	ID        primitive.ObjectID `json:"id"`
+	Title     string             `json:"title"`
+	Subtitle  string             `json:"subtitle,omitempty"`
+	Status    string             `json:"status,omitempty"`
+	Tags      []string           `json:"tags,omitempty"`
+	Names     []string           `json:"-"` // Name fields used for typo-tolerant matching
+	Emails    []string           `json:"emails,omitempty"`
+	Phones    []string           `json:"phones,omitempty"`
+	TextScore float64            `json:"-"` // MongoDB text score, 0 when matched by prefix only
+	Score     float64            `json:"score"`
+	UpdatedAt *time.Time         `json:"updated_at,omitempty"`
+}
+
+// SearchFacetCount is the number of hits for a facet value
+type SearchFacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchFacets holds the facet counts of a search
+type SearchFacets struct {
+	Types    []SearchFacetCount `json:"types"`
+	Statuses []SearchFacetCount `json:"statuses"`
+	Tags     []SearchFacetCount `json:"tags"`
+}
+
+// SearchResults is the response of the global search
+type SearchResults struct {
+	Query  string       `json:"query"`
+	Hits   []*SearchHit `json:"hits"`
+	Total  int          `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+	Facets SearchFacets `json:"facets"`
+}
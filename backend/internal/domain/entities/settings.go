@@ -34,11 +34,18 @@ type FoundationSettings struct {
 
 	// Policies
 	AdoptionPolicy     string `json:"adoption_policy,omitempty" bson:"adoption_policy,omitempty"`
+	ReturnPolicy       *AdoptionReturnPolicy `json:"return_policy,omitempty" bson:"return_policy,omitempty"` // Refunds and trial ends under the adoption policy; defaults apply when empty
 	PrivacyPolicy      string `json:"privacy_policy,omitempty" bson:"privacy_policy,omitempty"`
 	TermsOfService     string `json:"terms_of_service,omitempty" bson:"terms_of_service,omitempty"`
 	VolunteerPolicy    string `json:"volunteer_policy,omitempty" bson:"volunteer_policy,omitempty"`
 	DonationPolicy     string `json:"donation_policy,omitempty" bson:"donation_policy,omitempty"`
 
+	// Adoption application review pipeline; the default pipeline is used when empty
+	ApplicationReview *ApplicationReviewConfig `json:"application_review,omitempty" bson:"application_review,omitempty"`
+
+	// Post-adoption follow-up surveys; the built-in messages are used when empty
+	FollowUpSurvey *FollowUpSurveySettings `json:"follow_up_survey,omitempty" bson:"follow_up_survey,omitempty"`
+
 	// Fees & Pricing
 	DefaultAdoptionFees map[string]float64 `json:"default_adoption_fees,omitempty" bson:"default_adoption_fees,omitempty"` // species -> fee
 
@@ -0,0 +1,158 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SterilizationStatus represents the compliance state of a spay/neuter requirement
+type SterilizationStatus string
+
+const (
+	SterilizationStatusPending   SterilizationStatus = "pending"
+	SterilizationStatusOverdue   SterilizationStatus = "overdue"
+	SterilizationStatusCompleted SterilizationStatus = "completed"
+	SterilizationStatusWaived    SterilizationStatus = "waived"
+)
+
+// SterilizationReminderKind identifies a reminder sent to the adopter
+type SterilizationReminderKind string
+
+const (
+	SterilizationReminderUpcoming SterilizationReminderKind = "upcoming" // two weeks before the deadline
+	SterilizationReminderFinal    SterilizationReminderKind = "final"    // three days before the deadline
+	SterilizationReminderOverdue  SterilizationReminderKind = "overdue"  // once the deadline has passed
+)
+
+const (
+	// DefaultSterilizationDueDays is the time an adopter has to sterilize an intact animal
+	DefaultSterilizationDueDays = 60
+	// SterilizationMinAgeMonths is the age before which the procedure is not expected
+	SterilizationMinAgeMonths = 6
+)
+
+// SterilizationReminder records a reminder sent to the adopter
+type SterilizationReminder struct {
+	Kind            SterilizationReminderKind `json:"kind" bson:"kind"`
+	SentAt          time.Time                 `json:"sent_at" bson:"sent_at"`
+	Channel         TemplateType              `json:"channel" bson:"channel"`
+	CommunicationID *primitive.ObjectID       `json:"communication_id,omitempty" bson:"communication_id,omitempty"`
+}
+
+// SterilizationRequirement tracks the adopter's obligation to spay/neuter an animal adopted intact
+type SterilizationRequirement struct {
+	Status  SterilizationStatus `json:"status" bson:"status"`
+	DueDate time.Time           `json:"due_date" bson:"due_date"`
+
+	// Evidence: the spay/neuter visit or an uploaded certificate from the adopter's vet
+	VisitID       *primitive.ObjectID `json:"visit_id,omitempty" bson:"visit_id,omitempty"`
+	CertificateID *primitive.ObjectID `json:"certificate_id,omitempty" bson:"certificate_id,omitempty"` // Document ID
+	ProcedureDate *time.Time          `json:"procedure_date,omitempty" bson:"procedure_date,omitempty"`
+	VerifiedBy    *primitive.ObjectID `json:"verified_by,omitempty" bson:"verified_by,omitempty"`
+	VerifiedAt    *time.Time          `json:"verified_at,omitempty" bson:"verified_at,omitempty"`
+
+	WaiverReason string `json:"waiver_reason,omitempty" bson:"waiver_reason,omitempty"`
+	Notes        string `json:"notes,omitempty" bson:"notes,omitempty"`
+
+	Reminders        []SterilizationReminder `json:"reminders,omitempty" bson:"reminders,omitempty"`
+	EscalationTaskID *primitive.ObjectID     `json:"escalation_task_id,omitempty" bson:"escalation_task_id,omitempty"`
+}
+
+// NewSterilizationRequirement creates a pending requirement with the given deadline
+func NewSterilizationRequirement(dueDate time.Time) *SterilizationRequirement {
+	return &SterilizationRequirement{
+		Status:  SterilizationStatusPending,
+		DueDate: dueDate,
+	}
+}
+
+// SterilizationDueDate returns the deadline for an animal adopted on the given date. Animals
+// too young for the procedure get the given number of days from the age it is expected at.
+func SterilizationDueDate(adoptionDate time.Time, dateOfBirth *time.Time, days int) time.Time {
+	if days <= 0 {
+		days = DefaultSterilizationDueDays
+	}
+	from := adoptionDate
+	if dateOfBirth != nil {
+		if eligible := dateOfBirth.AddDate(0, SterilizationMinAgeMonths, 0); eligible.After(from) {
+			from = eligible
+		}
+	}
+	return from.AddDate(0, 0, days)
+}
+
+// IsOpen checks if the procedure is still expected
+func (r *SterilizationRequirement) IsOpen() bool {
+	return r.Status == SterilizationStatusPending || r.Status == SterilizationStatusOverdue
+}
+
+// IsPastDue checks if the deadline has passed without the procedure
+func (r *SterilizationRequirement) IsPastDue(now time.Time) bool {
+	return r.IsOpen() && now.After(r.DueDate)
+}
+
+// IsOnTime checks if the procedure was done by the deadline
+func (r *SterilizationRequirement) IsOnTime() bool {
+	return r.Status == SterilizationStatusCompleted && r.ProcedureDate != nil && !r.ProcedureDate.After(r.DueDate)
+}
+
+// HasReminder checks if a reminder of the kind was already sent
+func (r *SterilizationRequirement) HasReminder(kind SterilizationReminderKind) bool {
+	for _, reminder := range r.Reminders {
+		if reminder.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// DueReminder returns the reminder to send now, or an empty kind if none is due.
+// Only the most recent applicable reminder is returned, so a short deadline does
+// not send the earlier ones late.
+func (r *SterilizationRequirement) DueReminder(now time.Time) SterilizationReminderKind {
+	if !r.IsOpen() {
+		return ""
+	}
+
+	var kind SterilizationReminderKind
+	switch {
+	case now.After(r.DueDate):
+		kind = SterilizationReminderOverdue
+	case !now.Before(r.DueDate.AddDate(0, 0, -3)):
+		kind = SterilizationReminderFinal
+	case !now.Before(r.DueDate.AddDate(0, 0, -14)):
+		kind = SterilizationReminderUpcoming
+	default:
+		return ""
+	}
+
+	if r.HasReminder(kind) {
+		return ""
+	}
+	return kind
+}
+
+// Complete records the procedure and closes the requirement
+func (r *SterilizationRequirement) Complete(procedureDate time.Time, visitID, certificateID *primitive.ObjectID, userID primitive.ObjectID) {
+	now := time.Now()
+	r.Status = SterilizationStatusCompleted
+	r.ProcedureDate = &procedureDate
+	if visitID != nil {
+		r.VisitID = visitID
+	}
+	if certificateID != nil {
+		r.CertificateID = certificateID
+	}
+	r.VerifiedBy = &userID
+	r.VerifiedAt = &now
+}
+
+// Waive closes the requirement without the procedure, e.g. for a medical exemption
+func (r *SterilizationRequirement) Waive(reason string, userID primitive.ObjectID) {
+	now := time.Now()
+	r.Status = SterilizationStatusWaived
+	r.WaiverReason = reason
+	r.VerifiedBy = &userID
+	r.VerifiedAt = &now
+}
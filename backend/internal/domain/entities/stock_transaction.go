@@ -52,6 +52,10 @@ type StockTransaction struct {
 
 	// Expiration (for items with expiration)
 	ExpirationDate *time.Time `json:"expiration_date,omitempty" bson:"expiration_date,omitempty"`
+	LotNumber      string     `json:"lot_number,omitempty" bson:"lot_number,omitempty"`
+
+	// Animal whose care consumed the stock, also set when the related entity is a visit
+	AnimalID *primitive.ObjectID `json:"animal_id,omitempty" bson:"animal_id,omitempty"`
 
 	// Notes
 	Notes string `json:"notes,omitempty" bson:"notes,omitempty"`
@@ -111,3 +115,16 @@ func (st *StockTransaction) SetRelatedEntity(entityType string, entityID primiti
 	st.RelatedEntity = entityType
 	st.RelatedEntityID = &entityID
 }
+
+// StockConsumption describes stock used for an animal's care
+type StockConsumption struct {
+	ItemID          primitive.ObjectID
+	Quantity        float64
+	LotNumber       string // use this lot instead of first-expired-first-out selection
+	AnimalID        *primitive.ObjectID
+	RelatedEntity   string // "animal", "veterinary_visit", "vaccination", "medication"
+	RelatedEntityID *primitive.ObjectID
+	Reason          string
+	Reference       string
+	Notes           string
+}
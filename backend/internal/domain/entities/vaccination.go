@@ -23,6 +23,13 @@ const (
 	VaccineFeLV           VaccinationType = "felv"           // Feline Leukemia
 	VaccineFIP            VaccinationType = "fip"            // Feline Infectious Peritonitis
 
+	// Rabbit Vaccines
+	VaccineRHDV           VaccinationType = "rhdv"           // Rabbit Haemorrhagic Disease (RHDV1/RHDV2)
+	VaccineMyxomatosis    VaccinationType = "myxomatosis"
+
+	// Ferret Vaccines
+	VaccineFerretDistemper VaccinationType = "ferret_distemper"
+
 	// Other
 	VaccineOther          VaccinationType = "other"
 )
@@ -37,6 +44,9 @@ const (
 	VaccinationStatusExpired  VaccinationStatus = "expired"
 )
 
+// VaccinationDueWindowDays is how many days before the due date a vaccination counts as due
+const VaccinationDueWindowDays = 30
+
 // Vaccination represents a vaccination record for an animal
 type Vaccination struct {
 	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
@@ -49,6 +59,9 @@ type Vaccination struct {
 	VaccineName     string          `json:"vaccine_name" bson:"vaccine_name"`           // Brand or specific name
 	Manufacturer    string          `json:"manufacturer,omitempty" bson:"manufacturer,omitempty"`
 	LotNumber       string          `json:"lot_number,omitempty" bson:"lot_number,omitempty"`
+	LotExpirationDate *time.Time    `json:"lot_expiration_date,omitempty" bson:"lot_expiration_date,omitempty"`
+	LotUnitCost     float64         `json:"lot_unit_cost,omitempty" bson:"lot_unit_cost,omitempty"` // cost of the dose in its lot, used when the dose goes back into stock
+	InventoryItemID *primitive.ObjectID `json:"inventory_item_id,omitempty" bson:"inventory_item_id,omitempty"` // vaccine stock the dose was taken from
 	DoseNumber      int             `json:"dose_number" bson:"dose_number"`             // 1st, 2nd, 3rd dose, etc.
 	TotalDoses      int             `json:"total_doses,omitempty" bson:"total_doses,omitempty"` // Expected total doses
 
@@ -102,7 +115,7 @@ func (v *Vaccination) GetStatus() VaccinationStatus {
 		}
 
 		// Due if within 30 days
-		thirtyDaysFromNow := now.AddDate(0, 0, 30)
+		thirtyDaysFromNow := now.AddDate(0, 0, VaccinationDueWindowDays)
 		if dueDate.Before(thirtyDaysFromNow) {
 			return VaccinationStatusDue
 		}
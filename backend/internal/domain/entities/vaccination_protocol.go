@@ -0,0 +1,308 @@
+package entities
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultDoseIntervalWeeks is the spacing between series doses when a protocol does not set one
+const defaultDoseIntervalWeeks = 3
+
+// VaccinationProtocol describes the vaccination series and boosters a species needs
+type VaccinationProtocol struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+
+	Name        string          `json:"name" bson:"name"`
+	Species     string          `json:"species" bson:"species"` // matches Animal.Species, e.g. "dog"
+	VaccineType VaccinationType `json:"vaccine_type" bson:"vaccine_type"`
+	VaccineName string          `json:"vaccine_name,omitempty" bson:"vaccine_name,omitempty"`
+	Description string          `json:"description,omitempty" bson:"description,omitempty"`
+
+	// Juvenile series: doses are due at these ages (in weeks)
+	SeriesAgesWeeks []int `json:"series_ages_weeks,omitempty" bson:"series_ages_weeks,omitempty"`
+
+	// Animals past the juvenile series, or of unknown age, get AdultDoses doses starting at intake
+	AdultDoses int `json:"adult_doses" bson:"adult_doses"`
+
+	// Minimum spacing between doses of a series
+	DoseIntervalWeeks int `json:"dose_interval_weeks,omitempty" bson:"dose_interval_weeks,omitempty"`
+
+	// No dose is scheduled before this age, e.g. rabies at 12 weeks
+	MinAgeWeeks int `json:"min_age_weeks,omitempty" bson:"min_age_weeks,omitempty"`
+
+	// Booster after the series is complete, 0 for none. Rabies depends on local law (12 or 36 months).
+	BoosterIntervalMonths int `json:"booster_interval_months,omitempty" bson:"booster_interval_months,omitempty"`
+
+	Active bool `json:"active" bson:"active"`
+
+	// Metadata
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	UpdatedBy primitive.ObjectID `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NormalizeSpecies returns the species key protocols are matched on
+func NormalizeSpecies(species string) string {
+	return strings.ToLower(strings.TrimSpace(species))
+}
+
+// doseInterval returns the minimum spacing between series doses
+func (p *VaccinationProtocol) doseInterval() time.Duration {
+	weeks := p.DoseIntervalWeeks
+	if weeks <= 0 {
+		weeks = defaultDoseIntervalWeeks
+	}
+	return weeksDuration(weeks)
+}
+
+// Plan returns the due dates of the initial series for an animal entering the protocol at start.
+// Juveniles follow the age-based series; doses missed before start are replaced by one dose at start.
+// Adults and animals of unknown age get the adult series starting at start.
+func (p *VaccinationProtocol) Plan(dateOfBirth *time.Time, start time.Time) []time.Time {
+	earliest := start
+	if dateOfBirth != nil && p.MinAgeWeeks > 0 {
+		if minAge := dateOfBirth.Add(weeksDuration(p.MinAgeWeeks)); minAge.After(earliest) {
+			earliest = minAge
+		}
+	}
+
+	ages := append([]int(nil), p.SeriesAgesWeeks...)
+	sort.Ints(ages)
+
+	var dates []time.Time
+	if dateOfBirth != nil && len(ages) > 0 && start.Before(dateOfBirth.Add(weeksDuration(ages[len(ages)-1]))) {
+		missed := false
+		for _, age := range ages {
+			due := dateOfBirth.Add(weeksDuration(age))
+			if due.Before(earliest) {
+				missed = true
+				continue
+			}
+			dates = append(dates, due)
+		}
+		if missed {
+			dates = append([]time.Time{earliest}, dates...)
+		}
+	} else {
+		doses := p.AdultDoses
+		if doses <= 0 {
+			doses = 1
+		}
+		for i := 0; i < doses; i++ {
+			dates = append(dates, earliest)
+		}
+	}
+
+	// Keep the minimum spacing between consecutive doses
+	for i := 1; i < len(dates); i++ {
+		if next := dates[i-1].Add(p.doseInterval()); dates[i].Before(next) {
+			dates[i] = next
+		}
+	}
+
+	return dates
+}
+
+// NextDoseDue returns when the next series dose is due after a dose given at administered
+func (p *VaccinationProtocol) NextDoseDue(administered, planned time.Time) time.Time {
+	if next := administered.Add(p.doseInterval()); planned.Before(next) {
+		return next
+	}
+	return planned
+}
+
+// BoosterDue returns when the booster is due after the last dose, or nil when the protocol has none
+func (p *VaccinationProtocol) BoosterDue(lastDose time.Time) *time.Time {
+	if p.BoosterIntervalMonths <= 0 {
+		return nil
+	}
+	due := lastDose.AddDate(0, p.BoosterIntervalMonths, 0)
+	return &due
+}
+
+func weeksDuration(weeks int) time.Duration {
+	return time.Duration(weeks) * 7 * 24 * time.Hour
+}
+
+// DefaultVaccinationProtocols returns the protocols installed when none are configured
+func DefaultVaccinationProtocols() []*VaccinationProtocol {
+	return []*VaccinationProtocol{
+		{
+			Name:                  "DHPP",
+			Species:               "dog",
+			VaccineType:           VaccineDHPP,
+			Description:           "Distemper, hepatitis, parvovirus and parainfluenza at 8, 12 and 16 weeks, then yearly",
+			SeriesAgesWeeks:       []int{8, 12, 16},
+			AdultDoses:            2,
+			DoseIntervalWeeks:     3,
+			MinAgeWeeks:           6,
+			BoosterIntervalMonths: 12,
+			Active:                true,
+		},
+		{
+			Name:                  "Rabies (dog)",
+			Species:               "dog",
+			VaccineType:           VaccineRabies,
+			Description:           "Single dose from 12 weeks; booster interval follows local law",
+			AdultDoses:            1,
+			MinAgeWeeks:           12,
+			BoosterIntervalMonths: 12,
+			Active:                true,
+		},
+		{
+			Name:                  "FVRCP",
+			Species:               "cat",
+			VaccineType:           VaccineFVRCP,
+			Description:           "Rhinotracheitis, calicivirus and panleukopenia at 8, 12 and 16 weeks, then yearly",
+			SeriesAgesWeeks:       []int{8, 12, 16},
+			AdultDoses:            2,
+			DoseIntervalWeeks:     3,
+			MinAgeWeeks:           6,
+			BoosterIntervalMonths: 12,
+			Active:                true,
+		},
+		{
+			Name:                  "Rabies (cat)",
+			Species:               "cat",
+			VaccineType:           VaccineRabies,
+			Description:           "Single dose from 12 weeks; booster interval follows local law",
+			AdultDoses:            1,
+			MinAgeWeeks:           12,
+			BoosterIntervalMonths: 12,
+			Active:                true,
+		},
+		{
+			Name:                  "RHDV1/RHDV2",
+			Species:               "rabbit",
+			VaccineType:           VaccineRHDV,
+			Description:           "Rabbit haemorrhagic disease from 10 weeks, then yearly",
+			AdultDoses:            1,
+			MinAgeWeeks:           10,
+			BoosterIntervalMonths: 12,
+			Active:                true,
+		},
+	}
+}
+
+// VaccinationScheduleStatus represents the state of a scheduled dose
+type VaccinationScheduleStatus string
+
+const (
+	VaccinationSchedulePending   VaccinationScheduleStatus = "pending"
+	VaccinationScheduleCompleted VaccinationScheduleStatus = "completed"
+	VaccinationScheduleSkipped   VaccinationScheduleStatus = "skipped"
+)
+
+// VaccinationScheduleItem is an expected dose generated from a protocol
+type VaccinationScheduleItem struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AnimalID   primitive.ObjectID `json:"animal_id" bson:"animal_id"`
+	ProtocolID primitive.ObjectID `json:"protocol_id" bson:"protocol_id"`
+
+	VaccineType VaccinationType `json:"vaccine_type" bson:"vaccine_type"`
+	VaccineName string          `json:"vaccine_name" bson:"vaccine_name"`
+	DoseNumber  int             `json:"dose_number" bson:"dose_number"`
+	TotalDoses  int             `json:"total_doses" bson:"total_doses"`
+	Booster     bool            `json:"booster" bson:"booster"`
+	DueDate     time.Time       `json:"due_date" bson:"due_date"`
+
+	Status        VaccinationScheduleStatus `json:"status" bson:"status"`
+	VaccinationID *primitive.ObjectID       `json:"vaccination_id,omitempty" bson:"vaccination_id,omitempty"`
+	CompletedAt   *time.Time                `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+
+	// The vaccination that moved this dose or added this booster, and the due date it
+	// had before, so deleting that vaccination can undo the change
+	ScheduledBy     *primitive.ObjectID `json:"-" bson:"scheduled_by,omitempty"`
+	PreviousDueDate *time.Time          `json:"-" bson:"previous_due_date,omitempty"`
+
+	// Metadata
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// IsPending checks if the dose still has to be given
+func (i *VaccinationScheduleItem) IsPending() bool {
+	return i.Status == VaccinationSchedulePending
+}
+
+// GetStatus returns the compliance status of the dose at the given time
+func (i *VaccinationScheduleItem) GetStatus(now time.Time) VaccinationStatus {
+	if !i.IsPending() {
+		return VaccinationStatusCurrent
+	}
+	// A dose is overdue only once its due day has passed
+	if i.DueDate.Before(now.AddDate(0, 0, -1)) {
+		return VaccinationStatusOverdue
+	}
+	if i.DueDate.Before(now.AddDate(0, 0, VaccinationDueWindowDays)) {
+		return VaccinationStatusDue
+	}
+	return VaccinationStatusCurrent
+}
+
+// VaccinationCompliance summarizes the vaccination schedule of an animal
+type VaccinationCompliance struct {
+	AnimalID     primitive.ObjectID         `json:"animal_id"`
+	Status       VaccinationStatus          `json:"status"` // current, due or overdue
+	Vaccinated   bool                       `json:"vaccinated"`
+	NextDueDate  *time.Time                 `json:"next_due_date,omitempty"`
+	DueCount     int                        `json:"due_count"`
+	OverdueCount int                        `json:"overdue_count"`
+	Items        []*VaccinationScheduleItem `json:"items"`
+}
+
+// NewVaccinationCompliance computes the compliance of an animal from its schedule.
+// An animal counts as vaccinated when nothing is overdue and every scheduled vaccine has at least one dose.
+func NewVaccinationCompliance(animalID primitive.ObjectID, items []*VaccinationScheduleItem, now time.Time) *VaccinationCompliance {
+	compliance := &VaccinationCompliance{
+		AnimalID: animalID,
+		Status:   VaccinationStatusCurrent,
+		Items:    items,
+	}
+	if compliance.Items == nil {
+		compliance.Items = []*VaccinationScheduleItem{}
+	}
+
+	given := map[VaccinationType]bool{}
+	for _, item := range items {
+		if _, ok := given[item.VaccineType]; !ok {
+			given[item.VaccineType] = false
+		}
+		if item.Status == VaccinationScheduleCompleted {
+			given[item.VaccineType] = true
+		}
+		if !item.IsPending() {
+			continue
+		}
+
+		if compliance.NextDueDate == nil || item.DueDate.Before(*compliance.NextDueDate) {
+			due := item.DueDate
+			compliance.NextDueDate = &due
+		}
+
+		switch item.GetStatus(now) {
+		case VaccinationStatusOverdue:
+			compliance.OverdueCount++
+			compliance.Status = VaccinationStatusOverdue
+		case VaccinationStatusDue:
+			compliance.DueCount++
+			if compliance.Status == VaccinationStatusCurrent {
+				compliance.Status = VaccinationStatusDue
+			}
+		}
+	}
+
+	compliance.Vaccinated = len(given) > 0 && compliance.Status != VaccinationStatusOverdue
+	for _, ok := range given {
+		if !ok {
+			compliance.Vaccinated = false
+		}
+	}
+
+	return compliance
+}
@@ -50,6 +50,12 @@ type Prescription struct {
 	Instructions   string    `json:"instructions,omitempty" bson:"instructions,omitempty"`
 	StartDate      time.Time `json:"start_date" bson:"start_date"`
 	EndDate        *time.Time `json:"end_date,omitempty" bson:"end_date,omitempty"`
+
+	// Dispensing from shelter inventory
+	InventoryItemID *primitive.ObjectID `json:"inventory_item_id,omitempty" bson:"inventory_item_id,omitempty"`
+	Quantity        float64             `json:"quantity,omitempty" bson:"quantity,omitempty"` // stock units dispensed, defaults to 1
+	LotNumber       string              `json:"lot_number,omitempty" bson:"lot_number,omitempty"`
+	DispensedAt     *time.Time          `json:"dispensed_at,omitempty" bson:"dispensed_at,omitempty"`
 }
 
 // TestResult represents a medical test result
@@ -78,6 +84,15 @@ type VeterinaryVisit struct {
 	ClinicAddress     string `json:"clinic_address,omitempty" bson:"clinic_address,omitempty"`
 	ClinicPhone       string `json:"clinic_phone,omitempty" bson:"clinic_phone,omitempty"`
 
+	// Appointment at a partner clinic
+	PartnerID         *primitive.ObjectID  `json:"partner_id,omitempty" bson:"partner_id,omitempty"`
+	DurationMinutes   int                  `json:"duration_minutes,omitempty" bson:"duration_minutes,omitempty"`
+	AppointmentEnd    *time.Time           `json:"appointment_end,omitempty" bson:"appointment_end,omitempty"`
+	AssignedStaff     []primitive.ObjectID `json:"assigned_staff,omitempty" bson:"assigned_staff,omitempty"`
+	TransportTaskID   *primitive.ObjectID  `json:"transport_task_id,omitempty" bson:"transport_task_id,omitempty"`
+	CancellationReason string              `json:"cancellation_reason,omitempty" bson:"cancellation_reason,omitempty"`
+	Sequence          int                  `json:"sequence,omitempty" bson:"sequence,omitempty"` // iCalendar revision, bumped on reschedule or cancellation
+
 	// Examination Details
 	ChiefComplaint    string      `json:"chief_complaint,omitempty" bson:"chief_complaint,omitempty"` // reason for visit
 	VitalSigns        VitalSigns  `json:"vital_signs" bson:"vital_signs"`
@@ -0,0 +1,175 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VitalSource identifies where a vital-sign reading was captured
+type VitalSource string
+
+const (
+	VitalSourceVisit         VitalSource = "visit"           // Veterinary visit
+	VitalSourceDailyNote     VitalSource = "daily_note"      // Daily care note
+	VitalSourceFosterCheckIn VitalSource = "foster_check_in" // Foster check-in
+	VitalSourceManual        VitalSource = "manual"          // Entered directly
+)
+
+// VitalMetric names a tracked vital sign
+type VitalMetric string
+
+const (
+	VitalMetricWeight          VitalMetric = "weight"           // kg
+	VitalMetricTemperature     VitalMetric = "temperature"      // Celsius
+	VitalMetricHeartRate       VitalMetric = "heart_rate"       // beats per minute
+	VitalMetricRespiratoryRate VitalMetric = "respiratory_rate" // breaths per minute
+)
+
+// VitalAlertRule names the rule that raised an alert
+type VitalAlertRule string
+
+const (
+	VitalAlertWeightLoss VitalAlertRule = "weight_loss"  // weight dropped too fast
+	VitalAlertOutOfRange VitalAlertRule = "out_of_range" // outside the species reference range
+)
+
+// IsValidVitalMetric checks if the metric is tracked
+func IsValidVitalMetric(metric VitalMetric) bool {
+	switch metric {
+	case VitalMetricWeight, VitalMetricTemperature, VitalMetricHeartRate, VitalMetricRespiratoryRate:
+		return true
+	}
+	return false
+}
+
+// VitalSignReading is one point in an animal's weight and vitals time series.
+// Unmeasured values are left at zero.
+type VitalSignReading struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	AnimalID primitive.ObjectID `json:"animal_id" bson:"animal_id"`
+	Species  string             `json:"species,omitempty" bson:"species,omitempty"`
+
+	RecordedAt time.Time           `json:"recorded_at" bson:"recorded_at"`
+	Source     VitalSource         `json:"source" bson:"source"`
+	SourceID   *primitive.ObjectID `json:"source_id,omitempty" bson:"source_id,omitempty"` // Visit or assignment ID
+
+	Weight          float64 `json:"weight,omitempty" bson:"weight,omitempty"`
+	Temperature     float64 `json:"temperature,omitempty" bson:"temperature,omitempty"`
+	HeartRate       int     `json:"heart_rate,omitempty" bson:"heart_rate,omitempty"`
+	RespiratoryRate int     `json:"respiratory_rate,omitempty" bson:"respiratory_rate,omitempty"`
+	Notes           string  `json:"notes,omitempty" bson:"notes,omitempty"`
+
+	// Alerts raised by this reading
+	Alerts []VitalAlert `json:"alerts,omitempty" bson:"alerts,omitempty"`
+
+	// Metadata
+	RecordedBy primitive.ObjectID `json:"recorded_by" bson:"recorded_by"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// VitalAlert describes a rule a reading tripped
+type VitalAlert struct {
+	Rule    VitalAlertRule `json:"rule" bson:"rule"`
+	Metric  VitalMetric    `json:"metric" bson:"metric"`
+	Value   float64        `json:"value" bson:"value"`
+	Message string         `json:"message" bson:"message"`
+}
+
+// NewVitalSignReading creates a reading from captured vital signs
+func NewVitalSignReading(animal *Animal, vitals VitalSigns, source VitalSource, recordedAt time.Time, recordedBy primitive.ObjectID) *VitalSignReading {
+	return &VitalSignReading{
+		AnimalID:        animal.ID,
+		Species:         NormalizeSpecies(animal.Species),
+		RecordedAt:      recordedAt,
+		Source:          source,
+		Weight:          vitals.Weight,
+		Temperature:     vitals.Temperature,
+		HeartRate:       vitals.HeartRate,
+		RespiratoryRate: vitals.RespiratoryRate,
+		RecordedBy:      recordedBy,
+	}
+}
+
+// HasValues checks if any tracked vital sign was measured
+func (r *VitalSignReading) HasValues() bool {
+	return r.Weight > 0 || r.Temperature > 0 || r.HeartRate > 0 || r.RespiratoryRate > 0
+}
+
+// Value returns the value of a metric and whether it was measured
+func (r *VitalSignReading) Value(metric VitalMetric) (float64, bool) {
+	var value float64
+	switch metric {
+	case VitalMetricWeight:
+		value = r.Weight
+	case VitalMetricTemperature:
+		value = r.Temperature
+	case VitalMetricHeartRate:
+		value = float64(r.HeartRate)
+	case VitalMetricRespiratoryRate:
+		value = float64(r.RespiratoryRate)
+	}
+	return value, value > 0
+}
+
+// VitalRange is an inclusive normal range
+type VitalRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Contains checks if a value lies within the range
+func (r VitalRange) Contains(value float64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+// VitalReferenceRange holds the normal vital ranges of an adult animal of a species.
+// Weight varies too much by breed and age to have a reference range.
+type VitalReferenceRange struct {
+	Species         string     `json:"species"`
+	Temperature     VitalRange `json:"temperature"`
+	HeartRate       VitalRange `json:"heart_rate"`
+	RespiratoryRate VitalRange `json:"respiratory_rate"`
+}
+
+// Range returns the range of a metric, if the species has one
+func (r *VitalReferenceRange) Range(metric VitalMetric) (VitalRange, bool) {
+	switch metric {
+	case VitalMetricTemperature:
+		return r.Temperature, true
+	case VitalMetricHeartRate:
+		return r.HeartRate, true
+	case VitalMetricRespiratoryRate:
+		return r.RespiratoryRate, true
+	}
+	return VitalRange{}, false
+}
+
+// vitalReferenceRanges are textbook resting values for adult animals
+var vitalReferenceRanges = []VitalReferenceRange{
+	{Species: "dog", Temperature: VitalRange{37.5, 39.2}, HeartRate: VitalRange{60, 140}, RespiratoryRate: VitalRange{10, 35}},
+	{Species: "cat", Temperature: VitalRange{37.8, 39.2}, HeartRate: VitalRange{140, 220}, RespiratoryRate: VitalRange{20, 30}},
+	{Species: "rabbit", Temperature: VitalRange{38.5, 40}, HeartRate: VitalRange{130, 325}, RespiratoryRate: VitalRange{30, 60}},
+	{Species: "guinea pig", Temperature: VitalRange{37.2, 39.5}, HeartRate: VitalRange{230, 380}, RespiratoryRate: VitalRange{42, 104}},
+	{Species: "ferret", Temperature: VitalRange{37.8, 40}, HeartRate: VitalRange{180, 250}, RespiratoryRate: VitalRange{33, 36}},
+}
+
+// GetVitalReferenceRanges returns the reference ranges of all known species
+func GetVitalReferenceRanges() []VitalReferenceRange {
+	ranges := make([]VitalReferenceRange, len(vitalReferenceRanges))
+	copy(ranges, vitalReferenceRanges)
+	return ranges
+}
+
+// GetVitalReferenceRange returns the reference range of a species, or nil if unknown
+func GetVitalReferenceRange(species string) *VitalReferenceRange {
+	species = NormalizeSpecies(species)
+	for i := range vitalReferenceRanges {
+		if vitalReferenceRanges[i].Species == species {
+			rng := vitalReferenceRanges[i]
+			return &rng
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdopterRepository defines the interface for adopter data access
+type AdopterRepository interface {
+	// Create creates a new adopter
+	Create(ctx context.Context, adopter *entities.Adopter) error
+
+	// FindByID finds an adopter by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Adopter, error)
+
+	// FindMatches returns the adopters with the normalized email or phone; empty keys are ignored
+	FindMatches(ctx context.Context, emailKey, phoneKey string) ([]*entities.Adopter, error)
+
+	// Update updates an existing adopter
+	Update(ctx context.Context, adopter *entities.Adopter) error
+
+	// List returns the adopters matching the filter, sorted by name
+	List(ctx context.Context, filter *AdopterFilter) ([]*entities.Adopter, int64, error)
+
+	// EnsureIndexes creates necessary indexes for the adopters collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// AdopterFilter defines filter criteria for listing adopters
+type AdopterFilter struct {
+	Search     string // Name, email or phone
+	DoNotAdopt *bool
+	Limit      int64
+	Offset     int64
+}
@@ -44,6 +44,7 @@ type AdoptionApplicationRepository interface {
 // AdoptionApplicationFilter defines filter criteria for listing adoption applications
 type AdoptionApplicationFilter struct {
 	AnimalID        *primitive.ObjectID
+	AdopterID       *primitive.ObjectID
 	Status          string
 	ApplicantEmail  string
 	ApplicantName   string
@@ -67,6 +68,10 @@ type AdoptionRepository interface {
 	// Update updates an existing adoption
 	Update(ctx context.Context, adoption *entities.Adoption) error
 
+	// UpdateIfStatus saves an adoption only while its stored status is one of the given
+	// ones and reports whether it was saved
+	UpdateIfStatus(ctx context.Context, adoption *entities.Adoption, statuses ...entities.AdoptionStatus) (bool, error)
+
 	// Delete deletes an adoption by ID
 	Delete(ctx context.Context, id primitive.ObjectID) error
 
@@ -85,6 +90,9 @@ type AdoptionRepository interface {
 	// GetPendingFollowUps returns adoptions with pending follow-ups
 	GetPendingFollowUps(ctx context.Context, days int) ([]*entities.Adoption, error)
 
+	// FindByFollowUpToken finds the adoption with a follow-up survey token hash
+	FindByFollowUpToken(ctx context.Context, tokenHash string) (*entities.Adoption, error)
+
 	// GetAdoptionStatistics returns adoption statistics
 	GetAdoptionStatistics(ctx context.Context) (*AdoptionStatistics, error)
 
@@ -95,6 +103,7 @@ type AdoptionRepository interface {
 // AdoptionFilter defines filter criteria for listing adoptions
 type AdoptionFilter struct {
 	AnimalID      *primitive.ObjectID
+	AnimalIDs     []primitive.ObjectID // adoptions of any of the animals
 	AdopterID     *primitive.ObjectID
 	ApplicationID *primitive.ObjectID
 	Status        string
@@ -103,6 +112,17 @@ type AdoptionFilter struct {
 	ToDate        *time.Time
 	TrialPeriod   *bool
 	ProcessedBy   *primitive.ObjectID
+	SterilizationStatuses  []string   // adoptions with a spay/neuter requirement in one of the statuses
+	SterilizationDueBefore *time.Time
+	TrialEndsBefore        *time.Time // pending trial adoptions whose trial ends before the time
+	Statuses               []string   // adoptions in any of the statuses
+	CompletedFromDate      *time.Time // adoptions by the date they were finalized
+	CompletedToDate        *time.Time
+	ReturnFromDate         *time.Time // returned adoptions by return date
+	ReturnToDate           *time.Time
+	FollowUpDueBefore      *time.Time // pending or completed adoptions with a follow-up survey due and not sent
+	FollowUpSentFromDate   *time.Time // adoptions with a follow-up survey sent in the range
+	FollowUpSentToDate     *time.Time
 	Limit         int64
 	Offset        int64
 	SortBy        string // Field to sort by
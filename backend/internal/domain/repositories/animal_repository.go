@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,6 +16,9 @@ type AnimalRepository interface {
 	// FindByID finds an animal by ID
 	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Animal, error)
 
+	// FindByMicrochipNumber finds an animal by its normalized microchip number
+	FindByMicrochipNumber(ctx context.Context, number string) (*entities.Animal, error)
+
 	// Update updates an existing animal
 	Update(ctx context.Context, animal *entities.Animal) error
 
@@ -33,6 +37,9 @@ type AnimalRepository interface {
 	// UpdateStatus updates the status of an animal
 	UpdateStatus(ctx context.Context, animalID primitive.ObjectID, status entities.AnimalStatus) error
 
+	// UpdateVaccinationStatus updates the vaccination compliance fields of an animal
+	UpdateVaccinationStatus(ctx context.Context, animalID primitive.ObjectID, vaccinated bool, status entities.VaccinationStatus, nextDue *time.Time) error
+
 	// GetStatistics returns statistics about animals
 	GetStatistics(ctx context.Context) (*AnimalStatistics, error)
 
@@ -53,6 +60,7 @@ type AnimalFilter struct {
 	GoodWithCats     *bool    // Filter by good_with_cats
 	Search           string   // Search in name and description
 	AssignedCaretaker *primitive.ObjectID // Filter by assigned caretaker
+	Locations        []string // Animals housed at any of these locations, now or in the past
 	MinAge           *float64 // Minimum age in years
 	MaxAge           *float64 // Maximum age in years
 	Limit            int64    // Limit results
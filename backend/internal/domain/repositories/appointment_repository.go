@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClinicScheduleRepository defines the interface for partner clinic schedule data access
+type ClinicScheduleRepository interface {
+	// Create creates the schedule of a clinic
+	Create(ctx context.Context, schedule *entities.ClinicSchedule) error
+
+	// FindByPartnerID finds the schedule of a partner clinic
+	FindByPartnerID(ctx context.Context, partnerID primitive.ObjectID) (*entities.ClinicSchedule, error)
+
+	// Update updates an existing schedule
+	Update(ctx context.Context, schedule *entities.ClinicSchedule) error
+
+	// EnsureIndexes creates necessary indexes for the clinic_schedules collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// CalendarFeedRepository defines the interface for calendar feed data access
+type CalendarFeedRepository interface {
+	// Create creates a new feed
+	Create(ctx context.Context, feed *entities.CalendarFeed) error
+
+	// FindByID finds a feed by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.CalendarFeed, error)
+
+	// FindByToken finds a feed by its secret token
+	FindByToken(ctx context.Context, token string) (*entities.CalendarFeed, error)
+
+	// ListByUser returns the feeds owned by a user
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*entities.CalendarFeed, error)
+
+	// Update updates an existing feed
+	Update(ctx context.Context, feed *entities.CalendarFeed) error
+
+	// Delete deletes a feed by ID
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// EnsureIndexes creates necessary indexes for the calendar_feeds collection
+	EnsureIndexes(ctx context.Context) error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClinicInvoiceRepository defines the interface for clinic invoice data access
+type ClinicInvoiceRepository interface {
+	// Create creates a new invoice
+	Create(ctx context.Context, invoice *entities.ClinicInvoice) error
+
+	// FindByID finds an invoice by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.ClinicInvoice, error)
+
+	// Update updates an existing invoice
+	Update(ctx context.Context, invoice *entities.ClinicInvoice) error
+
+	// Delete deletes an invoice by ID
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// List returns the invoices matching the filter
+	List(ctx context.Context, filter *ClinicInvoiceFilter) ([]*entities.ClinicInvoice, int64, error)
+
+	// OutstandingByPartner returns the unpaid balance of each clinic, excluding void invoices
+	OutstandingByPartner(ctx context.Context, asOf time.Time) ([]*ClinicBalance, error)
+
+	// UpdateIfUnchanged updates an invoice only if it was not updated since it was read
+	// with updatedAt; reports whether it was updated
+	UpdateIfUnchanged(ctx context.Context, invoice *entities.ClinicInvoice, updatedAt time.Time) (bool, error)
+
+	// ChargedByDonation returns how much of each donation has been charged to invoices
+	ChargedByDonation(ctx context.Context, donationIDs []primitive.ObjectID) (map[primitive.ObjectID]float64, error)
+
+	// ReserveFund adds an amount to what is charged to a donation unless the total would
+	// exceed fundAmount; reports whether it was added
+	ReserveFund(ctx context.Context, donationID primitive.ObjectID, amount, fundAmount float64) (bool, error)
+
+	// ReleaseFund takes an amount off what is charged to a donation
+	ReleaseFund(ctx context.Context, donationID primitive.ObjectID, amount float64) error
+
+	// EnsureIndexes creates necessary indexes for the clinic_invoices collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// ClinicInvoiceFilter defines filter criteria for listing invoices
+type ClinicInvoiceFilter struct {
+	PartnerID *primitive.ObjectID
+	AnimalID  *primitive.ObjectID // invoices with a line for the animal
+	VisitID   *primitive.ObjectID // invoices with a line matched to the visit
+	Statuses  []string
+	Unmatched *bool      // invoices with (or without) unmatched lines
+	From      *time.Time // invoice date at or after
+	To        *time.Time // invoice date at or before
+	Limit     int64
+	Offset    int64
+	SortOrder string // "asc" or "desc" by invoice_date, default "desc"
+}
+
+// ClinicBalance is the amount owed to a partner clinic
+type ClinicBalance struct {
+	PartnerID      primitive.ObjectID `json:"partner_id" bson:"_id"`
+	ClinicName     string             `json:"clinic_name" bson:"clinic_name"`
+	InvoiceCount   int64              `json:"invoice_count" bson:"invoice_count"`
+	UnpaidCount    int64              `json:"unpaid_count" bson:"unpaid_count"`
+	Total          float64            `json:"total" bson:"total"`
+	Paid           float64            `json:"paid" bson:"paid"`
+	Balance        float64            `json:"balance" bson:"balance"`
+	OverdueBalance float64            `json:"overdue_balance" bson:"overdue_balance"`
+	OldestDueDate  *time.Time         `json:"oldest_due_date,omitempty" bson:"oldest_due_date,omitempty"`
+}
@@ -13,6 +13,7 @@ type ContactFilter struct {
 	Status  string
 	OwnerID string
 	Search  string
+	Email   string // Exact address, case-insensitive
 	Limit   int64
 	Offset  int64
 }
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContractTemplateRepository defines the interface for adoption contract template data access
+type ContractTemplateRepository interface {
+	// Create creates a new template version
+	Create(ctx context.Context, template *entities.ContractTemplate) error
+
+	// FindByID finds a template version by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.ContractTemplate, error)
+
+	// FindLatest returns the newest template version in a language, or nil if there is none
+	FindLatest(ctx context.Context, language string) (*entities.ContractTemplate, error)
+
+	// List returns the template versions, newest first; an empty language lists all
+	List(ctx context.Context, language string) ([]*entities.ContractTemplate, error)
+
+	// EnsureIndexes creates necessary indexes for the contract templates collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// ContractRepository defines the interface for adoption contract data access
+type ContractRepository interface {
+	// Create creates a new contract
+	Create(ctx context.Context, contract *entities.Contract) error
+
+	// FindByID finds a contract by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Contract, error)
+
+	// FindByTokenHash finds the pending contract with the signing token hash
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entities.Contract, error)
+
+	// Update updates an existing contract
+	Update(ctx context.Context, contract *entities.Contract) error
+
+	// UpdateIfPending saves a contract only while it is still pending with the given signing
+	// token hash; it reports whether the contract was saved
+	UpdateIfPending(ctx context.Context, contract *entities.Contract, tokenHash string) (bool, error)
+
+	// AddEvent appends an entry to the audit trail of a contract
+	AddEvent(ctx context.Context, id primitive.ObjectID, event entities.ContractEvent) error
+
+	// List returns the contracts matching the filter, newest first
+	List(ctx context.Context, filter *ContractFilter) ([]*entities.Contract, int64, error)
+
+	// EnsureIndexes creates necessary indexes for the contracts collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// ContractFilter defines filter criteria for listing contracts
+type ContractFilter struct {
+	AdoptionID *primitive.ObjectID
+	AnimalID   *primitive.ObjectID
+	Status     string
+	Limit      int64
+	Offset     int64
+}
@@ -127,6 +127,7 @@ type DonorAggregationResult struct {
 type DonationFilter struct {
 	DonorID       *primitive.ObjectID
 	CampaignID    *primitive.ObjectID
+	EventID       *primitive.ObjectID
 	Type          string
 	Status        string
 	MinAmount     *float64
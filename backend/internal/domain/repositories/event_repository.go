@@ -17,6 +17,7 @@ type EventFilter struct {
 	Featured  *bool
 	StartDate *time.Time
 	EndDate   *time.Time
+	SeriesID  *primitive.ObjectID
 	SortBy    string
 	SortOrder string
 	Limit     int64
@@ -54,7 +55,41 @@ type EventRepository interface {
 	GetEventsByCampaign(ctx context.Context, campaignID primitive.ObjectID) ([]*entities.Event, error)
 	GetEventsNeedingVolunteers(ctx context.Context) ([]*entities.Event, error)
 	UpdateEventStatistics(ctx context.Context, eventID primitive.ObjectID, attendees, volunteers int, fundsRaised float64, animalsAdopted int) error
+	// UpdateOutcomes sets the funds raised, adoptions and volunteer hours computed for an event
+	UpdateOutcomes(ctx context.Context, eventID primitive.ObjectID, fundsRaised float64, animalsAdopted int, volunteerHours float64, at time.Time) error
+	// AddReminder records a reminder before it is sent, unless one was already recorded for
+	// its offset or the event no longer starts at startDate; reports whether it was recorded
+	AddReminder(ctx context.Context, eventID primitive.ObjectID, startDate time.Time, reminder entities.EventReminder) (bool, error)
+	// SetReminderRecipients stores the number of people a recorded reminder was sent to
+	SetReminderRecipients(ctx context.Context, eventID primitive.ObjectID, offsetMinutes, recipients int) error
+	// MarkFeedbackRequested records when the feedback surveys of an event were sent
+	MarkFeedbackRequested(ctx context.Context, eventID primitive.ObjectID, at time.Time) error
 	GetEventStatistics(ctx context.Context) (*EventStatistics, error)
+	// ReserveSeats atomically takes seats on an event if they fit its capacity and reports whether they were taken
+	ReserveSeats(ctx context.Context, eventID primitive.ObjectID, seats int) (bool, error)
+	// ReleaseSeats gives back seats taken on an event
+	ReleaseSeats(ctx context.Context, eventID primitive.ObjectID, seats int) error
+	// AdjustWaitlist atomically changes the number of waitlisted registrations of an event
+	AdjustWaitlist(ctx context.Context, eventID primitive.ObjectID, delta int) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+// EventSeriesFilter represents filters for event series queries
+type EventSeriesFilter struct {
+	Type     string
+	Status   string
+	Search   string
+	ParentID *primitive.ObjectID
+	Limit    int64
+	Offset   int64
+}
+
+// EventSeriesRepository defines the interface for event series data access
+type EventSeriesRepository interface {
+	Create(ctx context.Context, series *entities.EventSeries) error
+	Update(ctx context.Context, series *entities.EventSeries) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.EventSeries, error)
+	List(ctx context.Context, filter *EventSeriesFilter) ([]*entities.EventSeries, int64, error)
 	EnsureIndexes(ctx context.Context) error
 }
 
@@ -106,24 +141,31 @@ type VolunteerRepository interface {
 
 // EventAttendanceFilter represents filters for event attendance queries
 type EventAttendanceFilter struct {
-	EventID      *primitive.ObjectID
-	VolunteerID  *primitive.ObjectID
-	UserID       *primitive.ObjectID
-	DonorID      *primitive.ObjectID
-	AttendeeType string
-	Status       string
-	SortBy       string
-	SortOrder    string
-	Limit        int64
-	Offset       int64
+	EventID          *primitive.ObjectID
+	VolunteerID      *primitive.ObjectID
+	UserID           *primitive.ObjectID
+	DonorID          *primitive.ObjectID
+	AttendeeType     string
+	Status           string
+	PaymentStatus    string
+	PaymentDueBefore *time.Time
+	SortBy           string
+	SortOrder        string
+	Limit            int64
+	Offset           int64
 }
 
 // EventAttendanceRepository defines the interface for event attendance data access
 type EventAttendanceRepository interface {
 	Create(ctx context.Context, attendance *entities.EventAttendance) error
 	Update(ctx context.Context, attendance *entities.EventAttendance) error
+	// UpdateIfStatus saves a registration only while its stored status is one of the given
+	// ones and reports whether it was saved
+	UpdateIfStatus(ctx context.Context, attendance *entities.EventAttendance, statuses ...entities.AttendanceStatus) (bool, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.EventAttendance, error)
+	// FindByFeedbackToken finds the registration with a feedback survey token hash
+	FindByFeedbackToken(ctx context.Context, tokenHash string) (*entities.EventAttendance, error)
 	List(ctx context.Context, filter *EventAttendanceFilter) ([]*entities.EventAttendance, int64, error)
 	GetAttendanceByEvent(ctx context.Context, eventID primitive.ObjectID) ([]*entities.EventAttendance, error)
 	GetAttendanceByVolunteer(ctx context.Context, volunteerID primitive.ObjectID) ([]*entities.EventAttendance, error)
@@ -132,6 +174,8 @@ type EventAttendanceRepository interface {
 	GetNoShows(ctx context.Context, eventID primitive.ObjectID) ([]*entities.EventAttendance, error)
 	GetPendingPayments(ctx context.Context) ([]*entities.EventAttendance, error)
 	CountAttendeesByEvent(ctx context.Context, eventID primitive.ObjectID) (int64, error)
+	// CountCheckedInSeats sums the seats of the checked-in registrations of an event
+	CountCheckedInSeats(ctx context.Context, eventID primitive.ObjectID) (int, error)
 	EnsureIndexes(ctx context.Context) error
 }
 
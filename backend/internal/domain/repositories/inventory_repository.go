@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -33,6 +34,7 @@ type StockTransactionRepository interface {
 	GetByItem(ctx context.Context, itemID primitive.ObjectID) ([]*entities.StockTransaction, error)
 	GetByType(ctx context.Context, transactionType entities.TransactionType) ([]*entities.StockTransaction, error)
 	GetTransactionStatistics(ctx context.Context) (*TransactionStatistics, error)
+	GetAnimalCostOfCare(ctx context.Context, animalID primitive.ObjectID, from, to *time.Time) ([]*CostOfCareItem, error)
 	EnsureIndexes(ctx context.Context) error
 }
 
@@ -60,6 +62,7 @@ type StockTransactionFilter struct {
 	ProcessedBy     *primitive.ObjectID
 	RelatedEntity   string
 	RelatedEntityID *primitive.ObjectID
+	AnimalID        *primitive.ObjectID
 	DateFrom        string
 	DateTo          string
 	Limit           int64
@@ -91,3 +94,15 @@ type TransactionStatistics struct {
 	TransactionsThisWeek int64          `json:"transactions_this_week"`
 	TransactionsThisMonth int64         `json:"transactions_this_month"`
 }
+
+// CostOfCareItem is the stock consumed for one animal, grouped by inventory item
+type CostOfCareItem struct {
+	ItemID       primitive.ObjectID `json:"item_id" bson:"_id"`
+	ItemName     string             `json:"item_name" bson:"item_name"`
+	Category     string             `json:"category" bson:"category"`
+	Unit         string             `json:"unit" bson:"unit"`
+	Quantity     float64            `json:"quantity" bson:"quantity"`
+	TotalCost    float64            `json:"total_cost" bson:"total_cost"`
+	Transactions int64              `json:"transactions" bson:"transactions"`
+	LastUsedAt   time.Time          `json:"last_used_at" bson:"last_used_at"`
+}
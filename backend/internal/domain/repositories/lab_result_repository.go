@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LabAnalyteRepository defines the interface for lab analyte data access
+type LabAnalyteRepository interface {
+	// Create creates a new analyte
+	Create(ctx context.Context, analyte *entities.LabAnalyte) error
+
+	// FindByID finds an analyte by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.LabAnalyte, error)
+
+	// FindByCode finds an analyte by its normalized code
+	FindByCode(ctx context.Context, code string) (*entities.LabAnalyte, error)
+
+	// Update updates an existing analyte
+	Update(ctx context.Context, analyte *entities.LabAnalyte) error
+
+	// List returns the analytes ordered by category and code
+	List(ctx context.Context, activeOnly bool) ([]*entities.LabAnalyte, error)
+
+	// EnsureIndexes creates necessary indexes for the lab_analytes collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// LabPanelRepository defines the interface for lab panel data access
+type LabPanelRepository interface {
+	// Create creates a new panel
+	Create(ctx context.Context, panel *entities.LabPanel) error
+
+	// FindByID finds a panel by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.LabPanel, error)
+
+	// FindByAccession finds the panel of an animal with a lab accession number
+	FindByAccession(ctx context.Context, animalID primitive.ObjectID, accessionNumber string) (*entities.LabPanel, error)
+
+	// Update updates an existing panel
+	Update(ctx context.Context, panel *entities.LabPanel) error
+
+	// Delete deletes a panel by ID
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// List returns the panels matching the filter
+	List(ctx context.Context, filter *LabPanelFilter) ([]*entities.LabPanel, int64, error)
+
+	// EnsureIndexes creates necessary indexes for the lab_panels collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// LabPanelFilter defines filter criteria for listing panels
+type LabPanelFilter struct {
+	AnimalID     *primitive.ObjectID
+	VisitID      *primitive.ObjectID
+	PartnerID    *primitive.ObjectID
+	AnalyteCodes []string // panels measuring any of these analytes
+	Abnormal     *bool
+	Critical     *bool
+	From         *time.Time // collected at or after
+	To           *time.Time // collected at or before
+	Limit        int64
+	Offset       int64
+	SortOrder    string // "asc" or "desc" by collected_at, default "desc"
+}
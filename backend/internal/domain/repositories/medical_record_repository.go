@@ -85,6 +85,12 @@ type MedicationRepository interface {
 	// AddAdministrationLog adds an administration log entry to a medication
 	AddAdministrationLog(ctx context.Context, medicationID primitive.ObjectID, log entities.AdministrationLog) error
 
+	// SetAdministrationWitness records the second sign-off on the log entry of a dose
+	SetAdministrationWitness(ctx context.Context, medicationID, doseID, witnessID primitive.ObjectID, witnessedAt time.Time) error
+
+	// FindScheduledActive finds active medications that have a structured dose schedule
+	FindScheduledActive(ctx context.Context) ([]*entities.Medication, error)
+
 	// EnsureIndexes creates necessary indexes for the medications collection
 	EnsureIndexes(ctx context.Context) error
 }
@@ -100,6 +106,43 @@ type MedicationFilter struct {
 	Offset      int64
 }
 
+// MedicationDoseRepository defines the interface for medication dose slot data access
+type MedicationDoseRepository interface {
+	// Create creates a new dose slot
+	Create(ctx context.Context, dose *entities.MedicationDose) error
+
+	// CreateScheduled inserts the dose slots that do not exist yet for their medication and time
+	CreateScheduled(ctx context.Context, doses []*entities.MedicationDose) error
+
+	// FindByID finds a dose slot by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.MedicationDose, error)
+
+	// Update updates a dose slot
+	Update(ctx context.Context, dose *entities.MedicationDose) error
+
+	// List returns dose slots ordered by scheduled time
+	List(ctx context.Context, filter MedicationDoseFilter) ([]*entities.MedicationDose, error)
+
+	// Delete deletes a dose slot by ID
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// DeleteScheduled removes the open scheduled slots of a medication from the given time on
+	DeleteScheduled(ctx context.Context, medicationID primitive.ObjectID, from time.Time) error
+
+	// EnsureIndexes creates necessary indexes for the medication_doses collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// MedicationDoseFilter defines filter criteria for listing dose slots
+type MedicationDoseFilter struct {
+	AnimalID     *primitive.ObjectID
+	MedicationID *primitive.ObjectID
+	Statuses     []entities.MedicationDoseStatus
+	Shift        entities.MedicationShift
+	From         *time.Time
+	To           *time.Time
+}
+
 // TreatmentPlanRepository defines the interface for treatment plan data access
 type TreatmentPlanRepository interface {
 	// Create creates a new treatment plan
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AdopterRepository struct {
+	mock.Mock
+}
+
+func (m *AdopterRepository) Create(ctx context.Context, adopter *entities.Adopter) error {
+	args := m.Called(ctx, adopter)
+	return args.Error(0)
+}
+
+func (m *AdopterRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Adopter, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Adopter), args.Error(1)
+}
+
+func (m *AdopterRepository) FindMatches(ctx context.Context, emailKey, phoneKey string) ([]*entities.Adopter, error) {
+	args := m.Called(ctx, emailKey, phoneKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Adopter), args.Error(1)
+}
+
+func (m *AdopterRepository) Update(ctx context.Context, adopter *entities.Adopter) error {
+	args := m.Called(ctx, adopter)
+	return args.Error(0)
+}
+
+func (m *AdopterRepository) List(ctx context.Context, filter *repositories.AdopterFilter) ([]*entities.Adopter, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Adopter), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *AdopterRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AdoptionApplicationRepository struct {
+	mock.Mock
+}
+
+func (m *AdoptionApplicationRepository) Create(ctx context.Context, application *entities.AdoptionApplication) error {
+	args := m.Called(ctx, application)
+	return args.Error(0)
+}
+
+func (m *AdoptionApplicationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AdoptionApplication), args.Error(1)
+}
+
+func (m *AdoptionApplicationRepository) Update(ctx context.Context, application *entities.AdoptionApplication) error {
+	args := m.Called(ctx, application)
+	return args.Error(0)
+}
+
+func (m *AdoptionApplicationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *AdoptionApplicationRepository) List(ctx context.Context, filter repositories.AdoptionApplicationFilter) ([]*entities.AdoptionApplication, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.AdoptionApplication), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *AdoptionApplicationRepository) GetByAnimalID(ctx context.Context, animalID primitive.ObjectID) ([]*entities.AdoptionApplication, error) {
+	args := m.Called(ctx, animalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AdoptionApplication), args.Error(1)
+}
+
+func (m *AdoptionApplicationRepository) GetByApplicantEmail(ctx context.Context, email string) ([]*entities.AdoptionApplication, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AdoptionApplication), args.Error(1)
+}
+
+func (m *AdoptionApplicationRepository) GetPendingApplications(ctx context.Context) ([]*entities.AdoptionApplication, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AdoptionApplication), args.Error(1)
+}
+
+func (m *AdoptionApplicationRepository) GetApplicationsByStatus(ctx context.Context, status entities.ApplicationStatus) ([]*entities.AdoptionApplication, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AdoptionApplication), args.Error(1)
+}
+
+func (m *AdoptionApplicationRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,103 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AdoptionRepository struct {
+	mock.Mock
+}
+
+func (m *AdoptionRepository) Create(ctx context.Context, adoption *entities.Adoption) error {
+	args := m.Called(ctx, adoption)
+	return args.Error(0)
+}
+
+func (m *AdoptionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Adoption, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Adoption), args.Error(1)
+}
+
+func (m *AdoptionRepository) Update(ctx context.Context, adoption *entities.Adoption) error {
+	args := m.Called(ctx, adoption)
+	return args.Error(0)
+}
+
+func (m *AdoptionRepository) UpdateIfStatus(ctx context.Context, adoption *entities.Adoption, statuses ...entities.AdoptionStatus) (bool, error) {
+	args := m.Called(ctx, adoption, statuses)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *AdoptionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *AdoptionRepository) List(ctx context.Context, filter repositories.AdoptionFilter) ([]*entities.Adoption, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Adoption), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *AdoptionRepository) GetByAnimalID(ctx context.Context, animalID primitive.ObjectID) (*entities.Adoption, error) {
+	args := m.Called(ctx, animalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Adoption), args.Error(1)
+}
+
+func (m *AdoptionRepository) GetByAdopterID(ctx context.Context, adopterID primitive.ObjectID) ([]*entities.Adoption, error) {
+	args := m.Called(ctx, adopterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Adoption), args.Error(1)
+}
+
+func (m *AdoptionRepository) GetByApplicationID(ctx context.Context, applicationID primitive.ObjectID) (*entities.Adoption, error) {
+	args := m.Called(ctx, applicationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Adoption), args.Error(1)
+}
+
+func (m *AdoptionRepository) FindByFollowUpToken(ctx context.Context, tokenHash string) (*entities.Adoption, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Adoption), args.Error(1)
+}
+
+func (m *AdoptionRepository) GetPendingFollowUps(ctx context.Context, days int) ([]*entities.Adoption, error) {
+	args := m.Called(ctx, days)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Adoption), args.Error(1)
+}
+
+func (m *AdoptionRepository) GetAdoptionStatistics(ctx context.Context) (*repositories.AdoptionStatistics, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repositories.AdoptionStatistics), args.Error(1)
+}
+
+func (m *AdoptionRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
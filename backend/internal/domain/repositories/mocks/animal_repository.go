@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
@@ -26,6 +27,14 @@ func (m *AnimalRepository) FindByID(ctx context.Context, id primitive.ObjectID)
 	return args.Get(0).(*entities.Animal), args.Error(1)
 }
 
+func (m *AnimalRepository) FindByMicrochipNumber(ctx context.Context, number string) (*entities.Animal, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Animal), args.Error(1)
+}
+
 func (m *AnimalRepository) Update(ctx context.Context, animal *entities.Animal) error {
 	args := m.Called(ctx, animal)
 	return args.Error(0)
@@ -56,6 +65,11 @@ func (m *AnimalRepository) UpdateStatus(ctx context.Context, animalID primitive.
 	return args.Error(0)
 }
 
+func (m *AnimalRepository) UpdateVaccinationStatus(ctx context.Context, animalID primitive.ObjectID, vaccinated bool, status entities.VaccinationStatus, nextDue *time.Time) error {
+	args := m.Called(ctx, animalID, vaccinated, status, nextDue)
+	return args.Error(0)
+}
+
 func (m *AnimalRepository) GetStatistics(ctx context.Context) (*repositories.AnimalStatistics, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
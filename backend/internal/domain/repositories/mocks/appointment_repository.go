@@ -0,0 +1,84 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ClinicScheduleRepository struct {
+	mock.Mock
+}
+
+func (m *ClinicScheduleRepository) Create(ctx context.Context, schedule *entities.ClinicSchedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *ClinicScheduleRepository) FindByPartnerID(ctx context.Context, partnerID primitive.ObjectID) (*entities.ClinicSchedule, error) {
+	args := m.Called(ctx, partnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ClinicSchedule), args.Error(1)
+}
+
+func (m *ClinicScheduleRepository) Update(ctx context.Context, schedule *entities.ClinicSchedule) error {
+	args := m.Called(ctx, schedule)
+	return args.Error(0)
+}
+
+func (m *ClinicScheduleRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+type CalendarFeedRepository struct {
+	mock.Mock
+}
+
+func (m *CalendarFeedRepository) Create(ctx context.Context, feed *entities.CalendarFeed) error {
+	args := m.Called(ctx, feed)
+	return args.Error(0)
+}
+
+func (m *CalendarFeedRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.CalendarFeed, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CalendarFeed), args.Error(1)
+}
+
+func (m *CalendarFeedRepository) FindByToken(ctx context.Context, token string) (*entities.CalendarFeed, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.CalendarFeed), args.Error(1)
+}
+
+func (m *CalendarFeedRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*entities.CalendarFeed, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.CalendarFeed), args.Error(1)
+}
+
+func (m *CalendarFeedRepository) Update(ctx context.Context, feed *entities.CalendarFeed) error {
+	args := m.Called(ctx, feed)
+	return args.Error(0)
+}
+
+func (m *CalendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *CalendarFeedRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ClinicInvoiceRepository struct {
+	mock.Mock
+}
+
+func (m *ClinicInvoiceRepository) Create(ctx context.Context, invoice *entities.ClinicInvoice) error {
+	args := m.Called(ctx, invoice)
+	return args.Error(0)
+}
+
+func (m *ClinicInvoiceRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ClinicInvoice), args.Error(1)
+}
+
+func (m *ClinicInvoiceRepository) Update(ctx context.Context, invoice *entities.ClinicInvoice) error {
+	args := m.Called(ctx, invoice)
+	return args.Error(0)
+}
+
+func (m *ClinicInvoiceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ClinicInvoiceRepository) List(ctx context.Context, filter *repositories.ClinicInvoiceFilter) ([]*entities.ClinicInvoice, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.ClinicInvoice), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *ClinicInvoiceRepository) OutstandingByPartner(ctx context.Context, asOf time.Time) ([]*repositories.ClinicBalance, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repositories.ClinicBalance), args.Error(1)
+}
+
+func (m *ClinicInvoiceRepository) ChargedByDonation(ctx context.Context, donationIDs []primitive.ObjectID) (map[primitive.ObjectID]float64, error) {
+	args := m.Called(ctx, donationIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[primitive.ObjectID]float64), args.Error(1)
+}
+
+func (m *ClinicInvoiceRepository) UpdateIfUnchanged(ctx context.Context, invoice *entities.ClinicInvoice, updatedAt time.Time) (bool, error) {
+	args := m.Called(ctx, invoice, updatedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ClinicInvoiceRepository) ReserveFund(ctx context.Context, donationID primitive.ObjectID, amount, fundAmount float64) (bool, error) {
+	args := m.Called(ctx, donationID, amount, fundAmount)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ClinicInvoiceRepository) ReleaseFund(ctx context.Context, donationID primitive.ObjectID, amount float64) error {
+	args := m.Called(ctx, donationID, amount)
+	return args.Error(0)
+}
+
+func (m *ClinicInvoiceRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
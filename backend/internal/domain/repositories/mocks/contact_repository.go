@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ContactRepository struct {
+	mock.Mock
+}
+
+func (m *ContactRepository) Create(ctx context.Context, contact *entities.Contact) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *ContactRepository) Update(ctx context.Context, contact *entities.Contact) error {
+	args := m.Called(ctx, contact)
+	return args.Error(0)
+}
+
+func (m *ContactRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *ContactRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Contact, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Contact), args.Error(1)
+}
+
+func (m *ContactRepository) List(ctx context.Context, filter repositories.ContactFilter) ([]*entities.Contact, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Contact), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *ContactRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
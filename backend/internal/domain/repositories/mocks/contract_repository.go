@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ContractTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *ContractTemplateRepository) Create(ctx context.Context, template *entities.ContractTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *ContractTemplateRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.ContractTemplate, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ContractTemplate), args.Error(1)
+}
+
+func (m *ContractTemplateRepository) FindLatest(ctx context.Context, language string) (*entities.ContractTemplate, error) {
+	args := m.Called(ctx, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ContractTemplate), args.Error(1)
+}
+
+func (m *ContractTemplateRepository) List(ctx context.Context, language string) ([]*entities.ContractTemplate, error) {
+	args := m.Called(ctx, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.ContractTemplate), args.Error(1)
+}
+
+func (m *ContractTemplateRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+type ContractRepository struct {
+	mock.Mock
+}
+
+func (m *ContractRepository) Create(ctx context.Context, contract *entities.Contract) error {
+	args := m.Called(ctx, contract)
+	return args.Error(0)
+}
+
+func (m *ContractRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Contract, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Contract), args.Error(1)
+}
+
+func (m *ContractRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entities.Contract, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Contract), args.Error(1)
+}
+
+func (m *ContractRepository) Update(ctx context.Context, contract *entities.Contract) error {
+	args := m.Called(ctx, contract)
+	return args.Error(0)
+}
+
+func (m *ContractRepository) UpdateIfPending(ctx context.Context, contract *entities.Contract, tokenHash string) (bool, error) {
+	args := m.Called(ctx, contract, tokenHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ContractRepository) AddEvent(ctx context.Context, id primitive.ObjectID, event entities.ContractEvent) error {
+	args := m.Called(ctx, id, event)
+	return args.Error(0)
+}
+
+func (m *ContractRepository) List(ctx context.Context, filter *repositories.ContractFilter) ([]*entities.Contract, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Contract), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *ContractRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -23,6 +23,11 @@ func (m *EventAttendanceRepository) Update(ctx context.Context, attendance *enti
 	return args.Error(0)
 }
 
+func (m *EventAttendanceRepository) UpdateIfStatus(ctx context.Context, attendance *entities.EventAttendance, statuses ...entities.AttendanceStatus) (bool, error) {
+	args := m.Called(ctx, attendance, statuses)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *EventAttendanceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -33,6 +38,14 @@ func (m *EventAttendanceRepository) FindByID(ctx context.Context, id primitive.O
 	return args.Get(0).(*entities.EventAttendance), args.Error(1)
 }
 
+func (m *EventAttendanceRepository) FindByFeedbackToken(ctx context.Context, tokenHash string) (*entities.EventAttendance, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EventAttendance), args.Error(1)
+}
+
 func (m *EventAttendanceRepository) List(ctx context.Context, filter *repositories.EventAttendanceFilter) ([]*entities.EventAttendance, int64, error) {
 	args := m.Called(ctx, filter)
 	return args.Get(0).([]*entities.EventAttendance), args.Get(1).(int64), args.Error(2)
@@ -73,6 +86,11 @@ func (m *EventAttendanceRepository) CountAttendeesByEvent(ctx context.Context, e
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *EventAttendanceRepository) CountCheckedInSeats(ctx context.Context, eventID primitive.ObjectID) (int, error) {
+	args := m.Called(ctx, eventID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *EventAttendanceRepository) EnsureIndexes(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
@@ -83,6 +84,41 @@ func (m *EventRepository) UpdateEventStatistics(ctx context.Context, eventID pri
 	return args.Error(0)
 }
 
+func (m *EventRepository) ReserveSeats(ctx context.Context, eventID primitive.ObjectID, seats int) (bool, error) {
+	args := m.Called(ctx, eventID, seats)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *EventRepository) ReleaseSeats(ctx context.Context, eventID primitive.ObjectID, seats int) error {
+	args := m.Called(ctx, eventID, seats)
+	return args.Error(0)
+}
+
+func (m *EventRepository) UpdateOutcomes(ctx context.Context, eventID primitive.ObjectID, fundsRaised float64, animalsAdopted int, volunteerHours float64, at time.Time) error {
+	args := m.Called(ctx, eventID, fundsRaised, animalsAdopted, volunteerHours, at)
+	return args.Error(0)
+}
+
+func (m *EventRepository) AddReminder(ctx context.Context, eventID primitive.ObjectID, startDate time.Time, reminder entities.EventReminder) (bool, error) {
+	args := m.Called(ctx, eventID, startDate, reminder)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *EventRepository) SetReminderRecipients(ctx context.Context, eventID primitive.ObjectID, offsetMinutes, recipients int) error {
+	args := m.Called(ctx, eventID, offsetMinutes, recipients)
+	return args.Error(0)
+}
+
+func (m *EventRepository) MarkFeedbackRequested(ctx context.Context, eventID primitive.ObjectID, at time.Time) error {
+	args := m.Called(ctx, eventID, at)
+	return args.Error(0)
+}
+
+func (m *EventRepository) AdjustWaitlist(ctx context.Context, eventID primitive.ObjectID, delta int) error {
+	args := m.Called(ctx, eventID, delta)
+	return args.Error(0)
+}
+
 func (m *EventRepository) GetEventStatistics(ctx context.Context) (*repositories.EventStatistics, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(*repositories.EventStatistics), args.Error(1)
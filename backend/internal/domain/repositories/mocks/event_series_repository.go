@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventSeriesRepository struct {
+	mock.Mock
+}
+
+func (m *EventSeriesRepository) Create(ctx context.Context, series *entities.EventSeries) error {
+	args := m.Called(ctx, series)
+	return args.Error(0)
+}
+
+func (m *EventSeriesRepository) Update(ctx context.Context, series *entities.EventSeries) error {
+	args := m.Called(ctx, series)
+	return args.Error(0)
+}
+
+func (m *EventSeriesRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.EventSeries, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EventSeries), args.Error(1)
+}
+
+func (m *EventSeriesRepository) List(ctx context.Context, filter *repositories.EventSeriesFilter) ([]*entities.EventSeries, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.EventSeries), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *EventSeriesRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
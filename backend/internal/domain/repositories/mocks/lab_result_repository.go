@@ -0,0 +1,101 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LabAnalyteRepository struct {
+	mock.Mock
+}
+
+func (m *LabAnalyteRepository) Create(ctx context.Context, analyte *entities.LabAnalyte) error {
+	args := m.Called(ctx, analyte)
+	return args.Error(0)
+}
+
+func (m *LabAnalyteRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.LabAnalyte, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LabAnalyte), args.Error(1)
+}
+
+func (m *LabAnalyteRepository) FindByCode(ctx context.Context, code string) (*entities.LabAnalyte, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LabAnalyte), args.Error(1)
+}
+
+func (m *LabAnalyteRepository) Update(ctx context.Context, analyte *entities.LabAnalyte) error {
+	args := m.Called(ctx, analyte)
+	return args.Error(0)
+}
+
+func (m *LabAnalyteRepository) List(ctx context.Context, activeOnly bool) ([]*entities.LabAnalyte, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.LabAnalyte), args.Error(1)
+}
+
+func (m *LabAnalyteRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+type LabPanelRepository struct {
+	mock.Mock
+}
+
+func (m *LabPanelRepository) Create(ctx context.Context, panel *entities.LabPanel) error {
+	args := m.Called(ctx, panel)
+	return args.Error(0)
+}
+
+func (m *LabPanelRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.LabPanel, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LabPanel), args.Error(1)
+}
+
+func (m *LabPanelRepository) FindByAccession(ctx context.Context, animalID primitive.ObjectID, accessionNumber string) (*entities.LabPanel, error) {
+	args := m.Called(ctx, animalID, accessionNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.LabPanel), args.Error(1)
+}
+
+func (m *LabPanelRepository) Update(ctx context.Context, panel *entities.LabPanel) error {
+	args := m.Called(ctx, panel)
+	return args.Error(0)
+}
+
+func (m *LabPanelRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *LabPanelRepository) List(ctx context.Context, filter *repositories.LabPanelFilter) ([]*entities.LabPanel, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*entities.LabPanel), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *LabPanelRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
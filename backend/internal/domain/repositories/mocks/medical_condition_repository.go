@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MedicalConditionRepository struct {
+	mock.Mock
+}
+
+func (m *MedicalConditionRepository) Create(ctx context.Context, condition *entities.MedicalCondition) error {
+	args := m.Called(ctx, condition)
+	return args.Error(0)
+}
+
+func (m *MedicalConditionRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.MedicalCondition, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.MedicalCondition), args.Error(1)
+}
+
+func (m *MedicalConditionRepository) Update(ctx context.Context, condition *entities.MedicalCondition) error {
+	args := m.Called(ctx, condition)
+	return args.Error(0)
+}
+
+func (m *MedicalConditionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MedicalConditionRepository) List(ctx context.Context, filter repositories.MedicalConditionFilter) ([]*entities.MedicalCondition, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.MedicalCondition), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MedicalConditionRepository) FindByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.MedicalCondition, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).([]*entities.MedicalCondition), args.Error(1)
+}
+
+func (m *MedicalConditionRepository) FindActiveByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.MedicalCondition, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).([]*entities.MedicalCondition), args.Error(1)
+}
+
+func (m *MedicalConditionRepository) FindChronicConditions(ctx context.Context) ([]*entities.MedicalCondition, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entities.MedicalCondition), args.Error(1)
+}
+
+func (m *MedicalConditionRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,135 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MedicationRepository struct {
+	mock.Mock
+}
+
+func (m *MedicationRepository) Create(ctx context.Context, medication *entities.Medication) error {
+	args := m.Called(ctx, medication)
+	return args.Error(0)
+}
+
+func (m *MedicationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Medication, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) Update(ctx context.Context, medication *entities.Medication) error {
+	args := m.Called(ctx, medication)
+	return args.Error(0)
+}
+
+func (m *MedicationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MedicationRepository) List(ctx context.Context, filter repositories.MedicationFilter) ([]*entities.Medication, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.Medication), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MedicationRepository) FindByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.Medication, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).([]*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) FindActiveByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.Medication, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).([]*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) FindByCondition(ctx context.Context, conditionID primitive.ObjectID) ([]*entities.Medication, error) {
+	args := m.Called(ctx, conditionID)
+	return args.Get(0).([]*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) FindDueForRefill(ctx context.Context) ([]*entities.Medication, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) FindExpiringSoon(ctx context.Context, days int) ([]*entities.Medication, error) {
+	args := m.Called(ctx, days)
+	return args.Get(0).([]*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) AddAdministrationLog(ctx context.Context, medicationID primitive.ObjectID, log entities.AdministrationLog) error {
+	args := m.Called(ctx, medicationID, log)
+	return args.Error(0)
+}
+
+func (m *MedicationRepository) SetAdministrationWitness(ctx context.Context, medicationID, doseID, witnessID primitive.ObjectID, witnessedAt time.Time) error {
+	args := m.Called(ctx, medicationID, doseID, witnessID, witnessedAt)
+	return args.Error(0)
+}
+
+func (m *MedicationRepository) FindScheduledActive(ctx context.Context) ([]*entities.Medication, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entities.Medication), args.Error(1)
+}
+
+func (m *MedicationRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+type MedicationDoseRepository struct {
+	mock.Mock
+}
+
+func (m *MedicationDoseRepository) Create(ctx context.Context, dose *entities.MedicationDose) error {
+	args := m.Called(ctx, dose)
+	return args.Error(0)
+}
+
+func (m *MedicationDoseRepository) CreateScheduled(ctx context.Context, doses []*entities.MedicationDose) error {
+	args := m.Called(ctx, doses)
+	return args.Error(0)
+}
+
+func (m *MedicationDoseRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.MedicationDose, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.MedicationDose), args.Error(1)
+}
+
+func (m *MedicationDoseRepository) Update(ctx context.Context, dose *entities.MedicationDose) error {
+	args := m.Called(ctx, dose)
+	return args.Error(0)
+}
+
+func (m *MedicationDoseRepository) List(ctx context.Context, filter repositories.MedicationDoseFilter) ([]*entities.MedicationDose, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.MedicationDose), args.Error(1)
+}
+
+func (m *MedicationDoseRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MedicationDoseRepository) DeleteScheduled(ctx context.Context, medicationID primitive.ObjectID, from time.Time) error {
+	args := m.Called(ctx, medicationID, from)
+	return args.Error(0)
+}
+
+func (m *MedicationDoseRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OutbreakRepository struct {
+	mock.Mock
+}
+
+func (m *OutbreakRepository) Create(ctx context.Context, outbreak *entities.Outbreak) error {
+	args := m.Called(ctx, outbreak)
+	return args.Error(0)
+}
+
+func (m *OutbreakRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Outbreak, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Outbreak), args.Error(1)
+}
+
+func (m *OutbreakRepository) Update(ctx context.Context, outbreak *entities.Outbreak) error {
+	args := m.Called(ctx, outbreak)
+	return args.Error(0)
+}
+
+func (m *OutbreakRepository) List(ctx context.Context, filter *repositories.OutbreakFilter) ([]*entities.Outbreak, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Outbreak), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *OutbreakRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
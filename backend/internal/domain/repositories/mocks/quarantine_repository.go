@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type QuarantineRepository struct {
+	mock.Mock
+}
+
+func (m *QuarantineRepository) Create(ctx context.Context, quarantine *entities.Quarantine) error {
+	args := m.Called(ctx, quarantine)
+	return args.Error(0)
+}
+
+func (m *QuarantineRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Quarantine, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Quarantine), args.Error(1)
+}
+
+func (m *QuarantineRepository) FindActiveByAnimal(ctx context.Context, animalID primitive.ObjectID) (*entities.Quarantine, error) {
+	args := m.Called(ctx, animalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Quarantine), args.Error(1)
+}
+
+func (m *QuarantineRepository) Update(ctx context.Context, quarantine *entities.Quarantine) error {
+	args := m.Called(ctx, quarantine)
+	return args.Error(0)
+}
+
+func (m *QuarantineRepository) List(ctx context.Context, filter *repositories.QuarantineFilter) ([]*entities.Quarantine, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entities.Quarantine), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *QuarantineRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+)
+
+type SearchRepository struct {
+	mock.Mock
+}
+
+func (m *SearchRepository) Search(ctx context.Context, query *repositories.SearchQuery) ([]*entities.SearchHit, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.SearchHit), args.Error(1)
+}
+
+func (m *SearchRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
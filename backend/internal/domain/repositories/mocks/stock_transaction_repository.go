@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
@@ -43,6 +44,14 @@ func (m *StockTransactionRepository) GetTransactionStatistics(ctx context.Contex
 	return args.Get(0).(*repositories.TransactionStatistics), args.Error(1)
 }
 
+func (m *StockTransactionRepository) GetAnimalCostOfCare(ctx context.Context, animalID primitive.ObjectID, from, to *time.Time) ([]*repositories.CostOfCareItem, error) {
+	args := m.Called(ctx, animalID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repositories.CostOfCareItem), args.Error(1)
+}
+
 func (m *StockTransactionRepository) EnsureIndexes(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
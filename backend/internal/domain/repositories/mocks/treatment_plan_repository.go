@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TreatmentPlanRepository struct {
+	mock.Mock
+}
+
+func (m *TreatmentPlanRepository) Create(ctx context.Context, plan *entities.TreatmentPlan) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+func (m *TreatmentPlanRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.TreatmentPlan, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TreatmentPlan), args.Error(1)
+}
+
+func (m *TreatmentPlanRepository) Update(ctx context.Context, plan *entities.TreatmentPlan) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+func (m *TreatmentPlanRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *TreatmentPlanRepository) List(ctx context.Context, filter repositories.TreatmentPlanFilter) ([]*entities.TreatmentPlan, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.TreatmentPlan), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *TreatmentPlanRepository) FindByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.TreatmentPlan, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).([]*entities.TreatmentPlan), args.Error(1)
+}
+
+func (m *TreatmentPlanRepository) FindActiveByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.TreatmentPlan, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).([]*entities.TreatmentPlan), args.Error(1)
+}
+
+func (m *TreatmentPlanRepository) FindByCondition(ctx context.Context, conditionID primitive.ObjectID) ([]*entities.TreatmentPlan, error) {
+	args := m.Called(ctx, conditionID)
+	return args.Get(0).([]*entities.TreatmentPlan), args.Error(1)
+}
+
+func (m *TreatmentPlanRepository) AddProgressNote(ctx context.Context, planID primitive.ObjectID, note entities.ProgressNote) error {
+	args := m.Called(ctx, planID, note)
+	return args.Error(0)
+}
+
+func (m *TreatmentPlanRepository) UpdateProcedureStatus(ctx context.Context, planID primitive.ObjectID, procedureIndex int, status string, completedDate *time.Time, performedBy *primitive.ObjectID) error {
+	args := m.Called(ctx, planID, procedureIndex, status, completedDate, performedBy)
+	return args.Error(0)
+}
+
+func (m *TreatmentPlanRepository) UpdateFollowUpStatus(ctx context.Context, planID primitive.ObjectID, followUpIndex int, completedDate *time.Time, vetVisitID *primitive.ObjectID) error {
+	args := m.Called(ctx, planID, followUpIndex, completedDate, vetVisitID)
+	return args.Error(0)
+}
+
+func (m *TreatmentPlanRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type VaccinationProtocolRepository struct {
+	mock.Mock
+}
+
+func (m *VaccinationProtocolRepository) Create(ctx context.Context, protocol *entities.VaccinationProtocol) error {
+	args := m.Called(ctx, protocol)
+	return args.Error(0)
+}
+
+func (m *VaccinationProtocolRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.VaccinationProtocol), args.Error(1)
+}
+
+func (m *VaccinationProtocolRepository) Update(ctx context.Context, protocol *entities.VaccinationProtocol) error {
+	args := m.Called(ctx, protocol)
+	return args.Error(0)
+}
+
+func (m *VaccinationProtocolRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *VaccinationProtocolRepository) List(ctx context.Context, filter repositories.VaccinationProtocolFilter) ([]*entities.VaccinationProtocol, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VaccinationProtocol), args.Error(1)
+}
+
+func (m *VaccinationProtocolRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+type VaccinationScheduleRepository struct {
+	mock.Mock
+}
+
+func (m *VaccinationScheduleRepository) CreateMany(ctx context.Context, items []*entities.VaccinationScheduleItem) error {
+	args := m.Called(ctx, items)
+	return args.Error(0)
+}
+
+func (m *VaccinationScheduleRepository) Update(ctx context.Context, item *entities.VaccinationScheduleItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *VaccinationScheduleRepository) GetByAnimalID(ctx context.Context, animalID primitive.ObjectID) ([]*entities.VaccinationScheduleItem, error) {
+	args := m.Called(ctx, animalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VaccinationScheduleItem), args.Error(1)
+}
+
+func (m *VaccinationScheduleRepository) FindByVaccinationID(ctx context.Context, vaccinationID primitive.ObjectID) (*entities.VaccinationScheduleItem, error) {
+	args := m.Called(ctx, vaccinationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.VaccinationScheduleItem), args.Error(1)
+}
+
+func (m *VaccinationScheduleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *VaccinationScheduleRepository) DeletePending(ctx context.Context, animalID primitive.ObjectID) error {
+	args := m.Called(ctx, animalID)
+	return args.Error(0)
+}
+
+func (m *VaccinationScheduleRepository) ListPending(ctx context.Context, dueBefore time.Time) ([]*entities.VaccinationScheduleItem, error) {
+	args := m.Called(ctx, dueBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VaccinationScheduleItem), args.Error(1)
+}
+
+func (m *VaccinationScheduleRepository) PendingAnimalIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]primitive.ObjectID), args.Error(1)
+}
+
+func (m *VaccinationScheduleRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,45 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type VitalSignRepository struct {
+	mock.Mock
+}
+
+func (m *VitalSignRepository) Create(ctx context.Context, reading *entities.VitalSignReading) error {
+	args := m.Called(ctx, reading)
+	return args.Error(0)
+}
+
+func (m *VitalSignRepository) Update(ctx context.Context, reading *entities.VitalSignReading) error {
+	args := m.Called(ctx, reading)
+	return args.Error(0)
+}
+
+func (m *VitalSignRepository) FindBySource(ctx context.Context, source entities.VitalSource, sourceID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	args := m.Called(ctx, source, sourceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.VitalSignReading), args.Error(1)
+}
+
+func (m *VitalSignRepository) List(ctx context.Context, filter *repositories.VitalSignFilter) ([]*entities.VitalSignReading, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*entities.VitalSignReading), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *VitalSignRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
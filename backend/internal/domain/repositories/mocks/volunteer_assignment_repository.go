@@ -0,0 +1,115 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type VolunteerAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *VolunteerAssignmentRepository) Create(ctx context.Context, assignment *entities.VolunteerAssignment) error {
+	args := m.Called(ctx, assignment)
+	return args.Error(0)
+}
+
+func (m *VolunteerAssignmentRepository) Update(ctx context.Context, assignment *entities.VolunteerAssignment) error {
+	args := m.Called(ctx, assignment)
+	return args.Error(0)
+}
+
+func (m *VolunteerAssignmentRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *VolunteerAssignmentRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) List(ctx context.Context, filter *repositories.VolunteerAssignmentFilter) ([]*entities.VolunteerAssignment, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *VolunteerAssignmentRepository) GetAssignmentsByVolunteer(ctx context.Context, volunteerID primitive.ObjectID) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, volunteerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetAssignmentsByEvent(ctx context.Context, eventID primitive.ObjectID) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetUpcomingAssignments(ctx context.Context, volunteerID primitive.ObjectID) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, volunteerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetActiveAssignments(ctx context.Context, volunteerID primitive.ObjectID) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, volunteerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetAssignmentsNeedingReminder(ctx context.Context) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetCompletedAssignmentsByVolunteer(ctx context.Context, volunteerID primitive.ObjectID, startDate, endDate time.Time) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, volunteerID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetAssignmentsByAnimal(ctx context.Context, animalID primitive.ObjectID) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, animalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) GetAssignmentsByCampaign(ctx context.Context, campaignID primitive.ObjectID) ([]*entities.VolunteerAssignment, error) {
+	args := m.Called(ctx, campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.VolunteerAssignment), args.Error(1)
+}
+
+func (m *VolunteerAssignmentRepository) EnsureIndexes(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
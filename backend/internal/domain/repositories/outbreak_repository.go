@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutbreakRepository defines the interface for outbreak data access
+type OutbreakRepository interface {
+	// Create creates a new outbreak
+	Create(ctx context.Context, outbreak *entities.Outbreak) error
+
+	// FindByID finds an outbreak by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Outbreak, error)
+
+	// Update updates an existing outbreak
+	Update(ctx context.Context, outbreak *entities.Outbreak) error
+
+	// List returns the outbreaks matching the filter, most recent first
+	List(ctx context.Context, filter *OutbreakFilter) ([]*entities.Outbreak, int64, error)
+
+	// EnsureIndexes creates necessary indexes for the outbreaks collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// OutbreakFilter defines filter criteria for listing outbreaks
+type OutbreakFilter struct {
+	Disease  string
+	Status   string
+	AnimalID *primitive.ObjectID // outbreaks where the animal is a case or was exposed
+	Limit    int64
+	Offset   int64
+}
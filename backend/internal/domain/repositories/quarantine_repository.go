@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuarantineRepository defines the interface for quarantine data access
+type QuarantineRepository interface {
+	// Create creates a new quarantine
+	Create(ctx context.Context, quarantine *entities.Quarantine) error
+
+	// FindByID finds a quarantine by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Quarantine, error)
+
+	// FindActiveByAnimal returns the active quarantine of an animal, or nil if there is none
+	FindActiveByAnimal(ctx context.Context, animalID primitive.ObjectID) (*entities.Quarantine, error)
+
+	// Update updates an existing quarantine
+	Update(ctx context.Context, quarantine *entities.Quarantine) error
+
+	// List returns the quarantines matching the filter
+	List(ctx context.Context, filter *QuarantineFilter) ([]*entities.Quarantine, int64, error)
+
+	// EnsureIndexes creates necessary indexes for the quarantines collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// QuarantineFilter defines filter criteria for listing quarantines
+type QuarantineFilter struct {
+	AnimalID   *primitive.ObjectID
+	OutbreakID *primitive.ObjectID
+	Status     string
+	Reason     string
+	EndsBefore *time.Time // planned release at or before
+	Limit      int64
+	Offset     int64
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchQuery holds the criteria of a global search
+type SearchQuery struct {
+	Text   string                      // Raw query for the text indexes
+	Terms  []string                    // Folded query words used for prefix matching
+	Phone  string                      // Digits of the query when it looks like a phone number
+	Types  []entities.SearchEntityType // Entity types the caller may see
+	UserID primitive.ObjectID          // Caller, used for per-record access rules (documents)
+	Limit  int64                       // Maximum candidates per entity type
+}
+
+// SearchRepository defines the interface for searching across collections
+type SearchRepository interface {
+	// Search returns the candidate hits of every requested entity type.
+	// Hits matched by the text indexes carry their text score; ranking is left to the caller.
+	Search(ctx context.Context, query *SearchQuery) ([]*entities.SearchHit, error)
+
+	// EnsureIndexes creates the text and prefix indexes used by the search
+	EnsureIndexes(ctx context.Context) error
+}
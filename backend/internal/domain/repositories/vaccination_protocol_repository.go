@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VaccinationProtocolRepository defines the interface for vaccination protocol data access
+type VaccinationProtocolRepository interface {
+	// Create creates a new protocol
+	Create(ctx context.Context, protocol *entities.VaccinationProtocol) error
+
+	// FindByID finds a protocol by ID
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.VaccinationProtocol, error)
+
+	// Update updates an existing protocol
+	Update(ctx context.Context, protocol *entities.VaccinationProtocol) error
+
+	// Delete deletes a protocol by ID
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// List returns the protocols matching the filter
+	List(ctx context.Context, filter VaccinationProtocolFilter) ([]*entities.VaccinationProtocol, error)
+
+	// EnsureIndexes creates necessary indexes for the vaccination_protocols collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// VaccinationProtocolFilter defines filter criteria for listing protocols
+type VaccinationProtocolFilter struct {
+	Species     string
+	VaccineType string
+	Active      *bool
+}
+
+// VaccinationScheduleRepository defines the interface for scheduled vaccination data access
+type VaccinationScheduleRepository interface {
+	// CreateMany stores generated schedule items
+	CreateMany(ctx context.Context, items []*entities.VaccinationScheduleItem) error
+
+	// Update updates a schedule item
+	Update(ctx context.Context, item *entities.VaccinationScheduleItem) error
+
+	// GetByAnimalID returns the schedule of an animal ordered by due date
+	GetByAnimalID(ctx context.Context, animalID primitive.ObjectID) ([]*entities.VaccinationScheduleItem, error)
+
+	// FindByVaccinationID returns the schedule item completed by a vaccination record
+	FindByVaccinationID(ctx context.Context, vaccinationID primitive.ObjectID) (*entities.VaccinationScheduleItem, error)
+
+	// Delete removes a schedule item
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// DeletePending removes the pending items of an animal
+	DeletePending(ctx context.Context, animalID primitive.ObjectID) error
+
+	// ListPending returns pending items due before the given time across all animals
+	ListPending(ctx context.Context, dueBefore time.Time) ([]*entities.VaccinationScheduleItem, error)
+
+	// PendingAnimalIDs returns the animals that have pending items
+	PendingAnimalIDs(ctx context.Context) ([]primitive.ObjectID, error)
+
+	// EnsureIndexes creates necessary indexes for the vaccination_schedules collection
+	EnsureIndexes(ctx context.Context) error
+}
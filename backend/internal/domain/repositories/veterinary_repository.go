@@ -46,6 +46,9 @@ type VeterinaryVisitFilter struct {
 	FromDate      *time.Time
 	ToDate        *time.Time
 	VeterinarianName string
+	PartnerID     *primitive.ObjectID
+	StaffID       *primitive.ObjectID // visits with the user in assigned_staff
+	Statuses      []string
 	Limit         int64
 	Offset        int64
 	SortBy        string // Field to sort by
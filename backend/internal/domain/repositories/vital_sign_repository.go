@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VitalSignRepository defines the interface for vital-sign reading data access
+type VitalSignRepository interface {
+	// Create creates a new reading
+	Create(ctx context.Context, reading *entities.VitalSignReading) error
+
+	// Update updates an existing reading
+	Update(ctx context.Context, reading *entities.VitalSignReading) error
+
+	// FindBySource finds the reading captured by a source record, e.g. a veterinary visit
+	FindBySource(ctx context.Context, source entities.VitalSource, sourceID primitive.ObjectID) (*entities.VitalSignReading, error)
+
+	// List returns the readings matching the filter
+	List(ctx context.Context, filter *VitalSignFilter) ([]*entities.VitalSignReading, int64, error)
+
+	// EnsureIndexes creates necessary indexes for the vital_sign_readings collection
+	EnsureIndexes(ctx context.Context) error
+}
+
+// VitalSignFilter defines filter criteria for listing readings
+type VitalSignFilter struct {
+	AnimalID  *primitive.ObjectID
+	Source    string
+	Metric    string // only readings that measured this metric
+	From      *time.Time
+	To        *time.Time
+	Limit     int64
+	Offset    int64
+	SortOrder string // "asc" or "desc" by recorded_at, default "desc"
+}
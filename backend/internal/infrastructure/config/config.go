@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -21,6 +22,12 @@ type Config struct {
 	Email       EmailConfig
 	SMS         SMSConfig
 	Payment     PaymentConfig
+	Tickets     TicketConfig
+	Events      EventConfig
+	Vitals      VitalsConfig
+	Microchip   MicrochipConfig
+	Scanner     ScannerConfig
+	Jobs        JobsConfig
 }
 
 // ServerConfig holds HTTP server configuration
@@ -28,6 +35,7 @@ type ServerConfig struct {
 	Port         string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	PublicURL    string // URL of the web app, for links sent to adopters
 }
 
 // DatabaseConfig holds MongoDB configuration
@@ -54,15 +62,16 @@ type JWTConfig struct {
 
 // StorageConfig holds file storage configuration
 type StorageConfig struct {
-	Type        string // "local" or "s3"
-	LocalPath   string
-	BaseURL     string
-	MaxFileSize int64  // Max file size in bytes
-	S3Bucket    string
-	S3Region    string
-	S3AccessKey string
-	S3SecretKey string
-	S3Endpoint  string // For MinIO
+	Type          string // "local" or "s3"
+	LocalPath     string
+	BaseURL       string
+	MaxFileSize   int64 // Max file size in bytes
+	S3Bucket      string
+	S3Region      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3Endpoint    string        // For MinIO
+	PresignExpiry time.Duration // Lifetime of pre-signed download URLs
 }
 
 // CORSConfig holds CORS configuration
@@ -97,6 +106,57 @@ type PaymentConfig struct {
 	SecretKey      string
 	PublishableKey string
 	WebhookSecret  string
+	Currency       string // ISO 4217 code of fees without their own currency
+}
+
+// TicketConfig holds event ticketing configuration
+type TicketConfig struct {
+	SigningKey    string        // signs the ticket QR codes; defaults to JWT_SECRET
+	PaymentWindow time.Duration // unpaid online registrations are cancelled after this
+}
+
+// EventConfig holds event reminder, series and feedback configuration
+type EventConfig struct {
+	ReminderOffsets      []time.Duration // reminders go out this long before an event starts
+	SeriesHorizon        time.Duration   // occurrences of event series are generated this far ahead
+	FeedbackDelay        time.Duration   // feedback surveys go out this long after an event ends
+	FeedbackLinkValidity time.Duration   // feedback survey links can be used this long
+}
+
+// VitalsConfig holds vitals alert configuration
+type VitalsConfig struct {
+	WeightLossAlertPercent float64       // a weight drop of this many percent raises an alert
+	WeightLossWindow       time.Duration // the drop is measured from the highest weight recorded in this window
+}
+
+// ScannerConfig holds malware scanner configuration
+type ScannerConfig struct {
+	Type          string // "fake" or "clamav"
+	ClamAVAddress string // e.g. "tcp://clamav:3310" or "unix:///var/run/clamav/clamd.ctl"
+	Timeout       time.Duration
+}
+
+// JobsConfig holds background job configuration
+type JobsConfig struct {
+	Enabled                         bool // disable on all but one instance when running several replicas
+	VaccinationComplianceInterval   time.Duration
+	MedicationDoseInterval          time.Duration
+	QuarantineReleaseInterval       time.Duration
+	SterilizationComplianceInterval time.Duration
+	TrialEndInterval                time.Duration
+	FollowUpInterval                time.Duration
+	UnpaidTicketInterval            time.Duration
+	EventReminderInterval           time.Duration
+	EventSeriesInterval             time.Duration
+	EventOutcomeInterval            time.Duration
+}
+
+// MicrochipConfig holds microchip registry configuration
+type MicrochipConfig struct {
+	Registry string // "local" or "http"
+	Name     string // stored on registrations made through the http registry
+	APIURL   string
+	APIKey   string
 }
 
 // Load reads configuration from environment variables and files
@@ -125,6 +185,7 @@ func Load() (*Config, error) {
 			Port:         viper.GetString("SERVER_PORT"),
 			ReadTimeout:  viper.GetDuration("SERVER_READ_TIMEOUT"),
 			WriteTimeout: viper.GetDuration("SERVER_WRITE_TIMEOUT"),
+			PublicURL:    viper.GetString("PUBLIC_URL"),
 		},
 		Database: DatabaseConfig{
 			URI:      viper.GetString("DB_URI"),
@@ -143,13 +204,16 @@ func Load() (*Config, error) {
 			RefreshTokenDuration: viper.GetDuration("JWT_REFRESH_DURATION"),
 		},
 		Storage: StorageConfig{
-			Type:        viper.GetString("STORAGE_TYPE"),
-			LocalPath:   viper.GetString("STORAGE_LOCAL_PATH"),
-			S3Bucket:    viper.GetString("STORAGE_S3_BUCKET"),
-			S3Region:    viper.GetString("STORAGE_S3_REGION"),
-			S3AccessKey: viper.GetString("STORAGE_S3_ACCESS_KEY"),
-			S3SecretKey: viper.GetString("STORAGE_S3_SECRET_KEY"),
-			S3Endpoint:  viper.GetString("STORAGE_S3_ENDPOINT"),
+			Type:          viper.GetString("STORAGE_TYPE"),
+			LocalPath:     viper.GetString("STORAGE_LOCAL_PATH"),
+			BaseURL:       viper.GetString("STORAGE_BASE_URL"),
+			MaxFileSize:   viper.GetInt64("STORAGE_MAX_FILE_SIZE"),
+			S3Bucket:      viper.GetString("STORAGE_S3_BUCKET"),
+			S3Region:      viper.GetString("STORAGE_S3_REGION"),
+			S3AccessKey:   viper.GetString("STORAGE_S3_ACCESS_KEY"),
+			S3SecretKey:   viper.GetString("STORAGE_S3_SECRET_KEY"),
+			S3Endpoint:    viper.GetString("STORAGE_S3_ENDPOINT"),
+			PresignExpiry: viper.GetDuration("STORAGE_PRESIGN_EXPIRY"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: viper.GetString("CORS_ALLOWED_ORIGINS"),
@@ -174,9 +238,58 @@ func Load() (*Config, error) {
 			SecretKey:      viper.GetString("PAYMENT_SECRET_KEY"),
 			PublishableKey: viper.GetString("PAYMENT_PUBLISHABLE_KEY"),
 			WebhookSecret:  viper.GetString("PAYMENT_WEBHOOK_SECRET"),
+			Currency:       viper.GetString("PAYMENT_CURRENCY"),
+		},
+		Tickets: TicketConfig{
+			SigningKey:    viper.GetString("TICKET_SIGNING_KEY"),
+			PaymentWindow: viper.GetDuration("TICKET_PAYMENT_WINDOW"),
+		},
+		Vitals: VitalsConfig{
+			WeightLossAlertPercent: viper.GetFloat64("VITALS_WEIGHT_LOSS_ALERT_PERCENT"),
+			WeightLossWindow:       viper.GetDuration("VITALS_WEIGHT_LOSS_WINDOW"),
+		},
+		Microchip: MicrochipConfig{
+			Registry: viper.GetString("MICROCHIP_REGISTRY"),
+			Name:     viper.GetString("MICROCHIP_REGISTRY_NAME"),
+			APIURL:   viper.GetString("MICROCHIP_REGISTRY_API_URL"),
+			APIKey:   viper.GetString("MICROCHIP_REGISTRY_API_KEY"),
+		},
+		Scanner: ScannerConfig{
+			Type:          viper.GetString("MALWARE_SCANNER"),
+			ClamAVAddress: viper.GetString("CLAMAV_ADDRESS"),
+			Timeout:       viper.GetDuration("MALWARE_SCAN_TIMEOUT"),
+		},
+		Jobs: JobsConfig{
+			Enabled:                         viper.GetBool("JOBS_ENABLED"),
+			VaccinationComplianceInterval:   viper.GetDuration("JOBS_VACCINATION_COMPLIANCE_INTERVAL"),
+			MedicationDoseInterval:          viper.GetDuration("JOBS_MEDICATION_DOSE_INTERVAL"),
+			QuarantineReleaseInterval:       viper.GetDuration("JOBS_QUARANTINE_RELEASE_INTERVAL"),
+			SterilizationComplianceInterval: viper.GetDuration("JOBS_STERILIZATION_COMPLIANCE_INTERVAL"),
+			TrialEndInterval:                viper.GetDuration("JOBS_TRIAL_END_INTERVAL"),
+			FollowUpInterval:                viper.GetDuration("JOBS_FOLLOW_UP_INTERVAL"),
+			UnpaidTicketInterval:            viper.GetDuration("JOBS_UNPAID_TICKET_INTERVAL"),
+			EventReminderInterval:           viper.GetDuration("JOBS_EVENT_REMINDER_INTERVAL"),
+			EventSeriesInterval:             viper.GetDuration("JOBS_EVENT_SERIES_INTERVAL"),
+			EventOutcomeInterval:            viper.GetDuration("JOBS_EVENT_OUTCOME_INTERVAL"),
 		},
 	}
 
+	// Files are served from the bucket unless a CDN or proxy URL is configured
+	if cfg.Storage.BaseURL == "" {
+		cfg.Storage.BaseURL = defaultStorageBaseURL(cfg.Storage)
+	}
+	if cfg.Tickets.SigningKey == "" {
+		cfg.Tickets.SigningKey = cfg.JWT.Secret
+	}
+	offsets, err := parseDurations(viper.GetString("EVENT_REMINDER_OFFSETS"))
+	if err != nil {
+		return nil, fmt.Errorf("EVENT_REMINDER_OFFSETS: %w", err)
+	}
+	cfg.Events.ReminderOffsets = offsets
+	cfg.Events.SeriesHorizon = viper.GetDuration("EVENT_SERIES_HORIZON")
+	cfg.Events.FeedbackDelay = viper.GetDuration("EVENT_FEEDBACK_DELAY")
+	cfg.Events.FeedbackLinkValidity = viper.GetDuration("EVENT_FEEDBACK_LINK_VALIDITY")
+
 	// Validate required fields
 	if err := validate(cfg); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -192,6 +305,7 @@ func setDefaults() {
 	viper.SetDefault("SERVER_PORT", "8080")
 	viper.SetDefault("SERVER_READ_TIMEOUT", 15*time.Second)
 	viper.SetDefault("SERVER_WRITE_TIMEOUT", 15*time.Second)
+	viper.SetDefault("PUBLIC_URL", "http://localhost:5173")
 	viper.SetDefault("DB_URI", "mongodb://localhost:27017")
 	viper.SetDefault("DB_NAME", "animalsys")
 	viper.SetDefault("DB_TIMEOUT", 10*time.Second)
@@ -204,11 +318,77 @@ func setDefaults() {
 	viper.SetDefault("JWT_REFRESH_DURATION", 168*time.Hour) // 7 days
 	viper.SetDefault("STORAGE_TYPE", "local")
 	viper.SetDefault("STORAGE_LOCAL_PATH", "./uploads")
+	viper.SetDefault("STORAGE_MAX_FILE_SIZE", 10<<20) // 10 MB
+	viper.SetDefault("STORAGE_PRESIGN_EXPIRY", 15*time.Minute)
 	viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
 	viper.SetDefault("LOG_LEVEL", "info")
 	viper.SetDefault("EMAIL_PROVIDER", "sendgrid")
 	viper.SetDefault("SMS_PROVIDER", "twilio")
 	viper.SetDefault("PAYMENT_PROVIDER", "stripe")
+	viper.SetDefault("PAYMENT_CURRENCY", "USD")
+	viper.SetDefault("TICKET_PAYMENT_WINDOW", 30*time.Minute)
+	viper.SetDefault("EVENT_REMINDER_OFFSETS", "168h,24h,2h")
+	viper.SetDefault("EVENT_SERIES_HORIZON", 90*24*time.Hour)
+	viper.SetDefault("EVENT_FEEDBACK_DELAY", 2*time.Hour)
+	viper.SetDefault("EVENT_FEEDBACK_LINK_VALIDITY", 14*24*time.Hour)
+	viper.SetDefault("VITALS_WEIGHT_LOSS_ALERT_PERCENT", 10.0)
+	viper.SetDefault("VITALS_WEIGHT_LOSS_WINDOW", 14*24*time.Hour)
+	viper.SetDefault("MICROCHIP_REGISTRY", "local")
+	viper.SetDefault("MICROCHIP_REGISTRY_NAME", "registry")
+	viper.SetDefault("MALWARE_SCANNER", "fake")
+	viper.SetDefault("CLAMAV_ADDRESS", "tcp://localhost:3310")
+	viper.SetDefault("MALWARE_SCAN_TIMEOUT", 60*time.Second)
+	viper.SetDefault("JOBS_ENABLED", true)
+	viper.SetDefault("JOBS_VACCINATION_COMPLIANCE_INTERVAL", time.Hour)
+	viper.SetDefault("JOBS_MEDICATION_DOSE_INTERVAL", 15*time.Minute)
+	viper.SetDefault("JOBS_QUARANTINE_RELEASE_INTERVAL", time.Hour)
+	viper.SetDefault("JOBS_STERILIZATION_COMPLIANCE_INTERVAL", 6*time.Hour)
+	viper.SetDefault("JOBS_TRIAL_END_INTERVAL", time.Hour)
+	viper.SetDefault("JOBS_FOLLOW_UP_INTERVAL", time.Hour)
+	viper.SetDefault("JOBS_UNPAID_TICKET_INTERVAL", 5*time.Minute)
+	viper.SetDefault("JOBS_EVENT_REMINDER_INTERVAL", 15*time.Minute)
+	viper.SetDefault("JOBS_EVENT_SERIES_INTERVAL", 24*time.Hour)
+	viper.SetDefault("JOBS_EVENT_OUTCOME_INTERVAL", time.Hour)
+}
+
+// parseDurations parses a comma-separated list of durations, e.g. "168h,24h,2h"
+func parseDurations(value string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("%s is not a positive duration", part)
+		}
+		durations = append(durations, duration)
+	}
+	return durations, nil
+}
+
+// defaultStorageBaseURL returns the public URL prefix for the configured storage type
+func defaultStorageBaseURL(storage StorageConfig) string {
+	if storage.Type != "s3" {
+		return "/uploads"
+	}
+
+	endpoint := strings.TrimSuffix(storage.S3Endpoint, "/")
+	if endpoint == "" {
+		if storage.S3Region == "" {
+			endpoint = "https://s3.amazonaws.com"
+		} else {
+			endpoint = "https://s3." + storage.S3Region + ".amazonaws.com"
+		}
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	return endpoint + "/" + storage.S3Bucket
 }
 
 // validate checks required configuration fields
@@ -222,5 +402,11 @@ func validate(cfg *Config) error {
 	if cfg.JWT.Secret == "change-me-in-production" && cfg.Environment == "production" {
 		return fmt.Errorf("JWT_SECRET must be set in production")
 	}
+	if cfg.Vitals.WeightLossAlertPercent <= 0 || cfg.Vitals.WeightLossAlertPercent >= 100 {
+		return fmt.Errorf("VITALS_WEIGHT_LOSS_ALERT_PERCENT must be between 0 and 100")
+	}
+	if cfg.Storage.Type == "s3" && cfg.Storage.S3Bucket == "" {
+		return fmt.Errorf("STORAGE_S3_BUCKET is required for s3 storage")
+	}
 	return nil
 }
@@ -74,6 +74,7 @@ var Collections = struct {
 	Campaigns             string
 	Events                string
 	EventAttendances      string
+	EventSeries           string
 	Volunteers            string
 	VolunteerHours        string
 	VolunteerAssignments  string
@@ -91,6 +92,20 @@ var Collections = struct {
 	MedicalConditions     string
 	Medications           string
 	TreatmentPlans        string
+	VaccinationProtocols  string
+	VaccinationSchedules  string
+	VitalSignReadings     string
+	LabAnalytes           string
+	LabPanels             string
+	ClinicSchedules       string
+	CalendarFeeds         string
+	ClinicInvoices        string
+	MedicalFundBalances   string
+	Quarantines           string
+	Outbreaks             string
+	ContractTemplates     string
+	Contracts             string
+	Adopters              string
 }{
 	Users:                "users",
 	Animals:              "animals",
@@ -104,6 +119,7 @@ var Collections = struct {
 	Campaigns:            "campaigns",
 	Events:               "events",
 	EventAttendances:     "event_attendances",
+	EventSeries:          "event_series",
 	Volunteers:           "volunteers",
 	VolunteerHours:       "volunteer_hours",
 	VolunteerAssignments: "volunteer_assignments",
@@ -121,4 +137,18 @@ var Collections = struct {
 	MedicalConditions:    "medical_conditions",
 	Medications:          "medications",
 	TreatmentPlans:       "treatment_plans",
+	VaccinationProtocols: "vaccination_protocols",
+	VaccinationSchedules: "vaccination_schedules",
+	VitalSignReadings:    "vital_sign_readings",
+	LabAnalytes:          "lab_analytes",
+	LabPanels:            "lab_panels",
+	ClinicSchedules:      "clinic_schedules",
+	CalendarFeeds:        "calendar_feeds",
+	ClinicInvoices:       "clinic_invoices",
+	MedicalFundBalances:  "medical_fund_balances",
+	Quarantines:          "quarantines",
+	Outbreaks:            "outbreaks",
+	ContractTemplates:    "contract_templates",
+	Contracts:            "adoption_contracts",
+	Adopters:             "adopters",
 }
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// adopterRepository implements the AdopterRepository interface
+type adopterRepository struct {
+	db *mongodb.Database
+}
+
+// NewAdopterRepository creates a new adopter repository
+func NewAdopterRepository(db *mongodb.Database) repositories.AdopterRepository {
+	return &adopterRepository{db: db}
+}
+
+// Create creates a new adopter
+func (r *adopterRepository) Create(ctx context.Context, adopter *entities.Adopter) error {
+	adopter.CreatedAt = time.Now()
+	adopter.UpdatedAt = time.Now()
+	if adopter.ID.IsZero() {
+		adopter.ID = primitive.NewObjectID()
+	}
+
+	collection := r.db.Collection(mongodb.Collections.Adopters)
+	if _, err := collection.InsertOne(ctx, adopter); err != nil {
+		return errors.Wrap(err, 500, "failed to create adopter")
+	}
+
+	return nil
+}
+
+// FindByID finds an adopter by ID
+func (r *adopterRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Adopter, error) {
+	collection := r.db.Collection(mongodb.Collections.Adopters)
+
+	var adopter entities.Adopter
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&adopter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find adopter")
+	}
+
+	return &adopter, nil
+}
+
+// FindMatches returns the adopters with the normalized email or phone; empty keys are ignored
+func (r *adopterRepository) FindMatches(ctx context.Context, emailKey, phoneKey string) ([]*entities.Adopter, error) {
+	var or []bson.M
+	if emailKey != "" {
+		or = append(or, bson.M{"email_key": emailKey})
+	}
+	if phoneKey != "" {
+		or = append(or, bson.M{"phone_key": phoneKey})
+	}
+	if len(or) == 0 {
+		return []*entities.Adopter{}, nil
+	}
+
+	collection := r.db.Collection(mongodb.Collections.Adopters)
+	cursor, err := collection.Find(ctx, bson.M{"$or": or}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query adopters")
+	}
+	defer cursor.Close(ctx)
+
+	adopters := []*entities.Adopter{}
+	if err := cursor.All(ctx, &adopters); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode adopters")
+	}
+
+	return adopters, nil
+}
+
+// Update updates an existing adopter
+func (r *adopterRepository) Update(ctx context.Context, adopter *entities.Adopter) error {
+	adopter.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Adopters)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": adopter.ID}, adopter)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update adopter")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the adopters matching the filter, sorted by name
+func (r *adopterRepository) List(ctx context.Context, filter *repositories.AdopterFilter) ([]*entities.Adopter, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.Adopters)
+
+	query := bson.M{}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		query["$or"] = []bson.M{
+			{"first_name": pattern},
+			{"last_name": pattern},
+			{"email": pattern},
+			{"phone": pattern},
+		}
+	}
+	if filter.DoNotAdopt != nil {
+		query["do_not_adopt"] = bson.M{"$exists": *filter.DoNotAdopt}
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count adopters")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "last_name", Value: 1}, {Key: "first_name", Value: 1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query adopters")
+	}
+	defer cursor.Close(ctx)
+
+	adopters := []*entities.Adopter{}
+	if err := cursor.All(ctx, &adopters); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode adopters")
+	}
+
+	return adopters, total, nil
+}
+
+// EnsureIndexes creates necessary indexes for the adopters collection
+func (r *adopterRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.Adopters)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "email_key", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "phone_key", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "last_name", Value: 1}, {Key: "first_name", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
@@ -101,6 +101,10 @@ func (r *adoptionApplicationRepository) List(ctx context.Context, filter reposit
 		query["animal_id"] = *filter.AnimalID
 	}
 
+	if filter.AdopterID != nil {
+		query["adopter_id"] = *filter.AdopterID
+	}
+
 	if filter.Status != "" {
 		query["status"] = filter.Status
 	}
@@ -264,6 +268,9 @@ func (r *adoptionApplicationRepository) EnsureIndexes(ctx context.Context) error
 		{
 			Keys: bson.D{{Key: "reviewed_by", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "adopter_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{
 				{Key: "animal_id", Value: 1},
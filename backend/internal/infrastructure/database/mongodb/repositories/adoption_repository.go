@@ -74,6 +74,21 @@ func (r *adoptionRepository) Update(ctx context.Context, adoption *entities.Adop
 	return nil
 }
 
+// UpdateIfStatus replaces an adoption only while its stored status is one of the given ones
+func (r *adoptionRepository) UpdateIfStatus(ctx context.Context, adoption *entities.Adoption, statuses ...entities.AdoptionStatus) (bool, error) {
+	adoption.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Adoptions)
+	filter := bson.M{"_id": adoption.ID, "status": bson.M{"$in": statuses}}
+
+	result, err := collection.ReplaceOne(ctx, filter, adoption)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "failed to update adoption")
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
 // Delete deletes an adoption by ID
 func (r *adoptionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	collection := r.db.Collection(mongodb.Collections.Adoptions)
@@ -101,6 +116,10 @@ func (r *adoptionRepository) List(ctx context.Context, filter repositories.Adopt
 		query["animal_id"] = *filter.AnimalID
 	}
 
+	if len(filter.AnimalIDs) > 0 {
+		query["animal_id"] = bson.M{"$in": filter.AnimalIDs}
+	}
+
 	if filter.AdopterID != nil {
 		query["adopter_id"] = *filter.AdopterID
 	}
@@ -113,6 +132,21 @@ func (r *adoptionRepository) List(ctx context.Context, filter repositories.Adopt
 		query["status"] = filter.Status
 	}
 
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+
+	if filter.CompletedFromDate != nil || filter.CompletedToDate != nil {
+		completedFilter := bson.M{}
+		if filter.CompletedFromDate != nil {
+			completedFilter["$gte"] = *filter.CompletedFromDate
+		}
+		if filter.CompletedToDate != nil {
+			completedFilter["$lte"] = *filter.CompletedToDate
+		}
+		query["completed_date"] = completedFilter
+	}
+
 	if filter.PaymentStatus != "" {
 		query["payment_status"] = filter.PaymentStatus
 	}
@@ -125,6 +159,52 @@ func (r *adoptionRepository) List(ctx context.Context, filter repositories.Adopt
 		query["processed_by"] = *filter.ProcessedBy
 	}
 
+	if len(filter.SterilizationStatuses) > 0 {
+		query["sterilization.status"] = bson.M{"$in": filter.SterilizationStatuses}
+	}
+
+	if filter.SterilizationDueBefore != nil {
+		query["sterilization.due_date"] = bson.M{"$lt": *filter.SterilizationDueBefore}
+	}
+
+	if filter.TrialEndsBefore != nil {
+		query["trial_period"] = true
+		query["trial_end_date"] = bson.M{"$lte": *filter.TrialEndsBefore}
+	}
+
+	if filter.ReturnFromDate != nil || filter.ReturnToDate != nil {
+		returnFilter := bson.M{}
+		if filter.ReturnFromDate != nil {
+			returnFilter["$gte"] = *filter.ReturnFromDate
+		}
+		if filter.ReturnToDate != nil {
+			returnFilter["$lte"] = *filter.ReturnToDate
+		}
+		query["return_date"] = returnFilter
+	}
+
+	if filter.FollowUpDueBefore != nil {
+		if filter.Status == "" {
+			query["status"] = bson.M{"$in": []entities.AdoptionStatus{entities.AdoptionStatusPending, entities.AdoptionStatusCompleted}}
+		}
+		query["follow_up_schedule"] = bson.M{"$elemMatch": bson.M{
+			"scheduled_date": bson.M{"$lte": *filter.FollowUpDueBefore},
+			"completed_date": bson.M{"$exists": false},
+			"sent_at":        bson.M{"$exists": false},
+		}}
+	}
+
+	if filter.FollowUpSentFromDate != nil || filter.FollowUpSentToDate != nil {
+		sentFilter := bson.M{}
+		if filter.FollowUpSentFromDate != nil {
+			sentFilter["$gte"] = *filter.FollowUpSentFromDate
+		}
+		if filter.FollowUpSentToDate != nil {
+			sentFilter["$lte"] = *filter.FollowUpSentToDate
+		}
+		query["follow_up_schedule.sent_at"] = sentFilter
+	}
+
 	// Date range filter
 	if filter.FromDate != nil || filter.ToDate != nil {
 		dateFilter := bson.M{}
@@ -239,6 +319,22 @@ func (r *adoptionRepository) GetByApplicationID(ctx context.Context, application
 	return &adoption, nil
 }
 
+// FindByFollowUpToken finds the adoption with a follow-up survey token hash
+func (r *adoptionRepository) FindByFollowUpToken(ctx context.Context, tokenHash string) (*entities.Adoption, error) {
+	collection := r.db.Collection(mongodb.Collections.Adoptions)
+
+	var adoption entities.Adoption
+	err := collection.FindOne(ctx, bson.M{"follow_up_schedule.token_hash": tokenHash}).Decode(&adoption)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find adoption")
+	}
+
+	return &adoption, nil
+}
+
 // GetPendingFollowUps returns adoptions with pending follow-ups
 func (r *adoptionRepository) GetPendingFollowUps(ctx context.Context, days int) ([]*entities.Adoption, error) {
 	collection := r.db.Collection(mongodb.Collections.Adoptions)
@@ -461,6 +557,36 @@ func (r *adoptionRepository) EnsureIndexes(ctx context.Context) error {
 				{Key: "next_follow_up_date", Value: 1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "trial_end_date", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "return_date", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "completed_date", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "follow_up_schedule.token_hash", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "follow_up_schedule.sent_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "sterilization.status", Value: 1},
+				{Key: "sterilization.due_date", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
@@ -32,6 +32,9 @@ func (r *animalRepository) Create(ctx context.Context, animal *entities.Animal)
 	collection := r.db.Collection(mongodb.Collections.Animals)
 	result, err := collection.InsertOne(ctx, animal)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("microchip number is already assigned to another animal")
+		}
 		return errors.Wrap(err, 500, "failed to create animal")
 	}
 
@@ -55,6 +58,22 @@ func (r *animalRepository) FindByID(ctx context.Context, id primitive.ObjectID)
 	return &animal, nil
 }
 
+// FindByMicrochipNumber finds an animal by its normalized microchip number
+func (r *animalRepository) FindByMicrochipNumber(ctx context.Context, number string) (*entities.Animal, error) {
+	collection := r.db.Collection(mongodb.Collections.Animals)
+
+	var animal entities.Animal
+	err := collection.FindOne(ctx, bson.M{"medical.microchip_number": number}).Decode(&animal)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find animal by microchip")
+	}
+
+	return &animal, nil
+}
+
 // Update updates an existing animal
 func (r *animalRepository) Update(ctx context.Context, animal *entities.Animal) error {
 	animal.UpdatedAt = time.Now()
@@ -64,6 +83,9 @@ func (r *animalRepository) Update(ctx context.Context, animal *entities.Animal)
 
 	result, err := collection.ReplaceOne(ctx, filter, animal)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("microchip number is already assigned to another animal")
+		}
 		return errors.Wrap(err, 500, "failed to update animal")
 	}
 
@@ -147,6 +169,14 @@ func (r *animalRepository) List(ctx context.Context, filter repositories.AnimalF
 		}
 	}
 
+	if len(filter.Locations) > 0 {
+		// Kept under $and so it does not replace the search $or
+		query["$and"] = []bson.M{{"$or": []bson.M{
+			{"shelter.location": bson.M{"$in": filter.Locations}},
+			{"shelter.location_history.location": bson.M{"$in": filter.Locations}},
+		}}}
+	}
+
 	// Age filters (calculate from date_of_birth)
 	if filter.MinAge != nil || filter.MaxAge != nil {
 		ageQuery := bson.M{}
@@ -282,6 +312,32 @@ func (r *animalRepository) UpdateStatus(ctx context.Context, animalID primitive.
 	return nil
 }
 
+// UpdateVaccinationStatus updates the vaccination compliance fields of an animal
+func (r *animalRepository) UpdateVaccinationStatus(ctx context.Context, animalID primitive.ObjectID, vaccinated bool, status entities.VaccinationStatus, nextDue *time.Time) error {
+	collection := r.db.Collection(mongodb.Collections.Animals)
+
+	filter := bson.M{"_id": animalID}
+	update := bson.M{
+		"$set": bson.M{
+			"medical.vaccinated":           vaccinated,
+			"medical.vaccination_status":   status,
+			"medical.next_vaccination_due": nextDue,
+			"updated_at":                   time.Now(),
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update vaccination status")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
 // GetStatistics returns statistics about animals
 func (r *animalRepository) GetStatistics(ctx context.Context) (*repositories.AnimalStatistics, error) {
 	collection := r.db.Collection(mongodb.Collections.Animals)
@@ -421,6 +477,16 @@ func (r *animalRepository) EnsureIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "shelter.assigned_caretaker", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "shelter.location", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "shelter.location_history.location", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "medical.microchip_number", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
 		{
 			Keys: bson.D{
 				{Key: "name.en", Value: "text"},
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// clinicScheduleRepository implements the ClinicScheduleRepository interface
+type clinicScheduleRepository struct {
+	db *mongodb.Database
+}
+
+// NewClinicScheduleRepository creates a new clinic schedule repository
+func NewClinicScheduleRepository(db *mongodb.Database) repositories.ClinicScheduleRepository {
+	return &clinicScheduleRepository{db: db}
+}
+
+// Create creates the schedule of a clinic
+func (r *clinicScheduleRepository) Create(ctx context.Context, schedule *entities.ClinicSchedule) error {
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.ClinicSchedules)
+	result, err := collection.InsertOne(ctx, schedule)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("the clinic already has a schedule")
+		}
+		return errors.Wrap(err, 500, "failed to create clinic schedule")
+	}
+
+	schedule.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByPartnerID finds the schedule of a partner clinic
+func (r *clinicScheduleRepository) FindByPartnerID(ctx context.Context, partnerID primitive.ObjectID) (*entities.ClinicSchedule, error) {
+	collection := r.db.Collection(mongodb.Collections.ClinicSchedules)
+
+	var schedule entities.ClinicSchedule
+	err := collection.FindOne(ctx, bson.M{"partner_id": partnerID}).Decode(&schedule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find clinic schedule")
+	}
+
+	return &schedule, nil
+}
+
+// Update updates an existing schedule
+func (r *clinicScheduleRepository) Update(ctx context.Context, schedule *entities.ClinicSchedule) error {
+	schedule.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.ClinicSchedules)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": schedule.ID}, schedule)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update clinic schedule")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// EnsureIndexes creates necessary indexes for the clinic_schedules collection
+func (r *clinicScheduleRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.ClinicSchedules)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "partner_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
+
+// calendarFeedRepository implements the CalendarFeedRepository interface
+type calendarFeedRepository struct {
+	db *mongodb.Database
+}
+
+// NewCalendarFeedRepository creates a new calendar feed repository
+func NewCalendarFeedRepository(db *mongodb.Database) repositories.CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+// Create creates a new feed
+func (r *calendarFeedRepository) Create(ctx context.Context, feed *entities.CalendarFeed) error {
+	feed.CreatedAt = time.Now()
+	feed.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.CalendarFeeds)
+	result, err := collection.InsertOne(ctx, feed)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create calendar feed")
+	}
+
+	feed.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds a feed by ID
+func (r *calendarFeedRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.CalendarFeed, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByToken finds a feed by its secret token
+func (r *calendarFeedRepository) FindByToken(ctx context.Context, token string) (*entities.CalendarFeed, error) {
+	return r.findOne(ctx, bson.M{"token": token})
+}
+
+func (r *calendarFeedRepository) findOne(ctx context.Context, query bson.M) (*entities.CalendarFeed, error) {
+	collection := r.db.Collection(mongodb.Collections.CalendarFeeds)
+
+	var feed entities.CalendarFeed
+	err := collection.FindOne(ctx, query).Decode(&feed)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find calendar feed")
+	}
+
+	return &feed, nil
+}
+
+// ListByUser returns the feeds owned by a user, newest first
+func (r *calendarFeedRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*entities.CalendarFeed, error) {
+	collection := r.db.Collection(mongodb.Collections.CalendarFeeds)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query calendar feeds")
+	}
+	defer cursor.Close(ctx)
+
+	var feeds []*entities.CalendarFeed
+	if err := cursor.All(ctx, &feeds); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode calendar feeds")
+	}
+
+	return feeds, nil
+}
+
+// Update updates an existing feed
+func (r *calendarFeedRepository) Update(ctx context.Context, feed *entities.CalendarFeed) error {
+	feed.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.CalendarFeeds)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": feed.ID}, feed)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update calendar feed")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a feed by ID
+func (r *calendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	collection := r.db.Collection(mongodb.Collections.CalendarFeeds)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to delete calendar feed")
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// EnsureIndexes creates necessary indexes for the calendar_feeds collection
+func (r *calendarFeedRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.CalendarFeeds)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package repositories
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// clinicInvoiceRepository implements the ClinicInvoiceRepository interface
+type clinicInvoiceRepository struct {
+	db *mongodb.Database
+}
+
+// NewClinicInvoiceRepository creates a new clinic invoice repository
+func NewClinicInvoiceRepository(db *mongodb.Database) repositories.ClinicInvoiceRepository {
+	return &clinicInvoiceRepository{db: db}
+}
+
+// Create creates a new invoice
+func (r *clinicInvoiceRepository) Create(ctx context.Context, invoice *entities.ClinicInvoice) error {
+	invoice.CreatedAt = time.Now()
+	invoice.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+	result, err := collection.InsertOne(ctx, invoice)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("an invoice with this number from the clinic already exists")
+		}
+		return errors.Wrap(err, 500, "failed to create clinic invoice")
+	}
+
+	invoice.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds an invoice by ID
+func (r *clinicInvoiceRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+
+	var invoice entities.ClinicInvoice
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invoice)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find clinic invoice")
+	}
+
+	return &invoice, nil
+}
+
+// Update updates an existing invoice
+func (r *clinicInvoiceRepository) Update(ctx context.Context, invoice *entities.ClinicInvoice) error {
+	invoice.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": invoice.ID}, invoice)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("an invoice with this number from the clinic already exists")
+		}
+		return errors.Wrap(err, 500, "failed to update clinic invoice")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes an invoice by ID
+func (r *clinicInvoiceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to delete clinic invoice")
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the invoices matching the filter
+func (r *clinicInvoiceRepository) List(ctx context.Context, filter *repositories.ClinicInvoiceFilter) ([]*entities.ClinicInvoice, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+
+	query := bson.M{}
+	if filter.PartnerID != nil {
+		query["partner_id"] = *filter.PartnerID
+	}
+	if filter.AnimalID != nil {
+		query["lines.animal_id"] = *filter.AnimalID
+	}
+	if filter.VisitID != nil {
+		query["lines.visit_id"] = *filter.VisitID
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.Unmatched != nil {
+		if *filter.Unmatched {
+			query["lines.match_status"] = bson.M{"$ne": entities.InvoiceMatchMatched}
+		} else {
+			query["lines"] = bson.M{"$not": bson.M{"$elemMatch": bson.M{"match_status": bson.M{"$ne": entities.InvoiceMatchMatched}}}}
+		}
+	}
+	if filter.From != nil || filter.To != nil {
+		dateFilter := bson.M{}
+		if filter.From != nil {
+			dateFilter["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			dateFilter["$lte"] = *filter.To
+		}
+		query["invoice_date"] = dateFilter
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count clinic invoices")
+	}
+
+	sortOrder := -1
+	if filter.SortOrder == "asc" {
+		sortOrder = 1
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "invoice_date", Value: sortOrder}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query clinic invoices")
+	}
+	defer cursor.Close(ctx)
+
+	var invoices []*entities.ClinicInvoice
+	if err := cursor.All(ctx, &invoices); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode clinic invoices")
+	}
+
+	return invoices, total, nil
+}
+
+// OutstandingByPartner returns the unpaid balance of each clinic, excluding void invoices
+func (r *clinicInvoiceRepository) OutstandingByPartner(ctx context.Context, asOf time.Time) ([]*repositories.ClinicBalance, error) {
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+
+	unpaid := bson.M{"$gt": bson.A{"$balance", 0}}
+	overdue := bson.M{"$and": bson.A{
+		unpaid,
+		bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$due_date", nil}}, nil}},
+		bson.M{"$lt": bson.A{"$due_date", asOf}},
+	}}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"status": bson.M{"$ne": entities.ClinicInvoiceStatusVoid}}},
+		{"$group": bson.M{
+			"_id":             "$partner_id",
+			"clinic_name":     bson.M{"$last": "$clinic_name"},
+			"invoice_count":   bson.M{"$sum": 1},
+			"unpaid_count":    bson.M{"$sum": bson.M{"$cond": bson.A{unpaid, 1, 0}}},
+			"total":           bson.M{"$sum": "$total"},
+			"paid":            bson.M{"$sum": "$amount_paid"},
+			"balance":         bson.M{"$sum": "$balance"},
+			"overdue_balance": bson.M{"$sum": bson.M{"$cond": bson.A{overdue, "$balance", 0}}},
+			"oldest_due_date": bson.M{"$min": bson.M{"$cond": bson.A{unpaid, "$due_date", nil}}},
+		}},
+		{"$sort": bson.M{"balance": -1}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to aggregate clinic balances")
+	}
+	defer cursor.Close(ctx)
+
+	var balances []*repositories.ClinicBalance
+	if err := cursor.All(ctx, &balances); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode clinic balances")
+	}
+
+	return balances, nil
+}
+
+// UpdateIfUnchanged replaces the invoice only if its stored update time is still the one
+// it was read with, so concurrent changes to its payments and charges are not lost
+func (r *clinicInvoiceRepository) UpdateIfUnchanged(ctx context.Context, invoice *entities.ClinicInvoice, updatedAt time.Time) (bool, error) {
+	invoice.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": invoice.ID, "updated_at": updatedAt}, invoice)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "failed to update clinic invoice")
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// medicalFundBalance is how much of a donation is charged to invoices, in cents so the
+// conditional increments compare exactly
+type medicalFundBalance struct {
+	DonationID   primitive.ObjectID `bson:"_id"`
+	ChargedCents int64              `bson:"charged_cents"`
+	UpdatedAt    time.Time          `bson:"updated_at"`
+}
+
+// ChargedByDonation returns how much of each donation has been charged to invoices
+func (r *clinicInvoiceRepository) ChargedByDonation(ctx context.Context, donationIDs []primitive.ObjectID) (map[primitive.ObjectID]float64, error) {
+	collection := r.db.Collection(mongodb.Collections.MedicalFundBalances)
+
+	charged := make(map[primitive.ObjectID]float64)
+	if len(donationIDs) == 0 {
+		return charged, nil
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": donationIDs}})
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to find medical fund balances")
+	}
+	defer cursor.Close(ctx)
+
+	var balances []medicalFundBalance
+	if err := cursor.All(ctx, &balances); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode medical fund balances")
+	}
+
+	for _, balance := range balances {
+		charged[balance.DonationID] = float64(balance.ChargedCents) / 100
+	}
+	return charged, nil
+}
+
+// ReserveFund increments the charged amount with a conditional update, so concurrent
+// charges cannot spend more of a donation than it holds. The balance is created on the
+// first charge; when it exists but has too little left the upsert collides with it.
+func (r *clinicInvoiceRepository) ReserveFund(ctx context.Context, donationID primitive.ObjectID, amount, fundAmount float64) (bool, error) {
+	cents, limit := toCents(amount), toCents(fundAmount)
+	if cents > limit {
+		return false, nil
+	}
+
+	collection := r.db.Collection(mongodb.Collections.MedicalFundBalances)
+	filter := bson.M{"_id": donationID, "charged_cents": bson.M{"$lte": limit - cents}}
+	update := bson.M{
+		"$inc": bson.M{"charged_cents": cents},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, 500, "failed to charge medical fund")
+	}
+
+	return true, nil
+}
+
+// ReleaseFund decrements the charged amount of a donation
+func (r *clinicInvoiceRepository) ReleaseFund(ctx context.Context, donationID primitive.ObjectID, amount float64) error {
+	collection := r.db.Collection(mongodb.Collections.MedicalFundBalances)
+	update := bson.M{
+		"$inc": bson.M{"charged_cents": -toCents(amount)},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": donationID}, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to release medical fund charge")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func (r *clinicInvoiceRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.ClinicInvoices)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "partner_id", Value: 1}, {Key: "invoice_number", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "invoice_date", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "lines.animal_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "lines.visit_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "fund_charges.donation_id", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
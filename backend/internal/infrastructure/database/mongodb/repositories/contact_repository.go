@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
@@ -93,6 +94,9 @@ func (r *contactRepository) List(ctx context.Context, filter domainRepo.ContactF
 			query["owner_id"] = ownerID
 		}
 	}
+	if filter.Email != "" {
+		query["email"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.Email) + "$", Options: "i"}
+	}
 	if filter.Search != "" {
 		regex := primitive.Regex{Pattern: filter.Search, Options: "i"}
 		query["$or"] = []bson.M{
@@ -133,6 +137,7 @@ func (r *contactRepository) EnsureIndexes(ctx context.Context) error {
 		{Keys: bson.D{{Key: "owner_id", Value: 1}}},
 		{Keys: bson.D{{Key: "status", Value: 1}}},
 		{Keys: bson.D{{Key: "type", Value: 1}}},
+		{Keys: bson.D{{Key: "email", Value: 1}}},
 		{Keys: bson.D{{Key: "updated_at", Value: -1}}},
 	}
 	_, err := r.collection().Indexes().CreateMany(ctx, indexes)
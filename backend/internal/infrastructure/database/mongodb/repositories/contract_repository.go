@@ -0,0 +1,305 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// contractTemplateRepository implements the ContractTemplateRepository interface
+type contractTemplateRepository struct {
+	db *mongodb.Database
+}
+
+// NewContractTemplateRepository creates a new contract template repository
+func NewContractTemplateRepository(db *mongodb.Database) repositories.ContractTemplateRepository {
+	return &contractTemplateRepository{db: db}
+}
+
+// Create creates a new template version
+func (r *contractTemplateRepository) Create(ctx context.Context, template *entities.ContractTemplate) error {
+	template.CreatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.ContractTemplates)
+	result, err := collection.InsertOne(ctx, template)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("this template version already exists")
+		}
+		return errors.Wrap(err, 500, "failed to create contract template")
+	}
+
+	template.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds a template version by ID
+func (r *contractTemplateRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.ContractTemplate, error) {
+	collection := r.db.Collection(mongodb.Collections.ContractTemplates)
+
+	var template entities.ContractTemplate
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find contract template")
+	}
+
+	return &template, nil
+}
+
+// FindLatest returns the newest template version in a language, or nil if there is none
+func (r *contractTemplateRepository) FindLatest(ctx context.Context, language string) (*entities.ContractTemplate, error) {
+	collection := r.db.Collection(mongodb.Collections.ContractTemplates)
+
+	var template entities.ContractTemplate
+	err := collection.FindOne(ctx, bson.M{"language": language},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, 500, "failed to find contract template")
+	}
+
+	return &template, nil
+}
+
+// List returns the template versions, newest first; an empty language lists all
+func (r *contractTemplateRepository) List(ctx context.Context, language string) ([]*entities.ContractTemplate, error) {
+	collection := r.db.Collection(mongodb.Collections.ContractTemplates)
+
+	query := bson.M{}
+	if language != "" {
+		query["language"] = language
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "language", Value: 1}, {Key: "version", Value: -1}})
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query contract templates")
+	}
+	defer cursor.Close(ctx)
+
+	var templates []*entities.ContractTemplate
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode contract templates")
+	}
+
+	return templates, nil
+}
+
+// EnsureIndexes creates necessary indexes for the contract templates collection
+func (r *contractTemplateRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.ContractTemplates)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "language", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true).SetName("language_version_unique"),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
+
+// contractRepository implements the ContractRepository interface
+type contractRepository struct {
+	db *mongodb.Database
+}
+
+// NewContractRepository creates a new contract repository
+func NewContractRepository(db *mongodb.Database) repositories.ContractRepository {
+	return &contractRepository{db: db}
+}
+
+// Create creates a new contract
+func (r *contractRepository) Create(ctx context.Context, contract *entities.Contract) error {
+	contract.CreatedAt = time.Now()
+	contract.UpdatedAt = time.Now()
+	if contract.ID.IsZero() {
+		contract.ID = primitive.NewObjectID()
+	}
+
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+	if _, err := collection.InsertOne(ctx, contract); err != nil {
+		return errors.Wrap(err, 500, "failed to create contract")
+	}
+
+	return nil
+}
+
+// FindByID finds a contract by ID
+func (r *contractRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Contract, error) {
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+
+	var contract entities.Contract
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&contract)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find contract")
+	}
+
+	return &contract, nil
+}
+
+// FindByTokenHash finds the pending contract with the signing token hash
+func (r *contractRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entities.Contract, error) {
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+
+	var contract entities.Contract
+	err := collection.FindOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"status":     entities.ContractStatusPending,
+	}).Decode(&contract)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find contract")
+	}
+
+	return &contract, nil
+}
+
+// Update updates an existing contract
+func (r *contractRepository) Update(ctx context.Context, contract *entities.Contract) error {
+	contract.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": contract.ID}, contract)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update contract")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateIfPending saves a contract only while it is still pending with the given signing token hash
+func (r *contractRepository) UpdateIfPending(ctx context.Context, contract *entities.Contract, tokenHash string) (bool, error) {
+	contract.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+	result, err := collection.ReplaceOne(ctx, bson.M{
+		"_id":        contract.ID,
+		"status":     entities.ContractStatusPending,
+		"token_hash": tokenHash,
+	}, contract)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "failed to update contract")
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// AddEvent appends an entry to the audit trail of a contract
+func (r *contractRepository) AddEvent(ctx context.Context, id primitive.ObjectID, event entities.ContractEvent) error {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"events": event},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update contract")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the contracts matching the filter, newest first
+func (r *contractRepository) List(ctx context.Context, filter *repositories.ContractFilter) ([]*entities.Contract, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+
+	query := bson.M{}
+	if filter.AdoptionID != nil {
+		query["adoption_id"] = *filter.AdoptionID
+	}
+	if filter.AnimalID != nil {
+		query["animal_id"] = *filter.AnimalID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count contracts")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query contracts")
+	}
+	defer cursor.Close(ctx)
+
+	var contracts []*entities.Contract
+	if err := cursor.All(ctx, &contracts); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode contracts")
+	}
+
+	return contracts, total, nil
+}
+
+// EnsureIndexes creates necessary indexes for the contracts collection
+func (r *contractRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.Contracts)
+
+	indexes := []mongo.IndexModel{
+		{
+			// Only pending contracts carry a signing token
+			Keys: bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": entities.ContractStatusPending}).
+				SetName("token_hash_pending_unique"),
+		},
+		{
+			Keys: bson.D{{Key: "adoption_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
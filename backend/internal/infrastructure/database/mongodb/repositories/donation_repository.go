@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
@@ -105,6 +107,10 @@ func (r *donationRepository) List(ctx context.Context, filter *repositories.Dona
 		query["campaign_id"] = *filter.CampaignID
 	}
 
+	if filter.EventID != nil {
+		query["event_id"] = *filter.EventID
+	}
+
 	if filter.Type != "" {
 		query["type"] = filter.Type
 	}
@@ -130,7 +136,9 @@ func (r *donationRepository) List(ctx context.Context, filter *repositories.Dona
 	}
 
 	if filter.Designation != "" {
-		query["designation"] = filter.Designation
+		// Designations are typed in by staff, so "Medical " matches "medical"
+		pattern := "^\\s*" + regexp.QuoteMeta(strings.TrimSpace(filter.Designation)) + "\\s*$"
+		query["designation"] = primitive.Regex{Pattern: pattern, Options: "i"}
 	}
 
 	if filter.IsRecurring != nil {
@@ -609,6 +617,10 @@ func (r *donationRepository) EnsureIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "campaign_id", Value: 1}},
 		},
+		{
+			Keys:    bson.D{{Key: "event_id", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 		{
 			Keys: bson.D{{Key: "type", Value: 1}},
 		},
@@ -47,6 +47,8 @@ func (r *eventAttendanceRepository) EnsureIndexes(ctx context.Context) error {
 			{Key: "volunteer_id", Value: 1},
 		}},
 		{Keys: bson.D{{Key: "registration_date", Value: -1}}},
+		{Keys: bson.D{{Key: "payment_due_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "feedback_token_hash", Value: 1}}, Options: options.Index().SetSparse(true)},
 		{Keys: bson.D{{Key: "created_at", Value: -1}}},
 	}
 
@@ -84,6 +86,22 @@ func (r *eventAttendanceRepository) Update(ctx context.Context, attendance *enti
 	return nil
 }
 
+// UpdateIfStatus saves a registration only while its stored status is one of the given ones,
+// so two requests changing the same registration cannot both succeed
+func (r *eventAttendanceRepository) UpdateIfStatus(ctx context.Context, attendance *entities.EventAttendance, statuses ...entities.AttendanceStatus) (bool, error) {
+	attendance.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": attendance.ID, "status": bson.M{"$in": statuses}}
+	update := bson.M{"$set": attendance}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to update event attendance")
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
 func (r *eventAttendanceRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 
@@ -114,6 +132,20 @@ func (r *eventAttendanceRepository) FindByID(ctx context.Context, id primitive.O
 	return &attendance, nil
 }
 
+// FindByFeedbackToken finds the registration with a feedback survey token hash
+func (r *eventAttendanceRepository) FindByFeedbackToken(ctx context.Context, tokenHash string) (*entities.EventAttendance, error) {
+	var attendance entities.EventAttendance
+	err := r.collection().FindOne(ctx, bson.M{"feedback_token_hash": tokenHash}).Decode(&attendance)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "Failed to find event attendance")
+	}
+
+	return &attendance, nil
+}
+
 func (r *eventAttendanceRepository) List(ctx context.Context, filter *repositories.EventAttendanceFilter) ([]*entities.EventAttendance, int64, error) {
 	query := bson.M{}
 
@@ -141,6 +173,14 @@ func (r *eventAttendanceRepository) List(ctx context.Context, filter *repositori
 		query["status"] = filter.Status
 	}
 
+	if filter.PaymentStatus != "" {
+		query["payment_status"] = filter.PaymentStatus
+	}
+
+	if filter.PaymentDueBefore != nil {
+		query["payment_due_at"] = bson.M{"$lt": *filter.PaymentDueBefore}
+	}
+
 	// Count total
 	total, err := r.collection().CountDocuments(ctx, query)
 	if err != nil {
@@ -306,3 +346,35 @@ func (r *eventAttendanceRepository) CountAttendeesByEvent(ctx context.Context, e
 
 	return count, nil
 }
+
+func (r *eventAttendanceRepository) CountCheckedInSeats(ctx context.Context, eventID primitive.ObjectID) (int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"event_id":      eventID,
+			"status":        entities.AttendanceStatusAttended,
+			"check_in_time": bson.M{"$ne": nil},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"seats": bson.M{"$sum": bson.M{"$add": bson.A{1, bson.M{"$ifNull": bson.A{"$number_of_guests", 0}}}}},
+		}}},
+	}
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, errors.Wrap(err, 500, "Failed to count checked-in attendees")
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Seats int `bson:"seats"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, errors.Wrap(err, 500, "Failed to decode checked-in attendees")
+	}
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	return result[0].Seats, nil
+}
@@ -38,6 +38,10 @@ func (r *eventRepository) EnsureIndexes(ctx context.Context) error {
 		{Keys: bson.D{{Key: "featured", Value: 1}}},
 		{Keys: bson.D{{Key: "organizer", Value: 1}}},
 		{Keys: bson.D{{Key: "campaign_id", Value: 1}}},
+		{Keys: bson.D{
+			{Key: "series_id", Value: 1},
+			{Key: "start_date", Value: 1},
+		}},
 		{Keys: bson.D{
 			{Key: "status", Value: 1},
 			{Key: "start_date", Value: 1},
@@ -73,8 +77,13 @@ func (r *eventRepository) Create(ctx context.Context, event *entities.Event) err
 func (r *eventRepository) Update(ctx context.Context, event *entities.Event) error {
 	event.UpdatedAt = time.Now()
 
+	fields, err := eventFields(event)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to update event")
+	}
+
 	filter := bson.M{"_id": event.ID}
-	update := bson.M{"$set": event}
+	update := bson.M{"$set": fields}
 
 	result, err := r.collection().UpdateOne(ctx, filter, update)
 	if err != nil {
@@ -88,6 +97,32 @@ func (r *eventRepository) Update(ctx context.Context, event *entities.Event) err
 	return nil
 }
 
+// eventFields returns the fields of an event to set on update. The seat and waitlist
+// counters are left out: registrations change them atomically and a stale copy of the
+// event must not overwrite them.
+func eventFields(event *entities.Event) (bson.M, error) {
+	raw, err := bson.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	delete(fields, "attendee_count")
+	if registration, ok := fields["registration"].(bson.M); ok {
+		delete(fields, "registration")
+		for key, value := range registration {
+			if key == "current_count" || key == "waitlist_count" {
+				continue
+			}
+			fields["registration."+key] = value
+		}
+	}
+	return fields, nil
+}
+
 func (r *eventRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 
@@ -137,6 +172,10 @@ func (r *eventRepository) List(ctx context.Context, filter *repositories.EventFi
 		query["featured"] = *filter.Featured
 	}
 
+	if filter.SeriesID != nil {
+		query["series_id"] = *filter.SeriesID
+	}
+
 	if filter.StartDate != nil {
 		query["start_date"] = bson.M{"$gte": *filter.StartDate}
 	}
@@ -386,6 +425,146 @@ func (r *eventRepository) UpdateEventStatistics(ctx context.Context, eventID pri
 	return nil
 }
 
+// UpdateOutcomes sets only the outcome fields, so it does not undo changes staff make to
+// the event while the outcomes are computed
+func (r *eventRepository) UpdateOutcomes(ctx context.Context, eventID primitive.ObjectID, fundsRaised float64, animalsAdopted int, volunteerHours float64, at time.Time) error {
+	filter := bson.M{"_id": eventID}
+	update := bson.M{
+		"$set": bson.M{
+			"funds_raised":        fundsRaised,
+			"animals_adopted":     animalsAdopted,
+			"volunteer_hours":     volunteerHours,
+			"outcomes_updated_at": at,
+		},
+	}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to update event outcomes")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// AddReminder pushes the reminder with a conditional update, so overlapping scheduler runs
+// cannot both claim it and a reschedule, which clears the reminders, is not undone
+func (r *eventRepository) AddReminder(ctx context.Context, eventID primitive.ObjectID, startDate time.Time, reminder entities.EventReminder) (bool, error) {
+	filter := bson.M{
+		"_id":                           eventID,
+		"start_date":                    startDate,
+		"reminders_sent.offset_minutes": bson.M{"$ne": reminder.OffsetMinutes},
+	}
+	update := bson.M{"$push": bson.M{"reminders_sent": reminder}}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to record event reminder")
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (r *eventRepository) SetReminderRecipients(ctx context.Context, eventID primitive.ObjectID, offsetMinutes, recipients int) error {
+	filter := bson.M{"_id": eventID, "reminders_sent.offset_minutes": offsetMinutes}
+	update := bson.M{"$set": bson.M{"reminders_sent.$.recipients": recipients}}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to update event reminder")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *eventRepository) MarkFeedbackRequested(ctx context.Context, eventID primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": eventID}
+	update := bson.M{"$set": bson.M{"feedback_requested_at": at}}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to update event")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// ReserveSeats takes seats with a conditional update, so concurrent registrations cannot
+// take more seats than the event has
+func (r *eventRepository) ReserveSeats(ctx context.Context, eventID primitive.ObjectID, seats int) (bool, error) {
+	filter := bson.M{
+		"_id": eventID,
+		"$expr": bson.M{"$or": bson.A{
+			bson.M{"$ne": bson.A{"$registration.required", true}},
+			bson.M{"$lte": bson.A{bson.M{"$ifNull": bson.A{"$registration.max_attendees", 0}}, 0}},
+			bson.M{"$lte": bson.A{
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$registration.current_count", 0}}, seats}},
+				"$registration.max_attendees",
+			}},
+		}},
+	}
+	update := bson.M{
+		"$inc": bson.M{"registration.current_count": seats},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, errors.Wrap(err, 500, "Failed to reserve event seats")
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *eventRepository) ReleaseSeats(ctx context.Context, eventID primitive.ObjectID, seats int) error {
+	filter := bson.M{"_id": eventID}
+	update := bson.M{
+		"$inc": bson.M{"registration.current_count": -seats},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to release event seats")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *eventRepository) AdjustWaitlist(ctx context.Context, eventID primitive.ObjectID, delta int) error {
+	filter := bson.M{"_id": eventID}
+	update := bson.M{
+		"$inc": bson.M{"registration.waitlist_count": delta},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection().UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to update event waitlist")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *eventRepository) GetEventStatistics(ctx context.Context) (*repositories.EventStatistics, error) {
 	stats := &repositories.EventStatistics{
 		ByType:   make(map[string]int64),
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type eventSeriesRepository struct {
+	db *mongodb.Database
+}
+
+// NewEventSeriesRepository creates a new event series repository
+func NewEventSeriesRepository(db *mongodb.Database) repositories.EventSeriesRepository {
+	return &eventSeriesRepository{db: db}
+}
+
+func (r *eventSeriesRepository) collection() *mongo.Collection {
+	return r.db.DB.Collection(mongodb.Collections.EventSeries)
+}
+
+// EnsureIndexes creates necessary indexes for the event series collection
+func (r *eventSeriesRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	}
+
+	_, err := r.collection().Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (r *eventSeriesRepository) Create(ctx context.Context, series *entities.EventSeries) error {
+	series.ID = primitive.NewObjectID()
+	series.CreatedAt = time.Now()
+	series.UpdatedAt = time.Now()
+
+	_, err := r.collection().InsertOne(ctx, series)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to create event series")
+	}
+	return nil
+}
+
+func (r *eventSeriesRepository) Update(ctx context.Context, series *entities.EventSeries) error {
+	series.UpdatedAt = time.Now()
+
+	result, err := r.collection().ReplaceOne(ctx, bson.M{"_id": series.ID}, series)
+	if err != nil {
+		return errors.Wrap(err, 500, "Failed to update event series")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *eventSeriesRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.EventSeries, error) {
+	var series entities.EventSeries
+
+	err := r.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&series)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "Failed to find event series")
+	}
+
+	return &series, nil
+}
+
+func (r *eventSeriesRepository) List(ctx context.Context, filter *repositories.EventSeriesFilter) ([]*entities.EventSeries, int64, error) {
+	query := bson.M{}
+
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	if filter.ParentID != nil {
+		query["parent_id"] = *filter.ParentID
+	}
+
+	if filter.Search != "" {
+		query["$or"] = []bson.M{
+			{"name.english": bson.M{"$regex": filter.Search, "$options": "i"}},
+			{"name.polish": bson.M{"$regex": filter.Search, "$options": "i"}},
+		}
+	}
+
+	total, err := r.collection().CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "Failed to count event series")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := r.collection().Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "Failed to list event series")
+	}
+	defer cursor.Close(ctx)
+
+	var series []*entities.EventSeries
+	if err := cursor.All(ctx, &series); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "Failed to decode event series")
+	}
+
+	return series, total, nil
+}
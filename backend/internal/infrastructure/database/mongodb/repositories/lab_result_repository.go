@@ -0,0 +1,321 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// labAnalyteRepository implements the LabAnalyteRepository interface
+type labAnalyteRepository struct {
+	db *mongodb.Database
+}
+
+// NewLabAnalyteRepository creates a new lab analyte repository
+func NewLabAnalyteRepository(db *mongodb.Database) repositories.LabAnalyteRepository {
+	return &labAnalyteRepository{db: db}
+}
+
+// Create creates a new analyte
+func (r *labAnalyteRepository) Create(ctx context.Context, analyte *entities.LabAnalyte) error {
+	analyte.CreatedAt = time.Now()
+	analyte.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.LabAnalytes)
+	result, err := collection.InsertOne(ctx, analyte)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("an analyte with this code already exists")
+		}
+		return errors.Wrap(err, 500, "failed to create lab analyte")
+	}
+
+	analyte.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds an analyte by ID
+func (r *labAnalyteRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.LabAnalyte, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByCode finds an analyte by its normalized code
+func (r *labAnalyteRepository) FindByCode(ctx context.Context, code string) (*entities.LabAnalyte, error) {
+	return r.findOne(ctx, bson.M{"code": entities.NormalizeAnalyteCode(code)})
+}
+
+func (r *labAnalyteRepository) findOne(ctx context.Context, query bson.M) (*entities.LabAnalyte, error) {
+	collection := r.db.Collection(mongodb.Collections.LabAnalytes)
+
+	var analyte entities.LabAnalyte
+	err := collection.FindOne(ctx, query).Decode(&analyte)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find lab analyte")
+	}
+
+	return &analyte, nil
+}
+
+// Update updates an existing analyte
+func (r *labAnalyteRepository) Update(ctx context.Context, analyte *entities.LabAnalyte) error {
+	analyte.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.LabAnalytes)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": analyte.ID}, analyte)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update lab analyte")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the analytes ordered by category and code
+func (r *labAnalyteRepository) List(ctx context.Context, activeOnly bool) ([]*entities.LabAnalyte, error) {
+	collection := r.db.Collection(mongodb.Collections.LabAnalytes)
+
+	query := bson.M{}
+	if activeOnly {
+		query["active"] = true
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "category", Value: 1}, {Key: "code", Value: 1}})
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query lab analytes")
+	}
+	defer cursor.Close(ctx)
+
+	var analytes []*entities.LabAnalyte
+	if err := cursor.All(ctx, &analytes); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode lab analytes")
+	}
+
+	return analytes, nil
+}
+
+// EnsureIndexes creates necessary indexes for the lab_analytes collection
+func (r *labAnalyteRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.LabAnalytes)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
+
+// labPanelRepository implements the LabPanelRepository interface
+type labPanelRepository struct {
+	db *mongodb.Database
+}
+
+// NewLabPanelRepository creates a new lab panel repository
+func NewLabPanelRepository(db *mongodb.Database) repositories.LabPanelRepository {
+	return &labPanelRepository{db: db}
+}
+
+// Create creates a new panel
+func (r *labPanelRepository) Create(ctx context.Context, panel *entities.LabPanel) error {
+	panel.CreatedAt = time.Now()
+	panel.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.LabPanels)
+	result, err := collection.InsertOne(ctx, panel)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create lab panel")
+	}
+
+	panel.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds a panel by ID
+func (r *labPanelRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.LabPanel, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// FindByAccession finds the panel of an animal with a lab accession number
+func (r *labPanelRepository) FindByAccession(ctx context.Context, animalID primitive.ObjectID, accessionNumber string) (*entities.LabPanel, error) {
+	return r.findOne(ctx, bson.M{"animal_id": animalID, "accession_number": accessionNumber})
+}
+
+func (r *labPanelRepository) findOne(ctx context.Context, query bson.M) (*entities.LabPanel, error) {
+	collection := r.db.Collection(mongodb.Collections.LabPanels)
+
+	var panel entities.LabPanel
+	err := collection.FindOne(ctx, query).Decode(&panel)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find lab panel")
+	}
+
+	return &panel, nil
+}
+
+// Update updates an existing panel
+func (r *labPanelRepository) Update(ctx context.Context, panel *entities.LabPanel) error {
+	panel.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.LabPanels)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": panel.ID}, panel)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update lab panel")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a panel by ID
+func (r *labPanelRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	collection := r.db.Collection(mongodb.Collections.LabPanels)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to delete lab panel")
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the panels matching the filter
+func (r *labPanelRepository) List(ctx context.Context, filter *repositories.LabPanelFilter) ([]*entities.LabPanel, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.LabPanels)
+
+	query := bson.M{}
+	if filter.AnimalID != nil {
+		query["animal_id"] = *filter.AnimalID
+	}
+	if filter.VisitID != nil {
+		query["visit_id"] = *filter.VisitID
+	}
+	if filter.PartnerID != nil {
+		query["partner_id"] = *filter.PartnerID
+	}
+	if len(filter.AnalyteCodes) > 0 {
+		codes := make([]string, len(filter.AnalyteCodes))
+		for i, code := range filter.AnalyteCodes {
+			codes[i] = entities.NormalizeAnalyteCode(code)
+		}
+		query["results.analyte_code"] = bson.M{"$in": codes}
+	}
+	if filter.Abnormal != nil {
+		if *filter.Abnormal {
+			query["abnormal_count"] = bson.M{"$gt": 0}
+		} else {
+			query["abnormal_count"] = 0
+		}
+	}
+	if filter.Critical != nil {
+		query["has_critical"] = *filter.Critical
+	}
+	if filter.From != nil || filter.To != nil {
+		collectedAt := bson.M{}
+		if filter.From != nil {
+			collectedAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			collectedAt["$lte"] = *filter.To
+		}
+		query["collected_at"] = collectedAt
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count lab panels")
+	}
+
+	sortOrder := -1
+	if filter.SortOrder == "asc" {
+		sortOrder = 1
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "collected_at", Value: sortOrder}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query lab panels")
+	}
+	defer cursor.Close(ctx)
+
+	var panels []*entities.LabPanel
+	if err := cursor.All(ctx, &panels); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode lab panels")
+	}
+
+	return panels, total, nil
+}
+
+// EnsureIndexes creates necessary indexes for the lab_panels collection
+func (r *labPanelRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.LabPanels)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "animal_id", Value: 1},
+				{Key: "collected_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "animal_id", Value: 1},
+				{Key: "results.analyte_code", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "visit_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{
+				{Key: "animal_id", Value: 1},
+				{Key: "accession_number", Value: 1},
+			},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type medicationDoseRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMedicationDoseRepository creates a new medication dose repository
+func NewMedicationDoseRepository(db *mongodb.Database) repositories.MedicationDoseRepository {
+	return &medicationDoseRepository{
+		collection: db.Collection("medication_doses"),
+	}
+}
+
+func (r *medicationDoseRepository) Create(ctx context.Context, dose *entities.MedicationDose) error {
+	dose.CreatedAt = time.Now()
+	dose.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, dose)
+	if err != nil {
+		return errors.ErrInternalServer
+	}
+
+	dose.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *medicationDoseRepository) CreateScheduled(ctx context.Context, doses []*entities.MedicationDose) error {
+	if len(doses) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(doses))
+	for _, dose := range doses {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"medication_id": dose.MedicationID, "scheduled_for": dose.ScheduledFor}).
+			SetUpdate(bson.M{"$setOnInsert": dose}).
+			SetUpsert(true))
+	}
+
+	if _, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return errors.ErrInternalServer
+	}
+
+	return nil
+}
+
+func (r *medicationDoseRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.MedicationDose, error) {
+	var dose entities.MedicationDose
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&dose)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.ErrInternalServer
+	}
+
+	return &dose, nil
+}
+
+func (r *medicationDoseRepository) Update(ctx context.Context, dose *entities.MedicationDose) error {
+	dose.UpdatedAt = time.Now()
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": dose.ID}, dose)
+	if err != nil {
+		return errors.ErrInternalServer
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *medicationDoseRepository) List(ctx context.Context, filter repositories.MedicationDoseFilter) ([]*entities.MedicationDose, error) {
+	query := bson.M{}
+
+	if filter.AnimalID != nil {
+		query["animal_id"] = *filter.AnimalID
+	}
+
+	if filter.MedicationID != nil {
+		query["medication_id"] = *filter.MedicationID
+	}
+
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+
+	if filter.Shift != "" {
+		query["shift"] = filter.Shift
+	}
+
+	if filter.From != nil || filter.To != nil {
+		dateFilter := bson.M{}
+		if filter.From != nil {
+			dateFilter["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			dateFilter["$lt"] = *filter.To
+		}
+		query["scheduled_for"] = dateFilter
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "scheduled_for", Value: 1}}))
+	if err != nil {
+		return nil, errors.ErrInternalServer
+	}
+	defer cursor.Close(ctx)
+
+	var doses []*entities.MedicationDose
+	if err = cursor.All(ctx, &doses); err != nil {
+		return nil, errors.ErrInternalServer
+	}
+
+	return doses, nil
+}
+
+func (r *medicationDoseRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.ErrInternalServer
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *medicationDoseRepository) DeleteScheduled(ctx context.Context, medicationID primitive.ObjectID, from time.Time) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{
+		"medication_id": medicationID,
+		"status":        entities.DoseStatusScheduled,
+		"scheduled_for": bson.M{"$gte": from},
+	})
+	if err != nil {
+		return errors.ErrInternalServer
+	}
+
+	return nil
+}
+
+func (r *medicationDoseRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "medication_id", Value: 1}, {Key: "scheduled_for", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "scheduled_for", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "animal_id", Value: 1}, {Key: "scheduled_for", Value: -1}},
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
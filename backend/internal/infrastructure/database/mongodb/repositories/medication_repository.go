@@ -279,6 +279,50 @@ func (r *medicationRepository) AddAdministrationLog(ctx context.Context, medicat
 	return nil
 }
 
+func (r *medicationRepository) SetAdministrationWitness(ctx context.Context, medicationID, doseID, witnessID primitive.ObjectID, witnessedAt time.Time) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": medicationID, "administration_logs.dose_id": doseID},
+		bson.M{
+			"$set": bson.M{
+				"administration_logs.$.witnessed_by": witnessID,
+				"administration_logs.$.witnessed_at": witnessedAt,
+				"updated_at":                         time.Now(),
+			},
+		},
+	)
+	if err != nil {
+		return errors.ErrInternalServer
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *medicationRepository) FindScheduledActive(ctx context.Context) ([]*entities.Medication, error) {
+	cursor, err := r.collection.Find(
+		ctx,
+		bson.M{
+			"status":        entities.MedicationStatusActive,
+			"schedule.type": bson.M{"$exists": true, "$ne": entities.DoseSchedulePRN},
+		},
+	)
+	if err != nil {
+		return nil, errors.ErrInternalServer
+	}
+	defer cursor.Close(ctx)
+
+	var medications []*entities.Medication
+	if err = cursor.All(ctx, &medications); err != nil {
+		return nil, errors.ErrInternalServer
+	}
+
+	return medications, nil
+}
+
 func (r *medicationRepository) EnsureIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
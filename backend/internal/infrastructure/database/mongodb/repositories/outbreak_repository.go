@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outbreakRepository implements the OutbreakRepository interface
+type outbreakRepository struct {
+	db *mongodb.Database
+}
+
+// NewOutbreakRepository creates a new outbreak repository
+func NewOutbreakRepository(db *mongodb.Database) repositories.OutbreakRepository {
+	return &outbreakRepository{db: db}
+}
+
+// Create creates a new outbreak
+func (r *outbreakRepository) Create(ctx context.Context, outbreak *entities.Outbreak) error {
+	outbreak.CreatedAt = time.Now()
+	outbreak.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Outbreaks)
+	result, err := collection.InsertOne(ctx, outbreak)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create outbreak")
+	}
+
+	outbreak.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds an outbreak by ID
+func (r *outbreakRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Outbreak, error) {
+	collection := r.db.Collection(mongodb.Collections.Outbreaks)
+
+	var outbreak entities.Outbreak
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&outbreak)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find outbreak")
+	}
+
+	return &outbreak, nil
+}
+
+// Update updates an existing outbreak
+func (r *outbreakRepository) Update(ctx context.Context, outbreak *entities.Outbreak) error {
+	outbreak.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Outbreaks)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": outbreak.ID}, outbreak)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update outbreak")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the outbreaks matching the filter, most recent first
+func (r *outbreakRepository) List(ctx context.Context, filter *repositories.OutbreakFilter) ([]*entities.Outbreak, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.Outbreaks)
+
+	query := bson.M{}
+	if filter.Disease != "" {
+		query["disease"] = filter.Disease
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.AnimalID != nil {
+		query["$or"] = []bson.M{
+			{"cases.animal_id": *filter.AnimalID},
+			{"exposures.animal_id": *filter.AnimalID},
+		}
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count outbreaks")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "declared_at", Value: -1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query outbreaks")
+	}
+	defer cursor.Close(ctx)
+
+	var outbreaks []*entities.Outbreak
+	if err := cursor.All(ctx, &outbreaks); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode outbreaks")
+	}
+
+	return outbreaks, total, nil
+}
+
+// EnsureIndexes creates necessary indexes for the outbreaks collection
+func (r *outbreakRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.Outbreaks)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "declared_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "cases.animal_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "exposures.animal_id", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// quarantineRepository implements the QuarantineRepository interface
+type quarantineRepository struct {
+	db *mongodb.Database
+}
+
+// NewQuarantineRepository creates a new quarantine repository
+func NewQuarantineRepository(db *mongodb.Database) repositories.QuarantineRepository {
+	return &quarantineRepository{db: db}
+}
+
+// Create creates a new quarantine
+func (r *quarantineRepository) Create(ctx context.Context, quarantine *entities.Quarantine) error {
+	quarantine.CreatedAt = time.Now()
+	quarantine.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Quarantines)
+	result, err := collection.InsertOne(ctx, quarantine)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.NewConflict("animal is already in quarantine")
+		}
+		return errors.Wrap(err, 500, "failed to create quarantine")
+	}
+
+	quarantine.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds a quarantine by ID
+func (r *quarantineRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Quarantine, error) {
+	collection := r.db.Collection(mongodb.Collections.Quarantines)
+
+	var quarantine entities.Quarantine
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&quarantine)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find quarantine")
+	}
+
+	return &quarantine, nil
+}
+
+// FindActiveByAnimal returns the active quarantine of an animal, or nil if there is none
+func (r *quarantineRepository) FindActiveByAnimal(ctx context.Context, animalID primitive.ObjectID) (*entities.Quarantine, error) {
+	collection := r.db.Collection(mongodb.Collections.Quarantines)
+
+	var quarantine entities.Quarantine
+	err := collection.FindOne(ctx, bson.M{
+		"animal_id": animalID,
+		"status":    entities.QuarantineStatusActive,
+	}).Decode(&quarantine)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, 500, "failed to find active quarantine")
+	}
+
+	return &quarantine, nil
+}
+
+// Update updates an existing quarantine
+func (r *quarantineRepository) Update(ctx context.Context, quarantine *entities.Quarantine) error {
+	quarantine.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.Quarantines)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": quarantine.ID}, quarantine)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update quarantine")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the quarantines matching the filter
+func (r *quarantineRepository) List(ctx context.Context, filter *repositories.QuarantineFilter) ([]*entities.Quarantine, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.Quarantines)
+
+	query := bson.M{}
+	if filter.AnimalID != nil {
+		query["animal_id"] = *filter.AnimalID
+	}
+	if filter.OutbreakID != nil {
+		query["outbreak_id"] = *filter.OutbreakID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Reason != "" {
+		query["reason"] = filter.Reason
+	}
+	if filter.EndsBefore != nil {
+		query["end_date"] = bson.M{"$lte": *filter.EndsBefore}
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count quarantines")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "end_date", Value: 1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query quarantines")
+	}
+	defer cursor.Close(ctx)
+
+	var quarantines []*entities.Quarantine
+	if err := cursor.All(ctx, &quarantines); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode quarantines")
+	}
+
+	return quarantines, total, nil
+}
+
+// EnsureIndexes creates necessary indexes for the quarantines collection
+func (r *quarantineRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.Quarantines)
+
+	indexes := []mongo.IndexModel{
+		{
+			// An animal can only be in one quarantine at a time
+			Keys: bson.D{{Key: "animal_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": entities.QuarantineStatusActive}).
+				SetName("animal_id_active_unique"),
+		},
+		{
+			Keys: bson.D{{Key: "animal_id", Value: 1}, {Key: "start_date", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "end_date", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "outbreak_id", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
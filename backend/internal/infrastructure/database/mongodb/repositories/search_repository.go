@@ -0,0 +1,526 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchTextIndexName is the name of the text index created on every searchable collection
+const searchTextIndexName = "search_text"
+
+// searchSource describes how one collection takes part in the global search
+type searchSource struct {
+	entityType  entities.SearchEntityType
+	collection  string
+	textWeights bson.D   // Fields of the text index with their weights; nil when the collection already has one
+	nameFields  []string // Prefix-matched at the start of any word
+	emailFields []string // Prefix-matched at the start of the value
+	phoneFields []string // Matched on digits, ignoring separators
+	toHit       func(doc bson.M) *entities.SearchHit
+}
+
+// searchSources lists the searchable collections
+var searchSources = []searchSource{
+	{
+		entityType: entities.SearchEntityAnimal,
+		collection: mongodb.Collections.Animals,
+		// The animals collection already has a text index on names, descriptions and breed
+		nameFields: []string{"name.en", "name.pl", "breed"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			return &entities.SearchHit{
+				Title:    firstNonEmpty(docString(doc, "name.en"), docString(doc, "name.pl")),
+				Subtitle: joinNonEmpty(" · ", docString(doc, "species"), docString(doc, "breed")),
+				Status:   docString(doc, "status"),
+				Tags:     nonEmpty(docString(doc, "category")),
+				Names:    nonEmpty(docString(doc, "name.en"), docString(doc, "name.pl"), docString(doc, "breed")),
+			}
+		},
+	},
+	{
+		entityType: entities.SearchEntityDonor,
+		collection: mongodb.Collections.Donors,
+		textWeights: bson.D{
+			{Key: "first_name", Value: 10},
+			{Key: "last_name", Value: 10},
+			{Key: "organization_name", Value: 10},
+			{Key: "contact_person", Value: 5},
+			{Key: "contact.email", Value: 5},
+			{Key: "tags", Value: 3},
+			{Key: "notes", Value: 1},
+		},
+		nameFields:  []string{"first_name", "last_name", "organization_name", "contact_person"},
+		emailFields: []string{"contact.email"},
+		phoneFields: []string{"contact.phone", "contact.alternate_phone"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			name := joinNonEmpty(" ", docString(doc, "first_name"), docString(doc, "last_name"))
+			return &entities.SearchHit{
+				Title:    firstNonEmpty(docString(doc, "organization_name"), name),
+				Subtitle: joinNonEmpty(" · ", docString(doc, "type"), docString(doc, "contact.email")),
+				Status:   docString(doc, "status"),
+				Tags:     docStrings(doc, "tags"),
+				Names:    nonEmpty(docString(doc, "first_name"), docString(doc, "last_name"), docString(doc, "organization_name"), docString(doc, "contact_person")),
+				Emails:   nonEmpty(docString(doc, "contact.email")),
+				Phones:   nonEmpty(docString(doc, "contact.phone"), docString(doc, "contact.alternate_phone")),
+			}
+		},
+	},
+	{
+		entityType: entities.SearchEntityVolunteer,
+		collection: mongodb.Collections.Volunteers,
+		textWeights: bson.D{
+			{Key: "first_name", Value: 10},
+			{Key: "last_name", Value: 10},
+			{Key: "email", Value: 5},
+			{Key: "skills.name", Value: 3},
+			{Key: "notes", Value: 1},
+		},
+		nameFields:  []string{"first_name", "last_name"},
+		emailFields: []string{"email"},
+		phoneFields: []string{"phone"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			return &entities.SearchHit{
+				Title:    joinNonEmpty(" ", docString(doc, "first_name"), docString(doc, "last_name")),
+				Subtitle: docString(doc, "email"),
+				Status:   docString(doc, "status"),
+				Tags:     docStrings(doc, "skills.name"),
+				Names:    nonEmpty(docString(doc, "first_name"), docString(doc, "last_name")),
+				Emails:   nonEmpty(docString(doc, "email")),
+				Phones:   nonEmpty(docString(doc, "phone")),
+			}
+		},
+	},
+	{
+		entityType: entities.SearchEntityContact,
+		collection: mongodb.Collections.Contacts,
+		textWeights: bson.D{
+			{Key: "first_name", Value: 10},
+			{Key: "last_name", Value: 10},
+			{Key: "organization", Value: 8},
+			{Key: "email", Value: 5},
+			{Key: "tags", Value: 3},
+			{Key: "notes", Value: 1},
+		},
+		nameFields:  []string{"first_name", "last_name", "organization"},
+		emailFields: []string{"email"},
+		phoneFields: []string{"phone"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			return &entities.SearchHit{
+				Title:    joinNonEmpty(" ", docString(doc, "first_name"), docString(doc, "last_name")),
+				Subtitle: joinNonEmpty(" · ", docString(doc, "type"), docString(doc, "organization")),
+				Status:   docString(doc, "status"),
+				Tags:     docStrings(doc, "tags"),
+				Names:    nonEmpty(docString(doc, "first_name"), docString(doc, "last_name"), docString(doc, "organization")),
+				Emails:   nonEmpty(docString(doc, "email")),
+				Phones:   nonEmpty(docString(doc, "phone")),
+			}
+		},
+	},
+	{
+		entityType: entities.SearchEntityPartner,
+		collection: mongodb.Collections.Partners,
+		textWeights: bson.D{
+			{Key: "name", Value: 10},
+			{Key: "legal_name", Value: 8},
+			{Key: "primary_contact.name", Value: 6},
+			{Key: "contact_info.email", Value: 5},
+			{Key: "services_provided", Value: 3},
+			{Key: "tags", Value: 3},
+			{Key: "notes", Value: 1},
+		},
+		nameFields:  []string{"name", "legal_name", "primary_contact.name"},
+		emailFields: []string{"contact_info.email", "primary_contact.email"},
+		phoneFields: []string{"contact_info.phone", "contact_info.mobile", "primary_contact.phone", "primary_contact.mobile"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			return &entities.SearchHit{
+				Title:    docString(doc, "name"),
+				Subtitle: joinNonEmpty(" · ", docString(doc, "type"), docString(doc, "primary_contact.name")),
+				Status:   docString(doc, "status"),
+				Tags:     docStrings(doc, "tags"),
+				Names:    nonEmpty(docString(doc, "name"), docString(doc, "legal_name"), docString(doc, "primary_contact.name")),
+				Emails:   nonEmpty(docString(doc, "contact_info.email"), docString(doc, "primary_contact.email")),
+				Phones:   nonEmpty(docString(doc, "contact_info.phone"), docString(doc, "contact_info.mobile"), docString(doc, "primary_contact.phone"), docString(doc, "primary_contact.mobile")),
+			}
+		},
+	},
+	{
+		entityType: entities.SearchEntityDocument,
+		collection: mongodb.Collections.Documents,
+		textWeights: bson.D{
+			{Key: "title", Value: 10},
+			{Key: "file_name", Value: 5},
+			{Key: "tags", Value: 3},
+			{Key: "description", Value: 1},
+		},
+		nameFields: []string{"title", "file_name"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			status := "active"
+			if archived, _ := doc["is_archived"].(bool); archived {
+				status = "archived"
+			}
+			return &entities.SearchHit{
+				Title:    docString(doc, "title"),
+				Subtitle: joinNonEmpty(" · ", docString(doc, "type"), docString(doc, "file_name")),
+				Status:   status,
+				Tags:     docStrings(doc, "tags"),
+				Names:    nonEmpty(docString(doc, "title"), docString(doc, "file_name")),
+			}
+		},
+	},
+	{
+		entityType: entities.SearchEntityEvent,
+		collection: mongodb.Collections.Events,
+		textWeights: bson.D{
+			{Key: "name.en", Value: 10},
+			{Key: "name.pl", Value: 10},
+			{Key: "location.name", Value: 4},
+			{Key: "location.city", Value: 4},
+			{Key: "tags", Value: 3},
+			{Key: "description.en", Value: 1},
+			{Key: "description.pl", Value: 1},
+		},
+		nameFields: []string{"name.en", "name.pl", "location.name", "location.city"},
+		toHit: func(doc bson.M) *entities.SearchHit {
+			subtitle := docString(doc, "location.name")
+			if start, ok := doc["start_date"].(primitive.DateTime); ok {
+				subtitle = joinNonEmpty(" · ", start.Time().Format("2006-01-02"), subtitle)
+			}
+			return &entities.SearchHit{
+				Title:    firstNonEmpty(docString(doc, "name.en"), docString(doc, "name.pl")),
+				Subtitle: subtitle,
+				Status:   docString(doc, "status"),
+				Tags:     docStrings(doc, "tags"),
+				Names:    nonEmpty(docString(doc, "name.en"), docString(doc, "name.pl"), docString(doc, "location.name"), docString(doc, "location.city")),
+			}
+		},
+	},
+}
+
+type searchRepository struct {
+	db *mongodb.Database
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *mongodb.Database) repositories.SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// Search returns the text and prefix matches of every requested entity type
+func (r *searchRepository) Search(ctx context.Context, query *repositories.SearchQuery) ([]*entities.SearchHit, error) {
+	allowed := make(map[entities.SearchEntityType]bool, len(query.Types))
+	for _, t := range query.Types {
+		allowed[t] = true
+	}
+
+	var hits []*entities.SearchHit
+	for _, source := range searchSources {
+		if !allowed[source.entityType] {
+			continue
+		}
+
+		sourceHits, err := r.searchSource(ctx, source, query)
+		if err != nil {
+			return nil, fmt.Errorf("search %s: %w", source.collection, err)
+		}
+		hits = append(hits, sourceHits...)
+	}
+
+	return hits, nil
+}
+
+// searchSource runs the text query and the prefix query on one collection and merges the results
+func (r *searchRepository) searchSource(ctx context.Context, source searchSource, query *repositories.SearchQuery) ([]*entities.SearchHit, error) {
+	collection := r.db.DB.Collection(source.collection)
+	base := r.accessFilter(source, query)
+	merged := make(map[primitive.ObjectID]*entities.SearchHit)
+	var order []primitive.ObjectID
+
+	collect := func(cursor *mongo.Cursor) error {
+		defer cursor.Close(ctx)
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				return err
+			}
+
+			id, ok := doc["_id"].(primitive.ObjectID)
+			if !ok {
+				continue
+			}
+
+			score, _ := doc["score"].(float64)
+			if existing, found := merged[id]; found {
+				if score > existing.TextScore {
+					existing.TextScore = score
+				}
+				continue
+			}
+
+			hit := source.toHit(doc)
+			hit.Type = source.entityType
+			hit.ID = id
+			hit.TextScore = score
+			if updated, ok := doc["updated_at"].(primitive.DateTime); ok {
+				t := updated.Time()
+				hit.UpdatedAt = &t
+			}
+
+			merged[id] = hit
+			order = append(order, id)
+		}
+		return cursor.Err()
+	}
+
+	// Full-text match, ranked by MongoDB
+	if strings.TrimSpace(query.Text) != "" {
+		filter := andFilters(base, bson.M{"$text": bson.M{"$search": query.Text}})
+		opts := options.Find().
+			SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+			SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+			SetLimit(query.Limit)
+
+		cursor, err := collection.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := collect(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Prefix match on names, emails and phone numbers; typos are scored by the caller
+	if prefix := prefixFilter(source, query); prefix != nil {
+		cursor, err := collection.Find(ctx, andFilters(base, prefix), options.Find().SetLimit(query.Limit))
+		if err != nil {
+			return nil, err
+		}
+		if err := collect(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	hits := make([]*entities.SearchHit, 0, len(order))
+	for _, id := range order {
+		hits = append(hits, merged[id])
+	}
+
+	return hits, nil
+}
+
+// accessFilter restricts documents to the ones the caller may open
+func (r *searchRepository) accessFilter(source searchSource, query *repositories.SearchQuery) bson.M {
+	if source.entityType != entities.SearchEntityDocument {
+		return nil
+	}
+
+	return bson.M{
+		"$or": []bson.M{
+			{"is_public": true, "is_confidential": bson.M{"$ne": true}},
+			{"uploaded_by": query.UserID},
+			{"accessible_by": query.UserID},
+		},
+	}
+}
+
+// prefixFilter matches the query terms at the start of names and emails, and the digits in phone numbers
+func prefixFilter(source searchSource, query *repositories.SearchQuery) bson.M {
+	var clauses []bson.M
+
+	for _, term := range query.Terms {
+		// Keep the first letters exact and leave the rest to the typo-tolerant scoring
+		prefix := []rune(term)
+		if len(prefix) > 3 {
+			prefix = prefix[:3]
+		}
+		if len(prefix) < 2 {
+			continue
+		}
+
+		pattern := foldedPattern(string(prefix))
+		for _, field := range source.nameFields {
+			clauses = append(clauses, bson.M{field: primitive.Regex{Pattern: `(^|[\s\-'])` + pattern, Options: "i"}})
+		}
+		for _, field := range source.emailFields {
+			clauses = append(clauses, bson.M{field: primitive.Regex{Pattern: "^" + pattern, Options: "i"}})
+		}
+	}
+
+	if len(query.Phone) >= 3 {
+		digits := strings.Split(query.Phone, "")
+		pattern := strings.Join(digits, `\D*`)
+		for _, field := range source.phoneFields {
+			clauses = append(clauses, bson.M{field: primitive.Regex{Pattern: pattern}})
+		}
+	}
+
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	return bson.M{"$or": clauses}
+}
+
+// foldedVariants lists the accented letters a plain letter also matches
+var foldedVariants = map[rune]string{
+	'a': "aąáàäâ",
+	'c': "cćč",
+	'e': "eęéèëě",
+	'i': "iíî",
+	'l': "lł",
+	'n': "nńñň",
+	'o': "oóöôø",
+	's': "sśš",
+	'u': "uúüů",
+	'y': "yý",
+	'z': "zźżž",
+}
+
+// foldedPattern builds a regex matching the folded text with or without diacritics
+func foldedPattern(folded string) string {
+	var b strings.Builder
+	for _, r := range folded {
+		variants, ok := foldedVariants[r]
+		if !ok {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+		b.WriteString("[" + variants + strings.ToUpper(variants) + "]")
+	}
+	return b.String()
+}
+
+// EnsureIndexes creates the weighted text index of every searchable collection
+func (r *searchRepository) EnsureIndexes(ctx context.Context) error {
+	for _, source := range searchSources {
+		if source.textWeights == nil {
+			continue
+		}
+
+		keys := make(bson.D, 0, len(source.textWeights))
+		for _, field := range source.textWeights {
+			keys = append(keys, bson.E{Key: field.Key, Value: "text"})
+		}
+
+		index := mongo.IndexModel{
+			Keys: keys,
+			Options: options.Index().
+				SetName(searchTextIndexName).
+				SetWeights(source.textWeights).
+				// Names and notes are Polish and English, so skip language-specific stemming
+				SetDefaultLanguage("none"),
+		}
+
+		if _, err := r.db.DB.Collection(source.collection).Indexes().CreateOne(ctx, index); err != nil {
+			return fmt.Errorf("create search index on %s: %w", source.collection, err)
+		}
+	}
+
+	return nil
+}
+
+// andFilters combines filters, skipping empty ones
+func andFilters(filters ...bson.M) bson.M {
+	var parts []bson.M
+	for _, f := range filters {
+		if len(f) > 0 {
+			parts = append(parts, f)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return parts[0]
+	default:
+		return bson.M{"$and": parts}
+	}
+}
+
+// docValue follows a dotted path through nested documents
+func docValue(doc bson.M, path string) interface{} {
+	var current interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case bson.M:
+			current = v[key]
+		case bson.D:
+			current = nil
+			for _, elem := range v {
+				if elem.Key == key {
+					current = elem.Value
+					break
+				}
+			}
+		case primitive.A:
+			// Collect the key from every element of an array of documents
+			values := primitive.A{}
+			for _, item := range v {
+				if m, ok := item.(bson.M); ok {
+					values = append(values, m[key])
+				}
+			}
+			current = values
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// docString returns the string at the path, or an empty string
+func docString(doc bson.M, path string) string {
+	s, _ := docValue(doc, path).(string)
+	return s
+}
+
+// docStrings returns the strings at the path
+func docStrings(doc bson.M, path string) []string {
+	values, ok := docValue(doc, path).(primitive.A)
+	if !ok {
+		return nil
+	}
+
+	var out []string
+	for _, v := range values {
+		if s, ok := v.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// joinNonEmpty joins the non-empty values
+func joinNonEmpty(sep string, values ...string) string {
+	return strings.Join(nonEmpty(values...), sep)
+}
+
+// nonEmpty drops empty values
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -80,6 +80,10 @@ func (r *stockTransactionRepository) List(ctx context.Context, filter *repositor
 		query["related_entity_id"] = filter.RelatedEntityID
 	}
 
+	if filter.AnimalID != nil {
+		query["animal_id"] = filter.AnimalID
+	}
+
 	// Date filters
 	if filter.DateFrom != "" || filter.DateTo != "" {
 		dateQuery := bson.M{}
@@ -314,6 +318,62 @@ func (r *stockTransactionRepository) GetTransactionStatistics(ctx context.Contex
 	return stats, nil
 }
 
+// GetAnimalCostOfCare sums the stock consumed for an animal per inventory item
+func (r *stockTransactionRepository) GetAnimalCostOfCare(ctx context.Context, animalID primitive.ObjectID, from, to *time.Time) ([]*repositories.CostOfCareItem, error) {
+	match := bson.M{
+		"animal_id": animalID,
+		"type":      entities.TransactionTypeOut,
+	}
+	if from != nil || to != nil {
+		processedAt := bson.M{}
+		if from != nil {
+			processedAt["$gte"] = *from
+		}
+		if to != nil {
+			processedAt["$lte"] = *to
+		}
+		match["processed_at"] = processedAt
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$item_id",
+			"quantity":     bson.M{"$sum": "$quantity"},
+			"total_cost":   bson.M{"$sum": "$total_cost"},
+			"transactions": bson.M{"$sum": 1},
+			"last_used_at": bson.M{"$max": "$processed_at"},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "inventory_items",
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "item",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$item", "preserveNullAndEmptyArrays": true}}},
+		{{Key: "$addFields", Value: bson.M{
+			"item_name": "$item.name",
+			"category":  "$item.category",
+			"unit":      "$item.unit",
+		}}},
+		{{Key: "$project", Value: bson.M{"item": 0}}},
+		{{Key: "$sort", Value: bson.M{"total_cost": -1}}},
+	}
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*repositories.CostOfCareItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
 // EnsureIndexes creates the necessary indexes for the stock transactions collection
 func (r *stockTransactionRepository) EnsureIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
@@ -332,6 +392,9 @@ func (r *stockTransactionRepository) EnsureIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "related_entity", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "animal_id", Value: 1}, {Key: "processed_at", Value: -1}},
+		},
 		{
 			Keys: bson.D{{Key: "created_at", Value: -1}},
 		},
@@ -0,0 +1,320 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// vaccinationProtocolRepository implements the VaccinationProtocolRepository interface
+type vaccinationProtocolRepository struct {
+	db *mongodb.Database
+}
+
+// NewVaccinationProtocolRepository creates a new vaccination protocol repository
+func NewVaccinationProtocolRepository(db *mongodb.Database) repositories.VaccinationProtocolRepository {
+	return &vaccinationProtocolRepository{db: db}
+}
+
+// Create creates a new protocol
+func (r *vaccinationProtocolRepository) Create(ctx context.Context, protocol *entities.VaccinationProtocol) error {
+	protocol.CreatedAt = time.Now()
+	protocol.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.VaccinationProtocols)
+	result, err := collection.InsertOne(ctx, protocol)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create vaccination protocol")
+	}
+
+	protocol.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds a protocol by ID
+func (r *vaccinationProtocolRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	collection := r.db.Collection(mongodb.Collections.VaccinationProtocols)
+
+	var protocol entities.VaccinationProtocol
+	err := collection.FindOne(ctx, bson.M{"_id": id}).Decode(&protocol)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find vaccination protocol")
+	}
+
+	return &protocol, nil
+}
+
+// Update updates an existing protocol
+func (r *vaccinationProtocolRepository) Update(ctx context.Context, protocol *entities.VaccinationProtocol) error {
+	protocol.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.VaccinationProtocols)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": protocol.ID}, protocol)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update vaccination protocol")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a protocol by ID
+func (r *vaccinationProtocolRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	collection := r.db.Collection(mongodb.Collections.VaccinationProtocols)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to delete vaccination protocol")
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the protocols matching the filter
+func (r *vaccinationProtocolRepository) List(ctx context.Context, filter repositories.VaccinationProtocolFilter) ([]*entities.VaccinationProtocol, error) {
+	collection := r.db.Collection(mongodb.Collections.VaccinationProtocols)
+
+	query := bson.M{}
+	if filter.Species != "" {
+		query["species"] = entities.NormalizeSpecies(filter.Species)
+	}
+	if filter.VaccineType != "" {
+		query["vaccine_type"] = filter.VaccineType
+	}
+	if filter.Active != nil {
+		query["active"] = *filter.Active
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "species", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query vaccination protocols")
+	}
+	defer cursor.Close(ctx)
+
+	var protocols []*entities.VaccinationProtocol
+	if err := cursor.All(ctx, &protocols); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode vaccination protocols")
+	}
+
+	return protocols, nil
+}
+
+// EnsureIndexes creates necessary indexes for the vaccination_protocols collection
+func (r *vaccinationProtocolRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.VaccinationProtocols)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "species", Value: 1},
+				{Key: "active", Value: 1},
+			},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
+
+// vaccinationScheduleRepository implements the VaccinationScheduleRepository interface
+type vaccinationScheduleRepository struct {
+	db *mongodb.Database
+}
+
+// NewVaccinationScheduleRepository creates a new vaccination schedule repository
+func NewVaccinationScheduleRepository(db *mongodb.Database) repositories.VaccinationScheduleRepository {
+	return &vaccinationScheduleRepository{db: db}
+}
+
+// CreateMany stores generated schedule items
+func (r *vaccinationScheduleRepository) CreateMany(ctx context.Context, items []*entities.VaccinationScheduleItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	docs := make([]interface{}, len(items))
+	for i, item := range items {
+		item.ID = primitive.NewObjectID()
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		docs[i] = item
+	}
+
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		return errors.Wrap(err, 500, "failed to create vaccination schedule")
+	}
+
+	return nil
+}
+
+// Update updates a schedule item
+func (r *vaccinationScheduleRepository) Update(ctx context.Context, item *entities.VaccinationScheduleItem) error {
+	item.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": item.ID}, item)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update vaccination schedule")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetByAnimalID returns the schedule of an animal ordered by due date
+func (r *vaccinationScheduleRepository) GetByAnimalID(ctx context.Context, animalID primitive.ObjectID) ([]*entities.VaccinationScheduleItem, error) {
+	return r.find(ctx, bson.M{"animal_id": animalID})
+}
+
+// FindByVaccinationID returns the schedule item completed by a vaccination record
+func (r *vaccinationScheduleRepository) FindByVaccinationID(ctx context.Context, vaccinationID primitive.ObjectID) (*entities.VaccinationScheduleItem, error) {
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+
+	var item entities.VaccinationScheduleItem
+	err := collection.FindOne(ctx, bson.M{"vaccination_id": vaccinationID}).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find vaccination schedule")
+	}
+
+	return &item, nil
+}
+
+// Delete removes a schedule item
+func (r *vaccinationScheduleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to delete vaccination schedule")
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeletePending removes the pending items of an animal
+func (r *vaccinationScheduleRepository) DeletePending(ctx context.Context, animalID primitive.ObjectID) error {
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+
+	_, err := collection.DeleteMany(ctx, bson.M{
+		"animal_id": animalID,
+		"status":    entities.VaccinationSchedulePending,
+	})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to delete vaccination schedule")
+	}
+
+	return nil
+}
+
+// ListPending returns pending items due before the given time across all animals
+func (r *vaccinationScheduleRepository) ListPending(ctx context.Context, dueBefore time.Time) ([]*entities.VaccinationScheduleItem, error) {
+	return r.find(ctx, bson.M{
+		"status":   entities.VaccinationSchedulePending,
+		"due_date": bson.M{"$lte": dueBefore},
+	})
+}
+
+// PendingAnimalIDs returns the animals that have pending items
+func (r *vaccinationScheduleRepository) PendingAnimalIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+
+	values, err := collection.Distinct(ctx, "animal_id", bson.M{"status": entities.VaccinationSchedulePending})
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query vaccination schedules")
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *vaccinationScheduleRepository) find(ctx context.Context, query bson.M) ([]*entities.VaccinationScheduleItem, error) {
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}, {Key: "dose_number", Value: 1}})
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to query vaccination schedules")
+	}
+	defer cursor.Close(ctx)
+
+	var items []*entities.VaccinationScheduleItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, errors.Wrap(err, 500, "failed to decode vaccination schedules")
+	}
+
+	return items, nil
+}
+
+// EnsureIndexes creates necessary indexes for the vaccination_schedules collection
+func (r *vaccinationScheduleRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.VaccinationSchedules)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "animal_id", Value: 1},
+				{Key: "due_date", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "due_date", Value: 1},
+			},
+		},
+		{
+			Keys:    bson.D{{Key: "vaccination_id", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
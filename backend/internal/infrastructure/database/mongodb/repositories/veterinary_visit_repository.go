@@ -219,6 +219,18 @@ func (r *veterinaryVisitRepository) List(ctx context.Context, filter repositorie
 		query["veterinarian_name"] = bson.M{"$regex": filter.VeterinarianName, "$options": "i"}
 	}
 
+	if filter.PartnerID != nil {
+		query["partner_id"] = *filter.PartnerID
+	}
+
+	if filter.StaffID != nil {
+		query["assigned_staff"] = *filter.StaffID
+	}
+
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+
 	// Date range filter
 	if filter.FromDate != nil || filter.ToDate != nil {
 		dateFilter := bson.M{}
@@ -347,6 +359,15 @@ func (r *veterinaryVisitRepository) EnsureIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "scheduled_date", Value: 1}},
 		},
+		{
+			Keys: bson.D{
+				{Key: "partner_id", Value: 1},
+				{Key: "visit_date", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "assigned_staff", Value: 1}},
+		},
 		{
 			Keys: bson.D{
 				{Key: "animal_id", Value: 1},
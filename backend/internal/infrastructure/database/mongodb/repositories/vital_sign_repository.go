@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/infrastructure/database/mongodb"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// vitalSignRepository implements the VitalSignRepository interface
+type vitalSignRepository struct {
+	db *mongodb.Database
+}
+
+// NewVitalSignRepository creates a new vital-sign reading repository
+func NewVitalSignRepository(db *mongodb.Database) repositories.VitalSignRepository {
+	return &vitalSignRepository{db: db}
+}
+
+// Create creates a new reading
+func (r *vitalSignRepository) Create(ctx context.Context, reading *entities.VitalSignReading) error {
+	reading.CreatedAt = time.Now()
+	reading.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.VitalSignReadings)
+	result, err := collection.InsertOne(ctx, reading)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create vital sign reading")
+	}
+
+	reading.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// Update updates an existing reading
+func (r *vitalSignRepository) Update(ctx context.Context, reading *entities.VitalSignReading) error {
+	reading.UpdatedAt = time.Now()
+
+	collection := r.db.Collection(mongodb.Collections.VitalSignReadings)
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": reading.ID}, reading)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to update vital sign reading")
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+// FindBySource finds the reading captured by a source record, e.g. a veterinary visit
+func (r *vitalSignRepository) FindBySource(ctx context.Context, source entities.VitalSource, sourceID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	collection := r.db.Collection(mongodb.Collections.VitalSignReadings)
+
+	var reading entities.VitalSignReading
+	err := collection.FindOne(ctx, bson.M{"source": source, "source_id": sourceID}).Decode(&reading)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrNotFound
+		}
+		return nil, errors.Wrap(err, 500, "failed to find vital sign reading")
+	}
+
+	return &reading, nil
+}
+
+// List returns the readings matching the filter
+func (r *vitalSignRepository) List(ctx context.Context, filter *repositories.VitalSignFilter) ([]*entities.VitalSignReading, int64, error) {
+	collection := r.db.Collection(mongodb.Collections.VitalSignReadings)
+
+	query := bson.M{}
+	if filter.AnimalID != nil {
+		query["animal_id"] = *filter.AnimalID
+	}
+	if filter.Source != "" {
+		query["source"] = filter.Source
+	}
+	if filter.Metric != "" {
+		query[filter.Metric] = bson.M{"$gt": 0}
+	}
+	if filter.From != nil || filter.To != nil {
+		recordedAt := bson.M{}
+		if filter.From != nil {
+			recordedAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			recordedAt["$lte"] = *filter.To
+		}
+		query["recorded_at"] = recordedAt
+	}
+
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to count vital sign readings")
+	}
+
+	sortOrder := -1
+	if filter.SortOrder == "asc" {
+		sortOrder = 1
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "recorded_at", Value: sortOrder}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		findOptions.SetSkip(filter.Offset)
+	}
+
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to query vital sign readings")
+	}
+	defer cursor.Close(ctx)
+
+	var readings []*entities.VitalSignReading
+	if err := cursor.All(ctx, &readings); err != nil {
+		return nil, 0, errors.Wrap(err, 500, "failed to decode vital sign readings")
+	}
+
+	return readings, total, nil
+}
+
+// EnsureIndexes creates necessary indexes for the vital_sign_readings collection
+func (r *vitalSignRepository) EnsureIndexes(ctx context.Context) error {
+	collection := r.db.Collection(mongodb.Collections.VitalSignReadings)
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "animal_id", Value: 1},
+				{Key: "recorded_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "source", Value: 1},
+				{Key: "source_id", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create indexes")
+	}
+
+	return nil
+}
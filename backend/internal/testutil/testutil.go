@@ -0,0 +1,77 @@
+// Package testutil holds the fixtures shared by the use case tests.
+package testutil
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Messenger records the communications a use case sends instead of delivering them
+type Messenger struct {
+	Sent []*entities.Communication
+}
+
+// CreateCommunication gives the communication an ID and records it
+func (m *Messenger) CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error {
+	communication.ID = primitive.NewObjectID()
+	m.Sent = append(m.Sent, communication)
+	return nil
+}
+
+// Notifier records the notifications a use case creates
+type Notifier struct {
+	Notifications []*entities.Notification
+}
+
+// CreateNotification records the notification
+func (n *Notifier) CreateNotification(ctx context.Context, notification *entities.Notification) error {
+	n.Notifications = append(n.Notifications, notification)
+	return nil
+}
+
+// LinkToken returns the token of the first link under the page URL in a message and
+// fails the test when the message has none
+func LinkToken(t *testing.T, communication *entities.Communication, pageURL string) string {
+	t.Helper()
+	prefix := strings.TrimRight(pageURL, "/") + "/"
+	for _, word := range strings.Fields(communication.Body) {
+		if strings.HasPrefix(word, prefix) {
+			return strings.TrimPrefix(word, prefix)
+		}
+	}
+	t.Fatalf("no link to %s in %q", pageURL, communication.Body)
+	return ""
+}
+
+// AuditLogs returns an audit log repository that accepts every entry
+func AuditLogs() *mocks.AuditLogRepository {
+	auditLogs := new(mocks.AuditLogRepository)
+	auditLogs.On("Create", mock.Anything, mock.Anything).Return(nil)
+	return auditLogs
+}
+
+// Clinic registers an active veterinary partner in Kraków with a standard rate of 100
+// and a 10% discount
+func Clinic(partners *mocks.PartnerRepository) *entities.Partner {
+	clinic := &entities.Partner{
+		ID:                 primitive.NewObjectID(),
+		Name:               "Vet Clinic",
+		Type:               entities.PartnerTypeVeterinary,
+		Status:             entities.PartnerStatusActive,
+		StandardRate:       100,
+		DiscountPercentage: 10,
+		Address: entities.AddressInfo{
+			Street:  "Długa 1",
+			City:    "Kraków",
+			ZipCode: "31-001",
+		},
+	}
+	partners.On("FindByID", mock.Anything, clinic.ID).Return(clinic, nil)
+	return clinic
+}
@@ -0,0 +1,371 @@
+package adopter
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContactTag marks the CRM contacts created for adopters
+const ContactTag = "adopter"
+
+// AdopterUseCase keeps one record per person who applies to adopt, matched by email or phone
+type AdopterUseCase struct {
+	adopterRepo     repositories.AdopterRepository
+	applicationRepo repositories.AdoptionApplicationRepository
+	adoptionRepo    repositories.AdoptionRepository
+	contactRepo     repositories.ContactRepository
+	auditLogRepo    repositories.AuditLogRepository
+}
+
+// NewAdopterUseCase creates a new adopter use case
+func NewAdopterUseCase(
+	adopterRepo repositories.AdopterRepository,
+	applicationRepo repositories.AdoptionApplicationRepository,
+	adoptionRepo repositories.AdoptionRepository,
+	contactRepo repositories.ContactRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) *AdopterUseCase {
+	return &AdopterUseCase{
+		adopterRepo:     adopterRepo,
+		applicationRepo: applicationRepo,
+		adoptionRepo:    adoptionRepo,
+		contactRepo:     contactRepo,
+		auditLogRepo:    auditLogRepo,
+	}
+}
+
+// ListAdoptersRequest represents a request to list adopters
+type ListAdoptersRequest struct {
+	Search     string `form:"search"`
+	DoNotAdopt *bool  `form:"do_not_adopt"`
+	Limit      int64  `form:"limit"`
+	Offset     int64  `form:"offset"`
+}
+
+// UpdateAdopterRequest represents a request to correct an adopter's details
+type UpdateAdopterRequest struct {
+	FirstName *string               `json:"first_name,omitempty" validate:"omitempty,min=1"`
+	LastName  *string               `json:"last_name,omitempty" validate:"omitempty,min=1"`
+	Email     *string               `json:"email,omitempty" validate:"omitempty,email"`
+	Phone     *string               `json:"phone,omitempty"`
+	Address   *entities.AddressInfo `json:"address,omitempty"`
+	Notes     *string               `json:"notes,omitempty"`
+}
+
+// DoNotAdoptRequest represents a request to flag a person do-not-adopt
+type DoNotAdoptRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+// AdopterHistory is a person with all their applications and adoptions
+type AdopterHistory struct {
+	Adopter      *entities.Adopter               `json:"adopter"`
+	Applications []*entities.AdoptionApplication `json:"applications"`
+	Adoptions    []*entities.Adoption            `json:"adoptions"`
+}
+
+// ResolveApplication returns the adopter of an application: the one it is already linked to,
+// or the person matched or created from the applicant. Flagged persons are refused.
+func (uc *AdopterUseCase) ResolveApplication(ctx context.Context, application *entities.AdoptionApplication, userID primitive.ObjectID) (*entities.Adopter, error) {
+	if application.AdopterID != nil {
+		adopter, err := uc.adopterRepo.FindByID(ctx, *application.AdopterID)
+		if err != nil {
+			return nil, err
+		}
+		if adopter.IsBlocked() {
+			return nil, blockedError(adopter)
+		}
+		return adopter, nil
+	}
+
+	return uc.resolveApplicant(ctx, application.Applicant, application.Address, userID)
+}
+
+// resolveApplicant matches the applicant to an adopter by email, then phone, refreshing the
+// record with the latest details, or creates a new adopter linked to an adopter contact
+func (uc *AdopterUseCase) resolveApplicant(ctx context.Context, applicant entities.ApplicantInfo, address entities.AddressInfo, userID primitive.ObjectID) (*entities.Adopter, error) {
+	emailKey := entities.NormalizeEmail(applicant.Email)
+	phoneKey := entities.NormalizePhone(applicant.Phone)
+	if emailKey == "" && phoneKey == "" {
+		return nil, errors.NewBadRequest("the applicant's email or phone is required")
+	}
+
+	matches, err := uc.adopterRepo.FindMatches(ctx, emailKey, phoneKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any record of the same person that is flagged blocks the application
+	for _, match := range matches {
+		if match.IsBlocked() {
+			return nil, blockedError(match)
+		}
+	}
+
+	adopter := bestMatch(matches, emailKey)
+	if adopter == nil {
+		adopter = entities.NewAdopter(applicant, address, userID)
+		uc.linkContact(ctx, adopter)
+		if err := uc.adopterRepo.Create(ctx, adopter); err != nil {
+			return nil, err
+		}
+
+		auditLog := entities.NewAuditLog(userID, entities.ActionCreate, "adopter", adopter.FullName(), "created from an adoption application").
+			WithEntityID(adopter.ID)
+		_ = uc.auditLogRepo.Create(ctx, auditLog)
+		return adopter, nil
+	}
+
+	if name := strings.TrimSpace(applicant.FirstName); name != "" {
+		adopter.FirstName = name
+	}
+	if name := strings.TrimSpace(applicant.LastName); name != "" {
+		adopter.LastName = name
+	}
+	if adopter.EmailKey == "" && emailKey != "" {
+		adopter.SetEmail(applicant.Email)
+	}
+	if adopter.PhoneKey == "" && phoneKey != "" {
+		adopter.SetPhone(applicant.Phone)
+	}
+	if address != (entities.AddressInfo{}) {
+		adopter.Address = &address
+	}
+	if adopter.ContactID == nil {
+		uc.linkContact(ctx, adopter)
+	}
+	if err := uc.adopterRepo.Update(ctx, adopter); err != nil {
+		return nil, err
+	}
+
+	return adopter, nil
+}
+
+// bestMatch prefers the adopter with the same email, then the oldest record with the same phone
+func bestMatch(matches []*entities.Adopter, emailKey string) *entities.Adopter {
+	if len(matches) == 0 {
+		return nil
+	}
+	if emailKey != "" {
+		for _, match := range matches {
+			if match.EmailKey == emailKey {
+				return match
+			}
+		}
+	}
+	return matches[0]
+}
+
+// linkContact links the adopter to the adopter contact with the same email, creating one
+// if there is none; the CRM contact is a convenience and never blocks the adopter record
+func (uc *AdopterUseCase) linkContact(ctx context.Context, adopter *entities.Adopter) {
+	if uc.contactRepo == nil {
+		return
+	}
+
+	if adopter.Email != "" {
+		contacts, _, err := uc.contactRepo.List(ctx, repositories.ContactFilter{
+			Type:  string(entities.ContactTypeAdopter),
+			Email: adopter.Email,
+			Limit: 1,
+		})
+		if err == nil && len(contacts) > 0 {
+			adopter.ContactID = &contacts[0].ID
+			return
+		}
+	}
+
+	contact := entities.NewContact(adopter.FirstName, adopter.LastName, entities.ContactTypeAdopter, entities.ContactStatusActive)
+	contact.Email = adopter.Email
+	contact.Phone = adopter.Phone
+	contact.Address = adopter.Address
+	contact.Tags = []string{ContactTag}
+	if err := uc.contactRepo.Create(ctx, contact); err != nil {
+		return
+	}
+	adopter.ContactID = &contact.ID
+}
+
+// blockedError explains why a person cannot adopt
+func blockedError(adopter *entities.Adopter) error {
+	return errors.NewForbidden("the applicant is flagged do-not-adopt: " + adopter.DoNotAdopt.Reason)
+}
+
+// GetAdopter returns an adopter
+func (uc *AdopterUseCase) GetAdopter(ctx context.Context, id primitive.ObjectID) (*entities.Adopter, error) {
+	adopter, err := uc.adopterRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewNotFound("adopter not found")
+		}
+		return nil, err
+	}
+	return adopter, nil
+}
+
+// ListAdopters lists adopters
+func (uc *AdopterUseCase) ListAdopters(ctx context.Context, req *ListAdoptersRequest) ([]*entities.Adopter, int64, error) {
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	return uc.adopterRepo.List(ctx, &repositories.AdopterFilter{
+		Search:     strings.TrimSpace(req.Search),
+		DoNotAdopt: req.DoNotAdopt,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+}
+
+// UpdateAdopter corrects an adopter's details; an email or phone already used by another
+// adopter is refused so that records are not silently duplicated
+func (uc *AdopterUseCase) UpdateAdopter(ctx context.Context, id primitive.ObjectID, req *UpdateAdopterRequest, userID primitive.ObjectID) (*entities.Adopter, error) {
+	adopter, err := uc.GetAdopter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]interface{})
+	if req.FirstName != nil {
+		adopter.FirstName = strings.TrimSpace(*req.FirstName)
+		changes["first_name"] = adopter.FirstName
+	}
+	if req.LastName != nil {
+		adopter.LastName = strings.TrimSpace(*req.LastName)
+		changes["last_name"] = adopter.LastName
+	}
+	if req.Email != nil {
+		adopter.SetEmail(*req.Email)
+		changes["email"] = adopter.Email
+	}
+	if req.Phone != nil {
+		adopter.SetPhone(*req.Phone)
+		changes["phone"] = adopter.Phone
+	}
+	if req.Address != nil {
+		adopter.Address = req.Address
+		changes["address"] = adopter.Address
+	}
+	if req.Notes != nil {
+		adopter.Notes = *req.Notes
+	}
+	if adopter.EmailKey == "" && adopter.PhoneKey == "" {
+		return nil, errors.NewBadRequest("an email or phone is required")
+	}
+
+	if req.Email != nil || req.Phone != nil {
+		matches, err := uc.adopterRepo.FindMatches(ctx, adopter.EmailKey, adopter.PhoneKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if match.ID != adopter.ID {
+				return nil, errors.NewConflict("another adopter has this email or phone: " + match.ID.Hex())
+			}
+		}
+	}
+
+	if err := uc.adopterRepo.Update(ctx, adopter); err != nil {
+		return nil, err
+	}
+
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "adopter", adopter.FullName(), "").
+		WithEntityID(id).
+		WithChanges(changes)
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return adopter, nil
+}
+
+// SetDoNotAdopt flags a person so that their future applications are refused
+func (uc *AdopterUseCase) SetDoNotAdopt(ctx context.Context, id primitive.ObjectID, req *DoNotAdoptRequest, userID primitive.ObjectID) (*entities.Adopter, error) {
+	adopter, err := uc.GetAdopter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	adopter.DoNotAdopt = &entities.DoNotAdoptFlag{
+		Reason: strings.TrimSpace(req.Reason),
+		SetBy:  userID,
+		SetAt:  time.Now(),
+	}
+	if err := uc.adopterRepo.Update(ctx, adopter); err != nil {
+		return nil, err
+	}
+
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "adopter", adopter.FullName(), "flagged do-not-adopt").
+		WithEntityID(id).
+		WithChanges(map[string]interface{}{"do_not_adopt": adopter.DoNotAdopt.Reason})
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return adopter, nil
+}
+
+// ClearDoNotAdopt removes the do-not-adopt flag
+func (uc *AdopterUseCase) ClearDoNotAdopt(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) (*entities.Adopter, error) {
+	adopter, err := uc.GetAdopter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !adopter.IsBlocked() {
+		return nil, errors.NewBadRequest("the adopter is not flagged do-not-adopt")
+	}
+
+	previous := adopter.DoNotAdopt.Reason
+	adopter.DoNotAdopt = nil
+	if err := uc.adopterRepo.Update(ctx, adopter); err != nil {
+		return nil, err
+	}
+
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "adopter", adopter.FullName(), "do-not-adopt flag cleared").
+		WithEntityID(id).
+		WithChanges(map[string]interface{}{"previous_reason": previous})
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return adopter, nil
+}
+
+// GetHistory returns an adopter with their applications and adoptions, newest first
+func (uc *AdopterUseCase) GetHistory(ctx context.Context, id primitive.ObjectID) (*AdopterHistory, error) {
+	adopter, err := uc.GetAdopter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applications, _, err := uc.applicationRepo.List(ctx, repositories.AdoptionApplicationFilter{
+		AdopterID: &id,
+		SortBy:    "application_date",
+		SortOrder: "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		AdopterID: &id,
+		SortBy:    "adoption_date",
+		SortOrder: "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := &AdopterHistory{
+		Adopter:      adopter,
+		Applications: applications,
+		Adoptions:    adoptions,
+	}
+	if history.Applications == nil {
+		history.Applications = []*entities.AdoptionApplication{}
+	}
+	if history.Adoptions == nil {
+		history.Adoptions = []*entities.Adoption{}
+	}
+	return history, nil
+}
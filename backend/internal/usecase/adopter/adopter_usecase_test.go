@@ -0,0 +1,167 @@
+package adopter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type adopterMocks struct {
+	adopters     *mocks.AdopterRepository
+	applications *mocks.AdoptionApplicationRepository
+	adoptions    *mocks.AdoptionRepository
+	contacts     *mocks.ContactRepository
+}
+
+func newAdopterUseCase() (*AdopterUseCase, *adopterMocks) {
+	m := &adopterMocks{
+		adopters:     new(mocks.AdopterRepository),
+		applications: new(mocks.AdoptionApplicationRepository),
+		adoptions:    new(mocks.AdoptionRepository),
+		contacts:     new(mocks.ContactRepository),
+	}
+	auditLogs := new(mocks.AuditLogRepository)
+	auditLogs.On("Create", mock.Anything, mock.Anything).Return(nil)
+	return NewAdopterUseCase(m.adopters, m.applications, m.adoptions, m.contacts, auditLogs), m
+}
+
+func application(email, phone string) *entities.AdoptionApplication {
+	return entities.NewAdoptionApplication(primitive.NewObjectID(), entities.ApplicantInfo{
+		FirstName: "Anna",
+		LastName:  "Nowak",
+		Email:     email,
+		Phone:     phone,
+	}, primitive.NewObjectID())
+}
+
+func TestAdopterUseCase_ResolveApplicationCreatesAdopterAndContact(t *testing.T) {
+	uc, m := newAdopterUseCase()
+	app := application(" Anna.Nowak@Example.com ", "+48 500-100-200")
+	app.Address = entities.AddressInfo{Street: "Długa 5", City: "Kraków", ZipCode: "30-001", Country: "PL"}
+
+	m.adopters.On("FindMatches", mock.Anything, "anna.nowak@example.com", "500100200").Return([]*entities.Adopter{}, nil)
+	m.contacts.On("List", mock.Anything, repositories.ContactFilter{Type: "adopter", Email: "Anna.Nowak@Example.com", Limit: 1}).
+		Return([]*entities.Contact{}, int64(0), nil)
+	var contact *entities.Contact
+	m.contacts.On("Create", mock.Anything, mock.AnythingOfType("*entities.Contact")).Run(func(args mock.Arguments) {
+		contact = args.Get(1).(*entities.Contact)
+	}).Return(nil)
+	m.adopters.On("Create", mock.Anything, mock.AnythingOfType("*entities.Adopter")).Return(nil)
+
+	adopter, err := uc.ResolveApplication(context.Background(), app, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Anna Nowak", adopter.FullName())
+	assert.Equal(t, "Anna.Nowak@Example.com", adopter.Email)
+	assert.Equal(t, "anna.nowak@example.com", adopter.EmailKey)
+	assert.Equal(t, "500100200", adopter.PhoneKey)
+	assert.Equal(t, "Kraków", adopter.Address.City)
+
+	require.NotNil(t, contact)
+	assert.Equal(t, entities.ContactTypeAdopter, contact.Type)
+	assert.Equal(t, []string{ContactTag}, contact.Tags)
+	assert.Equal(t, contact.ID, *adopter.ContactID)
+}
+
+func TestAdopterUseCase_ResolveApplicationMatchesExistingPerson(t *testing.T) {
+	uc, m := newAdopterUseCase()
+	contactID := primitive.NewObjectID()
+	byPhone := entities.NewAdopter(entities.ApplicantInfo{FirstName: "Ania", LastName: "Nowak", Phone: "500100200"}, entities.AddressInfo{}, primitive.NewObjectID())
+	byPhone.ContactID = &contactID
+	other := entities.NewAdopter(entities.ApplicantInfo{FirstName: "Jan", LastName: "Kowalski", Email: "jan@example.com"}, entities.AddressInfo{}, primitive.NewObjectID())
+
+	m.adopters.On("FindMatches", mock.Anything, "anna@example.com", "500100200").Return([]*entities.Adopter{byPhone, other}, nil)
+	m.adopters.On("Update", mock.Anything, byPhone).Return(nil)
+
+	adopter, err := uc.ResolveApplication(context.Background(), application("anna@example.com", "0048 500 100 200"), primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Same(t, byPhone, adopter, "the phone number written differently matches the same person")
+	assert.Equal(t, "Anna", adopter.FirstName, "the latest name is kept")
+	assert.Equal(t, "anna@example.com", adopter.Email, "a missing email is filled in")
+	assert.Equal(t, "500100200", adopter.Phone, "the known phone is kept")
+	m.adopters.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	m.contacts.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAdopterUseCase_DoNotAdoptBlocksApplications(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newAdopterUseCase()
+	flagged := entities.NewAdopter(entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak", Email: "anna@example.com"}, entities.AddressInfo{}, primitive.NewObjectID())
+	m.adopters.On("FindByID", mock.Anything, flagged.ID).Return(flagged, nil)
+	m.adopters.On("Update", mock.Anything, flagged).Return(nil)
+
+	_, err := uc.SetDoNotAdopt(ctx, flagged.ID, &DoNotAdoptRequest{Reason: " Animal neglect reported by inspector "}, primitive.NewObjectID())
+	require.NoError(t, err)
+	require.True(t, flagged.IsBlocked())
+	assert.Equal(t, "Animal neglect reported by inspector", flagged.DoNotAdopt.Reason)
+
+	// A new application under another email is still caught through the phone
+	m.adopters.On("FindMatches", mock.Anything, "a.nowak@example.com", "500100200").Return([]*entities.Adopter{flagged}, nil)
+	_, err = uc.ResolveApplication(ctx, application("a.nowak@example.com", "500 100 200"), primitive.NewObjectID())
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, err.(*errors.AppError).Code)
+	assert.Contains(t, err.Error(), "Animal neglect reported by inspector")
+
+	// An approved application linked earlier cannot become an adoption either
+	linked := application("anna@example.com", "")
+	linked.AdopterID = &flagged.ID
+	_, err = uc.ResolveApplication(ctx, linked, primitive.NewObjectID())
+	require.Error(t, err)
+
+	_, err = uc.ClearDoNotAdopt(ctx, flagged.ID, primitive.NewObjectID())
+	require.NoError(t, err)
+	adopter, err := uc.ResolveApplication(ctx, linked, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, flagged.ID, adopter.ID)
+}
+
+func TestAdopterUseCase_UpdateAdopterRefusesDuplicates(t *testing.T) {
+	uc, m := newAdopterUseCase()
+	adopter := entities.NewAdopter(entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak", Email: "anna@example.com"}, entities.AddressInfo{}, primitive.NewObjectID())
+	other := entities.NewAdopter(entities.ApplicantInfo{FirstName: "Jan", LastName: "Kowalski", Phone: "600700800"}, entities.AddressInfo{}, primitive.NewObjectID())
+	m.adopters.On("FindByID", mock.Anything, adopter.ID).Return(adopter, nil)
+	m.adopters.On("FindMatches", mock.Anything, "anna@example.com", "600700800").Return([]*entities.Adopter{adopter, other}, nil)
+
+	phone := "+48 600 700 800"
+	_, err := uc.UpdateAdopter(context.Background(), adopter.ID, &UpdateAdopterRequest{Phone: &phone}, primitive.NewObjectID())
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, err.(*errors.AppError).Code)
+	m.adopters.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestAdopterUseCase_GetHistory(t *testing.T) {
+	uc, m := newAdopterUseCase()
+	adopter := entities.NewAdopter(entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak", Email: "anna@example.com"}, entities.AddressInfo{}, primitive.NewObjectID())
+	first := application("anna@example.com", "")
+	second := application("anna@example.com", "")
+	adoption := entities.NewAdoption(second.ID, second.AnimalID, adopter.ID, 200, primitive.NewObjectID())
+
+	m.adopters.On("FindByID", mock.Anything, adopter.ID).Return(adopter, nil)
+	m.applications.On("List", mock.Anything, mock.MatchedBy(func(filter repositories.AdoptionApplicationFilter) bool {
+		return *filter.AdopterID == adopter.ID && filter.Limit == 0
+	})).Return([]*entities.AdoptionApplication{second, first}, int64(2), nil)
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(filter repositories.AdoptionFilter) bool {
+		return *filter.AdopterID == adopter.ID
+	})).Return(nil, int64(0), nil).Once()
+
+	history, err := uc.GetHistory(context.Background(), adopter.ID)
+	require.NoError(t, err)
+	assert.Len(t, history.Applications, 2)
+	assert.NotNil(t, history.Adoptions)
+	assert.Empty(t, history.Adoptions)
+
+	m.adoptions.On("List", mock.Anything, mock.Anything).Return([]*entities.Adoption{adoption}, int64(1), nil)
+	history, err = uc.GetHistory(context.Background(), adopter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*entities.Adoption{adoption}, history.Adoptions)
+}
@@ -2,11 +2,15 @@ package adoption
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
 	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/microchip"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,6 +20,30 @@ type AdoptionUseCase struct {
 	adoptionRepo    repositories.AdoptionRepository
 	animalRepo      repositories.AnimalRepository
 	auditLogRepo    repositories.AuditLogRepository
+	chipOwners      MicrochipOwners
+	packets         MedicalPacketGenerator
+	adopters        AdopterRegistry
+	settingsRepo    repositories.SettingsRepository
+	taskRepo        repositories.TaskRepository
+	messenger       Messenger
+	quarantines     QuarantineChecker
+}
+
+// AdopterRegistry matches applicants to their adopter record
+type AdopterRegistry interface {
+	ResolveApplication(ctx context.Context, application *entities.AdoptionApplication, userID primitive.ObjectID) (*entities.Adopter, error)
+}
+
+// MicrochipOwners registers a new owner of an animal's microchip with the registry and
+// stores the registration on the animal
+type MicrochipOwners interface {
+	RegisterMicrochipOwner(ctx context.Context, animalID primitive.ObjectID, owner microchip.Owner, userID primitive.ObjectID) (*entities.Animal, error)
+}
+
+// QuarantineChecker finds the open quarantine of an animal, which blocks its adoption
+// even when its status was changed by hand
+type QuarantineChecker interface {
+	HasActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (bool, error)
 }
 
 // NewAdoptionUseCase creates a new adoption use case
@@ -24,12 +52,26 @@ func NewAdoptionUseCase(
 	adoptionRepo repositories.AdoptionRepository,
 	animalRepo repositories.AnimalRepository,
 	auditLogRepo repositories.AuditLogRepository,
+	chipOwners MicrochipOwners,
+	packets MedicalPacketGenerator,
+	adopters AdopterRegistry,
+	settingsRepo repositories.SettingsRepository,
+	taskRepo repositories.TaskRepository,
+	messenger Messenger,
+	quarantines QuarantineChecker,
 ) *AdoptionUseCase {
 	return &AdoptionUseCase{
 		applicationRepo: applicationRepo,
 		adoptionRepo:    adoptionRepo,
 		animalRepo:      animalRepo,
 		auditLogRepo:    auditLogRepo,
+		chipOwners:      chipOwners,
+		packets:         packets,
+		adopters:        adopters,
+		settingsRepo:    settingsRepo,
+		taskRepo:        taskRepo,
+		messenger:       messenger,
+		quarantines:     quarantines,
 	}
 }
 
@@ -67,7 +109,7 @@ type CreateApplicationRequest struct {
 
 // UpdateApplicationRequest represents a request to update an adoption application
 type UpdateApplicationRequest struct {
-	Status          *entities.ApplicationStatus `json:"status,omitempty"`
+	Status          *entities.ApplicationStatus `json:"status,omitempty"` // Refused: the review decides the status
 	ReviewNotes     *string                     `json:"review_notes,omitempty"`
 	RejectionReason *string                     `json:"rejection_reason,omitempty"`
 	HomeVisitDate   *time.Time                  `json:"home_visit_date,omitempty"`
@@ -88,6 +130,8 @@ type CreateAdoptionRequest struct {
 	ContractURL        string    `json:"contract_url,omitempty"`
 	ScheduleFollowUps  bool      `json:"schedule_follow_ups"`
 	FollowUpIntervals  []int     `json:"follow_up_intervals,omitempty"` // Days: [7, 30, 90]
+	AgreesToSpayNeuter *bool     `json:"agrees_to_spay_neuter,omitempty"` // Intact animals, defaults to true; only false is refused
+	SterilizationDueDays int     `json:"sterilization_due_days,omitempty" validate:"omitempty,min=1"` // Intact animals, defaults to 60
 }
 
 // UpdateAdoptionRequest represents a request to update an adoption
@@ -107,6 +151,7 @@ type UpdateAdoptionRequest struct {
 // ListApplicationsRequest represents a request to list adoption applications
 type ListApplicationsRequest struct {
 	AnimalID       string     `form:"animal_id"`
+	AdopterID      string     `form:"adopter_id"`
 	Status         string     `form:"status"`
 	ApplicantEmail string     `form:"applicant_email"`
 	ApplicantName  string     `form:"applicant_name"`
@@ -128,6 +173,7 @@ type ListAdoptionsRequest struct {
 	FromDate      *time.Time `form:"from_date"`
 	ToDate        *time.Time `form:"to_date"`
 	TrialPeriod   *bool      `form:"trial_period"`
+	SterilizationStatus string `form:"sterilization_status"`
 	Limit         int64      `form:"limit"`
 	Offset        int64      `form:"offset"`
 	SortBy        string     `form:"sort_by"`
@@ -180,6 +226,14 @@ func (uc *AdoptionUseCase) CreateApplication(ctx context.Context, req *CreateApp
 	application.UnderstandsCommitment = req.UnderstandsCommitment
 	application.AdditionalInfo = req.AdditionalInfo
 
+	// Match the applicant to their adopter record; persons flagged do-not-adopt are refused
+	adopter, err := uc.adopters.ResolveApplication(ctx, application, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	application.AdopterID = &adopter.ID
+	uc.startReview(ctx, application, creatorID)
+
 	if err := uc.applicationRepo.Create(ctx, application); err != nil {
 		return nil, err
 	}
@@ -204,26 +258,14 @@ func (uc *AdoptionUseCase) UpdateApplication(ctx context.Context, id primitive.O
 		return nil, err
 	}
 
+	// Approving or rejecting here would skip the review stages, the reviewer
+	// checks and the applicant's notification
+	if req.Status != nil && *req.Status != application.Status {
+		return nil, errors.NewBadRequest("the status of an application is set by its review; use approve or reject")
+	}
+
 	// Track changes
 	changes := make(map[string]interface{})
-	now := time.Now()
-
-	if req.Status != nil {
-		changes["status"] = *req.Status
-		application.Status = *req.Status
-
-		// Update status-specific dates
-		switch *req.Status {
-		case entities.ApplicationStatusUnderReview:
-			application.ReviewDate = &now
-		case entities.ApplicationStatusApproved:
-			application.ApprovalDate = &now
-			application.ReviewDate = &now
-		case entities.ApplicationStatusRejected:
-			application.RejectionDate = &now
-			application.ReviewDate = &now
-		}
-	}
 
 	if req.ReviewNotes != nil {
 		application.ReviewNotes = *req.ReviewNotes
@@ -308,6 +350,13 @@ func (uc *AdoptionUseCase) ListApplications(ctx context.Context, req *ListApplic
 		}
 	}
 
+	if req.AdopterID != "" {
+		adopterID, err := primitive.ObjectIDFromHex(req.AdopterID)
+		if err == nil {
+			filter.AdopterID = &adopterID
+		}
+	}
+
 	return uc.applicationRepo.List(ctx, filter)
 }
 
@@ -348,11 +397,35 @@ func (uc *AdoptionUseCase) CreateAdoption(ctx context.Context, req *CreateAdopti
 		return nil, errors.NewBadRequest("adoption already exists for this application")
 	}
 
+	animal, err := uc.animalRepo.FindByID(ctx, application.AnimalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkNotQuarantined(ctx, animal); err != nil {
+		return nil, err
+	}
+	// Clients written before the agreement existed don't send it; the sterilization
+	// requirement below still binds the adopter
+	agreesToSpayNeuter := !animal.Medical.Sterilized
+	if req.AgreesToSpayNeuter != nil {
+		agreesToSpayNeuter = *req.AgreesToSpayNeuter
+	}
+	if !animal.Medical.Sterilized && !agreesToSpayNeuter {
+		return nil, errors.NewBadRequest("the adopter must agree to spay/neuter an intact animal")
+	}
+
+	// Applications made before adopter records existed are matched now
+	adopter, err := uc.adopters.ResolveApplication(ctx, application, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	application.AdopterID = &adopter.ID
+
 	// Create adoption
 	adoption := entities.NewAdoption(
 		applicationID,
 		application.AnimalID,
-		creatorID, // In real scenario, this should be the adopter's user ID
+		adopter.ID,
 		req.AdoptionFee,
 		creatorID,
 	)
@@ -377,6 +450,13 @@ func (uc *AdoptionUseCase) CreateAdoption(ctx context.Context, req *CreateAdopti
 	if req.ContractURL != "" {
 		adoption.Contract.ContractURL = req.ContractURL
 	}
+	adoption.AgreesToSpayNeuter = agreesToSpayNeuter
+
+	// Intact animals must be sterilized by the adopter before the deadline
+	if !animal.Medical.Sterilized {
+		dueDate := entities.SterilizationDueDate(adoption.AdoptionDate, animal.DateOfBirth, req.SterilizationDueDays)
+		adoption.Sterilization = entities.NewSterilizationRequirement(dueDate)
+	}
 
 	// Schedule follow-ups
 	if req.ScheduleFollowUps && len(req.FollowUpIntervals) > 0 {
@@ -384,7 +464,7 @@ func (uc *AdoptionUseCase) CreateAdoption(ctx context.Context, req *CreateAdopti
 			followUpDate := time.Now().AddDate(0, 0, days)
 			adoption.AddFollowUp(entities.FollowUpSchedule{
 				ScheduledDate: followUpDate,
-				Type:          "visit",
+				Type:          entities.FollowUpTypeSurvey,
 			})
 		}
 	}
@@ -399,11 +479,11 @@ func (uc *AdoptionUseCase) CreateAdoption(ctx context.Context, req *CreateAdopti
 	_ = uc.applicationRepo.Update(ctx, application)
 
 	// Update animal status to adopted
-	animal, _ := uc.animalRepo.FindByID(ctx, application.AnimalID)
-	if animal != nil {
-		animal.Status = entities.AnimalStatusAdopted
-		_ = uc.animalRepo.Update(ctx, animal)
-	}
+	animal.Status = entities.AnimalStatusAdopted
+	_ = uc.animalRepo.Update(ctx, animal)
+
+	// Hand the medical history over with the animal
+	uc.attachMedicalPacket(ctx, adoption, creatorID)
 
 	// Create audit log
 	auditLog := entities.NewAuditLog(creatorID, entities.ActionCreate, "adoption", "", "").
@@ -428,18 +508,20 @@ func (uc *AdoptionUseCase) UpdateAdoption(ctx context.Context, id primitive.Obje
 	// Track changes
 	changes := make(map[string]interface{})
 
-	if req.Status != nil {
+	if req.Status != nil && *req.Status != adoption.Status {
+		// Completing an adoption hands over the animal and returning one refunds the fee and
+		// starts a new shelter stay, so both have their own operation; a pending adoption can
+		// only be cancelled here
+		switch {
+		case *req.Status == entities.AdoptionStatusCompleted:
+			return nil, errors.NewBadRequest("complete adoptions with POST /adoptions/:id/finalize")
+		case *req.Status == entities.AdoptionStatusReturned:
+			return nil, errors.NewBadRequest("record returns with POST /adoptions/:id/return")
+		case *req.Status != entities.AdoptionStatusCancelled || adoption.Status != entities.AdoptionStatusPending:
+			return nil, errors.NewBadRequest("only pending adoptions can be cancelled")
+		}
 		changes["status"] = *req.Status
 		adoption.Status = *req.Status
-
-		// Handle animal status update if adoption is returned
-		if *req.Status == entities.AdoptionStatusReturned {
-			animal, _ := uc.animalRepo.FindByID(ctx, adoption.AnimalID)
-			if animal != nil {
-				animal.Status = entities.AnimalStatusAvailable
-				_ = uc.animalRepo.Update(ctx, animal)
-			}
-		}
 	}
 
 	if req.PaymentStatus != nil {
@@ -493,6 +575,90 @@ func (uc *AdoptionUseCase) UpdateAdoption(ctx context.Context, id primitive.Obje
 	return adoption, nil
 }
 
+// FinalizeAdoption completes a pending adoption and registers the adopter as the microchip owner
+func (uc *AdoptionUseCase) FinalizeAdoption(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) (*entities.Adoption, error) {
+	adoption, err := uc.adoptionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if adoption.Status != entities.AdoptionStatusPending {
+		return nil, errors.NewBadRequest("only pending adoptions can be finalized")
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkNotQuarantined(ctx, animal); err != nil {
+		return nil, err
+	}
+
+	// The animal is marked adopted first, so a failure leaves the adoption pending and
+	// the finalization can be retried
+	before := *animal
+	animal.MarkAsAdopted(adoption.AdopterID, adoption.AdoptionDate)
+	animal.UpdatedBy = userID
+	if err := uc.animalRepo.Update(ctx, animal); err != nil {
+		return nil, err
+	}
+
+	completedAt := time.Now()
+	adoption.Status = entities.AdoptionStatusCompleted
+	adoption.CompletedDate = &completedAt
+	adoption.UpdatedBy = userID
+
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		// Put the animal back as it was so it is not shown as adopted by a pending adoption
+		if restoreErr := uc.animalRepo.Update(ctx, &before); restoreErr != nil {
+			log.Error().Err(restoreErr).Str("animal_id", animal.ID.Hex()).Str("adoption_id", adoption.ID.Hex()).
+				Msg("failed to restore the animal after the adoption could not be finalized")
+		}
+		return nil, err
+	}
+
+	changes := map[string]interface{}{"status": adoption.Status}
+
+	// Transfer the microchip registration to the adopter; a registry outage must not block the adoption
+	if animal.Medical.MicrochipNumber != "" && uc.chipOwners != nil {
+		if err := uc.registerAdopterAsOwner(ctx, adoption, userID); err != nil {
+			changes["microchip_registration"] = "failed: " + err.Error()
+		} else {
+			changes["microchip_registration"] = "transferred"
+		}
+	}
+
+	uc.closeTrialTask(ctx, adoption, userID)
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "adoption", "", "").
+		WithEntityID(id).
+		WithChanges(changes)
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return adoption, nil
+}
+
+// registerAdopterAsOwner registers the applicant of the adoption as the owner of the
+// animal's microchip
+func (uc *AdoptionUseCase) registerAdopterAsOwner(ctx context.Context, adoption *entities.Adoption, userID primitive.ObjectID) error {
+	application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID)
+	if err != nil {
+		return err
+	}
+
+	address := application.Address
+	owner := microchip.Owner{
+		Name:    strings.TrimSpace(application.Applicant.FirstName + " " + application.Applicant.LastName),
+		Email:   application.Applicant.Email,
+		Phone:   application.Applicant.Phone,
+		Address: strings.TrimSpace(fmt.Sprintf("%s, %s %s, %s", address.Street, address.ZipCode, address.City, address.Country)),
+	}
+
+	_, err = uc.chipOwners.RegisterMicrochipOwner(ctx, adoption.AnimalID, owner, userID)
+	return err
+}
+
 // DeleteAdoption deletes an adoption
 func (uc *AdoptionUseCase) DeleteAdoption(ctx context.Context, id primitive.ObjectID, deleterID primitive.ObjectID) error {
 	if _, err := uc.adoptionRepo.FindByID(ctx, id); err != nil {
@@ -536,6 +702,10 @@ func (uc *AdoptionUseCase) ListAdoptions(ctx context.Context, req *ListAdoptions
 		}
 	}
 
+	if req.SterilizationStatus != "" {
+		filter.SterilizationStatuses = []string{req.SterilizationStatus}
+	}
+
 	if req.AdopterID != "" {
 		adopterID, err := primitive.ObjectIDFromHex(req.AdopterID)
 		if err == nil {
@@ -567,3 +737,19 @@ func (uc *AdoptionUseCase) GetPendingFollowUps(ctx context.Context, days int) ([
 func (uc *AdoptionUseCase) GetAdoptionStatistics(ctx context.Context) (*repositories.AdoptionStatistics, error) {
 	return uc.adoptionRepo.GetAdoptionStatistics(ctx)
 }
+
+// checkNotQuarantined refuses the adoption of an animal in quarantine
+func (uc *AdoptionUseCase) checkNotQuarantined(ctx context.Context, animal *entities.Animal) error {
+	quarantined := animal.IsQuarantined()
+	if !quarantined && uc.quarantines != nil {
+		active, err := uc.quarantines.HasActiveQuarantine(ctx, animal.ID)
+		if err != nil {
+			return err
+		}
+		quarantined = active
+	}
+	if quarantined {
+		return errors.NewConflict("animal is in quarantine and cannot be adopted until it is released")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package adoption
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFinalizeAdoption_RestoresTheAnimalWhenTheAdoptionIsNotSaved(t *testing.T) {
+	adoptions := new(mocks.AdoptionRepository)
+	animals := new(mocks.AnimalRepository)
+	uc := NewAdoptionUseCase(nil, adoptions, animals, new(mocks.AuditLogRepository), nil, nil, nil, nil, nil, nil, nil)
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Status: entities.AnimalStatusAvailable}
+	adoption := entities.NewAdoption(primitive.NewObjectID(), animal.ID, primitive.NewObjectID(), 150, primitive.NewObjectID())
+	adoption.ID = primitive.NewObjectID()
+
+	adoptions.On("FindByID", mock.Anything, adoption.ID).Return(adoption, nil)
+	animals.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	var saved []entities.AnimalStatus
+	animals.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(1).(*entities.Animal).Status)
+	}).Return(nil)
+	adoptions.On("Update", mock.Anything, adoption).Return(errors.NewInternalServer("write failed"))
+
+	_, err := uc.FinalizeAdoption(context.Background(), adoption.ID, primitive.NewObjectID())
+
+	assert.Error(t, err)
+	assert.Equal(t, []entities.AnimalStatus{entities.AnimalStatusAdopted, entities.AnimalStatusAvailable}, saved)
+}
+
+func TestUpdateAdoption_OnlyCancelsPendingAdoptions(t *testing.T) {
+	adoptions := new(mocks.AdoptionRepository)
+	auditLogs := new(mocks.AuditLogRepository)
+	auditLogs.On("Create", mock.Anything, mock.Anything).Return(nil)
+	uc := NewAdoptionUseCase(nil, adoptions, nil, auditLogs, nil, nil, nil, nil, nil, nil, nil)
+
+	adoption := entities.NewAdoption(primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), 150, primitive.NewObjectID())
+	adoption.ID = primitive.NewObjectID()
+	adoptions.On("FindByID", mock.Anything, adoption.ID).Return(adoption, nil)
+	adoptions.On("Update", mock.Anything, adoption).Return(nil)
+
+	for _, status := range []entities.AdoptionStatus{entities.AdoptionStatusCompleted, entities.AdoptionStatusReturned} {
+		_, err := uc.UpdateAdoption(context.Background(), adoption.ID, &UpdateAdoptionRequest{Status: &status}, primitive.NewObjectID())
+		assert.Error(t, err, status)
+	}
+	adoptions.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	cancelled := entities.AdoptionStatusCancelled
+	result, err := uc.UpdateAdoption(context.Background(), adoption.ID, &UpdateAdoptionRequest{Status: &cancelled}, primitive.NewObjectID())
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AdoptionStatusCancelled, result.Status)
+
+	pending := entities.AdoptionStatusPending
+	_, err = uc.UpdateAdoption(context.Background(), adoption.ID, &UpdateAdoptionRequest{Status: &pending}, primitive.NewObjectID())
+	assert.Error(t, err, "a cancelled adoption stays cancelled")
+}
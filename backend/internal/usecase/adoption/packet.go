@@ -0,0 +1,35 @@
+package adoption
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MedicalPacketGenerator stores the printable medical history of an animal as a document
+type MedicalPacketGenerator interface {
+	GeneratePacket(ctx context.Context, animalID primitive.ObjectID, language string, userID primitive.ObjectID) (*entities.Document, error)
+}
+
+// attachMedicalPacket generates the medical packet of the adopted animal and
+// adds the document ID to the adoption attachments. The adopter can be handed
+// a packet generated later, so a failure is logged and the adoption goes ahead.
+func (uc *AdoptionUseCase) attachMedicalPacket(ctx context.Context, adoption *entities.Adoption, userID primitive.ObjectID) {
+	if uc.packets == nil {
+		return
+	}
+
+	document, err := uc.packets.GeneratePacket(ctx, adoption.AnimalID, "", userID)
+	if err != nil {
+		log.Error().Err(err).Str("adoption_id", adoption.ID.Hex()).Msg("failed to generate the medical packet of an adoption")
+		return
+	}
+
+	adoption.Attachments = append(adoption.Attachments, document.ID.Hex())
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		log.Error().Err(err).Str("adoption_id", adoption.ID.Hex()).Str("document_id", document.ID.Hex()).
+			Msg("failed to attach the medical packet to the adoption")
+	}
+}
@@ -0,0 +1,328 @@
+package adoption
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TrialTag marks the tasks asking staff to decide on an ended trial adoption
+const TrialTag = "adoption-trial"
+
+// trialDecisionDays is the time staff get to decide on an ended trial
+const trialDecisionDays = 3
+
+// ReturnIntakeReason is the intake reason of the shelter stay started by a return
+const ReturnIntakeReason = "adoption_return"
+
+// ReturnAdoptionRequest represents a request to record the return of an adopted animal
+type ReturnAdoptionRequest struct {
+	ReturnDate      *time.Time                    `json:"return_date,omitempty"` // Defaults to now
+	Category        entities.ReturnReasonCategory `json:"category" validate:"required,oneof=behavior health allergies housing incompatible_pets children time financial owner_health other"`
+	Details         string                        `json:"details,omitempty"`
+	AnimalCondition string                        `json:"animal_condition,omitempty"`
+	Location        string                        `json:"location,omitempty"` // Where the animal is housed on return
+}
+
+// GetReturnPolicy returns the configured return policy, or the default one
+func (uc *AdoptionUseCase) GetReturnPolicy(ctx context.Context) *entities.AdoptionReturnPolicy {
+	if uc.settingsRepo == nil {
+		return entities.DefaultAdoptionReturnPolicy()
+	}
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil || settings.ReturnPolicy == nil {
+		return entities.DefaultAdoptionReturnPolicy()
+	}
+	return settings.ReturnPolicy
+}
+
+// UpdateReturnPolicy replaces the return policy; it applies to later returns and trial ends
+func (uc *AdoptionUseCase) UpdateReturnPolicy(ctx context.Context, policy *entities.AdoptionReturnPolicy, userID primitive.ObjectID) (*entities.AdoptionReturnPolicy, error) {
+	if uc.settingsRepo == nil {
+		return nil, errors.NewNotFound("settings not initialized")
+	}
+
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings.ReturnPolicy = policy
+	settings.UpdatedBy = userID
+	if err := uc.settingsRepo.Update(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "settings", "return_policy", "Updated the adoption return policy").
+			WithEntityID(settings.ID))
+
+	return policy, nil
+}
+
+// ReturnAdoption records the return of an adopted animal. The fee is refunded or forfeited
+// under the return policy and the animal starts a new stay at the shelter.
+func (uc *AdoptionUseCase) ReturnAdoption(ctx context.Context, id primitive.ObjectID, req *ReturnAdoptionRequest, userID primitive.ObjectID) (*entities.Adoption, error) {
+	adoption, err := uc.adoptionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adoption.Status != entities.AdoptionStatusPending && adoption.Status != entities.AdoptionStatusCompleted {
+		return nil, errors.NewConflict(fmt.Sprintf("adoption is already %s", adoption.Status))
+	}
+
+	now := time.Now()
+	returnDate := now
+	if req.ReturnDate != nil {
+		returnDate = *req.ReturnDate
+	}
+	if returnDate.Before(adoption.AdoptionDate) {
+		return nil, errors.NewBadRequest("return date is before the adoption date")
+	}
+	if returnDate.After(now) {
+		return nil, errors.NewBadRequest("return date cannot be in the future")
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID)
+	if err != nil {
+		return nil, err
+	}
+
+	duringTrial := adoption.TrialPeriod && adoption.Status == entities.AdoptionStatusPending &&
+		adoption.TrialEndDate != nil && !returnDate.After(*adoption.TrialEndDate)
+	days := int(returnDate.Sub(adoption.AdoptionDate).Hours() / 24)
+	refund, amount, rule := uc.GetReturnPolicy(ctx).Refund(adoption.AmountPaid, duringTrial, days)
+
+	adoption.Return = &entities.AdoptionReturn{
+		ReturnDate:        returnDate,
+		Category:          req.Category,
+		Details:           req.Details,
+		AnimalCondition:   req.AnimalCondition,
+		DuringTrial:       duringTrial,
+		DaysAfterAdoption: days,
+		Refund:            refund,
+		RefundAmount:      amount,
+		ForfeitedAmount:   math.Max(adoption.AmountPaid-amount, 0),
+		RefundRule:        rule,
+		RecordedBy:        userID,
+	}
+	previous := adoption.Status
+	adoption.Status = entities.AdoptionStatusReturned
+	adoption.ReturnDate = &returnDate
+	adoption.ReturnReason = string(req.Category)
+	adoption.ReturnNotes = req.Details
+	if amount > 0 {
+		adoption.PaymentStatus = entities.PaymentStatusRefunded
+	}
+	if adoption.Sterilization != nil && adoption.Sterilization.IsOpen() {
+		adoption.Sterilization.Waive("adoption returned", userID)
+	}
+	adoption.UpdatedBy = userID
+
+	// The animal is readmitted first, so a failure leaves the adoption as it was and the
+	// return can be recorded again. Readmitting closes the last housing stay in place, so
+	// the copy kept to restore the animal gets its own history.
+	before := *animal
+	before.Shelter.LocationHistory = append([]entities.LocationStay(nil), animal.Shelter.LocationHistory...)
+	animal.Readmit(ReturnIntakeReason, adoption, req.Location, returnDate)
+	animal.UpdatedBy = userID
+	if err := uc.animalRepo.Update(ctx, animal); err != nil {
+		return nil, err
+	}
+
+	// Only the request that moves the adoption out of the status it was read with records
+	// the return, otherwise two concurrent returns would both be recorded
+	saved, err := uc.adoptionRepo.UpdateIfStatus(ctx, adoption, previous)
+	if err != nil || !saved {
+		if restoreErr := uc.animalRepo.Update(ctx, &before); restoreErr != nil {
+			log.Error().Err(restoreErr).Str("animal_id", animal.ID.Hex()).Str("adoption_id", adoption.ID.Hex()).
+				Msg("failed to restore the animal after the adoption return could not be recorded")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.NewConflict("the adoption was changed by someone else, please try again")
+	}
+
+	uc.closeTrialTask(ctx, adoption, userID)
+
+	changes := map[string]interface{}{
+		"status":        adoption.Status,
+		"category":      req.Category,
+		"refund":        refund,
+		"refund_amount": amount,
+	}
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "adoption", "", "Animal returned: "+string(req.Category)).
+			WithEntityID(id).
+			WithChanges(changes))
+
+	return adoption, nil
+}
+
+// ProcessTrialEnds is the scheduler job for pending trial adoptions whose trial has ended.
+// They are finalized when the return policy says so; otherwise, or when finalizing fails,
+// the staff member who processed the adoption gets a task to finalize or record the return.
+func (uc *AdoptionUseCase) ProcessTrialEnds(ctx context.Context) error {
+	now := time.Now()
+	adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		Status:          string(entities.AdoptionStatusPending),
+		TrialEndsBefore: &now,
+		SortBy:          "trial_end_date",
+		SortOrder:       "asc",
+	})
+	if err != nil {
+		return err
+	}
+
+	policy := uc.GetReturnPolicy(ctx)
+	for _, adoption := range adoptions {
+		if policy.TrialAutoFinalize {
+			if _, err := uc.FinalizeAdoption(ctx, adoption.ID, adoption.ProcessedBy); err == nil {
+				continue
+			}
+		}
+		uc.promptTrialEnd(ctx, adoption, now)
+	}
+	return nil
+}
+
+// promptTrialEnd creates the task asking staff to decide on an ended trial unless one exists
+func (uc *AdoptionUseCase) promptTrialEnd(ctx context.Context, adoption *entities.Adoption, now time.Time) {
+	if uc.taskRepo == nil {
+		return
+	}
+	if adoption.TrialTaskID != nil {
+		task, err := uc.taskRepo.FindByID(ctx, *adoption.TrialTaskID)
+		if err != nil || task.Status != entities.TaskStatusCancelled {
+			return
+		}
+	}
+
+	name := adoption.AnimalID.Hex()
+	if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+		if animal.Name.English != "" {
+			name = animal.Name.English
+		} else if animal.Name.Polish != "" {
+			name = animal.Name.Polish
+		}
+	}
+
+	task := entities.NewTask(fmt.Sprintf("Trial adoption of %s ended: finalize or record the return", name), entities.TaskCategoryAdoption, entities.TaskPriorityHigh, adoption.ProcessedBy)
+	task.Description = fmt.Sprintf("The trial of %s ended on %s.", name, adoption.TrialEndDate.Format("2006-01-02"))
+	task.RelatedEntity = "adoption"
+	task.RelatedEntityID = &adoption.ID
+	task.Tags = []string{TrialTag, TrialTag + ":" + adoption.ID.Hex()}
+	dueDate := now.AddDate(0, 0, trialDecisionDays)
+	task.DueDate = &dueDate
+	if !adoption.ProcessedBy.IsZero() {
+		task.AssignTo(adoption.ProcessedBy)
+	}
+	task.AddChecklistItem("Contact the adopter")
+	task.AddChecklistItem("Finalize the adoption or record the return")
+
+	if err := uc.taskRepo.Create(ctx, task); err != nil {
+		return
+	}
+	adoption.TrialTaskID = &task.ID
+	_ = uc.adoptionRepo.Update(ctx, adoption)
+}
+
+// closeTrialTask completes the open trial task once the adoption is finalized or returned
+func (uc *AdoptionUseCase) closeTrialTask(ctx context.Context, adoption *entities.Adoption, userID primitive.ObjectID) {
+	if uc.taskRepo == nil || adoption.TrialTaskID == nil {
+		return
+	}
+	task, err := uc.taskRepo.FindByID(ctx, *adoption.TrialTaskID)
+	if err != nil || task.Status == entities.TaskStatusCompleted || task.Status == entities.TaskStatusCancelled {
+		return
+	}
+	task.Complete(userID)
+	_ = uc.taskRepo.Update(ctx, task)
+}
+
+// ReturnReport summarizes returns in a period for analytics
+type ReturnReport struct {
+	From                     time.Time                             `json:"from"`
+	To                       time.Time                             `json:"to"`
+	Returns                  int                                   `json:"returns"`
+	DuringTrial              int                                   `json:"during_trial"`
+	AverageDaysAfterAdoption float64                               `json:"average_days_after_adoption"`
+	ByCategory               map[entities.ReturnReasonCategory]int `json:"by_category"`
+	ByRefund                 map[entities.RefundDecision]int       `json:"by_refund"`
+	RefundedAmount           float64                               `json:"refunded_amount"`
+	ForfeitedAmount          float64                               `json:"forfeited_amount"`
+	Adoptions                int                                   `json:"adoptions"`          // Adoptions made in the period
+	ReturnedAdoptions        int                                   `json:"returned_adoptions"` // Of those, returned since
+	ReturnRate               float64                               `json:"return_rate"`        // Percentage of the adoptions made in the period
+}
+
+// GetReturnReport reports the returns recorded in a period and the return rate of the
+// adoptions made in it
+func (uc *AdoptionUseCase) GetReturnReport(ctx context.Context, from, to time.Time) (*ReturnReport, error) {
+	returned, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		Status:         string(entities.AdoptionStatusReturned),
+		ReturnFromDate: &from,
+		ReturnToDate:   &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	adopted, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		FromDate: &from,
+		ToDate:   &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReturnReport{
+		From:       from,
+		To:         to,
+		Returns:    len(returned),
+		ByCategory: make(map[entities.ReturnReasonCategory]int),
+		ByRefund:   make(map[entities.RefundDecision]int),
+		Adoptions:  len(adopted),
+	}
+
+	totalDays := 0
+	for _, adoption := range returned {
+		record := adoption.Return
+		if record == nil {
+			// Returns recorded before structured reasons existed
+			if adoption.ReturnDate != nil {
+				totalDays += int(adoption.ReturnDate.Sub(adoption.AdoptionDate).Hours() / 24)
+			}
+			report.ByCategory[entities.ReturnReasonOther]++
+			continue
+		}
+		totalDays += record.DaysAfterAdoption
+		report.ByCategory[record.Category]++
+		report.ByRefund[record.Refund]++
+		report.RefundedAmount += record.RefundAmount
+		report.ForfeitedAmount += record.ForfeitedAmount
+		if record.DuringTrial {
+			report.DuringTrial++
+		}
+	}
+	if report.Returns > 0 {
+		report.AverageDaysAfterAdoption = math.Round(float64(totalDays)/float64(report.Returns)*10) / 10
+	}
+
+	for _, adoption := range adopted {
+		if adoption.Status == entities.AdoptionStatusReturned {
+			report.ReturnedAdoptions++
+		}
+	}
+	if report.Adoptions > 0 {
+		report.ReturnRate = math.Round(float64(report.ReturnedAdoptions)/float64(report.Adoptions)*1000) / 10
+	}
+
+	return report, nil
+}
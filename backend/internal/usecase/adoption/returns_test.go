@@ -0,0 +1,203 @@
+package adoption
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type returnMocks struct {
+	adoptions *mocks.AdoptionRepository
+	animals   *mocks.AnimalRepository
+	tasks     *mocks.TaskRepository
+}
+
+func newReturnUseCase() (*AdoptionUseCase, *returnMocks) {
+	m := &returnMocks{
+		adoptions: new(mocks.AdoptionRepository),
+		animals:   new(mocks.AnimalRepository),
+		tasks:     new(mocks.TaskRepository),
+	}
+	auditLogs := testutil.AuditLogs()
+	m.adoptions.On("Update", mock.Anything, mock.Anything).Return(nil)
+	m.animals.On("Update", mock.Anything, mock.Anything).Return(nil)
+	return NewAdoptionUseCase(nil, m.adoptions, m.animals, auditLogs, nil, nil, nil, nil, m.tasks, nil, nil), m
+}
+
+// adopted registers an animal adopted the given number of days ago for a paid fee
+func adopted(m *returnMocks, daysAgo int, fee float64) (*entities.Adoption, *entities.Animal) {
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Status: entities.AnimalStatusAdopted}
+	adoptionDate := time.Now().AddDate(0, 0, -daysAgo)
+	animal.Shelter.IntakeDate = adoptionDate.AddDate(0, -2, 0)
+	animal.Shelter.IntakeReason = "stray"
+	animal.Shelter.Location = "Kennel 4"
+
+	adoption := entities.NewAdoption(primitive.NewObjectID(), animal.ID, primitive.NewObjectID(), fee, primitive.NewObjectID())
+	adoption.ID = primitive.NewObjectID()
+	adoption.AdoptionDate = adoptionDate
+	adoption.AmountPaid = fee
+	adoption.PaymentStatus = entities.PaymentStatusPaid
+
+	m.adoptions.On("FindByID", mock.Anything, adoption.ID).Return(adoption, nil)
+	m.animals.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	return adoption, animal
+}
+
+func TestReturnPolicy_Refund(t *testing.T) {
+	policy := entities.DefaultAdoptionReturnPolicy()
+
+	tests := []struct {
+		name        string
+		paid        float64
+		duringTrial bool
+		days        int
+		decision    entities.RefundDecision
+		amount      float64
+	}{
+		{"trial return refunds in full", 300, true, 10, entities.RefundFull, 300},
+		{"return within the window refunds in part", 300, false, 14, entities.RefundPartial, 150},
+		{"late return forfeits the fee", 300, false, 15, entities.RefundForfeited, 0},
+		{"nothing paid", 0, false, 3, entities.RefundNone, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, amount, rule := policy.Refund(tt.paid, tt.duringTrial, tt.days)
+			assert.Equal(t, tt.decision, decision)
+			assert.Equal(t, tt.amount, amount)
+			assert.NotEmpty(t, rule)
+		})
+	}
+}
+
+func TestReturnAdoption_StartsANewShelterStay(t *testing.T) {
+	uc, m := newReturnUseCase()
+	adoption, animal := adopted(m, 10, 300)
+	adoption.Status = entities.AdoptionStatusCompleted
+	userID := primitive.NewObjectID()
+	m.adoptions.On("UpdateIfStatus", mock.Anything, adoption, []entities.AdoptionStatus{entities.AdoptionStatusCompleted}).Return(true, nil)
+
+	result, err := uc.ReturnAdoption(context.Background(), adoption.ID, &ReturnAdoptionRequest{
+		Category: entities.ReturnReasonAllergies,
+		Details:  "Child developed an allergy",
+		Location: "Kennel 7",
+	}, userID)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.AdoptionStatusReturned, result.Status)
+	assert.Equal(t, entities.PaymentStatusRefunded, result.PaymentStatus)
+	require.NotNil(t, result.Return)
+	assert.Equal(t, entities.RefundPartial, result.Return.Refund)
+	assert.Equal(t, 150.0, result.Return.RefundAmount)
+	assert.Equal(t, 150.0, result.Return.ForfeitedAmount)
+	assert.Equal(t, 10, result.Return.DaysAfterAdoption)
+	assert.False(t, result.Return.DuringTrial)
+
+	assert.Equal(t, entities.AnimalStatusAvailable, animal.Status)
+	assert.Equal(t, ReturnIntakeReason, animal.Shelter.IntakeReason)
+	assert.Equal(t, "Kennel 7", animal.Shelter.Location)
+	require.Len(t, animal.Shelter.PreviousStays, 1)
+	assert.Equal(t, "stray", animal.Shelter.PreviousStays[0].IntakeReason)
+	assert.Equal(t, adoption.ID, *animal.Shelter.PreviousStays[0].AdoptionID)
+
+	_, err = uc.ReturnAdoption(context.Background(), adoption.ID, &ReturnAdoptionRequest{Category: entities.ReturnReasonOther}, userID)
+	assert.Equal(t, 409, appCode(err))
+}
+
+func TestReturnAdoption_RestoresTheAnimalWhenReturnedMeanwhile(t *testing.T) {
+	uc, m := newReturnUseCase()
+	adoption, animal := adopted(m, 10, 300)
+	adoption.Status = entities.AdoptionStatusCompleted
+	animal.Shelter.LocationHistory = []entities.LocationStay{{Location: "Kennel 4", From: animal.Shelter.IntakeDate}}
+	// A concurrent request recorded the return after this one read the adoption
+	m.adoptions.On("UpdateIfStatus", mock.Anything, adoption, []entities.AdoptionStatus{entities.AdoptionStatusCompleted}).Return(false, nil)
+
+	_, err := uc.ReturnAdoption(context.Background(), adoption.ID, &ReturnAdoptionRequest{Category: entities.ReturnReasonAllergies}, primitive.NewObjectID())
+	assert.Equal(t, 409, appCode(err))
+
+	calls := m.animals.Calls
+	restored := calls[len(calls)-1].Arguments.Get(1).(*entities.Animal)
+	assert.Equal(t, "Update", calls[len(calls)-1].Method)
+	assert.Equal(t, entities.AnimalStatusAdopted, restored.Status)
+	assert.Equal(t, "stray", restored.Shelter.IntakeReason)
+	assert.Empty(t, restored.Shelter.PreviousStays)
+	require.Len(t, restored.Shelter.LocationHistory, 1)
+	assert.Nil(t, restored.Shelter.LocationHistory[0].To, "the stay left open when adopted is not closed")
+}
+
+func TestProcessTrialEnds_PromptsStaff(t *testing.T) {
+	uc, m := newReturnUseCase()
+	adoption, _ := adopted(m, 20, 300)
+	adoption.TrialPeriod = true
+	trialEnd := time.Now().AddDate(0, 0, -1)
+	adoption.TrialEndDate = &trialEnd
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AdoptionFilter) bool {
+		return f.TrialEndsBefore != nil
+	})).Return([]*entities.Adoption{adoption}, int64(1), nil)
+
+	var created *entities.Task
+	m.tasks.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*entities.Task)
+		created.ID = primitive.NewObjectID()
+	}).Return(nil).Once()
+
+	require.NoError(t, uc.ProcessTrialEnds(context.Background()))
+	require.NotNil(t, created)
+	assert.Contains(t, created.Tags, TrialTag)
+	assert.Equal(t, created.ID, *adoption.TrialTaskID)
+	assert.Equal(t, entities.AdoptionStatusPending, adoption.Status)
+
+	// The open task is not duplicated on the next run
+	m.tasks.On("FindByID", mock.Anything, created.ID).Return(created, nil)
+	require.NoError(t, uc.ProcessTrialEnds(context.Background()))
+	m.tasks.AssertNumberOfCalls(t, "Create", 1)
+
+	// Returning the animal closes the task
+	m.tasks.On("Update", mock.Anything, created).Return(nil)
+	m.adoptions.On("UpdateIfStatus", mock.Anything, adoption, []entities.AdoptionStatus{entities.AdoptionStatusPending}).Return(true, nil)
+	_, err := uc.ReturnAdoption(context.Background(), adoption.ID, &ReturnAdoptionRequest{Category: entities.ReturnReasonBehavior}, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, entities.TaskStatusCompleted, created.Status)
+	assert.Equal(t, entities.RefundForfeited, adoption.Return.Refund)
+}
+
+func TestGetReturnReport(t *testing.T) {
+	uc, m := newReturnUseCase()
+	from := time.Now().AddDate(0, -1, 0)
+	to := time.Now()
+
+	returned := &entities.Adoption{
+		Status:       entities.AdoptionStatusReturned,
+		AdoptionDate: from.AddDate(0, 0, 1),
+		Return: &entities.AdoptionReturn{
+			Category:          entities.ReturnReasonHousing,
+			Refund:            entities.RefundFull,
+			RefundAmount:      200,
+			DuringTrial:       true,
+			DaysAfterAdoption: 5,
+		},
+	}
+	kept := &entities.Adoption{Status: entities.AdoptionStatusCompleted, AdoptionDate: from.AddDate(0, 0, 2)}
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AdoptionFilter) bool {
+		return f.ReturnFromDate != nil
+	})).Return([]*entities.Adoption{returned}, int64(1), nil)
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AdoptionFilter) bool {
+		return f.FromDate != nil
+	})).Return([]*entities.Adoption{returned, kept}, int64(2), nil)
+
+	report, err := uc.GetReturnReport(context.Background(), from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Returns)
+	assert.Equal(t, 1, report.DuringTrial)
+	assert.Equal(t, 1, report.ByCategory[entities.ReturnReasonHousing])
+	assert.Equal(t, 200.0, report.RefundedAmount)
+	assert.Equal(t, 50.0, report.ReturnRate)
+}
@@ -0,0 +1,670 @@
+package adoption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Messenger queues email and SMS messages to applicants
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// UpdateStageRequest represents a request to work on a review stage
+type UpdateStageRequest struct {
+	AssigneeID   *string         `json:"assignee_id,omitempty"`
+	Notes        *string         `json:"notes,omitempty"`
+	Checklist    map[string]bool `json:"checklist,omitempty"` // Checklist item ID -> done
+	AddChecklist []string        `json:"add_checklist,omitempty"`
+}
+
+// CompleteStageRequest represents a request to record the outcome of the current stage
+type CompleteStageRequest struct {
+	Outcome entities.ReviewStageStatus `json:"outcome" validate:"required,oneof=passed failed skipped"`
+	Notes   string                     `json:"notes,omitempty"`
+}
+
+// UpdateReferenceRequest represents a request to record a reference check
+type UpdateReferenceRequest struct {
+	Outcome entities.ReferenceOutcome `json:"outcome" validate:"required,oneof=positive neutral negative unreachable"`
+	Notes   *string                   `json:"notes,omitempty"`
+}
+
+// ScheduleVisitRequest represents a request to schedule a home visit or meet and greet
+type ScheduleVisitRequest struct {
+	Type        entities.ApplicationVisitType `json:"type,omitempty" validate:"omitempty,oneof=home_visit meet_and_greet"`
+	ScheduledAt time.Time                     `json:"scheduled_at" validate:"required"`
+	StaffID     string                        `json:"staff_id,omitempty"`
+	Location    string                        `json:"location,omitempty"`
+	Notes       string                        `json:"notes,omitempty"`
+}
+
+// VisitFinding represents an observation made during a visit
+type VisitFinding struct {
+	Item string `json:"item" validate:"required"`
+	Done bool   `json:"done"`
+}
+
+// RecordVisitRequest represents a request to record the result of a visit
+type RecordVisitRequest struct {
+	VisitID       string                        `json:"visit_id,omitempty"` // Defaults to the latest scheduled visit of the type
+	Type          entities.ApplicationVisitType `json:"type,omitempty" validate:"omitempty,oneof=home_visit meet_and_greet"`
+	VisitedAt     *time.Time                    `json:"visited_at,omitempty"`
+	Outcome       entities.ReviewStageStatus    `json:"outcome" validate:"required,oneof=passed failed"`
+	Findings      []VisitFinding                `json:"findings,omitempty" validate:"dive"`
+	Report        string                        `json:"report,omitempty"`
+	CompleteStage bool                          `json:"complete_stage"` // Record the visit outcome on the stage
+}
+
+// ApproveApplicationRequest represents a request to approve an application
+type ApproveApplicationRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// RejectApplicationRequest represents a request to reject an application
+type RejectApplicationRequest struct {
+	ReasonCode string `json:"reason_code" validate:"required"`
+	Notes      string `json:"notes,omitempty"`  // Added to the message to the applicant
+	Notify     *bool  `json:"notify,omitempty"` // Email the applicant, true by default
+}
+
+// GetReviewConfig returns the configured review pipeline, or the default one
+func (uc *AdoptionUseCase) GetReviewConfig(ctx context.Context) *entities.ApplicationReviewConfig {
+	if uc.settingsRepo == nil {
+		return entities.DefaultApplicationReviewConfig()
+	}
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil || settings.ApplicationReview == nil {
+		return entities.DefaultApplicationReviewConfig()
+	}
+	return settings.ApplicationReview
+}
+
+// UpdateReviewConfig replaces the review pipeline configuration. It applies to applications
+// whose review starts afterwards.
+func (uc *AdoptionUseCase) UpdateReviewConfig(ctx context.Context, config *entities.ApplicationReviewConfig, userID primitive.ObjectID) (*entities.ApplicationReviewConfig, error) {
+	if err := validateReviewConfig(config); err != nil {
+		return nil, err
+	}
+	if uc.settingsRepo == nil {
+		return nil, errors.NewNotFound("settings not initialized")
+	}
+
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings.ApplicationReview = config
+	settings.UpdatedBy = userID
+	if err := uc.settingsRepo.Update(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "settings", "application_review", "Updated the application review pipeline").
+			WithEntityID(settings.ID))
+
+	return config, nil
+}
+
+// validateReviewConfig checks that stages and rejection reasons are distinct and the decision is enabled
+func validateReviewConfig(config *entities.ApplicationReviewConfig) error {
+	seen := make(map[entities.ReviewStageKey]bool)
+	for i := range config.Stages {
+		stage := &config.Stages[i]
+		if seen[stage.Key] {
+			return errors.NewBadRequest(fmt.Sprintf("stage %s is configured twice", stage.Key))
+		}
+		seen[stage.Key] = true
+		if stage.Key == entities.ReviewStageDecision && !stage.Enabled {
+			return errors.NewBadRequest("the decision stage cannot be disabled")
+		}
+	}
+	if !seen[entities.ReviewStageDecision] {
+		return errors.NewBadRequest("the pipeline must end with the decision stage")
+	}
+
+	codes := make(map[string]bool)
+	for _, reason := range config.RejectionReasons {
+		if codes[reason.Code] {
+			return errors.NewBadRequest(fmt.Sprintf("rejection reason %s is configured twice", reason.Code))
+		}
+		codes[reason.Code] = true
+	}
+	return nil
+}
+
+// GetReview returns an application with its review; applications submitted before the
+// pipeline get a review started at their application date
+func (uc *AdoptionUseCase) GetReview(ctx context.Context, id primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	application, err := uc.applicationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Review == nil && application.CanBeReviewed() {
+		uc.startReview(ctx, application, application.CreatedBy)
+	}
+	return application, nil
+}
+
+// startReview creates the review of an application from the pipeline configuration
+func (uc *AdoptionUseCase) startReview(ctx context.Context, application *entities.AdoptionApplication, userID primitive.ObjectID) {
+	application.Review = entities.NewApplicationReview(uc.GetReviewConfig(ctx), application.ApplicationDate)
+	skipNotApplicable(application, userID, application.ApplicationDate)
+}
+
+// skipNotApplicable skips the landlord verification of applicants who do not rent
+func skipNotApplicable(application *entities.AdoptionApplication, userID primitive.ObjectID, now time.Time) {
+	review := application.Review
+	for {
+		current := review.Current()
+		if current == nil || current.Key != entities.ReviewStageLandlordVerification ||
+			application.Housing.Ownership == entities.OwnershipRented {
+			return
+		}
+		current.Notes = "Skipped: the applicant does not rent"
+		review.Finish(current.Key, entities.ReviewStageSkipped, userID, now)
+	}
+}
+
+// reviewable loads an application that is still under review and makes sure it has a review
+func (uc *AdoptionUseCase) reviewable(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID, now time.Time) (*entities.AdoptionApplication, error) {
+	application, err := uc.applicationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !application.CanBeReviewed() {
+		return nil, errors.NewConflict(fmt.Sprintf("application is already %s", application.Status))
+	}
+	if application.Review == nil {
+		uc.startReview(ctx, application, userID)
+	}
+	if application.Status == entities.ApplicationStatusPending {
+		application.Status = entities.ApplicationStatusUnderReview
+		application.ReviewDate = &now
+	}
+	application.UpdatedBy = userID
+	return application, nil
+}
+
+// reviewStage returns a stage of the review that has no outcome yet
+func reviewStage(application *entities.AdoptionApplication, key entities.ReviewStageKey) (*entities.ReviewStage, error) {
+	stage := application.Review.Stage(key)
+	if stage == nil {
+		return nil, errors.NewNotFound(fmt.Sprintf("stage %s is not part of this review", key))
+	}
+	if stage.Status.IsFinished() {
+		return nil, errors.NewConflict(fmt.Sprintf("stage %s is already %s", key, stage.Status))
+	}
+	return stage, nil
+}
+
+// saveReview stores the application and records the change in the audit log
+func (uc *AdoptionUseCase) saveReview(ctx context.Context, application *entities.AdoptionApplication, userID primitive.ObjectID, description string, changes map[string]interface{}) error {
+	if err := uc.applicationRepo.Update(ctx, application); err != nil {
+		return err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "adoption_application", application.Applicant.FirstName+" "+application.Applicant.LastName, description).
+			WithEntityID(application.ID).
+			WithChanges(changes))
+	return nil
+}
+
+// UpdateStage assigns a stage, ticks checklist items and records notes
+func (uc *AdoptionUseCase) UpdateStage(ctx context.Context, id primitive.ObjectID, key entities.ReviewStageKey, req *UpdateStageRequest, userID primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	stage, err := reviewStage(application, key)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{"stage": key}
+	if req.AssigneeID != nil {
+		if *req.AssigneeID == "" {
+			stage.AssigneeID = nil
+		} else {
+			assigneeID, err := primitive.ObjectIDFromHex(*req.AssigneeID)
+			if err != nil {
+				return nil, errors.NewBadRequest("invalid assignee ID")
+			}
+			stage.AssigneeID = &assigneeID
+		}
+		changes["assignee_id"] = *req.AssigneeID
+	}
+	if req.Notes != nil {
+		stage.Notes = *req.Notes
+	}
+	for _, text := range req.AddChecklist {
+		if text = strings.TrimSpace(text); text != "" {
+			stage.Checklist = append(stage.Checklist, entities.ChecklistItem{ID: primitive.NewObjectID().Hex(), Text: text})
+		}
+	}
+	for itemID, done := range req.Checklist {
+		item := checklistItem(stage.Checklist, itemID)
+		if item == nil {
+			return nil, errors.NewBadRequest(fmt.Sprintf("checklist item %s not found", itemID))
+		}
+		setChecklistItem(item, done, userID, now)
+	}
+	if len(req.Checklist) > 0 {
+		changes["checklist"] = req.Checklist
+	}
+
+	if err := uc.saveReview(ctx, application, userID, "Updated review stage "+string(key), changes); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// checklistItem returns the checklist item with the ID, or nil
+func checklistItem(checklist []entities.ChecklistItem, id string) *entities.ChecklistItem {
+	for i := range checklist {
+		if checklist[i].ID == id {
+			return &checklist[i]
+		}
+	}
+	return nil
+}
+
+// setChecklistItem ticks or unticks a checklist item
+func setChecklistItem(item *entities.ChecklistItem, done bool, userID primitive.ObjectID, now time.Time) {
+	item.IsCompleted = done
+	if done {
+		item.CompletedAt = &now
+		item.CompletedBy = &userID
+	} else {
+		item.CompletedAt = nil
+		item.CompletedBy = nil
+	}
+}
+
+// CompleteStage records the outcome of the current stage and moves the application to the
+// next one. A failed stage skips the remaining checks and leaves the application for decision.
+func (uc *AdoptionUseCase) CompleteStage(ctx context.Context, id primitive.ObjectID, key entities.ReviewStageKey, req *CompleteStageRequest, userID primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	if key == entities.ReviewStageDecision {
+		return nil, errors.NewBadRequest("the decision is made by approving or rejecting the application")
+	}
+
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.completeStage(application, key, req.Outcome, req.Notes, userID, now); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{"stage": key, "outcome": req.Outcome}
+	if err := uc.saveReview(ctx, application, userID, fmt.Sprintf("Review stage %s %s", key, req.Outcome), changes); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// completeStage finishes the current stage of the review
+func (uc *AdoptionUseCase) completeStage(application *entities.AdoptionApplication, key entities.ReviewStageKey, outcome entities.ReviewStageStatus, notes string, userID primitive.ObjectID, now time.Time) error {
+	stage, err := reviewStage(application, key)
+	if err != nil {
+		return err
+	}
+	review := application.Review
+	if review.CurrentStage != key {
+		return errors.NewConflict(fmt.Sprintf("only the current stage (%s) can be completed", review.CurrentStage))
+	}
+	if outcome == entities.ReviewStagePassed && !stage.ChecklistComplete() {
+		var open []string
+		for _, item := range stage.Checklist {
+			if !item.IsCompleted {
+				open = append(open, item.Text)
+			}
+		}
+		return errors.NewBadRequest("checklist items are still open: " + strings.Join(open, ", "))
+	}
+
+	if notes != "" {
+		stage.Notes = notes
+	}
+	review.Finish(key, outcome, userID, now)
+	if outcome == entities.ReviewStageFailed {
+		review.SkipRemaining(userID, now)
+	}
+	skipNotApplicable(application, userID, now)
+	return nil
+}
+
+// UpdateReference records the result of contacting a reference
+func (uc *AdoptionUseCase) UpdateReference(ctx context.Context, id primitive.ObjectID, index int, req *UpdateReferenceRequest, userID primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(application.References) {
+		return nil, errors.NewNotFound("reference not found")
+	}
+
+	reference := &application.References[index]
+	reference.Contacted = true
+	reference.ContactedAt = &now
+	reference.ContactedBy = &userID
+	reference.Outcome = req.Outcome
+	if req.Notes != nil {
+		reference.Notes = *req.Notes
+	}
+
+	changes := map[string]interface{}{"reference": reference.Name, "outcome": req.Outcome}
+	if err := uc.saveReview(ctx, application, userID, "Checked reference "+reference.Name, changes); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// ScheduleVisit schedules a home visit or meet and greet
+func (uc *AdoptionUseCase) ScheduleVisit(ctx context.Context, id primitive.ObjectID, req *ScheduleVisitRequest, userID primitive.ObjectID) (*entities.ApplicationVisit, error) {
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	visitType := req.Type
+	if visitType == "" {
+		visitType = entities.ApplicationVisitHome
+	}
+	if visitType == entities.ApplicationVisitHome && !application.AgreesToHomeVisit {
+		return nil, errors.NewBadRequest("the applicant did not agree to a home visit")
+	}
+	stage, err := reviewStage(application, visitType.Stage())
+	if err != nil {
+		return nil, err
+	}
+
+	visit := entities.ApplicationVisit{
+		ID:          primitive.NewObjectID(),
+		Type:        visitType,
+		Status:      entities.ApplicationVisitScheduled,
+		ScheduledAt: req.ScheduledAt,
+		Location:    req.Location,
+		Notes:       req.Notes,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+	}
+	if req.StaffID != "" {
+		staffID, err := primitive.ObjectIDFromHex(req.StaffID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid staff ID")
+		}
+		visit.StaffID = &staffID
+		if stage.AssigneeID == nil {
+			stage.AssigneeID = &staffID
+		}
+	}
+	if visitType == entities.ApplicationVisitHome {
+		application.HomeVisitDate = &visit.ScheduledAt
+	}
+	application.Visits = append(application.Visits, visit)
+
+	changes := map[string]interface{}{"visit": visitType, "scheduled_at": req.ScheduledAt}
+	if err := uc.saveReview(ctx, application, userID, "Scheduled "+string(visitType), changes); err != nil {
+		return nil, err
+	}
+	return &application.Visits[len(application.Visits)-1], nil
+}
+
+// RecordVisit records what was found during a visit. Findings tick the matching items of the
+// stage checklist, and the visit outcome can complete the stage when it is the current one.
+func (uc *AdoptionUseCase) RecordVisit(ctx context.Context, id primitive.ObjectID, req *RecordVisitRequest, userID primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	visit, err := findVisit(application, req.VisitID, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if visit == nil {
+		// A visit that was not scheduled in the system
+		visitType := req.Type
+		if visitType == "" {
+			visitType = entities.ApplicationVisitHome
+		}
+		application.Visits = append(application.Visits, entities.ApplicationVisit{
+			ID:          primitive.NewObjectID(),
+			Type:        visitType,
+			ScheduledAt: now,
+			CreatedBy:   userID,
+			CreatedAt:   now,
+		})
+		visit = &application.Visits[len(application.Visits)-1]
+	}
+	stage, err := reviewStage(application, visit.Type.Stage())
+	if err != nil {
+		return nil, err
+	}
+
+	visitedAt := now
+	if req.VisitedAt != nil {
+		visitedAt = *req.VisitedAt
+	}
+	visit.Status = entities.ApplicationVisitCompleted
+	visit.VisitedAt = &visitedAt
+	visit.Outcome = req.Outcome
+	visit.Report = req.Report
+	visit.RecordedBy = &userID
+	visit.Findings = nil
+	for _, finding := range req.Findings {
+		item := entities.ChecklistItem{ID: primitive.NewObjectID().Hex(), Text: finding.Item}
+		setChecklistItem(&item, finding.Done, userID, now)
+		visit.Findings = append(visit.Findings, item)
+		for i := range stage.Checklist {
+			if strings.EqualFold(stage.Checklist[i].Text, finding.Item) {
+				setChecklistItem(&stage.Checklist[i], finding.Done, userID, now)
+			}
+		}
+	}
+	if visit.Type == entities.ApplicationVisitHome {
+		application.HomeVisitDate = &visitedAt
+		application.HomeVisitNotes = req.Report
+	}
+
+	if req.CompleteStage {
+		if err := uc.completeStage(application, stage.Key, req.Outcome, req.Report, userID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := map[string]interface{}{"visit": visit.ID, "outcome": req.Outcome}
+	if err := uc.saveReview(ctx, application, userID, "Recorded "+string(visit.Type), changes); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// findVisit returns the visit with the ID, or the latest scheduled visit of the type
+func findVisit(application *entities.AdoptionApplication, visitID string, visitType entities.ApplicationVisitType) (*entities.ApplicationVisit, error) {
+	if visitID != "" {
+		id, err := primitive.ObjectIDFromHex(visitID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid visit ID")
+		}
+		for i := range application.Visits {
+			if application.Visits[i].ID == id {
+				if application.Visits[i].Status != entities.ApplicationVisitScheduled {
+					return nil, errors.NewConflict("visit is already " + string(application.Visits[i].Status))
+				}
+				return &application.Visits[i], nil
+			}
+		}
+		return nil, errors.NewNotFound("visit not found")
+	}
+
+	if visitType == "" {
+		visitType = entities.ApplicationVisitHome
+	}
+	for i := len(application.Visits) - 1; i >= 0; i-- {
+		visit := &application.Visits[i]
+		if visit.Type == visitType && visit.Status == entities.ApplicationVisitScheduled {
+			return visit, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetVisits returns the visits of an application
+func (uc *AdoptionUseCase) GetVisits(ctx context.Context, id primitive.ObjectID) ([]entities.ApplicationVisit, error) {
+	application, err := uc.applicationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Visits == nil {
+		return []entities.ApplicationVisit{}, nil
+	}
+	return application.Visits, nil
+}
+
+// ApproveApplication approves an application once every stage of the review has passed
+func (uc *AdoptionUseCase) ApproveApplication(ctx context.Context, id primitive.ObjectID, req *ApproveApplicationRequest, userID primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stage := range application.Review.Stages {
+		if stage.Key == entities.ReviewStageDecision {
+			continue
+		}
+		if stage.Status == entities.ReviewStageFailed {
+			return nil, errors.NewConflict(fmt.Sprintf("the application failed the %s stage", stage.Key))
+		}
+		if !stage.Status.IsFinished() {
+			return nil, errors.NewConflict(fmt.Sprintf("stage %s is not completed", stage.Key))
+		}
+	}
+
+	decision := application.Review.Stage(entities.ReviewStageDecision)
+	if req.Notes != "" {
+		decision.Notes = req.Notes
+		application.ReviewNotes = req.Notes
+	}
+	application.Review.Finish(entities.ReviewStageDecision, entities.ReviewStagePassed, userID, now)
+	application.Status = entities.ApplicationStatusApproved
+	application.ApprovalDate = &now
+	application.ReviewedBy = &userID
+
+	changes := map[string]interface{}{"status": application.Status}
+	if err := uc.saveReview(ctx, application, userID, "Approved application", changes); err != nil {
+		return nil, err
+	}
+	return application, nil
+}
+
+// RejectApplication rejects an application with a configured reason, ending the review, and
+// emails the reason to the applicant
+func (uc *AdoptionUseCase) RejectApplication(ctx context.Context, id primitive.ObjectID, req *RejectApplicationRequest, userID primitive.ObjectID) (*entities.AdoptionApplication, error) {
+	config := uc.GetReviewConfig(ctx)
+	reason := config.Reason(req.ReasonCode)
+	if reason == nil {
+		codes := make([]string, 0, len(config.RejectionReasons))
+		for _, r := range config.RejectionReasons {
+			codes = append(codes, r.Code)
+		}
+		return nil, errors.NewBadRequest("unknown rejection reason, expected one of: " + strings.Join(codes, ", "))
+	}
+
+	now := time.Now()
+	application, err := uc.reviewable(ctx, id, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	review := application.Review
+	review.SkipRemaining(userID, now)
+	if decision := review.Stage(entities.ReviewStageDecision); decision != nil {
+		decision.Notes = req.Notes
+	}
+	review.Finish(entities.ReviewStageDecision, entities.ReviewStageFailed, userID, now)
+	review.RejectionCode = reason.Code
+	review.RejectionNotes = req.Notes
+	application.Status = entities.ApplicationStatusRejected
+	application.RejectionDate = &now
+	application.RejectionReason = reason.Label
+	application.ReviewedBy = &userID
+
+	changes := map[string]interface{}{"status": application.Status, "rejection_reason": reason.Code}
+	if err := uc.saveReview(ctx, application, userID, "Rejected application: "+reason.Label, changes); err != nil {
+		return nil, err
+	}
+
+	if req.Notify == nil || *req.Notify {
+		uc.sendRejection(ctx, application, reason, req.Notes, userID)
+	}
+	return application, nil
+}
+
+// sendRejection emails the rejection reason to the applicant. The decision stands whether
+// or not the email goes out, so a failure to queue it is logged.
+func (uc *AdoptionUseCase) sendRejection(ctx context.Context, application *entities.AdoptionApplication, reason *entities.RejectionReasonTemplate, notes string, userID primitive.ObjectID) {
+	applicant := application.Applicant
+	if uc.messenger == nil || applicant.Email == "" {
+		return
+	}
+
+	animalName := "the animal"
+	if animal, err := uc.animalRepo.FindByID(ctx, application.AnimalID); err == nil {
+		if animal.Name.English != "" {
+			animalName = animal.Name.English
+		} else if animal.Name.Polish != "" {
+			animalName = animal.Name.Polish
+		}
+	}
+	replacer := strings.NewReplacer(
+		"{{first_name}}", applicant.FirstName,
+		"{{applicant_name}}", strings.TrimSpace(applicant.FirstName+" "+applicant.LastName),
+		"{{animal_name}}", animalName,
+		"{{organization}}", uc.organizationName(ctx),
+		"{{notes}}", notes,
+	)
+	body := strings.TrimSpace(replacer.Replace(reason.Body))
+	// Drop the empty paragraph left without notes
+	for strings.Contains(body, "\n\n\n") {
+		body = strings.ReplaceAll(body, "\n\n\n", "\n\n")
+	}
+
+	communication := entities.NewCommunication(entities.TemplateTypeEmail, entities.TemplateCategoryAdoption,
+		applicant.Email, replacer.Replace(reason.Subject), body, userID)
+	communication.RecipientName = strings.TrimSpace(applicant.FirstName + " " + applicant.LastName)
+	communication.RelatedType = "adoption_application"
+	communication.RelatedID = &application.ID
+	communication.Metadata["rejection_reason"] = reason.Code
+	if err := uc.messenger.CreateCommunication(ctx, communication, userID); err != nil {
+		log.Error().Err(err).Str("application_id", application.ID.Hex()).Msg("failed to queue the rejection email")
+	}
+}
+
+// organizationName returns the foundation name signing messages to applicants
+func (uc *AdoptionUseCase) organizationName(ctx context.Context) string {
+	if uc.settingsRepo == nil {
+		return ""
+	}
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil {
+		return ""
+	}
+	return settings.Name
+}
@@ -0,0 +1,162 @@
+package adoption
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StageReport summarizes the time applications spend in a stage
+type StageReport struct {
+	Key          entities.ReviewStageKey `json:"key"`
+	Name         string                  `json:"name"`
+	SLAHours     int                     `json:"sla_hours"`
+	Completed    int                     `json:"completed"` // Passed or failed
+	Passed       int                     `json:"passed"`
+	Failed       int                     `json:"failed"`
+	Skipped      int                     `json:"skipped"`
+	AverageHours float64                 `json:"average_hours"`
+	MaxHours     float64                 `json:"max_hours"`
+	SLABreached  int                     `json:"sla_breached"` // Completed after the deadline
+	InProgress   int                     `json:"in_progress"`
+	Overdue      int                     `json:"overdue"` // In progress past the deadline
+}
+
+// StalledApplication is an application waiting in a stage past its deadline
+type StalledApplication struct {
+	ApplicationID primitive.ObjectID      `json:"application_id"`
+	AnimalID      primitive.ObjectID      `json:"animal_id"`
+	ApplicantName string                  `json:"applicant_name"`
+	Stage         entities.ReviewStageKey `json:"stage"`
+	AssigneeID    *primitive.ObjectID     `json:"assignee_id,omitempty"`
+	StartedAt     time.Time               `json:"started_at"`
+	DueAt         time.Time               `json:"due_at"`
+	HoursOverdue  float64                 `json:"hours_overdue"`
+}
+
+// ReviewReport shows where applications submitted in a period stall in the review pipeline
+type ReviewReport struct {
+	From         time.Time            `json:"from"`
+	To           time.Time            `json:"to"`
+	Applications int                  `json:"applications"`
+	Approved     int                  `json:"approved"`
+	Rejected     int                  `json:"rejected"`
+	InReview     int                  `json:"in_review"`
+	Stages       []StageReport        `json:"stages"`
+	Stalled      []StalledApplication `json:"stalled"`
+	GeneratedAt  time.Time            `json:"generated_at"`
+}
+
+// GetReviewReport reports the stage timings of the applications submitted in the period
+func (uc *AdoptionUseCase) GetReviewReport(ctx context.Context, from, to time.Time) (*ReviewReport, error) {
+	now := time.Now()
+
+	applications, _, err := uc.applicationRepo.List(ctx, repositories.AdoptionApplicationFilter{
+		FromDate: &from,
+		ToDate:   &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReviewReport{
+		From:         from,
+		To:           to,
+		Applications: len(applications),
+		Stalled:      []StalledApplication{},
+		GeneratedAt:  now,
+	}
+
+	config := uc.GetReviewConfig(ctx)
+	index := make(map[entities.ReviewStageKey]int)
+	totals := make(map[entities.ReviewStageKey]float64)
+	for _, key := range entities.ReviewStageKeys {
+		stage := config.Stage(key)
+		if stage == nil {
+			continue
+		}
+		index[key] = len(report.Stages)
+		report.Stages = append(report.Stages, StageReport{Key: key, Name: stage.Name, SLAHours: stage.SLAHours})
+	}
+
+	for _, application := range applications {
+		switch {
+		case application.Status == entities.ApplicationStatusApproved || application.Status == entities.ApplicationStatusCompleted:
+			report.Approved++
+		case application.Status == entities.ApplicationStatusRejected:
+			report.Rejected++
+		case application.CanBeReviewed():
+			report.InReview++
+		}
+		if application.Review == nil {
+			continue
+		}
+
+		for _, stage := range application.Review.Stages {
+			i, ok := index[stage.Key]
+			if !ok {
+				continue
+			}
+			row := &report.Stages[i]
+			switch stage.Status {
+			case entities.ReviewStagePassed, entities.ReviewStageFailed:
+				hours := stage.Duration(now).Hours()
+				row.Completed++
+				totals[stage.Key] += hours
+				if hours > row.MaxHours {
+					row.MaxHours = hours
+				}
+				if stage.SLABreached {
+					row.SLABreached++
+				}
+				if stage.Status == entities.ReviewStagePassed {
+					row.Passed++
+				} else {
+					row.Failed++
+				}
+			case entities.ReviewStageSkipped:
+				row.Skipped++
+			case entities.ReviewStageInProgress:
+				if !application.CanBeReviewed() {
+					continue
+				}
+				row.InProgress++
+				if stage.IsOverdue(now) {
+					row.Overdue++
+					report.Stalled = append(report.Stalled, StalledApplication{
+						ApplicationID: application.ID,
+						AnimalID:      application.AnimalID,
+						ApplicantName: application.Applicant.FirstName + " " + application.Applicant.LastName,
+						Stage:         stage.Key,
+						AssigneeID:    stage.AssigneeID,
+						StartedAt:     *stage.StartedAt,
+						DueAt:         *stage.DueAt,
+						HoursOverdue:  roundHours(now.Sub(*stage.DueAt).Hours()),
+					})
+				}
+			}
+		}
+	}
+
+	for i := range report.Stages {
+		row := &report.Stages[i]
+		if row.Completed > 0 {
+			row.AverageHours = roundHours(totals[row.Key] / float64(row.Completed))
+		}
+		row.MaxHours = roundHours(row.MaxHours)
+	}
+	sort.SliceStable(report.Stalled, func(i, j int) bool {
+		return report.Stalled[i].HoursOverdue > report.Stalled[j].HoursOverdue
+	})
+
+	return report, nil
+}
+
+// roundHours rounds hours to one decimal place
+func roundHours(hours float64) float64 {
+	return float64(int64(hours*10+0.5)) / 10
+}
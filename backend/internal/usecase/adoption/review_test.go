@@ -0,0 +1,305 @@
+package adoption
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type reviewMocks struct {
+	applications *mocks.AdoptionApplicationRepository
+	animals      *mocks.AnimalRepository
+	messenger    *testutil.Messenger
+}
+
+func newReviewUseCase() (*AdoptionUseCase, *reviewMocks) {
+	m := &reviewMocks{
+		applications: new(mocks.AdoptionApplicationRepository),
+		animals:      new(mocks.AnimalRepository),
+		messenger:    &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+	m.applications.On("Update", mock.Anything, mock.Anything).Return(nil)
+	return NewAdoptionUseCase(m.applications, nil, m.animals, auditLogs, nil, nil, nil, nil, nil, m.messenger, nil), m
+}
+
+// submitted registers an application submitted before the review pipeline existed
+func submitted(m *reviewMocks, ownership entities.OwnershipStatus) *entities.AdoptionApplication {
+	application := entities.NewAdoptionApplication(primitive.NewObjectID(), entities.ApplicantInfo{
+		FirstName: "Anna",
+		LastName:  "Nowak",
+		Email:     "anna@example.com",
+	}, primitive.NewObjectID())
+	application.ID = primitive.NewObjectID()
+	application.Housing.Ownership = ownership
+	application.AgreesToHomeVisit = true
+	m.applications.On("FindByID", mock.Anything, application.ID).Return(application, nil)
+	return application
+}
+
+// pass ticks the checklist of a stage and completes it
+func pass(t *testing.T, uc *AdoptionUseCase, application *entities.AdoptionApplication, key entities.ReviewStageKey) {
+	t.Helper()
+	if application.Review == nil {
+		_, err := uc.GetReview(context.Background(), application.ID)
+		require.NoError(t, err)
+	}
+	checklist := map[string]bool{}
+	for _, item := range application.Review.Stage(key).Checklist {
+		checklist[item.ID] = true
+	}
+	userID := primitive.NewObjectID()
+	_, err := uc.UpdateStage(context.Background(), application.ID, key, &UpdateStageRequest{Checklist: checklist}, userID)
+	require.NoError(t, err)
+	_, err = uc.CompleteStage(context.Background(), application.ID, key, &CompleteStageRequest{Outcome: entities.ReviewStagePassed}, userID)
+	require.NoError(t, err)
+}
+
+func appCode(err error) int {
+	return err.(*errors.AppError).Code
+}
+
+func TestReview_StagesAdvanceInOrder(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newReviewUseCase()
+	application := submitted(m, entities.OwnershipOwned)
+	userID := primitive.NewObjectID()
+
+	review, err := uc.GetReview(ctx, application.ID)
+	require.NoError(t, err)
+	require.NotNil(t, review.Review)
+	assert.Equal(t, entities.ReviewStageScreening, review.Review.CurrentStage)
+	assert.Equal(t, entities.ApplicationStatusPending, review.Status, "viewing the review does not start it")
+
+	_, err = uc.CompleteStage(ctx, application.ID, entities.ReviewStageReferenceCheck, &CompleteStageRequest{Outcome: entities.ReviewStagePassed}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, appCode(err), "only the current stage can be completed")
+
+	_, err = uc.CompleteStage(ctx, application.ID, entities.ReviewStageScreening, &CompleteStageRequest{Outcome: entities.ReviewStagePassed}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, appCode(err), "an open checklist blocks passing")
+	assert.Contains(t, err.Error(), "Application complete")
+	assert.Equal(t, entities.ApplicationStatusUnderReview, application.Status)
+
+	pass(t, uc, application, entities.ReviewStageScreening)
+	screening := application.Review.Stage(entities.ReviewStageScreening)
+	assert.Equal(t, entities.ReviewStagePassed, screening.Status)
+	assert.NotNil(t, screening.CompletedAt)
+	assert.False(t, screening.SLABreached)
+
+	pass(t, uc, application, entities.ReviewStageReferenceCheck)
+	landlord := application.Review.Stage(entities.ReviewStageLandlordVerification)
+	assert.Equal(t, entities.ReviewStageSkipped, landlord.Status, "owners have no landlord to verify")
+	assert.Equal(t, entities.ReviewStageHomeVisit, application.Review.CurrentStage)
+	home := application.Review.Current()
+	require.NotNil(t, home.DueAt)
+	assert.WithinDuration(t, home.StartedAt.Add(168*time.Hour), *home.DueAt, time.Second)
+
+	_, err = uc.ApproveApplication(ctx, application.ID, &ApproveApplicationRequest{}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, appCode(err))
+	assert.Contains(t, err.Error(), "home_visit")
+
+	pass(t, uc, application, entities.ReviewStageHomeVisit)
+	pass(t, uc, application, entities.ReviewStageMeetAndGreet)
+	assert.Equal(t, entities.ReviewStageDecision, application.Review.CurrentStage)
+
+	approved, err := uc.ApproveApplication(ctx, application.ID, &ApproveApplicationRequest{Notes: "Great match"}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApplicationStatusApproved, approved.Status)
+	assert.NotNil(t, approved.ApprovalDate)
+	assert.Empty(t, approved.Review.CurrentStage)
+	assert.NotNil(t, approved.Review.CompletedAt)
+	assert.Equal(t, entities.ReviewStagePassed, approved.Review.Stage(entities.ReviewStageDecision).Status)
+
+	_, err = uc.CompleteStage(ctx, application.ID, entities.ReviewStageScreening, &CompleteStageRequest{Outcome: entities.ReviewStageFailed}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, appCode(err), "a decided application is closed")
+}
+
+func TestReview_FailedStageLeadsToTemplatedRejection(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newReviewUseCase()
+	application := submitted(m, entities.OwnershipRented)
+	m.animals.On("FindByID", mock.Anything, application.AnimalID).
+		Return(&entities.Animal{ID: application.AnimalID, Name: entities.MultilingualName{English: "Rex"}}, nil)
+	userID := primitive.NewObjectID()
+
+	pass(t, uc, application, entities.ReviewStageScreening)
+	pass(t, uc, application, entities.ReviewStageReferenceCheck)
+	assert.Equal(t, entities.ReviewStageLandlordVerification, application.Review.CurrentStage, "renters need landlord verification")
+
+	_, err := uc.CompleteStage(ctx, application.ID, entities.ReviewStageLandlordVerification,
+		&CompleteStageRequest{Outcome: entities.ReviewStageFailed, Notes: "Lease forbids dogs"}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReviewStageDecision, application.Review.CurrentStage)
+	assert.Equal(t, entities.ReviewStageSkipped, application.Review.Stage(entities.ReviewStageHomeVisit).Status)
+	assert.Equal(t, entities.ReviewStageSkipped, application.Review.Stage(entities.ReviewStageMeetAndGreet).Status)
+
+	_, err = uc.ApproveApplication(ctx, application.ID, &ApproveApplicationRequest{}, userID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed the landlord_verification stage")
+
+	_, err = uc.RejectApplication(ctx, application.ID, &RejectApplicationRequest{ReasonCode: "unknown"}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, appCode(err))
+
+	rejected, err := uc.RejectApplication(ctx, application.ID, &RejectApplicationRequest{
+		ReasonCode: "landlord_refused",
+		Notes:      "Your lease does not allow dogs.",
+	}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApplicationStatusRejected, rejected.Status)
+	assert.Equal(t, "Landlord did not allow pets", rejected.RejectionReason)
+	assert.Equal(t, "landlord_refused", rejected.Review.RejectionCode)
+	assert.Equal(t, entities.ReviewStageFailed, rejected.Review.Stage(entities.ReviewStageDecision).Status)
+
+	require.Len(t, m.messenger.Sent, 1)
+	message := m.messenger.Sent[0]
+	assert.Equal(t, "anna@example.com", message.RecipientEmail)
+	assert.Equal(t, "Your application to adopt Rex", message.Subject)
+	assert.Contains(t, message.Body, "Dear Anna,")
+	assert.Contains(t, message.Body, "Your lease does not allow dogs.")
+	assert.Equal(t, application.ID, *message.RelatedID)
+	assert.Equal(t, "landlord_refused", message.Metadata["rejection_reason"])
+}
+
+func TestReview_VisitFindingsCompleteTheStage(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newReviewUseCase()
+	application := submitted(m, entities.OwnershipOwned)
+	userID := primitive.NewObjectID()
+	staffID := primitive.NewObjectID()
+
+	pass(t, uc, application, entities.ReviewStageScreening)
+	pass(t, uc, application, entities.ReviewStageReferenceCheck)
+
+	application.AgreesToHomeVisit = false
+	_, err := uc.ScheduleVisit(ctx, application.ID, &ScheduleVisitRequest{ScheduledAt: time.Now().Add(48 * time.Hour)}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, appCode(err))
+	application.AgreesToHomeVisit = true
+
+	scheduledAt := time.Now().Add(48 * time.Hour)
+	visit, err := uc.ScheduleVisit(ctx, application.ID, &ScheduleVisitRequest{
+		ScheduledAt: scheduledAt,
+		StaffID:     staffID.Hex(),
+		Location:    "Długa 5, Kraków",
+	}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApplicationVisitHome, visit.Type)
+	assert.Equal(t, staffID, *application.Review.Stage(entities.ReviewStageHomeVisit).AssigneeID)
+	assert.Equal(t, scheduledAt, *application.HomeVisitDate)
+
+	updated, err := uc.RecordVisit(ctx, application.ID, &RecordVisitRequest{
+		Outcome: entities.ReviewStagePassed,
+		Findings: []VisitFinding{
+			{Item: "Safe living space", Done: true},
+			{Item: "secure fencing or balcony", Done: true},
+			{Item: "Space for the animal", Done: true},
+		},
+		Report:        "Quiet flat with a fenced balcony",
+		CompleteStage: true,
+	}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ReviewStagePassed, updated.Review.Stage(entities.ReviewStageHomeVisit).Status)
+	assert.Equal(t, entities.ReviewStageMeetAndGreet, updated.Review.CurrentStage)
+	assert.Equal(t, "Quiet flat with a fenced balcony", updated.HomeVisitNotes)
+
+	visits, err := uc.GetVisits(ctx, application.ID)
+	require.NoError(t, err)
+	require.Len(t, visits, 1)
+	assert.Equal(t, entities.ApplicationVisitCompleted, visits[0].Status)
+	assert.Len(t, visits[0].Findings, 3)
+}
+
+func TestUpdateApplication_CannotDecideTheApplication(t *testing.T) {
+	uc, m := newReviewUseCase()
+	application := submitted(m, entities.OwnershipOwned)
+
+	approved := entities.ApplicationStatusApproved
+	_, err := uc.UpdateApplication(context.Background(), application.ID, &UpdateApplicationRequest{Status: &approved}, primitive.NewObjectID())
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, appCode(err))
+	assert.Equal(t, entities.ApplicationStatusPending, application.Status)
+	assert.Nil(t, application.ApprovalDate)
+	m.applications.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestReview_UpdateReference(t *testing.T) {
+	uc, m := newReviewUseCase()
+	application := submitted(m, entities.OwnershipOwned)
+	application.References = []entities.Reference{{Name: "Jan Kowalski", Phone: "500100200"}}
+	userID := primitive.NewObjectID()
+
+	notes := "Known the applicant for ten years"
+	updated, err := uc.UpdateReference(context.Background(), application.ID, 0,
+		&UpdateReferenceRequest{Outcome: entities.ReferenceOutcomePositive, Notes: &notes}, userID)
+	require.NoError(t, err)
+	reference := updated.References[0]
+	assert.True(t, reference.Contacted)
+	assert.Equal(t, entities.ReferenceOutcomePositive, reference.Outcome)
+	assert.Equal(t, userID, *reference.ContactedBy)
+	assert.Equal(t, notes, reference.Notes)
+
+	_, err = uc.UpdateReference(context.Background(), application.ID, 1, &UpdateReferenceRequest{Outcome: entities.ReferenceOutcomeNegative}, userID)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, appCode(err))
+}
+
+func TestReview_ReportShowsWhereApplicationsStall(t *testing.T) {
+	uc, m := newReviewUseCase()
+	now := time.Now()
+	config := entities.DefaultApplicationReviewConfig()
+	userID := primitive.NewObjectID()
+
+	// Screened within a day, then stuck in the reference check for five days
+	stalled := entities.NewAdoptionApplication(primitive.NewObjectID(), entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak"}, userID)
+	stalled.Status = entities.ApplicationStatusUnderReview
+	stalled.Review = entities.NewApplicationReview(config, now.Add(-6*24*time.Hour))
+	stalled.Review.Finish(entities.ReviewStageScreening, entities.ReviewStagePassed, userID, now.Add(-5*24*time.Hour))
+
+	// Screened after three days, over the 48 hour deadline, then rejected
+	rejected := entities.NewAdoptionApplication(primitive.NewObjectID(), entities.ApplicantInfo{FirstName: "Jan", LastName: "Kowalski"}, userID)
+	rejected.Status = entities.ApplicationStatusRejected
+	rejected.Review = entities.NewApplicationReview(config, now.Add(-10*24*time.Hour))
+	rejected.Review.Finish(entities.ReviewStageScreening, entities.ReviewStagePassed, userID, now.Add(-7*24*time.Hour))
+	rejected.Review.SkipRemaining(userID, now.Add(-7*24*time.Hour))
+	rejected.Review.Finish(entities.ReviewStageDecision, entities.ReviewStageFailed, userID, now.Add(-7*24*time.Hour))
+
+	m.applications.On("List", mock.Anything, mock.Anything).Return([]*entities.AdoptionApplication{stalled, rejected}, int64(2), nil)
+
+	report, err := uc.GetReviewReport(context.Background(), now.AddDate(0, 0, -30), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Applications)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Equal(t, 1, report.InReview)
+
+	screening := report.Stages[0]
+	assert.Equal(t, entities.ReviewStageScreening, screening.Key)
+	assert.Equal(t, 2, screening.Completed)
+	assert.Equal(t, 48.0, screening.AverageHours)
+	assert.Equal(t, 72.0, screening.MaxHours)
+	assert.Equal(t, 1, screening.SLABreached)
+
+	references := report.Stages[1]
+	assert.Equal(t, 1, references.InProgress)
+	assert.Equal(t, 1, references.Overdue)
+	assert.Equal(t, 1, references.Skipped)
+
+	require.Len(t, report.Stalled, 1)
+	assert.Equal(t, stalled.ID, report.Stalled[0].ApplicationID)
+	assert.Equal(t, entities.ReviewStageReferenceCheck, report.Stalled[0].Stage)
+	assert.Equal(t, 48.0, report.Stalled[0].HoursOverdue)
+}
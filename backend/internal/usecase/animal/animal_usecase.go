@@ -2,21 +2,45 @@ package animal
 
 import (
 	"context"
+	"fmt"
 	"mime/multipart"
 	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
 	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/imaging"
+	"github.com/sainaif/animalsys/backend/pkg/microchip"
 	"github.com/sainaif/animalsys/backend/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// VaccinationScheduler plans the protocol vaccinations of newly admitted animals
+type VaccinationScheduler interface {
+	ScheduleIntakeVaccinations(ctx context.Context, animal *entities.Animal, userID primitive.ObjectID) error
+}
+
+// VitalsRecorder adds weight and vitals measured during daily care to the animal's time series
+type VitalsRecorder interface {
+	RecordVitals(ctx context.Context, animalID primitive.ObjectID, vitals entities.VitalSigns, source entities.VitalSource, sourceID *primitive.ObjectID, recordedAt time.Time, userID primitive.ObjectID) (*entities.VitalSignReading, error)
+}
+
+// QuarantineChecker tells whether an animal has an open quarantine, which only
+// releasing the quarantine may take it out of
+type QuarantineChecker interface {
+	HasActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (bool, error)
+}
+
 // AnimalUseCase handles animal business logic
 type AnimalUseCase struct {
 	animalRepo   repositories.AnimalRepository
 	auditLogRepo repositories.AuditLogRepository
 	storageService *storage.StorageService
+	chipRegistry   microchip.Registry
+	imageProcessor *imaging.Processor
+	vaccinationScheduler VaccinationScheduler
+	vitals               VitalsRecorder
+	quarantines          QuarantineChecker
 }
 
 // NewAnimalUseCase creates a new animal use case
@@ -24,11 +48,20 @@ func NewAnimalUseCase(
 	animalRepo repositories.AnimalRepository,
 	auditLogRepo repositories.AuditLogRepository,
 	storageService *storage.StorageService,
+	chipRegistry microchip.Registry,
+	vaccinationScheduler VaccinationScheduler,
+	vitals VitalsRecorder,
+	quarantines QuarantineChecker,
 ) *AnimalUseCase {
 	return &AnimalUseCase{
 		animalRepo:     animalRepo,
 		auditLogRepo:   auditLogRepo,
 		storageService: storageService,
+		chipRegistry:   chipRegistry,
+		imageProcessor: imaging.NewProcessor(imaging.DefaultSizes),
+		vaccinationScheduler: vaccinationScheduler,
+		vitals:               vitals,
+		quarantines:          quarantines,
 	}
 }
 
@@ -113,6 +146,11 @@ func (uc *AnimalUseCase) CreateAnimal(ctx context.Context, req *CreateAnimalRequ
 		status = entities.AnimalStatusAvailable
 	}
 
+	// Validate microchip and warn about chips already on file
+	if err := uc.prepareMicrochip(ctx, &req.Medical, nil); err != nil {
+		return nil, err
+	}
+
 	animal := &entities.Animal{
 		Name:         req.Name,
 		Category:     req.Category,
@@ -145,6 +183,12 @@ func (uc *AnimalUseCase) CreateAnimal(ctx context.Context, req *CreateAnimalRequ
 		return nil, err
 	}
 
+	// Plan the vaccinations required by the species protocols; the intake itself
+	// must not fail when the schedule cannot be generated
+	if uc.vaccinationScheduler != nil {
+		_ = uc.vaccinationScheduler.ScheduleIntakeVaccinations(ctx, animal, creatorID)
+	}
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(creatorID, entities.ActionCreate, "animal", "", "").
 		WithEntityID(animal.ID)
@@ -196,6 +240,9 @@ func (uc *AnimalUseCase) UpdateAnimal(ctx context.Context, id primitive.ObjectID
 		animal.Sex = *req.Sex
 	}
 	if req.Status != nil {
+		if err := uc.checkQuarantineStatus(ctx, animal, *req.Status); err != nil {
+			return nil, err
+		}
 		changes["status"] = req.Status
 		animal.Status = *req.Status
 	}
@@ -224,6 +271,17 @@ func (uc *AnimalUseCase) UpdateAnimal(ctx context.Context, id primitive.ObjectID
 		animal.Description = *req.Description
 	}
 	if req.Medical != nil {
+		if err := uc.prepareMicrochip(ctx, req.Medical, &animal.ID); err != nil {
+			return nil, err
+		}
+		// Keep the registry record unless the chip itself changed
+		if req.Medical.MicrochipNumber == animal.Medical.MicrochipNumber && req.Medical.MicrochipRegistration == nil {
+			req.Medical.MicrochipRegistration = animal.Medical.MicrochipRegistration
+		}
+		// Vaccination compliance follows the vaccination records
+		req.Medical.Vaccinated = animal.Medical.Vaccinated
+		req.Medical.VaccinationStatus = animal.Medical.VaccinationStatus
+		req.Medical.NextVaccinationDue = animal.Medical.NextVaccinationDue
 		changes["medical"] = *req.Medical
 		animal.Medical = *req.Medical
 	}
@@ -233,7 +291,7 @@ func (uc *AnimalUseCase) UpdateAnimal(ctx context.Context, id primitive.ObjectID
 	}
 	if req.Location != nil {
 		changes["location"] = *req.Location
-		animal.Shelter.Location = *req.Location
+		animal.MoveTo(*req.Location, time.Now())
 	}
 	if req.AdoptionFee != nil {
 		changes["adoption_fee"] = *req.AdoptionFee
@@ -267,18 +325,14 @@ func (uc *AnimalUseCase) DeleteAnimal(ctx context.Context, id primitive.ObjectID
 		return err
 	}
 
-	// Delete images if any
-	if animal.Images.Primary != "" {
-		_ = uc.storageService.DeleteFile(ctx, animal.Images.Primary)
-	}
-	if len(animal.Images.Gallery) > 0 {
-		_ = uc.storageService.DeleteMultipleFiles(ctx, animal.Images.Gallery)
-	}
-
 	if err := uc.animalRepo.Delete(ctx, id); err != nil {
 		return err
 	}
 
+	// Delete every referenced image file, then the animal's folder to catch orphans from failed uploads
+	_ = uc.storageService.DeleteMultipleFiles(ctx, animal.Images.AllURLs())
+	_ = uc.storageService.DeleteFolder(ctx, animalImageFolder(id))
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(deleterID, entities.ActionDelete, "animal", "", "").
 		WithEntityID(id)
@@ -335,7 +389,8 @@ func (uc *AnimalUseCase) ListAnimals(ctx context.Context, req *ListAnimalsReques
 	}, nil
 }
 
-// UploadAnimalImages uploads images for an animal
+// UploadAnimalImages processes and stores images for an animal.
+// Every upload is decoded and re-encoded into WebP sizes, which strips EXIF and GPS metadata.
 func (uc *AnimalUseCase) UploadAnimalImages(ctx context.Context, animalID primitive.ObjectID, primary *multipart.FileHeader, gallery []*multipart.FileHeader, userID primitive.ObjectID) error {
 	animal, err := uc.animalRepo.FindByID(ctx, animalID)
 	if err != nil {
@@ -343,33 +398,52 @@ func (uc *AnimalUseCase) UploadAnimalImages(ctx context.Context, animalID primit
 	}
 
 	images := animal.Images
+	images.EnsureItems()
 
-	// Upload primary image
-	if primary != nil {
-		// Delete old primary image if exists
-		if images.Primary != "" {
-			_ = uc.storageService.DeleteFile(ctx, images.Primary)
+	var uploaded []entities.AnimalImage
+	cleanup := func() {
+		for _, item := range uploaded {
+			_ = uc.storageService.DeleteMultipleFiles(ctx, item.URLs())
 		}
+	}
 
-		url, err := uc.storageService.UploadImage(ctx, primary, "animals")
+	files := gallery
+	if primary != nil {
+		files = append([]*multipart.FileHeader{primary}, gallery...)
+	}
+
+	for _, file := range files {
+		item, err := uc.processImage(ctx, animalID, file, userID)
 		if err != nil {
+			cleanup()
 			return err
 		}
-		images.Primary = url
+		uploaded = append(uploaded, *item)
 	}
 
-	// Upload gallery images
-	if len(gallery) > 0 {
-		urls, err := uc.storageService.UploadMultipleImages(ctx, gallery, "animals")
-		if err != nil {
-			return err
+	// A new primary image replaces the current one; its files are removed once the animal
+	// no longer points at them
+	var replaced *entities.AnimalImage
+	added := uploaded
+	if primary != nil {
+		if len(images.Items) > 0 {
+			current := images.Items[0]
+			replaced = &current
+			images.Items = images.Items[1:]
 		}
-		images.Gallery = append(images.Gallery, urls...)
+		images.Items = append([]entities.AnimalImage{added[0]}, images.Items...)
+		added = added[1:]
 	}
+	images.Items = append(images.Items, added...)
+	images.SyncURLs()
 
 	if err := uc.animalRepo.UpdateImages(ctx, animalID, images); err != nil {
+		cleanup()
 		return err
 	}
+	if replaced != nil {
+		_ = uc.storageService.DeleteMultipleFiles(ctx, replaced.URLs())
+	}
 
 	// Create audit log
 	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "animal", "", "").
@@ -380,18 +454,125 @@ func (uc *AnimalUseCase) UploadAnimalImages(ctx context.Context, animalID primit
 	return nil
 }
 
-// AddDailyNote adds a daily note to an animal
-func (uc *AnimalUseCase) AddDailyNote(ctx context.Context, animalID primitive.ObjectID, noteText string, userID primitive.ObjectID) error {
+// ReorderAnimalImages changes the display order of an animal's images; the first image becomes primary
+func (uc *AnimalUseCase) ReorderAnimalImages(ctx context.Context, animalID primitive.ObjectID, imageIDs []string, userID primitive.ObjectID) (*entities.AnimalImages, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := animal.Images
+	images.EnsureItems()
+
+	if !images.Reorder(imageIDs) {
+		return nil, errors.NewBadRequest("image order must list every image of the animal exactly once")
+	}
+
+	if err := uc.animalRepo.UpdateImages(ctx, animalID, images); err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "animal", "", "").
+		WithEntityID(animalID).
+		WithChanges(map[string]interface{}{"images": "reordered"})
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return &images, nil
+}
+
+// DeleteAnimalImage removes an image and its files from an animal
+func (uc *AnimalUseCase) DeleteAnimalImage(ctx context.Context, animalID primitive.ObjectID, imageID string, userID primitive.ObjectID) (*entities.AnimalImages, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := animal.Images
+	images.EnsureItems()
+
+	removed, ok := images.Remove(imageID)
+	if !ok {
+		return nil, errors.NewNotFound("image not found")
+	}
+
+	if err := uc.animalRepo.UpdateImages(ctx, animalID, images); err != nil {
+		return nil, err
+	}
+
+	_ = uc.storageService.DeleteMultipleFiles(ctx, removed.URLs())
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "animal", "", "").
+		WithEntityID(animalID).
+		WithChanges(map[string]interface{}{"images": "deleted " + imageID})
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return &images, nil
+}
+
+// processImage converts an uploaded file into stored WebP variants
+func (uc *AnimalUseCase) processImage(ctx context.Context, animalID primitive.ObjectID, file *multipart.FileHeader, userID primitive.ObjectID) (*entities.AnimalImage, error) {
+	if uc.storageService.ExceedsMaxFileSize(file.Size) {
+		return nil, errors.NewBadRequest("image file is too large")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to open uploaded file")
+	}
+	defer src.Close()
+
+	result, err := uc.imageProcessor.Process(src)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &entities.AnimalImage{
+		ID:         primitive.NewObjectID().Hex(),
+		Sizes:      make(map[string]string, len(result.Variants)),
+		Width:      result.Width,
+		Height:     result.Height,
+		UploadedBy: userID,
+		UploadedAt: time.Now(),
+	}
+
+	folder := animalImageFolder(animalID)
+	for _, variant := range result.Variants {
+		url, err := uc.storageService.SaveFile(ctx, variant.Data, folder, fmt.Sprintf("%s_%s.webp", item.ID, variant.Name))
+		if err != nil {
+			_ = uc.storageService.DeleteMultipleFiles(ctx, item.URLs())
+			return nil, err
+		}
+		item.Sizes[variant.Name] = url
+	}
+
+	return item, nil
+}
+
+// animalImageFolder returns the storage folder holding an animal's images
+func animalImageFolder(animalID primitive.ObjectID) string {
+	return "animals/" + animalID.Hex()
+}
+
+// AddDailyNote adds a daily note to an animal. Weight and vitals measured
+// during care are also added to the animal's vitals time series.
+func (uc *AnimalUseCase) AddDailyNote(ctx context.Context, animalID primitive.ObjectID, noteText string, vitals *entities.VitalSigns, userID primitive.ObjectID) error {
 	note := entities.DailyNote{
 		Date:      time.Now(),
 		Note:      noteText,
 		CreatedBy: userID,
+		Vitals:    vitals,
 	}
 
 	if err := uc.animalRepo.AddDailyNote(ctx, animalID, note); err != nil {
 		return err
 	}
 
+	if vitals != nil && uc.vitals != nil {
+		_, _ = uc.vitals.RecordVitals(ctx, animalID, *vitals, entities.VitalSourceDailyNote, nil, note.Date, userID)
+	}
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "animal", "", "").
 		WithEntityID(animalID).
@@ -410,3 +591,146 @@ func (uc *AnimalUseCase) GetStatistics(ctx context.Context) (*repositories.Anima
 func (uc *AnimalUseCase) GetSpeciesByCategory(category entities.AnimalCategory) []entities.SpeciesInfo {
 	return entities.GetSpeciesByCategory(category)
 }
+
+// MicrochipCheckResponse reports the result of checking a microchip number at intake
+type MicrochipCheckResponse struct {
+	ChipNumber     string                  `json:"chip_number"`
+	Valid          bool                    `json:"valid"`
+	Error          string                  `json:"error,omitempty"`
+	ExistingAnimal *entities.Animal        `json:"existing_animal,omitempty"`
+	Registration   *microchip.Registration `json:"registration,omitempty"`
+	Warnings       []string                `json:"warnings,omitempty"`
+}
+
+// CheckMicrochip validates a microchip number and reports animals and registry owners already linked to it
+func (uc *AnimalUseCase) CheckMicrochip(ctx context.Context, number string) (*MicrochipCheckResponse, error) {
+	response := &MicrochipCheckResponse{ChipNumber: microchip.Normalize(number)}
+
+	normalized, err := microchip.Validate(number)
+	if err != nil {
+		response.Error = err.Error()
+		return response, nil
+	}
+	response.ChipNumber = normalized
+	response.Valid = true
+
+	existing, err := uc.animalRepo.FindByMicrochipNumber(ctx, normalized)
+	if err != nil && err != errors.ErrNotFound {
+		return nil, err
+	}
+	if existing != nil {
+		response.ExistingAnimal = existing
+		response.Warnings = append(response.Warnings, "microchip is already assigned to an animal in the shelter")
+	}
+
+	if uc.chipRegistry != nil {
+		registration, err := uc.chipRegistry.Lookup(ctx, normalized)
+		if err == nil {
+			response.Registration = registration
+			response.Warnings = append(response.Warnings, "microchip is registered to an owner in "+registration.Registry)
+		}
+	}
+
+	return response, nil
+}
+
+// LookupMicrochipOwner looks up the registered owner of a microchip
+func (uc *AnimalUseCase) LookupMicrochipOwner(ctx context.Context, number string) (*microchip.Registration, error) {
+	if uc.chipRegistry == nil {
+		return nil, errors.NewInternalServer("microchip registry is not configured")
+	}
+
+	return uc.chipRegistry.Lookup(ctx, number)
+}
+
+// RegisterMicrochipOwner registers a new owner for an animal's microchip
+func (uc *AnimalUseCase) RegisterMicrochipOwner(ctx context.Context, animalID primitive.ObjectID, owner microchip.Owner, userID primitive.ObjectID) (*entities.Animal, error) {
+	if uc.chipRegistry == nil {
+		return nil, errors.NewInternalServer("microchip registry is not configured")
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if animal.Medical.MicrochipNumber == "" {
+		return nil, errors.NewBadRequest("animal has no microchip number")
+	}
+
+	registration, err := uc.chipRegistry.Register(ctx, animal.Medical.MicrochipNumber, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	animal.Medical.MicrochipRegistration = toMicrochipRegistration(registration)
+	animal.UpdatedBy = userID
+
+	if err := uc.animalRepo.Update(ctx, animal); err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "animal", "", "").
+		WithEntityID(animalID).
+		WithChanges(map[string]interface{}{"microchip_registration": registration.Registry})
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return animal, nil
+}
+
+// toMicrochipRegistration converts a registry registration into the record stored on the animal
+func toMicrochipRegistration(registration *microchip.Registration) *entities.MicrochipRegistration {
+	return &entities.MicrochipRegistration{
+		Registry:     registration.Registry,
+		Reference:    registration.Reference,
+		OwnerName:    registration.Owner.Name,
+		OwnerEmail:   registration.Owner.Email,
+		OwnerPhone:   registration.Owner.Phone,
+		RegisteredAt: registration.RegisteredAt,
+	}
+}
+
+// prepareMicrochip normalizes the microchip number and rejects chips already assigned to another animal
+func (uc *AnimalUseCase) prepareMicrochip(ctx context.Context, medical *entities.MedicalInfo, animalID *primitive.ObjectID) error {
+	if medical.MicrochipNumber == "" {
+		return nil
+	}
+
+	normalized, err := microchip.Validate(medical.MicrochipNumber)
+	if err != nil {
+		return err
+	}
+	medical.MicrochipNumber = normalized
+	medical.Microchipped = true
+
+	existing, err := uc.animalRepo.FindByMicrochipNumber(ctx, normalized)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if animalID == nil || existing.ID != *animalID {
+		return errors.NewConflict(fmt.Sprintf("microchip %s is already assigned to animal %s (%s)", normalized, existing.ID.Hex(), existing.Name.English))
+	}
+
+	return nil
+}
+
+// checkQuarantineStatus refuses to take an animal out of an open quarantine by changing
+// its status. It may still be put under treatment, which releasing the quarantine keeps.
+func (uc *AnimalUseCase) checkQuarantineStatus(ctx context.Context, animal *entities.Animal, status entities.AnimalStatus) error {
+	if uc.quarantines == nil || status == entities.AnimalStatusQuarantine || status == entities.AnimalStatusUnderTreatment {
+		return nil
+	}
+	active, err := uc.quarantines.HasActiveQuarantine(ctx, animal.ID)
+	if err != nil {
+		return err
+	}
+	if active {
+		return errors.NewConflict("animal is in quarantine; release the quarantine to change its status")
+	}
+	return nil
+}
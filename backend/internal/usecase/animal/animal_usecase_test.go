@@ -1,14 +1,24 @@
 package animal
 
 import (
+	"bytes"
 	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/microchip"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -16,7 +26,7 @@ func TestUpdateAnimal_AuditLog(t *testing.T) {
 	// Setup
 	animalRepo := new(mocks.AnimalRepository)
 	auditLogRepo := new(mocks.AuditLogRepository)
-	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil)
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, nil)
 
 	animalID := primitive.NewObjectID()
 	updaterID := primitive.NewObjectID()
@@ -89,3 +99,224 @@ func TestUpdateAnimal_AuditLog(t *testing.T) {
 		return true
 	}))
 }
+
+func TestCreateAnimal_DuplicateMicrochip(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, nil)
+
+	existing := &entities.Animal{
+		ID:   primitive.NewObjectID(),
+		Name: entities.MultilingualName{English: "Burek"},
+	}
+
+	req := &CreateAnimalRequest{
+		Name:    entities.MultilingualName{English: "Reks"},
+		Species: "dog",
+		Medical: entities.MedicalInfo{MicrochipNumber: "616 093 900 012 345"},
+	}
+
+	animalRepo.On("FindByMicrochipNumber", mock.Anything, "616093900012345").Return(existing, nil)
+
+	_, err := uc.CreateAnimal(context.Background(), req, primitive.NewObjectID())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), existing.ID.Hex())
+	animalRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateAnimal_InvalidMicrochip(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, nil)
+
+	req := &CreateAnimalRequest{
+		Name:    entities.MultilingualName{English: "Reks"},
+		Medical: entities.MedicalInfo{MicrochipNumber: "12345"},
+	}
+
+	_, err := uc.CreateAnimal(context.Background(), req, primitive.NewObjectID())
+
+	assert.Error(t, err)
+	animalRepo.AssertNotCalled(t, "FindByMicrochipNumber", mock.Anything, mock.Anything)
+}
+
+func TestCheckMicrochip_RegisteredOwner(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	registry := microchip.NewLocalRegistry()
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, registry, nil, nil, nil)
+
+	_, err := registry.Register(context.Background(), "985112003456789", microchip.Owner{Name: "Anna Nowak"})
+	assert.NoError(t, err)
+
+	animalRepo.On("FindByMicrochipNumber", mock.Anything, "985112003456789").Return(nil, errors.ErrNotFound)
+
+	response, err := uc.CheckMicrochip(context.Background(), "985-112-003-456-789")
+
+	assert.NoError(t, err)
+	assert.True(t, response.Valid)
+	assert.Nil(t, response.ExistingAnimal)
+	assert.NotNil(t, response.Registration)
+	assert.Equal(t, "Anna Nowak", response.Registration.Owner.Name)
+	assert.Len(t, response.Warnings, 1)
+}
+
+func TestReorderAnimalImages(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, nil)
+
+	animalID := primitive.NewObjectID()
+	existing := &entities.Animal{
+		ID: animalID,
+		Images: entities.AnimalImages{
+			Items: []entities.AnimalImage{
+				{ID: "a", Sizes: map[string]string{"large": "/uploads/a_large.webp", "thumbnail": "/uploads/a_thumbnail.webp"}},
+				{ID: "b", Sizes: map[string]string{"large": "/uploads/b_large.webp", "thumbnail": "/uploads/b_thumbnail.webp"}},
+			},
+		},
+	}
+
+	animalRepo.On("FindByID", mock.Anything, animalID).Return(existing, nil)
+	animalRepo.On("UpdateImages", mock.Anything, animalID, mock.Anything).Return(nil)
+	auditLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	images, err := uc.ReorderAnimalImages(context.Background(), animalID, []string{"b", "a"}, primitive.NewObjectID())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/uploads/b_large.webp", images.Primary)
+	assert.Equal(t, []string{"/uploads/a_large.webp"}, images.Gallery)
+	assert.Equal(t, []string{"/uploads/b_thumbnail.webp", "/uploads/a_thumbnail.webp"}, images.Thumbnails)
+
+	_, err = uc.ReorderAnimalImages(context.Background(), animalID, []string{"b"}, primitive.NewObjectID())
+	assert.Error(t, err)
+}
+
+func TestReorderAnimalImages_KeepsLegacyThumbnails(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, nil)
+
+	animalID := primitive.NewObjectID()
+	existing := &entities.Animal{
+		ID: animalID,
+		Images: entities.AnimalImages{
+			Primary:    "/uploads/a.jpg",
+			Gallery:    []string{"/uploads/b.jpg"},
+			Thumbnails: []string{"/uploads/a_thumb.jpg", "/uploads/b_thumb.jpg"},
+		},
+	}
+
+	animalRepo.On("FindByID", mock.Anything, animalID).Return(existing, nil)
+	animalRepo.On("UpdateImages", mock.Anything, animalID, mock.Anything).Return(nil)
+	auditLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	legacy := existing.Images
+	legacy.EnsureItems()
+	ids := []string{legacy.Items[1].ID, legacy.Items[0].ID}
+	existing.Images = legacy
+
+	images, err := uc.ReorderAnimalImages(context.Background(), animalID, ids, primitive.NewObjectID())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/uploads/b.jpg", images.Primary)
+	assert.Equal(t, []string{"/uploads/b_thumb.jpg", "/uploads/a_thumb.jpg"}, images.Thumbnails)
+}
+
+type openQuarantines map[primitive.ObjectID]bool
+
+func (q openQuarantines) HasActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (bool, error) {
+	return q[animalID], nil
+}
+
+func TestUpdateAnimal_KeepsAnOpenQuarantine(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Status: entities.AnimalStatusQuarantine}
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, openQuarantines{animal.ID: true})
+
+	animalRepo.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	animalRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	auditLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	available := entities.AnimalStatusAvailable
+	_, err := uc.UpdateAnimal(context.Background(), animal.ID, &UpdateAnimalRequest{Status: &available}, primitive.NewObjectID())
+	assert.Error(t, err)
+	assert.Equal(t, 409, err.(*errors.AppError).Code)
+	animalRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	treatment := entities.AnimalStatusUnderTreatment
+	_, err = uc.UpdateAnimal(context.Background(), animal.ID, &UpdateAnimalRequest{Status: &treatment}, primitive.NewObjectID())
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AnimalStatusUnderTreatment, animal.Status)
+}
+
+func TestUpdateAnimal_KeepsTheVaccinationCompliance(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	uc := NewAnimalUseCase(animalRepo, auditLogRepo, nil, nil, nil, nil, nil)
+
+	due := time.Now().AddDate(0, 1, 0)
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Medical: entities.MedicalInfo{
+		Vaccinated:         true,
+		VaccinationStatus:  entities.VaccinationStatusCurrent,
+		NextVaccinationDue: &due,
+		HealthStatus:       "healthy",
+	}}
+	animalRepo.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	animalRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	auditLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	updated, err := uc.UpdateAnimal(context.Background(), animal.ID, &UpdateAnimalRequest{
+		Medical: &entities.MedicalInfo{HealthStatus: "recovering", VaccinationStatus: entities.VaccinationStatusOverdue},
+	}, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, "recovering", updated.Medical.HealthStatus)
+	assert.True(t, updated.Medical.Vaccinated)
+	assert.Equal(t, entities.VaccinationStatusCurrent, updated.Medical.VaccinationStatus)
+	assert.Equal(t, &due, updated.Medical.NextVaccinationDue)
+}
+
+func TestUploadAnimalImages_KeepsThePrimaryWhenTheAnimalIsNotSaved(t *testing.T) {
+	animalRepo := new(mocks.AnimalRepository)
+	root := t.TempDir()
+	storageService := storage.NewStorageService(storage.NewLocalBackend(root), "/uploads", 1<<20)
+	uc := NewAnimalUseCase(animalRepo, new(mocks.AuditLogRepository), storageService, nil, nil, nil, nil)
+
+	animalID := primitive.NewObjectID()
+	oldURL, err := storageService.SaveFile(context.Background(), []byte("old"), animalImageFolder(animalID), "old_large.webp")
+	require.NoError(t, err)
+	existing := &entities.Animal{
+		ID:     animalID,
+		Images: entities.AnimalImages{Items: []entities.AnimalImage{{ID: "old", Sizes: map[string]string{"large": oldURL}}}},
+	}
+	animalRepo.On("FindByID", mock.Anything, animalID).Return(existing, nil)
+	animalRepo.On("UpdateImages", mock.Anything, animalID, mock.Anything).Return(errors.NewInternalServer("write failed"))
+
+	var picture bytes.Buffer
+	require.NoError(t, png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 64, 48))))
+	err = uc.UploadAnimalImages(context.Background(), animalID, imageHeader(t, picture.Bytes()), nil, primitive.NewObjectID())
+
+	assert.Error(t, err)
+	stored, err := os.ReadDir(filepath.Join(root, animalImageFolder(animalID)))
+	require.NoError(t, err)
+	require.Len(t, stored, 1, "the new files are removed and the old ones kept")
+	assert.Equal(t, "old_large.webp", stored[0].Name())
+}
+
+// imageHeader builds the multipart file header of an uploaded image
+func imageHeader(t *testing.T, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("primary", "rex.png")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["primary"][0]
+}
@@ -0,0 +1,739 @@
+package appointment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AppointmentUseCase schedules veterinary appointments at partner clinics
+type AppointmentUseCase struct {
+	visitRepo    repositories.VeterinaryVisitRepository
+	scheduleRepo repositories.ClinicScheduleRepository
+	feedRepo     repositories.CalendarFeedRepository
+	partnerRepo  repositories.PartnerRepository
+	animalRepo   repositories.AnimalRepository
+	taskRepo     repositories.TaskRepository
+	auditLogRepo repositories.AuditLogRepository
+}
+
+// NewAppointmentUseCase creates a new appointment use case
+func NewAppointmentUseCase(
+	visitRepo repositories.VeterinaryVisitRepository,
+	scheduleRepo repositories.ClinicScheduleRepository,
+	feedRepo repositories.CalendarFeedRepository,
+	partnerRepo repositories.PartnerRepository,
+	animalRepo repositories.AnimalRepository,
+	taskRepo repositories.TaskRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) *AppointmentUseCase {
+	return &AppointmentUseCase{
+		visitRepo:    visitRepo,
+		scheduleRepo: scheduleRepo,
+		feedRepo:     feedRepo,
+		partnerRepo:  partnerRepo,
+		animalRepo:   animalRepo,
+		taskRepo:     taskRepo,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// ConflictError lists the conflicts that prevented a booking
+type ConflictError struct {
+	Conflicts []entities.AppointmentConflict
+}
+
+func (e *ConflictError) Error() string {
+	messages := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		messages[i] = conflict.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// UpdateScheduleRequest represents a request to set a clinic's schedule
+type UpdateScheduleRequest struct {
+	Timezone             *string                        `json:"timezone,omitempty"`
+	WorkingHours         *[]entities.ClinicWorkingHours `json:"working_hours,omitempty" validate:"omitempty,dive"`
+	ClosedDates          *[]string                      `json:"closed_dates,omitempty"`
+	VisitDurations       map[entities.VisitType]int     `json:"visit_durations,omitempty"`
+	Capacity             *int                           `json:"capacity,omitempty" validate:"omitempty,min=1"`
+	SlotIntervalMinutes  *int                           `json:"slot_interval_minutes,omitempty" validate:"omitempty,min=5"`
+	TransportLeadMinutes *int                           `json:"transport_lead_minutes,omitempty" validate:"omitempty,min=0"`
+}
+
+// BookAppointmentRequest represents a request to book an appointment
+type BookAppointmentRequest struct {
+	AnimalID         string             `json:"animal_id" validate:"required"`
+	PartnerID        string             `json:"partner_id" validate:"required"`
+	VisitType        entities.VisitType `json:"visit_type" validate:"required"`
+	StartTime        time.Time          `json:"start_time" validate:"required"`
+	DurationMinutes  int                `json:"duration_minutes,omitempty" validate:"omitempty,min=5"`
+	VeterinarianName string             `json:"veterinarian_name,omitempty"`
+	ChiefComplaint   string             `json:"chief_complaint,omitempty"`
+	InternalNotes    string             `json:"internal_notes,omitempty"`
+	AssignedStaff    []string           `json:"assigned_staff,omitempty"`
+
+	// Transport defaults to true; the transport task goes to TransportAssigneeID
+	Transport           *bool  `json:"transport,omitempty"`
+	TransportAssigneeID string `json:"transport_assignee_id,omitempty"`
+}
+
+// RescheduleRequest represents a request to move an appointment
+type RescheduleRequest struct {
+	StartTime       time.Time `json:"start_time" validate:"required"`
+	DurationMinutes int       `json:"duration_minutes,omitempty" validate:"omitempty,min=5"`
+}
+
+// ListAppointmentsRequest represents a request to list appointments
+type ListAppointmentsRequest struct {
+	PartnerID *primitive.ObjectID
+	AnimalID  *primitive.ObjectID
+	StaffID   *primitive.ObjectID
+	From      *time.Time
+	To        *time.Time
+	Statuses  []string
+	Limit     int64
+	Offset    int64
+}
+
+// Slot is a bookable appointment start time
+type Slot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Available int       `json:"available"` // free places at the clinic
+}
+
+// GetSchedule returns the schedule of a partner clinic, or the defaults if none is set
+func (uc *AppointmentUseCase) GetSchedule(ctx context.Context, partnerID primitive.ObjectID) (*entities.ClinicSchedule, error) {
+	if _, err := uc.findClinic(ctx, partnerID); err != nil {
+		return nil, err
+	}
+	return uc.scheduleFor(ctx, partnerID)
+}
+
+// UpdateSchedule sets the working hours and appointment settings of a partner clinic
+func (uc *AppointmentUseCase) UpdateSchedule(ctx context.Context, partnerID primitive.ObjectID, req *UpdateScheduleRequest, userID primitive.ObjectID) (*entities.ClinicSchedule, error) {
+	if _, err := uc.findClinic(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	schedule, err := uc.scheduleFor(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Timezone != nil {
+		schedule.Timezone = *req.Timezone
+	}
+	if req.WorkingHours != nil {
+		schedule.WorkingHours = *req.WorkingHours
+	}
+	if req.ClosedDates != nil {
+		schedule.ClosedDates = *req.ClosedDates
+	}
+	if req.VisitDurations != nil {
+		schedule.VisitDurations = req.VisitDurations
+	}
+	if req.Capacity != nil {
+		schedule.Capacity = *req.Capacity
+	}
+	if req.SlotIntervalMinutes != nil {
+		schedule.SlotIntervalMinutes = *req.SlotIntervalMinutes
+	}
+	if req.TransportLeadMinutes != nil {
+		schedule.TransportLeadMinutes = *req.TransportLeadMinutes
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	schedule.UpdatedBy = userID
+
+	if schedule.ID.IsZero() {
+		schedule.CreatedBy = userID
+		err = uc.scheduleRepo.Create(ctx, schedule)
+	} else {
+		err = uc.scheduleRepo.Update(ctx, schedule)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_schedule", "", "").
+		WithEntityID(schedule.ID))
+
+	return schedule, nil
+}
+
+// GetAvailableSlots returns the free start times at a clinic on a day (YYYY-MM-DD, clinic time)
+func (uc *AppointmentUseCase) GetAvailableSlots(ctx context.Context, partnerID primitive.ObjectID, date string, visitType entities.VisitType) ([]Slot, error) {
+	if _, err := uc.findClinic(ctx, partnerID); err != nil {
+		return nil, err
+	}
+	schedule, err := uc.scheduleFor(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", date, schedule.Location())
+	if err != nil {
+		return nil, errors.NewBadRequest("invalid date, expected YYYY-MM-DD")
+	}
+
+	windows := schedule.WindowsOn(day)
+	slots := []Slot{}
+	if len(windows) == 0 {
+		return slots, nil
+	}
+
+	dayStart, dayEnd := windows[0][0], windows[0][1]
+	for _, window := range windows[1:] {
+		if window[0].Before(dayStart) {
+			dayStart = window[0]
+		}
+		if window[1].After(dayEnd) {
+			dayEnd = window[1]
+		}
+	}
+	booked, err := uc.blockingVisits(ctx, repositories.VeterinaryVisitFilter{PartnerID: &partnerID}, dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := schedule.DurationFor(visitType)
+	interval := time.Duration(schedule.SlotIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = entities.DefaultSlotIntervalMinutes * time.Minute
+	}
+	now := time.Now()
+	for _, window := range windows {
+		for start := window[0]; !start.Add(duration).After(window[1]); start = start.Add(interval) {
+			if start.Before(now) {
+				continue
+			}
+			end := start.Add(duration)
+			available := capacity(schedule) - len(overlapping(booked, start, end, nil))
+			if available > 0 {
+				slots = append(slots, Slot{Start: start, End: end, Available: available})
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+// CheckConflicts returns the conflicts a booking would run into
+func (uc *AppointmentUseCase) CheckConflicts(ctx context.Context, req *BookAppointmentRequest) ([]entities.AppointmentConflict, error) {
+	visit, schedule, _, err := uc.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.conflicts(ctx, visit, schedule, false)
+}
+
+// BookAppointment books an appointment at a partner clinic and creates the transport task
+func (uc *AppointmentUseCase) BookAppointment(ctx context.Context, req *BookAppointmentRequest, userID primitive.ObjectID) (*entities.VeterinaryVisit, error) {
+	visit, schedule, animal, err := uc.prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts, err := uc.conflicts(ctx, visit, schedule, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		conflictErr := &ConflictError{Conflicts: conflicts}
+		return nil, errors.Wrap(conflictErr, http.StatusConflict, "appointment conflicts: "+conflictErr.Error())
+	}
+
+	transport := req.Transport == nil || *req.Transport
+	var assignee *primitive.ObjectID
+	if transport && req.TransportAssigneeID != "" {
+		id, err := primitive.ObjectIDFromHex(req.TransportAssigneeID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid transport assignee ID")
+		}
+		assignee = &id
+	}
+
+	visit.ID = primitive.NewObjectID()
+	visit.CreatedBy = userID
+	visit.UpdatedBy = userID
+
+	if err := uc.visitRepo.Create(ctx, visit); err != nil {
+		return nil, err
+	}
+
+	// Bookings made at the same time both pass the check above. Checking again against the
+	// appointments booked before this one lets exactly one of them keep the place.
+	raced, err := uc.conflicts(ctx, visit, schedule, true)
+	if err == nil && len(raced) > 0 {
+		conflictErr := &ConflictError{Conflicts: raced}
+		err = errors.Wrap(conflictErr, http.StatusConflict, "appointment conflicts: "+conflictErr.Error())
+	}
+	if err != nil {
+		uc.discardBooking(ctx, visit, nil)
+		return nil, err
+	}
+
+	if transport {
+		task, err := uc.createTransportTask(ctx, visit, animal, schedule, assignee, userID)
+		if err != nil {
+			uc.discardBooking(ctx, visit, nil)
+			return nil, err
+		}
+		visit.TransportTaskID = &task.ID
+		if err := uc.visitRepo.Update(ctx, visit); err != nil {
+			uc.discardBooking(ctx, visit, task)
+			return nil, err
+		}
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionCreate, "veterinary_visit", "", "appointment booked").
+		WithEntityID(visit.ID).
+		WithChanges(map[string]interface{}{"partner_id": visit.PartnerID.Hex(), "start": visit.VisitDate}))
+
+	return visit, nil
+}
+
+// discardBooking deletes an appointment that could not be booked, and its transport task
+func (uc *AppointmentUseCase) discardBooking(ctx context.Context, visit *entities.VeterinaryVisit, task *entities.Task) {
+	if task != nil {
+		if err := uc.taskRepo.Delete(ctx, task.ID); err != nil {
+			log.Error().Err(err).Str("task_id", task.ID.Hex()).Msg("failed to delete the transport task of a discarded appointment")
+		}
+	}
+	if err := uc.visitRepo.Delete(ctx, visit.ID); err != nil {
+		log.Error().Err(err).Str("visit_id", visit.ID.Hex()).Msg("failed to delete a discarded appointment")
+	}
+}
+
+// RescheduleAppointment moves an appointment and its transport task
+func (uc *AppointmentUseCase) RescheduleAppointment(ctx context.Context, id primitive.ObjectID, req *RescheduleRequest, userID primitive.ObjectID) (*entities.VeterinaryVisit, error) {
+	visit, err := uc.findAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if visit.Status != entities.VisitStatusScheduled {
+		return nil, errors.NewBadRequest("only scheduled appointments can be rescheduled")
+	}
+
+	schedule, err := uc.scheduleFor(ctx, *visit.PartnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := visit.VisitDate
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		start, end := visit.AppointmentWindow()
+		duration = end.Sub(start)
+	}
+	setWindow(visit, req.StartTime, duration)
+
+	conflicts, err := uc.conflicts(ctx, visit, schedule, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		conflictErr := &ConflictError{Conflicts: conflicts}
+		return nil, errors.Wrap(conflictErr, http.StatusConflict, "appointment conflicts: "+conflictErr.Error())
+	}
+
+	visit.Sequence++
+	visit.UpdatedBy = userID
+	if err := uc.visitRepo.Update(ctx, visit); err != nil {
+		return nil, err
+	}
+
+	if task := uc.transportTask(ctx, visit); task != nil {
+		departure := transportDeparture(visit, schedule)
+		task.DueDate = &departure
+		task.StartDate = &departure
+		task.UpdatedAt = time.Now()
+		_ = uc.taskRepo.Update(ctx, task)
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "veterinary_visit", "", "appointment rescheduled").
+		WithEntityID(visit.ID).
+		WithChanges(map[string]interface{}{"from": previous, "to": visit.VisitDate}))
+
+	return visit, nil
+}
+
+// CancelAppointment cancels an appointment and its open transport task
+func (uc *AppointmentUseCase) CancelAppointment(ctx context.Context, id primitive.ObjectID, reason string, userID primitive.ObjectID) (*entities.VeterinaryVisit, error) {
+	visit, err := uc.findAppointment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if visit.Status != entities.VisitStatusScheduled {
+		return nil, errors.NewBadRequest("only scheduled appointments can be cancelled")
+	}
+
+	visit.Status = entities.VisitStatusCancelled
+	visit.CancellationReason = reason
+	visit.Sequence++
+	visit.UpdatedBy = userID
+	if err := uc.visitRepo.Update(ctx, visit); err != nil {
+		return nil, err
+	}
+
+	if task := uc.transportTask(ctx, visit); task != nil &&
+		task.Status != entities.TaskStatusCompleted && task.Status != entities.TaskStatusCancelled {
+		task.Cancel()
+		_ = uc.taskRepo.Update(ctx, task)
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "veterinary_visit", "", "appointment cancelled").
+		WithEntityID(visit.ID).
+		WithChanges(map[string]interface{}{"reason": reason}))
+
+	return visit, nil
+}
+
+// ListAppointments lists clinic appointments ordered by start time
+func (uc *AppointmentUseCase) ListAppointments(ctx context.Context, req *ListAppointmentsRequest) ([]*entities.VeterinaryVisit, int64, error) {
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	filter := repositories.VeterinaryVisitFilter{
+		AnimalID:  req.AnimalID,
+		PartnerID: req.PartnerID,
+		StaffID:   req.StaffID,
+		Statuses:  req.Statuses,
+		FromDate:  req.From,
+		ToDate:    req.To,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+		SortBy:    "visit_date",
+		SortOrder: "asc",
+	}
+	// Without a clinic, only visits booked as appointments
+	if filter.PartnerID == nil && len(filter.Statuses) == 0 {
+		filter.Statuses = []string{string(entities.VisitStatusScheduled), string(entities.VisitStatusInProgress)}
+	}
+
+	return uc.visitRepo.List(ctx, filter)
+}
+
+// prepare validates a booking request and builds the visit without saving it
+func (uc *AppointmentUseCase) prepare(ctx context.Context, req *BookAppointmentRequest) (*entities.VeterinaryVisit, *entities.ClinicSchedule, *entities.Animal, error) {
+	animalID, err := primitive.ObjectIDFromHex(req.AnimalID)
+	if err != nil {
+		return nil, nil, nil, errors.NewBadRequest("invalid animal ID")
+	}
+	partnerID, err := primitive.ObjectIDFromHex(req.PartnerID)
+	if err != nil {
+		return nil, nil, nil, errors.NewBadRequest("invalid partner ID")
+	}
+	if req.StartTime.Before(time.Now()) {
+		return nil, nil, nil, errors.NewBadRequest("appointments cannot be booked in the past")
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	partner, err := uc.findClinic(ctx, partnerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if partner.Status == entities.PartnerStatusInactive || partner.Status == entities.PartnerStatusSuspended {
+		return nil, nil, nil, errors.NewBadRequest("the clinic is not an active partner")
+	}
+	schedule, err := uc.scheduleFor(ctx, partnerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	staffIDs := append([]string{req.TransportAssigneeID}, req.AssignedStaff...)
+	staff := make([]primitive.ObjectID, 0, len(staffIDs))
+	for _, hex := range staffIDs {
+		if hex == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, nil, nil, errors.NewBadRequest("invalid staff ID")
+		}
+		if !containsID(staff, id) {
+			staff = append(staff, id)
+		}
+	}
+
+	visit := &entities.VeterinaryVisit{
+		AnimalID:         animal.ID,
+		VisitType:        req.VisitType,
+		Status:           entities.VisitStatusScheduled,
+		VeterinarianName: req.VeterinarianName,
+		ClinicName:       partner.Name,
+		ClinicAddress:    formatAddress(partner.Address),
+		ClinicPhone:      partner.ContactInfo.Phone,
+		PartnerID:        &partner.ID,
+		AssignedStaff:    staff,
+		ChiefComplaint:   req.ChiefComplaint,
+		InternalNotes:    req.InternalNotes,
+	}
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		duration = schedule.DurationFor(req.VisitType)
+	}
+	setWindow(visit, req.StartTime, duration)
+
+	return visit, schedule, animal, nil
+}
+
+// conflicts checks the clinic's hours and capacity and the animal's and staff's other appointments
+func (uc *AppointmentUseCase) conflicts(ctx context.Context, visit *entities.VeterinaryVisit, schedule *entities.ClinicSchedule, bookedBefore bool) ([]entities.AppointmentConflict, error) {
+	start, end := visit.AppointmentWindow()
+	var conflicts []entities.AppointmentConflict
+
+	// blocking returns the overlapping visits, leaving out the visit itself and, when
+	// checking a saved booking, the visits booked after it
+	blocking := func(visits []*entities.VeterinaryVisit, start, end time.Time, exclude *primitive.ObjectID) []*entities.VeterinaryVisit {
+		result := overlapping(visits, start, end, exclude)
+		if !bookedBefore {
+			return result
+		}
+		earlier := result[:0]
+		for _, other := range result {
+			if bytes.Compare(other.ID[:], visit.ID[:]) < 0 {
+				earlier = append(earlier, other)
+			}
+		}
+		return earlier
+	}
+
+	// Emergencies may be booked outside the published hours
+	if !bookedBefore && visit.VisitType != entities.VisitTypeEmergency && !schedule.IsOpen(start, end) {
+		conflicts = append(conflicts, entities.AppointmentConflict{
+			Type:    entities.ConflictClinicClosed,
+			Message: "the clinic is closed at " + start.In(schedule.Location()).Format("2006-01-02 15:04"),
+		})
+	}
+
+	atClinic, err := uc.blockingVisits(ctx, repositories.VeterinaryVisitFilter{PartnerID: visit.PartnerID}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if booked := blocking(atClinic, start, end, &visit.ID); len(booked) >= capacity(schedule) {
+		conflict := conflictWith(entities.ConflictClinicCapacity, booked[0],
+			fmt.Sprintf("the clinic is fully booked (%d of %d places)", len(booked), capacity(schedule)))
+		conflicts = append(conflicts, conflict)
+	}
+
+	forAnimal, err := uc.blockingVisits(ctx, repositories.VeterinaryVisitFilter{AnimalID: &visit.AnimalID}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range blocking(forAnimal, start, end, &visit.ID) {
+		conflicts = append(conflicts, conflictWith(entities.ConflictAnimal, other,
+			"the animal already has an appointment at "+clinicName(other)))
+	}
+
+	for _, staffID := range visit.AssignedStaff {
+		staffID := staffID
+		forStaff, err := uc.blockingVisits(ctx, repositories.VeterinaryVisitFilter{StaffID: &staffID}, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range blocking(forStaff, start, end, &visit.ID) {
+			conflict := conflictWith(entities.ConflictStaff, other, "a staff member is assigned to another appointment at "+clinicName(other))
+			conflict.UserID = &staffID
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// maxAppointmentLength bounds how far back an overlapping appointment can start
+const maxAppointmentLength = 24 * time.Hour
+
+// blockingVisits returns the scheduled and in-progress visits matching the
+// filter that may overlap the period
+func (uc *AppointmentUseCase) blockingVisits(ctx context.Context, filter repositories.VeterinaryVisitFilter, start, end time.Time) ([]*entities.VeterinaryVisit, error) {
+	from := start.Add(-maxAppointmentLength)
+	filter.FromDate = &from
+	filter.ToDate = &end
+	filter.Statuses = []string{string(entities.VisitStatusScheduled), string(entities.VisitStatusInProgress)}
+	filter.SortBy = "visit_date"
+	filter.SortOrder = "asc"
+
+	visits, _, err := uc.visitRepo.List(ctx, filter)
+	return visits, err
+}
+
+// createTransportTask creates the task of driving the animal to the clinic
+func (uc *AppointmentUseCase) createTransportTask(ctx context.Context, visit *entities.VeterinaryVisit, animal *entities.Animal, schedule *entities.ClinicSchedule, assignee *primitive.ObjectID, userID primitive.ObjectID) (*entities.Task, error) {
+	priority := entities.TaskPriorityMedium
+	switch visit.VisitType {
+	case entities.VisitTypeEmergency:
+		priority = entities.TaskPriorityUrgent
+	case entities.VisitTypeSurgery, entities.VisitTypeSpayNeuter:
+		priority = entities.TaskPriorityHigh
+	}
+
+	start, end := visit.AppointmentWindow()
+	local := schedule.Location()
+	task := entities.NewTask(fmt.Sprintf("Transport %s to %s", animalName(animal), visit.ClinicName), entities.TaskCategoryAnimalCare, priority, userID)
+	task.Description = fmt.Sprintf("%s appointment at %s, %s-%s.", visit.VisitType, visit.ClinicName,
+		start.In(local).Format("2006-01-02 15:04"), end.In(local).Format("15:04"))
+	if visit.ClinicAddress != "" {
+		task.Description += "\nAddress: " + visit.ClinicAddress
+	}
+	if visit.ChiefComplaint != "" {
+		task.Description += "\nReason: " + visit.ChiefComplaint
+	}
+	departure := transportDeparture(visit, schedule)
+	task.DueDate = &departure
+	task.StartDate = &departure
+	task.RelatedEntity = "veterinary_visit"
+	task.RelatedEntityID = &visit.ID
+	task.Tags = []string{"transport", "appointment"}
+	if assignee != nil {
+		task.AssignTo(*assignee)
+	}
+
+	if err := uc.taskRepo.Create(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// transportTask returns the appointment's transport task, if it still exists
+func (uc *AppointmentUseCase) transportTask(ctx context.Context, visit *entities.VeterinaryVisit) *entities.Task {
+	if visit.TransportTaskID == nil {
+		return nil
+	}
+	task, err := uc.taskRepo.FindByID(ctx, *visit.TransportTaskID)
+	if err != nil {
+		return nil
+	}
+	return task
+}
+
+func (uc *AppointmentUseCase) findAppointment(ctx context.Context, id primitive.ObjectID) (*entities.VeterinaryVisit, error) {
+	visit, err := uc.visitRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if visit.PartnerID == nil {
+		return nil, errors.NewBadRequest("the visit is not a clinic appointment")
+	}
+	return visit, nil
+}
+
+// findClinic returns a partner of type veterinary
+func (uc *AppointmentUseCase) findClinic(ctx context.Context, partnerID primitive.ObjectID) (*entities.Partner, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if partner.Type != entities.PartnerTypeVeterinary {
+		return nil, errors.NewBadRequest("the partner is not a veterinary clinic")
+	}
+	return partner, nil
+}
+
+// scheduleFor returns the stored schedule of a clinic or an unsaved default one
+func (uc *AppointmentUseCase) scheduleFor(ctx context.Context, partnerID primitive.ObjectID) (*entities.ClinicSchedule, error) {
+	schedule, err := uc.scheduleRepo.FindByPartnerID(ctx, partnerID)
+	if err == errors.ErrNotFound {
+		return entities.NewClinicSchedule(partnerID), nil
+	}
+	return schedule, err
+}
+
+func setWindow(visit *entities.VeterinaryVisit, start time.Time, duration time.Duration) {
+	end := start.Add(duration)
+	visit.VisitDate = start
+	visit.ScheduledDate = &start
+	visit.AppointmentEnd = &end
+	visit.DurationMinutes = int(duration / time.Minute)
+}
+
+func transportDeparture(visit *entities.VeterinaryVisit, schedule *entities.ClinicSchedule) time.Time {
+	start, _ := visit.AppointmentWindow()
+	return start.Add(-time.Duration(schedule.TransportLeadMinutes) * time.Minute)
+}
+
+// overlapping returns the visits overlapping the period, skipping the excluded visit
+func overlapping(visits []*entities.VeterinaryVisit, start, end time.Time, exclude *primitive.ObjectID) []*entities.VeterinaryVisit {
+	var result []*entities.VeterinaryVisit
+	for _, visit := range visits {
+		if exclude != nil && visit.ID == *exclude {
+			continue
+		}
+		if visit.BlocksCalendar() && visit.Overlaps(start, end) {
+			result = append(result, visit)
+		}
+	}
+	return result
+}
+
+func conflictWith(conflictType entities.AppointmentConflictType, visit *entities.VeterinaryVisit, message string) entities.AppointmentConflict {
+	start, end := visit.AppointmentWindow()
+	return entities.AppointmentConflict{
+		Type:    conflictType,
+		VisitID: &visit.ID,
+		Start:   &start,
+		End:     &end,
+		Message: message,
+	}
+}
+
+func capacity(schedule *entities.ClinicSchedule) int {
+	if schedule.Capacity < 1 {
+		return 1
+	}
+	return schedule.Capacity
+}
+
+func clinicName(visit *entities.VeterinaryVisit) string {
+	if visit.ClinicName != "" {
+		return visit.ClinicName
+	}
+	return "another clinic"
+}
+
+func animalName(animal *entities.Animal) string {
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	if animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	return "animal"
+}
+
+func formatAddress(address entities.AddressInfo) string {
+	var parts []string
+	for _, part := range []string{address.Street, strings.TrimSpace(address.ZipCode + " " + address.City), address.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
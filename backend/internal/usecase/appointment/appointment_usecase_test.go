@@ -0,0 +1,368 @@
+package appointment
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type testRepos struct {
+	visits    *mocks.VeterinaryVisitRepository
+	schedules *mocks.ClinicScheduleRepository
+	feeds     *mocks.CalendarFeedRepository
+	partners  *mocks.PartnerRepository
+	animals   *mocks.AnimalRepository
+	tasks     *mocks.TaskRepository
+}
+
+func newTestUseCase() (*AppointmentUseCase, *testRepos) {
+	repos := &testRepos{
+		visits:    new(mocks.VeterinaryVisitRepository),
+		schedules: new(mocks.ClinicScheduleRepository),
+		feeds:     new(mocks.CalendarFeedRepository),
+		partners:  new(mocks.PartnerRepository),
+		animals:   new(mocks.AnimalRepository),
+		tasks:     new(mocks.TaskRepository),
+	}
+	auditLogRepo := testutil.AuditLogs()
+
+	useCase := NewAppointmentUseCase(repos.visits, repos.schedules, repos.feeds, repos.partners, repos.animals, repos.tasks, auditLogRepo)
+	return useCase, repos
+}
+
+// setupClinic registers a clinic open 08:00-12:00 Warsaw time on weekdays and
+// returns it with a Monday at least a week ahead
+func setupClinic(ctx context.Context, repos *testRepos, capacity int) (*entities.Partner, time.Time) {
+	clinic := testutil.Clinic(repos.partners)
+	schedule := entities.NewClinicSchedule(clinic.ID)
+	schedule.ID = primitive.NewObjectID()
+	schedule.Timezone = "Europe/Warsaw"
+	schedule.Capacity = capacity
+	schedule.SlotIntervalMinutes = 30
+	for day := time.Monday; day <= time.Friday; day++ {
+		schedule.WorkingHours = append(schedule.WorkingHours, entities.ClinicWorkingHours{Weekday: day, Open: "08:00", Close: "12:00"})
+	}
+
+	repos.schedules.On("FindByPartnerID", ctx, clinic.ID).Return(schedule, nil)
+
+	monday := time.Now().In(schedule.Location()).AddDate(0, 0, 7)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	monday = time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, schedule.Location())
+	return clinic, monday
+}
+
+func setupAnimal(ctx context.Context, repos *testRepos) *entities.Animal {
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: "Burek"}}
+	repos.animals.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	return animal
+}
+
+func byPartner(partnerID primitive.ObjectID) interface{} {
+	return mock.MatchedBy(func(filter repositories.VeterinaryVisitFilter) bool {
+		return filter.PartnerID != nil && *filter.PartnerID == partnerID
+	})
+}
+
+func byAnimal() interface{} {
+	return mock.MatchedBy(func(filter repositories.VeterinaryVisitFilter) bool {
+		return filter.AnimalID != nil
+	})
+}
+
+func byStaff() interface{} {
+	return mock.MatchedBy(func(filter repositories.VeterinaryVisitFilter) bool {
+		return filter.StaffID != nil
+	})
+}
+
+func bookedVisit(animalID, partnerID primitive.ObjectID, start time.Time, minutes int) *entities.VeterinaryVisit {
+	visit := &entities.VeterinaryVisit{
+		ID:         primitive.NewObjectID(),
+		AnimalID:   animalID,
+		PartnerID:  &partnerID,
+		ClinicName: "Vet Clinic",
+		Status:     entities.VisitStatusScheduled,
+		VisitType:  entities.VisitTypeCheckup,
+	}
+	setWindow(visit, start, time.Duration(minutes)*time.Minute)
+	return visit
+}
+
+func TestAppointmentUseCase_BookCreatesTransportTask(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 1)
+	animal := setupAnimal(ctx, repos)
+	driver := primitive.NewObjectID()
+
+	repos.visits.On("List", ctx, mock.Anything).Return([]*entities.VeterinaryVisit{}, int64(0), nil)
+	repos.tasks.On("Create", ctx, mock.AnythingOfType("*entities.Task")).Return(nil)
+	repos.visits.On("Create", ctx, mock.AnythingOfType("*entities.VeterinaryVisit")).Return(nil)
+	repos.visits.On("Update", ctx, mock.AnythingOfType("*entities.VeterinaryVisit")).Return(nil)
+
+	start := monday.Add(10 * time.Hour)
+	visit, err := useCase.BookAppointment(ctx, &BookAppointmentRequest{
+		AnimalID:            animal.ID.Hex(),
+		PartnerID:           clinic.ID.Hex(),
+		VisitType:           entities.VisitTypeSpayNeuter,
+		StartTime:           start,
+		TransportAssigneeID: driver.Hex(),
+	}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.VisitStatusScheduled, visit.Status)
+	assert.Equal(t, "Vet Clinic", visit.ClinicName)
+	assert.Equal(t, "Długa 1, 31-001 Kraków", visit.ClinicAddress)
+	assert.Equal(t, 120, visit.DurationMinutes, "default spay/neuter duration")
+	assert.Equal(t, start.Add(2*time.Hour), *visit.AppointmentEnd)
+	assert.Equal(t, []primitive.ObjectID{driver}, visit.AssignedStaff)
+	require.NotNil(t, visit.TransportTaskID)
+
+	task := repos.tasks.Calls[0].Arguments.Get(1).(*entities.Task)
+	assert.Equal(t, *visit.TransportTaskID, task.ID)
+	assert.Equal(t, "Transport Burek to Vet Clinic", task.Title)
+	assert.Equal(t, entities.TaskPriorityHigh, task.Priority)
+	assert.Equal(t, start.Add(-30*time.Minute), *task.DueDate)
+	assert.Equal(t, &driver, task.AssignedTo)
+	assert.Equal(t, &visit.ID, task.RelatedEntityID)
+	repos.visits.AssertCalled(t, "Update", ctx, visit)
+}
+
+func TestAppointmentUseCase_BookLosesARaceToAnEarlierBooking(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 1)
+	animal := setupAnimal(ctx, repos)
+	start := monday.Add(10 * time.Hour)
+
+	// The other booking is not there when this one is checked, but is by the time it is saved
+	other := bookedVisit(primitive.NewObjectID(), clinic.ID, start, 30)
+	repos.visits.On("List", ctx, byPartner(clinic.ID)).Return([]*entities.VeterinaryVisit{}, int64(0), nil).Once()
+	repos.visits.On("List", ctx, byPartner(clinic.ID)).Return([]*entities.VeterinaryVisit{other}, int64(1), nil)
+	repos.visits.On("List", ctx, byAnimal()).Return([]*entities.VeterinaryVisit{}, int64(0), nil)
+	repos.visits.On("Create", ctx, mock.AnythingOfType("*entities.VeterinaryVisit")).Return(nil)
+	repos.visits.On("Delete", ctx, mock.Anything).Return(nil)
+
+	_, err := useCase.BookAppointment(ctx, &BookAppointmentRequest{
+		AnimalID:  animal.ID.Hex(),
+		PartnerID: clinic.ID.Hex(),
+		VisitType: entities.VisitTypeCheckup,
+		StartTime: start,
+	}, primitive.NewObjectID())
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, err.(*errors.AppError).Code)
+	var booked *entities.VeterinaryVisit
+	for _, call := range repos.visits.Calls {
+		if call.Method == "Create" {
+			booked = call.Arguments.Get(1).(*entities.VeterinaryVisit)
+		}
+	}
+	require.NotNil(t, booked)
+	repos.visits.AssertCalled(t, "Delete", ctx, booked.ID)
+	repos.tasks.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAppointmentUseCase_BookRejectsConflicts(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 1)
+	animal := setupAnimal(ctx, repos)
+
+	existing := bookedVisit(animal.ID, clinic.ID, monday.Add(9*time.Hour), 60)
+	repos.visits.On("List", ctx, byPartner(clinic.ID)).Return([]*entities.VeterinaryVisit{existing}, int64(1), nil)
+	repos.visits.On("List", ctx, byAnimal()).Return([]*entities.VeterinaryVisit{existing}, int64(1), nil)
+
+	_, err := useCase.BookAppointment(ctx, &BookAppointmentRequest{
+		AnimalID:  animal.ID.Hex(),
+		PartnerID: clinic.ID.Hex(),
+		VisitType: entities.VisitTypeCheckup,
+		StartTime: monday.Add(9*time.Hour + 30*time.Minute),
+	}, primitive.NewObjectID())
+	require.Error(t, err)
+
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.Code)
+	conflictErr, ok := appErr.Err.(*ConflictError)
+	require.True(t, ok)
+
+	var types []entities.AppointmentConflictType
+	for _, conflict := range conflictErr.Conflicts {
+		types = append(types, conflict.Type)
+		assert.Equal(t, &existing.ID, conflict.VisitID)
+	}
+	assert.ElementsMatch(t, []entities.AppointmentConflictType{entities.ConflictClinicCapacity, entities.ConflictAnimal}, types)
+	repos.visits.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	repos.tasks.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAppointmentUseCase_CheckConflictsOutsideHours(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 2)
+	animal := setupAnimal(ctx, repos)
+	repos.visits.On("List", ctx, mock.Anything).Return([]*entities.VeterinaryVisit{}, int64(0), nil)
+
+	req := &BookAppointmentRequest{
+		AnimalID:  animal.ID.Hex(),
+		PartnerID: clinic.ID.Hex(),
+		VisitType: entities.VisitTypeCheckup,
+		StartTime: monday.Add(11*time.Hour + 45*time.Minute),
+	}
+	conflicts, err := useCase.CheckConflicts(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1, "a 30 minute checkup does not fit before closing at 12:00")
+	assert.Equal(t, entities.ConflictClinicClosed, conflicts[0].Type)
+
+	req.VisitType = entities.VisitTypeEmergency
+	conflicts, err = useCase.CheckConflicts(ctx, req)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts, "emergencies may be booked outside the working hours")
+}
+
+func TestAppointmentUseCase_StaffDoubleBooking(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 3)
+	animal := setupAnimal(ctx, repos)
+	staff := primitive.NewObjectID()
+
+	otherClinic := primitive.NewObjectID()
+	elsewhere := bookedVisit(primitive.NewObjectID(), otherClinic, monday.Add(10*time.Hour), 30)
+	elsewhere.ClinicName = "Other Clinic"
+	elsewhere.AssignedStaff = []primitive.ObjectID{staff}
+
+	repos.visits.On("List", ctx, byPartner(clinic.ID)).Return([]*entities.VeterinaryVisit{}, int64(0), nil)
+	repos.visits.On("List", ctx, byAnimal()).Return([]*entities.VeterinaryVisit{}, int64(0), nil)
+	repos.visits.On("List", ctx, byStaff()).Return([]*entities.VeterinaryVisit{elsewhere}, int64(1), nil)
+
+	conflicts, err := useCase.CheckConflicts(ctx, &BookAppointmentRequest{
+		AnimalID:      animal.ID.Hex(),
+		PartnerID:     clinic.ID.Hex(),
+		VisitType:     entities.VisitTypeVaccination,
+		StartTime:     monday.Add(10*time.Hour + 15*time.Minute),
+		AssignedStaff: []string{staff.Hex()},
+	})
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, entities.ConflictStaff, conflicts[0].Type)
+	assert.Equal(t, &staff, conflicts[0].UserID)
+	assert.Contains(t, conflicts[0].Message, "Other Clinic")
+}
+
+func TestAppointmentUseCase_GetAvailableSlots(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 1)
+
+	booked := bookedVisit(primitive.NewObjectID(), clinic.ID, monday.Add(9*time.Hour), 45)
+	cancelled := bookedVisit(primitive.NewObjectID(), clinic.ID, monday.Add(11*time.Hour), 30)
+	cancelled.Status = entities.VisitStatusCancelled
+	repos.visits.On("List", ctx, byPartner(clinic.ID)).Return([]*entities.VeterinaryVisit{booked, cancelled}, int64(2), nil)
+
+	slots, err := useCase.GetAvailableSlots(ctx, clinic.ID, monday.Format("2006-01-02"), entities.VisitTypeCheckup)
+	require.NoError(t, err)
+
+	var starts []string
+	for _, slot := range slots {
+		starts = append(starts, slot.Start.Format("15:04"))
+		assert.Equal(t, 30*time.Minute, slot.End.Sub(slot.Start))
+	}
+	assert.Equal(t, []string{"08:00", "08:30", "10:00", "10:30", "11:00", "11:30"}, starts)
+
+	sunday, err := useCase.GetAvailableSlots(ctx, clinic.ID, monday.AddDate(0, 0, -1).Format("2006-01-02"), entities.VisitTypeCheckup)
+	require.NoError(t, err)
+	assert.Empty(t, sunday)
+}
+
+func TestAppointmentUseCase_RescheduleAndCancelUpdateTransportTask(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 1)
+
+	visit := bookedVisit(primitive.NewObjectID(), clinic.ID, monday.Add(9*time.Hour), 30)
+	task := entities.NewTask("Transport", entities.TaskCategoryAnimalCare, entities.TaskPriorityMedium, primitive.NewObjectID())
+	task.ID = primitive.NewObjectID()
+	visit.TransportTaskID = &task.ID
+
+	repos.visits.On("FindByID", ctx, visit.ID).Return(visit, nil)
+	repos.visits.On("List", ctx, mock.Anything).Return([]*entities.VeterinaryVisit{visit}, int64(1), nil)
+	repos.visits.On("Update", ctx, visit).Return(nil)
+	repos.tasks.On("FindByID", ctx, task.ID).Return(task, nil)
+	repos.tasks.On("Update", ctx, task).Return(nil)
+
+	newStart := monday.Add(9*time.Hour + 15*time.Minute)
+	moved, err := useCase.RescheduleAppointment(ctx, visit.ID, &RescheduleRequest{StartTime: newStart}, primitive.NewObjectID())
+	require.NoError(t, err, "the appointment does not conflict with itself")
+	assert.Equal(t, newStart, moved.VisitDate)
+	assert.Equal(t, newStart.Add(30*time.Minute), *moved.AppointmentEnd)
+	assert.Equal(t, 1, moved.Sequence)
+	assert.Equal(t, newStart.Add(-30*time.Minute), *task.DueDate)
+
+	cancelled, err := useCase.CancelAppointment(ctx, visit.ID, "clinic closed for the day", primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, entities.VisitStatusCancelled, cancelled.Status)
+	assert.Equal(t, 2, cancelled.Sequence)
+	assert.Equal(t, entities.TaskStatusCancelled, task.Status)
+}
+
+func TestAppointmentUseCase_RenderFeed(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic, monday := setupClinic(ctx, repos, 2)
+	animal := setupAnimal(ctx, repos)
+
+	feed := &entities.CalendarFeed{
+		ID:        primitive.NewObjectID(),
+		Token:     "secret",
+		Scope:     entities.CalendarFeedScopeClinic,
+		Name:      "Vet Clinic appointments",
+		PartnerID: &clinic.ID,
+	}
+	scheduled := bookedVisit(animal.ID, clinic.ID, monday.Add(9*time.Hour), 30)
+	scheduled.ClinicAddress = "Długa 1, 31-001 Kraków"
+	scheduled.ChiefComplaint = "Annual checkup"
+	cancelled := bookedVisit(animal.ID, clinic.ID, monday.Add(10*time.Hour), 30)
+	cancelled.Status = entities.VisitStatusCancelled
+	cancelled.Sequence = 1
+	walkIn := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: animal.ID, Status: entities.VisitStatusCompleted, VisitDate: monday}
+
+	repos.feeds.On("FindByToken", ctx, "secret").Return(feed, nil)
+	repos.feeds.On("FindByToken", ctx, "unknown").Return(nil, errors.ErrNotFound)
+	repos.feeds.On("Update", ctx, feed).Return(nil)
+	repos.visits.On("List", ctx, byPartner(clinic.ID)).Return([]*entities.VeterinaryVisit{scheduled, cancelled, walkIn}, int64(3), nil)
+
+	content, err := useCase.RenderFeed(ctx, "secret")
+	require.NoError(t, err)
+
+	out := string(content)
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"), "visits without an appointment at a partner clinic are left out")
+	assert.Contains(t, out, "X-WR-CALNAME:Vet Clinic appointments\r\n")
+	assert.Contains(t, out, "UID:visit-"+scheduled.ID.Hex()+"@animalsys\r\n")
+	assert.Contains(t, out, "SUMMARY:Checkup: Burek\r\n")
+	assert.Contains(t, out, "LOCATION:Vet Clinic\\, Długa 1\\, 31-001 Kraków\r\n")
+	assert.Contains(t, out, "DESCRIPTION:Reason: Annual checkup\r\n")
+	assert.Contains(t, out, "SEQUENCE:1\r\n")
+	assert.Contains(t, out, "STATUS:CANCELLED\r\n", "cancelled appointments stay in the feed so calendar apps remove them")
+	assert.NotNil(t, feed.LastAccessedAt)
+
+	_, err = useCase.RenderFeed(ctx, "unknown")
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, appErr.Code)
+}
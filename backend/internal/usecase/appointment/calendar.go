@@ -0,0 +1,217 @@
+package appointment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/ical"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Feeds cover appointments from feedPastDays ago to feedFutureDays ahead
+const (
+	feedPastDays   = 30
+	feedFutureDays = 180
+	feedMaxEvents  = 1000
+)
+
+// CreateFeedRequest represents a request to create a calendar subscription
+type CreateFeedRequest struct {
+	Scope     entities.CalendarFeedScope `json:"scope" validate:"required,oneof=clinic user"`
+	PartnerID string                     `json:"partner_id,omitempty"`
+	Name      string                     `json:"name,omitempty"`
+}
+
+// CreateFeed creates a secret calendar feed of a clinic's appointments or of
+// the appointments the user is assigned to
+func (uc *AppointmentUseCase) CreateFeed(ctx context.Context, req *CreateFeedRequest, userID primitive.ObjectID) (*entities.CalendarFeed, error) {
+	feed := &entities.CalendarFeed{
+		Scope:  req.Scope,
+		Name:   req.Name,
+		UserID: userID,
+	}
+
+	switch req.Scope {
+	case entities.CalendarFeedScopeClinic:
+		partnerID, err := primitive.ObjectIDFromHex(req.PartnerID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid partner ID")
+		}
+		partner, err := uc.findClinic(ctx, partnerID)
+		if err != nil {
+			return nil, err
+		}
+		feed.PartnerID = &partner.ID
+		if feed.Name == "" {
+			feed.Name = partner.Name + " appointments"
+		}
+	case entities.CalendarFeedScopeUser:
+		if feed.Name == "" {
+			feed.Name = "My vet appointments"
+		}
+	default:
+		return nil, errors.NewBadRequest("scope must be clinic or user")
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	feed.Token = token
+
+	if err := uc.feedRepo.Create(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionCreate, "calendar_feed", feed.Name, "").
+		WithEntityID(feed.ID))
+
+	return feed, nil
+}
+
+// ListFeeds returns the calendar feeds of a user
+func (uc *AppointmentUseCase) ListFeeds(ctx context.Context, userID primitive.ObjectID) ([]*entities.CalendarFeed, error) {
+	return uc.feedRepo.ListByUser(ctx, userID)
+}
+
+// RevokeFeed deletes a calendar feed; its URL stops working
+func (uc *AppointmentUseCase) RevokeFeed(ctx context.Context, id, userID primitive.ObjectID) error {
+	feed, err := uc.feedRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if feed.UserID != userID {
+		return errors.NewForbidden("the calendar feed belongs to another user")
+	}
+
+	if err := uc.feedRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionDelete, "calendar_feed", feed.Name, "").
+		WithEntityID(feed.ID))
+
+	return nil
+}
+
+// RenderFeed returns the iCalendar content of the feed with the token
+func (uc *AppointmentUseCase) RenderFeed(ctx context.Context, token string) ([]byte, error) {
+	feed, err := uc.feedRepo.FindByToken(ctx, token)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewNotFound("calendar feed not found")
+		}
+		return nil, err
+	}
+
+	from := time.Now().AddDate(0, 0, -feedPastDays)
+	to := time.Now().AddDate(0, 0, feedFutureDays)
+	filter := repositories.VeterinaryVisitFilter{
+		FromDate:  &from,
+		ToDate:    &to,
+		Limit:     feedMaxEvents,
+		SortBy:    "visit_date",
+		SortOrder: "asc",
+	}
+	switch feed.Scope {
+	case entities.CalendarFeedScopeClinic:
+		filter.PartnerID = feed.PartnerID
+	case entities.CalendarFeedScopeUser:
+		filter.StaffID = &feed.UserID
+	}
+
+	visits, _, err := uc.visitRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{Name: feed.Name}
+	names := make(map[primitive.ObjectID]string)
+	for _, visit := range visits {
+		// Clinic feeds also list the clinic's past visits; only booked appointments have a window
+		if visit.PartnerID == nil || visit.Status == entities.VisitStatusNoShow {
+			continue
+		}
+		calendar.Events = append(calendar.Events, uc.calendarEvent(ctx, visit, names))
+	}
+
+	now := time.Now()
+	feed.LastAccessedAt = &now
+	_ = uc.feedRepo.Update(ctx, feed)
+
+	return calendar.Bytes(), nil
+}
+
+// calendarEvent converts an appointment to an iCalendar event
+func (uc *AppointmentUseCase) calendarEvent(ctx context.Context, visit *entities.VeterinaryVisit, names map[primitive.ObjectID]string) ical.Event {
+	name, ok := names[visit.AnimalID]
+	if !ok {
+		name = "animal"
+		if animal, err := uc.animalRepo.FindByID(ctx, visit.AnimalID); err == nil {
+			name = animalName(animal)
+		}
+		names[visit.AnimalID] = name
+	}
+
+	start, end := visit.AppointmentWindow()
+	var description []string
+	if visit.ChiefComplaint != "" {
+		description = append(description, "Reason: "+visit.ChiefComplaint)
+	}
+	if visit.VeterinarianName != "" {
+		description = append(description, "Veterinarian: "+visit.VeterinarianName)
+	}
+	if visit.ClinicPhone != "" {
+		description = append(description, "Clinic phone: "+visit.ClinicPhone)
+	}
+	if visit.TransportTaskID != nil {
+		description = append(description, "Transport task: "+visit.TransportTaskID.Hex())
+	}
+
+	location := visit.ClinicName
+	if visit.ClinicAddress != "" {
+		location += ", " + visit.ClinicAddress
+	}
+
+	status := ical.StatusConfirmed
+	if visit.Status == entities.VisitStatusCancelled {
+		status = ical.StatusCancelled
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("visit-%s@animalsys", visit.ID.Hex()),
+		Sequence:    visit.Sequence,
+		Start:       start,
+		End:         end,
+		Summary:     fmt.Sprintf("%s: %s", visitTypeLabel(visit.VisitType), name),
+		Description: strings.Join(description, "\n"),
+		Location:    location,
+		Status:      status,
+		Updated:     visit.UpdatedAt,
+	}
+}
+
+// visitTypeLabel turns "spay_neuter" into "Spay neuter"
+func visitTypeLabel(visitType entities.VisitType) string {
+	label := strings.ReplaceAll(string(visitType), "_", " ")
+	if label == "" {
+		return "Vet visit"
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// newFeedToken returns an unguessable URL-safe token
+func newFeedToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, 500, "failed to generate calendar feed token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
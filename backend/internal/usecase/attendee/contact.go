@@ -0,0 +1,29 @@
+// Package attendee holds what the event, ticketing and outcome use cases share about the
+// people registered for an event.
+package attendee
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VolunteerFinder looks up the volunteer record of an attendee
+type VolunteerFinder interface {
+	FindByID(ctx context.Context, id primitive.ObjectID) (*entities.Volunteer, error)
+}
+
+// Contact returns the name, email and phone of an attendee, from the registration or,
+// when it has neither an email nor a phone, from their volunteer record
+func Contact(ctx context.Context, volunteers VolunteerFinder, attendance *entities.EventAttendance) (string, string, string) {
+	name, email, phone := attendance.GuestName, attendance.GuestEmail, attendance.GuestPhone
+	if email == "" && phone == "" && attendance.VolunteerID != nil && volunteers != nil {
+		if volunteer, err := volunteers.FindByID(ctx, *attendance.VolunteerID); err == nil {
+			name = strings.TrimSpace(volunteer.FirstName + " " + volunteer.LastName)
+			email, phone = volunteer.Email, volunteer.Phone
+		}
+	}
+	return name, email, phone
+}
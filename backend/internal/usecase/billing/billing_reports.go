@@ -0,0 +1,570 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// invoiceLagDays is how long after a service clinics are expected to invoice it
+const invoiceLagDays = 90
+
+// ChargeFundRequest represents a request to pay part of an invoice from medical donations
+type ChargeFundRequest struct {
+	// DonationID charges a single donation; without it the oldest medical donations with funds left are used
+	DonationID string `json:"donation_id,omitempty"`
+
+	// Amount defaults to the part of the invoice not charged yet
+	Amount *float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+// MedicalFundDonation is a restricted medical donation and how much of it was spent
+type MedicalFundDonation struct {
+	DonationID   primitive.ObjectID `json:"donation_id"`
+	DonorName    string             `json:"donor_name"`
+	CampaignName string             `json:"campaign_name,omitempty"`
+	DonationDate time.Time          `json:"donation_date"`
+	Amount       float64            `json:"amount"`
+	Charged      float64            `json:"charged"`
+	Remaining    float64            `json:"remaining"`
+}
+
+// MedicalFund summarizes the restricted medical donations
+type MedicalFund struct {
+	Donations      []MedicalFundDonation `json:"donations"`
+	TotalAmount    float64               `json:"total_amount"`
+	TotalCharged   float64               `json:"total_charged"`
+	TotalRemaining float64               `json:"total_remaining"`
+}
+
+// ExpenditureItem is a medical cost of an animal
+type ExpenditureItem struct {
+	Date          time.Time           `json:"date"`
+	Description   string              `json:"description"`
+	ClinicName    string              `json:"clinic_name,omitempty"`
+	Source        string              `json:"source"` // "invoice" or "visit" (cost recorded on a visit not invoiced yet)
+	InvoiceID     *primitive.ObjectID `json:"invoice_id,omitempty"`
+	InvoiceNumber string              `json:"invoice_number,omitempty"`
+	VisitID       *primitive.ObjectID `json:"visit_id,omitempty"`
+	Amount        float64             `json:"amount"`
+	ChargedToFund float64             `json:"charged_to_fund"`
+}
+
+// AnimalExpenditure is the medical spending on an animal
+type AnimalExpenditure struct {
+	AnimalID      primitive.ObjectID `json:"animal_id"`
+	AnimalName    string             `json:"animal_name"`
+	Invoiced      float64            `json:"invoiced"`
+	Uninvoiced    float64            `json:"uninvoiced"`
+	Total         float64            `json:"total"`
+	ChargedToFund float64            `json:"charged_to_fund"`
+	Items         []ExpenditureItem  `json:"items,omitempty"`
+}
+
+// MonthlyExpenditure is the medical spending in a month
+type MonthlyExpenditure struct {
+	Month         string  `json:"month"` // "2006-01"
+	Invoiced      float64 `json:"invoiced"`
+	Discounts     float64 `json:"discounts"`
+	Uninvoiced    float64 `json:"uninvoiced"`
+	Total         float64 `json:"total"`
+	ChargedToFund float64 `json:"charged_to_fund"`
+	Animals       int     `json:"animals"`
+}
+
+// ExpenditureReport is the medical spending in a period by month and by animal
+type ExpenditureReport struct {
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Months        []MonthlyExpenditure `json:"months"`
+	Animals       []AnimalExpenditure  `json:"animals"`
+	Invoiced      float64              `json:"invoiced"`
+	Discounts     float64              `json:"discounts"`
+	Uninvoiced    float64              `json:"uninvoiced"`
+	Total         float64              `json:"total"`
+	ChargedToFund float64              `json:"charged_to_fund"`
+}
+
+// ChargeToMedicalFund pays part of an invoice from restricted medical donations
+func (uc *BillingUseCase) ChargeToMedicalFund(ctx context.Context, id primitive.ObjectID, req *ChargeFundRequest, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == entities.ClinicInvoiceStatusVoid {
+		return nil, errors.NewBadRequest("void invoices cannot be charged")
+	}
+	readAt := invoice.UpdatedAt
+
+	chargeable := round(invoice.Total - invoice.ChargedToFund)
+	amount := chargeable
+	if req.Amount != nil {
+		amount = round(*req.Amount)
+	}
+	if amount <= 0 {
+		return nil, errors.NewBadRequest("the invoice is already fully charged to medical funds")
+	}
+	if amount > chargeable {
+		return nil, errors.NewBadRequest(fmt.Sprintf("only %.2f of the invoice is not charged yet", chargeable))
+	}
+
+	var donations []*entities.Donation
+	if req.DonationID != "" {
+		donationID, err := primitive.ObjectIDFromHex(req.DonationID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid donation ID")
+		}
+		donation, err := uc.donationRepo.FindByID(ctx, donationID)
+		if err != nil {
+			return nil, err
+		}
+		if !donation.IsMedicalFund() {
+			return nil, errors.NewBadRequest("the donation is not a completed restricted medical donation")
+		}
+		donations = []*entities.Donation{donation}
+	} else if donations, err = uc.medicalDonations(ctx); err != nil {
+		return nil, err
+	}
+
+	fund, err := uc.fundBalances(ctx, donations)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	remaining := amount
+	var charges []entities.MedicalFundCharge
+	for _, donation := range fund {
+		if remaining <= 0 {
+			break
+		}
+		take := donation.Remaining
+		if take > remaining {
+			take = remaining
+		}
+		if take <= 0 {
+			continue
+		}
+
+		// The balances were read before; the reservation fails if another invoice took the funds meanwhile
+		reserved, err := uc.invoiceRepo.ReserveFund(ctx, donation.DonationID, round(take), donation.Amount)
+		if err != nil {
+			uc.releaseFunds(ctx, charges)
+			return nil, err
+		}
+		if !reserved {
+			continue
+		}
+		charges = append(charges, entities.MedicalFundCharge{
+			ID:         primitive.NewObjectID(),
+			DonationID: donation.DonationID,
+			Amount:     round(take),
+			ChargedAt:  now,
+			ChargedBy:  userID,
+		})
+		remaining = round(remaining - take)
+	}
+	if remaining > 0 {
+		uc.releaseFunds(ctx, charges)
+		return nil, errors.NewBadRequest(fmt.Sprintf("medical donations cover only %.2f of %.2f", round(amount-remaining), amount))
+	}
+
+	invoice.FundCharges = append(invoice.FundCharges, charges...)
+	invoice.Recalculate()
+	invoice.UpdatedBy = userID
+	if err := uc.saveIfUnchanged(ctx, invoice, readAt); err != nil {
+		uc.releaseFunds(ctx, charges)
+		return nil, err
+	}
+
+	donationIDs := make([]string, len(charges))
+	for i, charge := range charges {
+		donationIDs[i] = charge.DonationID.Hex()
+	}
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "charged to medical fund").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"amount": amount, "donation_ids": donationIDs}))
+
+	return invoice, nil
+}
+
+// ReleaseFundCharge removes a medical fund charge, returning the amount to the donation
+func (uc *BillingUseCase) ReleaseFundCharge(ctx context.Context, id, chargeID, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	readAt := invoice.UpdatedAt
+
+	var released *entities.MedicalFundCharge
+	charges := invoice.FundCharges[:0]
+	for i := range invoice.FundCharges {
+		if invoice.FundCharges[i].ID == chargeID {
+			charge := invoice.FundCharges[i]
+			released = &charge
+			continue
+		}
+		charges = append(charges, invoice.FundCharges[i])
+	}
+	if released == nil {
+		return nil, errors.NewNotFound("medical fund charge not found")
+	}
+	invoice.FundCharges = charges
+
+	invoice.Recalculate()
+	invoice.UpdatedBy = userID
+	if err := uc.saveIfUnchanged(ctx, invoice, readAt); err != nil {
+		return nil, err
+	}
+	uc.releaseFunds(ctx, []entities.MedicalFundCharge{*released})
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "medical fund charge released").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"amount": released.Amount, "donation_id": released.DonationID.Hex()}))
+
+	return invoice, nil
+}
+
+// GetMedicalFund lists the restricted medical donations with the amounts spent and left
+func (uc *BillingUseCase) GetMedicalFund(ctx context.Context) (*MedicalFund, error) {
+	donations, err := uc.medicalDonations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := uc.fundBalances(ctx, donations)
+	if err != nil {
+		return nil, err
+	}
+
+	fund := &MedicalFund{Donations: balances}
+	for _, donation := range balances {
+		fund.TotalAmount += donation.Amount
+		fund.TotalCharged += donation.Charged
+		fund.TotalRemaining += donation.Remaining
+	}
+	fund.TotalAmount = round(fund.TotalAmount)
+	fund.TotalCharged = round(fund.TotalCharged)
+	fund.TotalRemaining = round(fund.TotalRemaining)
+	return fund, nil
+}
+
+// GetAnimalExpenditure returns the invoiced and recorded medical costs of an animal
+func (uc *BillingUseCase) GetAnimalExpenditure(ctx context.Context, animalID primitive.ObjectID) (*AnimalExpenditure, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, _, err := uc.invoiceRepo.List(ctx, &repositories.ClinicInvoiceFilter{
+		AnimalID:  &animalID,
+		Statuses:  billableStatuses(),
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	visits, err := uc.visitRepo.GetByAnimalID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenditure := &AnimalExpenditure{AnimalID: animal.ID, AnimalName: animalName(animal), Items: []ExpenditureItem{}}
+	invoiced := make(map[primitive.ObjectID]bool)
+	for _, invoice := range invoices {
+		share := fundShare(invoice)
+		for _, line := range invoice.Lines {
+			if line.VisitID != nil {
+				invoiced[*line.VisitID] = true
+			}
+			if line.AnimalID == nil || *line.AnimalID != animalID {
+				continue
+			}
+			invoiceID := invoice.ID
+			expenditure.Items = append(expenditure.Items, ExpenditureItem{
+				Date:          lineDate(invoice, line),
+				Description:   line.Description,
+				ClinicName:    invoice.ClinicName,
+				Source:        "invoice",
+				InvoiceID:     &invoiceID,
+				InvoiceNumber: invoice.InvoiceNumber,
+				VisitID:       line.VisitID,
+				Amount:        line.Amount,
+				ChargedToFund: round(line.Amount * share),
+			})
+		}
+	}
+	for _, visit := range visits {
+		if invoiced[visit.ID] || !costCounts(visit) {
+			continue
+		}
+		visitID := visit.ID
+		expenditure.Items = append(expenditure.Items, ExpenditureItem{
+			Date:        visit.VisitDate,
+			Description: fmt.Sprintf("%s visit", visit.VisitType),
+			ClinicName:  visit.ClinicName,
+			Source:      "visit",
+			VisitID:     &visitID,
+			Amount:      visit.Cost,
+		})
+	}
+
+	sort.SliceStable(expenditure.Items, func(i, j int) bool {
+		return expenditure.Items[i].Date.Before(expenditure.Items[j].Date)
+	})
+	for _, item := range expenditure.Items {
+		expenditure.add(item)
+	}
+	expenditure.round()
+
+	return expenditure, nil
+}
+
+// GetExpenditureReport returns the medical spending between two dates by month and by animal.
+// Invoiced services count in the month they were provided.
+func (uc *BillingUseCase) GetExpenditureReport(ctx context.Context, from, to time.Time) (*ExpenditureReport, error) {
+	if to.Before(from) {
+		return nil, errors.NewBadRequest("the end date is before the start date")
+	}
+
+	invoiceTo := to.AddDate(0, 0, invoiceLagDays)
+	invoices, _, err := uc.invoiceRepo.List(ctx, &repositories.ClinicInvoiceFilter{
+		Statuses:  billableStatuses(),
+		From:      &from,
+		To:        &invoiceTo,
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	visits, _, err := uc.visitRepo.List(ctx, repositories.VeterinaryVisitFilter{FromDate: &from, ToDate: &to})
+	if err != nil {
+		return nil, err
+	}
+
+	months := make(map[string]*MonthlyExpenditure)
+	animalsByMonth := make(map[string]map[primitive.ObjectID]bool)
+	animals := make(map[primitive.ObjectID]*AnimalExpenditure)
+	month := func(date time.Time) *MonthlyExpenditure {
+		key := date.Format("2006-01")
+		if months[key] == nil {
+			months[key] = &MonthlyExpenditure{Month: key}
+			animalsByMonth[key] = make(map[primitive.ObjectID]bool)
+		}
+		return months[key]
+	}
+	countAnimal := func(date time.Time, animalID *primitive.ObjectID, item ExpenditureItem) {
+		if animalID == nil {
+			return
+		}
+		animalsByMonth[date.Format("2006-01")][*animalID] = true
+		if animals[*animalID] == nil {
+			animals[*animalID] = &AnimalExpenditure{AnimalID: *animalID}
+		}
+		animals[*animalID].add(item)
+	}
+
+	report := &ExpenditureReport{From: from, To: to, Months: []MonthlyExpenditure{}, Animals: []AnimalExpenditure{}}
+	invoiced := make(map[primitive.ObjectID]bool)
+	for _, invoice := range invoices {
+		share := fundShare(invoice)
+		for _, line := range invoice.Lines {
+			if line.VisitID != nil {
+				invoiced[*line.VisitID] = true
+			}
+			date := lineDate(invoice, line)
+			if date.Before(from) || date.After(to) {
+				continue
+			}
+			item := ExpenditureItem{Source: "invoice", Amount: line.Amount, ChargedToFund: line.Amount * share}
+			bucket := month(date)
+			bucket.Invoiced += item.Amount
+			bucket.Discounts += line.GrossAmount() - line.Amount
+			bucket.ChargedToFund += item.ChargedToFund
+			countAnimal(date, line.AnimalID, item)
+		}
+	}
+	for _, visit := range visits {
+		if invoiced[visit.ID] || !costCounts(visit) {
+			continue
+		}
+		animalID := visit.AnimalID
+		item := ExpenditureItem{Source: "visit", Amount: visit.Cost}
+		month(visit.VisitDate).Uninvoiced += item.Amount
+		countAnimal(visit.VisitDate, &animalID, item)
+	}
+
+	for key, bucket := range months {
+		bucket.Total = bucket.Invoiced + bucket.Uninvoiced
+		bucket.Animals = len(animalsByMonth[key])
+		report.Invoiced += bucket.Invoiced
+		report.Discounts += bucket.Discounts
+		report.Uninvoiced += bucket.Uninvoiced
+		report.ChargedToFund += bucket.ChargedToFund
+		bucket.Invoiced = round(bucket.Invoiced)
+		bucket.Discounts = round(bucket.Discounts)
+		bucket.Uninvoiced = round(bucket.Uninvoiced)
+		bucket.Total = round(bucket.Total)
+		bucket.ChargedToFund = round(bucket.ChargedToFund)
+		report.Months = append(report.Months, *bucket)
+	}
+	sort.Slice(report.Months, func(i, j int) bool { return report.Months[i].Month < report.Months[j].Month })
+
+	for animalID, expenditure := range animals {
+		expenditure.AnimalName = "animal"
+		if animal, err := uc.animalRepo.FindByID(ctx, animalID); err == nil {
+			expenditure.AnimalName = animalName(animal)
+		}
+		expenditure.round()
+		report.Animals = append(report.Animals, *expenditure)
+	}
+	sort.Slice(report.Animals, func(i, j int) bool {
+		if report.Animals[i].Total != report.Animals[j].Total {
+			return report.Animals[i].Total > report.Animals[j].Total
+		}
+		return report.Animals[i].AnimalName < report.Animals[j].AnimalName
+	})
+
+	report.Total = round(report.Invoiced + report.Uninvoiced)
+	report.Invoiced = round(report.Invoiced)
+	report.Discounts = round(report.Discounts)
+	report.Uninvoiced = round(report.Uninvoiced)
+	report.ChargedToFund = round(report.ChargedToFund)
+
+	return report, nil
+}
+
+// saveIfUnchanged saves an invoice whose fund charges changed, unless it was changed by
+// someone else since it was read at readAt
+func (uc *BillingUseCase) saveIfUnchanged(ctx context.Context, invoice *entities.ClinicInvoice, readAt time.Time) error {
+	updated, err := uc.invoiceRepo.UpdateIfUnchanged(ctx, invoice, readAt)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errors.NewConflict("The invoice was changed by someone else, please try again")
+	}
+	return nil
+}
+
+// releaseFunds gives the amounts of fund charges back to their donations. A failure
+// leaves a donation showing less than it has left, so it is logged to be corrected.
+func (uc *BillingUseCase) releaseFunds(ctx context.Context, charges []entities.MedicalFundCharge) {
+	for _, charge := range charges {
+		if err := uc.invoiceRepo.ReleaseFund(ctx, charge.DonationID, charge.Amount); err != nil {
+			log.Error().Err(err).Str("donation_id", charge.DonationID.Hex()).Msg("failed to release medical fund charge")
+		}
+	}
+}
+
+// medicalDonations returns the restricted medical donations, oldest first
+func (uc *BillingUseCase) medicalDonations(ctx context.Context) ([]*entities.Donation, error) {
+	donations, _, err := uc.donationRepo.List(ctx, &repositories.DonationFilter{
+		Designation: entities.MedicalDesignation,
+		Status:      string(entities.DonationStatusCompleted),
+		SortBy:      "donation_date",
+		SortOrder:   "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var medical []*entities.Donation
+	for _, donation := range donations {
+		if donation.IsMedicalFund() {
+			medical = append(medical, donation)
+		}
+	}
+	return medical, nil
+}
+
+// fundBalances returns how much of each donation is charged and left
+func (uc *BillingUseCase) fundBalances(ctx context.Context, donations []*entities.Donation) ([]MedicalFundDonation, error) {
+	ids := make([]primitive.ObjectID, len(donations))
+	for i, donation := range donations {
+		ids[i] = donation.ID
+	}
+	charged, err := uc.invoiceRepo.ChargedByDonation(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]MedicalFundDonation, 0, len(donations))
+	for _, donation := range donations {
+		name := donation.DonorName
+		if donation.Anonymous {
+			name = "Anonymous"
+		}
+		amount := donation.FundAmount()
+		balances = append(balances, MedicalFundDonation{
+			DonationID:   donation.ID,
+			DonorName:    name,
+			CampaignName: donation.CampaignName,
+			DonationDate: donation.DonationDate,
+			Amount:       round(amount),
+			Charged:      round(charged[donation.ID]),
+			Remaining:    round(amount - charged[donation.ID]),
+		})
+	}
+	return balances, nil
+}
+
+func (e *AnimalExpenditure) add(item ExpenditureItem) {
+	if item.Source == "invoice" {
+		e.Invoiced += item.Amount
+	} else {
+		e.Uninvoiced += item.Amount
+	}
+	e.ChargedToFund += item.ChargedToFund
+}
+
+func (e *AnimalExpenditure) round() {
+	e.Total = round(e.Invoiced + e.Uninvoiced)
+	e.Invoiced = round(e.Invoiced)
+	e.Uninvoiced = round(e.Uninvoiced)
+	e.ChargedToFund = round(e.ChargedToFund)
+}
+
+// fundShare is the part of the invoice paid from medical donations, spread evenly over its lines
+func fundShare(invoice *entities.ClinicInvoice) float64 {
+	if invoice.Total <= 0 {
+		return 0
+	}
+	return invoice.ChargedToFund / invoice.Total
+}
+
+func lineDate(invoice *entities.ClinicInvoice, line entities.ClinicInvoiceLine) time.Time {
+	if line.ServiceDate != nil {
+		return *line.ServiceDate
+	}
+	return invoice.InvoiceDate
+}
+
+// costCounts reports whether a visit's recorded cost is medical spending
+func costCounts(visit *entities.VeterinaryVisit) bool {
+	return visit.Cost > 0 && visit.Status != entities.VisitStatusCancelled && visit.Status != entities.VisitStatusNoShow
+}
+
+func billableStatuses() []string {
+	return []string{
+		string(entities.ClinicInvoiceStatusOpen),
+		string(entities.ClinicInvoiceStatusPartiallyPaid),
+		string(entities.ClinicInvoiceStatusPaid),
+	}
+}
+
+func animalName(animal *entities.Animal) string {
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	if animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	return "animal"
+}
@@ -0,0 +1,728 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BillingUseCase reconciles partner clinic invoices with veterinary visits and medical funds
+type BillingUseCase struct {
+	invoiceRepo  repositories.ClinicInvoiceRepository
+	partnerRepo  repositories.PartnerRepository
+	visitRepo    repositories.VeterinaryVisitRepository
+	donationRepo repositories.DonationRepository
+	animalRepo   repositories.AnimalRepository
+	auditLogRepo repositories.AuditLogRepository
+}
+
+// NewBillingUseCase creates a new billing use case
+func NewBillingUseCase(
+	invoiceRepo repositories.ClinicInvoiceRepository,
+	partnerRepo repositories.PartnerRepository,
+	visitRepo repositories.VeterinaryVisitRepository,
+	donationRepo repositories.DonationRepository,
+	animalRepo repositories.AnimalRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) *BillingUseCase {
+	return &BillingUseCase{
+		invoiceRepo:  invoiceRepo,
+		partnerRepo:  partnerRepo,
+		visitRepo:    visitRepo,
+		donationRepo: donationRepo,
+		animalRepo:   animalRepo,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// InvoiceLineRequest represents a billed service on an invoice
+type InvoiceLineRequest struct {
+	Description string     `json:"description" validate:"required"`
+	AnimalID    string     `json:"animal_id,omitempty"`
+	VisitID     string     `json:"visit_id,omitempty"`
+	ServiceDate *time.Time `json:"service_date,omitempty"`
+	Quantity    float64    `json:"quantity,omitempty" validate:"omitempty,gt=0"`
+
+	// UnitPrice defaults to the partner's standard rate
+	UnitPrice *float64 `json:"unit_price,omitempty" validate:"omitempty,min=0"`
+
+	// DiscountPercentage overrides the partner discount for the line
+	DiscountPercentage *float64 `json:"discount_percentage,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
+// CreateInvoiceRequest represents a request to record a clinic invoice
+type CreateInvoiceRequest struct {
+	PartnerID     string               `json:"partner_id" validate:"required"`
+	InvoiceNumber string               `json:"invoice_number" validate:"required"`
+	InvoiceDate   time.Time            `json:"invoice_date" validate:"required"`
+	DueDate       *time.Time           `json:"due_date,omitempty"`
+	Currency      string               `json:"currency,omitempty"`
+	Lines         []InvoiceLineRequest `json:"lines" validate:"required,min=1,dive"`
+	DocumentID    string               `json:"document_id,omitempty"`
+	Notes         string               `json:"notes,omitempty"`
+
+	// ApplyPartnerDiscount applies the partner's DiscountPercentage to lines without
+	// their own; defaults to true. Turn it off when the clinic already discounted the prices.
+	ApplyPartnerDiscount *bool `json:"apply_partner_discount,omitempty"`
+}
+
+// UpdateInvoiceRequest represents a request to correct a clinic invoice
+type UpdateInvoiceRequest struct {
+	InvoiceNumber        *string               `json:"invoice_number,omitempty"`
+	InvoiceDate          *time.Time            `json:"invoice_date,omitempty"`
+	DueDate              *time.Time            `json:"due_date,omitempty"`
+	Lines                *[]InvoiceLineRequest `json:"lines,omitempty" validate:"omitempty,min=1,dive"`
+	ApplyPartnerDiscount *bool                 `json:"apply_partner_discount,omitempty"`
+	DocumentID           *string               `json:"document_id,omitempty"`
+	Notes                *string               `json:"notes,omitempty"`
+}
+
+// RecordPaymentRequest represents a payment made to a clinic
+type RecordPaymentRequest struct {
+	Amount    float64                    `json:"amount" validate:"required,gt=0"`
+	PaidAt    *time.Time                 `json:"paid_at,omitempty"`
+	Method    entities.PaymentMethodType `json:"method,omitempty"`
+	Reference string                     `json:"reference,omitempty"`
+	Notes     string                     `json:"notes,omitempty"`
+}
+
+// ClinicStatement is the outstanding balance of a clinic with its unpaid invoices
+type ClinicStatement struct {
+	Balance        *repositories.ClinicBalance `json:"balance"`
+	UnpaidInvoices []*entities.ClinicInvoice   `json:"unpaid_invoices"`
+}
+
+// CreateInvoice records an invoice from a partner clinic, applying the partner
+// discount and matching the lines to visits
+func (uc *BillingUseCase) CreateInvoice(ctx context.Context, req *CreateInvoiceRequest, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	partnerID, err := primitive.ObjectIDFromHex(req.PartnerID)
+	if err != nil {
+		return nil, errors.NewBadRequest("invalid partner ID")
+	}
+	partner, err := uc.findClinic(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &entities.ClinicInvoice{
+		PartnerID:     partner.ID,
+		ClinicName:    partner.Name,
+		InvoiceNumber: strings.TrimSpace(req.InvoiceNumber),
+		InvoiceDate:   req.InvoiceDate,
+		DueDate:       req.DueDate,
+		Currency:      req.Currency,
+		Notes:         req.Notes,
+		CreatedBy:     userID,
+		UpdatedBy:     userID,
+	}
+	if invoice.Currency == "" {
+		invoice.Currency = "USD"
+	}
+	if req.DocumentID != "" {
+		documentID, err := primitive.ObjectIDFromHex(req.DocumentID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid document ID")
+		}
+		invoice.DocumentID = &documentID
+	}
+
+	visits := make(map[primitive.ObjectID]*entities.VeterinaryVisit)
+	lines, err := uc.buildLines(ctx, partner, req.Lines, applyDiscount(req.ApplyPartnerDiscount), visits)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Lines = lines
+	invoice.Recalculate()
+	uc.matchLines(invoice, visits, nil)
+
+	if err := uc.invoiceRepo.Create(ctx, invoice); err != nil {
+		return nil, err
+	}
+	uc.syncVisits(ctx, invoice, visits, userID)
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionCreate, "clinic_invoice", invoice.InvoiceNumber, "").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"partner_id": partner.ID.Hex(), "total": invoice.Total}))
+
+	return invoice, nil
+}
+
+// GetInvoice returns a clinic invoice
+func (uc *BillingUseCase) GetInvoice(ctx context.Context, id primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	return uc.invoiceRepo.FindByID(ctx, id)
+}
+
+// ListInvoices lists clinic invoices
+func (uc *BillingUseCase) ListInvoices(ctx context.Context, filter *repositories.ClinicInvoiceFilter) ([]*entities.ClinicInvoice, int64, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	return uc.invoiceRepo.List(ctx, filter)
+}
+
+// UpdateInvoice corrects an invoice; replacing the lines re-applies discounts and matching
+func (uc *BillingUseCase) UpdateInvoice(ctx context.Context, id primitive.ObjectID, req *UpdateInvoiceRequest, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == entities.ClinicInvoiceStatusVoid {
+		return nil, errors.NewBadRequest("void invoices cannot be changed")
+	}
+
+	if req.InvoiceNumber != nil {
+		invoice.InvoiceNumber = strings.TrimSpace(*req.InvoiceNumber)
+	}
+	if req.InvoiceDate != nil {
+		invoice.InvoiceDate = *req.InvoiceDate
+	}
+	if req.DueDate != nil {
+		invoice.DueDate = req.DueDate
+	}
+	if req.Notes != nil {
+		invoice.Notes = *req.Notes
+	}
+	if req.DocumentID != nil {
+		invoice.DocumentID = nil
+		if *req.DocumentID != "" {
+			documentID, err := primitive.ObjectIDFromHex(*req.DocumentID)
+			if err != nil {
+				return nil, errors.NewBadRequest("invalid document ID")
+			}
+			invoice.DocumentID = &documentID
+		}
+	}
+
+	visits := make(map[primitive.ObjectID]*entities.VeterinaryVisit)
+	previous := invoice.Lines
+	if req.Lines != nil {
+		partner, err := uc.findClinic(ctx, invoice.PartnerID)
+		if err != nil {
+			return nil, err
+		}
+		lines, err := uc.buildLines(ctx, partner, *req.Lines, applyDiscount(req.ApplyPartnerDiscount), visits)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Lines = lines
+	} else if err := uc.loadVisits(ctx, invoice, visits); err != nil {
+		return nil, err
+	}
+
+	invoice.Recalculate()
+	if invoice.AmountPaid > invoice.Total {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the invoice total %.2f is below the %.2f already paid", invoice.Total, invoice.AmountPaid))
+	}
+	if invoice.ChargedToFund > invoice.Total {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the invoice total %.2f is below the %.2f charged to medical funds", invoice.Total, invoice.ChargedToFund))
+	}
+	uc.matchLines(invoice, visits, previous)
+	invoice.UpdatedBy = userID
+
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, err
+	}
+	uc.syncVisits(ctx, invoice, visits, userID)
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"total": invoice.Total}))
+
+	return invoice, nil
+}
+
+// DeleteInvoice deletes an invoice entered by mistake; paid or charged invoices must be voided instead
+func (uc *BillingUseCase) DeleteInvoice(ctx context.Context, id, userID primitive.ObjectID) error {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(invoice.Payments) > 0 || len(invoice.FundCharges) > 0 {
+		return errors.NewBadRequest("invoices with payments or medical fund charges cannot be deleted")
+	}
+
+	if err := uc.invoiceRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionDelete, "clinic_invoice", invoice.InvoiceNumber, "").
+		WithEntityID(invoice.ID))
+
+	return nil
+}
+
+// VoidInvoice cancels an invoice the clinic withdrew; it no longer counts towards balances
+func (uc *BillingUseCase) VoidInvoice(ctx context.Context, id primitive.ObjectID, reason string, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == entities.ClinicInvoiceStatusVoid {
+		return invoice, nil
+	}
+	if len(invoice.Payments) > 0 {
+		return nil, errors.NewBadRequest("remove the payments before voiding the invoice")
+	}
+
+	// The medical fund charges go back to their donations
+	charges := invoice.FundCharges
+	readAt := invoice.UpdatedAt
+	invoice.FundCharges = nil
+	invoice.Status = entities.ClinicInvoiceStatusVoid
+	invoice.Recalculate()
+	if reason != "" {
+		invoice.Notes = strings.TrimSpace(invoice.Notes + "\nVoided: " + reason)
+	}
+	invoice.UpdatedBy = userID
+	if err := uc.saveIfUnchanged(ctx, invoice, readAt); err != nil {
+		return nil, err
+	}
+	uc.releaseFunds(ctx, charges)
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "invoice voided").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"reason": reason}))
+
+	return invoice, nil
+}
+
+// RecordPayment records a full or partial payment of an invoice
+func (uc *BillingUseCase) RecordPayment(ctx context.Context, id primitive.ObjectID, req *RecordPaymentRequest, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == entities.ClinicInvoiceStatusVoid {
+		return nil, errors.NewBadRequest("void invoices cannot be paid")
+	}
+	if req.Amount > invoice.Balance+0.005 {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the payment exceeds the outstanding balance of %.2f", invoice.Balance))
+	}
+
+	payment := entities.ClinicInvoicePayment{
+		ID:         primitive.NewObjectID(),
+		Amount:     math.Round(req.Amount*100) / 100,
+		PaidAt:     time.Now(),
+		Method:     req.Method,
+		Reference:  req.Reference,
+		Notes:      req.Notes,
+		RecordedBy: userID,
+	}
+	if req.PaidAt != nil {
+		payment.PaidAt = *req.PaidAt
+	}
+	if payment.Method == "" {
+		payment.Method = entities.PaymentMethodBankTransfer
+	}
+	invoice.Payments = append(invoice.Payments, payment)
+
+	if err := uc.saveWithVisits(ctx, invoice, userID); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "payment recorded").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"amount": payment.Amount, "balance": invoice.Balance}))
+
+	return invoice, nil
+}
+
+// DeletePayment removes a payment recorded by mistake
+func (uc *BillingUseCase) DeletePayment(ctx context.Context, id, paymentID, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed *entities.ClinicInvoicePayment
+	payments := invoice.Payments[:0]
+	for i := range invoice.Payments {
+		if invoice.Payments[i].ID == paymentID {
+			payment := invoice.Payments[i]
+			removed = &payment
+			continue
+		}
+		payments = append(payments, invoice.Payments[i])
+	}
+	if removed == nil {
+		return nil, errors.NewNotFound("payment not found")
+	}
+	invoice.Payments = payments
+
+	if err := uc.saveWithVisits(ctx, invoice, userID); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "payment removed").
+		WithEntityID(invoice.ID).
+		WithChanges(map[string]interface{}{"amount": removed.Amount, "balance": invoice.Balance}))
+
+	return invoice, nil
+}
+
+// MatchLine links an invoice line to a visit, or unlinks it when visitID is nil
+func (uc *BillingUseCase) MatchLine(ctx context.Context, id, lineID primitive.ObjectID, visitID *primitive.ObjectID, userID primitive.ObjectID) (*entities.ClinicInvoice, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	line := invoice.Line(lineID)
+	if line == nil {
+		return nil, errors.NewNotFound("invoice line not found")
+	}
+
+	previous := append([]entities.ClinicInvoiceLine(nil), invoice.Lines...)
+	visits := make(map[primitive.ObjectID]*entities.VeterinaryVisit)
+	if err := uc.loadVisits(ctx, invoice, visits); err != nil {
+		return nil, err
+	}
+
+	line.VisitID = nil
+	line.MatchStatus = entities.InvoiceMatchUnmatched
+	line.ExpectedCost = 0
+	if visitID != nil {
+		visit, err := uc.visitRepo.FindByID(ctx, *visitID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVisitClinic(visit, invoice.PartnerID, invoice.ClinicName); err != nil {
+			return nil, err
+		}
+		if line.AnimalID != nil && *line.AnimalID != visit.AnimalID {
+			return nil, errors.NewBadRequest("the visit is for another animal than the invoice line")
+		}
+		visits[visit.ID] = visit
+		line.VisitID = &visit.ID
+		line.AnimalID = &visit.AnimalID
+		if line.ServiceDate == nil {
+			date := visit.VisitDate
+			line.ServiceDate = &date
+		}
+	}
+
+	uc.matchLines(invoice, visits, previous)
+	invoice.UpdatedBy = userID
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, err
+	}
+	uc.syncVisits(ctx, invoice, visits, userID)
+
+	changes := map[string]interface{}{"line_id": lineID.Hex(), "visit_id": nil}
+	if visitID != nil {
+		changes["visit_id"] = visitID.Hex()
+	}
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "clinic_invoice", invoice.InvoiceNumber, "invoice line matched").
+		WithEntityID(invoice.ID).
+		WithChanges(changes))
+
+	return invoice, nil
+}
+
+// GetClinicBalances returns the outstanding balance of every clinic
+func (uc *BillingUseCase) GetClinicBalances(ctx context.Context) ([]*repositories.ClinicBalance, error) {
+	balances, err := uc.invoiceRepo.OutstandingByPartner(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if balances == nil {
+		balances = []*repositories.ClinicBalance{}
+	}
+	return balances, nil
+}
+
+// GetClinicStatement returns the outstanding balance of a clinic with its unpaid invoices
+func (uc *BillingUseCase) GetClinicStatement(ctx context.Context, partnerID primitive.ObjectID) (*ClinicStatement, error) {
+	partner, err := uc.findClinic(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, _, err := uc.invoiceRepo.List(ctx, &repositories.ClinicInvoiceFilter{
+		PartnerID: &partner.ID,
+		Statuses:  []string{string(entities.ClinicInvoiceStatusOpen), string(entities.ClinicInvoiceStatusPartiallyPaid)},
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	balance := &repositories.ClinicBalance{PartnerID: partner.ID, ClinicName: partner.Name}
+	for _, invoice := range invoices {
+		balance.InvoiceCount++
+		balance.UnpaidCount++
+		balance.Total += invoice.Total
+		balance.Paid += invoice.AmountPaid
+		balance.Balance += invoice.Balance
+		if invoice.IsOverdue(now) {
+			balance.OverdueBalance += invoice.Balance
+		}
+		if invoice.DueDate != nil && (balance.OldestDueDate == nil || invoice.DueDate.Before(*balance.OldestDueDate)) {
+			balance.OldestDueDate = invoice.DueDate
+		}
+	}
+	balance.Total = round(balance.Total)
+	balance.Paid = round(balance.Paid)
+	balance.Balance = round(balance.Balance)
+	balance.OverdueBalance = round(balance.OverdueBalance)
+	if invoices == nil {
+		invoices = []*entities.ClinicInvoice{}
+	}
+
+	return &ClinicStatement{Balance: balance, UnpaidInvoices: invoices}, nil
+}
+
+// buildLines validates the requested lines, resolving visits, prices and discounts
+func (uc *BillingUseCase) buildLines(ctx context.Context, partner *entities.Partner, requests []InvoiceLineRequest, withDiscount bool, visits map[primitive.ObjectID]*entities.VeterinaryVisit) ([]entities.ClinicInvoiceLine, error) {
+	lines := make([]entities.ClinicInvoiceLine, 0, len(requests))
+	for i, req := range requests {
+		line := entities.ClinicInvoiceLine{
+			ID:          primitive.NewObjectID(),
+			Description: strings.TrimSpace(req.Description),
+			ServiceDate: req.ServiceDate,
+			Quantity:    req.Quantity,
+			MatchStatus: entities.InvoiceMatchUnmatched,
+		}
+		if line.Quantity == 0 {
+			line.Quantity = 1
+		}
+
+		if req.AnimalID != "" {
+			animalID, err := primitive.ObjectIDFromHex(req.AnimalID)
+			if err != nil {
+				return nil, errors.NewBadRequest(fmt.Sprintf("line %d: invalid animal ID", i+1))
+			}
+			line.AnimalID = &animalID
+		}
+
+		if req.VisitID != "" {
+			visitID, err := primitive.ObjectIDFromHex(req.VisitID)
+			if err != nil {
+				return nil, errors.NewBadRequest(fmt.Sprintf("line %d: invalid visit ID", i+1))
+			}
+			visit, ok := visits[visitID]
+			if !ok {
+				if visit, err = uc.visitRepo.FindByID(ctx, visitID); err != nil {
+					return nil, err
+				}
+			}
+			if err := checkVisitClinic(visit, partner.ID, partner.Name); err != nil {
+				return nil, err
+			}
+			if line.AnimalID != nil && *line.AnimalID != visit.AnimalID {
+				return nil, errors.NewBadRequest(fmt.Sprintf("line %d: the visit is for another animal", i+1))
+			}
+			visits[visit.ID] = visit
+			line.VisitID = &visit.ID
+			line.AnimalID = &visit.AnimalID
+			if line.ServiceDate == nil {
+				date := visit.VisitDate
+				line.ServiceDate = &date
+			}
+		} else if line.AnimalID != nil && line.ServiceDate != nil {
+			visit, err := uc.findVisit(ctx, partner, *line.AnimalID, *line.ServiceDate)
+			if err != nil {
+				return nil, err
+			}
+			if visit != nil {
+				visits[visit.ID] = visit
+				line.VisitID = &visit.ID
+			}
+		}
+
+		switch {
+		case req.UnitPrice != nil:
+			line.UnitPrice = *req.UnitPrice
+		case partner.StandardRate > 0:
+			line.UnitPrice = partner.StandardRate
+		default:
+			return nil, errors.NewBadRequest(fmt.Sprintf("line %d: unit price is required, the partner has no standard rate", i+1))
+		}
+
+		switch {
+		case req.DiscountPercentage != nil:
+			line.DiscountPercentage = *req.DiscountPercentage
+		case withDiscount:
+			line.DiscountPercentage = partner.DiscountPercentage
+		}
+
+		line.Calculate()
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// findVisit finds the animal's visit at the clinic on the service day
+func (uc *BillingUseCase) findVisit(ctx context.Context, partner *entities.Partner, animalID primitive.ObjectID, serviceDate time.Time) (*entities.VeterinaryVisit, error) {
+	visits, err := uc.visitRepo.GetByAnimalID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+	day := serviceDate.Format("2006-01-02")
+	for _, visit := range visits {
+		if visit.Status == entities.VisitStatusCancelled || visit.Status == entities.VisitStatusNoShow {
+			continue
+		}
+		if checkVisitClinic(visit, partner.ID, partner.Name) != nil || visit.PartnerID == nil && visit.ClinicName == "" {
+			continue
+		}
+		if visit.VisitDate.In(serviceDate.Location()).Format("2006-01-02") == day {
+			return visit, nil
+		}
+	}
+	return nil, nil
+}
+
+// matchLines compares the invoiced amount of each visit with its recorded cost.
+// Visits whose cost was filled in from this invoice before are treated as having none.
+func (uc *BillingUseCase) matchLines(invoice *entities.ClinicInvoice, visits map[primitive.ObjectID]*entities.VeterinaryVisit, previous []entities.ClinicInvoiceLine) {
+	filled := make(map[primitive.ObjectID]bool)
+	for _, line := range previous {
+		if line.VisitID != nil && line.MatchStatus == entities.InvoiceMatchMatched && line.ExpectedCost == 0 {
+			filled[*line.VisitID] = true
+		}
+	}
+
+	totals := invoicedByVisit(invoice)
+	for i := range invoice.Lines {
+		line := &invoice.Lines[i]
+		if line.VisitID == nil {
+			line.MatchStatus = entities.InvoiceMatchUnmatched
+			line.ExpectedCost = 0
+			continue
+		}
+		visit, ok := visits[*line.VisitID]
+		if !ok {
+			continue // visit deleted since; keep the earlier result
+		}
+		expected := visit.Cost
+		if filled[visit.ID] {
+			expected = 0
+		}
+		line.ExpectedCost = expected
+		if expected == 0 || math.Abs(totals[visit.ID]-expected) < 0.01 {
+			line.MatchStatus = entities.InvoiceMatchMatched
+		} else {
+			line.MatchStatus = entities.InvoiceMatchMismatch
+		}
+	}
+}
+
+// syncVisits records the invoiced cost on visits without one and mirrors the payment status
+func (uc *BillingUseCase) syncVisits(ctx context.Context, invoice *entities.ClinicInvoice, visits map[primitive.ObjectID]*entities.VeterinaryVisit, userID primitive.ObjectID) {
+	totals := invoicedByVisit(invoice)
+	filled := make(map[primitive.ObjectID]bool)
+	for _, line := range invoice.Lines {
+		if line.VisitID != nil && line.MatchStatus == entities.InvoiceMatchMatched && line.ExpectedCost == 0 {
+			filled[*line.VisitID] = true
+		}
+	}
+
+	for visitID, total := range totals {
+		visit, ok := visits[visitID]
+		if !ok {
+			continue
+		}
+		changed := false
+		if filled[visitID] && visit.Cost != total {
+			visit.Cost = total
+			changed = true
+		}
+		if status := invoice.VisitPaymentStatus(); visit.PaymentStatus != status {
+			visit.PaymentStatus = status
+			changed = true
+		}
+		if changed {
+			visit.UpdatedBy = userID
+			_ = uc.visitRepo.Update(ctx, visit)
+		}
+	}
+}
+
+// saveWithVisits recalculates and saves the invoice, then mirrors the payment status on its visits
+func (uc *BillingUseCase) saveWithVisits(ctx context.Context, invoice *entities.ClinicInvoice, userID primitive.ObjectID) error {
+	invoice.Recalculate()
+	invoice.UpdatedBy = userID
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return err
+	}
+
+	visits := make(map[primitive.ObjectID]*entities.VeterinaryVisit)
+	if err := uc.loadVisits(ctx, invoice, visits); err == nil {
+		uc.syncVisits(ctx, invoice, visits, userID)
+	}
+	return nil
+}
+
+// loadVisits loads the visits the invoice lines are matched to
+func (uc *BillingUseCase) loadVisits(ctx context.Context, invoice *entities.ClinicInvoice, visits map[primitive.ObjectID]*entities.VeterinaryVisit) error {
+	for _, line := range invoice.Lines {
+		if line.VisitID == nil {
+			continue
+		}
+		if _, ok := visits[*line.VisitID]; ok {
+			continue
+		}
+		visit, err := uc.visitRepo.FindByID(ctx, *line.VisitID)
+		if err != nil {
+			if err == errors.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		visits[visit.ID] = visit
+	}
+	return nil
+}
+
+// findClinic returns a partner of type veterinary
+func (uc *BillingUseCase) findClinic(ctx context.Context, partnerID primitive.ObjectID) (*entities.Partner, error) {
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if partner.Type != entities.PartnerTypeVeterinary {
+		return nil, errors.NewBadRequest("the partner is not a veterinary clinic")
+	}
+	return partner, nil
+}
+
+// checkVisitClinic rejects visits at another clinic; visits recorded before clinics were
+// linked to partners are compared by clinic name
+func checkVisitClinic(visit *entities.VeterinaryVisit, partnerID primitive.ObjectID, clinicName string) error {
+	if visit.PartnerID != nil {
+		if *visit.PartnerID != partnerID {
+			return errors.NewBadRequest("the visit took place at another clinic")
+		}
+		return nil
+	}
+	if visit.ClinicName != "" && !strings.EqualFold(strings.TrimSpace(visit.ClinicName), strings.TrimSpace(clinicName)) {
+		return errors.NewBadRequest("the visit took place at another clinic")
+	}
+	return nil
+}
+
+func invoicedByVisit(invoice *entities.ClinicInvoice) map[primitive.ObjectID]float64 {
+	totals := make(map[primitive.ObjectID]float64)
+	for _, line := range invoice.Lines {
+		if line.VisitID != nil {
+			totals[*line.VisitID] = round(totals[*line.VisitID] + line.Amount)
+		}
+	}
+	return totals
+}
+
+func applyDiscount(value *bool) bool {
+	return value == nil || *value
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,344 @@
+package billing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type testRepos struct {
+	invoices  *mocks.ClinicInvoiceRepository
+	partners  *mocks.PartnerRepository
+	visits    *mocks.VeterinaryVisitRepository
+	donations *mocks.DonationRepository
+	animals   *mocks.AnimalRepository
+}
+
+func newTestUseCase() (*BillingUseCase, *testRepos) {
+	repos := &testRepos{
+		invoices:  new(mocks.ClinicInvoiceRepository),
+		partners:  new(mocks.PartnerRepository),
+		visits:    new(mocks.VeterinaryVisitRepository),
+		donations: new(mocks.DonationRepository),
+		animals:   new(mocks.AnimalRepository),
+	}
+	auditLogRepo := testutil.AuditLogs()
+
+	useCase := NewBillingUseCase(repos.invoices, repos.partners, repos.visits, repos.donations, repos.animals, auditLogRepo)
+	return useCase, repos
+}
+
+func assertStatusCode(t *testing.T, err error, code int) {
+	t.Helper()
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok, "expected an AppError, got %v", err)
+	assert.Equal(t, code, appErr.Code)
+}
+
+func TestCreateInvoice_AppliesDiscountAndMatchesVisit(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic := testutil.Clinic(repos.partners)
+	userID := primitive.NewObjectID()
+
+	serviceDate := time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)
+	visit := &entities.VeterinaryVisit{
+		ID:        primitive.NewObjectID(),
+		AnimalID:  primitive.NewObjectID(),
+		PartnerID: &clinic.ID,
+		VisitDate: serviceDate,
+		Status:    entities.VisitStatusCompleted,
+	}
+	repos.visits.On("GetByAnimalID", ctx, visit.AnimalID).Return([]*entities.VeterinaryVisit{visit}, nil)
+	repos.invoices.On("Create", ctx, mock.AnythingOfType("*entities.ClinicInvoice")).Return(nil)
+	repos.visits.On("Update", ctx, visit).Return(nil)
+
+	price := 50.0
+	invoice, err := useCase.CreateInvoice(ctx, &CreateInvoiceRequest{
+		PartnerID:     clinic.ID.Hex(),
+		InvoiceNumber: " FV/2025/03/01 ",
+		InvoiceDate:   serviceDate.AddDate(0, 0, 5),
+		Lines: []InvoiceLineRequest{
+			{Description: "Consultation", AnimalID: visit.AnimalID.Hex(), ServiceDate: &serviceDate},
+			{Description: "Antibiotic", AnimalID: visit.AnimalID.Hex(), ServiceDate: &serviceDate, UnitPrice: &price, Quantity: 2},
+		},
+	}, userID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "FV/2025/03/01", invoice.InvoiceNumber)
+	assert.Equal(t, "USD", invoice.Currency)
+	assert.Equal(t, 200.0, invoice.Subtotal)
+	assert.Equal(t, 20.0, invoice.DiscountTotal)
+	assert.Equal(t, 180.0, invoice.Total)
+	assert.Equal(t, entities.ClinicInvoiceStatusOpen, invoice.Status)
+	for _, line := range invoice.Lines {
+		require.NotNil(t, line.VisitID)
+		assert.Equal(t, visit.ID, *line.VisitID)
+		assert.Equal(t, entities.InvoiceMatchMatched, line.MatchStatus)
+	}
+
+	// The visit had no cost recorded, so it takes the invoiced amount
+	assert.Equal(t, 180.0, visit.Cost)
+	assert.Equal(t, "pending", visit.PaymentStatus)
+	repos.visits.AssertCalled(t, "Update", ctx, visit)
+}
+
+func TestCreateInvoice_FlagsCostMismatch(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic := testutil.Clinic(repos.partners)
+
+	visit := &entities.VeterinaryVisit{
+		ID:        primitive.NewObjectID(),
+		AnimalID:  primitive.NewObjectID(),
+		PartnerID: &clinic.ID,
+		VisitDate: time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC),
+		Status:    entities.VisitStatusCompleted,
+		Cost:      60,
+	}
+	repos.visits.On("FindByID", ctx, visit.ID).Return(visit, nil)
+	repos.invoices.On("Create", ctx, mock.AnythingOfType("*entities.ClinicInvoice")).Return(nil)
+	repos.visits.On("Update", ctx, visit).Return(nil)
+
+	noDiscount := false
+	invoice, err := useCase.CreateInvoice(ctx, &CreateInvoiceRequest{
+		PartnerID:            clinic.ID.Hex(),
+		InvoiceNumber:        "FV/2025/03/02",
+		InvoiceDate:          visit.VisitDate,
+		ApplyPartnerDiscount: &noDiscount,
+		Lines:                []InvoiceLineRequest{{Description: "Consultation", VisitID: visit.ID.Hex()}},
+	}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, invoice.Lines, 1)
+	assert.Equal(t, 100.0, invoice.Total)
+	assert.Equal(t, entities.InvoiceMatchMismatch, invoice.Lines[0].MatchStatus)
+	assert.Equal(t, 60.0, invoice.Lines[0].ExpectedCost)
+	assert.Equal(t, 60.0, visit.Cost, "recorded costs are never overwritten")
+}
+
+func TestCreateInvoice_RejectsVisitAtAnotherClinic(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	clinic := testutil.Clinic(repos.partners)
+
+	otherClinic := primitive.NewObjectID()
+	visit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: primitive.NewObjectID(), PartnerID: &otherClinic}
+	repos.visits.On("FindByID", ctx, visit.ID).Return(visit, nil)
+
+	_, err := useCase.CreateInvoice(ctx, &CreateInvoiceRequest{
+		PartnerID:     clinic.ID.Hex(),
+		InvoiceNumber: "FV/2025/03/03",
+		InvoiceDate:   time.Now(),
+		Lines:         []InvoiceLineRequest{{Description: "Consultation", VisitID: visit.ID.Hex()}},
+	}, primitive.NewObjectID())
+	require.Error(t, err)
+	assertStatusCode(t, err, http.StatusBadRequest)
+	repos.invoices.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRecordPayment_TracksStatusOnInvoiceAndVisit(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	userID := primitive.NewObjectID()
+
+	visit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: primitive.NewObjectID(), Cost: 150, PaymentStatus: "pending"}
+	invoice := &entities.ClinicInvoice{
+		ID:            primitive.NewObjectID(),
+		PartnerID:     primitive.NewObjectID(),
+		InvoiceNumber: "FV/1",
+		Lines: []entities.ClinicInvoiceLine{{
+			ID: primitive.NewObjectID(), Description: "Surgery", VisitID: &visit.ID, AnimalID: &visit.AnimalID,
+			Quantity: 1, UnitPrice: 150, MatchStatus: entities.InvoiceMatchMatched, ExpectedCost: 150,
+		}},
+	}
+	invoice.Lines[0].Calculate()
+	invoice.Recalculate()
+
+	repos.invoices.On("FindByID", ctx, invoice.ID).Return(invoice, nil)
+	repos.invoices.On("Update", ctx, invoice).Return(nil)
+	repos.visits.On("FindByID", ctx, visit.ID).Return(visit, nil)
+	repos.visits.On("Update", ctx, visit).Return(nil)
+
+	_, err := useCase.RecordPayment(ctx, invoice.ID, &RecordPaymentRequest{Amount: 200}, userID)
+	require.Error(t, err)
+	assertStatusCode(t, err, http.StatusBadRequest)
+
+	updated, err := useCase.RecordPayment(ctx, invoice.ID, &RecordPaymentRequest{Amount: 100}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ClinicInvoiceStatusPartiallyPaid, updated.Status)
+	assert.Equal(t, 50.0, updated.Balance)
+	assert.Equal(t, entities.PaymentMethodBankTransfer, updated.Payments[0].Method)
+	assert.Equal(t, "partial", visit.PaymentStatus)
+
+	updated, err = useCase.RecordPayment(ctx, invoice.ID, &RecordPaymentRequest{Amount: 50}, userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ClinicInvoiceStatusPaid, updated.Status)
+	assert.Zero(t, updated.Balance)
+	assert.Equal(t, "paid", visit.PaymentStatus)
+	assert.Equal(t, 150.0, visit.Cost)
+}
+
+func TestChargeToMedicalFund_UsesOldestDonationsFirst(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+	userID := primitive.NewObjectID()
+
+	older := &entities.Donation{
+		ID: primitive.NewObjectID(), Amount: 100, Restricted: true, Designation: "Medical",
+		Status: entities.DonationStatusCompleted, DonationDate: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+	}
+	newer := &entities.Donation{
+		ID: primitive.NewObjectID(), Amount: 300, NetAmount: 290, Restricted: true, Designation: "medical",
+		Status: entities.DonationStatusCompleted, DonationDate: time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC),
+	}
+	unrestricted := &entities.Donation{ID: primitive.NewObjectID(), Amount: 1000, Designation: "medical", Status: entities.DonationStatusCompleted}
+	repos.donations.On("List", ctx, mock.MatchedBy(func(filter *repositories.DonationFilter) bool {
+		return filter.Designation == entities.MedicalDesignation && filter.SortOrder == "asc"
+	})).Return([]*entities.Donation{older, newer, unrestricted}, int64(3), nil)
+	repos.invoices.On("ChargedByDonation", ctx, []primitive.ObjectID{older.ID, newer.ID}).
+		Return(map[primitive.ObjectID]float64{older.ID: 40}, nil)
+
+	invoice := &entities.ClinicInvoice{
+		ID:            primitive.NewObjectID(),
+		InvoiceNumber: "FV/2",
+		Lines:         []entities.ClinicInvoiceLine{{ID: primitive.NewObjectID(), Description: "Surgery", Quantity: 1, UnitPrice: 500}},
+	}
+	invoice.Lines[0].Calculate()
+	invoice.Recalculate()
+	repos.invoices.On("FindByID", ctx, invoice.ID).Return(invoice, nil)
+	repos.invoices.On("UpdateIfUnchanged", ctx, invoice, mock.Anything).Return(true, nil)
+	repos.invoices.On("ReserveFund", ctx, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	repos.invoices.On("ReleaseFund", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := useCase.ChargeToMedicalFund(ctx, invoice.ID, &ChargeFundRequest{}, userID)
+	require.Error(t, err, "the fund holds only 350 of the 500 due")
+	assertStatusCode(t, err, http.StatusBadRequest)
+	assert.Empty(t, invoice.FundCharges)
+	repos.invoices.AssertCalled(t, "ReleaseFund", ctx, older.ID, 60.0)
+	repos.invoices.AssertCalled(t, "ReleaseFund", ctx, newer.ID, 290.0)
+
+	amount := 100.0
+	updated, err := useCase.ChargeToMedicalFund(ctx, invoice.ID, &ChargeFundRequest{Amount: &amount}, userID)
+	require.NoError(t, err)
+	require.Len(t, updated.FundCharges, 2)
+	assert.Equal(t, older.ID, updated.FundCharges[0].DonationID)
+	assert.Equal(t, 60.0, updated.FundCharges[0].Amount)
+	assert.Equal(t, newer.ID, updated.FundCharges[1].DonationID)
+	assert.Equal(t, 40.0, updated.FundCharges[1].Amount)
+	assert.Equal(t, 100.0, updated.ChargedToFund)
+	repos.invoices.AssertCalled(t, "ReserveFund", ctx, older.ID, 60.0, 100.0)
+	repos.invoices.AssertCalled(t, "ReserveFund", ctx, newer.ID, 40.0, 290.0)
+}
+
+func TestChargeToMedicalFund_SkipsADonationSpentByAnotherInvoiceMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+
+	spent := &entities.Donation{ID: primitive.NewObjectID(), Amount: 100, Restricted: true, Designation: "medical", Status: entities.DonationStatusCompleted}
+	other := &entities.Donation{ID: primitive.NewObjectID(), Amount: 100, Restricted: true, Designation: "medical", Status: entities.DonationStatusCompleted}
+	repos.donations.On("List", ctx, mock.Anything).Return([]*entities.Donation{spent, other}, int64(2), nil)
+	repos.invoices.On("ChargedByDonation", ctx, mock.Anything).Return(map[primitive.ObjectID]float64{}, nil)
+	repos.invoices.On("ReserveFund", ctx, spent.ID, 50.0, 100.0).Return(false, nil)
+	repos.invoices.On("ReserveFund", ctx, other.ID, 50.0, 100.0).Return(true, nil)
+
+	invoice := &entities.ClinicInvoice{ID: primitive.NewObjectID(), Total: 50, UpdatedAt: time.Now()}
+	repos.invoices.On("FindByID", ctx, invoice.ID).Return(invoice, nil)
+	repos.invoices.On("UpdateIfUnchanged", ctx, invoice, invoice.UpdatedAt).Return(true, nil)
+
+	updated, err := useCase.ChargeToMedicalFund(ctx, invoice.ID, &ChargeFundRequest{}, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	require.Len(t, updated.FundCharges, 1)
+	assert.Equal(t, other.ID, updated.FundCharges[0].DonationID)
+}
+
+func TestChargeToMedicalFund_GivesTheFundsBackWhenTheInvoiceChangedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+
+	donation := &entities.Donation{ID: primitive.NewObjectID(), Amount: 100, Restricted: true, Designation: "medical", Status: entities.DonationStatusCompleted}
+	repos.donations.On("List", ctx, mock.Anything).Return([]*entities.Donation{donation}, int64(1), nil)
+	repos.invoices.On("ChargedByDonation", ctx, mock.Anything).Return(map[primitive.ObjectID]float64{}, nil)
+	repos.invoices.On("ReserveFund", ctx, donation.ID, 50.0, 100.0).Return(true, nil)
+	repos.invoices.On("ReleaseFund", ctx, donation.ID, 50.0).Return(nil)
+
+	invoice := &entities.ClinicInvoice{ID: primitive.NewObjectID(), Total: 50}
+	repos.invoices.On("FindByID", ctx, invoice.ID).Return(invoice, nil)
+	repos.invoices.On("UpdateIfUnchanged", ctx, invoice, mock.Anything).Return(false, nil)
+
+	_, err := useCase.ChargeToMedicalFund(ctx, invoice.ID, &ChargeFundRequest{}, primitive.NewObjectID())
+
+	assertStatusCode(t, err, http.StatusConflict)
+	repos.invoices.AssertCalled(t, "ReleaseFund", ctx, donation.ID, 50.0)
+}
+
+func TestGetExpenditureReport_GroupsByMonthAndAnimal(t *testing.T) {
+	ctx := context.Background()
+	useCase, repos := newTestUseCase()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+	rex := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: "Rex"}}
+	mila := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: "Mila"}}
+	repos.animals.On("FindByID", ctx, rex.ID).Return(rex, nil)
+	repos.animals.On("FindByID", ctx, mila.ID).Return(mila, nil)
+
+	january := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	invoicedVisit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: rex.ID, VisitDate: january, Cost: 90, Status: entities.VisitStatusCompleted}
+	uninvoicedVisit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: mila.ID, VisitDate: february, Cost: 40, Status: entities.VisitStatusCompleted}
+	cancelledVisit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: mila.ID, VisitDate: february, Cost: 500, Status: entities.VisitStatusCancelled}
+
+	invoice := &entities.ClinicInvoice{
+		ID:          primitive.NewObjectID(),
+		InvoiceDate: february,
+		Lines: []entities.ClinicInvoiceLine{
+			{ID: primitive.NewObjectID(), AnimalID: &rex.ID, VisitID: &invoicedVisit.ID, ServiceDate: &january, Quantity: 1, UnitPrice: 100, DiscountPercentage: 10},
+			{ID: primitive.NewObjectID(), AnimalID: &mila.ID, ServiceDate: &february, Quantity: 1, UnitPrice: 110, DiscountPercentage: 10},
+		},
+		FundCharges: []entities.MedicalFundCharge{{ID: primitive.NewObjectID(), DonationID: primitive.NewObjectID(), Amount: 94.5}},
+	}
+	for i := range invoice.Lines {
+		invoice.Lines[i].Calculate()
+	}
+	invoice.Recalculate()
+
+	repos.invoices.On("List", ctx, mock.AnythingOfType("*repositories.ClinicInvoiceFilter")).
+		Return([]*entities.ClinicInvoice{invoice}, int64(1), nil)
+	repos.visits.On("List", ctx, mock.AnythingOfType("repositories.VeterinaryVisitFilter")).
+		Return([]*entities.VeterinaryVisit{invoicedVisit, uninvoicedVisit, cancelledVisit}, int64(3), nil)
+
+	report, err := useCase.GetExpenditureReport(ctx, from, to)
+	require.NoError(t, err)
+
+	require.Len(t, report.Months, 2)
+	assert.Equal(t, "2025-01", report.Months[0].Month)
+	assert.Equal(t, 90.0, report.Months[0].Invoiced)
+	assert.Equal(t, 10.0, report.Months[0].Discounts)
+	assert.Equal(t, 45.0, report.Months[0].ChargedToFund)
+	assert.Equal(t, "2025-02", report.Months[1].Month)
+	assert.Equal(t, 99.0, report.Months[1].Invoiced)
+	assert.Equal(t, 40.0, report.Months[1].Uninvoiced)
+	assert.Equal(t, 139.0, report.Months[1].Total)
+	assert.Equal(t, 1, report.Months[1].Animals)
+
+	assert.Equal(t, 229.0, report.Total)
+	assert.Equal(t, 94.5, report.ChargedToFund)
+	require.Len(t, report.Animals, 2)
+	assert.Equal(t, "Mila", report.Animals[0].AnimalName)
+	assert.Equal(t, 139.0, report.Animals[0].Total)
+	assert.Equal(t, "Rex", report.Animals[1].AnimalName)
+	assert.Equal(t, 90.0, report.Animals[1].Total)
+}
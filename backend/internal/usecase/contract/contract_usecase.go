@@ -0,0 +1,666 @@
+package contract
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ContractTag marks the documents holding signed adoption contracts
+const ContractTag = "adoption-contract"
+
+// DefaultLanguage is used when no contract language is requested
+const DefaultLanguage = "pl"
+
+// DefaultLinkValidityDays is how long a signing link can be used
+const DefaultLinkValidityDays = 14
+
+// Placeholders lists the values a template can insert, as {{name}}
+var Placeholders = []string{
+	"organization",
+	"adopter_name",
+	"adopter_email",
+	"adopter_phone",
+	"adopter_address",
+	"animal_name",
+	"species",
+	"breed",
+	"microchip_number",
+	"adoption_date",
+	"adoption_fee",
+	"contract_date",
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// Messenger sends the signing link of a contract to the adopter by email or SMS
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// ContractUseCase generates adoption contracts from templates and collects the adopter's electronic signature
+type ContractUseCase struct {
+	contractRepo    repositories.ContractRepository
+	templateRepo    repositories.ContractTemplateRepository
+	adoptionRepo    repositories.AdoptionRepository
+	applicationRepo repositories.AdoptionApplicationRepository
+	animalRepo      repositories.AnimalRepository
+	documentRepo    repositories.DocumentRepository
+	settingsRepo    repositories.SettingsRepository
+	auditLogRepo    repositories.AuditLogRepository
+	storageService  *storage.StorageService
+	messenger       Messenger
+	signingURL      string // the signing page of the web app; the token is appended
+}
+
+// NewContractUseCase creates a new contract use case
+func NewContractUseCase(
+	contractRepo repositories.ContractRepository,
+	templateRepo repositories.ContractTemplateRepository,
+	adoptionRepo repositories.AdoptionRepository,
+	applicationRepo repositories.AdoptionApplicationRepository,
+	animalRepo repositories.AnimalRepository,
+	documentRepo repositories.DocumentRepository,
+	settingsRepo repositories.SettingsRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	storageService *storage.StorageService,
+	messenger Messenger,
+	signingURL string,
+) *ContractUseCase {
+	return &ContractUseCase{
+		contractRepo:    contractRepo,
+		templateRepo:    templateRepo,
+		adoptionRepo:    adoptionRepo,
+		applicationRepo: applicationRepo,
+		animalRepo:      animalRepo,
+		documentRepo:    documentRepo,
+		settingsRepo:    settingsRepo,
+		auditLogRepo:    auditLogRepo,
+		storageService:  storageService,
+		messenger:       messenger,
+		signingURL:      strings.TrimRight(signingURL, "/"),
+	}
+}
+
+// CreateTemplateRequest represents a new version of the contract template in a language
+type CreateTemplateRequest struct {
+	Language string                     `json:"language" validate:"required,oneof=en pl"`
+	Title    string                     `json:"title" validate:"required"`
+	Sections []entities.ContractSection `json:"sections" validate:"required,min=1,dive"`
+	Consents []entities.ContractConsent `json:"consents,omitempty" validate:"omitempty,dive"`
+	Notes    string                     `json:"notes,omitempty"`
+}
+
+// GenerateContractRequest selects the template of a contract and how the signing link is delivered
+type GenerateContractRequest struct {
+	Language   string `json:"language,omitempty" validate:"omitempty,oneof=en pl"`
+	TemplateID string `json:"template_id,omitempty"` // a specific template version; defaults to the newest in the language
+	Send       bool   `json:"send"`                  // email the signing link to the applicant, or text it when there is no email
+	ValidDays  int    `json:"valid_days,omitempty" validate:"omitempty,min=1,max=90"`
+}
+
+// RenewLinkRequest represents a request for a new signing link
+type RenewLinkRequest struct {
+	Send      bool `json:"send"`
+	ValidDays int  `json:"valid_days,omitempty" validate:"omitempty,min=1,max=90"`
+}
+
+// VoidContractRequest represents a request to void a contract
+type VoidContractRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ListContractsRequest represents filters for listing contracts
+type ListContractsRequest struct {
+	AdoptionID string `form:"adoption_id"`
+	AnimalID   string `form:"animal_id"`
+	Status     string `form:"status"`
+	Limit      int64  `form:"limit"`
+	Offset     int64  `form:"offset"`
+}
+
+// IssuedContract is a contract with its signing link. The link is only returned
+// when the token is issued, since only its hash is stored.
+type IssuedContract struct {
+	Contract   *entities.Contract `json:"contract"`
+	SigningURL string             `json:"signing_url"`
+}
+
+// Client identifies the browser that opened or signed a contract
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+// CreateTemplate saves a template as the next version in its language
+func (uc *ContractUseCase) CreateTemplate(ctx context.Context, req *CreateTemplateRequest, userID primitive.ObjectID) (*entities.ContractTemplate, error) {
+	template := &entities.ContractTemplate{
+		Language:  req.Language,
+		Title:     strings.TrimSpace(req.Title),
+		Sections:  req.Sections,
+		Consents:  req.Consents,
+		Notes:     req.Notes,
+		CreatedBy: userID,
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	latest, err := uc.templateRepo.FindLatest(ctx, req.Language)
+	if err != nil {
+		return nil, err
+	}
+	template.Version = 1
+	if latest != nil {
+		template.Version = latest.Version + 1
+	}
+
+	if err := uc.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionCreate, "contract_template", template.Title, "saved contract template version").
+			WithEntityID(template.ID).
+			WithChanges(map[string]interface{}{
+				"language": template.Language,
+				"version":  template.Version,
+			}))
+
+	return template, nil
+}
+
+// GetTemplate returns a template version
+func (uc *ContractUseCase) GetTemplate(ctx context.Context, id primitive.ObjectID) (*entities.ContractTemplate, error) {
+	template, err := uc.templateRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewNotFound("contract template not found")
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListTemplates returns the saved template versions, newest first
+func (uc *ContractUseCase) ListTemplates(ctx context.Context, language string) ([]*entities.ContractTemplate, error) {
+	templates, err := uc.templateRepo.List(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []*entities.ContractTemplate{}
+	}
+	return templates, nil
+}
+
+// GenerateContract renders the adoption contract from a template with the application, animal and fee
+// data and issues a signing link. A contract of the adoption still waiting for a signature is voided.
+func (uc *ContractUseCase) GenerateContract(ctx context.Context, adoptionID primitive.ObjectID, req *GenerateContractRequest, userID primitive.ObjectID) (*IssuedContract, error) {
+	adoption, err := uc.adoptionRepo.FindByID(ctx, adoptionID)
+	if err != nil {
+		return nil, err
+	}
+	if adoption.Status == entities.AdoptionStatusReturned || adoption.Status == entities.AdoptionStatusCancelled {
+		return nil, errors.NewBadRequest("cannot generate a contract for a " + string(adoption.Status) + " adoption")
+	}
+
+	existing, _, err := uc.contractRepo.List(ctx, &repositories.ContractFilter{AdoptionID: &adoptionID})
+	if err != nil {
+		return nil, err
+	}
+	for _, contract := range existing {
+		if contract.Status == entities.ContractStatusSigned {
+			return nil, errors.NewConflict("the adoption already has a signed contract; void it before generating a new one")
+		}
+	}
+
+	application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID)
+	if err != nil {
+		return nil, err
+	}
+	applicant := application.Applicant
+	if req.Send && applicant.Email == "" && applicant.Phone == "" {
+		return nil, errors.NewBadRequest("the applicant has no email address or phone number to send the signing link to")
+	}
+
+	template, err := uc.resolveTemplate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	l := labelsFor(template.Language)
+
+	contract := &entities.Contract{
+		ID:              primitive.NewObjectID(),
+		AdoptionID:      adoption.ID,
+		ApplicationID:   adoption.ApplicationID,
+		AnimalID:        adoption.AnimalID,
+		TemplateVersion: template.Version,
+		Language:        template.Language,
+		Status:          entities.ContractStatusPending,
+		CreatedBy:       userID,
+		Parties: entities.ContractParties{
+			Organization:    uc.organizationName(ctx),
+			AdopterName:     strings.TrimSpace(applicant.FirstName + " " + applicant.LastName),
+			AdopterEmail:    applicant.Email,
+			AdopterPhone:    applicant.Phone,
+			AdopterAddress:  formatAddress(application.Address),
+			AnimalName:      animalName(animal, template.Language),
+			Species:         l.value(animal.Species),
+			Breed:           animal.Breed,
+			MicrochipNumber: animal.Medical.MicrochipNumber,
+			AdoptionDate:    adoption.AdoptionDate,
+			AdoptionFee:     adoption.AdoptionFee,
+		},
+	}
+	if !template.ID.IsZero() {
+		templateID := template.ID
+		contract.TemplateID = &templateID
+	}
+
+	// Placeholders are filled in once, so the stored text is exactly what the adopter signs
+	data := placeholderData(contract.Parties, l, time.Now())
+	contract.Title = render(template.Title, data)
+	for _, section := range template.Sections {
+		contract.Sections = append(contract.Sections, entities.ContractSection{
+			Heading: render(section.Heading, data),
+			Body:    render(section.Body, data),
+		})
+	}
+	for _, consent := range template.Consents {
+		if consent.Key == entities.ContractConsentSpayNeuter && animal.Medical.Sterilized {
+			continue
+		}
+		contract.Consents = append(contract.Consents, entities.ContractConsent{
+			Key:      consent.Key,
+			Text:     render(consent.Text, data),
+			Required: consent.Required,
+		})
+	}
+	contract.ContentHash = contract.ComputeContentHash()
+	contract.AddEvent(entities.ContractEvent{
+		Action:  entities.ContractEventCreated,
+		UserID:  &userID,
+		Details: fmt.Sprintf("template %s v%d", contract.Language, contract.TemplateVersion),
+	})
+
+	token, err := uc.issueToken(contract, req.ValidDays)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one link per adoption is valid at a time
+	for _, previous := range existing {
+		if previous.Status == entities.ContractStatusPending {
+			previous.Void("superseded by a new contract", &userID)
+			if err := uc.contractRepo.Update(ctx, previous); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := uc.contractRepo.Create(ctx, contract); err != nil {
+		return nil, err
+	}
+
+	issued := &IssuedContract{Contract: contract, SigningURL: uc.link(token)}
+	if req.Send {
+		uc.sendLink(ctx, contract, issued.SigningURL, userID)
+	}
+
+	// Create audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionCreate, "contract", contract.Title, "generated adoption contract").
+			WithEntityID(contract.ID).
+			WithChanges(map[string]interface{}{
+				"adoption_id":      adoption.ID.Hex(),
+				"language":         contract.Language,
+				"template_version": contract.TemplateVersion,
+				"sent":             req.Send,
+			}))
+
+	return issued, nil
+}
+
+// RenewLink issues a new signing link for a pending contract, invalidating the previous one
+func (uc *ContractUseCase) RenewLink(ctx context.Context, id primitive.ObjectID, req *RenewLinkRequest, userID primitive.ObjectID) (*IssuedContract, error) {
+	contract, err := uc.GetContract(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contract.Status != entities.ContractStatusPending {
+		return nil, errors.NewBadRequest("only a contract waiting for a signature can get a new link")
+	}
+
+	token, err := uc.issueToken(contract, req.ValidDays)
+	if err != nil {
+		return nil, err
+	}
+	issued := &IssuedContract{Contract: contract, SigningURL: uc.link(token)}
+	if req.Send {
+		uc.sendLink(ctx, contract, issued.SigningURL, userID)
+	}
+
+	if err := uc.contractRepo.Update(ctx, contract); err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+// VoidContract voids a contract. Voiding a signed contract unlinks it from the adoption.
+func (uc *ContractUseCase) VoidContract(ctx context.Context, id primitive.ObjectID, req *VoidContractRequest, userID primitive.ObjectID) (*entities.Contract, error) {
+	contract, err := uc.GetContract(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contract.Status == entities.ContractStatusVoided {
+		return nil, errors.NewBadRequest("the contract is already voided")
+	}
+	signed := contract.Status == entities.ContractStatusSigned
+
+	contract.Void(req.Reason, &userID)
+	if err := uc.contractRepo.Update(ctx, contract); err != nil {
+		return nil, err
+	}
+
+	if signed {
+		if adoption, err := uc.adoptionRepo.FindByID(ctx, contract.AdoptionID); err == nil &&
+			adoption.Contract.ContractID != nil && *adoption.Contract.ContractID == contract.ID {
+			adoption.Contract = entities.AdoptionContract{}
+			adoption.UpdatedBy = userID
+			_ = uc.adoptionRepo.Update(ctx, adoption)
+		}
+	}
+
+	// Create audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "contract", contract.Title, "voided adoption contract").
+			WithEntityID(contract.ID).
+			WithChanges(map[string]interface{}{
+				"reason": req.Reason,
+				"signed": signed,
+			}))
+
+	return contract, nil
+}
+
+// GetContract returns a contract
+func (uc *ContractUseCase) GetContract(ctx context.Context, id primitive.ObjectID) (*entities.Contract, error) {
+	contract, err := uc.contractRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewNotFound("contract not found")
+		}
+		return nil, err
+	}
+	return contract, nil
+}
+
+// ListContracts returns the contracts matching the filters, newest first
+func (uc *ContractUseCase) ListContracts(ctx context.Context, req *ListContractsRequest) ([]*entities.Contract, int64, error) {
+	filter := &repositories.ContractFilter{
+		Status: req.Status,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	if req.AdoptionID != "" {
+		id, err := primitive.ObjectIDFromHex(req.AdoptionID)
+		if err != nil {
+			return nil, 0, errors.NewBadRequest("invalid adoption ID")
+		}
+		filter.AdoptionID = &id
+	}
+	if req.AnimalID != "" {
+		id, err := primitive.ObjectIDFromHex(req.AnimalID)
+		if err != nil {
+			return nil, 0, errors.NewBadRequest("invalid animal ID")
+		}
+		filter.AnimalID = &id
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+
+	contracts, total, err := uc.contractRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if contracts == nil {
+		contracts = []*entities.Contract{}
+	}
+	return contracts, total, nil
+}
+
+// resolveTemplate returns the requested template version, the newest in the language,
+// or the built-in text when no template was saved yet
+func (uc *ContractUseCase) resolveTemplate(ctx context.Context, req *GenerateContractRequest) (*entities.ContractTemplate, error) {
+	if req.TemplateID != "" {
+		id, err := primitive.ObjectIDFromHex(req.TemplateID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid template ID")
+		}
+		return uc.GetTemplate(ctx, id)
+	}
+
+	language := req.Language
+	if language == "" {
+		language = DefaultLanguage
+	}
+	l, ok := contractLabels[language]
+	if !ok {
+		return nil, errors.NewBadRequest("Unsupported contract language")
+	}
+
+	template, err := uc.templateRepo.FindLatest(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		builtIn := l.Template
+		template = &builtIn
+	}
+	return template, nil
+}
+
+// issueToken sets a new signing token on the contract and returns it
+func (uc *ContractUseCase) issueToken(contract *entities.Contract, validDays int) (string, error) {
+	if validDays <= 0 {
+		validDays = DefaultLinkValidityDays
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, 500, "failed to generate signing token")
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	contract.TokenHash = entities.HashToken(token)
+	contract.TokenExpiresAt = time.Now().AddDate(0, 0, validDays)
+	return token, nil
+}
+
+// link returns the signing page URL for a token
+func (uc *ContractUseCase) link(token string) string {
+	return uc.signingURL + "/" + token
+}
+
+// sendLink queues the signing link to the adopter, by email or else by text message
+func (uc *ContractUseCase) sendLink(ctx context.Context, contract *entities.Contract, url string, userID primitive.ObjectID) {
+	if uc.messenger == nil {
+		return
+	}
+
+	l := labelsFor(contract.Language)
+	data := map[string]string{
+		"adopter_name": contract.Parties.AdopterName,
+		"animal_name":  contract.Parties.AnimalName,
+		"url":          url,
+		"expires":      l.date(contract.TokenExpiresAt),
+	}
+
+	channel := entities.TemplateTypeEmail
+	body := render(l.LinkBody, data)
+	recipient := contract.Parties.AdopterEmail
+	if recipient == "" {
+		if contract.Parties.AdopterPhone == "" {
+			return
+		}
+		channel = entities.TemplateTypeSMS
+		body = render(l.LinkSMS, data)
+		recipient = contract.Parties.AdopterPhone
+	}
+
+	communication := entities.NewCommunication(channel, entities.TemplateCategoryAdoption, contract.Parties.AdopterEmail, render(l.LinkSubject, data), body, userID)
+	communication.RecipientPhone = contract.Parties.AdopterPhone
+	communication.RecipientName = contract.Parties.AdopterName
+	communication.RelatedType = "adoption"
+	communication.RelatedID = &contract.AdoptionID
+	communication.Metadata["contract_id"] = contract.ID.Hex()
+
+	if err := uc.messenger.CreateCommunication(ctx, communication, userID); err != nil {
+		return
+	}
+	contract.AddEvent(entities.ContractEvent{
+		Action:  entities.ContractEventSent,
+		UserID:  &userID,
+		Details: string(channel) + " to " + recipient,
+	})
+}
+
+// organizationName returns the foundation name printed as a party of the contract
+func (uc *ContractUseCase) organizationName(ctx context.Context) string {
+	if uc.settingsRepo == nil {
+		return ""
+	}
+
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil {
+		return ""
+	}
+	if settings.LegalName != "" {
+		return settings.LegalName
+	}
+	return settings.Name
+}
+
+// validateTemplate checks that the consents are distinct and every placeholder is known
+func validateTemplate(template *entities.ContractTemplate) error {
+	known := make(map[string]bool, len(Placeholders))
+	for _, placeholder := range Placeholders {
+		known[placeholder] = true
+	}
+
+	texts := []string{template.Title}
+	for _, section := range template.Sections {
+		texts = append(texts, section.Heading, section.Body)
+	}
+	seen := map[entities.ContractConsentKey]bool{}
+	for _, consent := range template.Consents {
+		if seen[consent.Key] {
+			return errors.NewBadRequest("consent " + string(consent.Key) + " is listed more than once")
+		}
+		seen[consent.Key] = true
+		texts = append(texts, consent.Text)
+	}
+
+	var unknown []string
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if name := strings.TrimSpace(match[1]); !known[name] {
+				unknown = append(unknown, match[0])
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.NewBadRequest("unknown placeholders: " + strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// placeholderData returns the values inserted into a template
+func placeholderData(parties entities.ContractParties, l labels, now time.Time) map[string]string {
+	data := map[string]string{
+		"organization":     parties.Organization,
+		"adopter_name":     parties.AdopterName,
+		"adopter_email":    parties.AdopterEmail,
+		"adopter_phone":    parties.AdopterPhone,
+		"adopter_address":  parties.AdopterAddress,
+		"animal_name":      parties.AnimalName,
+		"species":          parties.Species,
+		"breed":            parties.Breed,
+		"microchip_number": parties.MicrochipNumber,
+		"adoption_date":    l.date(parties.AdoptionDate),
+		"adoption_fee":     l.amount(parties.AdoptionFee),
+		"contract_date":    l.date(now),
+	}
+	for key, value := range data {
+		if value == "" {
+			data[key] = "-"
+		}
+	}
+	return data
+}
+
+// render fills in the placeholders of a template text
+func render(text string, data map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-2])
+		if value, ok := data[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// formatAddress prints an address on one line
+func formatAddress(address entities.AddressInfo) string {
+	city := strings.TrimSpace(address.ZipCode + " " + address.City)
+	var parts []string
+	for _, part := range []string{address.Street, city, address.State, address.Country} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// animalName returns the animal's name in the language, falling back to the English name
+func animalName(animal *entities.Animal, language string) string {
+	if language == "pl" && animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	return animal.Name.Polish
+}
+
+// linkError returns the error for a signing token that does not open a pending contract
+func linkError(err error) error {
+	if err == errors.ErrNotFound {
+		return errors.NewNotFound("the signing link is invalid or the contract was already signed")
+	}
+	return err
+}
+
+// errLinkExpired is returned for a signing link past its expiry
+var errLinkExpired = errors.New(http.StatusGone, "the signing link has expired; please ask the shelter for a new one")
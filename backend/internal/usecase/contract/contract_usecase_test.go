@@ -0,0 +1,354 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type contractMocks struct {
+	contracts    *mocks.ContractRepository
+	templates    *mocks.ContractTemplateRepository
+	adoptions    *mocks.AdoptionRepository
+	applications *mocks.AdoptionApplicationRepository
+	animals      *mocks.AnimalRepository
+	documents    *mocks.DocumentRepository
+	messenger    *testutil.Messenger
+}
+
+func newContractUseCase(t *testing.T) (*ContractUseCase, *contractMocks) {
+	m := &contractMocks{
+		contracts:    new(mocks.ContractRepository),
+		templates:    new(mocks.ContractTemplateRepository),
+		adoptions:    new(mocks.AdoptionRepository),
+		applications: new(mocks.AdoptionApplicationRepository),
+		animals:      new(mocks.AnimalRepository),
+		documents:    new(mocks.DocumentRepository),
+		messenger:    &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+	storageService := storage.NewStorageService(storage.NewLocalBackend(t.TempDir()), "/uploads", 1<<20)
+	uc := NewContractUseCase(m.contracts, m.templates, m.adoptions, m.applications, m.animals, m.documents, nil, auditLogs, storageService, m.messenger, "https://shelter.example/contracts/sign/")
+	return uc, m
+}
+
+// pendingAdoption registers an adoption of an intact dog with its application and animal
+func pendingAdoption(m *contractMocks) *entities.Adoption {
+	animal := &entities.Animal{
+		ID:      primitive.NewObjectID(),
+		Name:    entities.MultilingualName{English: "Rex", Polish: "Reksio"},
+		Species: "dog",
+		Breed:   "Mixed",
+		Medical: entities.MedicalInfo{MicrochipNumber: "616093900012345"},
+	}
+	application := &entities.AdoptionApplication{
+		ID:        primitive.NewObjectID(),
+		AnimalID:  animal.ID,
+		Applicant: entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak", Email: "anna@example.com", Phone: "+48500100200"},
+		Address:   entities.AddressInfo{Street: "Długa 5", City: "Kraków", ZipCode: "30-001", Country: "PL"},
+	}
+	adoption := entities.NewAdoption(application.ID, animal.ID, primitive.NewObjectID(), 250, primitive.NewObjectID())
+	adoption.ID = primitive.NewObjectID()
+	adoption.AdoptionDate = time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+
+	m.adoptions.On("FindByID", mock.Anything, adoption.ID).Return(adoption, nil)
+	m.applications.On("FindByID", mock.Anything, application.ID).Return(application, nil)
+	m.animals.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	return adoption
+}
+
+// generate issues a contract with the built-in template and returns it with its token
+func generate(t *testing.T, uc *ContractUseCase, m *contractMocks, adoption *entities.Adoption, language string) (*entities.Contract, string) {
+	m.contracts.On("List", mock.Anything, mock.MatchedBy(func(filter *repositories.ContractFilter) bool {
+		return filter.AdoptionID != nil && *filter.AdoptionID == adoption.ID
+	})).Return([]*entities.Contract{}, int64(0), nil).Once()
+	m.templates.On("FindLatest", mock.Anything, language).Return(nil, nil).Once()
+	m.contracts.On("Create", mock.Anything, mock.AnythingOfType("*entities.Contract")).Return(nil).Once()
+
+	issued, err := uc.GenerateContract(context.Background(), adoption.ID, &GenerateContractRequest{Language: language, Send: true}, primitive.NewObjectID())
+	require.NoError(t, err)
+	token := strings.TrimPrefix(issued.SigningURL, "https://shelter.example/contracts/sign/")
+	require.NotEqual(t, issued.SigningURL, token)
+	return issued.Contract, token
+}
+
+func signatureImage(t *testing.T) string {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 10))
+	for x := 0; x < 40; x++ {
+		img.Set(x, 5, color.NRGBA{A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestContractUseCase_GenerateContract(t *testing.T) {
+	uc, m := newContractUseCase(t)
+	adoption := pendingAdoption(m)
+
+	contract, token := generate(t, uc, m, adoption, "pl")
+
+	assert.Equal(t, entities.ContractStatusPending, contract.Status)
+	assert.Equal(t, "Umowa adopcyjna", contract.Title)
+	assert.Nil(t, contract.TemplateID, "no template was saved, so the built-in text is used")
+	assert.Equal(t, entities.HashToken(token), contract.TokenHash, "only the token hash is stored")
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, DefaultLinkValidityDays), contract.TokenExpiresAt, time.Minute)
+	assert.True(t, contract.IsIntact())
+
+	assert.Equal(t, "Anna Nowak", contract.Parties.AdopterName)
+	assert.Equal(t, "Długa 5, 30-001 Kraków, PL", contract.Parties.AdopterAddress)
+	assert.Equal(t, "pies", contract.Parties.Species)
+	assert.Contains(t, contract.Sections[0].Body, "przekazuje Anna Nowak opiekę", "placeholders are filled in")
+	assert.Contains(t, contract.Sections[0].Body, "Reksio (pies, mikroczip 616093900012345)")
+	assert.Contains(t, contract.Sections[0].Body, "04.05.2026")
+	assert.Contains(t, contract.Sections[2].Body, "250,00")
+	for _, section := range contract.Sections {
+		assert.NotContains(t, section.Body, "{{")
+	}
+	require.Len(t, contract.Consents, 4, "an intact animal gets the spay/neuter consent")
+
+	require.Len(t, m.messenger.Sent, 1)
+	message := m.messenger.Sent[0]
+	assert.Equal(t, entities.TemplateTypeEmail, message.Type)
+	assert.Equal(t, "anna@example.com", message.RecipientEmail)
+	assert.Contains(t, message.Body, "https://shelter.example/contracts/sign/"+token)
+	assert.Equal(t, []entities.ContractEventAction{entities.ContractEventCreated, entities.ContractEventSent},
+		[]entities.ContractEventAction{contract.Events[0].Action, contract.Events[1].Action})
+
+	// A new contract supersedes the one waiting for a signature
+	m.contracts.On("List", mock.Anything, mock.Anything).Return([]*entities.Contract{contract}, int64(1), nil).Once()
+	m.templates.On("FindLatest", mock.Anything, "en").Return(&entities.ContractTemplate{
+		ID:       primitive.NewObjectID(),
+		Language: "en",
+		Version:  3,
+		Title:    "Contract for {{animal_name}}",
+		Sections: []entities.ContractSection{{Heading: "Terms", Body: "Fee: {{adoption_fee}}"}},
+		Consents: []entities.ContractConsent{{Key: entities.ContractConsentFollowUp, Text: "Follow-ups", Required: true}},
+	}, nil).Once()
+	m.contracts.On("Update", mock.Anything, contract).Return(nil).Once()
+	m.contracts.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+
+	issued, err := uc.GenerateContract(context.Background(), adoption.ID, &GenerateContractRequest{Language: "en"}, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, entities.ContractStatusVoided, contract.Status)
+	assert.Empty(t, contract.TokenHash)
+	assert.Equal(t, "Contract for Rex", issued.Contract.Title)
+	assert.Equal(t, 3, issued.Contract.TemplateVersion)
+	assert.Equal(t, "Fee: 250.00", issued.Contract.Sections[0].Body)
+	assert.Len(t, m.messenger.Sent, 1, "the link is only sent on request")
+}
+
+func TestContractUseCase_SignContract(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newContractUseCase(t)
+	adoption := pendingAdoption(m)
+	contract, token := generate(t, uc, m, adoption, "en")
+	m.contracts.On("FindByTokenHash", mock.Anything, entities.HashToken(token)).Return(contract, nil)
+	m.contracts.On("FindByID", mock.Anything, contract.ID).Return(contract, nil)
+	m.contracts.On("AddEvent", mock.Anything, contract.ID, mock.AnythingOfType("entities.ContractEvent")).Run(func(args mock.Arguments) {
+		contract.AddEvent(args.Get(2).(entities.ContractEvent))
+	}).Return(nil)
+	m.contracts.On("UpdateIfPending", mock.Anything, contract, entities.HashToken(token)).Return(true, nil)
+	client := Client{IPAddress: "203.0.113.7", UserAgent: "Firefox"}
+
+	view, err := uc.ViewContract(ctx, token, client)
+	require.NoError(t, err)
+	assert.Equal(t, contract.Sections, view.Sections)
+	_, err = uc.ViewContract(ctx, token, client)
+	require.NoError(t, err)
+	assert.Len(t, contract.Events, 3, "reloading the page is recorded once")
+
+	_, err = uc.SignContract(ctx, token, &SignContractRequest{
+		SignerName: "Anna Nowak",
+		Method:     entities.SignatureMethodDrawn,
+		Signature:  signatureImage(t),
+		Consents:   []entities.ContractConsentKey{entities.ContractConsentReturnPolicy, entities.ContractConsentMedicalCare},
+	}, client)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spay_neuter is required")
+
+	var document *entities.Document
+	m.documents.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Run(func(args mock.Arguments) {
+		document = args.Get(1).(*entities.Document)
+	}).Return(nil)
+	m.adoptions.On("Update", mock.Anything, adoption).Return(nil)
+
+	receipt, err := uc.SignContract(ctx, token, &SignContractRequest{
+		SignerName: "Anna Nowak",
+		Method:     entities.SignatureMethodDrawn,
+		Signature:  signatureImage(t),
+		Consents: []entities.ContractConsentKey{
+			entities.ContractConsentFollowUp,
+			entities.ContractConsentReturnPolicy,
+			entities.ContractConsentSpayNeuter,
+			entities.ContractConsentMedicalCare,
+		},
+	}, client)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.ContractStatusSigned, contract.Status)
+	assert.Empty(t, contract.TokenHash, "the link cannot be used again")
+	require.NotNil(t, contract.Signature)
+	assert.Len(t, contract.Signature.ImageHash, 64)
+	assert.Equal(t, "203.0.113.7", contract.Signature.IPAddress)
+	assert.Equal(t, entities.ContractEventSigned, contract.Events[len(contract.Events)-1].Action)
+
+	require.NotNil(t, document)
+	assert.Equal(t, entities.DocumentTypeContract, document.Type)
+	assert.Equal(t, "adoption", document.RelatedEntity)
+	assert.Equal(t, adoption.ID, *document.RelatedEntityID)
+	assert.Equal(t, document.Checksum, receipt.DocumentHash)
+	assert.Equal(t, contract.ContentHash, document.Metadata["content_hash"])
+
+	assert.Equal(t, document.FileURL, adoption.Contract.ContractURL)
+	assert.Equal(t, "Anna Nowak", adoption.Contract.SignedBy)
+	assert.Equal(t, contract.ID, *adoption.Contract.ContractID)
+	assert.Len(t, adoption.Contract.Terms, 4)
+	assert.True(t, adoption.AgreesToReturnPolicy)
+	assert.True(t, adoption.AgreesToSpayNeuter)
+	assert.True(t, adoption.AgreesToMedicalCare)
+	assert.True(t, adoption.AgreesToFollowUp)
+
+	stored, _, err := uc.storageService.Open(ctx, document.FileURL)
+	require.NoError(t, err)
+	var content bytes.Buffer
+	_, err = content.ReadFrom(stored)
+	stored.Close()
+	require.NoError(t, err)
+	pdf := content.String()
+	assert.Contains(t, pdf, "/Subtype /Image", "the drawn signature is embedded")
+	assert.Contains(t, pdf, shortHash(contract.ContentHash), "every page carries the content hash")
+	assert.Contains(t, pdf, "(203.0.113.7)", "the audit trail is printed")
+
+	m.documents.On("FindByID", mock.Anything, document.ID).Return(document, nil)
+	verification, err := uc.VerifyContract(ctx, contract.ID, bytes.NewReader(content.Bytes()))
+	require.NoError(t, err)
+	assert.True(t, verification.ContentIntact)
+	assert.True(t, verification.StoredIntact)
+	assert.True(t, *verification.FileMatches)
+	assert.True(t, verification.Valid)
+
+	tampered := bytes.Replace(content.Bytes(), []byte("Anna Nowak"), []byte("Anna Kowal"), 1)
+	verification, err = uc.VerifyContract(ctx, contract.ID, bytes.NewReader(tampered))
+	require.NoError(t, err)
+	assert.False(t, *verification.FileMatches)
+	assert.False(t, verification.Valid)
+
+	contract.Parties.AdoptionFee = 10
+	verification, err = uc.VerifyContract(ctx, contract.ID, nil)
+	require.NoError(t, err)
+	assert.False(t, verification.ContentIntact, "a changed record no longer matches its hash")
+	assert.False(t, verification.Valid)
+}
+
+func TestContractUseCase_SignContractConflictsWhenSignedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newContractUseCase(t)
+	adoption := pendingAdoption(m)
+	contract, token := generate(t, uc, m, adoption, "en")
+	m.contracts.On("FindByTokenHash", mock.Anything, entities.HashToken(token)).Return(contract, nil)
+	m.contracts.On("UpdateIfPending", mock.Anything, contract, entities.HashToken(token)).Return(false, nil)
+
+	var document *entities.Document
+	m.documents.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Run(func(args mock.Arguments) {
+		document = args.Get(1).(*entities.Document)
+	}).Return(nil)
+	m.documents.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := uc.SignContract(ctx, token, &SignContractRequest{
+		SignerName: "Anna Nowak",
+		Method:     entities.SignatureMethodTyped,
+		Consents: []entities.ContractConsentKey{
+			entities.ContractConsentFollowUp,
+			entities.ContractConsentReturnPolicy,
+			entities.ContractConsentSpayNeuter,
+			entities.ContractConsentMedicalCare,
+		},
+	}, Client{})
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, err.(*errors.AppError).Code)
+	require.NotNil(t, document)
+	m.documents.AssertCalled(t, "Delete", mock.Anything, document.ID)
+	_, _, err = uc.storageService.Open(ctx, document.FileURL)
+	assert.Error(t, err, "the PDF of the lost signature is removed")
+	m.adoptions.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestContractUseCase_SignContractRejectsInvalidLinks(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newContractUseCase(t)
+	adoption := pendingAdoption(m)
+	contract, token := generate(t, uc, m, adoption, "en")
+	m.contracts.On("FindByTokenHash", mock.Anything, entities.HashToken(token)).Return(contract, nil)
+	m.contracts.On("FindByTokenHash", mock.Anything, mock.Anything).Return(nil, errors.ErrNotFound)
+	typed := &SignContractRequest{SignerName: "Anna Nowak", Method: entities.SignatureMethodTyped}
+
+	_, err := uc.SignContract(ctx, "unknown", typed, Client{})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, err.(*errors.AppError).Code)
+
+	contract.Sections[1].Body = "The adopter may breed the animal."
+	_, err = uc.SignContract(ctx, token, typed, Client{})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusConflict, err.(*errors.AppError).Code, "a changed text cannot be signed")
+
+	contract.TokenExpiresAt = time.Now().Add(-time.Hour)
+	_, err = uc.ViewContract(ctx, token, Client{})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusGone, err.(*errors.AppError).Code)
+}
+
+func TestContractUseCase_CreateTemplate(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newContractUseCase(t)
+	m.templates.On("FindLatest", mock.Anything, "en").Return(&entities.ContractTemplate{Language: "en", Version: 2}, nil)
+	m.templates.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	template, err := uc.CreateTemplate(ctx, &CreateTemplateRequest{
+		Language: "en",
+		Title:    "Adoption of {{animal_name}}",
+		Sections: []entities.ContractSection{{Heading: "Fee", Body: "{{ adoption_fee }} paid by {{adopter_name}}"}},
+	}, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, 3, template.Version)
+
+	_, err = uc.CreateTemplate(ctx, &CreateTemplateRequest{
+		Language: "en",
+		Title:    "Adoption",
+		Sections: []entities.ContractSection{{Heading: "Fee", Body: "{{fee}} paid by {{adopter}}"}},
+	}, primitive.NewObjectID())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown placeholders: {{adopter}}, {{fee}}")
+
+	_, err = uc.CreateTemplate(ctx, &CreateTemplateRequest{
+		Language: "en",
+		Title:    "Adoption",
+		Sections: []entities.ContractSection{{Heading: "Terms", Body: "Terms"}},
+		Consents: []entities.ContractConsent{
+			{Key: entities.ContractConsentFollowUp, Text: "Visits"},
+			{Key: entities.ContractConsentFollowUp, Text: "Calls"},
+		},
+	}, primitive.NewObjectID())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "listed more than once")
+	m.templates.AssertNumberOfCalls(t, "Create", 1)
+}
@@ -0,0 +1,269 @@
+package contract
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+)
+
+// labels holds the fixed text of a contract in one language: the PDF headings,
+// the signing link message and the built-in contract used until a template is saved
+type labels struct {
+	DateLayout      string
+	DateTimeLayout  string
+	DecimalComma    bool
+	DocumentName    string
+	Generated       string
+	Parties         string
+	Organization    string
+	Adopter         string
+	Address         string
+	Email           string
+	Phone           string
+	Animal          string
+	Species         string
+	Breed           string
+	Microchip       string
+	AdoptionDate    string
+	AdoptionFee     string
+	Consents        string
+	Signature       string
+	SignedBy        string
+	SignedAt        string
+	Method          string
+	SigningRecord   string
+	RecordNote      string
+	ContractID      string
+	TemplateVersion string
+	BuiltIn         string
+	ContentHash     string
+	SignatureHash   string
+	Event           string
+	Date            string
+	IPAddress       string
+	Details         string
+
+	// The signing link message; placeholders: {{adopter_name}}, {{animal_name}}, {{url}}, {{expires}}
+	LinkSubject string
+	LinkBody    string
+	LinkSMS     string
+
+	// Values holds translations of stored enum values, keyed by the value
+	Values map[string]string
+
+	// Template is the built-in contract text
+	Template entities.ContractTemplate
+}
+
+var contractLabels = map[string]labels{
+	"en": {
+		DateLayout:      "Jan 2, 2006",
+		DateTimeLayout:  "Jan 2, 2006 15:04 MST",
+		DocumentName:    "Adoption contract",
+		Generated:       "Generated",
+		Parties:         "Parties",
+		Organization:    "Shelter",
+		Adopter:         "Adopter",
+		Address:         "Address",
+		Email:           "Email",
+		Phone:           "Phone",
+		Animal:          "Animal",
+		Species:         "Species",
+		Breed:           "Breed",
+		Microchip:       "Microchip",
+		AdoptionDate:    "Adoption date",
+		AdoptionFee:     "Adoption fee",
+		Consents:        "Declarations",
+		Signature:       "Signature",
+		SignedBy:        "Signed by",
+		SignedAt:        "Signed at",
+		Method:          "Method",
+		SigningRecord:   "Signing record",
+		RecordNote:      "The contract was signed electronically through a personal link. The content hash identifies the text shown to the adopter; the shelter keeps the SHA-256 of this file to detect any later change.",
+		ContractID:      "Contract",
+		TemplateVersion: "Template version",
+		BuiltIn:         "built-in",
+		ContentHash:     "Content hash",
+		SignatureHash:   "Signature image hash",
+		Event:           "Event",
+		Date:            "Date",
+		IPAddress:       "IP address",
+		Details:         "Details",
+
+		LinkSubject: "Your adoption contract for {{animal_name}}",
+		LinkBody:    "Hello {{adopter_name}},\n\nyour adoption contract for {{animal_name}} is ready. Please read it and sign it online:\n\n{{url}}\n\nThe link is personal and valid until {{expires}}.",
+		LinkSMS:     "Your adoption contract for {{animal_name}} is ready to sign: {{url}}",
+
+		Values: map[string]string{
+			string(entities.SignatureMethodTyped): "typed name",
+			string(entities.SignatureMethodDrawn): "drawn signature",
+		},
+
+		Template: entities.ContractTemplate{
+			Language: "en",
+			Title:    "Adoption contract",
+			Sections: []entities.ContractSection{
+				{
+					Heading: "Subject of the contract",
+					Body:    "{{organization}} transfers to {{adopter_name}} the care of {{animal_name}} ({{species}}, microchip {{microchip_number}}). The adoption takes effect on {{adoption_date}}.",
+				},
+				{
+					Heading: "Care of the animal",
+					Body:    "The adopter undertakes to provide the animal with suitable food, water, shelter, exercise and company, and never to keep it permanently chained or confined. The animal may not be used for breeding, fighting or any commercial purpose.",
+				},
+				{
+					Heading: "Adoption fee",
+					Body:    "The adopter pays an adoption fee of {{adoption_fee}}, which covers part of the cost of the animal's care at the shelter. The fee is not a sale price.",
+				},
+				{
+					Heading: "Transfer and return",
+					Body:    "The adopter may not sell, give away or abandon the animal. If the adopter can no longer keep it, the animal is returned to {{organization}}.",
+				},
+				{
+					Heading: "Final provisions",
+					Body:    "{{organization}} may withdraw from the contract and take the animal back if its terms are broken. The contract is signed electronically on {{contract_date}}.",
+				},
+			},
+			Consents: []entities.ContractConsent{
+				{Key: entities.ContractConsentReturnPolicy, Text: "I will return {{animal_name}} to the shelter instead of selling, giving away or abandoning the animal.", Required: true},
+				{Key: entities.ContractConsentSpayNeuter, Text: "I will have {{animal_name}} spayed or neutered within the time agreed with the shelter and send proof of the procedure.", Required: true},
+				{Key: entities.ContractConsentMedicalCare, Text: "I will provide {{animal_name}} with veterinary care, vaccinations and parasite prevention.", Required: true},
+				{Key: entities.ContractConsentFollowUp, Text: "I agree to follow-up contact and visits by the shelter after the adoption.", Required: true},
+			},
+		},
+	},
+	"pl": {
+		DateLayout:      "02.01.2006",
+		DateTimeLayout:  "02.01.2006 15:04 MST",
+		DecimalComma:    true,
+		DocumentName:    "Umowa adopcyjna",
+		Generated:       "Wygenerowano",
+		Parties:         "Strony umowy",
+		Organization:    "Schronisko",
+		Adopter:         "Adoptujący",
+		Address:         "Adres",
+		Email:           "E-mail",
+		Phone:           "Telefon",
+		Animal:          "Zwierzę",
+		Species:         "Gatunek",
+		Breed:           "Rasa",
+		Microchip:       "Mikroczip",
+		AdoptionDate:    "Data adopcji",
+		AdoptionFee:     "Opłata adopcyjna",
+		Consents:        "Oświadczenia",
+		Signature:       "Podpis",
+		SignedBy:        "Podpisał(a)",
+		SignedAt:        "Data podpisu",
+		Method:          "Sposób",
+		SigningRecord:   "Przebieg podpisania",
+		RecordNote:      "Umowę podpisano elektronicznie przez osobisty link. Skrót treści identyfikuje tekst przedstawiony adoptującemu; schronisko przechowuje skrót SHA-256 tego pliku, aby wykryć jego późniejszą zmianę.",
+		ContractID:      "Umowa",
+		TemplateVersion: "Wersja wzoru",
+		BuiltIn:         "wbudowany",
+		ContentHash:     "Skrót treści",
+		SignatureHash:   "Skrót obrazu podpisu",
+		Event:           "Zdarzenie",
+		Date:            "Data",
+		IPAddress:       "Adres IP",
+		Details:         "Szczegóły",
+
+		LinkSubject: "Umowa adopcyjna – {{animal_name}}",
+		LinkBody:    "Dzień dobry {{adopter_name}},\n\numowa adopcyjna dla {{animal_name}} jest gotowa. Prosimy o zapoznanie się z nią i podpisanie online:\n\n{{url}}\n\nLink jest osobisty i ważny do {{expires}}.",
+		LinkSMS:     "Umowa adopcyjna dla {{animal_name}} czeka na podpis: {{url}}",
+
+		Values: map[string]string{
+			string(entities.SignatureMethodTyped): "wpisane imię i nazwisko",
+			string(entities.SignatureMethodDrawn): "podpis odręczny",
+
+			string(entities.ContractEventCreated): "utworzono",
+			string(entities.ContractEventSent):    "wysłano",
+			string(entities.ContractEventViewed):  "otwarto",
+			string(entities.ContractEventSigned):  "podpisano",
+			string(entities.ContractEventVoided):  "unieważniono",
+
+			"dog":    "pies",
+			"cat":    "kot",
+			"rabbit": "królik",
+			"ferret": "fretka",
+		},
+
+		Template: entities.ContractTemplate{
+			Language: "pl",
+			Title:    "Umowa adopcyjna",
+			Sections: []entities.ContractSection{
+				{
+					Heading: "Przedmiot umowy",
+					Body:    "{{organization}} przekazuje {{adopter_name}} opiekę nad zwierzęciem {{animal_name}} ({{species}}, mikroczip {{microchip_number}}). Adopcja następuje z dniem {{adoption_date}}.",
+				},
+				{
+					Heading: "Opieka nad zwierzęciem",
+					Body:    "Adoptujący zobowiązuje się zapewnić zwierzęciu odpowiednie pożywienie, wodę, schronienie, ruch i towarzystwo oraz nie trzymać go stale na uwięzi ani w zamknięciu. Zwierzę nie może być wykorzystywane do rozmnażania, walk ani w celach zarobkowych.",
+				},
+				{
+					Heading: "Opłata adopcyjna",
+					Body:    "Adoptujący wnosi opłatę adopcyjną w wysokości {{adoption_fee}}, która pokrywa część kosztów opieki nad zwierzęciem w schronisku. Opłata nie jest ceną sprzedaży.",
+				},
+				{
+					Heading: "Przekazanie i zwrot zwierzęcia",
+					Body:    "Adoptujący nie może sprzedać, oddać ani porzucić zwierzęcia. Jeśli nie może dalej się nim opiekować, zwraca je do schroniska {{organization}}.",
+				},
+				{
+					Heading: "Postanowienia końcowe",
+					Body:    "W razie naruszenia postanowień umowy {{organization}} może od niej odstąpić i odebrać zwierzę. Umowę podpisano elektronicznie w dniu {{contract_date}}.",
+				},
+			},
+			Consents: []entities.ContractConsent{
+				{Key: entities.ContractConsentReturnPolicy, Text: "Zwrócę {{animal_name}} do schroniska zamiast sprzedawać, oddawać lub porzucać zwierzę.", Required: true},
+				{Key: entities.ContractConsentSpayNeuter, Text: "Poddam {{animal_name}} sterylizacji lub kastracji w terminie uzgodnionym ze schroniskiem i przekażę potwierdzenie zabiegu.", Required: true},
+				{Key: entities.ContractConsentMedicalCare, Text: "Zapewnię {{animal_name}} opiekę weterynaryjną, szczepienia i profilaktykę przeciwpasożytniczą.", Required: true},
+				{Key: entities.ContractConsentFollowUp, Text: "Zgadzam się na kontakt i wizyty poadopcyjne przedstawicieli schroniska.", Required: true},
+			},
+		},
+	},
+}
+
+// labelsFor returns the labels of a language, falling back to English
+func labelsFor(language string) labels {
+	if l, ok := contractLabels[language]; ok {
+		return l
+	}
+	return contractLabels["en"]
+}
+
+// value translates a stored enum value, or prints it readably when there is no translation
+func (l labels) value(v string) string {
+	if v == "" {
+		return ""
+	}
+	if translated, ok := l.Values[v]; ok {
+		return translated
+	}
+	return strings.ReplaceAll(v, "_", " ")
+}
+
+// date prints a date in the language's format
+func (l labels) date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(l.DateLayout)
+}
+
+// dateTime prints a timestamp in UTC in the language's format
+func (l labels) dateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(l.DateTimeLayout)
+}
+
+// amount prints a fee with two decimals
+func (l labels) amount(value float64) string {
+	s := strconv.FormatFloat(value, 'f', 2, 64)
+	if l.DecimalComma {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
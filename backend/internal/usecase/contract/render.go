@@ -0,0 +1,134 @@
+package contract
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/pdf"
+)
+
+// renderPDF prints a signed contract: the text, the ticked consents, the signature
+// and a final page with the signing audit trail and the content hash
+func renderPDF(contract *entities.Contract, drawing image.Image) []byte {
+	l := labelsFor(contract.Language)
+	p := contract.Parties
+	signature := contract.Signature
+
+	doc := pdf.New(fmt.Sprintf("%s – %s", contract.Title, p.AnimalName))
+	doc.SetSubject(l.DocumentName)
+	doc.SetCreated(signature.SignedAt)
+	footer := fmt.Sprintf("%s %s · %s", l.ContractID, contract.ID.Hex(), shortHash(contract.ContentHash))
+	if p.Organization != "" {
+		doc.SetAuthor(p.Organization)
+		footer = p.Organization + " · " + footer
+	}
+	doc.SetFooter(footer)
+
+	doc.Title(contract.Title)
+
+	doc.Heading(l.Parties)
+	if p.Organization != "" {
+		doc.Field(l.Organization, p.Organization)
+	}
+	doc.Field(l.Adopter, p.AdopterName)
+	if p.AdopterAddress != "" {
+		doc.Field(l.Address, p.AdopterAddress)
+	}
+	if p.AdopterEmail != "" {
+		doc.Field(l.Email, p.AdopterEmail)
+	}
+	if p.AdopterPhone != "" {
+		doc.Field(l.Phone, p.AdopterPhone)
+	}
+	doc.Space(6)
+	doc.Field(l.Animal, p.AnimalName)
+	doc.Field(l.Species, strings.TrimSpace(strings.Join([]string{p.Species, p.Breed}, " ")))
+	if p.MicrochipNumber != "" {
+		doc.Field(l.Microchip, p.MicrochipNumber)
+	}
+	doc.Field(l.AdoptionDate, l.date(p.AdoptionDate))
+	doc.Field(l.AdoptionFee, l.amount(p.AdoptionFee))
+
+	for i, section := range contract.Sections {
+		doc.Heading(fmt.Sprintf("§ %d. %s", i+1, section.Heading))
+		doc.Text(section.Body)
+	}
+
+	if len(contract.Consents) > 0 {
+		doc.Heading(l.Consents)
+		for _, consent := range contract.Consents {
+			doc.Check(signature.HasConsent(consent.Key), consent.Text)
+		}
+	}
+
+	doc.Heading(l.Signature)
+	if drawing != nil {
+		doc.Image(drawing, 200, 60)
+	}
+	doc.Field(l.SignedBy, signature.Name)
+	doc.Field(l.SignedAt, l.dateTime(signature.SignedAt))
+	doc.Field(l.Method, l.value(string(signature.Method)))
+
+	doc.PageBreak()
+	doc.Heading(l.SigningRecord)
+	doc.Small(l.RecordNote)
+	doc.Field(l.ContractID, contract.ID.Hex())
+	version := l.BuiltIn
+	if contract.TemplateID != nil {
+		version = fmt.Sprintf("%s v%d (%s)", contract.Language, contract.TemplateVersion, contract.TemplateID.Hex())
+	}
+	doc.Field(l.TemplateVersion, version)
+	doc.Field(l.ContentHash, contract.ContentHash)
+	if signature.ImageHash != "" {
+		doc.Field(l.SignatureHash, signature.ImageHash)
+	}
+	doc.Space(6)
+
+	rows := make([][]string, 0, len(contract.Events))
+	for _, event := range contract.Events {
+		details := event.Details
+		if event.UserAgent != "" {
+			details = strings.TrimSpace(details + " " + event.UserAgent)
+		}
+		rows = append(rows, []string{l.dateTime(event.At), l.value(string(event.Action)), event.IPAddress, details})
+	}
+	doc.Table([]pdf.Column{
+		{Header: l.Date, Width: 2},
+		{Header: l.Event, Width: 1.3},
+		{Header: l.IPAddress, Width: 1.5},
+		{Header: l.Details, Width: 4},
+	}, rows)
+
+	return doc.Bytes()
+}
+
+// shortHash abbreviates a hash for the page footer
+func shortHash(hash string) string {
+	if len(hash) > 16 {
+		return hash[:16]
+	}
+	return hash
+}
+
+// fileNameLetters replaces the Polish letters in file names
+var fileNameLetters = strings.NewReplacer("ą", "a", "ć", "c", "ę", "e", "ł", "l", "ń", "n", "ó", "o", "ś", "s", "ź", "z", "ż", "z")
+
+// slug lowercases text and keeps only ASCII letters and digits, separated by hyphens
+func slug(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range fileNameLetters.Replace(strings.ToLower(text)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,383 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxSignatureBytes limits the size of a drawn signature image
+	maxSignatureBytes = 512 << 10
+	// maxSignaturePixels limits the width and height of a drawn signature image
+	maxSignaturePixels = 2000
+)
+
+// ContractView is what the adopter sees on the signing page
+type ContractView struct {
+	ID        primitive.ObjectID         `json:"id"`
+	Title     string                     `json:"title"`
+	Language  string                     `json:"language"`
+	Parties   entities.ContractParties   `json:"parties"`
+	Sections  []entities.ContractSection `json:"sections"`
+	Consents  []entities.ContractConsent `json:"consents"`
+	ExpiresAt time.Time                  `json:"expires_at"`
+}
+
+// SignContractRequest is the adopter's signature and ticked consents
+type SignContractRequest struct {
+	SignerName string                        `json:"signer_name" validate:"required,max=200"`
+	Method     entities.SignatureMethod      `json:"method" validate:"required,oneof=typed drawn"`
+	Signature  string                        `json:"signature_image,omitempty"` // PNG as base64 or a data URL; required for a drawn signature
+	Consents   []entities.ContractConsentKey `json:"consents"`
+}
+
+// SigningReceipt confirms a signature to the adopter
+type SigningReceipt struct {
+	ContractID   primitive.ObjectID `json:"contract_id"`
+	SignedAt     time.Time          `json:"signed_at"`
+	ContentHash  string             `json:"content_hash"`
+	DocumentHash string             `json:"document_hash"`
+}
+
+// Verification is the result of checking a signed contract for tampering
+type Verification struct {
+	ContractID    primitive.ObjectID      `json:"contract_id"`
+	Status        entities.ContractStatus `json:"status"`
+	ContentHash   string                  `json:"content_hash"`
+	ContentIntact bool                    `json:"content_intact"` // the stored text still matches its hash
+	DocumentID    *primitive.ObjectID     `json:"document_id,omitempty"`
+	DocumentHash  string                  `json:"document_hash,omitempty"`
+	StoredIntact  bool                    `json:"stored_intact"` // the stored PDF still matches the hash taken at signing
+	FileHash      string                  `json:"file_hash,omitempty"`
+	FileMatches   *bool                   `json:"file_matches,omitempty"` // a presented copy is the signed PDF
+	Valid         bool                    `json:"valid"`
+}
+
+// ViewContract opens the contract of a signing link and records the view in the audit trail
+func (uc *ContractUseCase) ViewContract(ctx context.Context, token string, client Client) (*ContractView, error) {
+	contract, err := uc.contractRepo.FindByTokenHash(ctx, entities.HashToken(token))
+	if err != nil {
+		return nil, linkError(err)
+	}
+	if contract.IsExpired(time.Now()) {
+		return nil, errLinkExpired
+	}
+
+	// Reloading the page does not add an entry for every refresh
+	var last entities.ContractEvent
+	if len(contract.Events) > 0 {
+		last = contract.Events[len(contract.Events)-1]
+	}
+	if last.Action != entities.ContractEventViewed || last.IPAddress != client.IPAddress || last.UserAgent != client.UserAgent {
+		// Only the entry is written so a signature saved meanwhile is not overwritten
+		err := uc.contractRepo.AddEvent(ctx, contract.ID, entities.ContractEvent{
+			Action:    entities.ContractEventViewed,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ContractView{
+		ID:        contract.ID,
+		Title:     contract.Title,
+		Language:  contract.Language,
+		Parties:   contract.Parties,
+		Sections:  contract.Sections,
+		Consents:  contract.Consents,
+		ExpiresAt: contract.TokenExpiresAt,
+	}, nil
+}
+
+// SignContract records the adopter's signature through a signing link. The signed PDF, with the
+// signature and the signing audit trail, is stored as a document and the adoption is updated
+// with the contract and the consents given.
+func (uc *ContractUseCase) SignContract(ctx context.Context, token string, req *SignContractRequest, client Client) (*SigningReceipt, error) {
+	if uc.storageService == nil {
+		return nil, errors.NewInternalServer("file storage is not configured")
+	}
+
+	tokenHash := entities.HashToken(token)
+	contract, err := uc.contractRepo.FindByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return nil, linkError(err)
+	}
+	now := time.Now()
+	if contract.IsExpired(now) {
+		return nil, errLinkExpired
+	}
+	if !contract.IsIntact() {
+		return nil, errors.NewConflict("the contract text no longer matches the generated contract; please ask the shelter for a new link")
+	}
+
+	consents, err := checkConsents(contract, req.Consents)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := entities.ContractSignature{
+		Method:    req.Method,
+		Name:      strings.TrimSpace(req.SignerName),
+		Consents:  consents,
+		SignedAt:  now,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+	var drawing image.Image
+	if req.Method == entities.SignatureMethodDrawn {
+		content, img, err := decodeSignature(req.Signature)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(content)
+		signature.ImageHash = hex.EncodeToString(sum[:])
+		drawing = img
+	}
+
+	adoption, err := uc.adoptionRepo.FindByID(ctx, contract.AdoptionID)
+	if err != nil {
+		return nil, err
+	}
+	if adoption.Status == entities.AdoptionStatusReturned || adoption.Status == entities.AdoptionStatusCancelled {
+		return nil, errors.NewBadRequest("the adoption is no longer active")
+	}
+
+	contract.Sign(signature)
+	content := renderPDF(contract, drawing)
+
+	document, err := uc.storeDocument(ctx, contract, content)
+	if err != nil {
+		return nil, err
+	}
+	contract.DocumentID = &document.ID
+	contract.DocumentHash = document.Checksum
+	// A contract signed, voided or renewed since it was read keeps its state
+	saved, err := uc.contractRepo.UpdateIfPending(ctx, contract, tokenHash)
+	if err != nil || !saved {
+		uc.removeDocument(ctx, document)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.NewConflict("the contract was already signed or its link is no longer valid")
+	}
+
+	signedDate := signature.SignedAt
+	adoption.Contract.ContractURL = document.FileURL
+	adoption.Contract.SignedDate = &signedDate
+	adoption.Contract.SignedBy = signature.Name
+	adoption.Contract.ContractID = &contract.ID
+	adoption.Contract.DocumentID = &document.ID
+	adoption.Contract.Terms = nil
+	for _, consent := range contract.Consents {
+		if signature.HasConsent(consent.Key) {
+			adoption.Contract.Terms = append(adoption.Contract.Terms, consent.Text)
+		}
+	}
+	adoption.AgreesToReturnPolicy = signature.HasConsent(entities.ContractConsentReturnPolicy)
+	adoption.AgreesToMedicalCare = signature.HasConsent(entities.ContractConsentMedicalCare)
+	adoption.AgreesToFollowUp = signature.HasConsent(entities.ContractConsentFollowUp)
+	// An animal that is already sterilized gets no spay/neuter consent; keep what was recorded
+	if offered(contract, entities.ContractConsentSpayNeuter) {
+		adoption.AgreesToSpayNeuter = signature.HasConsent(entities.ContractConsentSpayNeuter)
+	}
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		return nil, err
+	}
+
+	// Create audit log; the adopter has no user account, so the entry is on behalf of the staff member who issued the link
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(contract.CreatedBy, entities.ActionUpdate, "contract", contract.Title, "adoption contract signed by "+signature.Name).
+			WithEntityID(contract.ID).
+			WithChanges(map[string]interface{}{
+				"adoption_id":   contract.AdoptionID.Hex(),
+				"document_id":   document.ID.Hex(),
+				"document_hash": contract.DocumentHash,
+				"method":        string(signature.Method),
+				"ip_address":    signature.IPAddress,
+			}))
+
+	return &SigningReceipt{
+		ContractID:   contract.ID,
+		SignedAt:     signature.SignedAt,
+		ContentHash:  contract.ContentHash,
+		DocumentHash: contract.DocumentHash,
+	}, nil
+}
+
+// VerifyContract checks that a signed contract and its stored PDF are unchanged. When a
+// copy of the PDF is presented, it is also compared with the signed file.
+func (uc *ContractUseCase) VerifyContract(ctx context.Context, id primitive.ObjectID, file io.Reader) (*Verification, error) {
+	contract, err := uc.GetContract(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contract.DocumentID == nil {
+		return nil, errors.NewBadRequest("the contract has not been signed")
+	}
+
+	result := &Verification{
+		ContractID:    contract.ID,
+		Status:        contract.Status,
+		ContentHash:   contract.ContentHash,
+		ContentIntact: contract.IsIntact(),
+		DocumentID:    contract.DocumentID,
+		DocumentHash:  contract.DocumentHash,
+	}
+
+	if document, err := uc.documentRepo.FindByID(ctx, *contract.DocumentID); err == nil && uc.storageService != nil {
+		if stored, _, err := uc.storageService.Open(ctx, document.FileURL); err == nil {
+			sum, err := hashReader(stored)
+			stored.Close()
+			result.StoredIntact = err == nil && sum == contract.DocumentHash && sum == document.Checksum
+		}
+	}
+
+	if file != nil {
+		sum, err := hashReader(file)
+		if err != nil {
+			return nil, errors.Wrap(err, 500, "failed to read the presented file")
+		}
+		matches := sum == contract.DocumentHash
+		result.FileHash = sum
+		result.FileMatches = &matches
+	}
+
+	result.Valid = contract.Status == entities.ContractStatusSigned && result.ContentIntact && result.StoredIntact &&
+		(result.FileMatches == nil || *result.FileMatches)
+	return result, nil
+}
+
+// storeDocument uploads the signed PDF and records it as a contract document of the adoption
+func (uc *ContractUseCase) storeDocument(ctx context.Context, contract *entities.Contract, content []byte) (*entities.Document, error) {
+	l := labelsFor(contract.Language)
+	size := int64(len(content))
+	fileName := slug(l.DocumentName+" "+contract.Parties.AnimalName) + "-" + contract.Signature.SignedAt.Format("2006-01-02") + ".pdf"
+
+	document := entities.NewDocument(l.DocumentName+" – "+contract.Parties.AnimalName, fileName, size, "application/pdf", entities.DocumentTypeContract, contract.CreatedBy)
+	document.RelatedEntity = "adoption"
+	document.RelatedEntityID = &contract.AdoptionID
+	document.IsConfidential = true
+	document.Tags = []string{ContractTag}
+	document.Metadata = map[string]string{
+		"generator":        "adoption_contract",
+		"contract_id":      contract.ID.Hex(),
+		"content_hash":     contract.ContentHash,
+		"language":         contract.Language,
+		"template_version": strconv.Itoa(contract.TemplateVersion),
+		"signed_by":        contract.Signature.Name,
+	}
+
+	sum := sha256.Sum256(content)
+	document.Checksum = hex.EncodeToString(sum[:])
+
+	url, err := uc.storageService.Upload(ctx, bytes.NewReader(content), size, "documents/"+document.ID.Hex(), primitive.NewObjectID().Hex()+".pdf", "application/pdf")
+	if err != nil {
+		return nil, err
+	}
+	document.FileURL = url
+
+	if err := uc.documentRepo.Create(ctx, document); err != nil {
+		_ = uc.storageService.DeleteFile(ctx, url)
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// removeDocument deletes the stored PDF of a signature that was not saved
+func (uc *ContractUseCase) removeDocument(ctx context.Context, document *entities.Document) {
+	if err := uc.documentRepo.Delete(ctx, document.ID); err != nil {
+		log.Error().Err(err).Str("document_id", document.ID.Hex()).Msg("failed to remove the document of an unsaved contract signature")
+	}
+	_ = uc.storageService.DeleteFile(ctx, document.FileURL)
+}
+
+// checkConsents validates the ticked consents against the contract and returns them in contract order
+func checkConsents(contract *entities.Contract, ticked []entities.ContractConsentKey) ([]entities.ContractConsentKey, error) {
+	given := make(map[entities.ContractConsentKey]bool, len(ticked))
+	for _, key := range ticked {
+		if !offered(contract, key) {
+			return nil, errors.NewBadRequest("unknown consent: " + string(key))
+		}
+		given[key] = true
+	}
+
+	consents := []entities.ContractConsentKey{}
+	for _, consent := range contract.Consents {
+		if given[consent.Key] {
+			consents = append(consents, consent.Key)
+		} else if consent.Required {
+			return nil, errors.NewBadRequest("consent " + string(consent.Key) + " is required to sign the contract")
+		}
+	}
+	return consents, nil
+}
+
+// offered checks if the contract asks for a consent
+func offered(contract *entities.Contract, key entities.ContractConsentKey) bool {
+	for _, consent := range contract.Consents {
+		if consent.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSignature decodes a drawn signature sent as base64 PNG, optionally as a data URL
+func decodeSignature(encoded string) ([]byte, image.Image, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil, errors.NewBadRequest("a drawn signature requires the signature image")
+	}
+	if strings.HasPrefix(encoded, "data:") {
+		comma := strings.Index(encoded, ",")
+		if comma < 0 || encoded[:comma] != "data:image/png;base64" {
+			return nil, nil, errors.NewBadRequest("the signature image must be a PNG")
+		}
+		encoded = encoded[comma+1:]
+	}
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxSignatureBytes {
+		return nil, nil, errors.NewBadRequest("the signature image is too large")
+	}
+
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, errors.NewBadRequest("the signature image is not valid base64")
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, errors.NewBadRequest("the signature image must be a PNG")
+	}
+	if config.Width > maxSignaturePixels || config.Height > maxSignaturePixels {
+		return nil, nil, errors.NewBadRequest("the signature image is too large")
+	}
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, errors.NewBadRequest("the signature image must be a PNG")
+	}
+	return content, img, nil
+}
+
+func hashReader(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
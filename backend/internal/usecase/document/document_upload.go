@@ -0,0 +1,282 @@
+package document
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// allowedMimeTypes maps the accepted document content types to their file extensions
+var allowedMimeTypes = map[string][]string{
+	"application/pdf":          {".pdf"},
+	"image/jpeg":               {".jpg", ".jpeg"},
+	"image/png":                {".png"},
+	"image/gif":                {".gif"},
+	"image/webp":               {".webp"},
+	"text/plain":               {".txt"},
+	"text/csv":                 {".csv"},
+	"application/msword":       {".doc"},
+	"application/vnd.ms-excel": {".xls"},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": {".docx"},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       {".xlsx"},
+	"application/vnd.oasis.opendocument.text":                                 {".odt"},
+	"application/vnd.oasis.opendocument.spreadsheet":                          {".ods"},
+}
+
+// UploadDocumentRequest holds the metadata sent with a document upload
+type UploadDocumentRequest struct {
+	Title           string                `form:"title" binding:"required"`
+	Description     string                `form:"description"`
+	Type            entities.DocumentType `form:"type" binding:"required"`
+	RelatedEntity   string                `form:"related_entity"`
+	RelatedEntityID string                `form:"related_entity_id"`
+	IsPublic        bool                  `form:"is_public"`
+	IsConfidential  bool                  `form:"is_confidential"`
+	Tags            []string              `form:"tags"`
+	ExpiresAt       *time.Time            `form:"expires_at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// DocumentFile is an opened document file ready for streaming
+type DocumentFile struct {
+	Document *entities.Document
+	Content  io.ReadSeekCloser
+	Info     *storage.ObjectInfo
+}
+
+// UploadDocument stores an uploaded file and creates its document record
+func (uc *DocumentUseCase) UploadDocument(ctx context.Context, req *UploadDocumentRequest, file *multipart.FileHeader, userID primitive.ObjectID) (*entities.Document, error) {
+	if file == nil {
+		return nil, errors.NewBadRequest("File is required")
+	}
+
+	document := entities.NewDocument(req.Title, file.Filename, file.Size, "", req.Type, userID)
+	document.Description = req.Description
+	document.RelatedEntity = req.RelatedEntity
+	document.IsPublic = req.IsPublic
+	document.IsConfidential = req.IsConfidential
+	document.ExpiresAt = req.ExpiresAt
+	if len(req.Tags) > 0 {
+		document.Tags = req.Tags
+	}
+
+	if req.RelatedEntityID != "" {
+		relatedID, err := primitive.ObjectIDFromHex(req.RelatedEntityID)
+		if err != nil {
+			return nil, errors.NewBadRequest("Invalid related entity ID")
+		}
+		document.RelatedEntityID = &relatedID
+	}
+
+	if err := uc.storeFile(ctx, document, file, userID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.documentRepo.Create(ctx, document); err != nil {
+		_ = uc.storageService.DeleteFile(ctx, document.FileURL)
+		return nil, err
+	}
+
+	// Create audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionCreate, "document", document.Title, "uploaded document").
+			WithEntityID(document.ID))
+
+	return document, nil
+}
+
+// OpenDocument checks access and opens the document file for streaming.
+// recordDownload is false for follow-up range requests of the same download.
+func (uc *DocumentUseCase) OpenDocument(ctx context.Context, id, userID primitive.ObjectID, recordDownload bool) (*DocumentFile, error) {
+	document, err := uc.findDownloadable(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.storageService == nil {
+		return nil, errors.NewInternalServer("file storage is not configured")
+	}
+
+	content, info, err := uc.storageService.Open(ctx, document.FileURL)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewNotFound("Document file not found")
+		}
+		return nil, err
+	}
+
+	if recordDownload {
+		if err := uc.recordDownload(ctx, document, userID); err != nil {
+			content.Close()
+			return nil, err
+		}
+	}
+
+	return &DocumentFile{Document: document, Content: content, Info: info}, nil
+}
+
+// storeFile validates, scans and stores an uploaded file, filling in the file fields of the document.
+// Infected files are rejected before they reach the storage.
+func (uc *DocumentUseCase) storeFile(ctx context.Context, document *entities.Document, file *multipart.FileHeader, userID primitive.ObjectID) error {
+	if uc.storageService == nil {
+		return errors.NewInternalServer("file storage is not configured")
+	}
+
+	// The limit from the system settings takes precedence over the storage default
+	limit := uc.maxUploadSize(ctx)
+	if (limit > 0 && file.Size > limit) || (limit == 0 && uc.storageService.ExceedsMaxFileSize(file.Size)) {
+		return errors.NewBadRequest("file size exceeds maximum allowed size")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to open uploaded file")
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(src, head)
+	mimeType, err := detectMimeType(head[:n], file.Filename)
+	if err != nil {
+		return err
+	}
+
+	// Nothing would ever finish a pending scan without a scanner
+	document.ScanStatus = entities.DocumentScanStatusSkipped
+	if uc.scanner != nil {
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return errors.Wrap(err, 500, "failed to read uploaded file")
+		}
+
+		result, err := uc.scanner.Scan(ctx, src)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		document.ScannedBy = uc.scanner.Name()
+		document.ScannedAt = &now
+
+		if !result.Clean {
+			_ = uc.auditLogRepo.Create(ctx,
+				entities.NewAuditLog(userID, entities.ActionCreate, "document", document.Title, "upload rejected by malware scanner").
+					WithChanges(map[string]interface{}{
+						"file_name": file.Filename,
+						"signature": result.Signature,
+					}))
+			return errors.NewBadRequest("File was rejected by the malware scanner")
+		}
+
+		document.ScanStatus = entities.DocumentScanStatusClean
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, 500, "failed to read uploaded file")
+	}
+
+	hash := sha256.New()
+	filename := primitive.NewObjectID().Hex() + strings.ToLower(filepath.Ext(file.Filename))
+	url, err := uc.storageService.Upload(ctx, io.TeeReader(src, hash), file.Size, documentFolder(document.ID), filename, mimeType)
+	if err != nil {
+		return err
+	}
+
+	document.FileName = filepath.Base(file.Filename)
+	document.FileSize = file.Size
+	document.MimeType = mimeType
+	document.FileURL = url
+	document.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return nil
+}
+
+// maxUploadSize returns the document size limit from the system settings in bytes, or 0 when unset
+func (uc *DocumentUseCase) maxUploadSize(ctx context.Context) int64 {
+	if uc.settingsRepo == nil {
+		return 0
+	}
+
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil || settings.Limits.MaxFileUploadSizeMB <= 0 {
+		return 0
+	}
+
+	return settings.Limits.MaxFileUploadSizeMB << 20
+}
+
+// detectMimeType sniffs the content type and checks it against the whitelist and the file extension
+func detectMimeType(head []byte, filename string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+
+	mimeType := sniffed
+	switch sniffed {
+	case "application/zip":
+		// Office Open XML and OpenDocument files are zip archives
+		mimeType = mimeTypeForExtension(ext, "application/vnd.")
+	case "application/octet-stream":
+		// Legacy Office files use the OLE container format
+		if len(head) >= 8 && string(head[:8]) == "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1" {
+			mimeType = mimeTypeForExtension(ext, "application/")
+		}
+	case "text/plain":
+		if ext == ".csv" {
+			mimeType = "text/csv"
+		}
+	}
+
+	extensions, ok := allowedMimeTypes[mimeType]
+	if !ok {
+		return "", errors.NewBadRequest(fmt.Sprintf("unsupported file type %q. Allowed types: pdf, images, text, csv and office documents", sniffed))
+	}
+
+	for _, allowed := range extensions {
+		if ext == allowed {
+			return mimeType, nil
+		}
+	}
+
+	return "", errors.NewBadRequest("file extension does not match the file content")
+}
+
+// mimeTypeForExtension finds the whitelisted content type for an extension
+func mimeTypeForExtension(ext, prefix string) string {
+	for mimeType, extensions := range allowedMimeTypes {
+		if !strings.HasPrefix(mimeType, prefix) {
+			continue
+		}
+		for _, allowed := range extensions {
+			if ext == allowed {
+				return mimeType
+			}
+		}
+	}
+	return ""
+}
+
+// documentFolder returns the storage folder of a document version
+func documentFolder(id primitive.ObjectID) string {
+	return "documents/" + id.Hex()
+}
+
+// uploaded reports whether the document file was stored by an upload of this document,
+// rather than generated by another use case or linked from elsewhere
+func (uc *DocumentUseCase) uploaded(document *entities.Document) bool {
+	if uc.storageService == nil || document.ScanStatus == "" {
+		return false
+	}
+	key, err := uc.storageService.KeyFromURL(document.FileURL)
+	return err == nil && strings.HasPrefix(key, documentFolder(document.ID)+"/")
+}
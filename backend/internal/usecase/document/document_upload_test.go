@@ -0,0 +1,194 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"testing"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/scanner"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fileHeader builds a multipart file header as received by a handler
+func fileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["file"][0]
+}
+
+func newUploadUseCase(t *testing.T) (*DocumentUseCase, *mocks.DocumentRepository, *mocks.AuditLogRepository) {
+	mockDocRepo := new(mocks.DocumentRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	storageService := storage.NewStorageService(storage.NewLocalBackend(t.TempDir()), "/uploads", 1<<20)
+	return NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, storageService, scanner.NewFakeScanner(), 0), mockDocRepo, mockAuditLogRepo
+}
+
+func TestDocumentUseCase_UploadDocument(t *testing.T) {
+	useCase, mockDocRepo, mockAuditLogRepo := newUploadUseCase(t)
+	userID := primitive.NewObjectID()
+	content := []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n")
+
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil).Once()
+	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
+
+	req := &UploadDocumentRequest{Title: "Vet record", Type: entities.DocumentTypeMedical, IsConfidential: true}
+	doc, err := useCase.UploadDocument(context.Background(), req, fileHeader(t, "record.pdf", content), userID)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(content)
+	assert.Equal(t, "application/pdf", doc.MimeType)
+	assert.Equal(t, hex.EncodeToString(sum[:]), doc.Checksum)
+	assert.Equal(t, entities.DocumentScanStatusClean, doc.ScanStatus)
+	assert.Equal(t, "record.pdf", doc.FileName)
+
+	// The uploader can stream the file; other users cannot
+	mockDocRepo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	mockDocRepo.On("IncrementDownloadCount", mock.Anything, doc.ID).Return(nil).Once()
+
+	file, err := useCase.OpenDocument(context.Background(), doc.ID, userID, true)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(file.Content)
+	file.Content.Close()
+	assert.Equal(t, content, data)
+
+	_, err = useCase.OpenDocument(context.Background(), doc.ID, primitive.NewObjectID(), true)
+	assert.Error(t, err)
+
+	mockDocRepo.AssertExpectations(t)
+}
+
+func TestDocumentUseCase_UploadDocument_RejectsInfectedFile(t *testing.T) {
+	useCase, mockDocRepo, mockAuditLogRepo := newUploadUseCase(t)
+	eicar := []byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+
+	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil).Once()
+
+	req := &UploadDocumentRequest{Title: "Notes", Type: entities.DocumentTypeOther}
+	_, err := useCase.UploadDocument(context.Background(), req, fileHeader(t, "notes.txt", eicar), primitive.NewObjectID())
+	assert.Error(t, err)
+	mockDocRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDocumentUseCase_UploadDocument_RejectsDisallowedType(t *testing.T) {
+	useCase, _, _ := newUploadUseCase(t)
+
+	req := &UploadDocumentRequest{Title: "Page", Type: entities.DocumentTypeOther}
+	_, err := useCase.UploadDocument(context.Background(), req, fileHeader(t, "page.pdf", []byte("<html><script>alert(1)</script></html>")), primitive.NewObjectID())
+	assert.Error(t, err)
+}
+
+func TestDocumentUseCase_UploadDocument_WithoutScanner(t *testing.T) {
+	mockDocRepo := new(mocks.DocumentRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	storageService := storage.NewStorageService(storage.NewLocalBackend(t.TempDir()), "/uploads", 1<<20)
+	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, storageService, nil, 0)
+	userID := primitive.NewObjectID()
+
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
+
+	req := &UploadDocumentRequest{Title: "Notes", Type: entities.DocumentTypeOther}
+	doc, err := useCase.UploadDocument(context.Background(), req, fileHeader(t, "notes.txt", []byte("Feeding plan\n")), userID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DocumentScanStatusSkipped, doc.ScanStatus)
+	assert.Nil(t, doc.ScannedAt)
+
+	// Nothing will scan the file later, so it can be downloaded
+	mockDocRepo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+	mockDocRepo.On("IncrementDownloadCount", mock.Anything, doc.ID).Return(nil)
+	file, err := useCase.OpenDocument(context.Background(), doc.ID, userID, true)
+	require.NoError(t, err)
+	file.Content.Close()
+}
+
+func TestDocumentUseCase_CreateDocument_OnlyLinksExternalFiles(t *testing.T) {
+	useCase, mockDocRepo, mockAuditLogRepo := newUploadUseCase(t)
+	userID := primitive.NewObjectID()
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil).Once()
+	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
+
+	stored := &entities.Document{Title: "Contract", FileName: "contract.pdf", Type: entities.DocumentTypeContract,
+		FileURL: "/uploads/documents/" + primitive.NewObjectID().Hex() + "/contract.pdf"}
+	assert.Error(t, useCase.CreateDocument(context.Background(), stored, userID))
+
+	linked := &entities.Document{Title: "Leaflet", FileName: "leaflet.pdf", Type: entities.DocumentTypeOther,
+		FileURL: "https://example.org/leaflet.pdf", Checksum: "forged", ScanStatus: entities.DocumentScanStatusClean, FileSize: 10}
+	require.NoError(t, useCase.CreateDocument(context.Background(), linked, userID))
+	assert.Empty(t, linked.Checksum)
+	assert.Empty(t, linked.ScanStatus)
+	assert.Zero(t, linked.FileSize)
+	mockDocRepo.AssertExpectations(t)
+}
+
+func TestDocumentUseCase_DeleteDocument_KeepsFilesItDidNotUpload(t *testing.T) {
+	useCase, mockDocRepo, mockAuditLogRepo := newUploadUseCase(t)
+	userID := primitive.NewObjectID()
+	mockDocRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil)
+	mockDocRepo.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
+
+	req := &UploadDocumentRequest{Title: "Vet record", Type: entities.DocumentTypeMedical}
+	uploaded, err := useCase.UploadDocument(context.Background(), req, fileHeader(t, "record.pdf", []byte("%PDF-1.4\n")), userID)
+	require.NoError(t, err)
+
+	// A record of another user pointing at the uploaded file
+	other := primitive.NewObjectID()
+	pointer := &entities.Document{ID: primitive.NewObjectID(), UploadedBy: other, FileURL: uploaded.FileURL, ScanStatus: entities.DocumentScanStatusClean}
+	mockDocRepo.On("FindByID", mock.Anything, pointer.ID).Return(pointer, nil)
+	require.NoError(t, useCase.DeleteDocument(context.Background(), pointer.ID, other))
+
+	mockDocRepo.On("FindByID", mock.Anything, uploaded.ID).Return(uploaded, nil)
+	mockDocRepo.On("IncrementDownloadCount", mock.Anything, uploaded.ID).Return(nil)
+	file, err := useCase.OpenDocument(context.Background(), uploaded.ID, userID, true)
+	require.NoError(t, err, "the uploaded file is still there")
+	file.Content.Close()
+
+	require.NoError(t, useCase.DeleteDocument(context.Background(), uploaded.ID, userID))
+	_, err = useCase.OpenDocument(context.Background(), uploaded.ID, userID, true)
+	assert.Error(t, err, "deleting the uploading document removes its file")
+}
+
+func TestDocumentUseCase_OpenDocument_PendingScan(t *testing.T) {
+	useCase, mockDocRepo, _ := newUploadUseCase(t)
+	userID := primitive.NewObjectID()
+	doc := &entities.Document{ID: primitive.NewObjectID(), UploadedBy: userID, ScanStatus: entities.DocumentScanStatusPending}
+
+	mockDocRepo.On("FindByID", mock.Anything, doc.ID).Return(doc, nil)
+
+	_, err := useCase.OpenDocument(context.Background(), doc.ID, userID, true)
+	assert.Error(t, err)
+}
+
+func TestDetectMimeType(t *testing.T) {
+	mimeType, err := detectMimeType([]byte("name,species\nBurek,dog\n"), "animals.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", mimeType)
+
+	mimeType, err = detectMimeType([]byte("PK\x03\x04\x14\x00\x06\x00"), "contract.docx")
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", mimeType)
+
+	_, err = detectMimeType([]byte("PK\x03\x04\x14\x00\x06\x00"), "archive.zip")
+	assert.Error(t, err)
+
+	_, err = detectMimeType([]byte("%PDF-1.4"), "invoice.exe")
+	assert.Error(t, err)
+}
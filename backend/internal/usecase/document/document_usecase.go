@@ -2,29 +2,51 @@ package document
 
 import (
 	"context"
+	"mime/multipart"
 	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
 	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/scanner"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type DocumentUseCase struct {
-	documentRepo repositories.DocumentRepository
-	auditLogRepo repositories.AuditLogRepository
+	documentRepo   repositories.DocumentRepository
+	auditLogRepo   repositories.AuditLogRepository
+	settingsRepo   repositories.SettingsRepository
+	storageService *storage.StorageService
+	scanner        scanner.Scanner
+	presignExpiry  time.Duration
 }
 
 func NewDocumentUseCase(
 	documentRepo repositories.DocumentRepository,
 	auditLogRepo repositories.AuditLogRepository,
+	settingsRepo repositories.SettingsRepository,
+	storageService *storage.StorageService,
+	malwareScanner scanner.Scanner,
+	presignExpiry time.Duration,
 ) *DocumentUseCase {
 	return &DocumentUseCase{
-		documentRepo: documentRepo,
-		auditLogRepo: auditLogRepo,
+		documentRepo:   documentRepo,
+		auditLogRepo:   auditLogRepo,
+		settingsRepo:   settingsRepo,
+		storageService: storageService,
+		scanner:        malwareScanner,
+		presignExpiry:  presignExpiry,
 	}
 }
 
+// DocumentDownload is the result of a download request
+type DocumentDownload struct {
+	Document    *entities.Document `json:"document"`
+	DownloadURL string             `json:"download_url,omitempty"` // Empty when the file must be streamed through the API
+	ExpiresAt   *time.Time         `json:"expires_at,omitempty"`   // Set when the URL is pre-signed
+}
+
 // CreateDocument creates a new document
 func (uc *DocumentUseCase) CreateDocument(ctx context.Context, document *entities.Document, userID primitive.ObjectID) error {
 	// Validate required fields
@@ -44,6 +66,22 @@ func (uc *DocumentUseCase) CreateDocument(ctx context.Context, document *entitie
 		return errors.NewBadRequest("File URL is required")
 	}
 
+	// Records created here point at files stored elsewhere; files in our storage are only
+	// reachable through the document that uploaded them
+	if uc.storageService != nil {
+		if _, err := uc.storageService.KeyFromURL(document.FileURL); err == nil {
+			return errors.NewBadRequest("Upload files with POST /documents/upload")
+		}
+	}
+
+	// The file was neither scanned nor measured by us
+	document.FileSize = 0
+	document.MimeType = ""
+	document.Checksum = ""
+	document.ScanStatus = ""
+	document.ScanSignature = ""
+	document.ScannedBy = ""
+	document.ScannedAt = nil
 	document.UploadedBy = userID
 
 	if err := uc.documentRepo.Create(ctx, document); err != nil {
@@ -147,6 +185,19 @@ func (uc *DocumentUseCase) UpdateDocument(ctx context.Context, document *entitie
 	document.UploadedBy = existing.UploadedBy
 	document.UploadedAt = existing.UploadedAt
 
+	// File information only changes through uploads and new versions
+	document.FileName = existing.FileName
+	document.FileSize = existing.FileSize
+	document.MimeType = existing.MimeType
+	document.FileURL = existing.FileURL
+	document.Checksum = existing.Checksum
+	document.ScanStatus = existing.ScanStatus
+	document.ScanSignature = existing.ScanSignature
+	document.ScannedBy = existing.ScannedBy
+	document.ScannedAt = existing.ScannedAt
+	document.Version = existing.Version
+	document.PreviousVersion = existing.PreviousVersion
+
 	if err := uc.documentRepo.Update(ctx, document); err != nil {
 		return err
 	}
@@ -176,6 +227,11 @@ func (uc *DocumentUseCase) DeleteDocument(ctx context.Context, id primitive.Obje
 		return err
 	}
 
+	// Remove the uploaded file; other versions keep their own files
+	if uc.uploaded(document) {
+		_ = uc.storageService.DeleteFile(ctx, document.FileURL)
+	}
+
 	// Create audit log
 	_ = uc.auditLogRepo.Create(ctx,
 		entities.NewAuditLog(userID, entities.ActionDelete, "document", document.Title, "").
@@ -288,39 +344,104 @@ func (uc *DocumentUseCase) GetDocumentStatistics(ctx context.Context) (*reposito
 	return uc.documentRepo.GetDocumentStatistics(ctx)
 }
 
-// DownloadDocument records a download and increments the counter
-func (uc *DocumentUseCase) DownloadDocument(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) (*entities.Document, error) {
-	document, err := uc.documentRepo.FindByID(ctx, id)
+// DownloadDocument records a download, increments the counter and returns the download URL.
+// Private and confidential documents get a short-lived pre-signed URL instead of the stored one;
+// when the storage cannot pre-sign, the URL is left empty and the file is streamed through the API.
+func (uc *DocumentUseCase) DownloadDocument(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) (*DocumentDownload, error) {
+	document, err := uc.findDownloadable(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check access permissions
-	if !document.HasAccess(userID) {
-		return nil, errors.NewForbidden("You don't have access to this document")
+	download, err := uc.downloadURL(ctx, document)
+	if err != nil {
+		return nil, err
 	}
 
-	// Increment download count
-	if err := uc.documentRepo.IncrementDownloadCount(ctx, id); err != nil {
+	// Streamed downloads are counted when the file is fetched
+	if download.DownloadURL == "" {
+		return download, nil
+	}
+
+	if err := uc.recordDownload(ctx, document, userID); err != nil {
 		return nil, err
 	}
 
-	// Refresh document to get updated count
-	document, err = uc.documentRepo.FindByID(ctx, id)
+	return download, nil
+}
+
+// findDownloadable loads a document and checks the user may download its file
+func (uc *DocumentUseCase) findDownloadable(ctx context.Context, id, userID primitive.ObjectID) (*entities.Document, error) {
+	document, err := uc.documentRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Check access permissions
+	if !document.CanDownload(userID) {
+		return nil, errors.NewForbidden("You don't have access to this document")
+	}
+
+	switch {
+	case document.ScanStatus == entities.DocumentScanStatusInfected:
+		return nil, errors.NewForbidden("This file was flagged by the malware scanner")
+	case !document.IsAvailable():
+		return nil, errors.NewConflict("This file is not available until the malware scan completes")
+	}
+
+	return document, nil
+}
+
+// recordDownload increments the download counter and writes the audit log
+func (uc *DocumentUseCase) recordDownload(ctx context.Context, document *entities.Document, userID primitive.ObjectID) error {
+	if err := uc.documentRepo.IncrementDownloadCount(ctx, document.ID); err != nil {
+		return err
+	}
+	document.IncrementDownloadCount()
+
 	// Create audit log
 	_ = uc.auditLogRepo.Create(ctx,
 		entities.NewAuditLog(userID, entities.ActionView, "document", document.Title, "downloaded document").
-			WithEntityID(id))
+			WithEntityID(document.ID))
 
-	return document, nil
+	return nil
 }
 
-// CreateNewVersion creates a new version of a document
-func (uc *DocumentUseCase) CreateNewVersion(ctx context.Context, documentID primitive.ObjectID, filePath string, fileSize int64, userID primitive.ObjectID) (*entities.Document, error) {
+// downloadURL resolves the URL a document is downloaded from
+func (uc *DocumentUseCase) downloadURL(ctx context.Context, document *entities.Document) (*DocumentDownload, error) {
+	download := &DocumentDownload{Document: document}
+
+	if document.IsPublic && !document.IsConfidential {
+		download.DownloadURL = document.FileURL
+		return download, nil
+	}
+
+	if uc.storageService == nil {
+		return download, nil
+	}
+
+	if _, err := uc.storageService.KeyFromURL(document.FileURL); err != nil {
+		// Files outside the storage (external links) are returned as they are
+		download.DownloadURL = document.FileURL
+		return download, nil
+	}
+
+	url, err := uc.storageService.PresignedURL(ctx, document.FileURL, uc.presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if url != document.FileURL {
+		expiresAt := time.Now().Add(uc.presignExpiry)
+		download.DownloadURL = url
+		download.ExpiresAt = &expiresAt
+	}
+
+	return download, nil
+}
+
+// CreateNewVersion uploads a file as a new version of a document
+func (uc *DocumentUseCase) CreateNewVersion(ctx context.Context, documentID primitive.ObjectID, file *multipart.FileHeader, userID primitive.ObjectID) (*entities.Document, error) {
 	// Get the existing document
 	existingDoc, err := uc.documentRepo.FindByID(ctx, documentID)
 	if err != nil {
@@ -332,18 +453,19 @@ func (uc *DocumentUseCase) CreateNewVersion(ctx context.Context, documentID prim
 		return nil, errors.NewForbidden("You don't have permission to update this document")
 	}
 
-	if filePath == "" {
-		return nil, errors.NewBadRequest("File path is required")
+	if file == nil {
+		return nil, errors.NewBadRequest("File is required")
 	}
 
-	// Derive MIME type and filename from the path
-	fileName := filePath
-	mimeType := "application/octet-stream"
-
 	// Create new version
-	newVersion := existingDoc.CreateNewVersion(fileName, fileSize, mimeType, filePath, userID)
+	newVersion := existingDoc.CreateNewVersion(file.Filename, file.Size, "", "", userID)
+
+	if err := uc.storeFile(ctx, newVersion, file, userID); err != nil {
+		return nil, err
+	}
 
 	if err := uc.documentRepo.Create(ctx, newVersion); err != nil {
+		_ = uc.storageService.DeleteFile(ctx, newVersion.FileURL)
 		return nil, err
 	}
 
@@ -16,7 +16,7 @@ import (
 func TestDocumentUseCase_ArchiveDocument(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo)
+	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, nil, nil, 0)
 
 	docID := primitive.NewObjectID()
 	uploaderID := primitive.NewObjectID()
@@ -54,7 +54,7 @@ func TestDocumentUseCase_ArchiveDocument(t *testing.T) {
 func TestDocumentUseCase_UnarchiveDocument(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo)
+	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, nil, nil, 0)
 
 	docID := primitive.NewObjectID()
 	uploaderID := primitive.NewObjectID()
@@ -95,7 +95,7 @@ func TestDocumentUseCase_UnarchiveDocument(t *testing.T) {
 func TestDocumentUseCase_SearchDocuments(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo)
+	useCase := NewDocumentUseCase(mockDocRepo, mockAuditLogRepo, nil, nil, nil, 0)
 
 	userID := primitive.NewObjectID()
 	docs := []*entities.Document{
@@ -118,7 +118,7 @@ func TestDocumentUseCase_SearchDocuments(t *testing.T) {
 
 func TestDocumentUseCase_GetDocumentsByRelatedEntity(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
-	useCase := NewDocumentUseCase(mockDocRepo, nil)
+	useCase := NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 
 	userID := primitive.NewObjectID()
 	docs := []*entities.Document{
@@ -138,7 +138,7 @@ func TestDocumentUseCase_GetDocumentsByRelatedEntity(t *testing.T) {
 
 func TestDocumentUseCase_GetDocumentsByType(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
-	useCase := NewDocumentUseCase(mockDocRepo, nil)
+	useCase := NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 
 	userID := primitive.NewObjectID()
 	docs := []*entities.Document{
@@ -158,7 +158,7 @@ func TestDocumentUseCase_GetDocumentsByType(t *testing.T) {
 
 func TestDocumentUseCase_GetDocumentsByCategory(t *testing.T) {
 	mockDocRepo := new(mocks.DocumentRepository)
-	useCase := NewDocumentUseCase(mockDocRepo, nil)
+	useCase := NewDocumentUseCase(mockDocRepo, nil, nil, nil, nil, 0)
 
 	userID := primitive.NewObjectID()
 	docs := []*entities.Document{
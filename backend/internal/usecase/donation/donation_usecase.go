@@ -72,6 +72,11 @@ func (uc *DonationUseCase) CreateDonation(ctx context.Context, donation *entitie
 		donation.CampaignName = campaign.Name.English // Use English name for caching
 	}
 
+	// Donations made at an event count towards its outcomes
+	if donation.EventID != nil && donation.Source == "" {
+		donation.Source = "event"
+	}
+
 	// Calculate net amount
 	donation.CalculateNetAmount()
 
@@ -2,6 +2,8 @@ package event
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
@@ -10,12 +12,32 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Messenger sends communications to attendees
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// Uploader stores the calendar invites attached to event emails
+type Uploader interface {
+	Upload(ctx context.Context, r io.Reader, size int64, folder, filename, contentType string) (string, error)
+}
+
 // EventUseCase handles event-related business logic
 type EventUseCase struct {
-	eventRepo      repositories.EventRepository
-	attendanceRepo repositories.EventAttendanceRepository
-	volunteerRepo  repositories.VolunteerRepository
-	auditLogRepo   repositories.AuditLogRepository
+	eventRepo       repositories.EventRepository
+	attendanceRepo  repositories.EventAttendanceRepository
+	volunteerRepo   repositories.VolunteerRepository
+	auditLogRepo    repositories.AuditLogRepository
+	animalRepo      repositories.AnimalRepository
+	quarantines     QuarantineChecker
+	messenger       Messenger
+	uploader        Uploader
+	reminderOffsets []time.Duration // reminders go out this long before an event starts
+}
+
+// QuarantineChecker looks up open quarantines; animals in one stay in isolation
+type QuarantineChecker interface {
+	HasActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (bool, error)
 }
 
 // NewEventUseCase creates a new event use case
@@ -24,12 +46,22 @@ func NewEventUseCase(
 	attendanceRepo repositories.EventAttendanceRepository,
 	volunteerRepo repositories.VolunteerRepository,
 	auditLogRepo repositories.AuditLogRepository,
+	animalRepo repositories.AnimalRepository,
+	quarantines QuarantineChecker,
+	messenger Messenger,
+	uploader Uploader,
+	reminderOffsets []time.Duration,
 ) *EventUseCase {
 	return &EventUseCase{
-		eventRepo:      eventRepo,
-		attendanceRepo: attendanceRepo,
-		volunteerRepo:  volunteerRepo,
-		auditLogRepo:   auditLogRepo,
+		eventRepo:       eventRepo,
+		attendanceRepo:  attendanceRepo,
+		volunteerRepo:   volunteerRepo,
+		auditLogRepo:    auditLogRepo,
+		animalRepo:      animalRepo,
+		quarantines:     quarantines,
+		messenger:       messenger,
+		uploader:        uploader,
+		reminderOffsets: reminderOffsets,
 	}
 }
 
@@ -53,18 +85,6 @@ func (uc *EventUseCase) CreateEvent(ctx context.Context, event *entities.Event,
 	return nil
 }
 
-// SendEventReminder sends reminders for an event
-func (uc *EventUseCase) SendEventReminder(ctx context.Context, eventID primitive.ObjectID) (int, error) {
-	attendees, err := uc.attendanceRepo.GetAttendanceByEvent(ctx, eventID)
-	if err != nil {
-		return 0, err
-	}
-
-	// In a real application, this is where you'd send emails or notifications.
-	// For this task, we just return the count.
-	return len(attendees), nil
-}
-
 // GetEventRegistrations gets all registrations for an event
 func (uc *EventUseCase) GetEventRegistrations(ctx context.Context, eventID primitive.ObjectID, limit, offset int64) ([]*entities.EventAttendance, int64, error) {
 	filter := &repositories.EventAttendanceFilter{
@@ -118,11 +138,28 @@ func (uc *EventUseCase) UpdateEvent(ctx context.Context, event *entities.Event,
 		return err
 	}
 
+	// Kept by the server, not by the client
+	event.CreatedBy = existingEvent.CreatedBy
+	event.CreatedAt = existingEvent.CreatedAt
+	event.Sequence = existingEvent.Sequence
+	event.RemindersSent = existingEvent.RemindersSent
+	event.SeriesID = existingEvent.SeriesID
+	event.OccurrenceDate = existingEvent.OccurrenceDate
+	event.FeedbackRequestedAt = existingEvent.FeedbackRequestedAt
+	event.OutcomesUpdatedAt = existingEvent.OutcomesUpdatedAt
+	// An occurrence edited on its own keeps its changes when the series changes
+	event.Detached = existingEvent.SeriesID != nil
+
 	// Update event
-	if err := uc.eventRepo.Update(ctx, event); err != nil {
+	if err := uc.saveChanges(ctx, existingEvent, event, userID); err != nil {
 		return err
 	}
 
+	// The extra seats go to the waitlist
+	if existingEvent.Registration.WaitlistCount > 0 && capacityRaised(existingEvent, event) {
+		uc.promoteWaitlist(ctx, event.ID, userID)
+	}
+
 	// Audit log
 	_ = uc.auditLogRepo.Create(ctx,
 		entities.NewAuditLog(userID, entities.ActionUpdate, "event", "", "").
@@ -135,6 +172,42 @@ func (uc *EventUseCase) UpdateEvent(ctx context.Context, event *entities.Event,
 	return nil
 }
 
+// capacityRaised reports whether an update leaves room for more attendees than before,
+// by raising the attendee limit or removing it
+func capacityRaised(before, after *entities.Event) bool {
+	limited := func(event *entities.Event) bool {
+		return event.Registration.Required && event.Registration.MaxAttendees > 0
+	}
+	if !limited(before) {
+		return false
+	}
+	return !limited(after) || after.Registration.MaxAttendees > before.Registration.MaxAttendees
+}
+
+// saveChanges saves an updated event and tells the people coming to it when it was
+// rescheduled, moved, postponed or cancelled
+func (uc *EventUseCase) saveChanges(ctx context.Context, before, after *entities.Event, userID primitive.ObjectID) error {
+	change, announce := changeBetween(before, after)
+	if announce {
+		after.Sequence++
+	}
+	if !after.StartDate.Equal(before.StartDate) {
+		// Remind again before the new date
+		after.RemindersSent = nil
+	}
+	after.UpdatedBy = userID
+	after.UpdatedAt = time.Now()
+
+	if err := uc.eventRepo.Update(ctx, after); err != nil {
+		return err
+	}
+
+	if announce {
+		uc.notifyInvitees(ctx, after, change, 0, userID)
+	}
+	return nil
+}
+
 // DeleteEvent deletes an event
 func (uc *EventUseCase) DeleteEvent(ctx context.Context, eventID primitive.ObjectID, userID primitive.ObjectID) error {
 	// Check if event exists
@@ -186,53 +259,6 @@ func (uc *EventUseCase) GetPastEvents(ctx context.Context, limit int) ([]*entiti
 	return uc.eventRepo.GetCompletedEvents(ctx, limit)
 }
 
-// RegisterForEvent registers a user for an event
-func (uc *EventUseCase) RegisterForEvent(ctx context.Context, eventID primitive.ObjectID, req entities.EventAttendance, userID primitive.ObjectID) (*entities.EventAttendance, error) {
-	event, err := uc.eventRepo.FindByID(ctx, eventID)
-	if err != nil {
-		return nil, err
-	}
-
-	if event.Status == entities.EventStatusCancelled {
-		return nil, errors.NewBadRequest("Cannot register for a cancelled event")
-	}
-
-	if event.Registration.Required && event.IsFull() {
-		return nil, errors.NewBadRequest("Event is full")
-	}
-
-	attendance := entities.NewEventAttendance(eventID, req.AttendeeType, event.Registration.RegistrationFee, userID)
-	attendance.VolunteerID = req.VolunteerID
-	attendance.UserID = req.UserID
-	attendance.DonorID = req.DonorID
-	attendance.GuestName = req.GuestName
-	attendance.GuestEmail = req.GuestEmail
-	attendance.GuestPhone = req.GuestPhone
-	attendance.NumberOfGuests = req.NumberOfGuests
-
-	if err := uc.attendanceRepo.Create(ctx, attendance); err != nil {
-		return nil, err
-	}
-
-	event.Registration.CurrentCount++
-	event.AttendeeCount += 1 + req.NumberOfGuests
-	event.UpdatedBy = userID
-
-	if err := uc.eventRepo.Update(ctx, event); err != nil {
-		// Rollback attendance creation? For now, just log and return error
-		return nil, err
-	}
-
-	_ = uc.auditLogRepo.Create(ctx,
-		entities.NewAuditLog(userID, entities.ActionCreate, "event_attendance", "registered for event", "").
-			WithEntityID(attendance.ID).
-			WithChanges(map[string]interface{}{
-				"event_id": eventID,
-			}))
-
-	return attendance, nil
-}
-
 // GetPublicEvents gets public events
 func (uc *EventUseCase) GetPublicEvents(ctx context.Context) ([]*entities.Event, error) {
 	return uc.eventRepo.GetPublicEvents(ctx)
@@ -316,13 +342,22 @@ func (uc *EventUseCase) CancelEvent(ctx context.Context, eventID primitive.Objec
 		return errors.NewBadRequest("Cannot cancel completed or already cancelled events")
 	}
 
+	// Drafts were never sent to anyone
+	announce := event.Status != entities.EventStatusDraft
 	event.Status = entities.EventStatusCancelled
+	if announce {
+		event.Sequence++
+	}
 	event.UpdatedBy = userID
 
 	if err := uc.eventRepo.Update(ctx, event); err != nil {
 		return err
 	}
 
+	if announce {
+		uc.notifyInvitees(ctx, event, inviteCancelled, 0, userID)
+	}
+
 	// Audit log
 	_ = uc.auditLogRepo.Create(ctx,
 		entities.NewAuditLog(userID, entities.ActionUpdate, "event", "cancelled", "").
@@ -416,6 +451,95 @@ func (uc *EventUseCase) UnassignVolunteer(ctx context.Context, eventID, voluntee
 	return nil
 }
 
+// AssignAnimal brings an animal to an event. Quarantined animals cannot
+// leave the shelter.
+func (uc *EventUseCase) AssignAnimal(ctx context.Context, eventID, animalID, userID primitive.ObjectID) error {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return errors.NewNotFound("Animal not found")
+	}
+
+	quarantined := animal.IsQuarantined()
+	if !quarantined && uc.quarantines != nil {
+		if quarantined, err = uc.quarantines.HasActiveQuarantine(ctx, animal.ID); err != nil {
+			return err
+		}
+	}
+	if quarantined {
+		return errors.NewConflict("Animal is in quarantine and cannot be taken to events")
+	}
+	if !animal.IsInCare() {
+		return errors.NewBadRequest("Only animals in the shelter's care can be assigned")
+	}
+
+	for _, aID := range event.Animals {
+		if aID == animalID {
+			return errors.NewBadRequest("Animal already assigned to this event")
+		}
+	}
+
+	event.Animals = append(event.Animals, animalID)
+	event.UpdatedBy = userID
+
+	if err := uc.eventRepo.Update(ctx, event); err != nil {
+		return err
+	}
+
+	// Audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event", "assigned_animal", "").
+			WithEntityID(eventID).
+			WithChanges(map[string]interface{}{
+				"animal_id": animalID,
+			}))
+
+	return nil
+}
+
+// UnassignAnimal removes an animal from an event
+func (uc *EventUseCase) UnassignAnimal(ctx context.Context, eventID, animalID, userID primitive.ObjectID) error {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	newAnimals := []primitive.ObjectID{}
+	found := false
+	for _, aID := range event.Animals {
+		if aID != animalID {
+			newAnimals = append(newAnimals, aID)
+		} else {
+			found = true
+		}
+	}
+
+	if !found {
+		return errors.NewBadRequest("Animal not assigned to this event")
+	}
+
+	event.Animals = newAnimals
+	event.UpdatedBy = userID
+
+	if err := uc.eventRepo.Update(ctx, event); err != nil {
+		return err
+	}
+
+	// Audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event", "unassigned_animal", "").
+			WithEntityID(eventID).
+			WithChanges(map[string]interface{}{
+				"animal_id": animalID,
+			}))
+
+	return nil
+}
+
 // UpdateEventStatistics updates event statistics
 func (uc *EventUseCase) UpdateEventStatistics(ctx context.Context, eventID primitive.ObjectID, attendees, volunteers int, fundsRaised float64, animalsAdopted int) error {
 	return uc.eventRepo.UpdateEventStatistics(ctx, eventID, attendees, volunteers, fundsRaised, animalsAdopted)
@@ -444,5 +568,14 @@ func (uc *EventUseCase) validateEvent(event *entities.Event) error {
 		return errors.NewBadRequest("Event duration must be greater than 0")
 	}
 
+	for _, cost := range event.Costs {
+		if cost.Description == "" {
+			return errors.NewBadRequest("Event cost description is required")
+		}
+		if cost.Amount < 0 {
+			return errors.NewBadRequest("Event cost amount cannot be negative")
+		}
+	}
+
 	return nil
 }
@@ -26,9 +26,12 @@ type EventUseCaseInterface interface {
 	CancelEvent(ctx context.Context, eventID primitive.ObjectID, userID primitive.ObjectID) error
 	AssignVolunteer(ctx context.Context, eventID, volunteerID, userID primitive.ObjectID) error
 	UnassignVolunteer(ctx context.Context, eventID, volunteerID, userID primitive.ObjectID) error
+	AssignAnimal(ctx context.Context, eventID, animalID, userID primitive.ObjectID) error
+	UnassignAnimal(ctx context.Context, eventID, animalID, userID primitive.ObjectID) error
 	UpdateEventStatistics(ctx context.Context, eventID primitive.ObjectID, attendees, volunteers int, fundsRaised float64, animalsAdopted int) error
 	GetEventStatistics(ctx context.Context) (*repositories.EventStatistics, error)
 	RegisterForEvent(ctx context.Context, eventID primitive.ObjectID, req entities.EventAttendance, userID primitive.ObjectID) (*entities.EventAttendance, error)
+	CancelRegistration(ctx context.Context, eventID, attendanceID, userID primitive.ObjectID) (*entities.EventAttendance, error)
 	GetEventRegistrations(ctx context.Context, eventID primitive.ObjectID, limit, offset int64) ([]*entities.EventAttendance, int64, error)
 	GetEventStatisticsDetail(ctx context.Context, eventID primitive.ObjectID) (*entities.Event, error)
 	PublishEvent(ctx context.Context, eventID primitive.ObjectID, userID primitive.ObjectID) error
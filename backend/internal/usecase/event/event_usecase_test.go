@@ -7,6 +7,7 @@ import (
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,7 +15,7 @@ import (
 
 func TestEventUseCase_GetPastEvents(t *testing.T) {
 	mockEventRepo := new(mocks.EventRepository)
-	uc := NewEventUseCase(mockEventRepo, nil, nil, nil)
+	uc := NewEventUseCase(mockEventRepo, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	expectedEvents := []*entities.Event{{ID: primitive.NewObjectID()}}
 	mockEventRepo.On("GetCompletedEvents", mock.Anything, 20).Return(expectedEvents, nil)
@@ -30,7 +31,7 @@ func TestEventUseCase_RegisterForEvent(t *testing.T) {
 	mockEventRepo := new(mocks.EventRepository)
 	mockAttendanceRepo := new(mocks.EventAttendanceRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	uc := NewEventUseCase(mockEventRepo, mockAttendanceRepo, nil, mockAuditLogRepo)
+	uc := NewEventUseCase(mockEventRepo, mockAttendanceRepo, nil, mockAuditLogRepo, nil, nil, nil, nil, nil)
 
 	eventID := primitive.NewObjectID()
 	userID := primitive.NewObjectID()
@@ -49,7 +50,7 @@ func TestEventUseCase_RegisterForEvent(t *testing.T) {
 
 	mockEventRepo.On("FindByID", mock.Anything, eventID).Return(event, nil)
 	mockAttendanceRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.EventAttendance")).Return(nil)
-	mockEventRepo.On("Update", mock.Anything, event).Return(nil)
+	mockEventRepo.On("ReserveSeats", mock.Anything, eventID, 1).Return(true, nil)
 	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
 
 	attendance, err := uc.RegisterForEvent(context.Background(), eventID, req, userID)
@@ -63,7 +64,7 @@ func TestEventUseCase_RegisterForEvent(t *testing.T) {
 
 func TestEventUseCase_RegisterForEvent_FullEvent(t *testing.T) {
 	mockEventRepo := new(mocks.EventRepository)
-	uc := NewEventUseCase(mockEventRepo, nil, nil, nil)
+	uc := NewEventUseCase(mockEventRepo, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	eventID := primitive.NewObjectID()
 	event := &entities.Event{
@@ -87,7 +88,7 @@ func TestEventUseCase_RegisterForEvent_FullEvent(t *testing.T) {
 
 func TestEventUseCase_GetEventRegistrations(t *testing.T) {
 	mockAttendanceRepo := new(mocks.EventAttendanceRepository)
-	uc := NewEventUseCase(nil, mockAttendanceRepo, nil, nil)
+	uc := NewEventUseCase(nil, mockAttendanceRepo, nil, nil, nil, nil, nil, nil, nil)
 
 	eventID := primitive.NewObjectID()
 	expectedRegistrations := []*entities.EventAttendance{{ID: primitive.NewObjectID()}}
@@ -109,7 +110,7 @@ func TestEventUseCase_GetEventRegistrations(t *testing.T) {
 
 func TestEventUseCase_GetEventStatisticsDetail(t *testing.T) {
 	mockEventRepo := new(mocks.EventRepository)
-	uc := NewEventUseCase(mockEventRepo, nil, nil, nil)
+	uc := NewEventUseCase(mockEventRepo, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	eventID := primitive.NewObjectID()
 	expectedEvent := &entities.Event{
@@ -134,7 +135,7 @@ func TestEventUseCase_GetEventStatisticsDetail(t *testing.T) {
 func TestEventUseCase_PublishEvent(t *testing.T) {
 	mockEventRepo := new(mocks.EventRepository)
 	mockAuditLogRepo := new(mocks.AuditLogRepository)
-	uc := NewEventUseCase(mockEventRepo, nil, nil, mockAuditLogRepo)
+	uc := NewEventUseCase(mockEventRepo, nil, nil, mockAuditLogRepo, nil, nil, nil, nil, nil)
 
 	eventID := primitive.NewObjectID()
 	userID := primitive.NewObjectID()
@@ -155,16 +156,53 @@ func TestEventUseCase_PublishEvent(t *testing.T) {
 }
 
 func TestEventUseCase_SendEventReminder(t *testing.T) {
+	mockEventRepo := new(mocks.EventRepository)
 	mockAttendanceRepo := new(mocks.EventAttendanceRepository)
-	uc := NewEventUseCase(nil, mockAttendanceRepo, nil, nil)
+	messenger := &testutil.Messenger{}
+	uc := NewEventUseCase(mockEventRepo, mockAttendanceRepo, nil, nil, nil, nil, messenger, nil, nil)
 
 	eventID := primitive.NewObjectID()
-	expectedAttendees := []*entities.EventAttendance{{ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}}
+	event := &entities.Event{ID: eventID, Name: entities.MultilingualName{English: "Adoption Day"}, Status: entities.EventStatusScheduled}
+	expectedAttendees := []*entities.EventAttendance{
+		{ID: primitive.NewObjectID(), Status: entities.AttendanceStatusConfirmed, GuestEmail: "anna@example.org"},
+		{ID: primitive.NewObjectID(), Status: entities.AttendanceStatusRegistered, GuestPhone: "+48600100200"},
+		{ID: primitive.NewObjectID(), Status: entities.AttendanceStatusCancelled, GuestEmail: "jan@example.org"},
+	}
+	mockEventRepo.On("FindByID", mock.Anything, eventID).Return(event, nil)
 	mockAttendanceRepo.On("GetAttendanceByEvent", mock.Anything, eventID).Return(expectedAttendees, nil)
 
 	count, err := uc.SendEventReminder(context.Background(), eventID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 2, count)
+	assert.Equal(t, entities.TemplateTypeSMS, messenger.Sent[1].Type)
 	mockAttendanceRepo.AssertExpectations(t)
 }
+
+func TestEventUseCase_AssignAnimal(t *testing.T) {
+	mockEventRepo := new(mocks.EventRepository)
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	uc := NewEventUseCase(mockEventRepo, nil, nil, mockAuditLogRepo, mockAnimalRepo, nil, nil, nil, nil)
+
+	eventID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+	event := &entities.Event{ID: eventID, Status: entities.EventStatusScheduled}
+	available := &entities.Animal{ID: primitive.NewObjectID(), Status: entities.AnimalStatusAvailable}
+	quarantined := &entities.Animal{ID: primitive.NewObjectID(), Status: entities.AnimalStatusQuarantine}
+
+	mockEventRepo.On("FindByID", mock.Anything, eventID).Return(event, nil)
+	mockEventRepo.On("Update", mock.Anything, event).Return(nil)
+	mockAnimalRepo.On("FindByID", mock.Anything, available.ID).Return(available, nil)
+	mockAnimalRepo.On("FindByID", mock.Anything, quarantined.ID).Return(quarantined, nil)
+	mockAuditLogRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
+
+	assert.NoError(t, uc.AssignAnimal(context.Background(), eventID, available.ID, userID))
+	assert.Equal(t, []primitive.ObjectID{available.ID}, event.Animals)
+
+	err := uc.AssignAnimal(context.Background(), eventID, quarantined.ID, userID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "quarantine")
+	assert.Len(t, event.Animals, 1)
+	mockEventRepo.AssertNumberOfCalls(t, "Update", 1)
+}
@@ -98,6 +98,16 @@ func (m *EventUseCase) UnassignVolunteer(ctx context.Context, eventID, volunteer
 	return args.Error(0)
 }
 
+func (m *EventUseCase) AssignAnimal(ctx context.Context, eventID, animalID, userID primitive.ObjectID) error {
+	args := m.Called(ctx, eventID, animalID, userID)
+	return args.Error(0)
+}
+
+func (m *EventUseCase) UnassignAnimal(ctx context.Context, eventID, animalID, userID primitive.ObjectID) error {
+	args := m.Called(ctx, eventID, animalID, userID)
+	return args.Error(0)
+}
+
 func (m *EventUseCase) UpdateEventStatistics(ctx context.Context, eventID primitive.ObjectID, attendees, volunteers int, fundsRaised float64, animalsAdopted int) error {
 	args := m.Called(ctx, eventID, attendees, volunteers, fundsRaised, animalsAdopted)
 	return args.Error(0)
@@ -113,6 +123,11 @@ func (m *EventUseCase) RegisterForEvent(ctx context.Context, eventID primitive.O
 	return args.Get(0).(*entities.EventAttendance), args.Error(1)
 }
 
+func (m *EventUseCase) CancelRegistration(ctx context.Context, eventID, attendanceID, userID primitive.ObjectID) (*entities.EventAttendance, error) {
+	args := m.Called(ctx, eventID, attendanceID, userID)
+	return args.Get(0).(*entities.EventAttendance), args.Error(1)
+}
+
 func (m *EventUseCase) GetEventRegistrations(ctx context.Context, eventID primitive.ObjectID, limit, offset int64) ([]*entities.EventAttendance, int64, error) {
 	args := m.Called(ctx, eventID, limit, offset)
 	return args.Get(0).([]*entities.EventAttendance), args.Get(1).(int64), args.Error(2)
@@ -0,0 +1,238 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/usecase/attendee"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RegisterForEvent registers an attendee and their guests for an event. The seats are
+// taken atomically, so concurrent registrations cannot oversell the event; once it is
+// full the registration goes on the waitlist if the event keeps one.
+func (uc *EventUseCase) RegisterForEvent(ctx context.Context, eventID primitive.ObjectID, req entities.EventAttendance, userID primitive.ObjectID) (*entities.EventAttendance, error) {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch event.Status {
+	case entities.EventStatusCancelled:
+		return nil, errors.NewBadRequest("Cannot register for a cancelled event")
+	case entities.EventStatusCompleted:
+		return nil, errors.NewBadRequest("Cannot register for a completed event")
+	}
+	if event.RegistrationClosed(time.Now()) {
+		return nil, errors.NewBadRequest("Registration for this event has closed")
+	}
+	if req.NumberOfGuests < 0 {
+		return nil, errors.NewBadRequest("Number of guests cannot be negative")
+	}
+
+	// The fee is charged for every seat
+	seats := 1 + req.NumberOfGuests
+	attendance := entities.NewEventAttendance(eventID, req.AttendeeType, event.Registration.RegistrationFee*float64(seats), userID)
+	attendance.VolunteerID = req.VolunteerID
+	attendance.UserID = req.UserID
+	attendance.DonorID = req.DonorID
+	attendance.GuestName = req.GuestName
+	attendance.GuestEmail = req.GuestEmail
+	attendance.GuestPhone = req.GuestPhone
+	attendance.NumberOfGuests = req.NumberOfGuests
+	attendance.SpecialRequirements = req.SpecialRequirements
+	attendance.DietaryRestrictions = req.DietaryRestrictions
+	attendance.Notes = req.Notes
+
+	registration := event.Registration
+	if registration.Required && registration.MaxAttendees > 0 && seats > registration.MaxAttendees {
+		return nil, errors.NewBadRequest(fmt.Sprintf("The event has only %d seats", registration.MaxAttendees))
+	}
+	if event.IsFull() && !registration.Waitlist {
+		return nil, errors.NewBadRequest("Event is full")
+	}
+
+	reserved, err := uc.eventRepo.ReserveSeats(ctx, eventID, seats)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		if !registration.Waitlist {
+			return nil, errors.NewBadRequest("Event is full")
+		}
+		attendance.Waitlist()
+	}
+
+	if err := uc.attendanceRepo.Create(ctx, attendance); err != nil {
+		// Give the seats back so they are not held by a registration that does not exist
+		if reserved {
+			_ = uc.eventRepo.ReleaseSeats(ctx, eventID, seats)
+		}
+		return nil, err
+	}
+	if !reserved {
+		_ = uc.eventRepo.AdjustWaitlist(ctx, eventID, 1)
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionCreate, "event_attendance", "registered for event", "").
+			WithEntityID(attendance.ID).
+			WithChanges(map[string]interface{}{
+				"event_id": eventID,
+				"seats":    seats,
+				"status":   attendance.Status,
+			}))
+
+	return attendance, nil
+}
+
+// CancelRegistration cancels a registration for an event. The seats it held go to the
+// waitlisted registrations that fit, in the order they registered, and the promoted
+// attendees are notified.
+func (uc *EventUseCase) CancelRegistration(ctx context.Context, eventID, attendanceID, userID primitive.ObjectID) (*entities.EventAttendance, error) {
+	attendance, err := uc.attendanceRepo.FindByID(ctx, attendanceID)
+	if err != nil {
+		return nil, err
+	}
+	if attendance.EventID != eventID {
+		return nil, errors.ErrNotFound
+	}
+
+	switch attendance.Status {
+	case entities.AttendanceStatusCancelled:
+		return nil, errors.NewBadRequest("Registration is already cancelled")
+	case entities.AttendanceStatusAttended, entities.AttendanceStatusNoShow:
+		return nil, errors.NewBadRequest("Cannot cancel a registration after the event")
+	}
+
+	previous := attendance.Status
+	waitlisted := previous == entities.AttendanceStatusWaitlisted
+	attendance.Cancel()
+	attendance.UpdatedBy = userID
+	attendance.UpdatedAt = time.Now()
+	// Only the request that actually moves the registration out of its status gives back its
+	// seats, otherwise two concurrent cancellations would release them twice
+	cancelled, err := uc.attendanceRepo.UpdateIfStatus(ctx, attendance, previous)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, errors.NewConflict("The registration was changed by someone else, please try again")
+	}
+
+	promoted := 0
+	if waitlisted {
+		_ = uc.eventRepo.AdjustWaitlist(ctx, eventID, -1)
+	} else {
+		if err := uc.eventRepo.ReleaseSeats(ctx, eventID, attendance.Seats()); err != nil {
+			return nil, err
+		}
+		promoted = uc.promoteWaitlist(ctx, eventID, userID)
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event_attendance", "cancelled event registration", "").
+			WithEntityID(attendance.ID).
+			WithChanges(map[string]interface{}{
+				"event_id":   eventID,
+				"seats":      attendance.Seats(),
+				"waitlisted": waitlisted,
+				"promoted":   promoted,
+			}))
+
+	return attendance, nil
+}
+
+// promoteWaitlist gives free seats to waitlisted registrations in the order they registered.
+// A registration whose party does not fit is skipped so a smaller one behind it can take
+// the seats. Returns the number of registrations promoted.
+func (uc *EventUseCase) promoteWaitlist(ctx context.Context, eventID, userID primitive.ObjectID) int {
+	waitlist, _, err := uc.attendanceRepo.List(ctx, &repositories.EventAttendanceFilter{
+		EventID:   &eventID,
+		Status:    string(entities.AttendanceStatusWaitlisted),
+		SortBy:    "registration_date",
+		SortOrder: "asc",
+	})
+	if err != nil || len(waitlist) == 0 {
+		return 0
+	}
+
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return 0
+	}
+
+	promoted := 0
+	for _, attendance := range waitlist {
+		reserved, err := uc.eventRepo.ReserveSeats(ctx, eventID, attendance.Seats())
+		if err != nil {
+			break
+		}
+		if !reserved {
+			continue
+		}
+
+		attendance.Promote()
+		attendance.UpdatedBy = userID
+		attendance.UpdatedAt = time.Now()
+		// A concurrent promotion or cancellation may have taken the registration off the
+		// waitlist since it was listed; the seats just reserved are then given back
+		promotedNow, err := uc.attendanceRepo.UpdateIfStatus(ctx, attendance, entities.AttendanceStatusWaitlisted)
+		if err != nil || !promotedNow {
+			_ = uc.eventRepo.ReleaseSeats(ctx, eventID, attendance.Seats())
+			continue
+		}
+		_ = uc.eventRepo.AdjustWaitlist(ctx, eventID, -1)
+		uc.notifyPromoted(ctx, event, attendance, userID)
+		promoted++
+	}
+	return promoted
+}
+
+// notifyPromoted tells an attendee that their registration moved off the waitlist
+func (uc *EventUseCase) notifyPromoted(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance, userID primitive.ObjectID) {
+	if uc.messenger == nil {
+		return
+	}
+
+	name, email, phone := attendee.Contact(ctx, uc.volunteerRepo, attendance)
+
+	channel := entities.TemplateTypeEmail
+	if email == "" {
+		if phone == "" {
+			return
+		}
+		channel = entities.TemplateTypeSMS
+	}
+
+	title := event.DisplayName()
+	greeting := "Hello"
+	if name != "" {
+		greeting += " " + name
+	}
+	seats := "A seat has"
+	if attendance.Seats() > 1 {
+		seats = fmt.Sprintf("%d seats have", attendance.Seats())
+	}
+	body := fmt.Sprintf("%s,\n\n%s opened up at %s on %s and your registration has moved off the waitlist.",
+		greeting, seats, title, event.StartDate.Format("2006-01-02 15:04"))
+	if !attendance.IsPaid() {
+		body += fmt.Sprintf(" Please pay the registration fee of %.2f to secure your place.", attendance.RegistrationFee)
+	}
+	body += "\n\nIf you can no longer come, please let us know so the place can go to the next person on the waitlist."
+
+	communication := entities.NewCommunication(channel, entities.TemplateCategoryEvent, email, "You're off the waitlist for "+title, body, userID)
+	communication.RecipientPhone = phone
+	communication.RecipientName = name
+	communication.RelatedType = "event"
+	communication.RelatedID = &event.ID
+	communication.Metadata["attendance_id"] = attendance.ID.Hex()
+
+	_ = uc.messenger.CreateCommunication(ctx, communication, userID)
+}
+
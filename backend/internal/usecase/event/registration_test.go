@@ -0,0 +1,211 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type registrationMocks struct {
+	events      *mocks.EventRepository
+	attendances *mocks.EventAttendanceRepository
+	messenger   *testutil.Messenger
+}
+
+func newRegistrationUseCase() (*EventUseCase, *registrationMocks) {
+	m := &registrationMocks{
+		events:      new(mocks.EventRepository),
+		attendances: new(mocks.EventAttendanceRepository),
+		messenger:   &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+	return NewEventUseCase(m.events, m.attendances, nil, auditLogs, nil, nil, m.messenger, nil, nil), m
+}
+
+func limitedEvent(m *registrationMocks, max, taken int, waitlist bool) *entities.Event {
+	event := &entities.Event{
+		ID:        primitive.NewObjectID(),
+		Name:      entities.MultilingualName{English: "Adoption Day"},
+		Status:    entities.EventStatusScheduled,
+		StartDate: time.Now().AddDate(0, 0, 7),
+		Registration: entities.EventRegistration{
+			Required:     true,
+			MaxAttendees: max,
+			CurrentCount: taken,
+			Waitlist:     waitlist,
+		},
+	}
+	m.events.On("FindByID", mock.Anything, event.ID).Return(event, nil)
+	return event
+}
+
+func TestRegisterForEvent_CountsGuestsAsSeats(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 10, 2, false)
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 3).Return(true, nil)
+	m.attendances.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	attendance, err := uc.RegisterForEvent(context.Background(), event.ID, entities.EventAttendance{
+		AttendeeType:   entities.AttendeeTypePublic,
+		NumberOfGuests: 2,
+	}, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.AttendanceStatusRegistered, attendance.Status)
+	m.events.AssertExpectations(t)
+}
+
+func TestRegisterForEvent_WaitlistsWhenSeatsAreTaken(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 10, 9, true)
+	// Another registration took the last seat after the event was read
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 2).Return(false, nil)
+	m.events.On("AdjustWaitlist", mock.Anything, event.ID, 1).Return(nil)
+	m.attendances.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	attendance, err := uc.RegisterForEvent(context.Background(), event.ID, entities.EventAttendance{NumberOfGuests: 1}, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.AttendanceStatusWaitlisted, attendance.Status)
+	m.events.AssertExpectations(t)
+}
+
+func TestRegisterForEvent_ReleasesSeatsWhenTheRegistrationIsNotSaved(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 10, 0, false)
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 1).Return(true, nil)
+	m.events.On("ReleaseSeats", mock.Anything, event.ID, 1).Return(nil)
+	m.attendances.On("Create", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	_, err := uc.RegisterForEvent(context.Background(), event.ID, entities.EventAttendance{}, primitive.NewObjectID())
+
+	assert.Error(t, err)
+	m.events.AssertExpectations(t)
+}
+
+func TestRegisterForEvent_AfterDeadline(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 10, 0, false)
+	deadline := time.Now().Add(-time.Hour)
+	event.Registration.Deadline = &deadline
+
+	_, err := uc.RegisterForEvent(context.Background(), event.ID, entities.EventAttendance{}, primitive.NewObjectID())
+
+	assert.EqualError(t, err, "Registration for this event has closed")
+	m.events.AssertNotCalled(t, "ReserveSeats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancelRegistration_PromotesTheWaitlist(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 4, 4, true)
+	userID := primitive.NewObjectID()
+
+	cancelled := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: event.ID, Status: entities.AttendanceStatusConfirmed, NumberOfGuests: 1}
+	family := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: event.ID, Status: entities.AttendanceStatusWaitlisted, NumberOfGuests: 3, GuestEmail: "family@example.com"}
+	single := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: event.ID, Status: entities.AttendanceStatusWaitlisted, GuestName: "Jan", GuestPhone: "+48500100200", RegistrationFee: 20, PaymentStatus: "pending"}
+
+	m.attendances.On("FindByID", mock.Anything, cancelled.ID).Return(cancelled, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, cancelled, []entities.AttendanceStatus{entities.AttendanceStatusConfirmed}).Return(true, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, single, []entities.AttendanceStatus{entities.AttendanceStatusWaitlisted}).Return(true, nil)
+	m.attendances.On("List", mock.Anything, mock.MatchedBy(func(f *repositories.EventAttendanceFilter) bool {
+		return f.Status == string(entities.AttendanceStatusWaitlisted) && f.SortBy == "registration_date"
+	})).Return([]*entities.EventAttendance{family, single}, int64(2), nil)
+	m.events.On("ReleaseSeats", mock.Anything, event.ID, 2).Return(nil)
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 4).Return(false, nil)
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 1).Return(true, nil)
+	m.events.On("AdjustWaitlist", mock.Anything, event.ID, -1).Return(nil).Once()
+
+	attendance, err := uc.CancelRegistration(context.Background(), event.ID, cancelled.ID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.AttendanceStatusCancelled, attendance.Status)
+	assert.Equal(t, entities.AttendanceStatusWaitlisted, family.Status)
+	assert.Equal(t, entities.AttendanceStatusRegistered, single.Status)
+	assert.NotNil(t, single.PromotedDate)
+	require.Len(t, m.messenger.Sent, 1)
+	message := m.messenger.Sent[0]
+	assert.Equal(t, entities.TemplateTypeSMS, message.Type)
+	assert.Equal(t, "+48500100200", message.RecipientPhone)
+	assert.Contains(t, message.Subject, "Adoption Day")
+	assert.Contains(t, message.Body, "registration fee")
+	m.events.AssertExpectations(t)
+}
+
+func TestCancelRegistration_FromTheWaitlist(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	eventID := primitive.NewObjectID()
+	waiting := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: eventID, Status: entities.AttendanceStatusWaitlisted}
+	m.attendances.On("FindByID", mock.Anything, waiting.ID).Return(waiting, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, waiting, mock.Anything).Return(true, nil)
+	m.events.On("AdjustWaitlist", mock.Anything, eventID, -1).Return(nil)
+
+	_, err := uc.CancelRegistration(context.Background(), eventID, waiting.ID, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	m.events.AssertNotCalled(t, "ReleaseSeats", mock.Anything, mock.Anything, mock.Anything)
+	m.events.AssertExpectations(t)
+}
+
+func TestCancelRegistration_CancelledConcurrently(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	eventID := primitive.NewObjectID()
+	attendance := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: eventID, Status: entities.AttendanceStatusRegistered, NumberOfGuests: 2}
+	m.attendances.On("FindByID", mock.Anything, attendance.ID).Return(attendance, nil)
+	// Another request cancelled it between the read and the write
+	m.attendances.On("UpdateIfStatus", mock.Anything, attendance, mock.Anything).Return(false, nil)
+
+	_, err := uc.CancelRegistration(context.Background(), eventID, attendance.ID, primitive.NewObjectID())
+
+	assert.Error(t, err)
+	m.events.AssertNotCalled(t, "ReleaseSeats", mock.Anything, mock.Anything, mock.Anything)
+	m.events.AssertNotCalled(t, "AdjustWaitlist", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPromoteWaitlist_GivesBackSeatsOfARegistrationPromotedConcurrently(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 4, 3, true)
+	waiting := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: event.ID, Status: entities.AttendanceStatusWaitlisted, GuestEmail: "jan@example.com"}
+
+	m.attendances.On("List", mock.Anything, mock.Anything).Return([]*entities.EventAttendance{waiting}, int64(1), nil)
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 1).Return(true, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, waiting, mock.Anything).Return(false, nil)
+	m.events.On("ReleaseSeats", mock.Anything, event.ID, 1).Return(nil)
+
+	promoted := uc.promoteWaitlist(context.Background(), event.ID, primitive.NewObjectID())
+
+	assert.Equal(t, 0, promoted)
+	assert.Empty(t, m.messenger.Sent)
+	m.events.AssertCalled(t, "ReleaseSeats", mock.Anything, event.ID, 1)
+	m.events.AssertNotCalled(t, "AdjustWaitlist", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateEvent_RaisingTheLimitPromotesTheWaitlist(t *testing.T) {
+	uc, m := newRegistrationUseCase()
+	event := limitedEvent(m, 2, 2, true)
+	event.Registration.WaitlistCount = 1
+	event.Type = entities.EventTypeAdoption
+	event.Duration = 120
+	waiting := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: event.ID, Status: entities.AttendanceStatusWaitlisted, GuestEmail: "jan@example.com"}
+
+	m.events.On("Update", mock.Anything, mock.Anything).Return(nil)
+	m.attendances.On("List", mock.Anything, mock.Anything).Return([]*entities.EventAttendance{waiting}, int64(1), nil)
+	m.events.On("ReserveSeats", mock.Anything, event.ID, 1).Return(true, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, waiting, mock.Anything).Return(true, nil)
+	m.events.On("AdjustWaitlist", mock.Anything, event.ID, -1).Return(nil)
+
+	updated := *event
+	updated.Registration.MaxAttendees = 3
+	require.NoError(t, uc.UpdateEvent(context.Background(), &updated, primitive.NewObjectID()))
+
+	assert.Equal(t, entities.AttendanceStatusRegistered, waiting.Status)
+	require.Len(t, m.messenger.Sent, 1)
+}
@@ -0,0 +1,338 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/usecase/attendee"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/ical"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// inviteChange is the kind of message sent to the people coming to an event
+type inviteChange string
+
+const (
+	inviteReminder  inviteChange = "reminder"
+	inviteUpdated   inviteChange = "updated"
+	invitePostponed inviteChange = "postponed"
+	inviteCancelled inviteChange = "cancelled"
+)
+
+// invitee is someone coming to an event: an attendee or an assigned volunteer
+type invitee struct {
+	name         string
+	email        string
+	phone        string
+	attendanceID *primitive.ObjectID
+	volunteerID  *primitive.ObjectID
+}
+
+// SendEventReminder sends a reminder with the calendar invite to the confirmed attendees
+// and assigned volunteers of an event now, and returns the number of people reminded
+func (uc *EventUseCase) SendEventReminder(ctx context.Context, eventID primitive.ObjectID) (int, error) {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if event.Status != entities.EventStatusScheduled && event.Status != entities.EventStatusActive {
+		return 0, errors.NewBadRequest("Reminders can only be sent for scheduled or active events")
+	}
+
+	return uc.notifyInvitees(ctx, event, inviteReminder, 0, event.CreatedBy), nil
+}
+
+// SendDueReminders is the scheduler job sending the reminders of upcoming events once
+// each configured offset before their start is reached
+func (uc *EventUseCase) SendDueReminders(ctx context.Context) error {
+	if len(uc.reminderOffsets) == 0 {
+		return nil
+	}
+
+	events, err := uc.eventRepo.GetUpcomingEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		offset, due := event.DueReminder(now, uc.reminderOffsets)
+		if !due {
+			continue
+		}
+
+		// The reminder is recorded before it is sent, so it is not sent again by an
+		// overlapping run and is not sent at all when the event was just rescheduled
+		reminder := entities.EventReminder{OffsetMinutes: int(offset / time.Minute), SentAt: now}
+		recorded, err := uc.eventRepo.AddReminder(ctx, event.ID, event.StartDate, reminder)
+		if err != nil {
+			log.Error().Err(err).Str("event_id", event.ID.Hex()).Msg("failed to record event reminder, not sending it")
+			continue
+		}
+		if !recorded {
+			continue
+		}
+
+		reminder.Recipients = uc.notifyInvitees(ctx, event, inviteReminder, offset, event.CreatedBy)
+		event.RemindersSent = append(event.RemindersSent, reminder)
+		if err := uc.eventRepo.SetReminderRecipients(ctx, event.ID, reminder.OffsetMinutes, reminder.Recipients); err != nil {
+			log.Error().Err(err).Str("event_id", event.ID.Hex()).Msg("failed to store the recipients of an event reminder")
+		}
+	}
+	return nil
+}
+
+// changeBetween returns what to tell the people coming to an event about an update:
+// that it was rescheduled or moved, postponed or cancelled. Reports false when the
+// update does not concern them.
+func changeBetween(before, after *entities.Event) (inviteChange, bool) {
+	if before.Status == entities.EventStatusDraft || after.Status == entities.EventStatusDraft {
+		return "", false
+	}
+
+	switch {
+	case after.Status == entities.EventStatusCancelled && before.Status != entities.EventStatusCancelled:
+		return inviteCancelled, true
+	case after.Status == entities.EventStatusPostponed && before.Status != entities.EventStatusPostponed:
+		return invitePostponed, true
+	case after.Status == entities.EventStatusCancelled || after.Status == entities.EventStatusCompleted:
+		return "", false
+	}
+
+	if !after.StartDate.Equal(before.StartDate) || !after.EndTime().Equal(before.EndTime()) ||
+		after.Location != before.Location || after.VirtualLink != before.VirtualLink {
+		return inviteUpdated, true
+	}
+	return "", false
+}
+
+// notifyInvitees sends a message with the calendar invite of an event to everyone coming
+// to it and returns the number of people it was sent to. Reminders go to the attendees
+// holding a paid seat; changes also reach unpaid and waitlisted registrations.
+func (uc *EventUseCase) notifyInvitees(ctx context.Context, event *entities.Event, change inviteChange, offset time.Duration, userID primitive.ObjectID) int {
+	if uc.messenger == nil {
+		return 0
+	}
+
+	invitees := uc.invitees(ctx, event, change != inviteReminder)
+	if len(invitees) == 0 {
+		return 0
+	}
+	attachment := uc.storeInvite(ctx, event, change)
+
+	sent := 0
+	for _, invitee := range invitees {
+		channel := entities.TemplateTypeEmail
+		if invitee.email == "" {
+			channel = entities.TemplateTypeSMS
+		}
+
+		subject, body := inviteMessage(event, change, offset, invitee.name, channel == entities.TemplateTypeSMS)
+		communication := entities.NewCommunication(channel, entities.TemplateCategoryEvent, invitee.email, subject, body, userID)
+		communication.RecipientPhone = invitee.phone
+		communication.RecipientName = invitee.name
+		communication.RelatedType = "event"
+		communication.RelatedID = &event.ID
+		communication.Metadata["invite"] = string(change)
+		communication.Metadata["sequence"] = event.Sequence
+		if invitee.attendanceID != nil {
+			communication.Metadata["attendance_id"] = invitee.attendanceID.Hex()
+		}
+		if invitee.volunteerID != nil {
+			communication.Metadata["volunteer_id"] = invitee.volunteerID.Hex()
+		}
+		if attachment != nil && channel == entities.TemplateTypeEmail {
+			communication.Attachments = append(communication.Attachments, *attachment)
+		}
+
+		if err := uc.messenger.CreateCommunication(ctx, communication, userID); err == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
+// invitees returns the attendees and assigned volunteers of an event that can be
+// contacted, each once
+func (uc *EventUseCase) invitees(ctx context.Context, event *entities.Event, includePending bool) []invitee {
+	var invitees []invitee
+	seen := make(map[string]bool)
+	add := func(i invitee) {
+		key := strings.ToLower(i.email)
+		if key == "" {
+			key = i.phone
+		}
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		invitees = append(invitees, i)
+	}
+
+	if attendances, err := uc.attendanceRepo.GetAttendanceByEvent(ctx, event.ID); err == nil {
+		for _, attendance := range attendances {
+			switch attendance.Status {
+			case entities.AttendanceStatusRegistered, entities.AttendanceStatusConfirmed:
+				if !attendance.IsPaid() && !includePending {
+					continue
+				}
+			case entities.AttendanceStatusWaitlisted:
+				if !includePending {
+					continue
+				}
+			default:
+				continue
+			}
+
+			name, email, phone := attendee.Contact(ctx, uc.volunteerRepo, attendance)
+			add(invitee{name: name, email: email, phone: phone, attendanceID: &attendance.ID, volunteerID: attendance.VolunteerID})
+		}
+	}
+
+	if uc.volunteerRepo != nil {
+		for _, volunteerID := range event.AssignedVolunteers {
+			volunteer, err := uc.volunteerRepo.FindByID(ctx, volunteerID)
+			if err != nil {
+				continue
+			}
+			id := volunteer.ID
+			add(invitee{name: volunteer.GetFullName(), email: volunteer.Email, phone: volunteer.Phone, volunteerID: &id})
+		}
+	}
+	return invitees
+}
+
+// storeInvite uploads the .ics invite of an event to attach it to emails
+func (uc *EventUseCase) storeInvite(ctx context.Context, event *entities.Event, change inviteChange) *entities.CommunicationAttachment {
+	if uc.uploader == nil {
+		return nil
+	}
+
+	content := calendarInvite(event, change).Bytes()
+	filename := fmt.Sprintf("invite-%d.ics", event.Sequence)
+	if change == inviteCancelled {
+		filename = fmt.Sprintf("cancel-%d.ics", event.Sequence)
+	}
+	url, err := uc.uploader.Upload(ctx, bytes.NewReader(content), int64(len(content)), "events/"+event.ID.Hex(), filename, "text/calendar")
+	if err != nil {
+		return nil
+	}
+	return &entities.CommunicationAttachment{Filename: "invite.ics", URL: url, ContentType: "text/calendar", Size: int64(len(content))}
+}
+
+// calendarInvite returns the iCalendar invite of an event. Every version of the invite
+// has the same UID and a higher sequence, so calendar apps update the event in place.
+func calendarInvite(event *entities.Event, change inviteChange) *ical.Calendar {
+	method := ical.MethodRequest
+	status := ical.StatusConfirmed
+	switch change {
+	case inviteCancelled:
+		method, status = ical.MethodCancel, ical.StatusCancelled
+	case invitePostponed:
+		status = ical.StatusTentative
+	}
+
+	invite := ical.Event{
+		UID:         fmt.Sprintf("event-%s@animalsys", event.ID.Hex()),
+		Sequence:    event.Sequence,
+		Start:       event.StartDate,
+		End:         event.EndTime(),
+		Summary:     event.DisplayName(),
+		Description: event.Description.English,
+		Location:    event.Location.String(),
+		URL:         event.VirtualLink,
+		Status:      status,
+		Updated:     event.UpdatedAt,
+	}
+	if invite.Description == "" {
+		invite.Description = event.Description.Polish
+	}
+	if invite.Location == "" {
+		invite.Location = event.VirtualLink
+	}
+	if event.ContactEmail != "" {
+		invite.Organizer = &ical.Attendee{Email: event.ContactEmail}
+	}
+
+	return &ical.Calendar{Method: method, Events: []ical.Event{invite}}
+}
+
+// inviteMessage returns the subject and body of a message about an event; text messages
+// get a short body without the details
+func inviteMessage(event *entities.Event, change inviteChange, offset time.Duration, name string, short bool) (string, string) {
+	title := event.DisplayName()
+	when := event.StartDate.Format("2006-01-02 15:04")
+	greeting := "Hello"
+	if name != "" {
+		greeting += " " + name
+	}
+
+	var subject, text string
+	switch change {
+	case inviteReminder:
+		subject = "Reminder: " + title
+		text = fmt.Sprintf("This is a reminder that %s starts on %s.", title, when)
+		if offset > 0 {
+			text = fmt.Sprintf("This is a reminder that %s starts %s, on %s.", title, startsIn(offset), when)
+		}
+	case inviteUpdated:
+		subject = "Updated: " + title
+		text = fmt.Sprintf("%s has changed. It now starts on %s.", title, when)
+	case invitePostponed:
+		subject = "Postponed: " + title
+		text = fmt.Sprintf("%s has been postponed. We will let you know the new date as soon as it is set.", title)
+	case inviteCancelled:
+		subject = "Cancelled: " + title
+		text = fmt.Sprintf("We are sorry to let you know that %s on %s has been cancelled.", title, when)
+	}
+
+	if short {
+		return subject, text
+	}
+
+	body := greeting + ",\n\n" + text
+	if change != inviteCancelled && change != invitePostponed {
+		if where := event.Location.String(); where != "" {
+			body += "\nLocation: " + where
+		}
+		if event.VirtualLink != "" {
+			body += "\nOnline: " + event.VirtualLink
+		}
+		body += "\n\nThe attached invite adds the event to your calendar."
+	} else {
+		body += "\n\nThe attached invite updates the event in your calendar."
+	}
+	if event.ContactEmail != "" || event.ContactPhone != "" {
+		contacts := []string{}
+		for _, contact := range []string{event.ContactEmail, event.ContactPhone} {
+			if contact != "" {
+				contacts = append(contacts, contact)
+			}
+		}
+		body += "\n\nQuestions? Contact us at " + strings.Join(contacts, " or ") + "."
+	}
+	return subject, body
+}
+
+// startsIn describes a reminder offset, e.g. "in 7 days", "tomorrow" or "in 2 hours"
+func startsIn(offset time.Duration) string {
+	switch {
+	case offset == 24*time.Hour:
+		return "tomorrow"
+	case offset%(24*time.Hour) == 0:
+		return fmt.Sprintf("in %d days", offset/(24*time.Hour))
+	case offset == time.Hour:
+		return "in 1 hour"
+	case offset%time.Hour == 0:
+		return fmt.Sprintf("in %d hours", offset/time.Hour)
+	default:
+		return fmt.Sprintf("in %d minutes", offset/time.Minute)
+	}
+}
@@ -0,0 +1,191 @@
+package event
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type recordingUploader struct {
+	files map[string]string
+}
+
+func (u *recordingUploader) Upload(ctx context.Context, r io.Reader, size int64, folder, filename, contentType string) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	u.files[folder+"/"+filename] = string(content)
+	return "https://files.example.org/" + folder + "/" + filename, nil
+}
+
+type reminderMocks struct {
+	events      *mocks.EventRepository
+	attendances *mocks.EventAttendanceRepository
+	volunteers  *mocks.VolunteerRepository
+	messenger   *testutil.Messenger
+	uploader    *recordingUploader
+}
+
+func newReminderUseCase(offsets ...time.Duration) (*EventUseCase, *reminderMocks) {
+	m := &reminderMocks{
+		events:      new(mocks.EventRepository),
+		attendances: new(mocks.EventAttendanceRepository),
+		volunteers:  new(mocks.VolunteerRepository),
+		messenger:   &testutil.Messenger{},
+		uploader:    &recordingUploader{files: map[string]string{}},
+	}
+	auditLogs := testutil.AuditLogs()
+	return NewEventUseCase(m.events, m.attendances, m.volunteers, auditLogs, nil, nil, m.messenger, m.uploader, offsets), m
+}
+
+// invitedEvent sets up a scheduled event with a paid attendee, an unpaid one and a volunteer
+func invitedEvent(m *reminderMocks, start time.Time) *entities.Event {
+	volunteer := &entities.Volunteer{ID: primitive.NewObjectID(), FirstName: "Ola", LastName: "Lis", Email: "ola@example.org"}
+	event := &entities.Event{
+		ID:                 primitive.NewObjectID(),
+		Name:               entities.MultilingualName{English: "Adoption Day"},
+		Type:               entities.EventTypeAdoption,
+		Status:             entities.EventStatusScheduled,
+		StartDate:          start,
+		Duration:           120,
+		Location:           entities.EventLocation{Name: "Main Shelter", City: "Warsaw"},
+		AssignedVolunteers: []primitive.ObjectID{volunteer.ID},
+	}
+	m.events.On("FindByID", mock.Anything, event.ID).Return(event, nil)
+	m.volunteers.On("FindByID", mock.Anything, volunteer.ID).Return(volunteer, nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, event.ID).Return([]*entities.EventAttendance{
+		{ID: primitive.NewObjectID(), Status: entities.AttendanceStatusConfirmed, GuestEmail: "anna@example.org", PaymentStatus: "paid"},
+		{ID: primitive.NewObjectID(), Status: entities.AttendanceStatusRegistered, GuestEmail: "jan@example.org", RegistrationFee: 10, PaymentStatus: "pending"},
+	}, nil)
+	return event
+}
+
+func recipients(sent []*entities.Communication) []string {
+	var emails []string
+	for _, communication := range sent {
+		emails = append(emails, communication.RecipientEmail)
+	}
+	return emails
+}
+
+func TestSendDueReminders_SendsTheShortestDueOffsetOnce(t *testing.T) {
+	uc, m := newReminderUseCase(7*24*time.Hour, 24*time.Hour, 2*time.Hour)
+	// Scheduled at short notice: the seven-day reminder was never due
+	event := invitedEvent(m, time.Now().Add(20*time.Hour))
+	m.events.On("GetUpcomingEvents", mock.Anything).Return([]*entities.Event{event}, nil)
+	m.events.On("AddReminder", mock.Anything, event.ID, event.StartDate, mock.MatchedBy(func(reminder entities.EventReminder) bool {
+		return reminder.OffsetMinutes == 24*60
+	})).Return(true, nil).Once()
+	m.events.On("SetReminderRecipients", mock.Anything, event.ID, 24*60, 2).Return(nil)
+
+	require.NoError(t, uc.SendDueReminders(context.Background()))
+	require.NoError(t, uc.SendDueReminders(context.Background()))
+
+	assert.Equal(t, []string{"anna@example.org", "ola@example.org"}, recipients(m.messenger.Sent))
+	assert.Equal(t, "Reminder: Adoption Day", m.messenger.Sent[0].Subject)
+	assert.Contains(t, m.messenger.Sent[0].Body, "starts tomorrow")
+	require.Len(t, m.messenger.Sent[0].Attachments, 1)
+	assert.Equal(t, "text/calendar", m.messenger.Sent[0].Attachments[0].ContentType)
+
+	require.Len(t, event.RemindersSent, 1)
+	assert.Equal(t, 24*60, event.RemindersSent[0].OffsetMinutes)
+	assert.Equal(t, 2, event.RemindersSent[0].Recipients)
+	m.events.AssertNumberOfCalls(t, "AddReminder", 1)
+	m.events.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestSendDueReminders_SkipsAReminderAlreadyClaimedOrRescheduled(t *testing.T) {
+	uc, m := newReminderUseCase(24 * time.Hour)
+	event := invitedEvent(m, time.Now().Add(20*time.Hour))
+	m.events.On("GetUpcomingEvents", mock.Anything).Return([]*entities.Event{event}, nil)
+	m.events.On("AddReminder", mock.Anything, event.ID, event.StartDate, mock.Anything).Return(false, nil)
+
+	require.NoError(t, uc.SendDueReminders(context.Background()))
+
+	assert.Empty(t, m.messenger.Sent)
+	m.events.AssertNotCalled(t, "SetReminderRecipients", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateEvent_SendsUpdatedInviteWhenRescheduled(t *testing.T) {
+	uc, m := newReminderUseCase(24 * time.Hour)
+	existing := invitedEvent(m, time.Now().Add(12*time.Hour))
+	existing.Sequence = 1
+	existing.CreatedBy = primitive.NewObjectID()
+	existing.RemindersSent = []entities.EventReminder{{OffsetMinutes: 24 * 60}}
+
+	updated := *existing
+	updated.CreatedBy = primitive.NilObjectID // Not sent by clients
+	updated.RemindersSent = nil
+	updated.StartDate = existing.StartDate.Add(48 * time.Hour)
+	m.events.On("Update", mock.Anything, &updated).Return(nil)
+
+	require.NoError(t, uc.UpdateEvent(context.Background(), &updated, primitive.NewObjectID()))
+
+	assert.Equal(t, 2, updated.Sequence)
+	assert.Equal(t, existing.CreatedBy, updated.CreatedBy)
+	assert.Empty(t, updated.RemindersSent, "reminders are sent again before the new date")
+	// Unpaid registrations hear about changes too
+	assert.Equal(t, []string{"anna@example.org", "jan@example.org", "ola@example.org"}, recipients(m.messenger.Sent))
+	assert.Equal(t, "Updated: Adoption Day", m.messenger.Sent[0].Subject)
+
+	invite := m.uploader.files["events/"+updated.ID.Hex()+"/invite-2.ics"]
+	assert.Contains(t, invite, "METHOD:REQUEST")
+	assert.Contains(t, invite, "UID:event-"+updated.ID.Hex()+"@animalsys")
+	assert.Contains(t, invite, "SEQUENCE:2")
+	assert.Contains(t, invite, "DTSTART:"+updated.StartDate.UTC().Format("20060102T150405Z"))
+	assert.Contains(t, invite, "LOCATION:Main Shelter\\, Warsaw")
+}
+
+func TestUpdateEvent_DoesNotAnnounceOtherChanges(t *testing.T) {
+	uc, m := newReminderUseCase()
+	existing := invitedEvent(m, time.Now().Add(72*time.Hour))
+
+	updated := *existing
+	updated.Notes = "Bring leashes"
+	m.events.On("Update", mock.Anything, &updated).Return(nil)
+
+	require.NoError(t, uc.UpdateEvent(context.Background(), &updated, primitive.NewObjectID()))
+
+	assert.Equal(t, 0, updated.Sequence)
+	assert.Empty(t, m.messenger.Sent)
+}
+
+func TestUpdateEvent_AnnouncesPostponement(t *testing.T) {
+	uc, m := newReminderUseCase()
+	existing := invitedEvent(m, time.Now().Add(72*time.Hour))
+
+	updated := *existing
+	updated.Status = entities.EventStatusPostponed
+	m.events.On("Update", mock.Anything, &updated).Return(nil)
+
+	require.NoError(t, uc.UpdateEvent(context.Background(), &updated, primitive.NewObjectID()))
+
+	require.Len(t, m.messenger.Sent, 3)
+	assert.Equal(t, "Postponed: Adoption Day", m.messenger.Sent[0].Subject)
+	assert.Contains(t, m.uploader.files["events/"+updated.ID.Hex()+"/invite-1.ics"], "STATUS:TENTATIVE")
+}
+
+func TestCancelEvent_SendsCancellation(t *testing.T) {
+	uc, m := newReminderUseCase()
+	event := invitedEvent(m, time.Now().Add(72*time.Hour))
+	m.events.On("Update", mock.Anything, event).Return(nil)
+
+	require.NoError(t, uc.CancelEvent(context.Background(), event.ID, primitive.NewObjectID()))
+
+	assert.Equal(t, 1, event.Sequence)
+	require.Len(t, m.messenger.Sent, 3)
+	assert.Equal(t, "Cancelled: Adoption Day", m.messenger.Sent[0].Subject)
+	invite := m.uploader.files["events/"+event.ID.Hex()+"/cancel-1.ics"]
+	assert.Contains(t, invite, "METHOD:CANCEL")
+	assert.Contains(t, invite, "STATUS:CANCELLED")
+}
@@ -0,0 +1,670 @@
+package event
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/ical"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxOccurrences caps the occurrences generated for a series at once, e.g. for a daily
+// series without an end
+const maxOccurrences = 400
+
+// SeriesUseCase handles recurring events: series whose occurrences are regular events
+// generated from a recurrence rule
+type SeriesUseCase struct {
+	events     *EventUseCase
+	seriesRepo repositories.EventSeriesRepository
+	horizon    time.Duration // occurrences are generated this far ahead
+}
+
+// NewSeriesUseCase creates a new event series use case
+func NewSeriesUseCase(events *EventUseCase, seriesRepo repositories.EventSeriesRepository, horizon time.Duration) *SeriesUseCase {
+	return &SeriesUseCase{
+		events:     events,
+		seriesRepo: seriesRepo,
+		horizon:    horizon,
+	}
+}
+
+// SeriesStatistics summarizes the attendance of the occurrences of a series and of the
+// series split off it
+type SeriesStatistics struct {
+	SeriesID           primitive.ObjectID     `json:"series_id"`
+	Occurrences        int                    `json:"occurrences"`
+	Upcoming           int                    `json:"upcoming"`
+	Held               int                    `json:"held"`
+	Cancelled          int                    `json:"cancelled"`
+	Registered         int                    `json:"registered"` // Seats registered for the occurrences held
+	Attended           int                    `json:"attended"`
+	NoShows            int                    `json:"no_shows"`
+	AverageAttendance  float64                `json:"average_attendance"` // Per occurrence held
+	AttendanceRate     float64                `json:"attendance_rate"`    // Attended out of registered seats, in percent
+	FillRate           float64                `json:"fill_rate"`          // Registered out of the capacity of occurrences with a limit, in percent
+	UniqueAttendees    int                    `json:"unique_attendees"`
+	ReturningAttendees int                    `json:"returning_attendees"` // Came to more than one occurrence
+	ByOccurrence       []OccurrenceStatistics `json:"by_occurrence"`
+}
+
+// OccurrenceStatistics is the attendance of one occurrence of a series
+type OccurrenceStatistics struct {
+	EventID    primitive.ObjectID   `json:"event_id"`
+	StartDate  time.Time            `json:"start_date"`
+	Status     entities.EventStatus `json:"status"`
+	Capacity   int                  `json:"capacity,omitempty"`
+	Registered int                  `json:"registered"`
+	Waitlisted int                  `json:"waitlisted"`
+	Attended   int                  `json:"attended"`
+	NoShows    int                  `json:"no_shows"`
+}
+
+// CreateSeries creates a series and generates its occurrences up to the horizon
+func (uc *SeriesUseCase) CreateSeries(ctx context.Context, series *entities.EventSeries, userID primitive.ObjectID) ([]*entities.Event, error) {
+	rule, err := validateSeries(series)
+	if err != nil {
+		return nil, err
+	}
+
+	series.Status = entities.EventSeriesStatusActive
+	series.GeneratedUntil = time.Time{}
+	series.ParentID = nil
+	if series.Organizer.IsZero() {
+		series.Organizer = userID
+	}
+	series.CreatedBy = userID
+	series.UpdatedBy = userID
+
+	if err := uc.seriesRepo.Create(ctx, series); err != nil {
+		return nil, err
+	}
+
+	// Occurrences are not generated in the past
+	from := series.StartDate
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	occurrences, err := uc.plan(ctx, series, rule, from, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.seriesRepo.Update(ctx, series); err != nil {
+		return nil, err
+	}
+
+	_ = uc.events.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionCreate, "event_series", series.Name.English, "").
+			WithEntityID(series.ID).
+			WithChanges(map[string]interface{}{
+				"recurrence":  series.Recurrence,
+				"occurrences": len(occurrences),
+			}))
+
+	return occurrences, nil
+}
+
+// GetSeries gets a series by ID
+func (uc *SeriesUseCase) GetSeries(ctx context.Context, seriesID primitive.ObjectID) (*entities.EventSeries, error) {
+	return uc.seriesRepo.FindByID(ctx, seriesID)
+}
+
+// ListSeries lists series with filters
+func (uc *SeriesUseCase) ListSeries(ctx context.Context, filter *repositories.EventSeriesFilter) ([]*entities.EventSeries, int64, error) {
+	return uc.seriesRepo.List(ctx, filter)
+}
+
+// ListOccurrences lists the occurrences of a series by start date
+func (uc *SeriesUseCase) ListOccurrences(ctx context.Context, seriesID primitive.ObjectID, from *time.Time, limit, offset int64) ([]*entities.Event, int64, error) {
+	if _, err := uc.seriesRepo.FindByID(ctx, seriesID); err != nil {
+		return nil, 0, err
+	}
+	return uc.events.eventRepo.List(ctx, &repositories.EventFilter{
+		SeriesID:  &seriesID,
+		StartDate: from,
+		SortBy:    "start_date",
+		SortOrder: "asc",
+		Limit:     limit,
+		Offset:    offset,
+	})
+}
+
+// UpdateSeries changes the template or the recurrence of a series. Without fromEventID
+// all upcoming occurrences change. With it only that occurrence and the following ones
+// change ("this and following"): the series then ends before the occurrence and a new
+// series split off it takes over from there, starting at update.StartDate if it was
+// changed. Occurrences edited on their own keep their changes. Returns the series now
+// holding the changed occurrences, and the occurrences changed or created.
+func (uc *SeriesUseCase) UpdateSeries(ctx context.Context, seriesID primitive.ObjectID, update *entities.EventSeries, fromEventID *primitive.ObjectID, userID primitive.ObjectID) (*entities.EventSeries, []*entities.Event, error) {
+	series, err := uc.seriesRepo.FindByID(ctx, seriesID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if series.Status != entities.EventSeriesStatusActive {
+		return nil, nil, errors.NewBadRequest("Only active series can be changed")
+	}
+
+	if update.Recurrence == "" {
+		update.Recurrence = series.Recurrence
+	}
+	startChanged := !update.StartDate.IsZero() && !update.StartDate.Equal(series.StartDate)
+	if !startChanged {
+		update.StartDate = series.StartDate
+	}
+	if update.Organizer.IsZero() {
+		update.Organizer = series.Organizer
+	}
+
+	from := time.Now()
+	scope := "all"
+	target, splitOff := update, false
+	if fromEventID != nil {
+		occurrence, err := uc.events.eventRepo.FindByID(ctx, *fromEventID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if occurrence.SeriesID == nil || *occurrence.SeriesID != series.ID {
+			return nil, nil, errors.NewBadRequest("The event is not an occurrence of this series")
+		}
+		from = occurrence.StartDate
+		if occurrence.OccurrenceDate != nil {
+			from = *occurrence.OccurrenceDate
+		}
+		scope = "following"
+
+		if !startChanged {
+			update.StartDate = from
+		}
+		target, splitOff, err = uc.split(ctx, series, update, from, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rule, err := validateSeries(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !splitOff {
+		// The series changes as a whole
+		update.ID = series.ID
+		update.Status = series.Status
+		update.GeneratedUntil = series.GeneratedUntil
+		update.ParentID = series.ParentID
+		update.CreatedBy = series.CreatedBy
+		update.CreatedAt = series.CreatedAt
+	}
+	target.UpdatedBy = userID
+
+	// Occurrences moved earlier than the change are planned too
+	planFrom := from
+	if target.StartDate.Before(planFrom) && target.StartDate.After(time.Now()) {
+		planFrom = target.StartDate
+	}
+	existing, _, err := uc.events.eventRepo.List(ctx, &repositories.EventFilter{
+		SeriesID:  &series.ID,
+		StartDate: &planFrom,
+		SortBy:    "start_date",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, occurrence := range existing {
+		if fromEventID != nil && occurrence.ID == *fromEventID {
+			// The occurrence the change was made on takes it, even if edited before
+			occurrence.Detached = false
+		}
+	}
+
+	occurrences, err := uc.plan(ctx, target, rule, planFrom, existing, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := uc.seriesRepo.Update(ctx, target); err != nil {
+		return nil, nil, err
+	}
+
+	_ = uc.events.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event_series", target.Name.English, "").
+			WithEntityID(target.ID).
+			WithChanges(map[string]interface{}{
+				"scope":       scope,
+				"from":        from,
+				"series_id":   series.ID.Hex(),
+				"recurrence":  target.Recurrence,
+				"occurrences": len(occurrences),
+			}))
+
+	return target, occurrences, nil
+}
+
+// split ends a series before the occurrence at from and creates the series taking over
+// from there with the changes. The first occurrence cannot be split off: the whole
+// series changes instead.
+func (uc *SeriesUseCase) split(ctx context.Context, series, update *entities.EventSeries, from time.Time, userID primitive.ObjectID) (*entities.EventSeries, bool, error) {
+	head, err := ical.ParseRRule(series.Recurrence)
+	if err != nil {
+		return nil, false, errors.NewBadRequest("Invalid recurrence: " + err.Error())
+	}
+	earlier := head.Occurrences(series.StartDate.In(series.TimeLocation()), from.Add(-time.Second), 0)
+	if len(earlier) == 0 {
+		return update, false, nil
+	}
+
+	if update.Recurrence == series.Recurrence && head.Count > 0 {
+		// The new series has the occurrences left
+		tail := *head
+		tail.Count = head.Count - len(earlier)
+		if tail.Count < 1 {
+			return nil, false, errors.NewBadRequest("The series has no occurrences left from this event")
+		}
+		update.Recurrence = tail.String()
+	}
+
+	if head.Count > 0 {
+		head.Count = len(earlier)
+	} else {
+		head.Until = from.Add(-time.Second).UTC()
+	}
+	series.Recurrence = head.String()
+	series.UpdatedBy = userID
+
+	update.ID = primitive.NilObjectID
+	update.Status = entities.EventSeriesStatusActive
+	update.GeneratedUntil = time.Time{}
+	update.ParentID = &series.ID
+	update.CreatedBy = userID
+	if _, err := validateSeries(update); err != nil {
+		return nil, false, err
+	}
+
+	if err := uc.seriesRepo.Update(ctx, series); err != nil {
+		return nil, false, err
+	}
+	if err := uc.seriesRepo.Create(ctx, update); err != nil {
+		return nil, false, err
+	}
+	return update, true, nil
+}
+
+// CancelSeries ends a series and cancels its upcoming occurrences, telling the people
+// coming to them. Returns the number of occurrences cancelled.
+func (uc *SeriesUseCase) CancelSeries(ctx context.Context, seriesID, userID primitive.ObjectID) (int, error) {
+	series, err := uc.seriesRepo.FindByID(ctx, seriesID)
+	if err != nil {
+		return 0, err
+	}
+	if series.Status == entities.EventSeriesStatusCancelled {
+		return 0, errors.NewBadRequest("The series is already cancelled")
+	}
+
+	series.Status = entities.EventSeriesStatusCancelled
+	series.UpdatedBy = userID
+	if err := uc.seriesRepo.Update(ctx, series); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	upcoming, _, err := uc.events.eventRepo.List(ctx, &repositories.EventFilter{
+		SeriesID:  &series.ID,
+		StartDate: &now,
+		SortBy:    "start_date",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, occurrence := range upcoming {
+		if !pending(occurrence) {
+			continue
+		}
+		if err := uc.events.CancelEvent(ctx, occurrence.ID, userID); err == nil {
+			cancelled++
+		}
+	}
+
+	_ = uc.events.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event_series", series.Name.English, "cancelled").
+			WithEntityID(series.ID).
+			WithChanges(map[string]interface{}{"occurrences_cancelled": cancelled}))
+
+	return cancelled, nil
+}
+
+// GenerateOccurrences is the scheduler job keeping the occurrences of active series
+// generated up to the horizon
+func (uc *SeriesUseCase) GenerateOccurrences(ctx context.Context) error {
+	series, _, err := uc.seriesRepo.List(ctx, &repositories.EventSeriesFilter{Status: string(entities.EventSeriesStatusActive)})
+	if err != nil {
+		return err
+	}
+
+	until := time.Now().Add(uc.horizon)
+	for _, s := range series {
+		if !s.GeneratedUntil.Before(until) {
+			continue
+		}
+		rule, err := ical.ParseRRule(s.Recurrence)
+		if err != nil {
+			continue
+		}
+
+		created := 0
+		for _, slot := range rule.Occurrences(s.StartDate.In(s.TimeLocation()), until, 0) {
+			if !slot.After(s.GeneratedUntil) {
+				continue
+			}
+			if err := uc.events.eventRepo.Create(ctx, s.NewOccurrence(slot, s.CreatedBy)); err != nil {
+				break
+			}
+			created++
+			if created == maxOccurrences {
+				until = slot
+				break
+			}
+		}
+
+		s.GeneratedUntil = until
+		_ = uc.seriesRepo.Update(ctx, s)
+	}
+	return nil
+}
+
+// plan brings the occurrences of a series from a date on in line with its rule and
+// template, up to the horizon. Existing occurrences are matched to the dates of the
+// rule, or to the nearest date within a week when the series moves to other days, and
+// updated; the ones left over are cancelled and missing ones are created. Occurrences
+// edited on their own or already under way or over only move to the series. Returns
+// the occurrences updated or created.
+func (uc *SeriesUseCase) plan(ctx context.Context, series *entities.EventSeries, rule *ical.RRule, from time.Time, existing []*entities.Event, userID primitive.ObjectID) ([]*entities.Event, error) {
+	loc := series.TimeLocation()
+	until := time.Now().Add(uc.horizon)
+
+	var slots []time.Time
+	for _, slot := range rule.Occurrences(series.StartDate.In(loc), until, 0) {
+		if slot.Before(from) {
+			continue
+		}
+		slots = append(slots, slot)
+		if len(slots) == maxOccurrences {
+			until = slot
+			break
+		}
+	}
+
+	day := func(t time.Time) string { return t.In(loc).Format("2006-01-02") }
+	open := make(map[string]time.Time, len(slots))
+	for _, slot := range slots {
+		open[day(slot)] = slot
+	}
+
+	// Occurrences on a date of the rule keep it; a date that is taken is not filled again
+	matched := make(map[primitive.ObjectID]time.Time)
+	var unmatched []*entities.Event
+	for _, occurrence := range existing {
+		key := day(occurrenceDate(occurrence))
+		if slot, ok := open[key]; ok {
+			matched[occurrence.ID] = slot
+			delete(open, key)
+		} else {
+			unmatched = append(unmatched, occurrence)
+		}
+	}
+
+	// The others take the nearest open date within a week, in order, e.g. when the new
+	// time moves the dates across midnight
+	for _, occurrence := range unmatched {
+		date := occurrenceDate(occurrence)
+		var nearest time.Time
+		for _, slot := range slots {
+			if _, ok := open[day(slot)]; !ok || absDuration(slot.Sub(date)) >= 7*24*time.Hour {
+				continue
+			}
+			if nearest.IsZero() || absDuration(slot.Sub(date)) < absDuration(nearest.Sub(date)) {
+				nearest = slot
+			}
+		}
+		if !nearest.IsZero() {
+			matched[occurrence.ID] = nearest
+			delete(open, day(nearest))
+		}
+	}
+
+	var changed []*entities.Event
+	for _, occurrence := range existing {
+		slot, ok := matched[occurrence.ID]
+		switch {
+		case occurrence.Detached || !pending(occurrence):
+			// Edited on its own or already held: keeps its date, but takes the slot
+			if occurrence.SeriesID == nil || *occurrence.SeriesID != series.ID {
+				occurrence.SeriesID = &series.ID
+				_ = uc.events.eventRepo.Update(ctx, occurrence)
+			}
+		case ok:
+			before := *occurrence
+			occurrence.SeriesID = &series.ID
+			occurrence.StartDate = slot
+			occurrence.OccurrenceDate = &slot
+			series.ApplyTo(occurrence)
+			if err := uc.events.saveChanges(ctx, &before, occurrence, userID); err != nil {
+				return changed, err
+			}
+			changed = append(changed, occurrence)
+		default:
+			uc.dropOccurrence(ctx, occurrence, userID)
+		}
+	}
+
+	for _, slot := range slots {
+		if _, ok := open[day(slot)]; !ok {
+			continue
+		}
+		occurrence := series.NewOccurrence(slot, userID)
+		if err := uc.events.eventRepo.Create(ctx, occurrence); err != nil {
+			return changed, err
+		}
+		changed = append(changed, occurrence)
+	}
+
+	if until.After(series.GeneratedUntil) {
+		series.GeneratedUntil = until
+	}
+	return changed, nil
+}
+
+// dropOccurrence removes an occurrence the rule no longer gives. Occurrences nobody
+// signed up for are deleted; the others are cancelled so the people coming hear of it.
+func (uc *SeriesUseCase) dropOccurrence(ctx context.Context, occurrence *entities.Event, userID primitive.ObjectID) {
+	if occurrence.Registration.CurrentCount == 0 && occurrence.Registration.WaitlistCount == 0 && len(occurrence.AssignedVolunteers) == 0 {
+		if err := uc.events.eventRepo.Delete(ctx, occurrence.ID); err == nil {
+			return
+		}
+	}
+	_ = uc.events.CancelEvent(ctx, occurrence.ID, userID)
+}
+
+// GetSeriesStatistics returns the attendance of the occurrences of a series, including
+// the series split off it
+func (uc *SeriesUseCase) GetSeriesStatistics(ctx context.Context, seriesID primitive.ObjectID) (*SeriesStatistics, error) {
+	series, err := uc.seriesRepo.FindByID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	var occurrences []*entities.Event
+	queue := []primitive.ObjectID{series.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		events, _, err := uc.events.eventRepo.List(ctx, &repositories.EventFilter{SeriesID: &id, SortBy: "start_date", SortOrder: "asc"})
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, events...)
+
+		children, _, err := uc.seriesRepo.List(ctx, &repositories.EventSeriesFilter{ParentID: &id})
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			queue = append(queue, child.ID)
+		}
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool { return occurrences[i].StartDate.Before(occurrences[j].StartDate) })
+
+	stats := &SeriesStatistics{SeriesID: series.ID, Occurrences: len(occurrences), ByOccurrence: []OccurrenceStatistics{}}
+	visits := make(map[string]int)
+	capacity, booked := 0, 0
+	for _, occurrence := range occurrences {
+		row := OccurrenceStatistics{
+			EventID:    occurrence.ID,
+			StartDate:  occurrence.StartDate,
+			Status:     occurrence.Status,
+			Capacity:   occurrence.Registration.MaxAttendees,
+			Registered: occurrence.Registration.CurrentCount,
+			Waitlisted: occurrence.Registration.WaitlistCount,
+			Attended:   occurrence.AttendeeCount,
+		}
+
+		held := occurrence.Status == entities.EventStatusCompleted || occurrence.Status == entities.EventStatusActive
+		if held {
+			if attendances, err := uc.events.attendanceRepo.GetAttendanceByEvent(ctx, occurrence.ID); err == nil {
+				seen := make(map[string]bool)
+				for _, attendance := range attendances {
+					switch attendance.Status {
+					case entities.AttendanceStatusNoShow:
+						row.NoShows += attendance.Seats()
+					case entities.AttendanceStatusRegistered, entities.AttendanceStatusConfirmed:
+						if occurrence.Status == entities.EventStatusCompleted && attendance.IsPaid() {
+							row.NoShows += attendance.Seats()
+						}
+					case entities.AttendanceStatusAttended:
+						if key := attendeeKey(attendance); key != "" && !seen[key] {
+							seen[key] = true
+							visits[key]++
+						}
+					}
+				}
+			}
+		}
+
+		switch {
+		case held:
+			stats.Held++
+			stats.Registered += row.Registered
+			stats.Attended += row.Attended
+			stats.NoShows += row.NoShows
+			if row.Capacity > 0 {
+				capacity += row.Capacity
+				booked += row.Registered
+			}
+		case occurrence.Status == entities.EventStatusCancelled:
+			stats.Cancelled++
+		default:
+			stats.Upcoming++
+		}
+		stats.ByOccurrence = append(stats.ByOccurrence, row)
+	}
+
+	if stats.Held > 0 {
+		stats.AverageAttendance = float64(stats.Attended) / float64(stats.Held)
+	}
+	if stats.Registered > 0 {
+		stats.AttendanceRate = float64(stats.Attended) / float64(stats.Registered) * 100
+	}
+	if capacity > 0 {
+		stats.FillRate = float64(booked) / float64(capacity) * 100
+	}
+	stats.UniqueAttendees = len(visits)
+	for _, count := range visits {
+		if count > 1 {
+			stats.ReturningAttendees++
+		}
+	}
+	return stats, nil
+}
+
+// validateSeries validates series data and returns its recurrence rule
+func validateSeries(series *entities.EventSeries) (*ical.RRule, error) {
+	if series.Name.English == "" {
+		return nil, errors.NewBadRequest("Series name is required")
+	}
+	if series.Type == "" {
+		return nil, errors.NewBadRequest("Series type is required")
+	}
+	if series.StartDate.IsZero() {
+		return nil, errors.NewBadRequest("Series start date is required")
+	}
+	if series.Duration <= 0 {
+		return nil, errors.NewBadRequest("Series duration must be greater than 0")
+	}
+	if series.RegistrationCloseMinutes < 0 {
+		return nil, errors.NewBadRequest("Registration close minutes cannot be negative")
+	}
+	if series.Timezone != "" {
+		if _, err := time.LoadLocation(series.Timezone); err != nil {
+			return nil, errors.NewBadRequest("Unknown time zone " + series.Timezone)
+		}
+	}
+
+	rule, err := ical.ParseRRule(series.Recurrence)
+	if err != nil {
+		return nil, errors.NewBadRequest("Invalid recurrence: " + err.Error())
+	}
+	series.Recurrence = rule.String()
+	return rule, nil
+}
+
+// pending checks if an occurrence has yet to take place
+func pending(occurrence *entities.Event) bool {
+	switch occurrence.Status {
+	case entities.EventStatusDraft, entities.EventStatusScheduled, entities.EventStatusPostponed:
+		return true
+	}
+	return false
+}
+
+// occurrenceDate returns the date the rule gave an occurrence, or its start for
+// occurrences from before it was recorded
+func occurrenceDate(occurrence *entities.Event) time.Time {
+	if occurrence.OccurrenceDate != nil {
+		return *occurrence.OccurrenceDate
+	}
+	return occurrence.StartDate
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// attendeeKey identifies an attendee across occurrences: by their record, or by the
+// contact details of a guest
+func attendeeKey(attendance *entities.EventAttendance) string {
+	switch {
+	case attendance.VolunteerID != nil:
+		return "volunteer:" + attendance.VolunteerID.Hex()
+	case attendance.UserID != nil:
+		return "user:" + attendance.UserID.Hex()
+	case attendance.DonorID != nil:
+		return "donor:" + attendance.DonorID.Hex()
+	case attendance.GuestEmail != "":
+		return "email:" + strings.ToLower(attendance.GuestEmail)
+	case attendance.GuestPhone != "":
+		return "phone:" + attendance.GuestPhone
+	}
+	return ""
+}
@@ -0,0 +1,272 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type seriesMocks struct {
+	*reminderMocks
+	series  *mocks.EventSeriesRepository
+	created []*entities.Event
+}
+
+func newSeriesUseCase(horizon time.Duration) (*SeriesUseCase, *seriesMocks) {
+	events, rm := newReminderUseCase()
+	m := &seriesMocks{reminderMocks: rm, series: new(mocks.EventSeriesRepository)}
+	m.events.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		occurrence := args.Get(1).(*entities.Event)
+		occurrence.ID = primitive.NewObjectID()
+		m.created = append(m.created, occurrence)
+	}).Return(nil)
+	m.series.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.EventSeries).ID = primitive.NewObjectID()
+	}).Return(nil)
+	m.series.On("Update", mock.Anything, mock.Anything).Return(nil)
+	return NewSeriesUseCase(events, m.series, horizon), m
+}
+
+// weeklySeries is a stored series of adoption days starting tomorrow
+func weeklySeries(m *seriesMocks, recurrence string) *entities.EventSeries {
+	series := &entities.EventSeries{
+		ID:         primitive.NewObjectID(),
+		Name:       entities.MultilingualName{English: "Adoption Day"},
+		Type:       entities.EventTypeAdoption,
+		Status:     entities.EventSeriesStatusActive,
+		Recurrence: recurrence,
+		StartDate:  time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour),
+		Duration:   120,
+		Location:   entities.EventLocation{Name: "Main Shelter"},
+	}
+	m.series.On("FindByID", mock.Anything, series.ID).Return(series, nil)
+	return series
+}
+
+// occurrence is a stored occurrence of a series, days after its start
+func occurrence(m *seriesMocks, series *entities.EventSeries, days int) *entities.Event {
+	start := series.StartDate.AddDate(0, 0, days)
+	event := series.NewOccurrence(start, primitive.NewObjectID())
+	event.ID = primitive.NewObjectID()
+	m.events.On("FindByID", mock.Anything, event.ID).Return(event, nil)
+	return event
+}
+
+func TestCreateSeries_GeneratesOccurrencesUpToTheHorizon(t *testing.T) {
+	uc, m := newSeriesUseCase(30 * 24 * time.Hour)
+	start := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+	deadline := start.Add(time.Hour)
+	series := &entities.EventSeries{
+		Name:       entities.MultilingualName{English: "Volunteer Training"},
+		Type:       entities.EventTypeVolunteer,
+		Recurrence: "freq=weekly",
+		StartDate:  start,
+		Duration:   90,
+		Registration: entities.EventRegistration{
+			Required:     true,
+			MaxAttendees: 20,
+			CurrentCount: 5,         // Counted per occurrence
+			Deadline:     &deadline, // Set per occurrence
+		},
+		RegistrationCloseMinutes: 60,
+	}
+
+	occurrences, err := uc.CreateSeries(context.Background(), series, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY", series.Recurrence)
+	require.Len(t, occurrences, 5)
+	for i, occurrence := range occurrences {
+		assert.Equal(t, start.AddDate(0, 0, 7*i), occurrence.StartDate)
+		assert.Equal(t, series.ID, *occurrence.SeriesID)
+		assert.Equal(t, occurrence.StartDate, *occurrence.OccurrenceDate)
+		assert.Equal(t, entities.EventStatusScheduled, occurrence.Status)
+		assert.Equal(t, 20, occurrence.Registration.MaxAttendees)
+		assert.Equal(t, 0, occurrence.Registration.CurrentCount)
+		assert.Equal(t, occurrence.StartDate.Add(-time.Hour), *occurrence.Registration.Deadline)
+	}
+	assert.True(t, series.GeneratedUntil.After(start.AddDate(0, 0, 28)))
+	m.series.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestUpdateSeries_ThisAndFollowingSplitsTheSeries(t *testing.T) {
+	uc, m := newSeriesUseCase(60 * 24 * time.Hour)
+	series := weeklySeries(m, "FREQ=WEEKLY;COUNT=6")
+	var existing []*entities.Event
+	for week := 2; week < 6; week++ {
+		existing = append(existing, occurrence(m, series, 7*week))
+	}
+	from, detached := existing[0], existing[1]
+	detached.Detached = true
+	detached.Notes = "Held indoors"
+	m.events.On("List", mock.Anything, mock.Anything).Return(existing, int64(len(existing)), nil)
+	m.events.On("Update", mock.Anything, mock.Anything).Return(nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, mock.Anything).Return([]*entities.EventAttendance{
+		{Status: entities.AttendanceStatusConfirmed, GuestEmail: "anna@example.org"},
+	}, nil)
+
+	update := *series
+	update.ID = primitive.NilObjectID
+	update.StartDate = from.StartDate.Add(time.Hour)
+	update.Location = entities.EventLocation{Name: "City Park"}
+
+	tail, changed, err := uc.UpdateSeries(context.Background(), series.ID, &update, &from.ID, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", series.Recurrence, "the series ends before the change")
+	require.NotEqual(t, series.ID, tail.ID)
+	assert.Equal(t, series.ID, *tail.ParentID)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4", tail.Recurrence, "the new series has the occurrences left")
+
+	assert.Len(t, changed, 3)
+	for _, occurrence := range []*entities.Event{existing[0], existing[2], existing[3]} {
+		assert.Equal(t, tail.ID, *occurrence.SeriesID)
+		assert.Equal(t, "City Park", occurrence.Location.Name)
+		assert.Equal(t, 1, occurrence.Sequence)
+	}
+	assert.Equal(t, series.StartDate.AddDate(0, 0, 14).Add(time.Hour), from.StartDate)
+
+	// Edited on its own: only moves to the new series
+	assert.Equal(t, tail.ID, *detached.SeriesID)
+	assert.Equal(t, "Main Shelter", detached.Location.Name)
+	assert.Equal(t, series.StartDate.AddDate(0, 0, 21), detached.StartDate)
+
+	assert.Len(t, m.messenger.Sent, 3)
+	assert.Equal(t, "Updated: Adoption Day", m.messenger.Sent[0].Subject)
+	m.events.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateSeries_MovesOccurrencesToOtherDays(t *testing.T) {
+	uc, m := newSeriesUseCase(20 * 24 * time.Hour)
+	series := weeklySeries(m, "FREQ=WEEKLY")
+	first := occurrence(m, series, 0)
+	booked := occurrence(m, series, 7)
+	booked.Registration.CurrentCount = 2
+	third := occurrence(m, series, 14)
+	unbooked := occurrence(m, series, 21)
+	existing := []*entities.Event{first, booked, third, unbooked}
+	m.events.On("List", mock.Anything, mock.Anything).Return(existing, int64(len(existing)), nil)
+	m.events.On("Update", mock.Anything, mock.Anything).Return(nil)
+	m.events.On("Delete", mock.Anything, unbooked.ID).Return(nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, mock.Anything).Return([]*entities.EventAttendance{}, nil)
+
+	// Every other week, a day later
+	update := *series
+	update.Recurrence = "FREQ=WEEKLY;INTERVAL=2"
+	update.StartDate = series.StartDate.AddDate(0, 0, 1)
+
+	updated, changed, err := uc.UpdateSeries(context.Background(), series.ID, &update, nil, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, series.ID, updated.ID)
+	assert.Equal(t, []*entities.Event{first, third}, changed, "occurrences keep their registrations when moved")
+	assert.Equal(t, series.StartDate.AddDate(0, 0, 1), first.StartDate)
+	assert.Equal(t, series.StartDate.AddDate(0, 0, 15), third.StartDate)
+	assert.Equal(t, entities.EventStatusCancelled, booked.Status, "people signed up hear it was cancelled")
+	m.events.AssertCalled(t, "Delete", mock.Anything, unbooked.ID)
+	m.events.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateSeries_LaterTimeAcrossMidnightKeepsEditedOccurrences(t *testing.T) {
+	uc, m := newSeriesUseCase(25 * 24 * time.Hour)
+	series := weeklySeries(m, "FREQ=WEEKLY")
+	series.StartDate = time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1).Add(23 * time.Hour)
+	var existing []*entities.Event
+	for week := 0; week < 4; week++ {
+		existing = append(existing, occurrence(m, series, 7*week))
+	}
+	existing[1].Detached = true
+	m.events.On("List", mock.Anything, mock.Anything).Return(existing, int64(len(existing)), nil)
+	m.events.On("Update", mock.Anything, mock.Anything).Return(nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, mock.Anything).Return([]*entities.EventAttendance{}, nil)
+
+	// Two hours later is past midnight, on the next day
+	update := *series
+	update.StartDate = series.StartDate.Add(2 * time.Hour)
+
+	_, changed, err := uc.UpdateSeries(context.Background(), series.ID, &update, nil, primitive.NewObjectID())
+
+	require.NoError(t, err)
+	assert.Equal(t, []*entities.Event{existing[0], existing[2], existing[3]}, changed)
+	for i, occurrence := range changed {
+		assert.Equal(t, update.StartDate.AddDate(0, 0, []int{0, 14, 21}[i]), occurrence.StartDate)
+	}
+	assert.Equal(t, series.StartDate.AddDate(0, 0, 7), existing[1].StartDate, "edited on its own, it keeps its date")
+	m.events.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestGenerateOccurrences_ExtendsTheHorizon(t *testing.T) {
+	uc, m := newSeriesUseCase(20 * 24 * time.Hour)
+	series := weeklySeries(m, "FREQ=WEEKLY")
+	series.GeneratedUntil = series.StartDate.AddDate(0, 0, 7)
+	m.series.On("List", mock.Anything, mock.Anything).Return([]*entities.EventSeries{series}, int64(1), nil)
+
+	require.NoError(t, uc.GenerateOccurrences(context.Background()))
+
+	require.Len(t, m.created, 1)
+	assert.Equal(t, series.StartDate.AddDate(0, 0, 14), m.created[0].StartDate)
+	assert.True(t, series.GeneratedUntil.After(m.created[0].StartDate))
+}
+
+func TestGetSeriesStatistics(t *testing.T) {
+	uc, m := newSeriesUseCase(0)
+	series := weeklySeries(m, "FREQ=WEEKLY")
+	child := &entities.EventSeries{ID: primitive.NewObjectID(), ParentID: &series.ID}
+
+	held := occurrence(m, series, -14)
+	held.Status = entities.EventStatusCompleted
+	held.Registration.MaxAttendees, held.Registration.CurrentCount, held.AttendeeCount = 10, 8, 6
+	cancelled := occurrence(m, series, -7)
+	cancelled.Status = entities.EventStatusCancelled
+	split := occurrence(m, series, -1)
+	split.Status = entities.EventStatusCompleted
+	split.Registration.MaxAttendees, split.Registration.CurrentCount, split.AttendeeCount = 10, 10, 7
+	upcoming := occurrence(m, series, 7)
+
+	m.events.On("List", mock.Anything, mock.MatchedBy(func(filter *repositories.EventFilter) bool {
+		return *filter.SeriesID == series.ID
+	})).Return([]*entities.Event{held, cancelled, upcoming}, int64(3), nil)
+	m.events.On("List", mock.Anything, mock.MatchedBy(func(filter *repositories.EventFilter) bool {
+		return *filter.SeriesID == child.ID
+	})).Return([]*entities.Event{split}, int64(1), nil)
+	m.series.On("List", mock.Anything, mock.MatchedBy(func(filter *repositories.EventSeriesFilter) bool {
+		return *filter.ParentID == series.ID
+	})).Return([]*entities.EventSeries{child}, int64(1), nil)
+	m.series.On("List", mock.Anything, mock.Anything).Return([]*entities.EventSeries{}, int64(0), nil)
+
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, held.ID).Return([]*entities.EventAttendance{
+		{Status: entities.AttendanceStatusAttended, GuestEmail: "Anna@example.org"},
+		{Status: entities.AttendanceStatusNoShow, GuestEmail: "jan@example.org"},
+		{Status: entities.AttendanceStatusConfirmed, GuestEmail: "piotr@example.org", NumberOfGuests: 1},
+	}, nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, split.ID).Return([]*entities.EventAttendance{
+		{Status: entities.AttendanceStatusAttended, GuestEmail: "anna@example.org"},
+		{Status: entities.AttendanceStatusAttended, GuestEmail: "ola@example.org"},
+	}, nil)
+
+	stats, err := uc.GetSeriesStatistics(context.Background(), series.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.Occurrences)
+	assert.Equal(t, 2, stats.Held)
+	assert.Equal(t, 1, stats.Cancelled)
+	assert.Equal(t, 1, stats.Upcoming)
+	assert.Equal(t, 18, stats.Registered)
+	assert.Equal(t, 13, stats.Attended)
+	assert.Equal(t, 3, stats.NoShows)
+	assert.Equal(t, 6.5, stats.AverageAttendance)
+	assert.InDelta(t, 72.2, stats.AttendanceRate, 0.1)
+	assert.Equal(t, 90.0, stats.FillRate)
+	assert.Equal(t, 2, stats.UniqueAttendees)
+	assert.Equal(t, 1, stats.ReturningAttendees)
+	require.Len(t, stats.ByOccurrence, 4)
+	assert.Equal(t, split.ID, stats.ByOccurrence[2].EventID, "by start date across the split")
+}
@@ -0,0 +1,355 @@
+package followup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/imaging"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag marks the tasks escalating concerning survey answers
+const Tag = "adoption-follow-up"
+
+// photoSizes stores the adopters' photos in a single size
+var photoSizes = []imaging.Size{{Name: imaging.SizeLarge, MaxWidth: 1600, MaxHeight: 1600}}
+
+// escalationDays is the time staff get to contact an adopter who reported concerns
+const escalationDays = 2
+
+// Messenger sends the post-adoption survey links to adopters
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// FollowUpUseCase runs the post-adoption follow-ups: it sends the adopter a survey link
+// when a follow-up is due, stores the answers and escalates concerning ones to staff
+type FollowUpUseCase struct {
+	adoptionRepo    repositories.AdoptionRepository
+	applicationRepo repositories.AdoptionApplicationRepository
+	animalRepo      repositories.AnimalRepository
+	templateRepo    repositories.CommunicationTemplateRepository
+	settingsRepo    repositories.SettingsRepository
+	taskRepo        repositories.TaskRepository
+	auditLogRepo    repositories.AuditLogRepository
+	storageService  *storage.StorageService
+	imageProcessor  *imaging.Processor
+	presignExpiry   time.Duration // how long the links to the adopters' photos are valid
+	messenger       Messenger
+	surveyURL       string // the survey page of the web app; the token is appended
+}
+
+// NewFollowUpUseCase creates a new follow-up use case
+func NewFollowUpUseCase(
+	adoptionRepo repositories.AdoptionRepository,
+	applicationRepo repositories.AdoptionApplicationRepository,
+	animalRepo repositories.AnimalRepository,
+	templateRepo repositories.CommunicationTemplateRepository,
+	settingsRepo repositories.SettingsRepository,
+	taskRepo repositories.TaskRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	storageService *storage.StorageService,
+	presignExpiry time.Duration,
+	messenger Messenger,
+	surveyURL string,
+) *FollowUpUseCase {
+	return &FollowUpUseCase{
+		adoptionRepo:    adoptionRepo,
+		applicationRepo: applicationRepo,
+		animalRepo:      animalRepo,
+		templateRepo:    templateRepo,
+		settingsRepo:    settingsRepo,
+		taskRepo:        taskRepo,
+		auditLogRepo:    auditLogRepo,
+		storageService:  storageService,
+		imageProcessor:  imaging.NewProcessor(photoSizes),
+		presignExpiry:   presignExpiry,
+		messenger:       messenger,
+		surveyURL:       strings.TrimRight(surveyURL, "/"),
+	}
+}
+
+// SentSurvey is a follow-up with its survey link. The link is only returned when it
+// is issued, since only the hash of its token is stored.
+type SentSurvey struct {
+	Adoption  *entities.Adoption `json:"adoption"`
+	SurveyURL string             `json:"survey_url"`
+}
+
+// GetSettings returns the follow-up survey settings, or the default ones
+func (uc *FollowUpUseCase) GetSettings(ctx context.Context) *entities.FollowUpSurveySettings {
+	if uc.settingsRepo == nil {
+		return entities.DefaultFollowUpSurveySettings()
+	}
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil || settings.FollowUpSurvey == nil {
+		return entities.DefaultFollowUpSurveySettings()
+	}
+	return settings.FollowUpSurvey
+}
+
+// UpdateSettings replaces the follow-up survey settings
+func (uc *FollowUpUseCase) UpdateSettings(ctx context.Context, survey *entities.FollowUpSurveySettings, userID primitive.ObjectID) (*entities.FollowUpSurveySettings, error) {
+	if err := uc.checkTemplate(ctx, survey.EmailTemplateID, entities.TemplateTypeEmail); err != nil {
+		return nil, err
+	}
+	if err := uc.checkTemplate(ctx, survey.SMSTemplateID, entities.TemplateTypeSMS); err != nil {
+		return nil, err
+	}
+
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings.FollowUpSurvey = survey
+	settings.UpdatedBy = userID
+	if err := uc.settingsRepo.Update(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "settings", "follow_up_survey", "Updated the follow-up survey settings").
+			WithEntityID(settings.ID))
+
+	return survey, nil
+}
+
+// checkTemplate verifies that a configured message template exists and has the right type
+func (uc *FollowUpUseCase) checkTemplate(ctx context.Context, id *primitive.ObjectID, templateType entities.TemplateType) error {
+	if id == nil {
+		return nil
+	}
+	template, err := uc.templateRepo.FindByID(ctx, *id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return errors.NewBadRequest(fmt.Sprintf("%s template not found", templateType))
+		}
+		return err
+	}
+	if template.Type != templateType {
+		return errors.NewBadRequest(fmt.Sprintf("template %s is not an %s template", template.Name, templateType))
+	}
+	return nil
+}
+
+// ProcessDueFollowUps is the scheduler job sending the surveys of due follow-ups. When
+// several follow-ups of an adoption fell due since the last run, only the latest is sent
+// and the earlier ones are closed as superseded.
+func (uc *FollowUpUseCase) ProcessDueFollowUps(ctx context.Context) error {
+	now := time.Now()
+	adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		FollowUpDueBefore: &now,
+		SortBy:            "adoption_date",
+		SortOrder:         "asc",
+	})
+	if err != nil {
+		return err
+	}
+
+	settings := uc.GetSettings(ctx)
+	for _, adoption := range adoptions {
+		latest := -1
+		for i := range adoption.FollowUpSchedule {
+			followUp := &adoption.FollowUpSchedule[i]
+			if !followUp.IsDue(now) {
+				continue
+			}
+			if latest >= 0 && adoption.FollowUpSchedule[latest].ScheduledDate.After(followUp.ScheduledDate) {
+				uc.supersede(followUp, now)
+				continue
+			}
+			if latest >= 0 {
+				uc.supersede(&adoption.FollowUpSchedule[latest], now)
+			}
+			latest = i
+		}
+		if latest < 0 {
+			continue
+		}
+
+		// Without contact details the follow-up stays due for staff to handle
+		_, _ = uc.send(ctx, adoption, latest, settings, adoption.ProcessedBy, now)
+		adoption.UpdateNextFollowUpDate()
+		adoption.UpdatedAt = now
+		_ = uc.adoptionRepo.Update(ctx, adoption)
+	}
+	return nil
+}
+
+// supersede closes an unsent follow-up replaced by a later one
+func (uc *FollowUpUseCase) supersede(followUp *entities.FollowUpSchedule, now time.Time) {
+	followUp.CompletedDate = &now
+	followUp.Notes = "Not sent: superseded by a later follow-up"
+}
+
+// SendFollowUp sends, or sends again with a new link, the survey of a follow-up
+func (uc *FollowUpUseCase) SendFollowUp(ctx context.Context, adoptionID primitive.ObjectID, index int, userID primitive.ObjectID) (*SentSurvey, error) {
+	adoption, err := uc.adoptionRepo.FindByID(ctx, adoptionID)
+	if err != nil {
+		return nil, err
+	}
+	if adoption.Status != entities.AdoptionStatusPending && adoption.Status != entities.AdoptionStatusCompleted {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the adoption is %s", adoption.Status))
+	}
+	if index < 0 || index >= len(adoption.FollowUpSchedule) {
+		return nil, errors.NewNotFound("follow-up not found")
+	}
+	if adoption.FollowUpSchedule[index].CompletedDate != nil {
+		return nil, errors.NewConflict("the follow-up is already completed")
+	}
+
+	url, err := uc.send(ctx, adoption, index, uc.GetSettings(ctx), userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	adoption.UpdatedBy = userID
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "adoption", "", "follow-up survey sent").
+			WithEntityID(adoption.ID).
+			WithChanges(map[string]interface{}{
+				"follow_up": index,
+				"channel":   adoption.FollowUpSchedule[index].Channel,
+			}))
+
+	return &SentSurvey{Adoption: adoption, SurveyURL: url}, nil
+}
+
+// send issues a new survey link for a follow-up and queues it to the adopter, by email
+// or else by text message. The adoption is not saved.
+func (uc *FollowUpUseCase) send(ctx context.Context, adoption *entities.Adoption, index int, settings *entities.FollowUpSurveySettings, userID primitive.ObjectID, now time.Time) (string, error) {
+	if uc.messenger == nil {
+		return "", errors.NewBadRequest("messaging is not configured")
+	}
+	application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID)
+	if err != nil {
+		return "", err
+	}
+	applicant := application.Applicant
+
+	channel, templateID := entities.TemplateTypeEmail, settings.EmailTemplateID
+	if applicant.Email == "" {
+		if applicant.Phone == "" {
+			return "", errors.NewBadRequest("the adopter has no email address or phone number")
+		}
+		channel, templateID = entities.TemplateTypeSMS, settings.SMSTemplateID
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	expires := now.AddDate(0, 0, settings.LinkValidDays)
+	url := uc.surveyURL + "/" + token
+
+	name := "your pet"
+	if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+		name = animalName(animal)
+	}
+	data := map[string]string{
+		"first_name":   applicant.FirstName,
+		"adopter_name": strings.TrimSpace(applicant.FirstName + " " + applicant.LastName),
+		"animal_name":  name,
+		"survey_url":   url,
+		"expires":      expires.Format("2006-01-02"),
+		"organization": uc.organizationName(ctx),
+	}
+
+	subject, body := surveyMessage(channel, data)
+	var template *entities.CommunicationTemplate
+	if templateID != nil && uc.templateRepo != nil {
+		if t, err := uc.templateRepo.FindByID(ctx, *templateID); err == nil && t.Active {
+			template = t
+			subject, body = t.RenderSubject(data), t.RenderBody(data)
+		}
+	}
+
+	communication := entities.NewCommunication(channel, entities.TemplateCategoryAdoption, applicant.Email, subject, body, userID)
+	communication.RecipientPhone = applicant.Phone
+	communication.RecipientName = data["adopter_name"]
+	communication.RelatedType = "adoption"
+	communication.RelatedID = &adoption.ID
+	communication.Metadata["follow_up"] = strconv.Itoa(index)
+	if template != nil {
+		communication.TemplateID = &template.ID
+	}
+	if err := uc.messenger.CreateCommunication(ctx, communication, userID); err != nil {
+		return "", err
+	}
+	if template != nil {
+		_ = uc.templateRepo.IncrementUsage(ctx, template.ID)
+	}
+
+	followUp := &adoption.FollowUpSchedule[index]
+	followUp.SentAt = &now
+	followUp.Channel = channel
+	followUp.CommunicationID = &communication.ID
+	followUp.TokenHash = entities.HashToken(token)
+	followUp.TokenExpiresAt = &expires
+	return url, nil
+}
+
+// newToken returns a random survey link token
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, 500, "failed to generate survey token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// surveyMessage returns the built-in subject and body of the survey invitation
+func surveyMessage(channel entities.TemplateType, data map[string]string) (string, string) {
+	greeting := "Hello"
+	if data["first_name"] != "" {
+		greeting += " " + data["first_name"]
+	}
+	if channel == entities.TemplateTypeSMS {
+		return "", fmt.Sprintf("%s, how is %s settling in? Tell us in a short survey: %s", greeting, data["animal_name"], data["survey_url"])
+	}
+
+	signature := ""
+	if data["organization"] != "" {
+		signature = "\n\n" + data["organization"]
+	}
+	return fmt.Sprintf("How is %s settling in?", data["animal_name"]),
+		fmt.Sprintf("%s,\n\nwe would love to hear how %s is doing in their new home. "+
+			"Please take a few minutes to answer our short survey and share a photo or two:\n\n%s\n\n"+
+			"If anything worries you, let us know in the survey and we will get in touch. The link is valid until %s.%s",
+			greeting, data["animal_name"], data["survey_url"], data["expires"], signature)
+}
+
+// organizationName returns the foundation name signing messages to adopters
+func (uc *FollowUpUseCase) organizationName(ctx context.Context) string {
+	if uc.settingsRepo == nil {
+		return ""
+	}
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil {
+		return ""
+	}
+	return settings.Name
+}
+
+// animalName returns the name of the animal used in messages
+func animalName(animal *entities.Animal) string {
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	if animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	return "your pet"
+}
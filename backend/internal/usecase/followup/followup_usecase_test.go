@@ -0,0 +1,241 @@
+package followup
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const surveyURL = "https://shelter.example.org/follow-ups"
+
+type followUpMocks struct {
+	adoptions    *mocks.AdoptionRepository
+	applications *mocks.AdoptionApplicationRepository
+	animals      *mocks.AnimalRepository
+	tasks        *mocks.TaskRepository
+	messenger    *testutil.Messenger
+}
+
+func newFollowUpUseCase() (*FollowUpUseCase, *followUpMocks) {
+	m := &followUpMocks{
+		adoptions:    new(mocks.AdoptionRepository),
+		applications: new(mocks.AdoptionApplicationRepository),
+		animals:      new(mocks.AnimalRepository),
+		tasks:        new(mocks.TaskRepository),
+		messenger:    &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+	m.adoptions.On("Update", mock.Anything, mock.Anything).Return(nil)
+	return NewFollowUpUseCase(m.adoptions, m.applications, m.animals, nil, nil, m.tasks, auditLogs, nil, 0, m.messenger, surveyURL+"/"), m
+}
+
+// adopted returns an adoption of Rex with follow-ups scheduled the given number of days from now
+func adopted(m *followUpMocks, applicant entities.ApplicantInfo, followUps ...int) *entities.Adoption {
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: "Rex"}, Status: entities.AnimalStatusAdopted}
+	application := &entities.AdoptionApplication{ID: primitive.NewObjectID(), AnimalID: animal.ID, Applicant: applicant}
+	adoption := entities.NewAdoption(application.ID, animal.ID, primitive.NewObjectID(), 200, primitive.NewObjectID())
+	adoption.ID = primitive.NewObjectID()
+	adoption.Status = entities.AdoptionStatusCompleted
+	for _, days := range followUps {
+		adoption.FollowUpSchedule = append(adoption.FollowUpSchedule, entities.FollowUpSchedule{
+			ScheduledDate: time.Now().AddDate(0, 0, days),
+			Type:          entities.FollowUpTypeSurvey,
+		})
+	}
+
+	m.animals.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	m.applications.On("FindByID", mock.Anything, application.ID).Return(application, nil)
+	m.adoptions.On("FindByID", mock.Anything, adoption.ID).Return(adoption, nil)
+	return adoption
+}
+
+func TestProcessDueFollowUps_SendsTheLatestDueSurvey(t *testing.T) {
+	uc, m := newFollowUpUseCase()
+	adoption := adopted(m, entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak", Email: "anna@example.com"}, -20, -3, 60)
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AdoptionFilter) bool {
+		return f.FollowUpDueBefore != nil
+	})).Return([]*entities.Adoption{adoption}, int64(1), nil)
+
+	require.NoError(t, uc.ProcessDueFollowUps(context.Background()))
+
+	require.Len(t, m.messenger.Sent, 1)
+	message := m.messenger.Sent[0]
+	assert.Equal(t, entities.TemplateTypeEmail, message.Type)
+	assert.Equal(t, "anna@example.com", message.RecipientEmail)
+	assert.Contains(t, message.Subject, "Rex")
+	token := testutil.LinkToken(t, message, surveyURL)
+
+	superseded, sent, upcoming := adoption.FollowUpSchedule[0], adoption.FollowUpSchedule[1], adoption.FollowUpSchedule[2]
+	assert.NotNil(t, superseded.CompletedDate)
+	assert.Nil(t, superseded.SentAt)
+	require.NotNil(t, sent.SentAt)
+	assert.Nil(t, sent.CompletedDate)
+	assert.Equal(t, entities.HashToken(token), sent.TokenHash)
+	assert.Equal(t, &message.ID, sent.CommunicationID)
+	assert.Nil(t, upcoming.SentAt)
+	assert.Equal(t, upcoming.ScheduledDate, *adoption.NextFollowUpDate)
+}
+
+func TestSubmitSurvey_EscalatesConcerns(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newFollowUpUseCase()
+	adoption := adopted(m, entities.ApplicantInfo{FirstName: "Anna", Phone: "+48500100200"}, -1)
+
+	sent, err := uc.SendFollowUp(ctx, adoption.ID, 0, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, entities.TemplateTypeSMS, adoption.FollowUpSchedule[0].Channel)
+	token := strings.TrimPrefix(sent.SurveyURL, surveyURL+"/")
+	m.adoptions.On("FindByFollowUpToken", mock.Anything, entities.HashToken(token)).Return(adoption, nil)
+
+	view, err := uc.ViewSurvey(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "Rex", view.AnimalName)
+	assert.Equal(t, "Anna", view.AdopterName)
+
+	var task *entities.Task
+	m.tasks.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		task = args.Get(1).(*entities.Task)
+		task.ID = primitive.NewObjectID()
+	}).Return(nil)
+
+	receipt, err := uc.SubmitSurvey(ctx, token, &SubmitSurveyRequest{
+		Settling:          entities.FollowUpSettlingStruggling,
+		EatingWell:        true,
+		BehaviorConcerns:  "Growls at visitors",
+		ConsideringReturn: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, receipt.FollowUp)
+
+	followUp := adoption.FollowUpSchedule[0]
+	require.NotNil(t, followUp.Survey)
+	assert.NotNil(t, followUp.CompletedDate)
+	assert.Len(t, followUp.Survey.Concerns, 3)
+	require.NotNil(t, task)
+	assert.Equal(t, &task.ID, followUp.EscalationTaskID)
+	assert.Equal(t, entities.TaskPriorityUrgent, task.Priority)
+	assert.Contains(t, task.Description, "Growls at visitors")
+	assert.Contains(t, task.Tags, Tag)
+
+	// The link works once
+	_, err = uc.SubmitSurvey(ctx, token, &SubmitSurveyRequest{Settling: entities.FollowUpSettlingWell, EatingWell: true})
+	assert.Equal(t, http.StatusNotFound, err.(*errors.AppError).Code)
+}
+
+func TestUploadPhotos_DropsTheLocation(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newFollowUpUseCase()
+	dir := t.TempDir()
+	uc.storageService = storage.NewStorageService(storage.NewLocalBackend(dir), "/uploads", 1<<20)
+	adoption := adopted(m, entities.ApplicantInfo{FirstName: "Anna", Email: "anna@example.com"}, -1)
+
+	sent, err := uc.SendFollowUp(ctx, adoption.ID, 0, primitive.NewObjectID())
+	require.NoError(t, err)
+	token := strings.TrimPrefix(sent.SurveyURL, surveyURL+"/")
+	m.adoptions.On("FindByFollowUpToken", mock.Anything, entities.HashToken(token)).Return(adoption, nil)
+
+	photos, err := uc.UploadPhotos(ctx, token, []*multipart.FileHeader{photoHeader(t, withGPS(t))})
+	require.NoError(t, err)
+
+	require.Len(t, photos, 1)
+	assert.True(t, strings.HasPrefix(photos[0], "/uploads/follow-ups/"+adoption.ID.Hex()+"/"))
+	assert.True(t, strings.HasSuffix(photos[0], ".webp"))
+	stored, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(photos[0], "/uploads/")))
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "Exif")
+	assert.NotContains(t, string(stored), "50.0614N")
+
+	view, err := uc.GetPhotos(ctx, adoption.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, photos, view.Photos)
+	assert.Nil(t, view.ExpiresAt, "files on local disk are not pre-signed")
+}
+
+// withGPS returns a JPEG with an EXIF block carrying a position
+func withGPS(t *testing.T) []byte {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil))
+	data := encoded.Bytes()
+
+	payload := append([]byte("Exif\x00\x00"), []byte("GPS 50.0614N 19.9366E")...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func photoHeader(t *testing.T, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("photos", "garden.jpg")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File["photos"][0]
+}
+
+func TestSubmitSurvey_ExpiredLink(t *testing.T) {
+	uc, m := newFollowUpUseCase()
+	adoption := adopted(m, entities.ApplicantInfo{FirstName: "Anna", Email: "anna@example.com"}, -30)
+	sentAt := time.Now().AddDate(0, 0, -30)
+	expired := time.Now().AddDate(0, 0, -16)
+	followUp := &adoption.FollowUpSchedule[0]
+	followUp.SentAt, followUp.TokenExpiresAt, followUp.TokenHash = &sentAt, &expired, entities.HashToken("old")
+	m.adoptions.On("FindByFollowUpToken", mock.Anything, entities.HashToken("old")).Return(adoption, nil)
+
+	_, err := uc.SubmitSurvey(context.Background(), "old", &SubmitSurveyRequest{Settling: entities.FollowUpSettlingWell, EatingWell: true})
+	assert.Equal(t, http.StatusGone, err.(*errors.AppError).Code)
+	assert.Nil(t, followUp.Survey)
+}
+
+func TestGetResponseReport(t *testing.T) {
+	uc, m := newFollowUpUseCase()
+	now := time.Now()
+	at := func(days int) *time.Time { t := now.AddDate(0, 0, days); return &t }
+
+	adoption := entities.NewAdoption(primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), 200, primitive.NewObjectID())
+	adoption.FollowUpSchedule = []entities.FollowUpSchedule{
+		{SentAt: at(-20), Channel: entities.TemplateTypeEmail, CompletedDate: at(-18), Photos: []string{"a.jpg"},
+			Survey: &entities.FollowUpSurvey{Settling: entities.FollowUpSettlingVeryWell, EatingWell: true, SubmittedAt: *at(-18)}},
+		{SentAt: at(-10), Channel: entities.TemplateTypeSMS, CompletedDate: at(-9),
+			Survey: &entities.FollowUpSurvey{Settling: entities.FollowUpSettlingStruggling, SubmittedAt: *at(-9), Concerns: []string{"The animal is struggling to settle in"}}},
+		{SentAt: at(-40), Channel: entities.TemplateTypeEmail, TokenExpiresAt: at(-26)},
+		{SentAt: at(-2), Channel: entities.TemplateTypeEmail, TokenExpiresAt: at(12)},
+		{SentAt: at(-200), Channel: entities.TemplateTypeEmail}, // Outside the period
+	}
+	m.adoptions.On("List", mock.Anything, mock.Anything).Return([]*entities.Adoption{adoption}, int64(1), nil)
+
+	report, err := uc.GetResponseReport(context.Background(), now.AddDate(0, 0, -90), now)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Sent)
+	assert.Equal(t, 2, report.Responded)
+	assert.Equal(t, 1, report.Awaiting)
+	assert.Equal(t, 1, report.Unanswered)
+	assert.Equal(t, 50.0, report.ResponseRate)
+	assert.Equal(t, 1.5, report.AverageResponseDays)
+	assert.Equal(t, 1, report.WithPhotos)
+	assert.Equal(t, 1, report.Concerning)
+	assert.Equal(t, 100.0, report.ByChannel[entities.TemplateTypeSMS].ResponseRate)
+	assert.Equal(t, 3, report.ByChannel[entities.TemplateTypeEmail].Sent)
+}
@@ -0,0 +1,123 @@
+package followup
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ResponseReport summarizes the follow-up surveys sent in a period
+type ResponseReport struct {
+	From                time.Time                          `json:"from"`
+	To                  time.Time                          `json:"to"`
+	Sent                int                                `json:"sent"`
+	Responded           int                                `json:"responded"`
+	Awaiting            int                                `json:"awaiting"`      // Link still open
+	Unanswered          int                                `json:"unanswered"`    // Link expired or follow-up closed by staff
+	ResponseRate        float64                            `json:"response_rate"` // Percentage of the surveys sent
+	AverageResponseDays float64                            `json:"average_response_days"`
+	WithPhotos          int                                `json:"with_photos"`
+	Concerning          int                                `json:"concerning"`
+	BySettling          map[entities.FollowUpSettling]int  `json:"by_settling"`
+	ByChannel           map[entities.TemplateType]*Channel `json:"by_channel"`
+	ConcerningResponses []ConcerningResponse               `json:"concerning_responses"`
+}
+
+// Channel is the response rate of the surveys sent through a channel
+type Channel struct {
+	Sent         int     `json:"sent"`
+	Responded    int     `json:"responded"`
+	ResponseRate float64 `json:"response_rate"`
+}
+
+// ConcerningResponse is a survey answer escalated to staff
+type ConcerningResponse struct {
+	AdoptionID  primitive.ObjectID  `json:"adoption_id"`
+	AnimalID    primitive.ObjectID  `json:"animal_id"`
+	SubmittedAt time.Time           `json:"submitted_at"`
+	Concerns    []string            `json:"concerns"`
+	TaskID      *primitive.ObjectID `json:"task_id,omitempty"`
+}
+
+// GetResponseReport reports the response rate of the follow-up surveys sent in a period
+func (uc *FollowUpUseCase) GetResponseReport(ctx context.Context, from, to time.Time) (*ResponseReport, error) {
+	adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		FollowUpSentFromDate: &from,
+		FollowUpSentToDate:   &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ResponseReport{
+		From:                from,
+		To:                  to,
+		BySettling:          make(map[entities.FollowUpSettling]int),
+		ByChannel:           make(map[entities.TemplateType]*Channel),
+		ConcerningResponses: []ConcerningResponse{},
+	}
+
+	now := time.Now()
+	totalDays := 0.0
+	for _, adoption := range adoptions {
+		for _, followUp := range adoption.FollowUpSchedule {
+			if followUp.SentAt == nil || followUp.SentAt.Before(from) || followUp.SentAt.After(to) {
+				continue
+			}
+			report.Sent++
+			channel := report.ByChannel[followUp.Channel]
+			if channel == nil {
+				channel = &Channel{}
+				report.ByChannel[followUp.Channel] = channel
+			}
+			channel.Sent++
+
+			survey := followUp.Survey
+			switch {
+			case survey != nil:
+				report.Responded++
+				channel.Responded++
+				totalDays += survey.SubmittedAt.Sub(*followUp.SentAt).Hours() / 24
+				report.BySettling[survey.Settling]++
+				if len(followUp.Photos) > 0 {
+					report.WithPhotos++
+				}
+				if len(survey.Concerns) > 0 {
+					report.Concerning++
+					report.ConcerningResponses = append(report.ConcerningResponses, ConcerningResponse{
+						AdoptionID:  adoption.ID,
+						AnimalID:    adoption.AnimalID,
+						SubmittedAt: survey.SubmittedAt,
+						Concerns:    survey.Concerns,
+						TaskID:      followUp.EscalationTaskID,
+					})
+				}
+			case followUp.LinkOpen(now):
+				report.Awaiting++
+			default:
+				report.Unanswered++
+			}
+		}
+	}
+
+	report.ResponseRate = rate(report.Responded, report.Sent)
+	for _, channel := range report.ByChannel {
+		channel.ResponseRate = rate(channel.Responded, channel.Sent)
+	}
+	if report.Responded > 0 {
+		report.AverageResponseDays = math.Round(totalDays/float64(report.Responded)*10) / 10
+	}
+	return report, nil
+}
+
+// rate returns a percentage rounded to one decimal
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*1000) / 10
+}
@@ -0,0 +1,303 @@
+package followup
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxPhotos is the number of photos an adopter can upload with a survey
+const MaxPhotos = 5
+
+// SurveyView is what the adopter sees on the survey page
+type SurveyView struct {
+	AnimalName    string    `json:"animal_name"`
+	AdopterName   string    `json:"adopter_name"`
+	Organization  string    `json:"organization,omitempty"`
+	ScheduledDate time.Time `json:"scheduled_date"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Photos        []string  `json:"photos"`
+	MaxPhotos     int       `json:"max_photos"`
+}
+
+// SubmitSurveyRequest is the adopter's answers to a follow-up survey
+type SubmitSurveyRequest struct {
+	Settling          entities.FollowUpSettling `json:"settling" validate:"required,oneof=very_well well struggling not_settled"`
+	EatingWell        bool                      `json:"eating_well"`
+	HealthConcerns    string                    `json:"health_concerns,omitempty" validate:"max=2000"`
+	BehaviorConcerns  string                    `json:"behavior_concerns,omitempty" validate:"max=2000"`
+	ConsideringReturn bool                      `json:"considering_return"`
+	Comments          string                    `json:"comments,omitempty" validate:"max=4000"`
+}
+
+// SurveyReceipt confirms a submitted survey to the adopter
+type SurveyReceipt struct {
+	SubmittedAt time.Time `json:"submitted_at"`
+	Photos      int       `json:"photos"`
+	FollowUp    bool      `json:"follow_up"` // Staff will get in touch about the concerns
+}
+
+// SurveyPhotos are the links through which staff see the photos of a follow-up
+type SurveyPhotos struct {
+	Photos    []string   `json:"photos"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Set when the links are pre-signed
+}
+
+// ViewSurvey opens the survey of a follow-up through its link
+func (uc *FollowUpUseCase) ViewSurvey(ctx context.Context, token string) (*SurveyView, error) {
+	adoption, followUp, err := uc.openLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &SurveyView{
+		AnimalName:    "your pet",
+		Organization:  uc.organizationName(ctx),
+		ScheduledDate: followUp.ScheduledDate,
+		ExpiresAt:     *followUp.TokenExpiresAt,
+		Photos:        []string{},
+		MaxPhotos:     MaxPhotos,
+	}
+	if len(followUp.Photos) > 0 && uc.storageService != nil {
+		if view.Photos, err = uc.photoLinks(ctx, followUp.Photos); err != nil {
+			return nil, err
+		}
+	}
+	if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+		view.AnimalName = animalName(animal)
+	}
+	if application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID); err == nil {
+		view.AdopterName = application.Applicant.FirstName
+	}
+	return view, nil
+}
+
+// UploadPhotos adds photos of the animal to a survey before it is submitted
+func (uc *FollowUpUseCase) UploadPhotos(ctx context.Context, token string, files []*multipart.FileHeader) ([]string, error) {
+	if len(files) == 0 {
+		return nil, errors.NewBadRequest("no photos uploaded")
+	}
+	if uc.storageService == nil {
+		return nil, errors.NewBadRequest("photo uploads are not configured")
+	}
+	adoption, followUp, err := uc.openLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(followUp.Photos)+len(files) > MaxPhotos {
+		return nil, errors.NewBadRequest(fmt.Sprintf("at most %d photos can be uploaded", MaxPhotos))
+	}
+
+	urls := make([]string, 0, len(files))
+	for _, file := range files {
+		url, err := uc.savePhoto(ctx, adoption.ID, file)
+		if err != nil {
+			_ = uc.storageService.DeleteMultipleFiles(ctx, urls)
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	followUp.Photos = append(followUp.Photos, urls...)
+	adoption.UpdatedAt = time.Now()
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		_ = uc.storageService.DeleteMultipleFiles(ctx, urls)
+		return nil, err
+	}
+	return uc.photoLinks(ctx, followUp.Photos)
+}
+
+// savePhoto re-encodes an uploaded photo, which drops its metadata such as the GPS
+// position of the adopter's home, and stores it with the follow-ups of the adoption
+func (uc *FollowUpUseCase) savePhoto(ctx context.Context, adoptionID primitive.ObjectID, file *multipart.FileHeader) (string, error) {
+	if uc.storageService.ExceedsMaxFileSize(file.Size) {
+		return "", errors.NewBadRequest("photo file is too large")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", errors.Wrap(err, 500, "failed to open uploaded file")
+	}
+	defer src.Close()
+
+	result, err := uc.imageProcessor.Process(src)
+	if err != nil {
+		return "", err
+	}
+	return uc.storageService.SaveFile(ctx, result.Variants[0].Data, photoFolder(adoptionID), primitive.NewObjectID().Hex()+".webp")
+}
+
+// photoLinks returns pre-signed links to stored follow-up photos, which are not public
+func (uc *FollowUpUseCase) photoLinks(ctx context.Context, photos []string) ([]string, error) {
+	links := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		link, err := uc.storageService.PresignedURL(ctx, photo, uc.presignExpiry)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// GetPhotos returns links to the photos the adopter uploaded with a follow-up survey
+func (uc *FollowUpUseCase) GetPhotos(ctx context.Context, adoptionID primitive.ObjectID, index int) (*SurveyPhotos, error) {
+	adoption, err := uc.adoptionRepo.FindByID(ctx, adoptionID)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(adoption.FollowUpSchedule) {
+		return nil, errors.NewNotFound("follow-up not found")
+	}
+
+	photos := adoption.FollowUpSchedule[index].Photos
+	result := &SurveyPhotos{Photos: []string{}}
+	if len(photos) == 0 || uc.storageService == nil {
+		return result, nil
+	}
+	if result.Photos, err = uc.photoLinks(ctx, photos); err != nil {
+		return nil, err
+	}
+	if result.Photos[0] != photos[0] {
+		expiresAt := time.Now().Add(uc.presignExpiry)
+		result.ExpiresAt = &expiresAt
+	}
+	return result, nil
+}
+
+// photoFolder returns the storage folder holding the follow-up photos of an adoption
+func photoFolder(adoptionID primitive.ObjectID) string {
+	return "follow-ups/" + adoptionID.Hex()
+}
+
+// SubmitSurvey stores the adopter's answers, completes the follow-up and escalates
+// concerning answers to the staff member who processed the adoption. The link cannot
+// be used again.
+func (uc *FollowUpUseCase) SubmitSurvey(ctx context.Context, token string, req *SubmitSurveyRequest) (*SurveyReceipt, error) {
+	adoption, followUp, err := uc.openLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	survey := &entities.FollowUpSurvey{
+		Settling:          req.Settling,
+		EatingWell:        req.EatingWell,
+		HealthConcerns:    strings.TrimSpace(req.HealthConcerns),
+		BehaviorConcerns:  strings.TrimSpace(req.BehaviorConcerns),
+		ConsideringReturn: req.ConsideringReturn,
+		Comments:          strings.TrimSpace(req.Comments),
+		SubmittedAt:       now,
+	}
+	survey.Concerns = survey.Assess()
+
+	followUp.Survey = survey
+	followUp.CompletedDate = &now
+	followUp.TokenHash = ""
+	if len(survey.Concerns) > 0 {
+		followUp.EscalationTaskID = uc.escalate(ctx, adoption, survey, now)
+	}
+	adoption.UpdateNextFollowUpDate()
+	adoption.UpdatedAt = now
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		return nil, err
+	}
+
+	// The adopter has no user account, so the entry is on behalf of the staff member who processed the adoption
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(adoption.ProcessedBy, entities.ActionUpdate, "adoption", "", "follow-up survey answered by the adopter").
+			WithEntityID(adoption.ID).
+			WithChanges(map[string]interface{}{
+				"settling": survey.Settling,
+				"concerns": len(survey.Concerns),
+			}))
+
+	return &SurveyReceipt{SubmittedAt: now, Photos: len(followUp.Photos), FollowUp: len(survey.Concerns) > 0}, nil
+}
+
+// openLink finds the follow-up of a survey link that can still be answered
+func (uc *FollowUpUseCase) openLink(ctx context.Context, token string) (*entities.Adoption, *entities.FollowUpSchedule, error) {
+	hash := entities.HashToken(token)
+	adoption, err := uc.adoptionRepo.FindByFollowUpToken(ctx, hash)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, nil, errLinkInvalid
+		}
+		return nil, nil, err
+	}
+
+	for i := range adoption.FollowUpSchedule {
+		followUp := &adoption.FollowUpSchedule[i]
+		if followUp.TokenHash != hash {
+			continue
+		}
+		if !followUp.LinkOpen(time.Now()) {
+			return nil, nil, errLinkExpired
+		}
+		return adoption, followUp, nil
+	}
+	return nil, nil, errLinkInvalid
+}
+
+// escalate creates the task asking staff to contact an adopter who reported concerns
+func (uc *FollowUpUseCase) escalate(ctx context.Context, adoption *entities.Adoption, survey *entities.FollowUpSurvey, now time.Time) *primitive.ObjectID {
+	if uc.taskRepo == nil {
+		return nil
+	}
+
+	name := adoption.AnimalID.Hex()
+	if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+		name = animalName(animal)
+	}
+	var contact []string
+	if application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID); err == nil {
+		applicant := application.Applicant
+		contact = append(contact, strings.TrimSpace(applicant.FirstName+" "+applicant.LastName))
+		if applicant.Phone != "" {
+			contact = append(contact, applicant.Phone)
+		}
+		if applicant.Email != "" {
+			contact = append(contact, applicant.Email)
+		}
+	}
+
+	priority := entities.TaskPriorityHigh
+	if survey.ConsideringReturn {
+		priority = entities.TaskPriorityUrgent
+	}
+	task := entities.NewTask(fmt.Sprintf("Follow-up concerns about %s", name), entities.TaskCategoryAdoption, priority, adoption.ProcessedBy)
+	task.Description = fmt.Sprintf("The adopter of %s reported in the follow-up survey:\n- %s", name, strings.Join(survey.Concerns, "\n- "))
+	if survey.Comments != "" {
+		task.Description += "\n\nComments: " + survey.Comments
+	}
+	if len(contact) > 0 {
+		task.Description += "\n\nContact: " + strings.Join(contact, ", ")
+	}
+	task.RelatedEntity = "adoption"
+	task.RelatedEntityID = &adoption.ID
+	task.Tags = []string{Tag, Tag + ":" + adoption.ID.Hex()}
+	dueDate := now.AddDate(0, 0, escalationDays)
+	task.DueDate = &dueDate
+	if !adoption.ProcessedBy.IsZero() {
+		task.AssignTo(adoption.ProcessedBy)
+	}
+	task.AddChecklistItem("Contact the adopter")
+	task.AddChecklistItem("Record the outcome on the adoption")
+
+	if err := uc.taskRepo.Create(ctx, task); err != nil {
+		return nil
+	}
+	return &task.ID
+}
+
+// errLinkInvalid is returned for a survey link that does not open an unanswered follow-up
+var errLinkInvalid = errors.NewNotFound("the survey link is invalid or the survey was already answered")
+
+// errLinkExpired is returned for a survey link past its expiry
+var errLinkExpired = errors.New(http.StatusGone, "the survey link has expired")
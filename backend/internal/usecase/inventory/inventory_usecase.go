@@ -209,8 +209,13 @@ func (uc *InventoryUseCase) GetInventoryStatistics(ctx context.Context) (*reposi
 	return uc.inventoryRepo.GetInventoryStatistics(ctx)
 }
 
-// AddStock adds stock to an inventory item
+// AddStock adds untracked stock to an inventory item
 func (uc *InventoryUseCase) AddStock(ctx context.Context, itemID primitive.ObjectID, quantity, unitCost float64, userID primitive.ObjectID, reference, notes string) error {
+	return uc.AddStockLot(ctx, itemID, quantity, unitCost, "", nil, userID, reference, notes)
+}
+
+// AddStockLot adds stock to an inventory item, tracking it as a lot when a lot number or expiry is given
+func (uc *InventoryUseCase) AddStockLot(ctx context.Context, itemID primitive.ObjectID, quantity, unitCost float64, lotNumber string, expirationDate *time.Time, userID primitive.ObjectID, reference, notes string) error {
 	if quantity <= 0 {
 		return errors.NewBadRequest("Quantity must be greater than 0")
 	}
@@ -228,6 +233,15 @@ func (uc *InventoryUseCase) AddStock(ctx context.Context, itemID primitive.Objec
 
 	// Add stock to item
 	item.AddStock(quantity, unitCost)
+	if lotNumber != "" || expirationDate != nil {
+		item.AddLot(entities.StockLot{
+			LotNumber:      lotNumber,
+			ExpirationDate: expirationDate,
+			Quantity:       quantity,
+			UnitCost:       unitCost,
+			ReceivedAt:     time.Now(),
+		})
+	}
 
 	if err := uc.inventoryRepo.Update(ctx, item); err != nil {
 		return err
@@ -236,6 +250,8 @@ func (uc *InventoryUseCase) AddStock(ctx context.Context, itemID primitive.Objec
 	// Create stock transaction
 	transaction := entities.NewStockTransaction(itemID, entities.TransactionTypeIn, quantity, stockBefore, userID)
 	transaction.SetCost(unitCost)
+	transaction.LotNumber = lotNumber
+	transaction.ExpirationDate = expirationDate
 	transaction.Reference = reference
 	transaction.Notes = notes
 
@@ -253,50 +269,125 @@ func (uc *InventoryUseCase) AddStock(ctx context.Context, itemID primitive.Objec
 
 // RemoveStock removes stock from an inventory item
 func (uc *InventoryUseCase) RemoveStock(ctx context.Context, itemID primitive.ObjectID, quantity float64, userID primitive.ObjectID, reason, reference, notes string) error {
-	if quantity <= 0 {
-		return errors.NewBadRequest("Quantity must be greater than 0")
+	_, err := uc.ConsumeStock(ctx, &entities.StockConsumption{
+		ItemID:    itemID,
+		Quantity:  quantity,
+		Reason:    reason,
+		Reference: reference,
+		Notes:     notes,
+	}, userID)
+	return err
+}
+
+// ConsumeStock removes stock first-expired-first-out and records one outgoing
+// transaction per lot used, costed at the lot's unit cost
+func (uc *InventoryUseCase) ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	if consumption.Quantity <= 0 {
+		return nil, errors.NewBadRequest("Quantity must be greater than 0")
 	}
 
-	item, err := uc.inventoryRepo.FindByID(ctx, itemID)
+	item, err := uc.inventoryRepo.FindByID(ctx, consumption.ItemID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !item.IsActive {
-		return errors.NewBadRequest("Cannot remove stock from inactive item")
+		return nil, errors.NewBadRequest("Cannot remove stock from inactive item")
 	}
 
-	if item.CurrentStock < quantity {
-		return errors.NewBadRequest("Insufficient stock")
+	if item.CurrentStock < consumption.Quantity {
+		return nil, errors.NewBadRequest("Insufficient stock")
 	}
 
 	stockBefore := item.CurrentStock
 
-	// Remove stock from item
-	if !item.RemoveStock(quantity) {
-		return errors.NewBadRequest("Failed to remove stock")
+	lots, err := item.ConsumeFEFO(consumption.Quantity, consumption.LotNumber, time.Now())
+	if err != nil {
+		return nil, errors.NewBadRequest(err.Error())
 	}
 
 	if err := uc.inventoryRepo.Update(ctx, item); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Create stock transaction
-	transaction := entities.NewStockTransaction(itemID, entities.TransactionTypeOut, quantity, stockBefore, userID)
-	transaction.Reason = reason
-	transaction.Reference = reference
-	transaction.Notes = notes
-
-	if err := uc.stockTransactionRepo.Create(ctx, transaction); err != nil {
-		return err
+	// Create one stock transaction per lot so each carries its own cost and expiry
+	transactions := make([]*entities.StockTransaction, 0, len(lots))
+	for _, lot := range lots {
+		transaction := entities.NewStockTransaction(item.ID, entities.TransactionTypeOut, lot.Quantity, stockBefore, userID)
+		transaction.SetCost(lot.UnitCost)
+		transaction.LotNumber = lot.LotNumber
+		transaction.ExpirationDate = lot.ExpirationDate
+		transaction.AnimalID = consumption.AnimalID
+		if consumption.RelatedEntityID != nil {
+			transaction.SetRelatedEntity(consumption.RelatedEntity, *consumption.RelatedEntityID)
+		}
+		transaction.Reason = consumption.Reason
+		transaction.Reference = consumption.Reference
+		transaction.Notes = consumption.Notes
+
+		if err := uc.stockTransactionRepo.Create(ctx, transaction); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+		stockBefore = transaction.StockAfter
 	}
 
 	// Create audit log
 	_ = uc.auditLogRepo.Create(ctx,
 		entities.NewAuditLog(userID, entities.ActionUpdate, "inventory", item.Name, "removed stock").
-			WithEntityID(itemID))
+			WithEntityID(item.ID))
 
-	return nil
+	return transactions, nil
+}
+
+// ReturnStock puts stock taken out by ConsumeStock back into the lots it came from. It is
+// used when the record the stock was consumed for could not be saved; every transaction
+// is returned even if one fails, and the first error is reported.
+func (uc *InventoryUseCase) ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error {
+	var firstErr error
+	for _, transaction := range transactions {
+		err := uc.AddStockLot(ctx, transaction.ItemID, transaction.Quantity, transaction.UnitCost,
+			transaction.LotNumber, transaction.ExpirationDate, userID, transaction.Reference, reason)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// AnimalCostOfCare is the inventory consumed for an animal's care
+type AnimalCostOfCare struct {
+	AnimalID   primitive.ObjectID             `json:"animal_id"`
+	From       *time.Time                     `json:"from,omitempty"`
+	To         *time.Time                     `json:"to,omitempty"`
+	TotalCost  float64                        `json:"total_cost"`
+	ByCategory map[string]float64             `json:"by_category"`
+	Items      []*repositories.CostOfCareItem `json:"items"`
+}
+
+// GetAnimalCostOfCare rolls up the cost of stock consumed for an animal, optionally within a date range
+func (uc *InventoryUseCase) GetAnimalCostOfCare(ctx context.Context, animalID primitive.ObjectID, from, to *time.Time) (*AnimalCostOfCare, error) {
+	items, err := uc.stockTransactionRepo.GetAnimalCostOfCare(ctx, animalID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := &AnimalCostOfCare{
+		AnimalID:   animalID,
+		From:       from,
+		To:         to,
+		ByCategory: make(map[string]float64),
+		Items:      items,
+	}
+	if rollup.Items == nil {
+		rollup.Items = []*repositories.CostOfCareItem{}
+	}
+	for _, item := range items {
+		rollup.TotalCost += item.TotalCost
+		rollup.ByCategory[item.Category] += item.TotalCost
+	}
+
+	return rollup, nil
 }
 
 // AdjustStock adjusts stock (inventory count correction)
@@ -318,6 +409,7 @@ func (uc *InventoryUseCase) AdjustStock(ctx context.Context, itemID primitive.Ob
 	item.TotalValue = item.CurrentStock * item.UnitCost
 	item.UpdatedAt = time.Now()
 	item.CheckLowStock()
+	item.ReconcileLots()
 
 	if err := uc.inventoryRepo.Update(ctx, item); err != nil {
 		return err
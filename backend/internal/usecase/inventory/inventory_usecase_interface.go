@@ -2,6 +2,7 @@ package inventory
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
@@ -23,7 +24,11 @@ type IInventoryUseCase interface {
 	GetItemsNeedingReorder(ctx context.Context) ([]*entities.InventoryItem, error)
 	GetInventoryStatistics(ctx context.Context) (*repositories.InventoryStatistics, error)
 	AddStock(ctx context.Context, itemID primitive.ObjectID, quantity, unitCost float64, userID primitive.ObjectID, reference, notes string) error
+	AddStockLot(ctx context.Context, itemID primitive.ObjectID, quantity, unitCost float64, lotNumber string, expirationDate *time.Time, userID primitive.ObjectID, reference, notes string) error
 	RemoveStock(ctx context.Context, itemID primitive.ObjectID, quantity float64, userID primitive.ObjectID, reason, reference, notes string) error
+	ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error)
+	ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error
+	GetAnimalCostOfCare(ctx context.Context, animalID primitive.ObjectID, from, to *time.Time) (*AnimalCostOfCare, error)
 	AdjustStock(ctx context.Context, itemID primitive.ObjectID, newQuantity float64, userID primitive.ObjectID, reason, notes string) error
 	ActivateItem(ctx context.Context, itemID, userID primitive.ObjectID) error
 	DeactivateItem(ctx context.Context, itemID, userID primitive.ObjectID) error
@@ -2,9 +2,11 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/usecase/inventory"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -88,6 +90,32 @@ func (m *InventoryUseCase) RemoveStock(ctx context.Context, itemID primitive.Obj
 	return args.Error(0)
 }
 
+func (m *InventoryUseCase) AddStockLot(ctx context.Context, itemID primitive.ObjectID, quantity, unitCost float64, lotNumber string, expirationDate *time.Time, userID primitive.ObjectID, reference, notes string) error {
+	args := m.Called(ctx, itemID, quantity, unitCost, lotNumber, expirationDate, userID, reference, notes)
+	return args.Error(0)
+}
+
+func (m *InventoryUseCase) ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	args := m.Called(ctx, consumption, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.StockTransaction), args.Error(1)
+}
+
+func (m *InventoryUseCase) ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error {
+	args := m.Called(ctx, transactions, userID, reason)
+	return args.Error(0)
+}
+
+func (m *InventoryUseCase) GetAnimalCostOfCare(ctx context.Context, animalID primitive.ObjectID, from, to *time.Time) (*inventory.AnimalCostOfCare, error) {
+	args := m.Called(ctx, animalID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*inventory.AnimalCostOfCare), args.Error(1)
+}
+
 func (m *InventoryUseCase) AdjustStock(ctx context.Context, itemID primitive.ObjectID, newQuantity float64, userID primitive.ObjectID, reason, notes string) error {
 	args := m.Called(ctx, itemID, newQuantity, userID, reason, notes)
 	return args.Error(0)
@@ -0,0 +1,204 @@
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func daysFromNow(days int) *time.Time {
+	t := time.Now().AddDate(0, 0, days)
+	return &t
+}
+
+func lotTrackedItem() *entities.InventoryItem {
+	return &entities.InventoryItem{
+		ID:            primitive.NewObjectID(),
+		Name:          "Meloxicam 1.5mg/ml",
+		IsActive:      true,
+		CurrentStock:  10,
+		UnitCost:      1,
+		HasExpiration: true,
+		Lots: []entities.StockLot{
+			{LotNumber: "LATE", ExpirationDate: daysFromNow(60), Quantity: 3, UnitCost: 2},
+			{LotNumber: "SOON", ExpirationDate: daysFromNow(10), Quantity: 4, UnitCost: 3},
+			{LotNumber: "OLD", ExpirationDate: daysFromNow(-1), Quantity: 1, UnitCost: 5},
+		},
+	}
+}
+
+func TestInventoryUseCase_ConsumeStock(t *testing.T) {
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	animalID := primitive.NewObjectID()
+
+	t.Run("lots are used first-expired-first-out and costed per lot", func(t *testing.T) {
+		inventoryRepo := new(mocks.InventoryRepository)
+		stockTransactionRepo := new(mocks.StockTransactionRepository)
+		auditLogRepo := new(mocks.AuditLogRepository)
+		useCase := NewInventoryUseCase(inventoryRepo, stockTransactionRepo, auditLogRepo)
+
+		item := lotTrackedItem()
+		inventoryRepo.On("FindByID", ctx, item.ID).Return(item, nil)
+		inventoryRepo.On("Update", ctx, item).Return(nil)
+		stockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.StockTransaction")).Return(nil)
+		auditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		transactions, err := useCase.ConsumeStock(ctx, &entities.StockConsumption{
+			ItemID:          item.ID,
+			Quantity:        8,
+			AnimalID:        &animalID,
+			RelatedEntity:   "animal",
+			RelatedEntityID: &animalID,
+		}, userID)
+		require.NoError(t, err)
+
+		require.Len(t, transactions, 3)
+		assert.Equal(t, "SOON", transactions[0].LotNumber)
+		assert.Equal(t, 12.0, transactions[0].TotalCost)
+		assert.Equal(t, "LATE", transactions[1].LotNumber)
+		assert.Equal(t, 6.0, transactions[1].TotalCost)
+		assert.Empty(t, transactions[2].LotNumber, "untracked stock is used after the lots")
+		assert.Equal(t, 1.0, transactions[2].Quantity)
+		assert.Equal(t, 10.0, transactions[0].StockBefore)
+		assert.Equal(t, 2.0, transactions[2].StockAfter)
+		for _, transaction := range transactions {
+			assert.Equal(t, entities.TransactionTypeOut, transaction.Type)
+			assert.Equal(t, &animalID, transaction.AnimalID)
+			assert.Equal(t, "animal", transaction.RelatedEntity)
+		}
+
+		assert.Equal(t, 2.0, item.CurrentStock)
+		require.Len(t, item.Lots, 1, "only the expired lot is left")
+		assert.Equal(t, "OLD", item.Lots[0].LotNumber)
+		assert.True(t, item.IsExpired)
+		stockTransactionRepo.AssertNumberOfCalls(t, "Create", 3)
+	})
+
+	t.Run("requested lot must be usable", func(t *testing.T) {
+		inventoryRepo := new(mocks.InventoryRepository)
+		useCase := NewInventoryUseCase(inventoryRepo, new(mocks.StockTransactionRepository), nil)
+
+		item := lotTrackedItem()
+		inventoryRepo.On("FindByID", ctx, item.ID).Return(item, nil)
+
+		_, err := useCase.ConsumeStock(ctx, &entities.StockConsumption{ItemID: item.ID, Quantity: 1, LotNumber: "OLD"}, userID)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 400, appErr.Code)
+
+		_, err = useCase.ConsumeStock(ctx, &entities.StockConsumption{ItemID: item.ID, Quantity: 5, LotNumber: "SOON"}, userID)
+		require.Error(t, err)
+		assert.Equal(t, 10.0, item.CurrentStock)
+		inventoryRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryUseCase_AddStockLot(t *testing.T) {
+	ctx := context.Background()
+	inventoryRepo := new(mocks.InventoryRepository)
+	stockTransactionRepo := new(mocks.StockTransactionRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	useCase := NewInventoryUseCase(inventoryRepo, stockTransactionRepo, auditLogRepo)
+
+	item := lotTrackedItem()
+	inventoryRepo.On("FindByID", ctx, item.ID).Return(item, nil)
+	inventoryRepo.On("Update", ctx, item).Return(nil)
+	stockTransactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.StockTransaction) bool {
+		return transaction.LotNumber == "SOON" && transaction.ExpirationDate != nil
+	})).Return(nil)
+	auditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	expiry := *item.Lots[1].ExpirationDate
+	err := useCase.AddStockLot(ctx, item.ID, 6, 3, "SOON", &expiry, primitive.NewObjectID(), "PO-7", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 16.0, item.CurrentStock)
+	require.Len(t, item.Lots, 3, "a known lot number is merged")
+	assert.Equal(t, 10.0, item.Lots[1].Quantity)
+	assert.Equal(t, *item.Lots[2].ExpirationDate, *item.ExpirationDate, "the item keeps flagging the expired lot")
+	stockTransactionRepo.AssertExpectations(t)
+}
+
+func TestInventoryUseCase_ReturnStockRestoresTheConsumedLots(t *testing.T) {
+	ctx := context.Background()
+	inventoryRepo := new(mocks.InventoryRepository)
+	stockTransactionRepo := new(mocks.StockTransactionRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	useCase := NewInventoryUseCase(inventoryRepo, stockTransactionRepo, auditLogRepo)
+
+	item := lotTrackedItem()
+	inventoryRepo.On("FindByID", ctx, item.ID).Return(item, nil)
+	inventoryRepo.On("Update", ctx, item).Return(nil)
+	stockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.StockTransaction")).Return(nil)
+	auditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	userID := primitive.NewObjectID()
+	transactions, err := useCase.ConsumeStock(ctx, &entities.StockConsumption{ItemID: item.ID, Quantity: 6}, userID)
+	require.NoError(t, err)
+	require.Equal(t, 4.0, item.CurrentStock)
+
+	require.NoError(t, useCase.ReturnStock(ctx, transactions, userID, "visit not saved"))
+
+	assert.Equal(t, 10.0, item.CurrentStock)
+	quantities := map[string]float64{}
+	for _, lot := range item.Lots {
+		quantities[lot.LotNumber] = lot.Quantity
+	}
+	assert.Equal(t, map[string]float64{"SOON": 4, "LATE": 3, "OLD": 1}, quantities)
+}
+
+func TestInventoryUseCase_AdjustStockReconcilesLots(t *testing.T) {
+	ctx := context.Background()
+	inventoryRepo := new(mocks.InventoryRepository)
+	stockTransactionRepo := new(mocks.StockTransactionRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	useCase := NewInventoryUseCase(inventoryRepo, stockTransactionRepo, auditLogRepo)
+
+	item := lotTrackedItem()
+	inventoryRepo.On("FindByID", ctx, item.ID).Return(item, nil)
+	inventoryRepo.On("Update", ctx, item).Return(nil)
+	stockTransactionRepo.On("Create", ctx, mock.Anything).Return(nil)
+	auditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	require.NoError(t, useCase.AdjustStock(ctx, item.ID, 5, primitive.NewObjectID(), "count", ""))
+
+	total := 0.0
+	for _, lot := range item.Lots {
+		total += lot.Quantity
+	}
+	assert.Equal(t, 5.0, total)
+	assert.Equal(t, "LATE", item.Lots[len(item.Lots)-1].LotNumber, "the latest expiring lot is kept")
+}
+
+func TestInventoryUseCase_GetAnimalCostOfCare(t *testing.T) {
+	ctx := context.Background()
+	stockTransactionRepo := new(mocks.StockTransactionRepository)
+	useCase := NewInventoryUseCase(nil, stockTransactionRepo, nil)
+
+	animalID := primitive.NewObjectID()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []*repositories.CostOfCareItem{
+		{ItemID: primitive.NewObjectID(), ItemName: "Rabies vaccine", Category: "medicine", Quantity: 1, TotalCost: 18},
+		{ItemID: primitive.NewObjectID(), ItemName: "Meloxicam", Category: "medicine", Quantity: 14, TotalCost: 7},
+		{ItemID: primitive.NewObjectID(), ItemName: "Gauze", Category: "medical_supplies", Quantity: 3, TotalCost: 1.5},
+	}
+	stockTransactionRepo.On("GetAnimalCostOfCare", ctx, animalID, &from, (*time.Time)(nil)).Return(items, nil)
+
+	rollup, err := useCase.GetAnimalCostOfCare(ctx, animalID, &from, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 26.5, rollup.TotalCost)
+	assert.Equal(t, 25.0, rollup.ByCategory["medicine"])
+	assert.Equal(t, 1.5, rollup.ByCategory["medical_supplies"])
+	assert.Len(t, rollup.Items, 3)
+}
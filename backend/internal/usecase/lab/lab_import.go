@@ -0,0 +1,524 @@
+package lab
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/microchip"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Import formats
+const (
+	ImportFormatCSV = "csv"
+	ImportFormatHL7 = "hl7"
+)
+
+// ImportRequest describes a results file dropped by a partner clinic or lab
+type ImportRequest struct {
+	Format    string `form:"format"` // csv or hl7, detected from the file name when empty
+	FileName  string `form:"-"`
+	VisitID   string `form:"visit_id"`
+	PartnerID string `form:"partner_id"`
+	LabName   string `form:"lab_name"`
+}
+
+// ImportError reports a line of the file that could not be imported
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportResult summarizes a results import
+type ImportResult struct {
+	PanelsCreated int                  `json:"panels_created"`
+	PanelsUpdated int                  `json:"panels_updated"`
+	Panels        []*entities.LabPanel `json:"panels"`
+	Errors        []ImportError        `json:"errors"`
+}
+
+// importedPanel is a panel parsed from a file before the animal is resolved
+type importedPanel struct {
+	line        int
+	animalRef   string
+	accession   string
+	name        string
+	collectedAt time.Time
+	results     []entities.LabResultValue
+}
+
+// ImportResults imports lab panels from a CSV or HL7-like file. Panels with an
+// accession number already recorded for the animal replace the stored results.
+// Lines that cannot be parsed are reported and skipped.
+func (uc *LabUseCase) ImportResults(ctx context.Context, req *ImportRequest, file io.Reader, userID primitive.ObjectID) (*ImportResult, error) {
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(req.FileName)) {
+		case ".hl7", ".txt":
+			format = ImportFormatHL7
+		default:
+			format = ImportFormatCSV
+		}
+	}
+
+	var (
+		parsed []*importedPanel
+		result = &ImportResult{Panels: []*entities.LabPanel{}, Errors: []ImportError{}}
+		err    error
+	)
+	switch format {
+	case ImportFormatCSV:
+		parsed, result.Errors, err = parseCSV(file)
+	case ImportFormatHL7:
+		parsed, result.Errors, err = parseHL7(file)
+	default:
+		return nil, errors.NewBadRequest("unsupported import format, expected csv or hl7")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 && len(result.Errors) == 0 {
+		return nil, errors.NewBadRequest("the file contains no lab results")
+	}
+
+	for _, imported := range parsed {
+		panel, created, err := uc.storeImported(ctx, req, imported, entities.LabResultSource(format), userID)
+		if err != nil {
+			message := err.Error()
+			if appErr, ok := err.(*errors.AppError); ok {
+				message = appErr.Message
+			}
+			result.Errors = append(result.Errors, ImportError{Line: imported.line, Message: message})
+			continue
+		}
+
+		if created {
+			result.PanelsCreated++
+		} else {
+			result.PanelsUpdated++
+		}
+		result.Panels = append(result.Panels, panel)
+	}
+
+	return result, nil
+}
+
+// storeImported resolves the animal of an imported panel and stores it
+func (uc *LabUseCase) storeImported(ctx context.Context, req *ImportRequest, imported *importedPanel, source entities.LabResultSource, userID primitive.ObjectID) (*entities.LabPanel, bool, error) {
+	animal, err := uc.resolveAnimal(ctx, imported.animalRef)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if imported.accession != "" {
+		existing, err := uc.panelRepo.FindByAccession(ctx, animal.ID, imported.accession)
+		if err != nil && err != errors.ErrNotFound {
+			return nil, false, err
+		}
+		if existing != nil {
+			// The same file is often dropped again; only changed results make a correction
+			if !sameResults(existing.Results, imported.results) {
+				existing.Status = entities.LabPanelStatusCorrected
+			}
+			existing.Results = imported.results
+			existing.Source = source
+			existing.CollectedAt = imported.collectedAt
+			if imported.name != "" {
+				existing.Name = imported.name
+			}
+			existing.UpdatedBy = userID
+			if err := uc.savePanel(ctx, existing, false); err != nil {
+				return nil, false, err
+			}
+			uc.audit(ctx, userID, entities.ActionUpdate, existing, map[string]interface{}{"source": source, "accession_number": imported.accession})
+			return existing, false, nil
+		}
+	}
+
+	now := time.Now()
+	panel := &entities.LabPanel{
+		AnimalID:        animal.ID,
+		Species:         entities.NormalizeSpecies(animal.Species),
+		Name:            imported.name,
+		LabName:         req.LabName,
+		AccessionNumber: imported.accession,
+		Status:          entities.LabPanelStatusFinal,
+		Source:          source,
+		CollectedAt:     imported.collectedAt,
+		ReportedAt:      &now,
+		Results:         imported.results,
+		CreatedBy:       userID,
+		UpdatedBy:       userID,
+	}
+	if panel.Name == "" {
+		panel.Name = "Lab results"
+	}
+	if req.PartnerID != "" {
+		if err := uc.setPartner(ctx, panel, req.PartnerID); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := uc.setVisit(ctx, panel, req.VisitID); err != nil {
+		return nil, false, err
+	}
+
+	if err := uc.savePanel(ctx, panel, true); err != nil {
+		return nil, false, err
+	}
+	uc.audit(ctx, userID, entities.ActionCreate, panel, map[string]interface{}{"source": source})
+	return panel, true, nil
+}
+
+// sameResults checks if imported results report the same values as the stored ones.
+// Ranges, flags and missing units are left out, as stored ones are filled in from the
+// analyte definitions.
+func sameResults(stored, imported []entities.LabResultValue) bool {
+	if len(stored) != len(imported) {
+		return false
+	}
+
+	byCode := make(map[string]entities.LabResultValue, len(stored))
+	for _, result := range stored {
+		byCode[entities.NormalizeAnalyteCode(result.AnalyteCode)] = result
+	}
+	for _, result := range imported {
+		previous, ok := byCode[entities.NormalizeAnalyteCode(result.AnalyteCode)]
+		if !ok {
+			return false
+		}
+		if (previous.Value == nil) != (result.Value == nil) || (previous.Value != nil && *previous.Value != *result.Value) {
+			return false
+		}
+		unit := strings.TrimSpace(result.Unit)
+		if strings.TrimSpace(previous.Text) != strings.TrimSpace(result.Text) ||
+			(unit != "" && !strings.EqualFold(strings.TrimSpace(previous.Unit), unit)) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveAnimal finds an animal by ID or microchip number
+func (uc *LabUseCase) resolveAnimal(ctx context.Context, ref string) (*entities.Animal, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.NewBadRequest("missing animal ID or microchip number")
+	}
+
+	if id, err := primitive.ObjectIDFromHex(ref); err == nil {
+		if animal, err := uc.animalRepo.FindByID(ctx, id); err == nil {
+			return animal, nil
+		}
+	}
+
+	animal, err := uc.animalRepo.FindByMicrochipNumber(ctx, microchip.Normalize(ref))
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewBadRequest("no animal found for " + ref)
+		}
+		return nil, err
+	}
+	return animal, nil
+}
+
+// parseCSV reads one result per row. The header names the columns:
+// animal_id or microchip, collected_at, panel, accession, analyte, value,
+// unit, reference_range ("low-high") and flag. Rows with the same animal,
+// accession (or panel name) and collection time form one panel.
+func parseCSV(file io.Reader) ([]*importedPanel, []ImportError, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.NewBadRequest("failed to read the CSV header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["analyte"]; !ok {
+		return nil, nil, errors.NewBadRequest("CSV header must include an analyte column")
+	}
+	if _, ok := columns["value"]; !ok {
+		return nil, nil, errors.NewBadRequest("CSV header must include a value column")
+	}
+
+	var (
+		panels   []*importedPanel
+		byKey    = make(map[string]*importedPanel)
+		problems []ImportError
+		line     = 1
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			problems = append(problems, ImportError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		animalRef := field("animal_id")
+		if animalRef == "" {
+			animalRef = field("microchip")
+		}
+		if animalRef == "" {
+			problems = append(problems, ImportError{Line: line, Message: "missing animal_id or microchip"})
+			continue
+		}
+
+		collectedAt, err := parseImportTime(field("collected_at"))
+		if err != nil {
+			problems = append(problems, ImportError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		value, err := parseResult(field("analyte"), field("value"), field("unit"), field("reference_range"), field("flag"))
+		if err != nil {
+			problems = append(problems, ImportError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		panelName := field("panel")
+		accession := field("accession")
+		key := strings.Join([]string{animalRef, accession, panelName, collectedAt.Format(time.RFC3339)}, "|")
+		if accession != "" {
+			key = animalRef + "|" + accession
+		}
+		panel, ok := byKey[key]
+		if !ok {
+			panel = &importedPanel{line: line, animalRef: animalRef, accession: accession, name: panelName, collectedAt: collectedAt}
+			byKey[key] = panel
+			panels = append(panels, panel)
+		}
+		panel.results = append(panel.results, *value)
+	}
+
+	return panels, problems, nil
+}
+
+// parseHL7 reads pipe-delimited HL7 v2 style segments. PID-3 holds the animal
+// ID or microchip number, OBR starts a panel (OBR-3 accession, OBR-4 panel
+// name, OBR-7 collection time) and each OBX is a result (OBX-3 analyte,
+// OBX-5 value, OBX-6 unit, OBX-7 reference range, OBX-8 flag).
+func parseHL7(file io.Reader) ([]*importedPanel, []ImportError, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Split(scanSegments)
+
+	var (
+		panels    []*importedPanel
+		problems  []ImportError
+		animalRef string
+		current   *importedPanel
+		line      int
+	)
+	for scanner.Scan() {
+		line++
+		segment := strings.TrimSpace(scanner.Text())
+		if segment == "" {
+			continue
+		}
+		fields := strings.Split(segment, "|")
+		get := func(i int) string {
+			if i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		switch fields[0] {
+		case "PID":
+			animalRef = component(get(3), 0)
+			current = nil
+		case "OBR":
+			if animalRef == "" {
+				problems = append(problems, ImportError{Line: line, Message: "OBR segment before a PID segment"})
+				current = nil
+				continue
+			}
+			collectedAt, err := parseImportTime(get(7))
+			if err != nil {
+				problems = append(problems, ImportError{Line: line, Message: err.Error()})
+				current = nil
+				continue
+			}
+			name := component(get(4), 1)
+			if name == "" {
+				name = component(get(4), 0)
+			}
+			current = &importedPanel{line: line, animalRef: animalRef, accession: component(get(3), 0), name: name, collectedAt: collectedAt}
+			panels = append(panels, current)
+		case "OBX":
+			if current == nil {
+				problems = append(problems, ImportError{Line: line, Message: "OBX segment without a valid OBR segment"})
+				continue
+			}
+			value, err := parseResult(get(3), get(5), get(6), get(7), get(8))
+			if err != nil {
+				problems = append(problems, ImportError{Line: line, Message: err.Error()})
+				continue
+			}
+			current.results = append(current.results, *value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, errors.NewBadRequest("failed to read the HL7 file")
+	}
+
+	// Drop panels whose results all failed to parse
+	kept := panels[:0]
+	for _, panel := range panels {
+		if len(panel.results) > 0 {
+			kept = append(kept, panel)
+		}
+	}
+
+	return kept, problems, nil
+}
+
+// scanSegments splits HL7 segments on \r, \n or \r\n
+func scanSegments(data []byte, atEOF bool) (int, []byte, error) {
+	for i, b := range data {
+		if b == '\r' || b == '\n' {
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// component returns a ^-separated component of an HL7 field
+func component(field string, i int) string {
+	parts := strings.Split(field, "^")
+	if i < len(parts) {
+		return strings.TrimSpace(parts[i])
+	}
+	return ""
+}
+
+// parseResult builds a result from the raw analyte, value, unit, range and flag columns
+func parseResult(analyte, rawValue, unit, referenceRange, flag string) (*entities.LabResultValue, error) {
+	code := component(analyte, 0)
+	if code == "" {
+		return nil, fmt.Errorf("missing analyte")
+	}
+	if rawValue == "" {
+		return nil, fmt.Errorf("missing value for %s", code)
+	}
+
+	result := &entities.LabResultValue{
+		AnalyteCode: entities.NormalizeAnalyteCode(code),
+		Name:        component(analyte, 1),
+		Unit:        unit,
+		Flag:        parseFlag(flag),
+	}
+	if value, err := strconv.ParseFloat(strings.Replace(rawValue, ",", ".", 1), 64); err == nil {
+		result.Value = &value
+	} else {
+		result.Text = rawValue
+	}
+
+	if referenceRange != "" {
+		low, high, err := parseRange(referenceRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference range %q for %s", referenceRange, code)
+		}
+		result.ReferenceLow = low
+		result.ReferenceHigh = high
+	}
+
+	return result, nil
+}
+
+// parseRange parses "low-high", "<high" or ">low"
+func parseRange(value string) (*float64, *float64, error) {
+	value = strings.ReplaceAll(value, " ", "")
+	if value == "" {
+		return nil, nil, nil
+	}
+	parse := func(s string) (*float64, error) {
+		f, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+		if err != nil {
+			return nil, err
+		}
+		return &f, nil
+	}
+
+	switch {
+	case strings.HasPrefix(value, "<"):
+		high, err := parse(strings.TrimLeft(value, "<="))
+		return nil, high, err
+	case strings.HasPrefix(value, ">"):
+		low, err := parse(strings.TrimLeft(value, ">="))
+		return low, nil, err
+	}
+
+	// Split on the first dash that is not a leading minus sign
+	i := strings.Index(value[1:], "-")
+	if i < 0 {
+		return nil, nil, fmt.Errorf("missing separator")
+	}
+	low, err := parse(value[:i+1])
+	if err != nil {
+		return nil, nil, err
+	}
+	high, err := parse(value[i+2:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return low, high, nil
+}
+
+// parseFlag maps the abnormal flags used by labs to a LabFlag
+func parseFlag(flag string) entities.LabFlag {
+	switch strings.ToUpper(strings.TrimSpace(flag)) {
+	case "L", "LOW":
+		return entities.LabFlagLow
+	case "H", "HIGH":
+		return entities.LabFlagHigh
+	case "LL", "CRITICAL_LOW":
+		return entities.LabFlagCriticalLow
+	case "HH", "CRITICAL_HIGH":
+		return entities.LabFlagCriticalHigh
+	case "N", "NORMAL":
+		return entities.LabFlagNormal
+	}
+	return ""
+}
+
+// parseImportTime accepts RFC 3339, "YYYY-MM-DD HH:MM", "YYYY-MM-DD" and HL7 "YYYYMMDD[HHMM[SS]]"
+func parseImportTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing collection date")
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02", "20060102150405", "200601021504", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid collection date %q", value)
+}
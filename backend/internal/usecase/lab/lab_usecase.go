@@ -0,0 +1,599 @@
+package lab
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LabUseCase handles lab panels, analyte reference ranges and result imports
+type LabUseCase struct {
+	panelRepo    repositories.LabPanelRepository
+	analyteRepo  repositories.LabAnalyteRepository
+	animalRepo   repositories.AnimalRepository
+	visitRepo    repositories.VeterinaryVisitRepository
+	documentRepo repositories.DocumentRepository
+	partnerRepo  repositories.PartnerRepository
+	auditLogRepo repositories.AuditLogRepository
+}
+
+// NewLabUseCase creates a new lab use case
+func NewLabUseCase(
+	panelRepo repositories.LabPanelRepository,
+	analyteRepo repositories.LabAnalyteRepository,
+	animalRepo repositories.AnimalRepository,
+	visitRepo repositories.VeterinaryVisitRepository,
+	documentRepo repositories.DocumentRepository,
+	partnerRepo repositories.PartnerRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) *LabUseCase {
+	return &LabUseCase{
+		panelRepo:    panelRepo,
+		analyteRepo:  analyteRepo,
+		animalRepo:   animalRepo,
+		visitRepo:    visitRepo,
+		documentRepo: documentRepo,
+		partnerRepo:  partnerRepo,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// CreatePanelRequest represents a request to record a lab panel
+type CreatePanelRequest struct {
+	AnimalID        string                    `json:"animal_id" validate:"required"`
+	VisitID         string                    `json:"visit_id,omitempty"`
+	Name            string                    `json:"name" validate:"required"`
+	LabName         string                    `json:"lab_name,omitempty"`
+	PartnerID       string                    `json:"partner_id,omitempty"`
+	AccessionNumber string                    `json:"accession_number,omitempty"`
+	Status          entities.LabPanelStatus   `json:"status,omitempty"`
+	CollectedAt     time.Time                 `json:"collected_at" validate:"required"`
+	ReportedAt      *time.Time                `json:"reported_at,omitempty"`
+	Results         []entities.LabResultValue `json:"results" validate:"required,min=1"`
+	Notes           string                    `json:"notes,omitempty"`
+}
+
+// UpdatePanelRequest represents a request to update a lab panel
+type UpdatePanelRequest struct {
+	VisitID     *string                    `json:"visit_id,omitempty"`
+	Name        *string                    `json:"name,omitempty"`
+	LabName     *string                    `json:"lab_name,omitempty"`
+	Status      *entities.LabPanelStatus   `json:"status,omitempty"`
+	CollectedAt *time.Time                 `json:"collected_at,omitempty"`
+	ReportedAt  *time.Time                 `json:"reported_at,omitempty"`
+	Results     *[]entities.LabResultValue `json:"results,omitempty" validate:"omitempty,min=1"`
+	Notes       *string                    `json:"notes,omitempty"`
+}
+
+// CreatePanel records a lab panel and flags its results
+func (uc *LabUseCase) CreatePanel(ctx context.Context, req *CreatePanelRequest, userID primitive.ObjectID) (*entities.LabPanel, error) {
+	animalID, err := primitive.ObjectIDFromHex(req.AnimalID)
+	if err != nil {
+		return nil, errors.NewBadRequest("invalid animal ID")
+	}
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	panel := &entities.LabPanel{
+		AnimalID:        animal.ID,
+		Species:         entities.NormalizeSpecies(animal.Species),
+		Name:            req.Name,
+		LabName:         req.LabName,
+		AccessionNumber: strings.TrimSpace(req.AccessionNumber),
+		Status:          req.Status,
+		Source:          entities.LabResultSourceManual,
+		CollectedAt:     req.CollectedAt,
+		ReportedAt:      req.ReportedAt,
+		Results:         req.Results,
+		Notes:           req.Notes,
+		CreatedBy:       userID,
+		UpdatedBy:       userID,
+	}
+
+	if req.PartnerID != "" {
+		if err := uc.setPartner(ctx, panel, req.PartnerID); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.setVisit(ctx, panel, req.VisitID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.savePanel(ctx, panel, true); err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, userID, entities.ActionCreate, panel, nil)
+	return panel, nil
+}
+
+// GetPanel returns a lab panel
+func (uc *LabUseCase) GetPanel(ctx context.Context, id primitive.ObjectID) (*entities.LabPanel, error) {
+	return uc.panelRepo.FindByID(ctx, id)
+}
+
+// UpdatePanel updates a lab panel and re-flags its results
+func (uc *LabUseCase) UpdatePanel(ctx context.Context, id primitive.ObjectID, req *UpdatePanelRequest, userID primitive.ObjectID) (*entities.LabPanel, error) {
+	panel, err := uc.panelRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]interface{})
+	if req.VisitID != nil {
+		panel.VisitID = nil
+		if err := uc.setVisit(ctx, panel, *req.VisitID); err != nil {
+			return nil, err
+		}
+		changes["visit_id"] = panel.VisitID
+	}
+	if req.Name != nil {
+		panel.Name = *req.Name
+		changes["name"] = panel.Name
+	}
+	if req.LabName != nil {
+		panel.LabName = *req.LabName
+	}
+	if req.Status != nil {
+		panel.Status = *req.Status
+		changes["status"] = panel.Status
+	}
+	if req.CollectedAt != nil {
+		panel.CollectedAt = *req.CollectedAt
+	}
+	if req.ReportedAt != nil {
+		panel.ReportedAt = req.ReportedAt
+	}
+	if req.Results != nil {
+		panel.Results = *req.Results
+		changes["results"] = len(panel.Results)
+	}
+	if req.Notes != nil {
+		panel.Notes = *req.Notes
+	}
+	panel.UpdatedBy = userID
+
+	if err := uc.savePanel(ctx, panel, false); err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, userID, entities.ActionUpdate, panel, changes)
+	return panel, nil
+}
+
+// DeletePanel deletes a lab panel. The attached report document is kept.
+func (uc *LabUseCase) DeletePanel(ctx context.Context, id primitive.ObjectID, userID primitive.ObjectID) error {
+	panel, err := uc.panelRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.panelRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, userID, entities.ActionDelete, panel, nil)
+	return nil
+}
+
+// ListPanels returns the lab panels matching the filter, newest first
+func (uc *LabUseCase) ListPanels(ctx context.Context, filter *repositories.LabPanelFilter) ([]*entities.LabPanel, int64, error) {
+	return uc.panelRepo.List(ctx, filter)
+}
+
+// AttachDocument links the lab's report to a panel. The report is uploaded
+// through the documents API first; documents not linked to anything yet are
+// linked to the animal, linked ones must belong to the panel's animal or visit.
+func (uc *LabUseCase) AttachDocument(ctx context.Context, panelID, documentID, userID primitive.ObjectID) (*entities.LabPanel, error) {
+	panel, err := uc.panelRepo.FindByID(ctx, panelID)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := uc.documentRepo.FindByID(ctx, documentID)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewBadRequest("document not found")
+		}
+		return nil, err
+	}
+	if document.MimeType != "application/pdf" {
+		return nil, errors.NewBadRequest("lab reports must be PDF documents")
+	}
+	if document.ScanStatus == entities.DocumentScanStatusInfected {
+		return nil, errors.NewBadRequest("document failed the malware scan")
+	}
+
+	if document.RelatedEntity == "" {
+		document.RelatedEntity = "animal"
+		document.RelatedEntityID = &panel.AnimalID
+		if err := uc.documentRepo.Update(ctx, document); err != nil {
+			return nil, err
+		}
+	} else if !belongsToPanel(document, panel) {
+		return nil, errors.NewBadRequest("the document belongs to another record")
+	}
+
+	panel.DocumentID = &document.ID
+	panel.UpdatedBy = userID
+	if err := uc.panelRepo.Update(ctx, panel); err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, userID, entities.ActionUpdate, panel, map[string]interface{}{"document_id": document.ID.Hex()})
+	return panel, nil
+}
+
+// belongsToPanel checks if a linked document is linked to the animal or the visit of the panel
+func belongsToPanel(document *entities.Document, panel *entities.LabPanel) bool {
+	if document.RelatedEntityID == nil {
+		return false
+	}
+	switch document.RelatedEntity {
+	case "animal":
+		return *document.RelatedEntityID == panel.AnimalID
+	case "veterinary_visit":
+		return panel.VisitID != nil && *document.RelatedEntityID == *panel.VisitID
+	}
+	return false
+}
+
+// AnalyteComparison is the history of one analyte of an animal across panels
+type AnalyteComparison struct {
+	AnalyteCode string                    `json:"analyte_code"`
+	Name        string                    `json:"name,omitempty"`
+	Unit        string                    `json:"unit,omitempty"`
+	Range       *entities.LabSpeciesRange `json:"reference_range,omitempty"`
+	Points      []AnalytePoint            `json:"points"`
+
+	// Change from the previous to the latest numeric value, nil with fewer than two
+	Change        *float64 `json:"change,omitempty"`
+	ChangePercent *float64 `json:"change_percent,omitempty"`
+}
+
+// AnalytePoint is one result of an analyte
+type AnalytePoint struct {
+	PanelID     primitive.ObjectID  `json:"panel_id"`
+	PanelName   string              `json:"panel_name"`
+	VisitID     *primitive.ObjectID `json:"visit_id,omitempty"`
+	CollectedAt time.Time           `json:"collected_at"`
+	Value       *float64            `json:"value,omitempty"`
+	Text        string              `json:"text,omitempty"`
+	Unit        string              `json:"unit,omitempty"`
+	Flag        entities.LabFlag    `json:"flag,omitempty"`
+}
+
+// CompareAnalytes returns the results of the analytes across the animal's panels, oldest first
+func (uc *LabUseCase) CompareAnalytes(ctx context.Context, animalID primitive.ObjectID, codes []string) ([]*AnalyteComparison, error) {
+	if len(codes) == 0 {
+		return nil, errors.NewBadRequest("at least one analyte is required")
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	panels, _, err := uc.panelRepo.List(ctx, &repositories.LabPanelFilter{
+		AnimalID:     &animalID,
+		AnalyteCodes: codes,
+		SortOrder:    "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comparisons := make([]*AnalyteComparison, 0, len(codes))
+	for _, code := range codes {
+		comparison := &AnalyteComparison{AnalyteCode: entities.NormalizeAnalyteCode(code), Points: []AnalytePoint{}}
+		if analyte, err := uc.analyteRepo.FindByCode(ctx, code); err == nil {
+			comparison.Name = analyte.Name
+			comparison.Unit = analyte.Unit
+			comparison.Range = analyte.RangeFor(animal.Species)
+		}
+
+		for _, panel := range panels {
+			result := panel.Result(code)
+			if result == nil {
+				continue
+			}
+			if comparison.Name == "" {
+				comparison.Name = result.Name
+			}
+			comparison.Points = append(comparison.Points, AnalytePoint{
+				PanelID:     panel.ID,
+				PanelName:   panel.Name,
+				VisitID:     panel.VisitID,
+				CollectedAt: panel.CollectedAt,
+				Value:       result.Value,
+				Text:        result.Text,
+				Unit:        result.Unit,
+				Flag:        result.Flag,
+			})
+		}
+
+		comparison.computeChange()
+		comparisons = append(comparisons, comparison)
+	}
+
+	return comparisons, nil
+}
+
+// computeChange compares the last two numeric values reported in the same unit
+func (c *AnalyteComparison) computeChange() {
+	var numeric []AnalytePoint
+	for _, point := range c.Points {
+		if point.Value != nil {
+			numeric = append(numeric, point)
+		}
+	}
+	if len(numeric) < 2 {
+		return
+	}
+
+	previous, latest := numeric[len(numeric)-2], numeric[len(numeric)-1]
+	if !strings.EqualFold(previous.Unit, latest.Unit) {
+		return
+	}
+
+	change := *latest.Value - *previous.Value
+	c.Change = &change
+	if *previous.Value != 0 {
+		percent := change / *previous.Value * 100
+		c.ChangePercent = &percent
+	}
+}
+
+// savePanel validates the results, flags them and stores the panel
+func (uc *LabUseCase) savePanel(ctx context.Context, panel *entities.LabPanel, create bool) error {
+	if panel.Status == "" {
+		panel.Status = entities.LabPanelStatusFinal
+	}
+	for _, result := range panel.Results {
+		if strings.TrimSpace(result.AnalyteCode) == "" {
+			return errors.NewBadRequest("every result needs an analyte code")
+		}
+		if result.Value == nil && result.Text == "" {
+			return errors.NewBadRequest("result " + result.AnalyteCode + " has no value")
+		}
+	}
+
+	analytes, err := uc.analyteIndex(ctx)
+	if err != nil {
+		return err
+	}
+	panel.Evaluate(analytes)
+
+	if create {
+		return uc.panelRepo.Create(ctx, panel)
+	}
+	return uc.panelRepo.Update(ctx, panel)
+}
+
+// analyteIndex returns the analyte definitions keyed by code
+func (uc *LabUseCase) analyteIndex(ctx context.Context) (map[string]*entities.LabAnalyte, error) {
+	analytes, err := uc.analyteRepo.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]*entities.LabAnalyte, len(analytes))
+	for _, analyte := range analytes {
+		index[entities.NormalizeAnalyteCode(analyte.Code)] = analyte
+	}
+	return index, nil
+}
+
+// setVisit links a panel to a visit of the same animal. Without a visit ID the
+// panel is linked to the animal's visit on the collection day, if there is one.
+func (uc *LabUseCase) setVisit(ctx context.Context, panel *entities.LabPanel, visitIDHex string) error {
+	if visitIDHex != "" {
+		visitID, err := primitive.ObjectIDFromHex(visitIDHex)
+		if err != nil {
+			return errors.NewBadRequest("invalid visit ID")
+		}
+		visit, err := uc.visitRepo.FindByID(ctx, visitID)
+		if err != nil {
+			return err
+		}
+		if visit.AnimalID != panel.AnimalID {
+			return errors.NewBadRequest("visit belongs to another animal")
+		}
+		panel.VisitID = &visit.ID
+		return nil
+	}
+
+	if uc.visitRepo == nil {
+		return nil
+	}
+	visits, err := uc.visitRepo.GetByAnimalID(ctx, panel.AnimalID)
+	if err != nil {
+		return nil
+	}
+	collected := panel.CollectedAt.Format("2006-01-02")
+	for _, visit := range visits {
+		if visit.VisitDate.Format("2006-01-02") == collected {
+			panel.VisitID = &visit.ID
+			break
+		}
+	}
+	return nil
+}
+
+// setPartner links a panel to the partner clinic or lab that reported it
+func (uc *LabUseCase) setPartner(ctx context.Context, panel *entities.LabPanel, partnerIDHex string) error {
+	partnerID, err := primitive.ObjectIDFromHex(partnerIDHex)
+	if err != nil {
+		return errors.NewBadRequest("invalid partner ID")
+	}
+	partner, err := uc.partnerRepo.FindByID(ctx, partnerID)
+	if err != nil {
+		return err
+	}
+
+	panel.PartnerID = &partner.ID
+	if panel.LabName == "" {
+		panel.LabName = partner.Name
+	}
+	return nil
+}
+
+func (uc *LabUseCase) audit(ctx context.Context, userID primitive.ObjectID, action entities.AuditAction, panel *entities.LabPanel, changes map[string]interface{}) {
+	if uc.auditLogRepo == nil {
+		return
+	}
+	if changes == nil {
+		changes = map[string]interface{}{}
+	}
+	changes["animal_id"] = panel.AnimalID.Hex()
+
+	_ = uc.auditLogRepo.Create(ctx, &entities.AuditLog{
+		UserID:     userID,
+		Action:     action,
+		EntityType: "lab_panel",
+		EntityID:   &panel.ID,
+		Changes:    changes,
+	})
+}
+
+// CreateAnalyteRequest represents a request to define a lab analyte
+type CreateAnalyteRequest struct {
+	Code     string                     `json:"code" validate:"required"`
+	Name     string                     `json:"name" validate:"required"`
+	Unit     string                     `json:"unit" validate:"required"`
+	Category string                     `json:"category,omitempty"`
+	Ranges   []entities.LabSpeciesRange `json:"ranges,omitempty" validate:"dive"`
+	Active   *bool                      `json:"active,omitempty"`
+}
+
+// UpdateAnalyteRequest represents a request to update a lab analyte
+type UpdateAnalyteRequest struct {
+	Name     *string                     `json:"name,omitempty"`
+	Unit     *string                     `json:"unit,omitempty"`
+	Category *string                     `json:"category,omitempty"`
+	Ranges   *[]entities.LabSpeciesRange `json:"ranges,omitempty"`
+	Active   *bool                       `json:"active,omitempty"`
+}
+
+// ListAnalytes returns the analyte definitions
+func (uc *LabUseCase) ListAnalytes(ctx context.Context, activeOnly bool) ([]*entities.LabAnalyte, error) {
+	return uc.analyteRepo.List(ctx, activeOnly)
+}
+
+// CreateAnalyte defines a new analyte
+func (uc *LabUseCase) CreateAnalyte(ctx context.Context, req *CreateAnalyteRequest, userID primitive.ObjectID) (*entities.LabAnalyte, error) {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	analyte := &entities.LabAnalyte{
+		Code:      entities.NormalizeAnalyteCode(req.Code),
+		Name:      req.Name,
+		Unit:      req.Unit,
+		Category:  req.Category,
+		Ranges:    normalizeRanges(req.Ranges),
+		Active:    active,
+		CreatedBy: userID,
+		UpdatedBy: userID,
+	}
+	if err := validateRanges(analyte.Ranges); err != nil {
+		return nil, err
+	}
+
+	if err := uc.analyteRepo.Create(ctx, analyte); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionCreate, "lab_analyte", analyte.Code, "").
+		WithEntityID(analyte.ID))
+
+	return analyte, nil
+}
+
+// UpdateAnalyte updates an analyte. Stored panels keep the ranges they were flagged with.
+func (uc *LabUseCase) UpdateAnalyte(ctx context.Context, id primitive.ObjectID, req *UpdateAnalyteRequest, userID primitive.ObjectID) (*entities.LabAnalyte, error) {
+	analyte, err := uc.analyteRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		analyte.Name = *req.Name
+	}
+	if req.Unit != nil {
+		analyte.Unit = *req.Unit
+	}
+	if req.Category != nil {
+		analyte.Category = *req.Category
+	}
+	if req.Ranges != nil {
+		analyte.Ranges = normalizeRanges(*req.Ranges)
+		if err := validateRanges(analyte.Ranges); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil {
+		analyte.Active = *req.Active
+	}
+	analyte.UpdatedBy = userID
+
+	if err := uc.analyteRepo.Update(ctx, analyte); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "lab_analyte", analyte.Code, "").
+		WithEntityID(analyte.ID))
+
+	return analyte, nil
+}
+
+// EnsureDefaultAnalytes installs the default analytes when none are defined yet
+func (uc *LabUseCase) EnsureDefaultAnalytes(ctx context.Context) error {
+	existing, err := uc.analyteRepo.List(ctx, false)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	for _, analyte := range entities.DefaultLabAnalytes() {
+		if err := uc.analyteRepo.Create(ctx, analyte); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func normalizeRanges(ranges []entities.LabSpeciesRange) []entities.LabSpeciesRange {
+	for i := range ranges {
+		ranges[i].Species = entities.NormalizeSpecies(ranges[i].Species)
+	}
+	return ranges
+}
+
+func validateRanges(ranges []entities.LabSpeciesRange) error {
+	seen := make(map[string]bool)
+	for _, rng := range ranges {
+		if rng.Species == "" {
+			return errors.NewBadRequest("every range needs a species")
+		}
+		if seen[rng.Species] {
+			return errors.NewBadRequest("duplicate range for " + rng.Species)
+		}
+		seen[rng.Species] = true
+		if rng.Low != nil && rng.High != nil && *rng.Low > *rng.High {
+			return errors.NewBadRequest("range low is above high for " + rng.Species)
+		}
+	}
+	return nil
+}
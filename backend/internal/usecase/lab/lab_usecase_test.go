@@ -0,0 +1,263 @@
+package lab
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func newTestUseCase() (*LabUseCase, *mocks.LabPanelRepository, *mocks.LabAnalyteRepository, *mocks.AnimalRepository, *mocks.VeterinaryVisitRepository, *mocks.DocumentRepository) {
+	panelRepo := new(mocks.LabPanelRepository)
+	analyteRepo := new(mocks.LabAnalyteRepository)
+	animalRepo := new(mocks.AnimalRepository)
+	visitRepo := new(mocks.VeterinaryVisitRepository)
+	documentRepo := new(mocks.DocumentRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	auditLogRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	useCase := NewLabUseCase(panelRepo, analyteRepo, animalRepo, visitRepo, documentRepo, nil, auditLogRepo)
+	analyteRepo.On("List", mock.Anything, false).Return(entities.DefaultLabAnalytes(), nil)
+	return useCase, panelRepo, analyteRepo, animalRepo, visitRepo, documentRepo
+}
+
+func TestLabUseCase_CreatePanelFlagsResults(t *testing.T) {
+	ctx := context.Background()
+	useCase, panelRepo, _, animalRepo, visitRepo, _ := newTestUseCase()
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "Cat"}
+	collected := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	visit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: animal.ID, VisitDate: time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)}
+
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	visitRepo.On("GetByAnimalID", ctx, animal.ID).Return([]*entities.VeterinaryVisit{visit}, nil)
+	panelRepo.On("Create", ctx, mock.AnythingOfType("*entities.LabPanel")).Return(nil)
+
+	panel, err := useCase.CreatePanel(ctx, &CreatePanelRequest{
+		AnimalID:    animal.ID.Hex(),
+		Name:        "Chemistry",
+		CollectedAt: collected,
+		Results: []entities.LabResultValue{
+			{AnalyteCode: "crea", Value: float(3.1)},
+			{AnalyteCode: "K", Value: float(8)},
+			{AnalyteCode: "ALT", Value: float(40)},
+			{AnalyteCode: "GLU", Value: float(120), Unit: "mmol/L"},
+			{AnalyteCode: "FIV", Text: "negative", Flag: entities.LabFlagNormal},
+		},
+	}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, "cat", panel.Species)
+	assert.Equal(t, entities.LabPanelStatusFinal, panel.Status)
+	require.NotNil(t, panel.VisitID, "linked to the visit on the collection day")
+	assert.Equal(t, visit.ID, *panel.VisitID)
+
+	assert.Equal(t, entities.LabFlagHigh, panel.Result("CREA").Flag)
+	assert.Equal(t, "Creatinine", panel.Result("CREA").Name)
+	assert.Equal(t, entities.LabFlagCriticalHigh, panel.Result("K").Flag)
+	assert.Equal(t, entities.LabFlagNormal, panel.Result("ALT").Flag)
+	assert.Empty(t, panel.Result("GLU").Flag, "ranges in another unit are not applied")
+	assert.Equal(t, entities.LabFlagNormal, panel.Result("FIV").Flag, "qualitative results keep the lab flag")
+	assert.Equal(t, 2, panel.AbnormalCount)
+	assert.True(t, panel.HasCritical)
+}
+
+func TestLabUseCase_CreatePanelRejectsVisitOfAnotherAnimal(t *testing.T) {
+	ctx := context.Background()
+	useCase, panelRepo, _, animalRepo, visitRepo, _ := newTestUseCase()
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "Dog"}
+	visit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: primitive.NewObjectID()}
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	visitRepo.On("FindByID", ctx, visit.ID).Return(visit, nil)
+
+	_, err := useCase.CreatePanel(ctx, &CreatePanelRequest{
+		AnimalID:    animal.ID.Hex(),
+		VisitID:     visit.ID.Hex(),
+		Name:        "CBC",
+		CollectedAt: time.Now(),
+		Results:     []entities.LabResultValue{{AnalyteCode: "WBC", Value: float(9)}},
+	}, primitive.NewObjectID())
+
+	appErr, ok := err.(*errors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, 400, appErr.Code)
+	panelRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestLabUseCase_ImportCSV(t *testing.T) {
+	ctx := context.Background()
+	useCase, panelRepo, _, animalRepo, visitRepo, _ := newTestUseCase()
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "Dog"}
+	animalRepo.On("FindByMicrochipNumber", ctx, "985112003456789").Return(animal, nil)
+	animalRepo.On("FindByMicrochipNumber", ctx, "000000000000000").Return(nil, errors.ErrNotFound)
+	visitRepo.On("GetByAnimalID", ctx, animal.ID).Return([]*entities.VeterinaryVisit{}, nil)
+	panelRepo.On("FindByAccession", ctx, animal.ID, "A-100").Return(nil, errors.ErrNotFound)
+
+	var created []*entities.LabPanel
+	panelRepo.On("Create", ctx, mock.AnythingOfType("*entities.LabPanel")).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(1).(*entities.LabPanel))
+	}).Return(nil)
+
+	file := strings.Join([]string{
+		"microchip,collected_at,panel,accession,analyte,value,unit,reference_range,flag",
+		"985-112-003-456-789,2026-03-04 09:30,CBC,A-100,HCT,18,%,,",
+		"985-112-003-456-789,2026-03-04 09:30,CBC,A-100,WBC,21.5,10^9/L,5.0-18.0,H",
+		"985-112-003-456-789,2026-03-04 09:30,CBC,A-100,PLT,,10^9/L,,",
+		"000000000000000,2026-03-04 09:30,CBC,B-1,WBC,9,10^9/L,,",
+	}, "\n")
+
+	result, err := useCase.ImportResults(ctx, &ImportRequest{FileName: "results.csv", LabName: "VetLab"}, strings.NewReader(file), primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.PanelsCreated)
+	require.Len(t, created, 1)
+	panel := created[0]
+	assert.Equal(t, entities.LabResultSourceCSV, panel.Source)
+	assert.Equal(t, "CBC", panel.Name)
+	assert.Equal(t, "VetLab", panel.LabName)
+	require.Len(t, panel.Results, 2)
+	assert.Equal(t, entities.LabFlagCriticalLow, panel.Result("HCT").Flag, "dog hematocrit at or below 20% is critical")
+	assert.Equal(t, 18.0, *panel.Result("WBC").ReferenceHigh, "the lab's range wins over the default")
+	assert.Equal(t, entities.LabFlagHigh, panel.Result("WBC").Flag)
+
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, 4, result.Errors[0].Line)
+	assert.Contains(t, result.Errors[0].Message, "missing value")
+	assert.Equal(t, 5, result.Errors[1].Line)
+	assert.Contains(t, result.Errors[1].Message, "no animal found")
+}
+
+func TestLabUseCase_ImportHL7CorrectsExistingAccession(t *testing.T) {
+	ctx := context.Background()
+	useCase, panelRepo, _, animalRepo, _, _ := newTestUseCase()
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "Cat"}
+	existing := &entities.LabPanel{ID: primitive.NewObjectID(), AnimalID: animal.ID, Species: "cat", Name: "Chem", AccessionNumber: "ACC-7", Status: entities.LabPanelStatusPreliminary}
+
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	panelRepo.On("FindByAccession", ctx, animal.ID, "ACC-7").Return(existing, nil)
+	panelRepo.On("Update", ctx, existing).Return(nil)
+
+	file := strings.Join([]string{
+		"MSH|^~\\&|VETLAB|CLINIC|||202603041200||ORU^R01|1|P|2.5",
+		"PID|1||" + animal.ID.Hex() + "||Mruczek",
+		"OBR|1||ACC-7|CHEM^Chemistry Panel|||202603040930",
+		"OBX|1|NM|CREA^Creatinine||2.9|mg/dL|0.8-2.4|H",
+		"OBX|2|NM|GLU^Glucose||35|mg/dL||LL",
+	}, "\r")
+
+	result, err := useCase.ImportResults(ctx, &ImportRequest{Format: "hl7"}, strings.NewReader(file), primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.PanelsCreated)
+	assert.Equal(t, 1, result.PanelsUpdated)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, entities.LabPanelStatusCorrected, existing.Status)
+	assert.Equal(t, entities.LabResultSourceHL7, existing.Source)
+	assert.Equal(t, "Chemistry Panel", existing.Name)
+	assert.Equal(t, time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC), existing.CollectedAt)
+	assert.Equal(t, entities.LabFlagHigh, existing.Result("CREA").Flag)
+	assert.Equal(t, entities.LabFlagCriticalLow, existing.Result("GLU").Flag)
+	assert.True(t, existing.HasCritical)
+	panelRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// The same file dropped again is not a correction
+	existing.Status = entities.LabPanelStatusFinal
+	_, err = useCase.ImportResults(ctx, &ImportRequest{Format: "hl7"}, strings.NewReader(file), primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, entities.LabPanelStatusFinal, existing.Status)
+}
+
+func TestLabUseCase_CompareAnalytes(t *testing.T) {
+	ctx := context.Background()
+	useCase, panelRepo, analyteRepo, animalRepo, _, _ := newTestUseCase()
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "Dog"}
+	first := &entities.LabPanel{ID: primitive.NewObjectID(), Name: "Chem", CollectedAt: time.Now().AddDate(0, -1, 0), Results: []entities.LabResultValue{
+		{AnalyteCode: "CREA", Value: float(1.6), Unit: "mg/dL", Flag: entities.LabFlagNormal},
+	}}
+	second := &entities.LabPanel{ID: primitive.NewObjectID(), Name: "Chem", CollectedAt: time.Now(), Results: []entities.LabResultValue{
+		{AnalyteCode: "CREA", Value: float(2.0), Unit: "mg/dL", Flag: entities.LabFlagHigh},
+	}}
+
+	var creatinine *entities.LabAnalyte
+	for _, analyte := range entities.DefaultLabAnalytes() {
+		if analyte.Code == "CREA" {
+			creatinine = analyte
+		}
+	}
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	analyteRepo.On("FindByCode", ctx, "crea").Return(creatinine, nil)
+	analyteRepo.On("FindByCode", ctx, "ALT").Return(nil, errors.ErrNotFound)
+	panelRepo.On("List", ctx, mock.MatchedBy(func(filter *repositories.LabPanelFilter) bool {
+		return *filter.AnimalID == animal.ID && filter.SortOrder == "asc"
+	})).Return([]*entities.LabPanel{first, second}, int64(2), nil)
+
+	comparisons, err := useCase.CompareAnalytes(ctx, animal.ID, []string{"crea", "ALT"})
+	require.NoError(t, err)
+	require.Len(t, comparisons, 2)
+
+	crea := comparisons[0]
+	assert.Equal(t, "CREA", crea.AnalyteCode)
+	assert.Equal(t, "Creatinine", crea.Name)
+	require.NotNil(t, crea.Range)
+	assert.Equal(t, "dog", crea.Range.Species)
+	require.Len(t, crea.Points, 2)
+	assert.Equal(t, first.ID, crea.Points[0].PanelID)
+	require.NotNil(t, crea.Change)
+	assert.InDelta(t, 0.4, *crea.Change, 1e-9)
+	assert.InDelta(t, 25.0, *crea.ChangePercent, 1e-9)
+
+	assert.Empty(t, comparisons[1].Points)
+	assert.Nil(t, comparisons[1].Change)
+}
+
+func TestLabUseCase_AttachDocument(t *testing.T) {
+	ctx := context.Background()
+	useCase, panelRepo, _, _, _, documentRepo := newTestUseCase()
+
+	panel := &entities.LabPanel{ID: primitive.NewObjectID(), AnimalID: primitive.NewObjectID()}
+	report := &entities.Document{ID: primitive.NewObjectID(), MimeType: "application/pdf", ScanStatus: entities.DocumentScanStatusClean}
+	image := &entities.Document{ID: primitive.NewObjectID(), MimeType: "image/png"}
+
+	panelRepo.On("FindByID", ctx, panel.ID).Return(panel, nil)
+	panelRepo.On("Update", ctx, panel).Return(nil)
+	documentRepo.On("FindByID", ctx, report.ID).Return(report, nil)
+	documentRepo.On("FindByID", ctx, image.ID).Return(image, nil)
+	documentRepo.On("Update", ctx, report).Return(nil)
+
+	_, err := useCase.AttachDocument(ctx, panel.ID, image.ID, primitive.NewObjectID())
+	require.Error(t, err)
+	assert.Nil(t, panel.DocumentID)
+
+	updated, err := useCase.AttachDocument(ctx, panel.ID, report.ID, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Equal(t, report.ID, *updated.DocumentID)
+	assert.Equal(t, "animal", report.RelatedEntity)
+	assert.Equal(t, panel.AnimalID, *report.RelatedEntityID)
+
+	otherAnimal := primitive.NewObjectID()
+	foreign := &entities.Document{ID: primitive.NewObjectID(), MimeType: "application/pdf", RelatedEntity: "animal", RelatedEntityID: &otherAnimal}
+	documentRepo.On("FindByID", ctx, foreign.ID).Return(foreign, nil)
+
+	_, err = useCase.AttachDocument(ctx, panel.ID, foreign.ID, primitive.NewObjectID())
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 400, appErr.Code)
+	assert.Equal(t, report.ID, *panel.DocumentID)
+}
@@ -15,6 +15,10 @@ type MedicalUseCase struct {
 	treatmentPlanRepo repositories.TreatmentPlanRepository
 	animalRepo       repositories.AnimalRepository
 	auditLogRepo     repositories.AuditLogRepository
+	doseRepo         repositories.MedicationDoseRepository
+	userRepo         repositories.UserRepository
+	notifier         Notifier
+	stock            StockConsumer
 }
 
 func NewMedicalUseCase(
@@ -23,6 +27,10 @@ func NewMedicalUseCase(
 	treatmentPlanRepo repositories.TreatmentPlanRepository,
 	animalRepo repositories.AnimalRepository,
 	auditLogRepo repositories.AuditLogRepository,
+	doseRepo repositories.MedicationDoseRepository,
+	userRepo repositories.UserRepository,
+	notifier Notifier,
+	stock StockConsumer,
 ) *MedicalUseCase {
 	return &MedicalUseCase{
 		conditionRepo:    conditionRepo,
@@ -30,6 +38,10 @@ func NewMedicalUseCase(
 		treatmentPlanRepo: treatmentPlanRepo,
 		animalRepo:       animalRepo,
 		auditLogRepo:     auditLogRepo,
+		doseRepo:         doseRepo,
+		userRepo:         userRepo,
+		notifier:         notifier,
+		stock:            stock,
 	}
 }
 
@@ -166,10 +178,17 @@ func (uc *MedicalUseCase) CreateMedication(ctx context.Context, medication *enti
 		}
 	}
 
+	if err := normalizeSchedule(medication); err != nil {
+		return err
+	}
+
 	if err := uc.medicationRepo.Create(ctx, medication); err != nil {
 		return err
 	}
 
+	// Dose slots are generated again by the scheduler if this fails
+	_ = uc.resetDoses(ctx, medication)
+
 	// Create audit log
 	uc.auditLogRepo.Create(ctx, &entities.AuditLog{
 		UserID:     userID,
@@ -188,10 +207,16 @@ func (uc *MedicalUseCase) GetMedication(ctx context.Context, id primitive.Object
 
 // UpdateMedication updates a medication record
 func (uc *MedicalUseCase) UpdateMedication(ctx context.Context, medication *entities.Medication, userID primitive.ObjectID) error {
+	if err := normalizeSchedule(medication); err != nil {
+		return err
+	}
+
 	if err := uc.medicationRepo.Update(ctx, medication); err != nil {
 		return err
 	}
 
+	_ = uc.resetDoses(ctx, medication)
+
 	// Create audit log
 	uc.auditLogRepo.Create(ctx, &entities.AuditLog{
 		UserID:     userID,
@@ -214,6 +239,8 @@ func (uc *MedicalUseCase) DeleteMedication(ctx context.Context, id primitive.Obj
 		return err
 	}
 
+	_ = uc.doseRepo.DeleteScheduled(ctx, id, time.Time{})
+
 	// Create audit log
 	uc.auditLogRepo.Create(ctx, &entities.AuditLog{
 		UserID:     userID,
@@ -255,11 +282,6 @@ func (uc *MedicalUseCase) GetExpiringSoonMedications(ctx context.Context, days i
 	return uc.medicationRepo.FindExpiringSoon(ctx, days)
 }
 
-// RecordMedicationAdministration records when medication was given
-func (uc *MedicalUseCase) RecordMedicationAdministration(ctx context.Context, medicationID primitive.ObjectID, log entities.AdministrationLog) error {
-	return uc.medicationRepo.AddAdministrationLog(ctx, medicationID, log)
-}
-
 // RefillMedication processes a medication refill
 func (uc *MedicalUseCase) RefillMedication(ctx context.Context, medicationID primitive.ObjectID, userID primitive.ObjectID) error {
 	medication, err := uc.medicationRepo.FindByID(ctx, medicationID)
@@ -0,0 +1,564 @@
+package medical
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notifier delivers in-app notifications
+type Notifier interface {
+	CreateNotification(ctx context.Context, notification *entities.Notification) error
+}
+
+// StockConsumer takes consumed medication out of inventory and puts it back when
+// the dose it was taken for cannot be saved
+type StockConsumer interface {
+	ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error)
+	ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error
+}
+
+const (
+	// doseHorizon is how far ahead dose slots are generated
+	doseHorizon = 24 * time.Hour
+	// doseMatchWindow is how far back an administration is matched to an open dose
+	doseMatchWindow = 12 * time.Hour
+	// missedDoseLookback limits missed-dose detection to recent slots
+	missedDoseLookback = 24 * time.Hour
+)
+
+// AdministerMedicationRequest represents a request to record a given dose
+type AdministerMedicationRequest struct {
+	DoseID      string `json:"dose_id,omitempty"`
+	DosageGiven string `json:"dosage_given" binding:"required"`
+	Notes       string `json:"notes"`
+}
+
+// RecordMedicationAdministration records when medication was given. The
+// administration is matched to the dose slot given in the request or to the
+// closest open slot. Controlled substances stay awaiting a witness sign-off.
+func (uc *MedicalUseCase) RecordMedicationAdministration(ctx context.Context, medicationID primitive.ObjectID, req *AdministerMedicationRequest, userID primitive.ObjectID) (*entities.MedicationDose, error) {
+	medication, err := uc.medicationRepo.FindByID(ctx, medicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if medication.Status != entities.MedicationStatusActive {
+		return nil, errors.NewBadRequest("medication is not active")
+	}
+
+	now := time.Now()
+	dose, err := uc.doseForAdministration(ctx, medication, req.DoseID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *dose
+	dose.AdministeredAt = &now
+	dose.AdministeredBy = &userID
+	dose.DosageGiven = req.DosageGiven
+	dose.Notes = req.Notes
+	dose.Status = entities.DoseStatusGiven
+	if dose.Controlled {
+		dose.Status = entities.DoseStatusAwaitingWitness
+	}
+
+	created := dose.ID.IsZero()
+	if created {
+		err = uc.doseRepo.Create(ctx, dose)
+	} else {
+		err = uc.doseRepo.Update(ctx, dose)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Stock is only taken out once the dose is recorded; without it the dose is undone
+	lotNumber, err := uc.saveDoseLot(ctx, medication, dose, userID)
+	if err != nil {
+		uc.undoAdministration(ctx, dose, &previous, created)
+		return nil, err
+	}
+
+	administration := entities.AdministrationLog{
+		AdministeredAt: now,
+		AdministeredBy: userID,
+		DosageGiven:    req.DosageGiven,
+		Notes:          req.Notes,
+		DoseID:         &dose.ID,
+		LotNumber:      lotNumber,
+	}
+	if !dose.PRN {
+		scheduledFor := dose.ScheduledFor
+		administration.ScheduledFor = &scheduledFor
+	}
+
+	if err := uc.medicationRepo.AddAdministrationLog(ctx, medicationID, administration); err != nil {
+		return nil, err
+	}
+
+	return dose, nil
+}
+
+// consumeMedicationStock takes one dose of a medication linked to an inventory
+// item out of stock
+func (uc *MedicalUseCase) consumeMedicationStock(ctx context.Context, medication *entities.Medication, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	if medication.InventoryItemID == nil || uc.stock == nil {
+		return nil, nil
+	}
+
+	quantity := medication.QuantityPerDose
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	return uc.stock.ConsumeStock(ctx, &entities.StockConsumption{
+		ItemID:          *medication.InventoryItemID,
+		Quantity:        quantity,
+		AnimalID:        &medication.AnimalID,
+		RelatedEntity:   "animal",
+		RelatedEntityID: &medication.AnimalID,
+		Reason:          "medication administered",
+		Reference:       "medication:" + medication.ID.Hex(),
+	}, userID)
+}
+
+// saveDoseLot takes a recorded dose out of stock and stores the lots used on the
+// dose, returning them. The stock goes back if the dose cannot be updated.
+func (uc *MedicalUseCase) saveDoseLot(ctx context.Context, medication *entities.Medication, dose *entities.MedicationDose, userID primitive.ObjectID) (string, error) {
+	transactions, err := uc.consumeMedicationStock(ctx, medication, userID)
+	if err != nil || len(transactions) == 0 {
+		return "", err
+	}
+
+	var lots []string
+	for _, transaction := range transactions {
+		if transaction.LotNumber != "" {
+			lots = append(lots, transaction.LotNumber)
+		}
+	}
+	if len(lots) == 0 {
+		return "", nil
+	}
+
+	dose.LotNumber = strings.Join(lots, ", ")
+	if err := uc.doseRepo.Update(ctx, dose); err != nil {
+		if returnErr := uc.stock.ReturnStock(ctx, transactions, userID, "dose "+dose.ID.Hex()+" was not saved"); returnErr != nil {
+			log.Error().Err(returnErr).Str("dose_id", dose.ID.Hex()).Msg("failed to return stock of a dose that was not saved")
+		}
+		return "", err
+	}
+
+	return dose.LotNumber, nil
+}
+
+// undoAdministration removes a dose recorded without a dose slot or reopens the
+// slot it was recorded on
+func (uc *MedicalUseCase) undoAdministration(ctx context.Context, dose, previous *entities.MedicationDose, created bool) {
+	var err error
+	if created {
+		err = uc.doseRepo.Delete(ctx, dose.ID)
+	} else {
+		err = uc.doseRepo.Update(ctx, previous)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("dose_id", dose.ID.Hex()).Msg("failed to undo a dose whose stock was not taken")
+	}
+}
+
+// WitnessDose records the second sign-off of a controlled substance dose
+func (uc *MedicalUseCase) WitnessDose(ctx context.Context, doseID, witnessID primitive.ObjectID) (*entities.MedicationDose, error) {
+	dose, err := uc.doseRepo.FindByID(ctx, doseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dose.Status != entities.DoseStatusAwaitingWitness {
+		return nil, errors.NewBadRequest("dose is not awaiting a witness sign-off")
+	}
+
+	if dose.AdministeredBy != nil && *dose.AdministeredBy == witnessID {
+		return nil, errors.NewForbidden("the witness must be a different user than the one who administered the dose")
+	}
+
+	now := time.Now()
+	dose.WitnessedBy = &witnessID
+	dose.WitnessedAt = &now
+	dose.Status = entities.DoseStatusGiven
+
+	if err := uc.doseRepo.Update(ctx, dose); err != nil {
+		return nil, err
+	}
+
+	if err := uc.medicationRepo.SetAdministrationWitness(ctx, dose.MedicationID, dose.ID, witnessID, now); err != nil {
+		return nil, err
+	}
+
+	uc.auditLogRepo.Create(ctx, &entities.AuditLog{
+		UserID:     witnessID,
+		Action:     entities.ActionUpdate,
+		EntityType: "medication",
+		EntityID:   &dose.MedicationID,
+		Changes: map[string]interface{}{
+			"dose_id":         dose.ID,
+			"administered_by": dose.AdministeredBy,
+			"witnessed_by":    witnessID,
+		},
+	})
+
+	return dose, nil
+}
+
+// SkipDose marks an open dose as intentionally not given
+func (uc *MedicalUseCase) SkipDose(ctx context.Context, doseID primitive.ObjectID, reason string, userID primitive.ObjectID) (*entities.MedicationDose, error) {
+	dose, err := uc.doseRepo.FindByID(ctx, doseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dose.IsOpen() {
+		return nil, errors.NewBadRequest("dose has already been recorded")
+	}
+
+	dose.Status = entities.DoseStatusSkipped
+	dose.Notes = reason
+	dose.AdministeredBy = &userID
+
+	if err := uc.doseRepo.Update(ctx, dose); err != nil {
+		return nil, err
+	}
+
+	return dose, nil
+}
+
+// GetDosesDueNow returns open doses that are overdue or due within the window
+func (uc *MedicalUseCase) GetDosesDueNow(ctx context.Context, window time.Duration) ([]*entities.MedicationDose, error) {
+	now := time.Now()
+	if err := uc.GenerateDoses(ctx, now, now.Add(window)); err != nil {
+		return nil, err
+	}
+
+	from := now.Add(-missedDoseLookback)
+	to := now.Add(window)
+	return uc.doseRepo.List(ctx, repositories.MedicationDoseFilter{
+		Statuses: []entities.MedicationDoseStatus{entities.DoseStatusScheduled, entities.DoseStatusMissed},
+		From:     &from,
+		To:       &to,
+	})
+}
+
+// GetShiftDoses returns the administration record of a shift, generating missing slots first
+func (uc *MedicalUseCase) GetShiftDoses(ctx context.Context, day time.Time, shift entities.MedicationShift, animalID *primitive.ObjectID) ([]*entities.MedicationDose, error) {
+	from, to, err := entities.ShiftWindow(day, shift)
+	if err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+
+	if err := uc.GenerateDoses(ctx, from, to); err != nil {
+		return nil, err
+	}
+
+	return uc.doseRepo.List(ctx, repositories.MedicationDoseFilter{
+		AnimalID: animalID,
+		From:     &from,
+		To:       &to,
+	})
+}
+
+// GenerateDoses creates the dose slots of all scheduled active medications in [from, to)
+func (uc *MedicalUseCase) GenerateDoses(ctx context.Context, from, to time.Time) error {
+	medications, err := uc.medicationRepo.FindScheduledActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	animals := make(map[primitive.ObjectID]*entities.Animal)
+	for _, medication := range medications {
+		if err := uc.scheduleDoses(ctx, medication, from, to, animals); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProcessMedicationSchedules generates upcoming dose slots and escalates
+// missed doses. It is run periodically by the scheduler.
+func (uc *MedicalUseCase) ProcessMedicationSchedules(ctx context.Context) error {
+	now := time.Now()
+	if err := uc.GenerateDoses(ctx, now, now.Add(doseHorizon)); err != nil {
+		return err
+	}
+
+	return uc.DetectMissedDoses(ctx, now)
+}
+
+// DetectMissedDoses marks doses past their grace period as missed and sends
+// notifications that escalate from the prescriber and caretaker to administrators.
+func (uc *MedicalUseCase) DetectMissedDoses(ctx context.Context, now time.Time) error {
+	from := now.Add(-missedDoseLookback)
+	to := now.Add(-entities.MedicationDoseGracePeriod)
+	doses, err := uc.doseRepo.List(ctx, repositories.MedicationDoseFilter{
+		Statuses: []entities.MedicationDoseStatus{entities.DoseStatusScheduled, entities.DoseStatusMissed},
+		From:     &from,
+		To:       &to,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dose := range doses {
+		level := dose.EscalationDue(now)
+		if level == 0 || (dose.Status == entities.DoseStatusMissed && level <= dose.EscalationLevel) {
+			continue
+		}
+
+		dose.Status = entities.DoseStatusMissed
+		if level > dose.EscalationLevel {
+			uc.notifyMissedDose(ctx, dose, level)
+			dose.EscalationLevel = level
+			dose.LastEscalatedAt = &now
+		}
+
+		if err := uc.doseRepo.Update(ctx, dose); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *MedicalUseCase) doseForAdministration(ctx context.Context, medication *entities.Medication, doseID string, now time.Time) (*entities.MedicationDose, error) {
+	if doseID != "" {
+		id, err := primitive.ObjectIDFromHex(doseID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid dose ID")
+		}
+		dose, err := uc.doseRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if dose.MedicationID != medication.ID {
+			return nil, errors.NewBadRequest("dose does not belong to this medication")
+		}
+		if !dose.IsOpen() {
+			return nil, errors.NewBadRequest("dose has already been recorded")
+		}
+		return dose, nil
+	}
+
+	schedule := medication.Schedule
+	if schedule != nil && !schedule.IsPRN() {
+		from := now.Add(-doseMatchWindow)
+		to := now.Add(entities.MedicationDoseGracePeriod)
+		open, err := uc.doseRepo.List(ctx, repositories.MedicationDoseFilter{
+			MedicationID: &medication.ID,
+			Statuses:     []entities.MedicationDoseStatus{entities.DoseStatusScheduled, entities.DoseStatusMissed},
+			From:         &from,
+			To:           &to,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(open) == 0 {
+			return nil, errors.NewBadRequest("no dose of this medication is due now")
+		}
+		return closestDose(open, now), nil
+	}
+
+	if schedule.IsPRN() {
+		if err := checkPRNLimits(medication, schedule, now); err != nil {
+			return nil, err
+		}
+	}
+
+	dose := entities.NewMedicationDose(medication, now, medication.Dosage)
+	dose.PRN = schedule.IsPRN()
+	uc.fillAnimalDetails(ctx, dose, map[primitive.ObjectID]*entities.Animal{})
+	return dose, nil
+}
+
+func checkPRNLimits(medication *entities.Medication, schedule *entities.DoseSchedule, now time.Time) error {
+	dayAgo := now.Add(-24 * time.Hour)
+	given := 0
+	var last time.Time
+	for _, log := range medication.AdministrationLogs {
+		if log.AdministeredAt.After(dayAgo) {
+			given++
+		}
+		if log.AdministeredAt.After(last) {
+			last = log.AdministeredAt
+		}
+	}
+
+	if schedule.MaxDailyDoses > 0 && given >= schedule.MaxDailyDoses {
+		return errors.NewBadRequest(fmt.Sprintf("maximum of %d doses in 24 hours reached", schedule.MaxDailyDoses))
+	}
+
+	minGap := time.Duration(schedule.MinHoursBetween) * time.Hour
+	if minGap > 0 && !last.IsZero() && now.Sub(last) < minGap {
+		return errors.NewBadRequest(fmt.Sprintf("doses must be at least %d hours apart, next dose allowed at %s",
+			schedule.MinHoursBetween, last.Add(minGap).Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+func closestDose(doses []*entities.MedicationDose, now time.Time) *entities.MedicationDose {
+	closest := doses[0]
+	for _, dose := range doses[1:] {
+		if absDuration(dose.ScheduledFor.Sub(now)) < absDuration(closest.ScheduledFor.Sub(now)) {
+			closest = dose
+		}
+	}
+	return closest
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// scheduleDoses creates the dose slots of a medication in [from, to)
+func (uc *MedicalUseCase) scheduleDoses(ctx context.Context, medication *entities.Medication, from, to time.Time, animals map[primitive.ObjectID]*entities.Animal) error {
+	planned := medication.DosesBetween(from, to)
+	if len(planned) == 0 {
+		return nil
+	}
+
+	doses := make([]*entities.MedicationDose, 0, len(planned))
+	for _, p := range planned {
+		dose := entities.NewMedicationDose(medication, p.At, p.Dosage)
+		uc.fillAnimalDetails(ctx, dose, animals)
+		doses = append(doses, dose)
+	}
+
+	return uc.doseRepo.CreateScheduled(ctx, doses)
+}
+
+// fillAnimalDetails copies the name and kennel location of the animal so the
+// administration record can be read without looking up each animal.
+func (uc *MedicalUseCase) fillAnimalDetails(ctx context.Context, dose *entities.MedicationDose, animals map[primitive.ObjectID]*entities.Animal) {
+	animal, ok := animals[dose.AnimalID]
+	if !ok {
+		animal, _ = uc.animalRepo.FindByID(ctx, dose.AnimalID)
+		animals[dose.AnimalID] = animal
+	}
+	if animal == nil {
+		return
+	}
+
+	dose.AnimalName = animal.Name.English
+	if dose.AnimalName == "" {
+		dose.AnimalName = animal.Name.Polish
+	}
+	dose.Location = animal.Shelter.Location
+}
+
+// resetDoses replaces the open future slots of a medication after it changed
+func (uc *MedicalUseCase) resetDoses(ctx context.Context, medication *entities.Medication) error {
+	now := time.Now()
+	if err := uc.doseRepo.DeleteScheduled(ctx, medication.ID, now); err != nil {
+		return err
+	}
+
+	if medication.Status != entities.MedicationStatusActive || medication.Schedule.IsPRN() {
+		return nil
+	}
+
+	return uc.scheduleDoses(ctx, medication, now, now.Add(doseHorizon), map[primitive.ObjectID]*entities.Animal{})
+}
+
+// normalizeSchedule derives a schedule from the free-text frequency when none
+// is given and validates it
+func normalizeSchedule(medication *entities.Medication) error {
+	if medication.Schedule == nil {
+		medication.Schedule = entities.ParseFrequency(medication.Frequency)
+	}
+	if medication.Schedule == nil {
+		return nil
+	}
+
+	if err := medication.Schedule.Validate(); err != nil {
+		return errors.NewBadRequest(err.Error())
+	}
+	return nil
+}
+
+func (uc *MedicalUseCase) notifyMissedDose(ctx context.Context, dose *entities.MedicationDose, level int) {
+	if uc.notifier == nil {
+		return
+	}
+
+	recipients := uc.missedDoseRecipients(ctx, dose, level)
+	if len(recipients) == 0 {
+		return
+	}
+
+	animal := dose.AnimalName
+	if animal == "" {
+		animal = dose.AnimalID.Hex()
+	}
+	title := fmt.Sprintf("Missed dose: %s", dose.MedicationName)
+	message := fmt.Sprintf("%s %s %s for %s was due at %s and has not been given.",
+		dose.MedicationName, dose.Dosage, dose.Unit, animal, dose.ScheduledFor.Format("2006-01-02 15:04"))
+
+	for userID := range recipients {
+		var notification *entities.Notification
+		if level >= entities.MedicationMaxEscalationLevel {
+			notification = entities.NewErrorNotification(userID, title, message)
+		} else {
+			notification = entities.NewWarningNotification(userID, title, message)
+		}
+		notification.Category = "medication"
+		notification.RelatedType = "medication"
+		notification.RelatedID = &dose.MedicationID
+		notification.GroupKey = "missed_dose:" + dose.ID.Hex()
+		notification.Metadata["dose_id"] = dose.ID.Hex()
+		notification.Metadata["escalation_level"] = level
+
+		_ = uc.notifier.CreateNotification(ctx, notification)
+	}
+}
+
+// missedDoseRecipients returns the prescriber and caretaker of the animal at
+// the first level and administrators from the second level on
+func (uc *MedicalUseCase) missedDoseRecipients(ctx context.Context, dose *entities.MedicationDose, level int) map[primitive.ObjectID]bool {
+	recipients := make(map[primitive.ObjectID]bool)
+
+	if level == 1 {
+		if medication, err := uc.medicationRepo.FindByID(ctx, dose.MedicationID); err == nil && !medication.PrescribedBy.IsZero() {
+			recipients[medication.PrescribedBy] = true
+		}
+		if animal, err := uc.animalRepo.FindByID(ctx, dose.AnimalID); err == nil && animal.Shelter.AssignedCaretaker != nil {
+			recipients[*animal.Shelter.AssignedCaretaker] = true
+		}
+		if len(recipients) > 0 {
+			return recipients
+		}
+	}
+
+	if uc.userRepo == nil {
+		return recipients
+	}
+	for _, role := range []entities.UserRole{entities.RoleAdmin, entities.RoleSuperAdmin} {
+		users, _, err := uc.userRepo.List(ctx, repositories.UserFilter{Role: string(role), Status: string(entities.StatusActive), Limit: 100})
+		if err != nil {
+			continue
+		}
+		for _, user := range users {
+			recipients[user.ID] = true
+		}
+	}
+
+	return recipients
+}
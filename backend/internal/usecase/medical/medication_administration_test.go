@@ -0,0 +1,279 @@
+package medical
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type recordingStockConsumer struct {
+	consumptions []*entities.StockConsumption
+	returned     []*entities.StockTransaction
+	err          error
+}
+
+func (s *recordingStockConsumer) ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.consumptions = append(s.consumptions, consumption)
+	first := entities.NewStockTransaction(consumption.ItemID, entities.TransactionTypeOut, 1, 3, userID)
+	first.LotNumber = "L-1"
+	second := entities.NewStockTransaction(consumption.ItemID, entities.TransactionTypeOut, consumption.Quantity-1, 2, userID)
+	second.LotNumber = "L-2"
+	return []*entities.StockTransaction{first, second}, nil
+}
+
+func (s *recordingStockConsumer) ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error {
+	s.returned = append(s.returned, transactions...)
+	return nil
+}
+
+func TestMedication_DosesBetween(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("fixed times of day", func(t *testing.T) {
+		medication := &entities.Medication{
+			Dosage:    "1",
+			StartDate: start,
+			Schedule:  &entities.DoseSchedule{Type: entities.DoseScheduleFixedTimes, TimesOfDay: []string{"20:00", "08:00"}},
+		}
+		doses := medication.DosesBetween(start.Add(10*time.Hour), start.Add(34*time.Hour))
+		require.Len(t, doses, 2)
+		assert.Equal(t, start.Add(20*time.Hour), doses[0].At)
+		assert.Equal(t, start.Add(32*time.Hour), doses[1].At)
+	})
+
+	t.Run("interval aligned to the start date", func(t *testing.T) {
+		medication := &entities.Medication{
+			Dosage:    "5 ml",
+			StartDate: start.Add(7 * time.Hour),
+			Schedule:  &entities.DoseSchedule{Type: entities.DoseScheduleInterval, IntervalHours: 8},
+		}
+		doses := medication.DosesBetween(start.Add(10*time.Hour), start.Add(24*time.Hour))
+		require.Len(t, doses, 2)
+		assert.Equal(t, start.Add(15*time.Hour), doses[0].At)
+		assert.Equal(t, start.Add(23*time.Hour), doses[1].At)
+	})
+
+	t.Run("tapering changes dosage per step and stops after the last step", func(t *testing.T) {
+		medication := &entities.Medication{
+			StartDate: start,
+			Schedule: &entities.DoseSchedule{Type: entities.DoseScheduleTapering, TaperSteps: []entities.TaperStep{
+				{Days: 2, Dosage: "2 tablets", TimesOfDay: []string{"08:00", "20:00"}},
+				{Days: 1, Dosage: "1 tablet", TimesOfDay: []string{"08:00"}},
+			}},
+		}
+		doses := medication.DosesBetween(start, start.AddDate(0, 0, 7))
+		require.Len(t, doses, 5)
+		assert.Equal(t, "2 tablets", doses[3].Dosage)
+		assert.Equal(t, "1 tablet", doses[4].Dosage)
+		assert.Equal(t, start.AddDate(0, 0, 2).Add(8*time.Hour), doses[4].At)
+	})
+
+	t.Run("end date and prn produce no slots", func(t *testing.T) {
+		end := start.Add(9 * time.Hour)
+		medication := &entities.Medication{
+			StartDate: start,
+			EndDate:   &end,
+			Schedule:  &entities.DoseSchedule{Type: entities.DoseScheduleFixedTimes, TimesOfDay: []string{"08:00", "20:00"}},
+		}
+		assert.Len(t, medication.DosesBetween(start, start.AddDate(0, 0, 1)), 1)
+
+		medication.Schedule = &entities.DoseSchedule{Type: entities.DoseSchedulePRN}
+		assert.Empty(t, medication.DosesBetween(start, start.AddDate(0, 0, 1)))
+	})
+}
+
+func TestParseFrequency(t *testing.T) {
+	assert.Equal(t, []string{"08:00", "20:00"}, entities.ParseFrequency("Twice daily").TimesOfDay)
+	assert.Equal(t, 8, entities.ParseFrequency("every 8 hours").IntervalHours)
+	assert.Equal(t, 12, entities.ParseFrequency("q12h").IntervalHours)
+	assert.True(t, entities.ParseFrequency("as needed for pain").IsPRN())
+	assert.Nil(t, entities.ParseFrequency("see instructions"))
+}
+
+func TestMedicalUseCase_RecordMedicationAdministration(t *testing.T) {
+	ctx := context.Background()
+	nurse := primitive.NewObjectID()
+
+	t.Run("controlled substance waits for a witness", func(t *testing.T) {
+		mockMedicationRepo := new(mocks.MedicationRepository)
+		mockDoseRepo := new(mocks.MedicationDoseRepository)
+		mockAuditLogRepo := new(mocks.AuditLogRepository)
+		useCase := NewMedicalUseCase(nil, mockMedicationRepo, nil, nil, mockAuditLogRepo, mockDoseRepo, nil, nil, nil)
+
+		medication := &entities.Medication{
+			ID:                  primitive.NewObjectID(),
+			MedicationName:      "Buprenorphine",
+			Status:              entities.MedicationStatusActive,
+			ControlledSubstance: true,
+			Schedule:            &entities.DoseSchedule{Type: entities.DoseScheduleInterval, IntervalHours: 8},
+		}
+		dose := entities.NewMedicationDose(medication, time.Now().Add(-10*time.Minute), "0.1 ml")
+		dose.ID = primitive.NewObjectID()
+
+		mockMedicationRepo.On("FindByID", ctx, medication.ID).Return(medication, nil)
+		mockDoseRepo.On("List", ctx, mock.Anything).Return([]*entities.MedicationDose{dose}, nil)
+		mockDoseRepo.On("Update", ctx, dose).Return(nil)
+		mockMedicationRepo.On("AddAdministrationLog", ctx, medication.ID, mock.MatchedBy(func(log entities.AdministrationLog) bool {
+			return *log.DoseID == dose.ID && log.ScheduledFor != nil && log.WitnessedBy == nil
+		})).Return(nil)
+
+		recorded, err := useCase.RecordMedicationAdministration(ctx, medication.ID, &AdministerMedicationRequest{DosageGiven: "0.1 ml"}, nurse)
+		require.NoError(t, err)
+		assert.Equal(t, entities.DoseStatusAwaitingWitness, recorded.Status)
+
+		mockDoseRepo.On("FindByID", ctx, dose.ID).Return(dose, nil)
+		_, err = useCase.WitnessDose(ctx, dose.ID, nurse)
+		var appErr *errors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, 403, appErr.Code)
+
+		witness := primitive.NewObjectID()
+		mockMedicationRepo.On("SetAdministrationWitness", ctx, medication.ID, dose.ID, witness, mock.AnythingOfType("time.Time")).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		witnessed, err := useCase.WitnessDose(ctx, dose.ID, witness)
+		require.NoError(t, err)
+		assert.Equal(t, entities.DoseStatusGiven, witnessed.Status)
+		assert.Equal(t, &witness, witnessed.WitnessedBy)
+		mockMedicationRepo.AssertExpectations(t)
+	})
+
+	t.Run("linked inventory item is consumed for the animal", func(t *testing.T) {
+		mockMedicationRepo := new(mocks.MedicationRepository)
+		mockDoseRepo := new(mocks.MedicationDoseRepository)
+		mockAnimalRepo := new(mocks.AnimalRepository)
+		stock := &recordingStockConsumer{}
+		useCase := NewMedicalUseCase(nil, mockMedicationRepo, nil, mockAnimalRepo, nil, mockDoseRepo, nil, nil, stock)
+
+		itemID := primitive.NewObjectID()
+		medication := &entities.Medication{
+			ID:              primitive.NewObjectID(),
+			AnimalID:        primitive.NewObjectID(),
+			Status:          entities.MedicationStatusActive,
+			Schedule:        &entities.DoseSchedule{Type: entities.DoseSchedulePRN},
+			InventoryItemID: &itemID,
+			QuantityPerDose: 2,
+		}
+		mockMedicationRepo.On("FindByID", ctx, medication.ID).Return(medication, nil)
+		mockAnimalRepo.On("FindByID", ctx, medication.AnimalID).Return(&entities.Animal{ID: medication.AnimalID}, nil)
+		mockDoseRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockDoseRepo.On("Update", ctx, mock.Anything).Return(nil)
+		mockMedicationRepo.On("AddAdministrationLog", ctx, medication.ID, mock.MatchedBy(func(log entities.AdministrationLog) bool {
+			return log.LotNumber == "L-1, L-2"
+		})).Return(nil)
+
+		dose, err := useCase.RecordMedicationAdministration(ctx, medication.ID, &AdministerMedicationRequest{DosageGiven: "2 tablets"}, nurse)
+		require.NoError(t, err)
+
+		require.Len(t, stock.consumptions, 1)
+		assert.Equal(t, itemID, stock.consumptions[0].ItemID)
+		assert.Equal(t, 2.0, stock.consumptions[0].Quantity)
+		assert.Equal(t, "animal", stock.consumptions[0].RelatedEntity)
+		assert.Equal(t, &medication.AnimalID, stock.consumptions[0].RelatedEntityID)
+		assert.Equal(t, "L-1, L-2", dose.LotNumber)
+		mockMedicationRepo.AssertExpectations(t)
+	})
+
+	t.Run("dose is undone when its stock cannot be taken", func(t *testing.T) {
+		mockMedicationRepo := new(mocks.MedicationRepository)
+		mockDoseRepo := new(mocks.MedicationDoseRepository)
+		stock := &recordingStockConsumer{err: errors.NewBadRequest("Insufficient stock")}
+		useCase := NewMedicalUseCase(nil, mockMedicationRepo, nil, nil, nil, mockDoseRepo, nil, nil, stock)
+
+		itemID := primitive.NewObjectID()
+		medication := &entities.Medication{
+			ID:              primitive.NewObjectID(),
+			Status:          entities.MedicationStatusActive,
+			Schedule:        &entities.DoseSchedule{Type: entities.DoseScheduleInterval, IntervalHours: 8},
+			InventoryItemID: &itemID,
+		}
+		slot := entities.NewMedicationDose(medication, time.Now().Add(-10*time.Minute), "1")
+		slot.ID = primitive.NewObjectID()
+		mockMedicationRepo.On("FindByID", ctx, medication.ID).Return(medication, nil)
+		mockDoseRepo.On("FindByID", ctx, slot.ID).Return(slot, nil)
+		var saved []entities.MedicationDoseStatus
+		mockDoseRepo.On("Update", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			saved = append(saved, args.Get(1).(*entities.MedicationDose).Status)
+		}).Return(nil)
+
+		_, err := useCase.RecordMedicationAdministration(ctx, medication.ID, &AdministerMedicationRequest{DoseID: slot.ID.Hex(), DosageGiven: "1"}, nurse)
+		require.Error(t, err)
+
+		assert.Equal(t, []entities.MedicationDoseStatus{entities.DoseStatusGiven, entities.DoseStatusScheduled}, saved, "the slot is recorded, then reopened")
+		mockMedicationRepo.AssertNotCalled(t, "AddAdministrationLog", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("prn limits are enforced", func(t *testing.T) {
+		mockMedicationRepo := new(mocks.MedicationRepository)
+		useCase := NewMedicalUseCase(nil, mockMedicationRepo, nil, nil, nil, new(mocks.MedicationDoseRepository), nil, nil, nil)
+
+		medication := &entities.Medication{
+			ID:       primitive.NewObjectID(),
+			Status:   entities.MedicationStatusActive,
+			Schedule: &entities.DoseSchedule{Type: entities.DoseSchedulePRN, MinHoursBetween: 6, MaxDailyDoses: 3},
+			AdministrationLogs: []entities.AdministrationLog{
+				{AdministeredAt: time.Now().Add(-2 * time.Hour)},
+			},
+		}
+		mockMedicationRepo.On("FindByID", ctx, medication.ID).Return(medication, nil)
+
+		_, err := useCase.RecordMedicationAdministration(ctx, medication.ID, &AdministerMedicationRequest{DosageGiven: "1"}, nurse)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 6 hours apart")
+	})
+}
+
+func TestMedicalUseCase_DetectMissedDoses(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	mockMedicationRepo := new(mocks.MedicationRepository)
+	mockDoseRepo := new(mocks.MedicationDoseRepository)
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	notifier := &testutil.Notifier{}
+	useCase := NewMedicalUseCase(nil, mockMedicationRepo, nil, mockAnimalRepo, nil, mockDoseRepo, nil, notifier, nil)
+
+	vet := primitive.NewObjectID()
+	caretaker := primitive.NewObjectID()
+	medication := &entities.Medication{ID: primitive.NewObjectID(), AnimalID: primitive.NewObjectID(), PrescribedBy: vet, MedicationName: "Meloxicam"}
+	animal := &entities.Animal{ID: medication.AnimalID, Shelter: entities.ShelterInfo{AssignedCaretaker: &caretaker}}
+
+	onTime := entities.NewMedicationDose(medication, now.Add(-30*time.Minute), "0.5 ml")
+	late := entities.NewMedicationDose(medication, now.Add(-90*time.Minute), "0.5 ml")
+	late.ID = primitive.NewObjectID()
+	alreadyEscalated := entities.NewMedicationDose(medication, now.Add(-2*time.Hour), "0.5 ml")
+	alreadyEscalated.Status = entities.DoseStatusMissed
+	alreadyEscalated.EscalationLevel = 1
+
+	mockDoseRepo.On("List", ctx, mock.Anything).Return([]*entities.MedicationDose{onTime, late, alreadyEscalated}, nil)
+	mockDoseRepo.On("Update", ctx, late).Return(nil).Once()
+	mockMedicationRepo.On("FindByID", ctx, medication.ID).Return(medication, nil)
+	mockAnimalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+
+	require.NoError(t, useCase.DetectMissedDoses(ctx, now))
+
+	assert.Equal(t, entities.DoseStatusScheduled, onTime.Status)
+	assert.Equal(t, entities.DoseStatusMissed, late.Status)
+	assert.Equal(t, 1, late.EscalationLevel)
+	require.Len(t, notifier.Notifications, 2)
+	recipients := []primitive.ObjectID{notifier.Notifications[0].UserID, notifier.Notifications[1].UserID}
+	assert.ElementsMatch(t, []primitive.ObjectID{vet, caretaker}, recipients)
+	assert.Equal(t, "missed_dose:"+late.ID.Hex(), notifier.Notifications[0].GroupKey)
+	mockDoseRepo.AssertExpectations(t)
+
+	// Three hours late the dose escalates to the second level
+	later := now.Add(2 * time.Hour)
+	assert.Equal(t, 2, late.EscalationDue(later))
+}
@@ -0,0 +1,232 @@
+package outcome
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/usecase/attendee"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeedbackView is what the attendee sees on the feedback page
+type FeedbackView struct {
+	EventName    string    `json:"event_name"`
+	EventDate    time.Time `json:"event_date"`
+	AttendeeName string    `json:"attendee_name,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// SubmitFeedbackRequest is the attendee's rating of an event
+type SubmitFeedbackRequest struct {
+	Rating   int    `json:"rating" validate:"required,min=1,max=5"`
+	Feedback string `json:"feedback,omitempty" validate:"max=4000"`
+}
+
+// SendFeedbackRequests sends the feedback survey of a completed event to its attendees
+// now, with a new link for those who got one before, and returns the number of
+// attendees asked
+func (uc *OutcomeUseCase) SendFeedbackRequests(ctx context.Context, eventID, userID primitive.ObjectID) (int, error) {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if event.Status != entities.EventStatusCompleted {
+		return 0, errors.NewBadRequest("Feedback can only be requested for completed events")
+	}
+
+	now := time.Now()
+	sent, err := uc.requestFeedback(ctx, event, userID, now)
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.eventRepo.MarkFeedbackRequested(ctx, event.ID, now); err != nil {
+		return 0, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event", "feedback_requested", "").
+			WithEntityID(event.ID).
+			WithChanges(map[string]interface{}{"recipients": sent}))
+
+	return sent, nil
+}
+
+// requestFeedback sends a feedback survey link to every attendee of an event who has not
+// rated it yet, and returns the number of attendees asked. The event is not saved.
+func (uc *OutcomeUseCase) requestFeedback(ctx context.Context, event *entities.Event, userID primitive.ObjectID, now time.Time) (int, error) {
+	if uc.messenger == nil {
+		return 0, errors.NewBadRequest("messaging is not configured")
+	}
+	attendances, err := uc.attendanceRepo.GetAttendanceByEvent(ctx, event.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, attendance := range feedbackRecipients(attendances) {
+		if attendance.HasFeedback() {
+			continue
+		}
+		name, email, phone := attendee.Contact(ctx, uc.volunteerRepo, attendance)
+		if email == "" && phone == "" {
+			continue
+		}
+		channel := entities.TemplateTypeEmail
+		if email == "" {
+			channel = entities.TemplateTypeSMS
+		}
+
+		token, err := newToken()
+		if err != nil {
+			return sent, err
+		}
+		expires := now.Add(uc.linkValidity)
+		url := uc.surveyURL + "/" + token
+
+		subject, body := feedbackMessage(event, name, url, expires, channel == entities.TemplateTypeSMS)
+		communication := entities.NewCommunication(channel, entities.TemplateCategoryEvent, email, subject, body, userID)
+		communication.RecipientPhone = phone
+		communication.RecipientName = name
+		communication.RelatedType = "event"
+		communication.RelatedID = &event.ID
+		communication.Metadata["attendance_id"] = attendance.ID.Hex()
+		communication.Metadata["feedback"] = true
+		if err := uc.messenger.CreateCommunication(ctx, communication, userID); err != nil {
+			continue
+		}
+
+		attendance.FeedbackRequestedAt = &now
+		attendance.FeedbackTokenHash = entities.HashToken(token)
+		attendance.FeedbackTokenExpiresAt = &expires
+		attendance.UpdatedBy = userID
+		if err := uc.attendanceRepo.Update(ctx, attendance); err != nil {
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// feedbackRecipients returns the registrations asked for feedback: the checked-in
+// attendees, or everyone holding a paid seat when the event did not check anyone in
+func feedbackRecipients(attendances []*entities.EventAttendance) []*entities.EventAttendance {
+	var attended, seated []*entities.EventAttendance
+	for _, attendance := range attendances {
+		switch {
+		case attendance.IsAttended():
+			attended = append(attended, attendance)
+		case attendance.HoldsSeats() && attendance.IsPaid():
+			seated = append(seated, attendance)
+		}
+	}
+	if len(attended) > 0 {
+		return attended
+	}
+	return seated
+}
+
+// ViewFeedback opens the feedback survey of an attendee through its link
+func (uc *OutcomeUseCase) ViewFeedback(ctx context.Context, token string) (*FeedbackView, error) {
+	attendance, event, err := uc.openLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	name, _, _ := attendee.Contact(ctx, uc.volunteerRepo, attendance)
+	return &FeedbackView{
+		EventName:    event.DisplayName(),
+		EventDate:    event.StartDate,
+		AttendeeName: name,
+		ExpiresAt:    *attendance.FeedbackTokenExpiresAt,
+	}, nil
+}
+
+// SubmitFeedback stores the attendee's rating of an event. The link cannot be used again.
+func (uc *OutcomeUseCase) SubmitFeedback(ctx context.Context, token string, req *SubmitFeedbackRequest) error {
+	attendance, event, err := uc.openLink(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	attendance.SubmitFeedback(req.Rating, strings.TrimSpace(req.Feedback))
+	if err := uc.attendanceRepo.Update(ctx, attendance); err != nil {
+		return err
+	}
+
+	// The attendee may have no user account, so the entry is on behalf of the event's creator
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(event.CreatedBy, entities.ActionUpdate, "event_attendance", "", "event feedback submitted by the attendee").
+			WithEntityID(attendance.ID).
+			WithChanges(map[string]interface{}{
+				"event_id": event.ID,
+				"rating":   req.Rating,
+			}))
+
+	return nil
+}
+
+// openLink finds the registration and event of a feedback link that can still be answered
+func (uc *OutcomeUseCase) openLink(ctx context.Context, token string) (*entities.EventAttendance, *entities.Event, error) {
+	attendance, err := uc.attendanceRepo.FindByFeedbackToken(ctx, entities.HashToken(token))
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, nil, errLinkInvalid
+		}
+		return nil, nil, err
+	}
+	if attendance.HasFeedback() {
+		return nil, nil, errLinkInvalid
+	}
+	if !attendance.FeedbackLinkOpen(time.Now()) {
+		return nil, nil, errLinkExpired
+	}
+
+	event, err := uc.eventRepo.FindByID(ctx, attendance.EventID)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, nil, errLinkInvalid
+		}
+		return nil, nil, err
+	}
+	return attendance, event, nil
+}
+
+// feedbackMessage returns the subject and body of the feedback survey invitation; text
+// messages get a short body
+func feedbackMessage(event *entities.Event, name, url string, expires time.Time, short bool) (string, string) {
+	title := event.DisplayName()
+	if short {
+		return "", fmt.Sprintf("Thank you for coming to %s! Tell us how it was: %s", title, url)
+	}
+
+	greeting := "Hello"
+	if name != "" {
+		greeting += " " + name
+	}
+	return fmt.Sprintf("How was %s?", title),
+		fmt.Sprintf("%s,\n\nthank you for coming to %s on %s. We would love to hear what you thought of it. "+
+			"Please rate the event in our short survey:\n\n%s\n\nThe link is valid until %s.",
+			greeting, title, event.StartDate.Format("2006-01-02"), url, expires.Format("2006-01-02"))
+}
+
+// newToken returns a random survey link token
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, 500, "failed to generate survey token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// errLinkInvalid is returned for a feedback link that does not open an unanswered survey
+var errLinkInvalid = errors.NewNotFound("the feedback link is invalid or the survey was already answered")
+
+// errLinkExpired is returned for a feedback link past its expiry
+var errLinkExpired = errors.New(http.StatusGone, "the feedback link has expired")
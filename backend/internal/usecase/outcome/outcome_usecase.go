@@ -0,0 +1,320 @@
+package outcome
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// outcomeWindow is how long after an event its outcomes are refreshed, since donations
+// and adoptions made at it are often recorded some days later
+const outcomeWindow = 30 * 24 * time.Hour
+
+// feedbackCutoff is how long after an event the job still sends its feedback surveys
+const feedbackCutoff = 7 * 24 * time.Hour
+
+// Messenger queues email and SMS messages to attendees
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// OutcomeUseCase works out what events achieved: it computes the funds raised, the
+// adoptions and the volunteer hours of an event, asks its attendees for feedback once it
+// is over and reports its return on investment
+type OutcomeUseCase struct {
+	eventRepo      repositories.EventRepository
+	attendanceRepo repositories.EventAttendanceRepository
+	donationRepo   repositories.DonationRepository
+	adoptionRepo   repositories.AdoptionRepository
+	animalRepo     repositories.AnimalRepository
+	assignmentRepo repositories.VolunteerAssignmentRepository
+	volunteerRepo  repositories.VolunteerRepository
+	auditLogRepo   repositories.AuditLogRepository
+	messenger      Messenger
+	surveyURL      string        // the feedback page of the web app; the token is appended
+	feedbackDelay  time.Duration // surveys go out this long after an event ends
+	linkValidity   time.Duration
+}
+
+// NewOutcomeUseCase creates a new event outcome use case
+func NewOutcomeUseCase(
+	eventRepo repositories.EventRepository,
+	attendanceRepo repositories.EventAttendanceRepository,
+	donationRepo repositories.DonationRepository,
+	adoptionRepo repositories.AdoptionRepository,
+	animalRepo repositories.AnimalRepository,
+	assignmentRepo repositories.VolunteerAssignmentRepository,
+	volunteerRepo repositories.VolunteerRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	messenger Messenger,
+	surveyURL string,
+	feedbackDelay time.Duration,
+	linkValidity time.Duration,
+) *OutcomeUseCase {
+	return &OutcomeUseCase{
+		eventRepo:      eventRepo,
+		attendanceRepo: attendanceRepo,
+		donationRepo:   donationRepo,
+		adoptionRepo:   adoptionRepo,
+		animalRepo:     animalRepo,
+		assignmentRepo: assignmentRepo,
+		volunteerRepo:  volunteerRepo,
+		auditLogRepo:   auditLogRepo,
+		messenger:      messenger,
+		surveyURL:      surveyURL,
+		feedbackDelay:  feedbackDelay,
+		linkValidity:   linkValidity,
+	}
+}
+
+// outcomes are the records an event is credited with
+type outcomes struct {
+	donations   []*entities.Donation            // completed donations made at the event or to its campaign during it
+	attendances []*entities.EventAttendance     // registrations, for ticket sales and attendance
+	adoptions   []*entities.Adoption            // adoptions of the animals at the event, finalized during it
+	assignments []*entities.VolunteerAssignment // completed volunteer assignments of the event
+}
+
+// collect finds the records an event is credited with:
+//   - donations recorded for the event, and donations to its campaign on the days of the event
+//   - adoptions finalized on the days of the event, of the animals brought to it or housed
+//     at its location then; returned adoptions still count
+//   - completed volunteer assignments of the event
+func (uc *OutcomeUseCase) collect(ctx context.Context, event *entities.Event) (*outcomes, error) {
+	from, to := eventDays(event)
+	result := &outcomes{}
+
+	seen := make(map[primitive.ObjectID]bool)
+	filters := []*repositories.DonationFilter{{EventID: &event.ID, Status: string(entities.DonationStatusCompleted)}}
+	if event.CampaignID != nil {
+		filters = append(filters, &repositories.DonationFilter{
+			CampaignID: event.CampaignID,
+			Status:     string(entities.DonationStatusCompleted),
+			FromDate:   &from,
+			ToDate:     &to,
+		})
+	}
+	for _, filter := range filters {
+		donations, _, err := uc.donationRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, donation := range donations {
+			// Made at another event of the same campaign
+			if donation.EventID != nil && *donation.EventID != event.ID {
+				continue
+			}
+			if !seen[donation.ID] {
+				seen[donation.ID] = true
+				result.donations = append(result.donations, donation)
+			}
+		}
+	}
+
+	attendances, err := uc.attendanceRepo.GetAttendanceByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.attendances = attendances
+
+	animals, err := uc.animalsAtEvent(ctx, event, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(animals) > 0 {
+		adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+			AnimalIDs:         animals,
+			Statuses:          []string{string(entities.AdoptionStatusCompleted), string(entities.AdoptionStatusReturned)},
+			CompletedFromDate: &from,
+			CompletedToDate:   &to,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.adoptions = adoptions
+	}
+
+	if uc.assignmentRepo != nil {
+		assignments, err := uc.assignmentRepo.GetAssignmentsByEvent(ctx, event.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignment := range assignments {
+			if assignment.Status == entities.AssignmentStatusCompleted {
+				result.assignments = append(result.assignments, assignment)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// animalsAtEvent returns the animals brought to an event and those housed at its location
+// while it was on
+func (uc *OutcomeUseCase) animalsAtEvent(ctx context.Context, event *entities.Event, from, to time.Time) ([]primitive.ObjectID, error) {
+	ids := append([]primitive.ObjectID{}, event.Animals...)
+	location := strings.TrimSpace(event.Location.Name)
+	if location == "" || uc.animalRepo == nil {
+		return ids, nil
+	}
+
+	housed, _, err := uc.animalRepo.List(ctx, repositories.AnimalFilter{Locations: []string{location}})
+	if err != nil {
+		return nil, err
+	}
+	brought := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		brought[id] = true
+	}
+	for _, animal := range housed {
+		if !brought[animal.ID] && stayedAt(animal, location, from, to) {
+			brought[animal.ID] = true
+			ids = append(ids, animal.ID)
+		}
+	}
+	return ids, nil
+}
+
+// stayedAt reports whether an animal was housed at a location at some point in the range
+func stayedAt(animal *entities.Animal, location string, from, to time.Time) bool {
+	for _, stay := range animal.LocationStays() {
+		if !strings.EqualFold(strings.TrimSpace(stay.Location), location) {
+			continue
+		}
+		if stay.From.After(to) {
+			continue
+		}
+		if stay.To != nil && stay.To.Before(from) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// donationTotal sums the donations
+func (o *outcomes) donationTotal() float64 {
+	total := 0.0
+	for _, donation := range o.donations {
+		total += donation.Amount
+	}
+	return total
+}
+
+// ticketSales sums the registration fees paid and not refunded
+func (o *outcomes) ticketSales() float64 {
+	total := 0.0
+	for _, attendance := range o.attendances {
+		if attendance.RegistrationFee > 0 && attendance.PaymentStatus == "paid" {
+			total += attendance.RegistrationFee
+		}
+	}
+	return total
+}
+
+// adopted counts the adoptions finalized at the event, returned ones included
+func (o *outcomes) adopted() int {
+	return len(o.adoptions)
+}
+
+// volunteerHours sums the hours logged on the volunteer assignments
+func (o *outcomes) volunteerHours() float64 {
+	total := 0.0
+	for _, assignment := range o.assignments {
+		total += assignment.GetDuration()
+	}
+	return total
+}
+
+// save stores the outcomes in the statistics of the event
+func (o *outcomes) save(ctx context.Context, eventRepo repositories.EventRepository, event *entities.Event, now time.Time) error {
+	fundsRaised, adopted, hours := o.donationTotal()+o.ticketSales(), o.adopted(), o.volunteerHours()
+	if err := eventRepo.UpdateOutcomes(ctx, event.ID, fundsRaised, adopted, hours, now); err != nil {
+		return err
+	}
+	event.FundsRaised = fundsRaised
+	event.AnimalsAdopted = adopted
+	event.VolunteerHours = hours
+	event.OutcomesUpdatedAt = &now
+	return nil
+}
+
+// RefreshOutcomes computes the funds raised, adoptions and volunteer hours of an event
+// now and stores them in its statistics
+func (uc *OutcomeUseCase) RefreshOutcomes(ctx context.Context, eventID, userID primitive.ObjectID) (*entities.Event, error) {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != entities.EventStatusActive && event.Status != entities.EventStatusCompleted {
+		return nil, errors.NewBadRequest("Outcomes can only be computed for active or completed events")
+	}
+
+	result, err := uc.collect(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.save(ctx, uc.eventRepo, event, time.Now()); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionUpdate, "event", "outcomes", "").
+			WithEntityID(event.ID).
+			WithChanges(map[string]interface{}{
+				"funds_raised":    event.FundsRaised,
+				"animals_adopted": event.AnimalsAdopted,
+				"volunteer_hours": event.VolunteerHours,
+			}))
+
+	return event, nil
+}
+
+// ProcessCompletedEvents is the scheduler job following up on completed events. It
+// refreshes their outcomes for a month, while donations and adoptions made at them are
+// still being recorded, and sends the feedback surveys once an event has been over for
+// the configured delay. Events that ended more than a week ago get no surveys.
+func (uc *OutcomeUseCase) ProcessCompletedEvents(ctx context.Context) error {
+	now := time.Now()
+	since := now.Add(-outcomeWindow)
+	events, _, err := uc.eventRepo.List(ctx, &repositories.EventFilter{
+		Status:    string(entities.EventStatusCompleted),
+		StartDate: &since,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Only the outcome and survey fields are written, so staff editing the event meanwhile
+	// keep their changes
+	for _, event := range events {
+		if result, err := uc.collect(ctx, event); err == nil {
+			_ = result.save(ctx, uc.eventRepo, event, now)
+		}
+
+		ended := event.EndTime()
+		if event.FeedbackRequestedAt == nil && !now.Before(ended.Add(uc.feedbackDelay)) && now.Before(ended.Add(feedbackCutoff)) {
+			// Without messaging the surveys stay due until the cutoff
+			if _, err := uc.requestFeedback(ctx, event, event.CreatedBy, now); err == nil {
+				_ = uc.eventRepo.MarkFeedbackRequested(ctx, event.ID, now)
+			}
+		}
+	}
+	return nil
+}
+
+// eventDays returns the start of the first and the end of the last day of an event
+func eventDays(event *entities.Event) (time.Time, time.Time) {
+	start, end := event.StartDate, event.EndTime()
+	if end.Before(start) {
+		end = start
+	}
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	to := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location()).AddDate(0, 0, 1).Add(-time.Nanosecond)
+	return from, to
+}
@@ -0,0 +1,300 @@
+package outcome
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const surveyURL = "https://shelter.example.org/event-feedback"
+
+type outcomeMocks struct {
+	events      *mocks.EventRepository
+	attendances *mocks.EventAttendanceRepository
+	donations   *mocks.DonationRepository
+	adoptions   *mocks.AdoptionRepository
+	animals     *mocks.AnimalRepository
+	assignments *mocks.VolunteerAssignmentRepository
+	volunteers  *mocks.VolunteerRepository
+	messenger   *testutil.Messenger
+}
+
+func newOutcomeUseCase() (*OutcomeUseCase, *outcomeMocks) {
+	m := &outcomeMocks{
+		events:      new(mocks.EventRepository),
+		attendances: new(mocks.EventAttendanceRepository),
+		donations:   new(mocks.DonationRepository),
+		adoptions:   new(mocks.AdoptionRepository),
+		animals:     new(mocks.AnimalRepository),
+		assignments: new(mocks.VolunteerAssignmentRepository),
+		volunteers:  new(mocks.VolunteerRepository),
+		messenger:   &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+	m.events.On("UpdateOutcomes", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.events.On("MarkFeedbackRequested", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	m.attendances.On("Update", mock.Anything, mock.Anything).Return(nil)
+	uc := NewOutcomeUseCase(m.events, m.attendances, m.donations, m.adoptions, m.animals, m.assignments, m.volunteers, auditLogs,
+		m.messenger, surveyURL, 2*time.Hour, 14*24*time.Hour)
+	return uc, m
+}
+
+// completedEvent returns a completed four-hour event that started at the given time
+func completedEvent(m *outcomeMocks, start time.Time) *entities.Event {
+	event := entities.NewEvent(entities.MultilingualName{English: "Adoption Day"}, entities.EventTypeAdoption, start, primitive.NewObjectID(), primitive.NewObjectID())
+	event.ID = primitive.NewObjectID()
+	event.Status = entities.EventStatusCompleted
+	event.Duration = 240
+	m.events.On("FindByID", mock.Anything, event.ID).Return(event, nil)
+	return event
+}
+
+func attendance(event *entities.Event, status entities.AttendanceStatus, fee float64) *entities.EventAttendance {
+	a := entities.NewEventAttendance(event.ID, entities.AttendeeTypePublic, fee, primitive.NewObjectID())
+	a.ID = primitive.NewObjectID()
+	a.Status = status
+	if status == entities.AttendanceStatusAttended {
+		checkIn := event.StartDate.Add(10 * time.Minute)
+		a.CheckInTime = &checkIn
+	}
+	if fee > 0 {
+		a.PaymentStatus = "paid"
+	}
+	return a
+}
+
+func TestGetEventReport_CreditsLinkedDonationsAdoptionsAndHours(t *testing.T) {
+	uc, m := newOutcomeUseCase()
+	event := completedEvent(m, time.Date(2026, 9, 12, 10, 0, 0, 0, time.UTC))
+	campaignID := primitive.NewObjectID()
+	event.CampaignID = &campaignID
+	event.Animals = []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	event.Costs = []entities.EventCost{
+		{Description: "Venue", Category: "venue", Amount: 300},
+		{Description: "Flyers", Category: "marketing", Amount: 150},
+		{Description: "Snacks", Amount: 50},
+	}
+
+	otherEvent := primitive.NewObjectID()
+	atEvent := &entities.Donation{ID: primitive.NewObjectID(), DonorID: primitive.NewObjectID(), Amount: 100, EventID: &event.ID}
+	toCampaign := &entities.Donation{ID: primitive.NewObjectID(), DonorID: primitive.NewObjectID(), Amount: 400}
+	atOtherEvent := &entities.Donation{ID: primitive.NewObjectID(), DonorID: primitive.NewObjectID(), Amount: 50, EventID: &otherEvent}
+	m.donations.On("List", mock.Anything, mock.MatchedBy(func(f *repositories.DonationFilter) bool {
+		return f.EventID != nil && *f.EventID == event.ID
+	})).Return([]*entities.Donation{atEvent}, int64(1), nil)
+	m.donations.On("List", mock.Anything, mock.MatchedBy(func(f *repositories.DonationFilter) bool {
+		return f.CampaignID != nil && f.FromDate.Equal(time.Date(2026, 9, 12, 0, 0, 0, 0, time.UTC)) &&
+			f.ToDate.After(time.Date(2026, 9, 12, 23, 59, 59, 0, time.UTC)) && f.ToDate.Before(time.Date(2026, 9, 13, 0, 0, 0, 0, time.UTC))
+	})).Return([]*entities.Donation{atEvent, toCampaign, atOtherEvent}, int64(3), nil)
+
+	rated := attendance(event, entities.AttendanceStatusAttended, 20)
+	rated.NumberOfGuests = 1
+	rated.SubmitFeedback(5, "Lovely day")
+	noShow := attendance(event, entities.AttendanceStatusRegistered, 10)
+	refunded := attendance(event, entities.AttendanceStatusCancelled, 10)
+	refunded.PaymentStatus = "refunded"
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, event.ID).Return([]*entities.EventAttendance{rated, noShow, refunded}, nil)
+
+	adoption := func(status entities.AdoptionStatus, paid float64) *entities.Adoption {
+		return &entities.Adoption{ID: primitive.NewObjectID(), AnimalID: event.Animals[0], Status: status, AmountPaid: paid}
+	}
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AdoptionFilter) bool {
+		return len(f.AnimalIDs) == 3 && f.CompletedFromDate != nil && f.CompletedToDate != nil &&
+			assert.ObjectsAreEqual([]string{"completed", "returned"}, f.Statuses)
+	})).Return([]*entities.Adoption{
+		adoption(entities.AdoptionStatusCompleted, 150),
+		adoption(entities.AdoptionStatusReturned, 150),
+	}, int64(2), nil)
+
+	volunteerID := primitive.NewObjectID()
+	m.assignments.On("GetAssignmentsByEvent", mock.Anything, event.ID).Return([]*entities.VolunteerAssignment{
+		{VolunteerID: volunteerID, Status: entities.AssignmentStatusCompleted, ActualHours: 4},
+		{VolunteerID: volunteerID, Status: entities.AssignmentStatusCompleted, Duration: 120},
+		{VolunteerID: primitive.NewObjectID(), Status: entities.AssignmentStatusCancelled, Duration: 240},
+	}, nil)
+
+	report, err := uc.GetEventReport(context.Background(), event.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 500.0, report.TotalCost)
+	assert.Equal(t, map[string]float64{"venue": 300, "marketing": 150, "other": 50}, report.CostsByCategory)
+	assert.Equal(t, 500.0, report.Donations)
+	assert.Equal(t, 2, report.DonationCount)
+	assert.Equal(t, 2, report.Donors)
+	assert.ElementsMatch(t, []primitive.ObjectID{atEvent.ID, toCampaign.ID}, report.DonationIDs)
+	assert.Equal(t, 30.0, report.TicketSales)
+	assert.Equal(t, 530.0, report.FundsRaised)
+	assert.Equal(t, 300.0, report.AdoptionFees)
+	assert.Equal(t, 330.0, report.NetResult)
+	require.NotNil(t, report.ROI)
+	assert.Equal(t, 66.0, *report.ROI)
+
+	assert.Equal(t, 3, report.AnimalsBrought)
+	assert.Equal(t, 2, report.Adoptions)
+	assert.Equal(t, 1, report.AdoptionsReturned)
+	require.NotNil(t, report.CostPerAdoption)
+	assert.Equal(t, 250.0, *report.CostPerAdoption)
+
+	assert.Equal(t, 3, report.Registered)
+	assert.Equal(t, 2, report.Attended)
+	assert.Equal(t, 66.7, report.AttendanceRate)
+	assert.Equal(t, 1, report.Volunteers)
+	assert.Equal(t, 6.0, report.VolunteerHours)
+
+	assert.Equal(t, 1, report.Feedback.Responses)
+	assert.Equal(t, 5.0, report.Feedback.AverageRating)
+	assert.Equal(t, map[int]int{5: 1}, report.Feedback.ByRating)
+	require.Len(t, report.Feedback.Comments, 1)
+	assert.Equal(t, "Lovely day", report.Feedback.Comments[0].Feedback)
+}
+
+func TestGetEventReport_CreditsAdoptionsOfAnimalsHousedAtTheEventLocation(t *testing.T) {
+	uc, m := newOutcomeUseCase()
+	start := time.Date(2026, 9, 12, 10, 0, 0, 0, time.UTC)
+	event := completedEvent(m, start)
+	event.Location.Name = "Adoption Tent"
+	brought := primitive.NewObjectID()
+	event.Animals = []primitive.ObjectID{brought}
+
+	movedOut := start.AddDate(0, 0, -3)
+	atTent := &entities.Animal{ID: primitive.NewObjectID()}
+	atTent.Shelter.LocationHistory = []entities.LocationStay{{Location: "adoption tent", From: start.Add(-time.Hour)}}
+	before := &entities.Animal{ID: primitive.NewObjectID()}
+	before.Shelter.LocationHistory = []entities.LocationStay{{Location: "Adoption Tent", From: start.AddDate(0, 0, -10), To: &movedOut}}
+	m.animals.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AnimalFilter) bool {
+		return assert.ObjectsAreEqual([]string{"Adoption Tent"}, f.Locations)
+	})).Return([]*entities.Animal{atTent, before}, int64(2), nil)
+
+	m.donations.On("List", mock.Anything, mock.Anything).Return([]*entities.Donation{}, int64(0), nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, event.ID).Return([]*entities.EventAttendance{}, nil)
+	m.assignments.On("GetAssignmentsByEvent", mock.Anything, event.ID).Return([]*entities.VolunteerAssignment{}, nil)
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(f repositories.AdoptionFilter) bool {
+		return assert.ObjectsAreEqual([]primitive.ObjectID{brought, atTent.ID}, f.AnimalIDs)
+	})).Return([]*entities.Adoption{{ID: primitive.NewObjectID(), AnimalID: atTent.ID, Status: entities.AdoptionStatusCompleted}}, int64(1), nil)
+
+	report, err := uc.GetEventReport(context.Background(), event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Adoptions)
+}
+
+func TestGetEventReport_HasNoROIWithoutCosts(t *testing.T) {
+	uc, m := newOutcomeUseCase()
+	event := completedEvent(m, time.Now().Add(-24*time.Hour))
+	m.donations.On("List", mock.Anything, mock.Anything).Return([]*entities.Donation{
+		{ID: primitive.NewObjectID(), Amount: 75, EventID: &event.ID},
+	}, int64(1), nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, event.ID).Return([]*entities.EventAttendance{}, nil)
+	m.assignments.On("GetAssignmentsByEvent", mock.Anything, event.ID).Return([]*entities.VolunteerAssignment{}, nil)
+
+	report, err := uc.GetEventReport(context.Background(), event.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 75.0, report.NetResult)
+	assert.Nil(t, report.ROI)
+	assert.Nil(t, report.CostPerAttendee)
+	m.adoptions.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestProcessCompletedEvents_StoresOutcomesAndAsksAttendeesForFeedback(t *testing.T) {
+	uc, m := newOutcomeUseCase()
+	now := time.Now()
+	recent := completedEvent(m, now.Add(-7*time.Hour))      // ended three hours ago
+	earlier := completedEvent(m, now.Add(-10*24*time.Hour)) // too long ago for surveys
+	m.events.On("List", mock.Anything, mock.MatchedBy(func(f *repositories.EventFilter) bool {
+		return f.Status == string(entities.EventStatusCompleted) && f.StartDate != nil
+	})).Return([]*entities.Event{recent, earlier}, int64(2), nil)
+
+	m.donations.On("List", mock.Anything, mock.Anything).Return([]*entities.Donation{
+		{ID: primitive.NewObjectID(), Amount: 120},
+	}, int64(1), nil)
+	m.assignments.On("GetAssignmentsByEvent", mock.Anything, mock.Anything).Return([]*entities.VolunteerAssignment{
+		{VolunteerID: primitive.NewObjectID(), Status: entities.AssignmentStatusCompleted, ActualHours: 3.5},
+	}, nil)
+
+	guest := attendance(recent, entities.AttendanceStatusAttended, 0)
+	guest.GuestName, guest.GuestEmail = "Anna", "anna@example.com"
+	volunteer := &entities.Volunteer{ID: primitive.NewObjectID(), FirstName: "Jan", LastName: "Kowalski", Phone: "+48500100200"}
+	m.volunteers.On("FindByID", mock.Anything, volunteer.ID).Return(volunteer, nil)
+	helper := attendance(recent, entities.AttendanceStatusAttended, 0)
+	helper.VolunteerID = &volunteer.ID
+	absent := attendance(recent, entities.AttendanceStatusRegistered, 0)
+	absent.GuestEmail = "absent@example.com"
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, recent.ID).Return([]*entities.EventAttendance{guest, helper, absent}, nil)
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, earlier.ID).Return([]*entities.EventAttendance{}, nil)
+
+	require.NoError(t, uc.ProcessCompletedEvents(context.Background()))
+
+	for _, event := range []*entities.Event{recent, earlier} {
+		m.events.AssertCalled(t, "UpdateOutcomes", mock.Anything, event.ID, 120.0, 0, 3.5, mock.Anything)
+	}
+	m.events.AssertCalled(t, "MarkFeedbackRequested", mock.Anything, recent.ID, mock.Anything)
+	m.events.AssertNotCalled(t, "MarkFeedbackRequested", mock.Anything, earlier.ID, mock.Anything)
+	m.events.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	// Only the attendees who were checked in are asked
+	require.Len(t, m.messenger.Sent, 2)
+	email, sms := m.messenger.Sent[0], m.messenger.Sent[1]
+	assert.Equal(t, entities.TemplateTypeEmail, email.Type)
+	assert.Equal(t, "anna@example.com", email.RecipientEmail)
+	assert.Equal(t, "How was Adoption Day?", email.Subject)
+	assert.Equal(t, entities.HashToken(testutil.LinkToken(t, email, surveyURL)), guest.FeedbackTokenHash)
+	assert.Equal(t, entities.TemplateTypeSMS, sms.Type)
+	assert.Equal(t, "+48500100200", sms.RecipientPhone)
+	assert.NotEmpty(t, helper.FeedbackTokenHash)
+	assert.Nil(t, absent.FeedbackRequestedAt)
+}
+
+func TestSubmitFeedback_RatesTheEventOnce(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newOutcomeUseCase()
+	event := completedEvent(m, time.Now().Add(-6*time.Hour))
+	guest := attendance(event, entities.AttendanceStatusAttended, 0)
+	guest.GuestName, guest.GuestEmail = "Anna", "anna@example.com"
+	m.attendances.On("GetAttendanceByEvent", mock.Anything, event.ID).Return([]*entities.EventAttendance{guest}, nil)
+
+	sent, err := uc.SendFeedbackRequests(ctx, event.ID, primitive.NewObjectID())
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	token := testutil.LinkToken(t, m.messenger.Sent[0], surveyURL)
+	m.attendances.On("FindByFeedbackToken", mock.Anything, entities.HashToken(token)).Return(guest, nil)
+
+	view, err := uc.ViewFeedback(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "Adoption Day", view.EventName)
+	assert.Equal(t, "Anna", view.AttendeeName)
+
+	require.NoError(t, uc.SubmitFeedback(ctx, token, &SubmitFeedbackRequest{Rating: 4, Feedback: "  Great dogs  "}))
+	assert.Equal(t, 4, guest.Rating)
+	assert.Equal(t, "Great dogs", guest.Feedback)
+	require.NotNil(t, guest.FeedbackDate)
+
+	err = uc.SubmitFeedback(ctx, token, &SubmitFeedbackRequest{Rating: 1})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, err.(*errors.AppError).Code)
+	assert.Equal(t, 4, guest.Rating)
+}
+
+func TestViewFeedback_RejectsExpiredLinks(t *testing.T) {
+	uc, m := newOutcomeUseCase()
+	event := completedEvent(m, time.Now().Add(-30*24*time.Hour))
+	guest := attendance(event, entities.AttendanceStatusAttended, 0)
+	expired := time.Now().Add(-time.Hour)
+	guest.FeedbackTokenHash = entities.HashToken("old-token")
+	guest.FeedbackTokenExpiresAt = &expired
+	m.attendances.On("FindByFeedbackToken", mock.Anything, entities.HashToken("old-token")).Return(guest, nil)
+
+	_, err := uc.ViewFeedback(context.Background(), "old-token")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusGone, err.(*errors.AppError).Code)
+}
@@ -0,0 +1,196 @@
+package outcome
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Report is the return on investment of an event: what it cost against the funds it
+// raised and the animals adopted at it, with its attendance and feedback
+type Report struct {
+	EventID   primitive.ObjectID        `json:"event_id"`
+	Name      entities.MultilingualName `json:"name"`
+	Status    entities.EventStatus      `json:"status"`
+	StartDate time.Time                 `json:"start_date"`
+	EndDate   time.Time                 `json:"end_date"`
+
+	// Costs
+	TotalCost       float64            `json:"total_cost"`
+	CostsByCategory map[string]float64 `json:"costs_by_category"`
+
+	// Funds
+	Donations     float64  `json:"donations"`
+	DonationCount int      `json:"donation_count"`
+	Donors        int      `json:"donors"`
+	TicketSales   float64  `json:"ticket_sales"`
+	FundsRaised   float64  `json:"funds_raised"` // Donations and ticket sales
+	AdoptionFees  float64  `json:"adoption_fees"`
+	NetResult     float64  `json:"net_result"`    // Funds raised and adoption fees less the costs
+	ROI           *float64 `json:"roi,omitempty"` // Net result out of the costs, in percent; none without costs
+
+	// Adoptions
+	AnimalsBrought    int      `json:"animals_brought"`
+	Adoptions         int      `json:"adoptions"`
+	AdoptionsReturned int      `json:"adoptions_returned"`
+	CostPerAdoption   *float64 `json:"cost_per_adoption,omitempty"`
+
+	// Attendance, in seats
+	Registered      int      `json:"registered"`
+	Attended        int      `json:"attended"`
+	AttendanceRate  float64  `json:"attendance_rate"`
+	CostPerAttendee *float64 `json:"cost_per_attendee,omitempty"`
+
+	// Volunteers
+	Volunteers     int     `json:"volunteers"`
+	VolunteerHours float64 `json:"volunteer_hours"`
+
+	Feedback FeedbackSummary `json:"feedback"`
+
+	DonationIDs []primitive.ObjectID `json:"donation_ids"`
+	AdoptionIDs []primitive.ObjectID `json:"adoption_ids"`
+}
+
+// FeedbackSummary sums up the attendees' ratings of an event
+type FeedbackSummary struct {
+	Requested     int               `json:"requested"`
+	Responses     int               `json:"responses"`
+	ResponseRate  float64           `json:"response_rate"` // Percentage of the attendees asked
+	AverageRating float64           `json:"average_rating"`
+	ByRating      map[int]int       `json:"by_rating"`
+	Comments      []FeedbackComment `json:"comments"`
+}
+
+// FeedbackComment is an attendee's written feedback
+type FeedbackComment struct {
+	AttendanceID primitive.ObjectID `json:"attendance_id"`
+	Rating       int                `json:"rating"`
+	Feedback     string             `json:"feedback"`
+	SubmittedAt  time.Time          `json:"submitted_at"`
+}
+
+// GetEventReport reports the costs of an event against the funds it raised and the
+// adoptions made at it. The outcomes are computed from the current records.
+func (uc *OutcomeUseCase) GetEventReport(ctx context.Context, eventID primitive.ObjectID) (*Report, error) {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := uc.collect(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		EventID:         event.ID,
+		Name:            event.Name,
+		Status:          event.Status,
+		StartDate:       event.StartDate,
+		EndDate:         event.EndTime(),
+		TotalCost:       event.TotalCost(),
+		CostsByCategory: make(map[string]float64),
+		Donations:       result.donationTotal(),
+		DonationCount:   len(result.donations),
+		TicketSales:     result.ticketSales(),
+		AnimalsBrought:  len(event.Animals),
+		VolunteerHours:  math.Round(result.volunteerHours()*100) / 100,
+		Feedback: FeedbackSummary{
+			ByRating: make(map[int]int),
+			Comments: []FeedbackComment{},
+		},
+		DonationIDs: []primitive.ObjectID{},
+		AdoptionIDs: []primitive.ObjectID{},
+	}
+
+	for _, cost := range event.Costs {
+		category := cost.Category
+		if category == "" {
+			category = "other"
+		}
+		report.CostsByCategory[category] += cost.Amount
+	}
+
+	donors := make(map[primitive.ObjectID]bool)
+	for _, donation := range result.donations {
+		donors[donation.DonorID] = true
+		report.DonationIDs = append(report.DonationIDs, donation.ID)
+	}
+	report.Donors = len(donors)
+	report.FundsRaised = report.Donations + report.TicketSales
+
+	for _, adoption := range result.adoptions {
+		report.Adoptions++
+		if adoption.Status == entities.AdoptionStatusReturned {
+			report.AdoptionsReturned++
+		}
+		report.AdoptionFees += adoption.AmountPaid
+		report.AdoptionIDs = append(report.AdoptionIDs, adoption.ID)
+	}
+
+	volunteers := make(map[primitive.ObjectID]bool)
+	for _, assignment := range result.assignments {
+		volunteers[assignment.VolunteerID] = true
+	}
+	report.Volunteers = len(volunteers)
+
+	ratings := 0
+	for _, attendance := range result.attendances {
+		if attendance.HoldsSeats() {
+			report.Registered += attendance.Seats()
+		}
+		if attendance.IsAttended() {
+			report.Attended += attendance.Seats()
+		}
+		if attendance.FeedbackRequestedAt != nil || attendance.HasFeedback() {
+			report.Feedback.Requested++
+		}
+		if attendance.HasFeedback() {
+			report.Feedback.Responses++
+			report.Feedback.ByRating[attendance.Rating]++
+			ratings += attendance.Rating
+			if attendance.Feedback != "" {
+				report.Feedback.Comments = append(report.Feedback.Comments, FeedbackComment{
+					AttendanceID: attendance.ID,
+					Rating:       attendance.Rating,
+					Feedback:     attendance.Feedback,
+					SubmittedAt:  *attendance.FeedbackDate,
+				})
+			}
+		}
+	}
+	report.AttendanceRate = rate(report.Attended, report.Registered)
+	report.Feedback.ResponseRate = rate(report.Feedback.Responses, report.Feedback.Requested)
+	if report.Feedback.Responses > 0 {
+		report.Feedback.AverageRating = math.Round(float64(ratings)/float64(report.Feedback.Responses)*10) / 10
+	}
+
+	report.NetResult = report.FundsRaised + report.AdoptionFees - report.TotalCost
+	if report.TotalCost > 0 {
+		report.ROI = ratio(report.NetResult*100, report.TotalCost)
+		if report.Adoptions > 0 {
+			report.CostPerAdoption = ratio(report.TotalCost, float64(report.Adoptions))
+		}
+		if report.Attended > 0 {
+			report.CostPerAttendee = ratio(report.TotalCost, float64(report.Attended))
+		}
+	}
+
+	return report, nil
+}
+
+// rate returns a percentage rounded to one decimal
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(total)*1000) / 10
+}
+
+// ratio returns a quotient rounded to two decimals
+func ratio(value, total float64) *float64 {
+	result := math.Round(value/total*100) / 100
+	return &result
+}
@@ -0,0 +1,319 @@
+package packet
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+)
+
+// labels holds the fixed text of a packet in one language. Free text from the
+// records (diagnoses, notes, instructions) is printed as entered.
+type labels struct {
+	Title        string
+	Subtitle     string
+	Generated    string
+	DateLayout   string
+	Yes          string
+	No           string
+	None         string
+	Ongoing      string
+	Estimated    string
+	Disclaimer   string
+	DocumentName string
+
+	Details      string
+	Name         string
+	Species      string
+	Breed        string
+	Sex          string
+	DateOfBirth  string
+	Color        string
+	Weight       string
+	Microchip    string
+	Sterilized   string
+	IntakeDate   string
+	HealthStatus string
+	Allergies    string
+	SpecialNeeds string
+
+	Vaccinations   string
+	NoVaccinations string
+	Date           string
+	Vaccine        string
+	Dose           string
+	NextDue        string
+	Veterinarian   string
+	LotNumber      string
+
+	Medications    string
+	NoMedications  string
+	Medication     string
+	Dosage         string
+	Frequency      string
+	Route          string
+	Period         string
+	Instructions   string
+	Conditions     string
+	Condition      string
+	Diagnosed      string
+	Severity       string
+	Status         string
+	TreatmentPlans string
+	Plan           string
+	Goals          string
+
+	History   string
+	NoHistory string
+	VisitType string
+	Findings  string
+
+	Behavior     string
+	Temperament  string
+	GoodWithKids string
+	GoodWithDogs string
+	GoodWithCats string
+	HouseTrained string
+	Notes        string
+
+	// Values holds translations of stored enum values, keyed by the value
+	Values map[string]string
+}
+
+var packetLabels = map[string]labels{
+	"en": {
+		Title:        "Medical history",
+		Subtitle:     "Adoption packet",
+		Generated:    "Generated",
+		DateLayout:   "Jan 2, 2006",
+		Yes:          "Yes",
+		No:           "No",
+		None:         "None",
+		Ongoing:      "ongoing",
+		Estimated:    "estimated",
+		Disclaimer:   "This summary is compiled from the shelter's records on the date shown. It does not replace an examination by a veterinarian; please take it to the first visit with the animal.",
+		DocumentName: "Medical history",
+
+		Details:      "Animal details",
+		Name:         "Name",
+		Species:      "Species",
+		Breed:        "Breed",
+		Sex:          "Sex",
+		DateOfBirth:  "Date of birth",
+		Color:        "Color",
+		Weight:       "Weight",
+		Microchip:    "Microchip",
+		Sterilized:   "Spayed/neutered",
+		IntakeDate:   "Intake date",
+		HealthStatus: "Health status",
+		Allergies:    "Allergies",
+		SpecialNeeds: "Special needs",
+
+		Vaccinations:   "Vaccination certificate",
+		NoVaccinations: "No vaccinations recorded.",
+		Date:           "Date",
+		Vaccine:        "Vaccine",
+		Dose:           "Dose",
+		NextDue:        "Next due",
+		Veterinarian:   "Veterinarian",
+		LotNumber:      "Lot",
+
+		Medications:    "Current medications and conditions",
+		NoMedications:  "No current medications.",
+		Medication:     "Medication",
+		Dosage:         "Dosage",
+		Frequency:      "Frequency",
+		Route:          "Route",
+		Period:         "Period",
+		Instructions:   "Instructions",
+		Conditions:     "Conditions",
+		Condition:      "Condition",
+		Diagnosed:      "Diagnosed",
+		Severity:       "Severity",
+		Status:         "Status",
+		TreatmentPlans: "Treatment plans",
+		Plan:           "Plan",
+		Goals:          "Goals",
+
+		History:   "Medical history",
+		NoHistory: "No veterinary visits recorded.",
+		VisitType: "Visit",
+		Findings:  "Reason, diagnosis and treatment",
+
+		Behavior:     "Behavior",
+		Temperament:  "Temperament",
+		GoodWithKids: "Good with children",
+		GoodWithDogs: "Good with dogs",
+		GoodWithCats: "Good with cats",
+		HouseTrained: "House trained",
+		Notes:        "Notes",
+
+		Values: map[string]string{},
+	},
+	"pl": {
+		Title:        "Karta zdrowia",
+		Subtitle:     "Pakiet adopcyjny",
+		Generated:    "Wygenerowano",
+		DateLayout:   "02.01.2006",
+		Yes:          "Tak",
+		No:           "Nie",
+		None:         "Brak",
+		Ongoing:      "w trakcie",
+		Estimated:    "szacunkowo",
+		Disclaimer:   "Zestawienie przygotowano na podstawie dokumentacji schroniska w podanym dniu. Nie zastępuje badania weterynaryjnego; prosimy zabrać je na pierwszą wizytę ze zwierzęciem.",
+		DocumentName: "Karta zdrowia",
+
+		Details:      "Dane zwierzęcia",
+		Name:         "Imię",
+		Species:      "Gatunek",
+		Breed:        "Rasa",
+		Sex:          "Płeć",
+		DateOfBirth:  "Data urodzenia",
+		Color:        "Umaszczenie",
+		Weight:       "Waga",
+		Microchip:    "Mikroczip",
+		Sterilized:   "Sterylizacja/kastracja",
+		IntakeDate:   "Data przyjęcia",
+		HealthStatus: "Stan zdrowia",
+		Allergies:    "Alergie",
+		SpecialNeeds: "Szczególne potrzeby",
+
+		Vaccinations:   "Świadectwo szczepień",
+		NoVaccinations: "Brak zarejestrowanych szczepień.",
+		Date:           "Data",
+		Vaccine:        "Szczepionka",
+		Dose:           "Dawka",
+		NextDue:        "Kolejna dawka",
+		Veterinarian:   "Lekarz weterynarii",
+		LotNumber:      "Seria",
+
+		Medications:    "Aktualne leki i schorzenia",
+		NoMedications:  "Brak aktualnie podawanych leków.",
+		Medication:     "Lek",
+		Dosage:         "Dawkowanie",
+		Frequency:      "Częstotliwość",
+		Route:          "Podanie",
+		Period:         "Okres",
+		Instructions:   "Zalecenia",
+		Conditions:     "Schorzenia",
+		Condition:      "Schorzenie",
+		Diagnosed:      "Rozpoznano",
+		Severity:       "Nasilenie",
+		Status:         "Status",
+		TreatmentPlans: "Plany leczenia",
+		Plan:           "Plan",
+		Goals:          "Cele",
+
+		History:   "Historia leczenia",
+		NoHistory: "Brak zarejestrowanych wizyt weterynaryjnych.",
+		VisitType: "Wizyta",
+		Findings:  "Powód, rozpoznanie i leczenie",
+
+		Behavior:     "Zachowanie",
+		Temperament:  "Temperament",
+		GoodWithKids: "Dogaduje się z dziećmi",
+		GoodWithDogs: "Dogaduje się z psami",
+		GoodWithCats: "Dogaduje się z kotami",
+		HouseTrained: "Nauczony czystości",
+		Notes:        "Uwagi",
+
+		Values: map[string]string{
+			string(entities.SexMale):    "samiec",
+			string(entities.SexFemale):  "samica",
+			string(entities.SexUnknown): "nieznana",
+
+			string(entities.TemperamentFriendly):   "przyjazny",
+			string(entities.TemperamentShy):        "nieśmiały",
+			string(entities.TemperamentAggressive): "agresywny",
+			string(entities.TemperamentPlayful):    "zabawowy",
+			string(entities.TemperamentCalm):       "spokojny",
+			string(entities.TemperamentEnergetic):  "energiczny",
+
+			string(entities.VisitTypeCheckup):     "kontrola",
+			string(entities.VisitTypeVaccination): "szczepienie",
+			string(entities.VisitTypeEmergency):   "nagły przypadek",
+			string(entities.VisitTypeSurgery):     "zabieg chirurgiczny",
+			string(entities.VisitTypeDental):      "stomatologia",
+			string(entities.VisitTypeSpayNeuter):  "sterylizacja/kastracja",
+			string(entities.VisitTypeFollowUp):    "wizyta kontrolna",
+			string(entities.VisitTypeTreatment):   "leczenie",
+			string(entities.VisitTypeDiagnostic):  "diagnostyka",
+
+			string(entities.SeverityMild):     "łagodne",
+			string(entities.SeverityModerate): "umiarkowane",
+			string(entities.SeveritySevere):   "ciężkie",
+			string(entities.SeverityCritical): "krytyczne",
+
+			string(entities.ConditionStatusActive):    "aktywne",
+			string(entities.ConditionStatusTreating):  "w leczeniu",
+			string(entities.ConditionStatusMonitored): "obserwowane",
+			string(entities.ConditionStatusResolved):  "wyleczone",
+			string(entities.ConditionStatusChronic):   "przewlekłe",
+
+			string(entities.RouteOral):          "doustnie",
+			string(entities.RouteTopical):       "miejscowo",
+			string(entities.RouteInjection):     "iniekcja",
+			string(entities.RouteIntravenous):   "dożylnie",
+			string(entities.RouteSubcutaneous):  "podskórnie",
+			string(entities.RouteIntramuscular): "domięśniowo",
+			string(entities.RouteInhalation):    "wziewnie",
+			string(entities.RouteOphthalmic):    "do oka",
+			string(entities.RouteOtic):          "do ucha",
+
+			"dog":        "pies",
+			"cat":        "kot",
+			"rabbit":     "królik",
+			"ferret":     "fretka",
+			"healthy":    "zdrowy",
+			"sick":       "chory",
+			"injured":    "ranny",
+			"recovering": "w trakcie rekonwalescencji",
+		},
+	},
+}
+
+// labelsFor returns the labels of a language, falling back to English
+func labelsFor(language string) labels {
+	if l, ok := packetLabels[language]; ok {
+		return l
+	}
+	return packetLabels["en"]
+}
+
+// value translates a stored enum value, or prints it readably when there is no translation
+func (l labels) value(v string) string {
+	if v == "" {
+		return ""
+	}
+	if translated, ok := l.Values[v]; ok {
+		return translated
+	}
+	return strings.ReplaceAll(v, "_", " ")
+}
+
+// yesNo prints a flag
+func (l labels) yesNo(v bool) string {
+	if v {
+		return l.Yes
+	}
+	return l.No
+}
+
+// date prints a date in the language's format
+func (l labels) date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(l.DateLayout)
+}
+
+// animalName returns the animal's name in the language, falling back to the English name
+func animalName(animal *entities.Animal, language string) string {
+	if language == "pl" && animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	return animal.Name.Polish
+}
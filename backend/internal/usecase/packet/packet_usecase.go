@@ -0,0 +1,568 @@
+package packet
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/pdf"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PacketTag marks the documents holding generated medical packets
+const PacketTag = "medical-packet"
+
+// DefaultLanguage is used when no packet language is requested
+const DefaultLanguage = "pl"
+
+// Section is a part of the medical packet that can be included or left out
+type Section string
+
+const (
+	SectionHistory      Section = "history"
+	SectionVaccinations Section = "vaccinations"
+	SectionMedications  Section = "medications"
+	SectionBehavior     Section = "behavior"
+)
+
+// AllSections lists the packet sections in the order they are printed
+var AllSections = []Section{SectionVaccinations, SectionMedications, SectionHistory, SectionBehavior}
+
+// PacketUseCase assembles printable medical history and adoption packets
+type PacketUseCase struct {
+	animalRepo        repositories.AnimalRepository
+	visitRepo         repositories.VeterinaryVisitRepository
+	vaccinationRepo   repositories.VaccinationRepository
+	conditionRepo     repositories.MedicalConditionRepository
+	medicationRepo    repositories.MedicationRepository
+	treatmentPlanRepo repositories.TreatmentPlanRepository
+	documentRepo      repositories.DocumentRepository
+	settingsRepo      repositories.SettingsRepository
+	auditLogRepo      repositories.AuditLogRepository
+	storageService    *storage.StorageService
+}
+
+// NewPacketUseCase creates a new packet use case
+func NewPacketUseCase(
+	animalRepo repositories.AnimalRepository,
+	visitRepo repositories.VeterinaryVisitRepository,
+	vaccinationRepo repositories.VaccinationRepository,
+	conditionRepo repositories.MedicalConditionRepository,
+	medicationRepo repositories.MedicationRepository,
+	treatmentPlanRepo repositories.TreatmentPlanRepository,
+	documentRepo repositories.DocumentRepository,
+	settingsRepo repositories.SettingsRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	storageService *storage.StorageService,
+) *PacketUseCase {
+	return &PacketUseCase{
+		animalRepo:        animalRepo,
+		visitRepo:         visitRepo,
+		vaccinationRepo:   vaccinationRepo,
+		conditionRepo:     conditionRepo,
+		medicationRepo:    medicationRepo,
+		treatmentPlanRepo: treatmentPlanRepo,
+		documentRepo:      documentRepo,
+		settingsRepo:      settingsRepo,
+		auditLogRepo:      auditLogRepo,
+		storageService:    storageService,
+	}
+}
+
+// PacketRequest selects the language and sections of a packet
+type PacketRequest struct {
+	Language string    `json:"language,omitempty" form:"lang" validate:"omitempty,oneof=en pl"`
+	Sections []Section `json:"sections,omitempty" form:"sections" validate:"omitempty,dive,oneof=history vaccinations medications behavior"`
+}
+
+// Packet is a rendered packet PDF
+type Packet struct {
+	Animal   *entities.Animal `json:"-"`
+	Language string           `json:"language"`
+	FileName string           `json:"file_name"`
+	Content  []byte           `json:"-"`
+}
+
+// RenderPacket renders the packet of an animal without storing it
+func (uc *PacketUseCase) RenderPacket(ctx context.Context, animalID primitive.ObjectID, req *PacketRequest) (*Packet, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	language := DefaultLanguage
+	sections := AllSections
+	if req != nil {
+		if req.Language != "" {
+			language = req.Language
+		}
+		if len(req.Sections) > 0 {
+			sections = req.Sections
+		}
+	}
+	if _, ok := packetLabels[language]; !ok {
+		return nil, errors.NewBadRequest("Unsupported packet language")
+	}
+
+	l := labelsFor(language)
+	name := animalName(animal, language)
+	now := time.Now()
+
+	doc := pdf.New(fmt.Sprintf("%s – %s", l.Title, name))
+	doc.SetSubject(l.Subtitle)
+	doc.SetCreated(now)
+	footer := fmt.Sprintf("%s %s", l.Generated, l.date(now))
+	if organization := uc.organizationName(ctx); organization != "" {
+		doc.SetAuthor(organization)
+		footer = organization + " · " + footer
+	}
+	doc.SetFooter(footer)
+
+	doc.Title(fmt.Sprintf("%s – %s", l.Title, name))
+	doc.Small(l.Subtitle)
+	writeDetails(doc, l, animal, language)
+
+	include := make(map[Section]bool, len(sections))
+	for _, section := range sections {
+		include[section] = true
+	}
+	for _, section := range AllSections {
+		if !include[section] {
+			continue
+		}
+
+		var err error
+		switch section {
+		case SectionVaccinations:
+			err = uc.writeVaccinations(ctx, doc, l, animalID)
+		case SectionMedications:
+			err = uc.writeMedications(ctx, doc, l, animalID)
+		case SectionHistory:
+			err = uc.writeHistory(ctx, doc, l, animalID, now)
+		case SectionBehavior:
+			writeBehavior(doc, l, animal)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, 500, "failed to assemble medical packet")
+		}
+	}
+
+	doc.Space(12)
+	doc.Small(l.Disclaimer)
+
+	return &Packet{
+		Animal:   animal,
+		Language: language,
+		FileName: packetFileName(l, name, language, now),
+		Content:  doc.Bytes(),
+	}, nil
+}
+
+// CreatePacket renders the packet of an animal and stores it as a document linked to the animal.
+// A packet in the same language that was stored before becomes the previous version.
+func (uc *PacketUseCase) CreatePacket(ctx context.Context, animalID primitive.ObjectID, req *PacketRequest, userID primitive.ObjectID) (*entities.Document, error) {
+	if uc.storageService == nil {
+		return nil, errors.NewInternalServer("file storage is not configured")
+	}
+
+	packet, err := uc.RenderPacket(ctx, animalID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	l := labelsFor(packet.Language)
+	title := fmt.Sprintf("%s – %s", l.DocumentName, animalName(packet.Animal, packet.Language))
+	size := int64(len(packet.Content))
+
+	var document *entities.Document
+	if previous := uc.latestPacket(ctx, animalID, packet.Language); previous != nil {
+		document = previous.CreateNewVersion(packet.FileName, size, "application/pdf", "", userID)
+		document.Title = title
+	} else {
+		document = entities.NewDocument(title, packet.FileName, size, "application/pdf", entities.DocumentTypeMedical, userID)
+		document.RelatedEntity = "animal"
+		document.RelatedEntityID = &animalID
+		document.IsConfidential = true
+	}
+	document.Tags = []string{PacketTag}
+	document.Metadata = map[string]string{
+		"generator": "medical_packet",
+		"language":  packet.Language,
+	}
+
+	sum := sha256.Sum256(packet.Content)
+	document.Checksum = hex.EncodeToString(sum[:])
+
+	url, err := uc.storageService.Upload(ctx, bytes.NewReader(packet.Content), size, "documents/"+document.ID.Hex(), primitive.NewObjectID().Hex()+".pdf", "application/pdf")
+	if err != nil {
+		return nil, err
+	}
+	document.FileURL = url
+
+	if err := uc.documentRepo.Create(ctx, document); err != nil {
+		_ = uc.storageService.DeleteFile(ctx, url)
+		return nil, err
+	}
+
+	// Create audit log
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(userID, entities.ActionCreate, "document", document.Title, "generated medical packet").
+			WithEntityID(document.ID).
+			WithChanges(map[string]interface{}{
+				"animal_id": animalID.Hex(),
+				"language":  packet.Language,
+				"version":   document.Version,
+			}))
+
+	return document, nil
+}
+
+// GeneratePacket stores a packet with all sections, for attaching to adoption and transfer records
+func (uc *PacketUseCase) GeneratePacket(ctx context.Context, animalID primitive.ObjectID, language string, userID primitive.ObjectID) (*entities.Document, error) {
+	return uc.CreatePacket(ctx, animalID, &PacketRequest{Language: language}, userID)
+}
+
+// latestPacket returns the newest stored packet of an animal in a language
+func (uc *PacketUseCase) latestPacket(ctx context.Context, animalID primitive.ObjectID, language string) *entities.Document {
+	documents, err := uc.documentRepo.GetByRelatedEntity(ctx, "animal", animalID)
+	if err != nil {
+		return nil
+	}
+
+	var latest *entities.Document
+	for _, document := range documents {
+		if document.IsArchived || document.Metadata["generator"] != "medical_packet" || document.Metadata["language"] != language {
+			continue
+		}
+		if latest == nil || document.Version > latest.Version {
+			latest = document
+		}
+	}
+	return latest
+}
+
+// organizationName returns the foundation name printed in the footer
+func (uc *PacketUseCase) organizationName(ctx context.Context) string {
+	if uc.settingsRepo == nil {
+		return ""
+	}
+
+	settings, err := uc.settingsRepo.Get(ctx)
+	if err != nil || settings == nil {
+		return ""
+	}
+	return settings.Name
+}
+
+func writeDetails(doc *pdf.Document, l labels, animal *entities.Animal, language string) {
+	doc.Heading(l.Details)
+	doc.Field(l.Name, animalName(animal, language))
+
+	species := l.value(animal.Species)
+	if animal.Name.Latin != "" {
+		species = fmt.Sprintf("%s (%s)", species, animal.Name.Latin)
+	}
+	doc.Field(l.Species, species)
+	if animal.Breed != "" {
+		doc.Field(l.Breed, animal.Breed)
+	}
+	doc.Field(l.Sex, l.value(string(animal.Sex)))
+	if animal.DateOfBirth != nil {
+		born := l.date(*animal.DateOfBirth)
+		if animal.AgeEstimated {
+			born = fmt.Sprintf("%s (%s)", born, l.Estimated)
+		}
+		doc.Field(l.DateOfBirth, born)
+	}
+	if animal.Color != "" {
+		doc.Field(l.Color, animal.Color)
+	}
+	if animal.Weight > 0 {
+		doc.Field(l.Weight, fmt.Sprintf("%.1f kg", animal.Weight))
+	}
+	if animal.Medical.MicrochipNumber != "" {
+		doc.Field(l.Microchip, animal.Medical.MicrochipNumber)
+	}
+	doc.Field(l.Sterilized, l.yesNo(animal.Medical.Sterilized))
+	if !animal.Shelter.IntakeDate.IsZero() {
+		doc.Field(l.IntakeDate, l.date(animal.Shelter.IntakeDate))
+	}
+	if animal.Medical.HealthStatus != "" {
+		doc.Field(l.HealthStatus, l.value(animal.Medical.HealthStatus))
+	}
+	allergies := l.None
+	if len(animal.Medical.Allergies) > 0 {
+		allergies = strings.Join(animal.Medical.Allergies, ", ")
+	}
+	doc.Field(l.Allergies, allergies)
+	if animal.Medical.SpecialNeeds != "" {
+		doc.Field(l.SpecialNeeds, animal.Medical.SpecialNeeds)
+	}
+}
+
+func (uc *PacketUseCase) writeVaccinations(ctx context.Context, doc *pdf.Document, l labels, animalID primitive.ObjectID) error {
+	vaccinations, err := uc.vaccinationRepo.GetByAnimalID(ctx, animalID)
+	if err != nil {
+		return err
+	}
+
+	doc.Heading(l.Vaccinations)
+	if len(vaccinations) == 0 {
+		doc.Text(l.NoVaccinations)
+		return nil
+	}
+
+	sort.Slice(vaccinations, func(i, j int) bool {
+		return vaccinations[i].DateAdministered.Before(vaccinations[j].DateAdministered)
+	})
+
+	rows := make([][]string, 0, len(vaccinations))
+	for _, v := range vaccinations {
+		vaccine := v.VaccineName
+		if v.VaccineType != "" && v.VaccineType != entities.VaccineOther {
+			vaccine = fmt.Sprintf("%s (%s)", vaccine, strings.ToUpper(l.value(string(v.VaccineType))))
+		}
+		if v.Manufacturer != "" {
+			vaccine = fmt.Sprintf("%s, %s", vaccine, v.Manufacturer)
+		}
+
+		dose := ""
+		if v.DoseNumber > 0 {
+			dose = fmt.Sprintf("%d", v.DoseNumber)
+			if v.TotalDoses > 0 {
+				dose = fmt.Sprintf("%d/%d", v.DoseNumber, v.TotalDoses)
+			}
+		}
+
+		nextDue := ""
+		if v.NextDueDate != nil {
+			nextDue = l.date(*v.NextDueDate)
+		}
+
+		rows = append(rows, []string{
+			l.date(v.DateAdministered),
+			vaccine,
+			dose,
+			v.LotNumber,
+			joinNonEmpty(", ", v.VeterinarianName, v.ClinicName),
+			nextDue,
+		})
+	}
+
+	doc.Table([]pdf.Column{
+		{Header: l.Date, Width: 1.2},
+		{Header: l.Vaccine, Width: 2.6},
+		{Header: l.Dose, Width: 0.7},
+		{Header: l.LotNumber, Width: 1},
+		{Header: l.Veterinarian, Width: 2},
+		{Header: l.NextDue, Width: 1.2},
+	}, rows)
+	return nil
+}
+
+func (uc *PacketUseCase) writeMedications(ctx context.Context, doc *pdf.Document, l labels, animalID primitive.ObjectID) error {
+	doc.Heading(l.Medications)
+
+	medications, err := uc.medicationRepo.FindActiveByAnimal(ctx, animalID)
+	if err != nil {
+		return err
+	}
+	if len(medications) == 0 {
+		doc.Text(l.NoMedications)
+	} else {
+		rows := make([][]string, 0, len(medications))
+		for _, m := range medications {
+			period := fmt.Sprintf("%s – %s", l.date(m.StartDate), l.Ongoing)
+			if m.EndDate != nil {
+				period = fmt.Sprintf("%s – %s", l.date(m.StartDate), l.date(*m.EndDate))
+			}
+			rows = append(rows, []string{
+				m.MedicationName,
+				strings.TrimSpace(m.Dosage + " " + m.Unit),
+				m.Frequency,
+				l.value(string(m.Route)),
+				period,
+				m.Instructions,
+			})
+		}
+		doc.Table([]pdf.Column{
+			{Header: l.Medication, Width: 1.6},
+			{Header: l.Dosage, Width: 1},
+			{Header: l.Frequency, Width: 1.1},
+			{Header: l.Route, Width: 0.9},
+			{Header: l.Period, Width: 1.6},
+			{Header: l.Instructions, Width: 2},
+		}, rows)
+	}
+
+	conditions, err := uc.conditionRepo.FindByAnimal(ctx, animalID)
+	if err != nil {
+		return err
+	}
+	var current [][]string
+	for _, c := range conditions {
+		if c.Status == entities.ConditionStatusResolved && !c.IsChronic {
+			continue
+		}
+		current = append(current, []string{
+			joinNonEmpty(" – ", c.ConditionName, c.Description),
+			l.date(c.DiagnosisDate),
+			l.value(string(c.Severity)),
+			l.value(string(c.Status)),
+		})
+	}
+	if len(current) > 0 {
+		doc.Space(6)
+		doc.Text(l.Conditions)
+		doc.Table([]pdf.Column{
+			{Header: l.Condition, Width: 4},
+			{Header: l.Diagnosed, Width: 1.2},
+			{Header: l.Severity, Width: 1.2},
+			{Header: l.Status, Width: 1.2},
+		}, current)
+	}
+
+	plans, err := uc.treatmentPlanRepo.FindActiveByAnimal(ctx, animalID)
+	if err != nil {
+		return err
+	}
+	if len(plans) > 0 {
+		doc.Space(6)
+		doc.Text(l.TreatmentPlans)
+		rows := make([][]string, 0, len(plans))
+		for _, p := range plans {
+			period := fmt.Sprintf("%s – %s", l.date(p.StartDate), l.Ongoing)
+			if p.EndDate != nil {
+				period = fmt.Sprintf("%s – %s", l.date(p.StartDate), l.date(*p.EndDate))
+			}
+			rows = append(rows, []string{
+				joinNonEmpty("\n", p.PlanName, p.DietaryPlan),
+				period,
+				strings.Join(p.Goals, "; "),
+			})
+		}
+		doc.Table([]pdf.Column{
+			{Header: l.Plan, Width: 2.5},
+			{Header: l.Period, Width: 1.6},
+			{Header: l.Goals, Width: 3},
+		}, rows)
+	}
+
+	return nil
+}
+
+func (uc *PacketUseCase) writeHistory(ctx context.Context, doc *pdf.Document, l labels, animalID primitive.ObjectID, now time.Time) error {
+	visits, err := uc.visitRepo.GetByAnimalID(ctx, animalID)
+	if err != nil {
+		return err
+	}
+
+	// Only visits that took place belong in the history
+	var past []*entities.VeterinaryVisit
+	for _, v := range visits {
+		if v.Status == entities.VisitStatusCancelled || v.Status == entities.VisitStatusNoShow {
+			continue
+		}
+		if v.Status == entities.VisitStatusScheduled && v.VisitDate.After(now) {
+			continue
+		}
+		past = append(past, v)
+	}
+
+	doc.Heading(l.History)
+	if len(past) == 0 {
+		doc.Text(l.NoHistory)
+		return nil
+	}
+
+	sort.Slice(past, func(i, j int) bool {
+		return past[i].VisitDate.After(past[j].VisitDate)
+	})
+
+	rows := make([][]string, 0, len(past))
+	for _, v := range past {
+		rows = append(rows, []string{
+			l.date(v.VisitDate),
+			l.value(string(v.VisitType)),
+			joinNonEmpty(", ", v.VeterinarianName, v.ClinicName),
+			joinNonEmpty("\n", v.ChiefComplaint, v.Diagnosis, v.Treatment),
+		})
+	}
+
+	doc.Table([]pdf.Column{
+		{Header: l.Date, Width: 1.2},
+		{Header: l.VisitType, Width: 1.4},
+		{Header: l.Veterinarian, Width: 1.8},
+		{Header: l.Findings, Width: 4},
+	}, rows)
+	return nil
+}
+
+func writeBehavior(doc *pdf.Document, l labels, animal *entities.Animal) {
+	behavior := animal.Behavior
+
+	doc.Heading(l.Behavior)
+	if len(behavior.Temperament) > 0 {
+		traits := make([]string, 0, len(behavior.Temperament))
+		for _, t := range behavior.Temperament {
+			traits = append(traits, l.value(string(t)))
+		}
+		doc.Field(l.Temperament, strings.Join(traits, ", "))
+	}
+	doc.Field(l.GoodWithKids, l.yesNo(behavior.GoodWithKids))
+	doc.Field(l.GoodWithDogs, l.yesNo(behavior.GoodWithDogs))
+	doc.Field(l.GoodWithCats, l.yesNo(behavior.GoodWithCats))
+	doc.Field(l.HouseTrained, l.yesNo(behavior.HouseTrained))
+	if behavior.SpecialNeeds != "" {
+		doc.Field(l.SpecialNeeds, behavior.SpecialNeeds)
+	}
+	if behavior.Notes != "" {
+		doc.Field(l.Notes, behavior.Notes)
+	}
+}
+
+// fileNameLetters replaces the Polish letters in file names
+var fileNameLetters = strings.NewReplacer("ą", "a", "ć", "c", "ę", "e", "ł", "l", "ń", "n", "ó", "o", "ś", "s", "ź", "z", "ż", "z")
+
+// packetFileName builds an ASCII file name such as medical-history-burek-pl-2026-03-04.pdf
+func packetFileName(l labels, name, language string, now time.Time) string {
+	return fmt.Sprintf("%s-%s-%s-%s.pdf", slug(l.DocumentName), slug(name), language, now.Format("2006-01-02"))
+}
+
+// slug lowercases text and keeps only ASCII letters and digits, separated by hyphens
+func slug(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range fileNameLetters.Replace(strings.ToLower(text)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
+
+// joinNonEmpty joins the non-empty parts with the separator
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
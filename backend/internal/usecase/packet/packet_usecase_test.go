@@ -0,0 +1,178 @@
+package packet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type packetMocks struct {
+	animals      *mocks.AnimalRepository
+	visits       *mocks.VeterinaryVisitRepository
+	vaccinations *mocks.VaccinationRepository
+	conditions   *mocks.MedicalConditionRepository
+	medications  *mocks.MedicationRepository
+	plans        *mocks.TreatmentPlanRepository
+	documents    *mocks.DocumentRepository
+	auditLogs    *mocks.AuditLogRepository
+}
+
+func newPacketUseCase(t *testing.T) (*PacketUseCase, *packetMocks) {
+	m := &packetMocks{
+		animals:      new(mocks.AnimalRepository),
+		visits:       new(mocks.VeterinaryVisitRepository),
+		vaccinations: new(mocks.VaccinationRepository),
+		conditions:   new(mocks.MedicalConditionRepository),
+		medications:  new(mocks.MedicationRepository),
+		plans:        new(mocks.TreatmentPlanRepository),
+		documents:    new(mocks.DocumentRepository),
+		auditLogs:    new(mocks.AuditLogRepository),
+	}
+	storageService := storage.NewStorageService(storage.NewLocalBackend(t.TempDir()), "/uploads", 1<<20)
+	uc := NewPacketUseCase(m.animals, m.visits, m.vaccinations, m.conditions, m.medications, m.plans, m.documents, nil, m.auditLogs, storageService)
+	return uc, m
+}
+
+func packetAnimal() *entities.Animal {
+	born := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	return &entities.Animal{
+		ID:          primitive.NewObjectID(),
+		Name:        entities.MultilingualName{English: "Rex", Polish: "Reksio"},
+		Species:     "dog",
+		Breed:       "Mixed",
+		Sex:         entities.SexMale,
+		DateOfBirth: &born,
+		Medical:     entities.MedicalInfo{Sterilized: true, MicrochipNumber: "616093900012345"},
+		Behavior: entities.BehaviorInfo{
+			Temperament:  []entities.Temperament{entities.TemperamentCalm},
+			GoodWithKids: true,
+			Notes:        "Pulls on the leash",
+		},
+	}
+}
+
+// expectRecords sets up the medical records of an animal
+func (m *packetMocks) expectRecords(animal *entities.Animal) {
+	animalID := animal.ID
+	m.animals.On("FindByID", mock.Anything, animalID).Return(animal, nil)
+	m.vaccinations.On("GetByAnimalID", mock.Anything, animalID).Return([]*entities.Vaccination{
+		{AnimalID: animalID, VaccineType: entities.VaccineRabies, VaccineName: "Nobivac Rabies", LotNumber: "LOT42", DoseNumber: 1, DateAdministered: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	m.medications.On("FindActiveByAnimal", mock.Anything, animalID).Return([]*entities.Medication{
+		{AnimalID: animalID, MedicationName: "Meloxicam", Dosage: "0.5", Unit: "ml", Frequency: "daily", Route: entities.RouteOral, StartDate: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+	m.conditions.On("FindByAnimal", mock.Anything, animalID).Return([]*entities.MedicalCondition{
+		{AnimalID: animalID, ConditionName: "Hip dysplasia", Status: entities.ConditionStatusChronic, IsChronic: true},
+		{AnimalID: animalID, ConditionName: "Ear infection", Status: entities.ConditionStatusResolved},
+	}, nil)
+	m.plans.On("FindActiveByAnimal", mock.Anything, animalID).Return([]*entities.TreatmentPlan{}, nil)
+	m.visits.On("GetByAnimalID", mock.Anything, animalID).Return([]*entities.VeterinaryVisit{
+		{AnimalID: animalID, VisitType: entities.VisitTypeCheckup, Status: entities.VisitStatusCompleted, VisitDate: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), Diagnosis: "Healthy overall"},
+		{AnimalID: animalID, VisitType: entities.VisitTypeDental, Status: entities.VisitStatusCancelled, VisitDate: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC), Diagnosis: "Never happened"},
+	}, nil)
+}
+
+func TestPacketUseCase_RenderPacket(t *testing.T) {
+	uc, m := newPacketUseCase(t)
+	animal := packetAnimal()
+	m.expectRecords(animal)
+
+	packet, err := uc.RenderPacket(context.Background(), animal.ID, &PacketRequest{Language: "pl"})
+	require.NoError(t, err)
+
+	content := string(packet.Content)
+	assert.True(t, strings.HasPrefix(content, "%PDF-"))
+	assert.Equal(t, "pl", packet.Language)
+	assert.True(t, strings.HasPrefix(packet.FileName, "karta-zdrowia-reksio-pl-"))
+
+	// Labels and enum values are translated; the Polish name is used
+	assert.Contains(t, content, "(Reksio)")
+	assert.Contains(t, content, "(Gatunek)")
+	assert.Contains(t, content, "(pies)")
+	assert.Contains(t, content, "(spokojny)")
+	assert.Contains(t, content, "(01.05.2022)")
+
+	assert.Contains(t, content, `(Nobivac Rabies \(RABIES\))`)
+	assert.Contains(t, content, "(LOT42)")
+	assert.Contains(t, content, "(Meloxicam)")
+	assert.Contains(t, content, "(Hip dysplasia)")
+	assert.NotContains(t, content, "Ear infection", "resolved conditions are left out")
+	assert.Contains(t, content, "(Healthy overall)")
+	assert.NotContains(t, content, "Never happened", "cancelled visits are not history")
+	assert.Contains(t, content, "(Pulls on the leash)")
+}
+
+func TestPacketUseCase_RenderPacket_Sections(t *testing.T) {
+	uc, m := newPacketUseCase(t)
+	animal := packetAnimal()
+	m.animals.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+
+	packet, err := uc.RenderPacket(context.Background(), animal.ID, &PacketRequest{Language: "en", Sections: []Section{SectionBehavior}})
+	require.NoError(t, err)
+
+	content := string(packet.Content)
+	assert.Contains(t, content, "(Rex)")
+	assert.Contains(t, content, "(Good with children)")
+	assert.NotContains(t, content, "(Vaccination certificate)")
+	m.vaccinations.AssertNotCalled(t, "GetByAnimalID", mock.Anything, mock.Anything)
+	m.visits.AssertNotCalled(t, "GetByAnimalID", mock.Anything, mock.Anything)
+
+	_, err = uc.RenderPacket(context.Background(), animal.ID, &PacketRequest{Language: "de"})
+	assert.Error(t, err)
+}
+
+func TestPacketUseCase_CreatePacket_VersionsPreviousPacket(t *testing.T) {
+	uc, m := newPacketUseCase(t)
+	animal := packetAnimal()
+	userID := primitive.NewObjectID()
+	m.expectRecords(animal)
+
+	previous := entities.NewDocument("Karta zdrowia – Reksio", "old.pdf", 100, "application/pdf", entities.DocumentTypeMedical, userID)
+	previous.RelatedEntity = "animal"
+	previous.RelatedEntityID = &animal.ID
+	previous.Version = 2
+	previous.Metadata = map[string]string{"generator": "medical_packet", "language": "pl"}
+	english := entities.NewDocument("Medical history – Rex", "en.pdf", 100, "application/pdf", entities.DocumentTypeMedical, userID)
+	english.Version = 5
+	english.Metadata = map[string]string{"generator": "medical_packet", "language": "en"}
+
+	m.documents.On("GetByRelatedEntity", mock.Anything, "animal", animal.ID).Return([]*entities.Document{previous, english}, nil)
+	m.documents.On("Create", mock.Anything, mock.AnythingOfType("*entities.Document")).Return(nil).Once()
+	m.auditLogs.On("Create", mock.Anything, mock.AnythingOfType("*entities.AuditLog")).Return(nil)
+
+	document, err := uc.GeneratePacket(context.Background(), animal.ID, "pl", userID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, document.Version)
+	assert.Equal(t, previous.ID, *document.PreviousVersion)
+	assert.Equal(t, entities.DocumentTypeMedical, document.Type)
+	assert.Equal(t, animal.ID, *document.RelatedEntityID)
+	assert.Equal(t, "application/pdf", document.MimeType)
+	assert.Equal(t, []string{PacketTag}, document.Tags)
+	assert.Equal(t, "pl", document.Metadata["language"])
+	assert.Equal(t, userID, document.UploadedBy)
+	assert.NotEmpty(t, document.FileURL)
+
+	content, _, err := uc.storageService.Open(context.Background(), document.FileURL)
+	require.NoError(t, err)
+	defer content.Close()
+	hash := sha256.New()
+	n, err := io.Copy(hash, content)
+	require.NoError(t, err)
+	assert.Equal(t, document.FileSize, n)
+	assert.Equal(t, hex.EncodeToString(hash.Sum(nil)), document.Checksum)
+
+	m.documents.AssertExpectations(t)
+}
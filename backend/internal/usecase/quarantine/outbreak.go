@@ -0,0 +1,495 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutbreakTag marks the tasks generated for outbreaks
+const OutbreakTag = "outbreak"
+
+// DeclareOutbreakRequest represents a request to declare an outbreak
+type DeclareOutbreakRequest struct {
+	Disease           entities.OutbreakDisease `json:"disease" validate:"required,oneof=parvovirus panleukopenia uri"`
+	CaseAnimalIDs     []string                 `json:"case_animal_ids" validate:"required,min=1,dive,required"`
+	ExposureDays      int                      `json:"exposure_days,omitempty" validate:"omitempty,min=1,max=60"` // defaults to the disease's tracing window
+	IsolationLocation string                   `json:"isolation_location,omitempty"`                              // where the cases are moved
+	QuarantineExposed *bool                    `json:"quarantine_exposed,omitempty"`                              // defaults to true
+	Notes             string                   `json:"notes,omitempty"`
+}
+
+// AddOutbreakCasesRequest represents a request to add cases to an active outbreak
+type AddOutbreakCasesRequest struct {
+	CaseAnimalIDs     []string `json:"case_animal_ids" validate:"required,min=1,dive,required"`
+	IsolationLocation string   `json:"isolation_location,omitempty"`
+	QuarantineExposed *bool    `json:"quarantine_exposed,omitempty"`
+}
+
+// ResolveOutbreakRequest represents a request to close an outbreak
+type ResolveOutbreakRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+// outbreakCaseSpec tells how new cases of an outbreak are handled
+type outbreakCaseSpec struct {
+	IsolationLocation string
+	QuarantineExposed bool
+}
+
+// DeclareOutbreak records an outbreak, traces the animals housed with the
+// cases and generates isolation, testing and cleaning tasks
+func (uc *QuarantineUseCase) DeclareOutbreak(ctx context.Context, req *DeclareOutbreakRequest, userID primitive.ObjectID) (*entities.Outbreak, error) {
+	profile, ok := entities.DiseaseProfiles[req.Disease]
+	if !ok {
+		return nil, errors.NewBadRequest("unknown disease")
+	}
+
+	cases, err := uc.loadAnimals(ctx, req.CaseAnimalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	days := req.ExposureDays
+	if days == 0 {
+		days = profile.ExposureDays
+	}
+	now := time.Now()
+	outbreak := &entities.Outbreak{
+		ID:           primitive.NewObjectID(),
+		Disease:      req.Disease,
+		Status:       entities.OutbreakStatusActive,
+		DeclaredAt:   now,
+		ExposureFrom: now.AddDate(0, 0, -days),
+		Cases:        []entities.OutbreakCase{},
+		Locations:    []string{},
+		Exposures:    []entities.OutbreakExposure{},
+		Notes:        req.Notes,
+		CreatedBy:    userID,
+		UpdatedBy:    userID,
+	}
+
+	if err := uc.addCases(ctx, outbreak, cases, outbreakCaseSpec{
+		IsolationLocation: req.IsolationLocation,
+		QuarantineExposed: req.QuarantineExposed == nil || *req.QuarantineExposed,
+	}, userID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.outbreakRepo.Create(ctx, outbreak); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionCreate, "outbreak", profile.Name,
+		fmt.Sprintf("Declared %s outbreak: %d cases, %d exposed animals", profile.Name, len(outbreak.Cases), len(outbreak.Exposures))).
+		WithEntityID(outbreak.ID))
+
+	return outbreak, nil
+}
+
+// AddOutbreakCases adds cases to an active outbreak and traces their contacts
+func (uc *QuarantineUseCase) AddOutbreakCases(ctx context.Context, id primitive.ObjectID, req *AddOutbreakCasesRequest, userID primitive.ObjectID) (*entities.Outbreak, error) {
+	outbreak, err := uc.outbreakRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !outbreak.IsActive() {
+		return nil, errors.NewBadRequest("outbreak has been resolved")
+	}
+
+	cases, err := uc.loadAnimals(ctx, req.CaseAnimalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.addCases(ctx, outbreak, cases, outbreakCaseSpec{
+		IsolationLocation: req.IsolationLocation,
+		QuarantineExposed: req.QuarantineExposed == nil || *req.QuarantineExposed,
+	}, userID); err != nil {
+		return nil, err
+	}
+
+	outbreak.UpdatedBy = userID
+	if err := uc.outbreakRepo.Update(ctx, outbreak); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "outbreak", string(outbreak.Disease),
+		fmt.Sprintf("Added cases to the outbreak: %d cases, %d exposed animals", len(outbreak.Cases), len(outbreak.Exposures))).
+		WithEntityID(outbreak.ID))
+
+	return outbreak, nil
+}
+
+// ResolveOutbreak closes an outbreak. Quarantines it started stay in force
+// until each animal is released.
+func (uc *QuarantineUseCase) ResolveOutbreak(ctx context.Context, id primitive.ObjectID, req *ResolveOutbreakRequest, userID primitive.ObjectID) (*entities.Outbreak, error) {
+	outbreak, err := uc.outbreakRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !outbreak.IsActive() {
+		return nil, errors.NewBadRequest("outbreak has already been resolved")
+	}
+
+	now := time.Now()
+	outbreak.Status = entities.OutbreakStatusResolved
+	outbreak.ResolvedAt = &now
+	outbreak.ResolvedBy = &userID
+	outbreak.ResolutionNotes = req.Notes
+	outbreak.UpdatedBy = userID
+	if err := uc.outbreakRepo.Update(ctx, outbreak); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "outbreak", string(outbreak.Disease),
+		"Resolved the outbreak").
+		WithEntityID(outbreak.ID))
+
+	return outbreak, nil
+}
+
+// GetOutbreak returns an outbreak by ID
+func (uc *QuarantineUseCase) GetOutbreak(ctx context.Context, id primitive.ObjectID) (*entities.Outbreak, error) {
+	return uc.outbreakRepo.FindByID(ctx, id)
+}
+
+// ListOutbreaks lists outbreaks
+func (uc *QuarantineUseCase) ListOutbreaks(ctx context.Context, filter *repositories.OutbreakFilter) ([]*entities.Outbreak, int64, error) {
+	return uc.outbreakRepo.List(ctx, filter)
+}
+
+// addCases records the cases, traces the animals exposed to them and
+// generates the containment tasks and quarantines
+func (uc *QuarantineUseCase) addCases(ctx context.Context, outbreak *entities.Outbreak, animals []*entities.Animal, spec outbreakCaseSpec, userID primitive.ObjectID) error {
+	profile := entities.DiseaseProfiles[outbreak.Disease]
+	now := time.Now()
+	knownLocations := append([]string{}, outbreak.Locations...)
+
+	var cases []*entities.Animal
+	for _, animal := range animals {
+		if outbreak.HasCase(animal.ID) {
+			continue
+		}
+		// Housing is read before the case is moved to isolation
+		stays := staysWithin(animal, outbreak.ExposureFrom, now)
+		locations := make([]string, 0, len(stays))
+		for _, stay := range stays {
+			locations = addLocation(locations, stay.Location)
+			outbreak.Locations = addLocation(outbreak.Locations, stay.Location)
+		}
+		outbreak.Cases = append(outbreak.Cases, entities.OutbreakCase{
+			AnimalID:   animal.ID,
+			AnimalName: animalName(animal),
+			Locations:  locations,
+			AddedAt:    now,
+		})
+		cases = append(cases, animal)
+	}
+	if len(cases) == 0 {
+		return errors.NewBadRequest("the animals are already cases of this outbreak")
+	}
+
+	// Animals that became cases are no longer just exposed
+	exposures := outbreak.Exposures[:0]
+	for _, exposure := range outbreak.Exposures {
+		if !outbreak.HasCase(exposure.AnimalID) {
+			exposures = append(exposures, exposure)
+		}
+	}
+	outbreak.Exposures = exposures
+
+	var candidates []*entities.Animal
+	if len(outbreak.Locations) > 0 {
+		var err error
+		candidates, _, err = uc.animalRepo.List(ctx, repositories.AnimalFilter{Locations: outbreak.Locations})
+		if err != nil {
+			return err
+		}
+	}
+	candidatesByID := make(map[primitive.ObjectID]*entities.Animal, len(candidates))
+	for _, candidate := range candidates {
+		candidatesByID[candidate.ID] = candidate
+	}
+
+	var newExposures []*entities.OutbreakExposure
+	for _, traced := range traceExposures(profile, cases, candidates, outbreak.HasCase, outbreak.ExposureFrom, now) {
+		if existing := outbreak.Exposure(traced.AnimalID); existing != nil {
+			mergeExposure(existing, traced)
+			continue
+		}
+		outbreak.Exposures = append(outbreak.Exposures, traced)
+	}
+	for i := range outbreak.Exposures {
+		if len(outbreak.Exposures[i].TaskIDs) == 0 {
+			newExposures = append(newExposures, &outbreak.Exposures[i])
+		}
+	}
+
+	tags := []string{OutbreakTag, OutbreakTag + ":" + outbreak.ID.Hex(), string(outbreak.Disease)}
+	due := now.Add(24 * time.Hour)
+
+	for _, animal := range cases {
+		name := animalName(animal)
+		for _, task := range []*entities.Task{
+			uc.createTask(ctx, fmt.Sprintf("Isolate %s: %s case", name, profile.Name), entities.TaskCategoryMedical, entities.TaskPriorityUrgent, now, animal, outbreak, tags, profile.Isolation, userID),
+			uc.createTask(ctx, fmt.Sprintf("Test %s for %s", name, profile.Name), entities.TaskCategoryMedical, entities.TaskPriorityUrgent, now, animal, outbreak, tags, profile.Testing, userID),
+		} {
+			if task != nil {
+				outbreak.TaskIDs = append(outbreak.TaskIDs, task.ID)
+			}
+		}
+
+		if _, err := uc.ensure(ctx, animal, quarantineSpec{
+			Reason:     entities.QuarantineReasonIllness,
+			Disease:    profile.Name,
+			OutbreakID: &outbreak.ID,
+			Start:      now,
+			End:        now.AddDate(0, 0, profile.QuarantineDays),
+			Location:   spec.IsolationLocation,
+		}, userID); err != nil && animal.IsInCare() {
+			return err
+		}
+	}
+
+	for _, exposure := range newExposures {
+		animal := candidatesByID[exposure.AnimalID]
+		if animal == nil {
+			continue
+		}
+		name := animalName(animal)
+		var tasks []*entities.Task
+		if exposure.InCare {
+			tasks = append(tasks,
+				uc.createTask(ctx, fmt.Sprintf("Separate %s after %s exposure", name, profile.Name), entities.TaskCategoryMedical, entities.TaskPriorityHigh, due, animal, outbreak, tags, profile.Isolation, userID),
+				uc.createTask(ctx, fmt.Sprintf("Monitor and test %s for %s", name, profile.Name), entities.TaskCategoryMedical, entities.TaskPriorityHigh, due, animal, outbreak, tags, profile.Testing, userID),
+			)
+		} else {
+			tasks = append(tasks, uc.createTask(ctx, fmt.Sprintf("Contact the new home of %s about %s exposure", name, profile.Name), entities.TaskCategoryAdministrative, entities.TaskPriorityHigh, due, animal, outbreak, tags,
+				[]string{"Explain the exposure and the symptoms to watch for", "Recommend a visit to their veterinarian", "Record the outcome in the notes"}, userID))
+		}
+		for _, task := range tasks {
+			if task != nil {
+				exposure.TaskIDs = append(exposure.TaskIDs, task.ID)
+				outbreak.TaskIDs = append(outbreak.TaskIDs, task.ID)
+			}
+		}
+	}
+
+	// Quarantine every exposed animal still in care whose incubation period
+	// has not passed, extending existing quarantines if needed
+	if spec.QuarantineExposed {
+		for i := range outbreak.Exposures {
+			exposure := &outbreak.Exposures[i]
+			animal := candidatesByID[exposure.AnimalID]
+			if animal == nil || !exposure.InCare {
+				continue
+			}
+			end := exposure.LastContact.AddDate(0, 0, profile.QuarantineDays)
+			if !end.After(now) {
+				continue
+			}
+			quarantine, err := uc.ensure(ctx, animal, quarantineSpec{
+				Reason:     entities.QuarantineReasonExposure,
+				Disease:    profile.Name,
+				OutbreakID: &outbreak.ID,
+				Start:      now,
+				End:        end,
+			}, userID)
+			if err != nil {
+				return err
+			}
+			exposure.QuarantineID = &quarantine.ID
+		}
+	}
+
+	for _, location := range outbreak.Locations {
+		if len(addLocation(knownLocations, location)) == len(knownLocations) {
+			continue // cleaned when the location was first traced
+		}
+		task := uc.createTask(ctx, fmt.Sprintf("Disinfect %s (%s)", location, profile.Name), entities.TaskCategoryMaintenance, entities.TaskPriorityHigh, now, nil, outbreak,
+			append(tags, "location:"+locationKey(location)), profile.Cleaning, userID)
+		if task != nil {
+			outbreak.TaskIDs = append(outbreak.TaskIDs, task.ID)
+		}
+	}
+
+	return nil
+}
+
+// createTask creates a containment task for an animal, or for the outbreak itself
+func (uc *QuarantineUseCase) createTask(ctx context.Context, title string, category entities.TaskCategory, priority entities.TaskPriority, due time.Time, animal *entities.Animal, outbreak *entities.Outbreak, tags, checklist []string, userID primitive.ObjectID) *entities.Task {
+	task := entities.NewTask(title, category, priority, userID)
+	task.Description = fmt.Sprintf("%s outbreak declared on %s.", entities.DiseaseProfiles[outbreak.Disease].Name, outbreak.DeclaredAt.Format("2006-01-02"))
+	if animal != nil {
+		task.RelatedEntity = "animal"
+		task.RelatedEntityID = &animal.ID
+		if animal.Shelter.AssignedCaretaker != nil {
+			task.AssignTo(*animal.Shelter.AssignedCaretaker)
+		}
+	} else {
+		task.RelatedEntity = "outbreak"
+		task.RelatedEntityID = &outbreak.ID
+	}
+	task.Tags = append([]string{}, tags...)
+	task.DueDate = &due
+	for _, item := range checklist {
+		task.AddChecklistItem(item)
+	}
+
+	if err := uc.taskRepo.Create(ctx, task); err != nil {
+		return nil
+	}
+	return task
+}
+
+// loadAnimals finds the animals with the IDs
+func (uc *QuarantineUseCase) loadAnimals(ctx context.Context, ids []string) ([]*entities.Animal, error) {
+	animals := make([]*entities.Animal, 0, len(ids))
+	for _, hex := range ids {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid animal ID: " + hex)
+		}
+		animal, err := uc.animalRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		animals = append(animals, animal)
+	}
+	return animals, nil
+}
+
+// traceExposures finds the animals that shared a location with one of the
+// cases at the same time during the window
+func traceExposures(profile entities.DiseaseProfile, cases, candidates []*entities.Animal, isCase func(primitive.ObjectID) bool, from, now time.Time) []entities.OutbreakExposure {
+	caseStays := make([]entities.LocationStay, 0)
+	caseEnds := make([]time.Time, 0)
+	for _, animal := range cases {
+		for _, stay := range staysWithin(animal, from, now) {
+			caseStays = append(caseStays, stay)
+			caseEnds = append(caseEnds, *stay.To)
+		}
+	}
+
+	var exposures []entities.OutbreakExposure
+	for _, animal := range candidates {
+		if isCase(animal.ID) || animal.Status == entities.AnimalStatusDeceased || !profile.Affects(animal.Species) {
+			continue
+		}
+
+		var exposure *entities.OutbreakExposure
+		for _, stay := range staysWithin(animal, from, now) {
+			for i, caseStay := range caseStays {
+				if !sameLocation(stay.Location, caseStay.Location) {
+					continue
+				}
+				start := later(stay.From, caseStay.From)
+				end := earlier(*stay.To, caseEnds[i])
+				if !end.After(start) {
+					continue
+				}
+				if exposure == nil {
+					exposure = &entities.OutbreakExposure{
+						AnimalID:     animal.ID,
+						AnimalName:   animalName(animal),
+						Species:      animal.Species,
+						FirstContact: start,
+						LastContact:  end,
+						InCare:       animal.IsInCare(),
+					}
+				}
+				exposure.Locations = addLocation(exposure.Locations, stay.Location)
+				exposure.FirstContact = earlier(exposure.FirstContact, start)
+				exposure.LastContact = later(exposure.LastContact, end)
+			}
+		}
+		if exposure != nil {
+			exposures = append(exposures, *exposure)
+		}
+	}
+
+	sort.Slice(exposures, func(i, j int) bool {
+		return exposures[i].FirstContact.Before(exposures[j].FirstContact)
+	})
+	return exposures
+}
+
+// staysWithin returns the stays of the animal clipped to the window. Every
+// returned stay has an end; for animals that left the shelter it is the day
+// they left.
+func staysWithin(animal *entities.Animal, from, now time.Time) []entities.LocationStay {
+	end := now
+	if !animal.IsInCare() {
+		end = animal.UpdatedAt
+		if animal.Adoption.AdoptionDate != nil {
+			end = *animal.Adoption.AdoptionDate
+		}
+	}
+
+	var stays []entities.LocationStay
+	for _, stay := range animal.LocationStays() {
+		stayEnd := end
+		if stay.To != nil {
+			stayEnd = earlier(*stay.To, end)
+		}
+		stayStart := later(stay.From, from)
+		if !stayEnd.After(stayStart) {
+			continue
+		}
+		stays = append(stays, entities.LocationStay{Location: stay.Location, From: stayStart, To: &stayEnd})
+	}
+	return stays
+}
+
+// mergeExposure widens an exposure traced before with a new trace
+func mergeExposure(existing *entities.OutbreakExposure, traced entities.OutbreakExposure) {
+	for _, location := range traced.Locations {
+		existing.Locations = addLocation(existing.Locations, location)
+	}
+	existing.FirstContact = earlier(existing.FirstContact, traced.FirstContact)
+	existing.LastContact = later(existing.LastContact, traced.LastContact)
+	existing.InCare = traced.InCare
+}
+
+func locationKey(location string) string {
+	return strings.ToLower(strings.TrimSpace(location))
+}
+
+func sameLocation(a, b string) bool {
+	return locationKey(a) != "" && locationKey(a) == locationKey(b)
+}
+
+func addLocation(locations []string, location string) []string {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return locations
+	}
+	for _, l := range locations {
+		if sameLocation(l, location) {
+			return locations
+		}
+	}
+	return append(locations, location)
+}
+
+func earlier(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,144 @@
+package quarantine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func housedAnimal(name, species string, status entities.AnimalStatus, stays ...entities.LocationStay) *entities.Animal {
+	animal := &entities.Animal{
+		ID:      primitive.NewObjectID(),
+		Name:    entities.MultilingualName{English: name},
+		Species: species,
+		Status:  status,
+	}
+	animal.Shelter.LocationHistory = stays
+	if n := len(stays); n > 0 && stays[n-1].To == nil {
+		animal.Shelter.Location = stays[n-1].Location
+	}
+	return animal
+}
+
+func stay(location string, from time.Time, to *time.Time) entities.LocationStay {
+	return entities.LocationStay{Location: location, From: from, To: to}
+}
+
+func TestQuarantineUseCase_DeclareOutbreakTracesExposures(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newQuarantineUseCase()
+	userID := primitive.NewObjectID()
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, n) }
+	at := func(n int) *time.Time { t := days(n); return &t }
+
+	rex := housedAnimal("Rex", "dog", entities.AnimalStatusAvailable, stay("Kennel A", days(-5), nil))
+	// Moved out of the kennel three days ago
+	burek := housedAnimal("Burek", "dog", entities.AnimalStatusAvailable, stay("Kennel A", days(-20), at(-3)), stay("Kennel B", days(-3), nil))
+	// Parvovirus does not spread to cats
+	mruczek := housedAnimal("Mruczek", "cat", entities.AnimalStatusAvailable, stay("Kennel A", days(-10), nil))
+	// Left the kennel before the case arrived
+	azor := housedAnimal("Azor", "dog", entities.AnimalStatusAvailable, stay("Kennel A", days(-30), at(-6)), stay("Kennel C", days(-6), nil))
+	// Adopted two days ago; housed before location history was kept
+	luna := housedAnimal("Luna", "dog", entities.AnimalStatusAdopted)
+	luna.Shelter.Location = " kennel a"
+	luna.Shelter.IntakeDate = days(-20)
+	luna.Adoption.AdoptionDate = at(-2)
+
+	m.animals.On("FindByID", ctx, rex.ID).Return(rex, nil)
+	m.animals.On("List", ctx, mock.MatchedBy(func(filter repositories.AnimalFilter) bool {
+		return assert.ObjectsAreEqual([]string{"Kennel A"}, filter.Locations)
+	})).Return([]*entities.Animal{rex, burek, mruczek, azor, luna}, int64(5), nil)
+	m.animals.On("Update", ctx, mock.AnythingOfType("*entities.Animal")).Return(nil)
+	m.quarantines.On("FindActiveByAnimal", ctx, mock.Anything).Return(nil, nil)
+	quarantined := map[primitive.ObjectID]*entities.Quarantine{}
+	m.quarantines.On("Create", ctx, mock.AnythingOfType("*entities.Quarantine")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*entities.Quarantine)
+		quarantined[q.AnimalID] = q
+	}).Return(nil)
+	var tasks []*entities.Task
+	m.tasks.On("Create", ctx, mock.AnythingOfType("*entities.Task")).Run(func(args mock.Arguments) {
+		tasks = append(tasks, args.Get(1).(*entities.Task))
+	}).Return(nil)
+	m.outbreaks.On("Create", ctx, mock.AnythingOfType("*entities.Outbreak")).Return(nil)
+
+	outbreak, err := uc.DeclareOutbreak(ctx, &DeclareOutbreakRequest{
+		Disease:           entities.DiseaseParvovirus,
+		CaseAnimalIDs:     []string{rex.ID.Hex()},
+		IsolationLocation: "Isolation ward",
+	}, userID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Kennel A"}, outbreak.Locations)
+	require.Len(t, outbreak.Exposures, 2)
+
+	exposed := outbreak.Exposure(burek.ID)
+	require.NotNil(t, exposed)
+	assert.True(t, exposed.InCare)
+	assert.WithinDuration(t, days(-5), exposed.FirstContact, time.Second)
+	assert.WithinDuration(t, days(-3), exposed.LastContact, time.Second)
+	require.NotNil(t, exposed.QuarantineID)
+	assert.Len(t, exposed.TaskIDs, 2, "isolation and testing tasks")
+
+	adopted := outbreak.Exposure(luna.ID)
+	require.NotNil(t, adopted)
+	assert.False(t, adopted.InCare)
+	assert.WithinDuration(t, days(-2), adopted.LastContact, time.Second, "contact ends on the adoption date")
+	assert.Nil(t, adopted.QuarantineID)
+	assert.Len(t, adopted.TaskIDs, 1, "the new home is contacted")
+
+	assert.Nil(t, outbreak.Exposure(mruczek.ID))
+	assert.Nil(t, outbreak.Exposure(azor.ID))
+
+	// The case is isolated; the exposed dog is quarantined for the incubation period after the last contact
+	require.Contains(t, quarantined, rex.ID)
+	assert.Equal(t, entities.QuarantineReasonIllness, quarantined[rex.ID].Reason)
+	assert.Equal(t, "Isolation ward", rex.Shelter.Location)
+	assert.Equal(t, entities.AnimalStatusQuarantine, rex.Status)
+	require.Contains(t, quarantined, burek.ID)
+	assert.Equal(t, entities.QuarantineReasonExposure, quarantined[burek.ID].Reason)
+	assert.Equal(t, outbreak.ID, *quarantined[burek.ID].OutbreakID)
+	assert.WithinDuration(t, days(11), quarantined[burek.ID].EndDate, time.Second)
+	assert.NotContains(t, quarantined, luna.ID)
+
+	var cleaning []*entities.Task
+	for _, task := range tasks {
+		if task.Category == entities.TaskCategoryMaintenance {
+			cleaning = append(cleaning, task)
+		}
+	}
+	require.Len(t, cleaning, 1)
+	assert.Equal(t, "Disinfect Kennel A (Canine parvovirus)", cleaning[0].Title)
+	assert.NotEmpty(t, cleaning[0].Checklist)
+	// Isolation and testing for the case and the exposed dog, a call to the new home and cleaning;
+	// plus a release task for each of the two quarantines
+	assert.Len(t, tasks, 8)
+	assert.Len(t, outbreak.TaskIDs, 6)
+}
+
+func TestTraceExposures_OverlapMustBeConcurrent(t *testing.T) {
+	now := time.Now()
+	from := now.AddDate(0, 0, -10)
+	day := func(n int) time.Time { return now.AddDate(0, 0, n) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	kase := housedAnimal("Case", "cat", entities.AnimalStatusAvailable, stay("Room 1", day(-8), ptr(day(-4))), stay("Room 2", day(-4), nil))
+	before := housedAnimal("Before", "cat", entities.AnimalStatusAvailable, stay("Room 2", day(-9), ptr(day(-5))), stay("Room 3", day(-5), nil))
+	both := housedAnimal("Both", "cat", entities.AnimalStatusAvailable, stay("Room 1", day(-6), ptr(day(-5))), stay("Room 2", day(-1), nil))
+
+	isCase := func(id primitive.ObjectID) bool { return id == kase.ID }
+	exposures := traceExposures(entities.DiseaseProfiles[entities.DiseasePanleukopenia], []*entities.Animal{kase}, []*entities.Animal{kase, before, both}, isCase, from, now)
+
+	require.Len(t, exposures, 1, "sharing a room at different times is not an exposure")
+	assert.Equal(t, both.ID, exposures[0].AnimalID)
+	assert.Equal(t, []string{"Room 1", "Room 2"}, exposures[0].Locations)
+	assert.Equal(t, day(-6), exposures[0].FirstContact)
+	assert.WithinDuration(t, now, exposures[0].LastContact, time.Second)
+}
@@ -0,0 +1,453 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag marks the release tasks of quarantines
+const Tag = "quarantine"
+
+// QuarantineUseCase manages quarantine periods and infectious disease outbreaks
+type QuarantineUseCase struct {
+	quarantineRepo repositories.QuarantineRepository
+	outbreakRepo   repositories.OutbreakRepository
+	animalRepo     repositories.AnimalRepository
+	taskRepo       repositories.TaskRepository
+	auditLogRepo   repositories.AuditLogRepository
+}
+
+// NewQuarantineUseCase creates a new quarantine use case
+func NewQuarantineUseCase(
+	quarantineRepo repositories.QuarantineRepository,
+	outbreakRepo repositories.OutbreakRepository,
+	animalRepo repositories.AnimalRepository,
+	taskRepo repositories.TaskRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) *QuarantineUseCase {
+	return &QuarantineUseCase{
+		quarantineRepo: quarantineRepo,
+		outbreakRepo:   outbreakRepo,
+		animalRepo:     animalRepo,
+		taskRepo:       taskRepo,
+		auditLogRepo:   auditLogRepo,
+	}
+}
+
+// StartQuarantineRequest represents a request to quarantine an animal
+type StartQuarantineRequest struct {
+	Reason    entities.QuarantineReason `json:"reason" validate:"required,oneof=intake transfer exposure illness bite other"`
+	Disease   string                    `json:"disease,omitempty"`
+	StartDate *time.Time                `json:"start_date,omitempty"` // defaults to now
+	EndDate   *time.Time                `json:"end_date,omitempty"`   // defaults to the usual length for the reason
+	Days      int                       `json:"days,omitempty" validate:"omitempty,min=1,max=365"`
+	Location  string                    `json:"location,omitempty"` // isolation housing to move the animal to
+	Notes     string                    `json:"notes,omitempty"`
+}
+
+// ExtendQuarantineRequest represents a request to change the planned release date
+type ExtendQuarantineRequest struct {
+	EndDate time.Time `json:"end_date" validate:"required"`
+	Notes   string    `json:"notes,omitempty"`
+}
+
+// ReleaseQuarantineRequest represents a request to release an animal from quarantine
+type ReleaseQuarantineRequest struct {
+	Location string `json:"location,omitempty"` // housing to move the animal back to
+	Notes    string `json:"notes,omitempty"`
+}
+
+// quarantineSpec describes a quarantine started by the system rather than a request
+type quarantineSpec struct {
+	Reason     entities.QuarantineReason
+	Disease    string
+	OutbreakID *primitive.ObjectID
+	TransferID *primitive.ObjectID
+	Start      time.Time
+	End        time.Time
+	Location   string
+	Notes      string
+}
+
+// StartQuarantine puts an animal in quarantine and schedules its release task
+func (uc *QuarantineUseCase) StartQuarantine(ctx context.Context, animalID primitive.ObjectID, req *StartQuarantineRequest, userID primitive.ObjectID) (*entities.Quarantine, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.quarantineRepo.FindActiveByAnimal(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.NewConflict("animal is already in quarantine")
+	}
+
+	start := time.Now()
+	if req.StartDate != nil {
+		start = *req.StartDate
+	}
+	days := req.Days
+	if days == 0 {
+		days = entities.DefaultQuarantineDays(req.Reason)
+	}
+	end := start.AddDate(0, 0, days)
+	if req.EndDate != nil {
+		end = *req.EndDate
+	}
+	if !end.After(start) {
+		return nil, errors.NewBadRequest("end date must be after the start date")
+	}
+
+	return uc.start(ctx, animal, quarantineSpec{
+		Reason:   req.Reason,
+		Disease:  req.Disease,
+		Start:    start,
+		End:      end,
+		Location: req.Location,
+		Notes:    req.Notes,
+	}, userID)
+}
+
+// QuarantineArrival quarantines an animal that arrived from a partner organization
+func (uc *QuarantineUseCase) QuarantineArrival(ctx context.Context, animalID, transferID primitive.ObjectID, days int, userID primitive.ObjectID) (*entities.Quarantine, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = entities.DefaultQuarantineDays(entities.QuarantineReasonTransfer)
+	}
+
+	now := time.Now()
+	return uc.ensure(ctx, animal, quarantineSpec{
+		Reason:     entities.QuarantineReasonTransfer,
+		TransferID: &transferID,
+		Start:      now,
+		End:        now.AddDate(0, 0, days),
+	}, userID)
+}
+
+// ensure quarantines the animal, or extends and links its active quarantine
+func (uc *QuarantineUseCase) ensure(ctx context.Context, animal *entities.Animal, spec quarantineSpec, userID primitive.ObjectID) (*entities.Quarantine, error) {
+	existing, err := uc.quarantineRepo.FindActiveByAnimal(ctx, animal.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return uc.start(ctx, animal, spec, userID)
+	}
+
+	if existing.OutbreakID == nil {
+		existing.OutbreakID = spec.OutbreakID
+	}
+	if existing.TransferID == nil {
+		existing.TransferID = spec.TransferID
+	}
+	if existing.Disease == "" {
+		existing.Disease = spec.Disease
+	}
+	if spec.End.After(existing.EndDate) {
+		existing.EndDate = spec.End
+		uc.rescheduleReleaseTask(ctx, existing)
+	}
+	if spec.Location != "" && animal.Shelter.Location != spec.Location {
+		animal.MoveTo(spec.Location, time.Now())
+		animal.UpdatedBy = userID
+		if err := uc.animalRepo.Update(ctx, animal); err != nil {
+			return nil, err
+		}
+		existing.Location = spec.Location
+	}
+	existing.UpdatedBy = userID
+	if err := uc.quarantineRepo.Update(ctx, existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// start creates a quarantine, updates the animal and creates the release task
+func (uc *QuarantineUseCase) start(ctx context.Context, animal *entities.Animal, spec quarantineSpec, userID primitive.ObjectID) (*entities.Quarantine, error) {
+	if !animal.IsInCare() {
+		return nil, errors.NewBadRequest("only animals in the shelter's care can be quarantined")
+	}
+
+	quarantine := entities.NewQuarantine(animal.ID, spec.Reason, spec.Start, spec.End, userID)
+	quarantine.Disease = spec.Disease
+	quarantine.OutbreakID = spec.OutbreakID
+	quarantine.TransferID = spec.TransferID
+	quarantine.Notes = spec.Notes
+	quarantine.PreviousStatus = animal.Status
+	if animal.IsQuarantined() {
+		// Set by hand before quarantines were tracked
+		quarantine.PreviousStatus = entities.AnimalStatusAvailable
+	}
+
+	before := *animal
+	before.Shelter.LocationHistory = append([]entities.LocationStay(nil), animal.Shelter.LocationHistory...)
+	if spec.Location != "" {
+		animal.MoveTo(spec.Location, time.Now())
+	}
+	quarantine.Location = animal.Shelter.Location
+
+	animal.Status = entities.AnimalStatusQuarantine
+	animal.UpdatedBy = userID
+	if err := uc.animalRepo.Update(ctx, animal); err != nil {
+		return nil, err
+	}
+
+	task := uc.createReleaseTask(ctx, animal, quarantine, userID)
+	if task != nil {
+		quarantine.ReleaseTaskID = &task.ID
+	}
+
+	if err := uc.quarantineRepo.Create(ctx, quarantine); err != nil {
+		// Without the record nothing would ever release the animal, so it goes back
+		// to its previous status and housing
+		*animal = before
+		if rollbackErr := uc.animalRepo.Update(ctx, animal); rollbackErr != nil {
+			log.Error().Err(rollbackErr).Str("animal_id", animal.ID.Hex()).
+				Msg("failed to restore the animal after its quarantine could not be created")
+		}
+		if task != nil {
+			_ = uc.taskRepo.Delete(ctx, task.ID)
+		}
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionCreate, "quarantine", animalName(animal),
+		fmt.Sprintf("Quarantined %s (%s) until %s", animalName(animal), quarantine.Reason, quarantine.EndDate.Format("2006-01-02"))).
+		WithEntityID(quarantine.ID))
+
+	return quarantine, nil
+}
+
+// ExtendQuarantine changes the planned release date of an active quarantine
+func (uc *QuarantineUseCase) ExtendQuarantine(ctx context.Context, id primitive.ObjectID, req *ExtendQuarantineRequest, userID primitive.ObjectID) (*entities.Quarantine, error) {
+	quarantine, err := uc.quarantineRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !quarantine.IsActive() {
+		return nil, errors.NewBadRequest("quarantine has already ended")
+	}
+	if !req.EndDate.After(quarantine.StartDate) {
+		return nil, errors.NewBadRequest("end date must be after the start date")
+	}
+
+	previous := quarantine.EndDate
+	quarantine.EndDate = req.EndDate
+	if req.Notes != "" {
+		quarantine.Notes = appendNote(quarantine.Notes, req.Notes)
+	}
+	quarantine.UpdatedBy = userID
+	if err := uc.quarantineRepo.Update(ctx, quarantine); err != nil {
+		return nil, err
+	}
+	uc.rescheduleReleaseTask(ctx, quarantine)
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "quarantine", quarantine.ID.Hex(),
+		"Changed the quarantine release date").
+		WithEntityID(quarantine.ID).
+		WithChanges(map[string]interface{}{"end_date": map[string]time.Time{"from": previous, "to": quarantine.EndDate}}))
+
+	return quarantine, nil
+}
+
+// ReleaseQuarantine ends a quarantine and returns the animal to its previous status
+func (uc *QuarantineUseCase) ReleaseQuarantine(ctx context.Context, id primitive.ObjectID, req *ReleaseQuarantineRequest, userID primitive.ObjectID) (*entities.Quarantine, error) {
+	quarantine, err := uc.quarantineRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !quarantine.IsActive() {
+		return nil, errors.NewBadRequest("quarantine has already ended")
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, quarantine.AnimalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status may have changed during the quarantine, e.g. to under treatment
+	if animal.IsQuarantined() {
+		animal.Status = quarantine.PreviousStatus
+		if animal.Status == "" || animal.Status == entities.AnimalStatusQuarantine {
+			animal.Status = entities.AnimalStatusAvailable
+		}
+	}
+	if req.Location != "" {
+		animal.MoveTo(req.Location, time.Now())
+	}
+	animal.UpdatedBy = userID
+	if err := uc.animalRepo.Update(ctx, animal); err != nil {
+		return nil, err
+	}
+
+	quarantine.Release(userID, req.Notes)
+	if err := uc.quarantineRepo.Update(ctx, quarantine); err != nil {
+		return nil, err
+	}
+	uc.completeReleaseTask(ctx, quarantine, userID)
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "quarantine", animalName(animal),
+		fmt.Sprintf("Released %s from quarantine", animalName(animal))).
+		WithEntityID(quarantine.ID).
+		WithChanges(map[string]interface{}{"status": quarantine.Status, "animal_status": animal.Status}))
+
+	return quarantine, nil
+}
+
+// GetQuarantine returns a quarantine by ID
+func (uc *QuarantineUseCase) GetQuarantine(ctx context.Context, id primitive.ObjectID) (*entities.Quarantine, error) {
+	return uc.quarantineRepo.FindByID(ctx, id)
+}
+
+// GetActiveQuarantine returns the active quarantine of an animal
+func (uc *QuarantineUseCase) GetActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (*entities.Quarantine, error) {
+	quarantine, err := uc.quarantineRepo.FindActiveByAnimal(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+	if quarantine == nil {
+		return nil, errors.NewNotFound("animal is not in quarantine")
+	}
+	return quarantine, nil
+}
+
+// HasActiveQuarantine checks whether an animal has a quarantine that was not released yet,
+// whatever its status says
+func (uc *QuarantineUseCase) HasActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (bool, error) {
+	quarantine, err := uc.quarantineRepo.FindActiveByAnimal(ctx, animalID)
+	if err != nil {
+		return false, err
+	}
+	return quarantine != nil, nil
+}
+
+// ListQuarantines lists quarantines
+func (uc *QuarantineUseCase) ListQuarantines(ctx context.Context, filter *repositories.QuarantineFilter) ([]*entities.Quarantine, int64, error) {
+	return uc.quarantineRepo.List(ctx, filter)
+}
+
+// ProcessDueReleases makes sure every quarantine past its release date has an
+// open, high priority release task. Animals are never released automatically;
+// staff confirm the health check first.
+func (uc *QuarantineUseCase) ProcessDueReleases(ctx context.Context) error {
+	now := time.Now()
+	quarantines, _, err := uc.quarantineRepo.List(ctx, &repositories.QuarantineFilter{
+		Status:     string(entities.QuarantineStatusActive),
+		EndsBefore: &now,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, quarantine := range quarantines {
+		var task *entities.Task
+		if quarantine.ReleaseTaskID != nil {
+			task, _ = uc.taskRepo.FindByID(ctx, *quarantine.ReleaseTaskID)
+		}
+
+		if task == nil || task.Status == entities.TaskStatusCancelled {
+			animal, err := uc.animalRepo.FindByID(ctx, quarantine.AnimalID)
+			if err != nil {
+				continue
+			}
+			task = uc.createReleaseTask(ctx, animal, quarantine, quarantine.CreatedBy)
+			if task == nil {
+				continue
+			}
+			quarantine.ReleaseTaskID = &task.ID
+			_ = uc.quarantineRepo.Update(ctx, quarantine)
+		}
+
+		if task.Status != entities.TaskStatusCompleted && task.Priority != entities.TaskPriorityHigh && task.Priority != entities.TaskPriorityUrgent {
+			task.Priority = entities.TaskPriorityHigh
+			task.UpdatedAt = now
+			_ = uc.taskRepo.Update(ctx, task)
+		}
+	}
+
+	return nil
+}
+
+// createReleaseTask creates the task to check and release the animal at the end of the quarantine
+func (uc *QuarantineUseCase) createReleaseTask(ctx context.Context, animal *entities.Animal, quarantine *entities.Quarantine, userID primitive.ObjectID) *entities.Task {
+	task := entities.NewTask(fmt.Sprintf("Release %s from quarantine", animalName(animal)), entities.TaskCategoryMedical, entities.TaskPriorityMedium, userID)
+	task.Description = fmt.Sprintf("Quarantine (%s) ends on %s. Check the animal's health and release it.", quarantine.Reason, quarantine.EndDate.Format("2006-01-02"))
+	task.RelatedEntity = "animal"
+	task.RelatedEntityID = &animal.ID
+	task.Tags = []string{Tag, Tag + ":" + quarantine.ID.Hex()}
+	dueDate := quarantine.EndDate
+	task.DueDate = &dueDate
+	if animal.Shelter.AssignedCaretaker != nil {
+		task.AssignTo(*animal.Shelter.AssignedCaretaker)
+	}
+	task.AddChecklistItem("Health check: temperature, appetite, stool and discharge")
+	if quarantine.Disease != "" {
+		task.AddChecklistItem(fmt.Sprintf("Confirm negative test results for %s", quarantine.Disease))
+	}
+	task.AddChecklistItem("Move to regular housing and disinfect the quarantine space")
+	task.AddChecklistItem("Release the quarantine in the system")
+
+	if err := uc.taskRepo.Create(ctx, task); err != nil {
+		return nil
+	}
+	return task
+}
+
+// rescheduleReleaseTask moves the release task to the new end of the quarantine
+func (uc *QuarantineUseCase) rescheduleReleaseTask(ctx context.Context, quarantine *entities.Quarantine) {
+	if quarantine.ReleaseTaskID == nil {
+		return
+	}
+	task, err := uc.taskRepo.FindByID(ctx, *quarantine.ReleaseTaskID)
+	if err != nil || task.Status == entities.TaskStatusCompleted || task.Status == entities.TaskStatusCancelled {
+		return
+	}
+	dueDate := quarantine.EndDate
+	task.DueDate = &dueDate
+	task.Description = fmt.Sprintf("Quarantine (%s) ends on %s. Check the animal's health and release it.", quarantine.Reason, quarantine.EndDate.Format("2006-01-02"))
+	task.UpdatedAt = time.Now()
+	_ = uc.taskRepo.Update(ctx, task)
+}
+
+// completeReleaseTask closes the release task once the animal is released
+func (uc *QuarantineUseCase) completeReleaseTask(ctx context.Context, quarantine *entities.Quarantine, userID primitive.ObjectID) {
+	if quarantine.ReleaseTaskID == nil {
+		return
+	}
+	task, err := uc.taskRepo.FindByID(ctx, *quarantine.ReleaseTaskID)
+	if err != nil || task.Status == entities.TaskStatusCompleted || task.Status == entities.TaskStatusCancelled {
+		return
+	}
+	task.Complete(userID)
+	task.CompletionNotes = quarantine.ReleaseNotes
+	_ = uc.taskRepo.Update(ctx, task)
+}
+
+func animalName(animal *entities.Animal) string {
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	if animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	return animal.ID.Hex()
+}
+
+func appendNote(notes, note string) string {
+	if notes == "" {
+		return note
+	}
+	return notes + "\n" + note
+}
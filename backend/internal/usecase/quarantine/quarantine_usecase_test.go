@@ -0,0 +1,181 @@
+package quarantine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type quarantineMocks struct {
+	quarantines *mocks.QuarantineRepository
+	outbreaks   *mocks.OutbreakRepository
+	animals     *mocks.AnimalRepository
+	tasks       *mocks.TaskRepository
+	auditLogs   *mocks.AuditLogRepository
+}
+
+func newQuarantineUseCase() (*QuarantineUseCase, *quarantineMocks) {
+	m := &quarantineMocks{
+		quarantines: new(mocks.QuarantineRepository),
+		outbreaks:   new(mocks.OutbreakRepository),
+		animals:     new(mocks.AnimalRepository),
+		tasks:       new(mocks.TaskRepository),
+		auditLogs:   new(mocks.AuditLogRepository),
+	}
+	m.auditLogs.On("Create", mock.Anything, mock.Anything).Return(nil)
+	return NewQuarantineUseCase(m.quarantines, m.outbreaks, m.animals, m.tasks, m.auditLogs), m
+}
+
+func TestQuarantineUseCase_StartAndRelease(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newQuarantineUseCase()
+	userID := primitive.NewObjectID()
+	caretaker := primitive.NewObjectID()
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: "Mruczek"}, Species: "cat", Status: entities.AnimalStatusReserved}
+	animal.Shelter.Location = "Cattery 2"
+	animal.Shelter.AssignedCaretaker = &caretaker
+
+	var releaseTask *entities.Task
+	var saved *entities.Quarantine
+	m.animals.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	m.animals.On("Update", ctx, animal).Return(nil)
+	m.tasks.On("Create", ctx, mock.AnythingOfType("*entities.Task")).Run(func(args mock.Arguments) {
+		releaseTask = args.Get(1).(*entities.Task)
+	}).Return(nil).Once()
+	m.quarantines.On("FindActiveByAnimal", ctx, animal.ID).Return(nil, nil).Once()
+	m.quarantines.On("Create", ctx, mock.AnythingOfType("*entities.Quarantine")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*entities.Quarantine)
+	}).Return(nil)
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	q, err := uc.StartQuarantine(ctx, animal.ID, &StartQuarantineRequest{
+		Reason:    entities.QuarantineReasonBite,
+		StartDate: &start,
+		Location:  "Isolation 1",
+	}, userID)
+	require.NoError(t, err)
+
+	assert.Same(t, saved, q)
+	assert.Equal(t, start.AddDate(0, 0, 10), q.EndDate, "bite quarantines last 10 days")
+	assert.Equal(t, entities.AnimalStatusReserved, q.PreviousStatus)
+	assert.Equal(t, "Isolation 1", q.Location)
+	assert.Equal(t, entities.AnimalStatusQuarantine, animal.Status)
+	require.Len(t, animal.Shelter.LocationHistory, 2, "the move to isolation is recorded")
+	assert.Equal(t, "Cattery 2", animal.Shelter.LocationHistory[0].Location)
+	assert.NotNil(t, animal.Shelter.LocationHistory[0].To)
+
+	require.NotNil(t, releaseTask)
+	assert.Equal(t, releaseTask.ID, *q.ReleaseTaskID)
+	assert.Equal(t, q.EndDate, *releaseTask.DueDate)
+	assert.Equal(t, caretaker, *releaseTask.AssignedTo)
+	assert.Contains(t, releaseTask.Tags, "quarantine:"+q.ID.Hex())
+
+	// A second quarantine is refused while the first is active
+	m.quarantines.On("FindActiveByAnimal", ctx, animal.ID).Return(q, nil)
+	_, err = uc.StartQuarantine(ctx, animal.ID, &StartQuarantineRequest{Reason: entities.QuarantineReasonOther}, userID)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 409, appErr.Code)
+
+	m.quarantines.On("FindByID", ctx, q.ID).Return(q, nil)
+	m.quarantines.On("Update", ctx, q).Return(nil)
+	m.tasks.On("FindByID", ctx, releaseTask.ID).Return(releaseTask, nil)
+	m.tasks.On("Update", ctx, releaseTask).Return(nil)
+
+	released, err := uc.ReleaseQuarantine(ctx, q.ID, &ReleaseQuarantineRequest{Location: "Cattery 2", Notes: "healthy"}, userID)
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.QuarantineStatusReleased, released.Status)
+	assert.Equal(t, userID, *released.ReleasedBy)
+	assert.Equal(t, entities.AnimalStatusReserved, animal.Status, "the previous status is restored")
+	assert.Equal(t, "Cattery 2", animal.Shelter.Location)
+	assert.Equal(t, entities.TaskStatusCompleted, releaseTask.Status)
+
+	_, err = uc.ReleaseQuarantine(ctx, q.ID, &ReleaseQuarantineRequest{}, userID)
+	assert.Error(t, err, "a released quarantine cannot be released again")
+}
+
+func TestQuarantineUseCase_StartRestoresTheAnimalWhenTheRecordFails(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newQuarantineUseCase()
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "dog", Status: entities.AnimalStatusAvailable}
+	animal.Shelter.Location = "Kennel 4"
+
+	var statuses []entities.AnimalStatus
+	m.animals.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	m.animals.On("Update", ctx, animal).Run(func(args mock.Arguments) {
+		statuses = append(statuses, args.Get(1).(*entities.Animal).Status)
+	}).Return(nil)
+	var releaseTask *entities.Task
+	m.tasks.On("Create", ctx, mock.AnythingOfType("*entities.Task")).Run(func(args mock.Arguments) {
+		releaseTask = args.Get(1).(*entities.Task)
+	}).Return(nil)
+	m.tasks.On("Delete", ctx, mock.Anything).Return(nil)
+	m.quarantines.On("FindActiveByAnimal", ctx, animal.ID).Return(nil, nil)
+	m.quarantines.On("Create", ctx, mock.Anything).Return(errors.NewConflict("animal is already in quarantine"))
+
+	_, err := uc.StartQuarantine(ctx, animal.ID, &StartQuarantineRequest{Reason: entities.QuarantineReasonIllness, Location: "Isolation 1"}, primitive.NewObjectID())
+	require.Error(t, err)
+
+	assert.Equal(t, []entities.AnimalStatus{entities.AnimalStatusQuarantine, entities.AnimalStatusAvailable}, statuses)
+	assert.Equal(t, entities.AnimalStatusAvailable, animal.Status)
+	assert.Equal(t, "Kennel 4", animal.Shelter.Location)
+	require.NotNil(t, releaseTask)
+	m.tasks.AssertCalled(t, "Delete", ctx, releaseTask.ID)
+}
+
+func TestQuarantineUseCase_StartRefusesAnimalsOutOfCare(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newQuarantineUseCase()
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Status: entities.AnimalStatusAdopted}
+	m.animals.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	m.quarantines.On("FindActiveByAnimal", ctx, animal.ID).Return(nil, nil)
+
+	_, err := uc.StartQuarantine(ctx, animal.ID, &StartQuarantineRequest{Reason: entities.QuarantineReasonIntake}, primitive.NewObjectID())
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 400, appErr.Code)
+	m.animals.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestQuarantineUseCase_ProcessDueReleases(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newQuarantineUseCase()
+
+	openTask := entities.NewTask("Release Rex from quarantine", entities.TaskCategoryMedical, entities.TaskPriorityMedium, primitive.NewObjectID())
+	withTask := entities.NewQuarantine(primitive.NewObjectID(), entities.QuarantineReasonIntake, time.Now().AddDate(0, 0, -15), time.Now().AddDate(0, 0, -1), primitive.NewObjectID())
+	withTask.ReleaseTaskID = &openTask.ID
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{Polish: "Burek"}, Status: entities.AnimalStatusQuarantine}
+	withoutTask := entities.NewQuarantine(animal.ID, entities.QuarantineReasonTransfer, time.Now().AddDate(0, 0, -14), time.Now().Add(-time.Hour), primitive.NewObjectID())
+
+	m.quarantines.On("List", ctx, mock.MatchedBy(func(filter *repositories.QuarantineFilter) bool {
+		return filter.Status == string(entities.QuarantineStatusActive) && filter.EndsBefore != nil
+	})).Return([]*entities.Quarantine{withTask, withoutTask}, int64(2), nil)
+	m.tasks.On("FindByID", ctx, openTask.ID).Return(openTask, nil)
+	m.tasks.On("Update", ctx, mock.AnythingOfType("*entities.Task")).Return(nil)
+	m.animals.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	var created *entities.Task
+	m.tasks.On("Create", ctx, mock.AnythingOfType("*entities.Task")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*entities.Task)
+	}).Return(nil)
+	m.quarantines.On("Update", ctx, withoutTask).Return(nil)
+
+	require.NoError(t, uc.ProcessDueReleases(ctx))
+
+	assert.Equal(t, entities.TaskPriorityHigh, openTask.Priority, "overdue release tasks are raised")
+	require.NotNil(t, created, "a missing release task is recreated")
+	assert.Equal(t, "Release Burek from quarantine", created.Title)
+	assert.Equal(t, created.ID, *withoutTask.ReleaseTaskID)
+	assert.Equal(t, entities.TaskPriorityHigh, created.Priority)
+	assert.Equal(t, entities.QuarantineStatusActive, withTask.Status, "animals are never released automatically")
+}
@@ -0,0 +1,285 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/fuzzy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+
+	// candidatesPerType bounds the records fetched from each collection before ranking
+	candidatesPerType = 200
+
+	// textScoreWeight balances MongoDB text scores against the prefix match scores
+	textScoreWeight = 0.25
+)
+
+// SearchUseCase handles the global search across entity types
+type SearchUseCase struct {
+	searchRepo repositories.SearchRepository
+}
+
+// NewSearchUseCase creates a new search use case
+func NewSearchUseCase(searchRepo repositories.SearchRepository) *SearchUseCase {
+	return &SearchUseCase{
+		searchRepo: searchRepo,
+	}
+}
+
+// SearchRequest represents a global search request
+type SearchRequest struct {
+	Query    string   `json:"q" validate:"required,min=2"`
+	Types    []string `json:"types,omitempty"`
+	Statuses []string `json:"statuses,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Limit    int      `json:"limit"`
+	Offset   int      `json:"offset"`
+}
+
+// Search runs the query against every entity type the caller may view, ranks the hits
+// and returns the requested page with facet counts
+func (uc *SearchUseCase) Search(ctx context.Context, req *SearchRequest, allowed []entities.SearchEntityType, userID primitive.ObjectID) (*entities.SearchResults, error) {
+	query := strings.TrimSpace(req.Query)
+	if len([]rune(query)) < 2 {
+		return nil, errors.NewBadRequest("search query must have at least 2 characters")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	results := &entities.SearchResults{
+		Query:  query,
+		Hits:   []*entities.SearchHit{},
+		Limit:  limit,
+		Offset: offset,
+		Facets: entities.SearchFacets{
+			Types:    []entities.SearchFacetCount{},
+			Statuses: []entities.SearchFacetCount{},
+			Tags:     []entities.SearchFacetCount{},
+		},
+	}
+
+	if len(allowed) == 0 {
+		return results, nil
+	}
+
+	terms := fuzzy.Tokenize(query)
+	phone := ""
+	if digits := fuzzy.Digits(query); len(digits) >= 3 && looksLikePhone(query) {
+		phone = digits
+	}
+
+	candidates, err := uc.searchRepo.Search(ctx, &repositories.SearchQuery{
+		Text:   query,
+		Terms:  terms,
+		Phone:  phone,
+		Types:  allowed,
+		UserID: userID,
+		Limit:  candidatesPerType,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to search")
+	}
+
+	var ranked []*entities.SearchHit
+	for _, hit := range candidates {
+		hit.Score = score(hit, terms, phone)
+		if hit.Score > 0 {
+			ranked = append(ranked, hit)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	filter := newHitFilter(req)
+	results.Facets = facets(ranked, filter)
+
+	var matched []*entities.SearchHit
+	for _, hit := range ranked {
+		if filter.matches(hit, "") {
+			matched = append(matched, hit)
+		}
+	}
+
+	results.Total = len(matched)
+	if offset < len(matched) {
+		end := offset + limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		results.Hits = matched[offset:end]
+	}
+
+	return results, nil
+}
+
+// score ranks a hit by how well the query terms match its names, emails and phone numbers,
+// plus the text index score. Every term must match something for prefix-only hits.
+func score(hit *entities.SearchHit, terms []string, phone string) float64 {
+	var words []string
+	for _, name := range hit.Names {
+		words = append(words, fuzzy.Tokenize(name)...)
+	}
+	for _, email := range hit.Emails {
+		words = append(words, fuzzy.Fold(email))
+		if at := strings.Index(email, "@"); at > 0 {
+			words = append(words, fuzzy.Tokenize(email[:at])...)
+		}
+	}
+
+	total := 0.0
+	matchedTerms := 0
+	for _, term := range terms {
+		best := 0.0
+		for _, word := range words {
+			if s := fuzzy.Match(term, word); s > best {
+				best = s
+			}
+		}
+		if best > 0 {
+			matchedTerms++
+		}
+		total += best
+	}
+
+	prefixScore := 0.0
+	if len(terms) > 0 && matchedTerms == len(terms) {
+		prefixScore = total / float64(len(terms))
+	}
+
+	if phone != "" {
+		for _, p := range hit.Phones {
+			if strings.Contains(fuzzy.Digits(p), phone) {
+				prefixScore = 1
+				break
+			}
+		}
+	}
+
+	if prefixScore == 0 && hit.TextScore == 0 {
+		return 0
+	}
+
+	return prefixScore + textScoreWeight*hit.TextScore
+}
+
+// looksLikePhone reports whether the query is made of digits and phone separators only
+func looksLikePhone(query string) bool {
+	for _, r := range query {
+		if !strings.ContainsRune("0123456789+-() .", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hitFilter holds the facet selections of a request
+type hitFilter struct {
+	types    map[string]bool
+	statuses map[string]bool
+	tags     map[string]bool
+}
+
+func newHitFilter(req *SearchRequest) *hitFilter {
+	return &hitFilter{
+		types:    toSet(req.Types),
+		statuses: toSet(req.Statuses),
+		tags:     toSet(req.Tags),
+	}
+}
+
+// matches checks the hit against every selected facet except the skipped one,
+// so each facet shows the counts available when only its own selection changes
+func (f *hitFilter) matches(hit *entities.SearchHit, skip string) bool {
+	if skip != "type" && len(f.types) > 0 && !f.types[string(hit.Type)] {
+		return false
+	}
+	if skip != "status" && len(f.statuses) > 0 && !f.statuses[hit.Status] {
+		return false
+	}
+	if skip != "tags" && len(f.tags) > 0 {
+		found := false
+		for _, tag := range hit.Tags {
+			if f.tags[tag] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// facets counts the type, status and tag values of the ranked hits
+func facets(hits []*entities.SearchHit, filter *hitFilter) entities.SearchFacets {
+	types := map[string]int{}
+	statuses := map[string]int{}
+	tags := map[string]int{}
+
+	for _, hit := range hits {
+		if filter.matches(hit, "type") {
+			types[string(hit.Type)]++
+		}
+		if hit.Status != "" && filter.matches(hit, "status") {
+			statuses[hit.Status]++
+		}
+		if filter.matches(hit, "tags") {
+			for _, tag := range hit.Tags {
+				tags[tag]++
+			}
+		}
+	}
+
+	return entities.SearchFacets{
+		Types:    facetCounts(types),
+		Statuses: facetCounts(statuses),
+		Tags:     facetCounts(tags),
+	}
+}
+
+// facetCounts sorts facet values by count, then alphabetically
+func facetCounts(counts map[string]int) []entities.SearchFacetCount {
+	out := make([]entities.SearchFacetCount, 0, len(counts))
+	for value, count := range counts {
+		out = append(out, entities.SearchFacetCount{Value: value, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func hit(entityType entities.SearchEntityType, name, status string, tags ...string) *entities.SearchHit {
+	return &entities.SearchHit{
+		Type:   entityType,
+		ID:     primitive.NewObjectID(),
+		Title:  name,
+		Status: status,
+		Tags:   tags,
+		Names:  []string{name},
+	}
+}
+
+func TestSearchUseCase_RanksTypoTolerantMatches(t *testing.T) {
+	mockRepo := new(mocks.SearchRepository)
+	useCase := NewSearchUseCase(mockRepo)
+
+	exact := hit(entities.SearchEntityDonor, "Anna Kowalska", "active")
+	typo := hit(entities.SearchEntityVolunteer, "Anna Kowalsky", "active")
+	prefix := hit(entities.SearchEntityVolunteer, "Irena Kowalskàja", "active")
+	unrelated := hit(entities.SearchEntityDonor, "Piotr Nowak", "active")
+
+	mockRepo.On("Search", mock.Anything, mock.MatchedBy(func(q *repositories.SearchQuery) bool {
+		return q.Text == "kowalska" && len(q.Types) == 2
+	})).Return([]*entities.SearchHit{unrelated, typo, prefix, exact}, nil)
+
+	allowed := []entities.SearchEntityType{entities.SearchEntityDonor, entities.SearchEntityVolunteer}
+	results, err := useCase.Search(context.Background(), &SearchRequest{Query: " kowalska "}, allowed, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, results.Hits, 3)
+	assert.Equal(t, exact.ID, results.Hits[0].ID)
+	assert.Equal(t, prefix.ID, results.Hits[1].ID)
+	assert.Equal(t, typo.ID, results.Hits[2].ID)
+	assert.Equal(t, 3, results.Total)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSearchUseCase_MatchesPhoneNumbers(t *testing.T) {
+	mockRepo := new(mocks.SearchRepository)
+	useCase := NewSearchUseCase(mockRepo)
+
+	donor := hit(entities.SearchEntityDonor, "Jan Nowak", "active")
+	donor.Phones = []string{"+48 601-234-567"}
+
+	mockRepo.On("Search", mock.Anything, mock.MatchedBy(func(q *repositories.SearchQuery) bool {
+		return q.Phone == "601234"
+	})).Return([]*entities.SearchHit{donor}, nil)
+
+	results, err := useCase.Search(context.Background(), &SearchRequest{Query: "601 234"}, entities.SearchEntityTypes, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, donor.ID, results.Hits[0].ID)
+}
+
+func TestSearchUseCase_FacetsAndFilters(t *testing.T) {
+	mockRepo := new(mocks.SearchRepository)
+	useCase := NewSearchUseCase(mockRepo)
+
+	hits := []*entities.SearchHit{
+		hit(entities.SearchEntityDonor, "Burek Fund", "active", "vip"),
+		hit(entities.SearchEntityDonor, "Burek Friends", "inactive"),
+		hit(entities.SearchEntityEvent, "Burek Day", "scheduled", "vip"),
+		hit(entities.SearchEntityAnimal, "Burek", "available"),
+	}
+	mockRepo.On("Search", mock.Anything, mock.Anything).Return(hits, nil)
+
+	req := &SearchRequest{Query: "burek", Types: []string{"donor"}, Tags: []string{"vip"}, Limit: 10}
+	results, err := useCase.Search(context.Background(), req, entities.SearchEntityTypes, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, hits[0].ID, results.Hits[0].ID)
+
+	// The type facet ignores the type selection but applies the tag selection
+	assert.ElementsMatch(t, []entities.SearchFacetCount{
+		{Value: "donor", Count: 1},
+		{Value: "event", Count: 1},
+	}, results.Facets.Types)
+	assert.Equal(t, []entities.SearchFacetCount{{Value: "vip", Count: 1}}, results.Facets.Tags)
+	assert.Equal(t, []entities.SearchFacetCount{{Value: "active", Count: 1}}, results.Facets.Statuses)
+}
+
+func TestSearchUseCase_Validation(t *testing.T) {
+	mockRepo := new(mocks.SearchRepository)
+	useCase := NewSearchUseCase(mockRepo)
+
+	_, err := useCase.Search(context.Background(), &SearchRequest{Query: "a"}, entities.SearchEntityTypes, primitive.NewObjectID())
+	assert.Error(t, err)
+
+	// Without any viewable entity type the repository is not queried
+	results, err := useCase.Search(context.Background(), &SearchRequest{Query: "burek"}, nil, primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Empty(t, results.Hits)
+	mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+}
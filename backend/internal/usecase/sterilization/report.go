@@ -0,0 +1,149 @@
+package sterilization
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ComplianceRow is the spay/neuter outcome of one adoption
+type ComplianceRow struct {
+	AdoptionID      primitive.ObjectID           `json:"adoption_id"`
+	AnimalID        primitive.ObjectID           `json:"animal_id"`
+	AnimalName      string                       `json:"animal_name"`
+	Species         string                       `json:"species,omitempty"`
+	MicrochipNumber string                       `json:"microchip_number,omitempty"`
+	AdopterName     string                       `json:"adopter_name,omitempty"`
+	AdoptionDate    time.Time                    `json:"adoption_date"`
+	DueDate         time.Time                    `json:"due_date"`
+	Status          entities.SterilizationStatus `json:"status"`
+	ProcedureDate   *time.Time                   `json:"procedure_date,omitempty"`
+	Evidence        string                       `json:"evidence,omitempty"` // visit, certificate
+	OnTime          bool                         `json:"on_time"`
+	DaysOverdue     int                          `json:"days_overdue,omitempty"`
+	RemindersSent   int                          `json:"reminders_sent"`
+	WaiverReason    string                       `json:"waiver_reason,omitempty"`
+}
+
+// ComplianceReport summarizes the spay/neuter compliance of intact animals adopted in a period
+type ComplianceReport struct {
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Total     int       `json:"total"`
+	Completed int       `json:"completed"`
+	OnTime    int       `json:"on_time"`
+	Pending   int       `json:"pending"`
+	Overdue   int       `json:"overdue"`
+	Waived    int       `json:"waived"`
+	// Due counts the requirements that are completed or past their deadline, excluding waivers
+	Due                    int             `json:"due"`
+	ComplianceRate         float64         `json:"compliance_rate"` // percentage of due requirements completed
+	OnTimeRate             float64         `json:"on_time_rate"`    // percentage of due requirements completed by the deadline
+	AverageDaysToProcedure float64         `json:"average_days_to_procedure"`
+	Adoptions              []ComplianceRow `json:"adoptions"`
+}
+
+// GetComplianceReport reports the spay/neuter compliance of intact animals adopted between two dates
+func (uc *SterilizationUseCase) GetComplianceReport(ctx context.Context, from, to time.Time) (*ComplianceReport, error) {
+	if to.Before(from) {
+		return nil, errors.NewBadRequest("the end date is before the start date")
+	}
+
+	adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		SterilizationStatuses: []string{
+			string(entities.SterilizationStatusPending),
+			string(entities.SterilizationStatusOverdue),
+			string(entities.SterilizationStatusCompleted),
+			string(entities.SterilizationStatusWaived),
+		},
+		FromDate:  &from,
+		ToDate:    &to,
+		SortBy:    "adoption_date",
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &ComplianceReport{From: from, To: to, Adoptions: []ComplianceRow{}}
+	var procedureDays float64
+	for _, adoption := range adoptions {
+		requirement := adoption.Sterilization
+		if requirement == nil {
+			continue
+		}
+
+		row := ComplianceRow{
+			AdoptionID:    adoption.ID,
+			AnimalID:      adoption.AnimalID,
+			AnimalName:    adoption.AnimalID.Hex(),
+			AdoptionDate:  adoption.AdoptionDate,
+			DueDate:       requirement.DueDate,
+			Status:        requirement.Status,
+			ProcedureDate: requirement.ProcedureDate,
+			OnTime:        requirement.IsOnTime(),
+			RemindersSent: len(requirement.Reminders),
+			WaiverReason:  requirement.WaiverReason,
+		}
+		if requirement.IsPastDue(now) {
+			row.Status = entities.SterilizationStatusOverdue
+			row.DaysOverdue = int(now.Sub(requirement.DueDate).Hours() / 24)
+		}
+		switch {
+		case requirement.VisitID != nil:
+			row.Evidence = "visit"
+		case requirement.CertificateID != nil:
+			row.Evidence = "certificate"
+		}
+		if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+			row.AnimalName = animalName(animal)
+			row.Species = animal.Species
+			row.MicrochipNumber = animal.Medical.MicrochipNumber
+		}
+		if application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID); err == nil {
+			row.AdopterName = strings.TrimSpace(application.Applicant.FirstName + " " + application.Applicant.LastName)
+		}
+
+		report.Total++
+		switch row.Status {
+		case entities.SterilizationStatusCompleted:
+			report.Completed++
+			report.Due++
+			if row.OnTime {
+				report.OnTime++
+			}
+			if row.ProcedureDate != nil {
+				procedureDays += math.Max(0, row.ProcedureDate.Sub(adoption.AdoptionDate).Hours()/24)
+			}
+		case entities.SterilizationStatusOverdue:
+			report.Overdue++
+			report.Due++
+		case entities.SterilizationStatusWaived:
+			report.Waived++
+		default:
+			report.Pending++
+		}
+		report.Adoptions = append(report.Adoptions, row)
+	}
+
+	if report.Due > 0 {
+		report.ComplianceRate = percentage(report.Completed, report.Due)
+		report.OnTimeRate = percentage(report.OnTime, report.Due)
+	}
+	if report.Completed > 0 {
+		report.AverageDaysToProcedure = math.Round(procedureDays/float64(report.Completed)*10) / 10
+	}
+
+	return report, nil
+}
+
+func percentage(part, total int) float64 {
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
@@ -0,0 +1,445 @@
+package sterilization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag marks the escalation tasks of overdue sterilizations
+const Tag = "sterilization"
+
+// escalationDays is the time staff get to resolve an overdue sterilization
+const escalationDays = 7
+
+// Messenger queues the spay/neuter deadline reminders to adopters
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// SterilizationUseCase tracks the spay/neuter obligation of adopters of intact animals
+type SterilizationUseCase struct {
+	adoptionRepo    repositories.AdoptionRepository
+	applicationRepo repositories.AdoptionApplicationRepository
+	animalRepo      repositories.AnimalRepository
+	visitRepo       repositories.VeterinaryVisitRepository
+	documentRepo    repositories.DocumentRepository
+	taskRepo        repositories.TaskRepository
+	auditLogRepo    repositories.AuditLogRepository
+	messenger       Messenger
+}
+
+// NewSterilizationUseCase creates a new sterilization use case
+func NewSterilizationUseCase(
+	adoptionRepo repositories.AdoptionRepository,
+	applicationRepo repositories.AdoptionApplicationRepository,
+	animalRepo repositories.AnimalRepository,
+	visitRepo repositories.VeterinaryVisitRepository,
+	documentRepo repositories.DocumentRepository,
+	taskRepo repositories.TaskRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	messenger Messenger,
+) *SterilizationUseCase {
+	return &SterilizationUseCase{
+		adoptionRepo:    adoptionRepo,
+		applicationRepo: applicationRepo,
+		animalRepo:      animalRepo,
+		visitRepo:       visitRepo,
+		documentRepo:    documentRepo,
+		taskRepo:        taskRepo,
+		auditLogRepo:    auditLogRepo,
+		messenger:       messenger,
+	}
+}
+
+// RecordSterilizationRequest represents proof that an adopted animal was spayed/neutered
+type RecordSterilizationRequest struct {
+	VisitID       string     `json:"visit_id,omitempty"`       // completed spay/neuter visit
+	CertificateID string     `json:"certificate_id,omitempty"` // uploaded certificate document
+	ProcedureDate *time.Time `json:"procedure_date,omitempty"` // required with a certificate, defaults to the visit date
+	Notes         string     `json:"notes,omitempty"`
+}
+
+// WaiveSterilizationRequest represents a request to waive the spay/neuter requirement
+type WaiveSterilizationRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// RecordSterilization links the spay/neuter visit or certificate to an adoption and closes its requirement
+func (uc *SterilizationUseCase) RecordSterilization(ctx context.Context, adoptionID primitive.ObjectID, req *RecordSterilizationRequest, userID primitive.ObjectID) (*entities.Adoption, error) {
+	if req.VisitID == "" && req.CertificateID == "" {
+		return nil, errors.NewBadRequest("a spay/neuter visit or certificate is required")
+	}
+
+	adoption, err := uc.adoptionRepo.FindByID(ctx, adoptionID)
+	if err != nil {
+		return nil, err
+	}
+	requirement := adoption.Sterilization
+	if requirement == nil {
+		return nil, errors.NewBadRequest("the adoption has no spay/neuter requirement")
+	}
+	if requirement.Status == entities.SterilizationStatusCompleted {
+		return nil, errors.NewBadRequest("the sterilization is already recorded")
+	}
+
+	procedureDate := req.ProcedureDate
+	var visitID, certificateID *primitive.ObjectID
+	if req.VisitID != "" {
+		visit, err := uc.sterilizationVisit(ctx, req.VisitID, adoption.AnimalID)
+		if err != nil {
+			return nil, err
+		}
+		visitID = &visit.ID
+		if procedureDate == nil {
+			procedureDate = &visit.VisitDate
+		}
+	}
+	if req.CertificateID != "" {
+		document, err := uc.certificate(ctx, req.CertificateID, adoption)
+		if err != nil {
+			return nil, err
+		}
+		certificateID = &document.ID
+	}
+	if procedureDate == nil {
+		return nil, errors.NewBadRequest("the procedure date is required with a certificate")
+	}
+	if procedureDate.After(time.Now()) {
+		return nil, errors.NewBadRequest("the procedure date cannot be in the future")
+	}
+
+	requirement.Complete(*procedureDate, visitID, certificateID, userID)
+	if req.Notes != "" {
+		requirement.Notes = req.Notes
+	}
+	if err := uc.saveResolved(ctx, adoption, userID); err != nil {
+		return nil, err
+	}
+	uc.markSterilized(ctx, adoption.AnimalID, userID)
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "adoption", "", "sterilization recorded").
+		WithEntityID(adoption.ID).
+		WithChanges(map[string]interface{}{
+			"sterilization_status": requirement.Status,
+			"procedure_date":       procedureDate,
+			"visit_id":             visitID,
+			"certificate_id":       certificateID,
+		}))
+
+	return adoption, nil
+}
+
+// WaiveSterilization closes a requirement without the procedure, e.g. for a veterinary exemption
+func (uc *SterilizationUseCase) WaiveSterilization(ctx context.Context, adoptionID primitive.ObjectID, req *WaiveSterilizationRequest, userID primitive.ObjectID) (*entities.Adoption, error) {
+	adoption, err := uc.adoptionRepo.FindByID(ctx, adoptionID)
+	if err != nil {
+		return nil, err
+	}
+	if adoption.Sterilization == nil || !adoption.Sterilization.IsOpen() {
+		return nil, errors.NewBadRequest("the adoption has no open spay/neuter requirement")
+	}
+
+	adoption.Sterilization.Waive(req.Reason, userID)
+	if err := uc.saveResolved(ctx, adoption, userID); err != nil {
+		return nil, err
+	}
+
+	_ = uc.auditLogRepo.Create(ctx, entities.NewAuditLog(userID, entities.ActionUpdate, "adoption", "", "sterilization waived").
+		WithEntityID(adoption.ID).
+		WithChanges(map[string]interface{}{
+			"sterilization_status": adoption.Sterilization.Status,
+			"waiver_reason":        req.Reason,
+		}))
+
+	return adoption, nil
+}
+
+// ProcessCompliance is the scheduler job for open requirements. It links spay/neuter
+// visits recorded since the last run, reminds adopters as the deadline approaches
+// and passes, and escalates overdue requirements to the staff member who processed
+// the adoption.
+func (uc *SterilizationUseCase) ProcessCompliance(ctx context.Context) error {
+	adoptions, _, err := uc.adoptionRepo.List(ctx, repositories.AdoptionFilter{
+		SterilizationStatuses: []string{string(entities.SterilizationStatusPending), string(entities.SterilizationStatusOverdue)},
+		SortBy:                "sterilization.due_date",
+		SortOrder:             "asc",
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, adoption := range adoptions {
+		uc.process(ctx, adoption, now)
+	}
+	return nil
+}
+
+// process advances one open requirement
+func (uc *SterilizationUseCase) process(ctx context.Context, adoption *entities.Adoption, now time.Time) {
+	requirement := adoption.Sterilization
+	if requirement == nil || !requirement.IsOpen() {
+		return
+	}
+
+	// The animal is back with the shelter, so the adopter has nothing left to do
+	if adoption.Status == entities.AdoptionStatusReturned || adoption.Status == entities.AdoptionStatusCancelled {
+		requirement.Waive("adoption "+string(adoption.Status), adoption.ProcessedBy)
+		_ = uc.saveResolved(ctx, adoption, adoption.ProcessedBy)
+		return
+	}
+
+	if visit := uc.findSterilizationVisit(ctx, adoption); visit != nil {
+		requirement.Complete(visit.VisitDate, &visit.ID, nil, adoption.ProcessedBy)
+		if uc.saveResolved(ctx, adoption, adoption.ProcessedBy) == nil {
+			uc.markSterilized(ctx, adoption.AnimalID, adoption.ProcessedBy)
+		}
+		return
+	}
+
+	changed := false
+	if kind := requirement.DueReminder(now); kind != "" {
+		if reminder := uc.remind(ctx, adoption, kind, now); reminder != nil {
+			requirement.Reminders = append(requirement.Reminders, *reminder)
+			changed = true
+		}
+	}
+
+	if requirement.IsPastDue(now) {
+		if requirement.Status != entities.SterilizationStatusOverdue {
+			requirement.Status = entities.SterilizationStatusOverdue
+			changed = true
+		}
+		if uc.ensureEscalationTask(ctx, adoption, now) {
+			changed = true
+		}
+	}
+
+	if changed {
+		adoption.UpdatedAt = now
+		_ = uc.adoptionRepo.Update(ctx, adoption)
+	}
+}
+
+// sterilizationVisit loads a completed spay/neuter visit of the animal
+func (uc *SterilizationUseCase) sterilizationVisit(ctx context.Context, id string, animalID primitive.ObjectID) (*entities.VeterinaryVisit, error) {
+	visitID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewBadRequest("invalid visit ID")
+	}
+	visit, err := uc.visitRepo.FindByID(ctx, visitID)
+	if err != nil {
+		return nil, err
+	}
+	if visit.AnimalID != animalID {
+		return nil, errors.NewBadRequest("the visit is for another animal")
+	}
+	if visit.VisitType != entities.VisitTypeSpayNeuter {
+		return nil, errors.NewBadRequest("the visit is not a spay/neuter visit")
+	}
+	if visit.Status != entities.VisitStatusCompleted {
+		return nil, errors.NewBadRequest("the spay/neuter visit is not completed")
+	}
+	return visit, nil
+}
+
+// findSterilizationVisit returns the latest completed spay/neuter visit of the adopted animal
+// since the day of the adoption, if any; visits are often entered with a date only
+func (uc *SterilizationUseCase) findSterilizationVisit(ctx context.Context, adoption *entities.Adoption) *entities.VeterinaryVisit {
+	animalID := adoption.AnimalID
+	adoptedOn := adoption.AdoptionDate.Truncate(24 * time.Hour)
+	visits, _, err := uc.visitRepo.List(ctx, repositories.VeterinaryVisitFilter{
+		AnimalID:  &animalID,
+		FromDate:  &adoptedOn,
+		VisitType: string(entities.VisitTypeSpayNeuter),
+		Status:    string(entities.VisitStatusCompleted),
+		Limit:     1,
+		SortBy:    "visit_date",
+		SortOrder: "desc",
+	})
+	if err != nil || len(visits) == 0 {
+		return nil
+	}
+	return visits[0]
+}
+
+// certificate loads the certificate document and links it to the adoption if it is not linked yet
+func (uc *SterilizationUseCase) certificate(ctx context.Context, id string, adoption *entities.Adoption) (*entities.Document, error) {
+	documentID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewBadRequest("invalid certificate ID")
+	}
+	document, err := uc.documentRepo.FindByID(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if document.ScanStatus == entities.DocumentScanStatusInfected {
+		return nil, errors.NewBadRequest("the certificate failed the malware scan")
+	}
+
+	if document.RelatedEntityID == nil {
+		document.RelatedEntity = "adoption"
+		document.RelatedEntityID = &adoption.ID
+		document.UpdatedAt = time.Now()
+		_ = uc.documentRepo.Update(ctx, document)
+	}
+	return document, nil
+}
+
+// saveResolved saves a completed or waived requirement and closes its escalation task
+func (uc *SterilizationUseCase) saveResolved(ctx context.Context, adoption *entities.Adoption, userID primitive.ObjectID) error {
+	adoption.UpdatedBy = userID
+	if err := uc.adoptionRepo.Update(ctx, adoption); err != nil {
+		return err
+	}
+
+	if taskID := adoption.Sterilization.EscalationTaskID; taskID != nil {
+		task, err := uc.taskRepo.FindByID(ctx, *taskID)
+		if err == nil && task.Status != entities.TaskStatusCompleted && task.Status != entities.TaskStatusCancelled {
+			if adoption.Sterilization.Status == entities.SterilizationStatusCompleted {
+				task.Complete(userID)
+			} else {
+				task.Cancel()
+			}
+			_ = uc.taskRepo.Update(ctx, task)
+		}
+	}
+	return nil
+}
+
+// markSterilized updates the medical record of the adopted animal
+func (uc *SterilizationUseCase) markSterilized(ctx context.Context, animalID, userID primitive.ObjectID) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil || animal.Medical.Sterilized {
+		return
+	}
+	animal.Medical.Sterilized = true
+	animal.UpdatedBy = userID
+	_ = uc.animalRepo.Update(ctx, animal)
+}
+
+// remind queues a reminder to the adopter by email, or by SMS without an email address
+func (uc *SterilizationUseCase) remind(ctx context.Context, adoption *entities.Adoption, kind entities.SterilizationReminderKind, now time.Time) *entities.SterilizationReminder {
+	if uc.messenger == nil {
+		return nil
+	}
+	application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID)
+	if err != nil {
+		return nil
+	}
+	applicant := application.Applicant
+
+	name := "your pet"
+	if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+		name = animalName(animal)
+	}
+	subject, body := reminderMessage(kind, applicant.FirstName, name, adoption.Sterilization.DueDate)
+
+	channel := entities.TemplateTypeEmail
+	if applicant.Email == "" {
+		if applicant.Phone == "" {
+			return nil
+		}
+		channel = entities.TemplateTypeSMS
+	}
+	communication := entities.NewCommunication(channel, entities.TemplateCategoryAdoption, applicant.Email, subject, body, adoption.ProcessedBy)
+	communication.RecipientPhone = applicant.Phone
+	communication.RecipientName = strings.TrimSpace(applicant.FirstName + " " + applicant.LastName)
+	communication.RelatedType = "adoption"
+	communication.RelatedID = &adoption.ID
+	communication.Metadata["sterilization_reminder"] = string(kind)
+
+	if err := uc.messenger.CreateCommunication(ctx, communication, adoption.ProcessedBy); err != nil {
+		return nil
+	}
+	return &entities.SterilizationReminder{Kind: kind, SentAt: now, Channel: channel, CommunicationID: &communication.ID}
+}
+
+// reminderMessage returns the subject and body of a reminder
+func reminderMessage(kind entities.SterilizationReminderKind, firstName, animal string, dueDate time.Time) (string, string) {
+	greeting := "Hello"
+	if firstName != "" {
+		greeting += " " + firstName
+	}
+	due := dueDate.Format("2006-01-02")
+	proof := "Once the procedure is done, please send us the certificate from your veterinarian."
+
+	switch kind {
+	case entities.SterilizationReminderOverdue:
+		return fmt.Sprintf("%s's spay/neuter is overdue", animal),
+			fmt.Sprintf("%s,\n\nthe adoption agreement required %s to be spayed/neutered by %s and we have not received confirmation yet. "+
+				"Please contact us as soon as possible. If the procedure is already done, send us the certificate from your veterinarian.", greeting, animal, due)
+	case entities.SterilizationReminderFinal:
+		return fmt.Sprintf("%s's spay/neuter is due in 3 days", animal),
+			fmt.Sprintf("%s,\n\nas agreed at adoption, %s must be spayed/neutered by %s. %s", greeting, animal, due, proof)
+	default:
+		return fmt.Sprintf("Reminder: %s is due to be spayed/neutered by %s", animal, due),
+			fmt.Sprintf("%s,\n\nthank you for adopting %s. As agreed at adoption, %s must be spayed/neutered by %s. "+
+				"If you have not booked the procedure yet, please do so now. %s", greeting, animal, animal, due, proof)
+	}
+}
+
+// ensureEscalationTask creates the follow-up task of an overdue requirement unless an open one exists
+func (uc *SterilizationUseCase) ensureEscalationTask(ctx context.Context, adoption *entities.Adoption, now time.Time) bool {
+	requirement := adoption.Sterilization
+	if requirement.EscalationTaskID != nil {
+		task, err := uc.taskRepo.FindByID(ctx, *requirement.EscalationTaskID)
+		if err != nil || task.Status != entities.TaskStatusCancelled {
+			return false
+		}
+	}
+
+	name := adoption.AnimalID.Hex()
+	if animal, err := uc.animalRepo.FindByID(ctx, adoption.AnimalID); err == nil {
+		name = animalName(animal)
+	}
+	var contact []string
+	if application, err := uc.applicationRepo.FindByID(ctx, adoption.ApplicationID); err == nil {
+		applicant := application.Applicant
+		for _, value := range []string{strings.TrimSpace(applicant.FirstName + " " + applicant.LastName), applicant.Email, applicant.Phone} {
+			if value != "" {
+				contact = append(contact, value)
+			}
+		}
+	}
+
+	task := entities.NewTask(fmt.Sprintf("Follow up overdue spay/neuter of %s", name), entities.TaskCategoryAdoption, entities.TaskPriorityHigh, adoption.ProcessedBy)
+	task.Description = fmt.Sprintf("The adopter had to spay/neuter %s by %s.", name, requirement.DueDate.Format("2006-01-02"))
+	if len(contact) > 0 {
+		task.Description += "\nAdopter: " + strings.Join(contact, ", ")
+	}
+	task.RelatedEntity = "adoption"
+	task.RelatedEntityID = &adoption.ID
+	task.Tags = []string{Tag, Tag + ":" + adoption.ID.Hex()}
+	dueDate := now.AddDate(0, 0, escalationDays)
+	task.DueDate = &dueDate
+	if !adoption.ProcessedBy.IsZero() {
+		task.AssignTo(adoption.ProcessedBy)
+	}
+	task.AddChecklistItem("Contact the adopter")
+	task.AddChecklistItem("Collect the spay/neuter certificate or help book the procedure")
+	task.AddChecklistItem("Record the sterilization or a waiver in the system")
+
+	if err := uc.taskRepo.Create(ctx, task); err != nil {
+		return false
+	}
+	requirement.EscalationTaskID = &task.ID
+	return true
+}
+
+func animalName(animal *entities.Animal) string {
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	if animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	return animal.ID.Hex()
+}
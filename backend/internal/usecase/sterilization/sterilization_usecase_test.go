@@ -0,0 +1,248 @@
+package sterilization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type sterilizationMocks struct {
+	adoptions    *mocks.AdoptionRepository
+	applications *mocks.AdoptionApplicationRepository
+	animals      *mocks.AnimalRepository
+	visits       *mocks.VeterinaryVisitRepository
+	documents    *mocks.DocumentRepository
+	tasks        *mocks.TaskRepository
+	messenger    *testutil.Messenger
+}
+
+func newSterilizationUseCase() (*SterilizationUseCase, *sterilizationMocks) {
+	m := &sterilizationMocks{
+		adoptions:    new(mocks.AdoptionRepository),
+		applications: new(mocks.AdoptionApplicationRepository),
+		animals:      new(mocks.AnimalRepository),
+		visits:       new(mocks.VeterinaryVisitRepository),
+		documents:    new(mocks.DocumentRepository),
+		tasks:        new(mocks.TaskRepository),
+		messenger:    &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+	return NewSterilizationUseCase(m.adoptions, m.applications, m.animals, m.visits, m.documents, m.tasks, auditLogs, m.messenger), m
+}
+
+// adoptedIntact returns an adoption of an intact animal with the given deadline and registers its animal and application
+func adoptedIntact(m *sterilizationMocks, name string, adopted, due time.Time, applicant entities.ApplicantInfo) (*entities.Adoption, *entities.Animal) {
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: name}, Species: "dog", Status: entities.AnimalStatusAdopted}
+	application := &entities.AdoptionApplication{ID: primitive.NewObjectID(), AnimalID: animal.ID, Applicant: applicant}
+	adoption := entities.NewAdoption(application.ID, animal.ID, primitive.NewObjectID(), 200, primitive.NewObjectID())
+	adoption.ID = primitive.NewObjectID()
+	adoption.Status = entities.AdoptionStatusCompleted
+	adoption.AdoptionDate = adopted
+	adoption.AgreesToSpayNeuter = true
+	adoption.Sterilization = entities.NewSterilizationRequirement(due)
+
+	m.animals.On("FindByID", mock.Anything, animal.ID).Return(animal, nil)
+	m.applications.On("FindByID", mock.Anything, application.ID).Return(application, nil)
+	return adoption, animal
+}
+
+func noVisits(m *sterilizationMocks, animalID primitive.ObjectID) {
+	m.visits.On("List", mock.Anything, mock.MatchedBy(func(filter repositories.VeterinaryVisitFilter) bool {
+		return filter.AnimalID != nil && *filter.AnimalID == animalID
+	})).Return([]*entities.VeterinaryVisit{}, int64(0), nil)
+}
+
+func TestSterilizationUseCase_ProcessCompliance(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newSterilizationUseCase()
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, n) }
+
+	upcoming, _ := adoptedIntact(m, "Rex", days(-50), days(10), entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak", Email: "anna@example.com", Phone: "+48500100200"})
+	noVisits(m, upcoming.AnimalID)
+
+	overdue, _ := adoptedIntact(m, "Burek", days(-70), days(-2), entities.ApplicantInfo{FirstName: "Jan", Phone: "+48500300400"})
+	noVisits(m, overdue.AnimalID)
+
+	visited, visitedAnimal := adoptedIntact(m, "Luna", days(-30), days(30), entities.ApplicantInfo{Email: "luna@example.com"})
+	visit := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: visitedAnimal.ID, VisitType: entities.VisitTypeSpayNeuter, Status: entities.VisitStatusCompleted, VisitDate: days(-3)}
+	m.visits.On("List", mock.Anything, mock.MatchedBy(func(filter repositories.VeterinaryVisitFilter) bool {
+		return filter.AnimalID != nil && *filter.AnimalID == visitedAnimal.ID &&
+			filter.VisitType == string(entities.VisitTypeSpayNeuter) && filter.Status == string(entities.VisitStatusCompleted) &&
+			filter.FromDate != nil && filter.FromDate.Equal(visited.AdoptionDate.Truncate(24*time.Hour))
+	})).Return([]*entities.VeterinaryVisit{visit}, int64(1), nil)
+	m.animals.On("Update", mock.Anything, visitedAnimal).Return(nil)
+
+	returned, _ := adoptedIntact(m, "Azor", days(-80), days(-20), entities.ApplicantInfo{Email: "azor@example.com"})
+	returned.Status = entities.AdoptionStatusReturned
+
+	m.adoptions.On("List", mock.Anything, mock.MatchedBy(func(filter repositories.AdoptionFilter) bool {
+		return assert.ObjectsAreEqual([]string{"pending", "overdue"}, filter.SterilizationStatuses)
+	})).Return([]*entities.Adoption{upcoming, overdue, visited, returned}, int64(4), nil)
+	m.adoptions.On("Update", mock.Anything, mock.AnythingOfType("*entities.Adoption")).Return(nil)
+	var escalation *entities.Task
+	m.tasks.On("Create", mock.Anything, mock.AnythingOfType("*entities.Task")).Run(func(args mock.Arguments) {
+		escalation = args.Get(1).(*entities.Task)
+	}).Return(nil)
+
+	require.NoError(t, uc.ProcessCompliance(ctx))
+
+	// Two weeks before the deadline the adopter gets an email
+	assert.Equal(t, entities.SterilizationStatusPending, upcoming.Sterilization.Status)
+	require.Len(t, upcoming.Sterilization.Reminders, 1)
+	assert.Equal(t, entities.SterilizationReminderUpcoming, upcoming.Sterilization.Reminders[0].Kind)
+	assert.Equal(t, entities.TemplateTypeEmail, upcoming.Sterilization.Reminders[0].Channel)
+
+	// Past the deadline: an SMS to an adopter without email and a task for staff
+	assert.Equal(t, entities.SterilizationStatusOverdue, overdue.Sterilization.Status)
+	require.Len(t, overdue.Sterilization.Reminders, 1)
+	assert.Equal(t, entities.SterilizationReminderOverdue, overdue.Sterilization.Reminders[0].Kind)
+	assert.Equal(t, entities.TemplateTypeSMS, overdue.Sterilization.Reminders[0].Channel)
+	require.NotNil(t, escalation)
+	assert.Equal(t, escalation.ID, *overdue.Sterilization.EscalationTaskID)
+	assert.Equal(t, "Follow up overdue spay/neuter of Burek", escalation.Title)
+	assert.Equal(t, entities.TaskPriorityHigh, escalation.Priority)
+	assert.Equal(t, overdue.ProcessedBy, *escalation.AssignedTo)
+	assert.Equal(t, overdue.ID, *escalation.RelatedEntityID)
+	assert.Contains(t, escalation.Tags, "sterilization:"+overdue.ID.Hex())
+	assert.Contains(t, escalation.Description, "Jan, +48500300400")
+
+	// A spay/neuter visit recorded for the animal closes the requirement
+	assert.Equal(t, entities.SterilizationStatusCompleted, visited.Sterilization.Status)
+	assert.Equal(t, visit.ID, *visited.Sterilization.VisitID)
+	assert.True(t, visited.Sterilization.IsOnTime())
+	assert.True(t, visitedAnimal.Medical.Sterilized)
+	assert.Empty(t, visited.Sterilization.Reminders)
+
+	assert.Equal(t, entities.SterilizationStatusWaived, returned.Sterilization.Status)
+	assert.Equal(t, "adoption returned", returned.Sterilization.WaiverReason)
+
+	require.Len(t, m.messenger.Sent, 2)
+	assert.Equal(t, "anna@example.com", m.messenger.Sent[0].RecipientEmail)
+	assert.Equal(t, "Reminder: Rex is due to be spayed/neutered by "+days(10).Format("2006-01-02"), m.messenger.Sent[0].Subject)
+	assert.Equal(t, upcoming.ID, *m.messenger.Sent[0].RelatedID)
+	assert.Equal(t, "+48500300400", m.messenger.Sent[1].RecipientPhone)
+	m.tasks.AssertNumberOfCalls(t, "Create", 1)
+
+	// The next run sends nothing new and keeps the single escalation task
+	m.tasks.On("FindByID", mock.Anything, escalation.ID).Return(escalation, nil)
+	require.NoError(t, uc.ProcessCompliance(ctx))
+	assert.Len(t, m.messenger.Sent, 2)
+	m.tasks.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestSterilizationUseCase_RecordCertificate(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newSterilizationUseCase()
+	userID := primitive.NewObjectID()
+	now := time.Now()
+
+	adoption, animal := adoptedIntact(m, "Mruczek", now.AddDate(0, 0, -90), now.AddDate(0, 0, -30), entities.ApplicantInfo{Email: "kot@example.com"})
+	task := entities.NewTask("Follow up overdue spay/neuter of Mruczek", entities.TaskCategoryAdoption, entities.TaskPriorityHigh, userID)
+	adoption.Sterilization.Status = entities.SterilizationStatusOverdue
+	adoption.Sterilization.EscalationTaskID = &task.ID
+	certificate := &entities.Document{ID: primitive.NewObjectID(), Type: entities.DocumentTypeCertificate, ScanStatus: entities.DocumentScanStatusClean}
+
+	m.adoptions.On("FindByID", ctx, adoption.ID).Return(adoption, nil)
+	m.adoptions.On("Update", ctx, adoption).Return(nil)
+	m.documents.On("FindByID", ctx, certificate.ID).Return(certificate, nil)
+	m.documents.On("Update", ctx, certificate).Return(nil)
+	m.tasks.On("FindByID", ctx, task.ID).Return(task, nil)
+	m.tasks.On("Update", ctx, task).Return(nil)
+	m.animals.On("Update", ctx, animal).Return(nil)
+
+	_, err := uc.RecordSterilization(ctx, adoption.ID, &RecordSterilizationRequest{CertificateID: certificate.ID.Hex()}, userID)
+	require.Error(t, err, "a certificate needs the procedure date")
+
+	procedure := now.AddDate(0, 0, -10)
+	result, err := uc.RecordSterilization(ctx, adoption.ID, &RecordSterilizationRequest{CertificateID: certificate.ID.Hex(), ProcedureDate: &procedure}, userID)
+	require.NoError(t, err)
+
+	requirement := result.Sterilization
+	assert.Equal(t, entities.SterilizationStatusCompleted, requirement.Status)
+	assert.Equal(t, certificate.ID, *requirement.CertificateID)
+	assert.Equal(t, userID, *requirement.VerifiedBy)
+	assert.False(t, requirement.IsOnTime(), "done after the deadline")
+	assert.Equal(t, adoption.ID, *certificate.RelatedEntityID, "the certificate is filed with the adoption")
+	assert.True(t, animal.Medical.Sterilized)
+	assert.Equal(t, entities.TaskStatusCompleted, task.Status)
+
+	_, err = uc.RecordSterilization(ctx, adoption.ID, &RecordSterilizationRequest{CertificateID: certificate.ID.Hex(), ProcedureDate: &procedure}, userID)
+	assert.Error(t, err, "the sterilization is recorded only once")
+}
+
+func TestSterilizationUseCase_RecordRejectsOtherVisits(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newSterilizationUseCase()
+	now := time.Now()
+
+	adoption, animal := adoptedIntact(m, "Rex", now.AddDate(0, 0, -10), now.AddDate(0, 0, 50), entities.ApplicantInfo{})
+	checkup := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: animal.ID, VisitType: entities.VisitTypeCheckup, Status: entities.VisitStatusCompleted, VisitDate: now}
+	otherAnimal := &entities.VeterinaryVisit{ID: primitive.NewObjectID(), AnimalID: primitive.NewObjectID(), VisitType: entities.VisitTypeSpayNeuter, Status: entities.VisitStatusCompleted, VisitDate: now}
+	m.adoptions.On("FindByID", ctx, adoption.ID).Return(adoption, nil)
+	m.visits.On("FindByID", ctx, checkup.ID).Return(checkup, nil)
+	m.visits.On("FindByID", ctx, otherAnimal.ID).Return(otherAnimal, nil)
+
+	_, err := uc.RecordSterilization(ctx, adoption.ID, &RecordSterilizationRequest{VisitID: checkup.ID.Hex()}, primitive.NewObjectID())
+	assert.ErrorContains(t, err, "not a spay/neuter visit")
+	_, err = uc.RecordSterilization(ctx, adoption.ID, &RecordSterilizationRequest{VisitID: otherAnimal.ID.Hex()}, primitive.NewObjectID())
+	assert.ErrorContains(t, err, "another animal")
+	assert.Equal(t, entities.SterilizationStatusPending, adoption.Sterilization.Status)
+	m.adoptions.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestSterilizationUseCase_ComplianceReport(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newSterilizationUseCase()
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, n) }
+	at := func(n int) *time.Time { t := days(n); return &t }
+	certificateID, visitID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	onTime, _ := adoptedIntact(m, "Rex", days(-100), days(-40), entities.ApplicantInfo{FirstName: "Anna", LastName: "Nowak"})
+	onTime.Sterilization.Complete(*at(-60), nil, &certificateID, primitive.NewObjectID())
+	late, _ := adoptedIntact(m, "Burek", days(-100), days(-40), entities.ApplicantInfo{})
+	late.Sterilization.Complete(*at(-30), &visitID, nil, primitive.NewObjectID())
+	// Still marked pending, but the deadline has passed since the last job run
+	overdue, _ := adoptedIntact(m, "Azor", days(-70), days(-5), entities.ApplicantInfo{})
+	pending, _ := adoptedIntact(m, "Luna", days(-20), days(40), entities.ApplicantInfo{})
+	waived, _ := adoptedIntact(m, "Mruczek", days(-90), days(-30), entities.ApplicantInfo{})
+	waived.Sterilization.Waive("medical exemption", primitive.NewObjectID())
+
+	from, to := days(-365), now
+	m.adoptions.On("List", ctx, mock.MatchedBy(func(filter repositories.AdoptionFilter) bool {
+		return len(filter.SterilizationStatuses) == 4 && *filter.FromDate == from && *filter.ToDate == to
+	})).Return([]*entities.Adoption{onTime, late, overdue, pending, waived}, int64(5), nil)
+
+	report, err := uc.GetComplianceReport(ctx, from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 2, report.Completed)
+	assert.Equal(t, 1, report.OnTime)
+	assert.Equal(t, 1, report.Overdue)
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, 1, report.Waived)
+	assert.Equal(t, 3, report.Due)
+	assert.Equal(t, 66.7, report.ComplianceRate)
+	assert.Equal(t, 33.3, report.OnTimeRate)
+	assert.Equal(t, 55.0, report.AverageDaysToProcedure)
+
+	require.Len(t, report.Adoptions, 5)
+	assert.Equal(t, "Rex", report.Adoptions[0].AnimalName)
+	assert.Equal(t, "Anna Nowak", report.Adoptions[0].AdopterName)
+	assert.Equal(t, "certificate", report.Adoptions[0].Evidence)
+	assert.Equal(t, "visit", report.Adoptions[1].Evidence)
+	assert.Equal(t, entities.SterilizationStatusOverdue, report.Adoptions[2].Status)
+	assert.Equal(t, 5, report.Adoptions[2].DaysOverdue)
+	assert.Equal(t, "medical exemption", report.Adoptions[4].WaiverReason)
+}
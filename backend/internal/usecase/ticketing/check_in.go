@@ -0,0 +1,212 @@
+package ticketing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Results of the check-ins synced from an offline scanner
+const (
+	CheckInResultCheckedIn = "checked_in"
+	CheckInResultDuplicate = "duplicate"
+	CheckInResultRejected  = "rejected"
+)
+
+// CheckInRequest is a scanned ticket. Scanners that were offline send the time of the
+// scan along with it.
+type CheckInRequest struct {
+	Code        string     `json:"code" validate:"required"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+// SyncCheckInsRequest is the queue of tickets scanned while a scanner was offline
+type SyncCheckInsRequest struct {
+	CheckIns []CheckInRequest `json:"check_ins" validate:"required,min=1,max=1000,dive"`
+}
+
+// CheckInResult is the outcome of a synced check-in
+type CheckInResult struct {
+	Code         string              `json:"code"`
+	Result       string              `json:"result"`
+	AttendanceID *primitive.ObjectID `json:"attendance_id,omitempty"`
+	Name         string              `json:"name,omitempty"`
+	Seats        int                 `json:"seats,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// SyncCheckInsResponse reports the synced check-ins and the attendance afterwards
+type SyncCheckInsResponse struct {
+	Results       []CheckInResult `json:"results"`
+	CheckedIn     int             `json:"checked_in"`
+	Duplicates    int             `json:"duplicates"`
+	Rejected      int             `json:"rejected"`
+	AttendeeCount int             `json:"attendee_count"`
+}
+
+// CheckInListEntry is a registration on the attendee list scanners download to check
+// tickets in while offline. The ticket hash lets the scanner match a scanned code
+// without the list holding the codes themselves.
+type CheckInListEntry struct {
+	AttendanceID primitive.ObjectID        `json:"attendance_id"`
+	TicketHash   string                    `json:"ticket_hash"`
+	Name         string                    `json:"name"`
+	Email        string                    `json:"email,omitempty"`
+	Seats        int                       `json:"seats"`
+	Status       entities.AttendanceStatus `json:"status"`
+	Paid         bool                      `json:"paid"`
+	CheckedInAt  *time.Time                `json:"checked_in_at,omitempty"`
+}
+
+// CheckIn checks in the ticket scanned at the door of an event and updates the
+// attendance of the event
+func (uc *TicketUseCase) CheckIn(ctx context.Context, eventID primitive.ObjectID, req *CheckInRequest, userID primitive.ObjectID) (*entities.EventAttendance, error) {
+	attendance, err := uc.checkIn(ctx, eventID, req, userID)
+	if err != nil {
+		return nil, err
+	}
+	uc.refreshAttendance(ctx, eventID)
+	return attendance, nil
+}
+
+// SyncCheckIns checks in the tickets scanned while a scanner was offline. Every ticket
+// gets its own result, so one bad code does not hold up the rest of the queue; tickets
+// another scanner checked in already are reported as duplicates.
+func (uc *TicketUseCase) SyncCheckIns(ctx context.Context, eventID primitive.ObjectID, req *SyncCheckInsRequest, userID primitive.ObjectID) (*SyncCheckInsResponse, error) {
+	if _, err := uc.eventRepo.FindByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	response := &SyncCheckInsResponse{Results: make([]CheckInResult, 0, len(req.CheckIns))}
+	for i := range req.CheckIns {
+		checkIn := req.CheckIns[i]
+		result := CheckInResult{Code: checkIn.Code, Result: CheckInResultCheckedIn}
+
+		attendance, err := uc.checkIn(ctx, eventID, &checkIn, userID)
+		switch {
+		case err == nil:
+			response.CheckedIn++
+		case isConflict(err):
+			result.Result = CheckInResultDuplicate
+			response.Duplicates++
+		default:
+			result.Result = CheckInResultRejected
+			result.Error = err.Error()
+			response.Rejected++
+		}
+		if attendance != nil {
+			result.AttendanceID = &attendance.ID
+			result.Name = attendance.GuestName
+			result.Seats = attendance.Seats()
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	response.AttendeeCount = uc.refreshAttendance(ctx, eventID)
+	return response, nil
+}
+
+// CheckInList returns the registrations holding seats at an event for scanners to
+// download before going offline
+func (uc *TicketUseCase) CheckInList(ctx context.Context, eventID primitive.ObjectID) ([]*CheckInListEntry, error) {
+	if _, err := uc.eventRepo.FindByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+	attendances, err := uc.attendanceRepo.GetAttendanceByEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*CheckInListEntry{}
+	for _, attendance := range attendances {
+		if !attendance.HoldsSeats() {
+			continue
+		}
+		list = append(list, &CheckInListEntry{
+			AttendanceID: attendance.ID,
+			TicketHash:   entities.HashToken(uc.signer.Sign(attendance.ID)),
+			Name:         attendance.GuestName,
+			Email:        attendance.GuestEmail,
+			Seats:        attendance.Seats(),
+			Status:       attendance.Status,
+			Paid:         attendance.IsPaid(),
+			CheckedInAt:  attendance.CheckInTime,
+		})
+	}
+	return list, nil
+}
+
+// checkIn validates a scanned ticket and checks it in. The registration is returned with
+// the errors about it, so the scanner can show whose ticket was refused.
+func (uc *TicketUseCase) checkIn(ctx context.Context, eventID primitive.ObjectID, req *CheckInRequest, userID primitive.ObjectID) (*entities.EventAttendance, error) {
+	id, err := uc.signer.Verify(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	attendance, err := uc.attendanceRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewBadRequest("unknown ticket")
+		}
+		return nil, err
+	}
+	if attendance.EventID != eventID {
+		return nil, errors.NewBadRequest("the ticket is for another event")
+	}
+
+	switch attendance.Status {
+	case entities.AttendanceStatusAttended:
+		checkedIn := "already"
+		if attendance.CheckInTime != nil {
+			checkedIn = "at " + attendance.CheckInTime.Format("15:04:05")
+		}
+		return attendance, errors.NewConflict(fmt.Sprintf("the ticket was checked in %s", checkedIn))
+	case entities.AttendanceStatusCancelled:
+		return attendance, errors.NewBadRequest("the registration was cancelled")
+	case entities.AttendanceStatusWaitlisted:
+		return attendance, errors.NewBadRequest("the registration is on the waitlist")
+	case entities.AttendanceStatusNoShow:
+		return attendance, errors.NewBadRequest("the registration was marked as a no-show")
+	}
+	if !attendance.IsPaid() {
+		return attendance, errors.New(http.StatusPaymentRequired, "the ticket is not paid")
+	}
+
+	attendance.CheckIn()
+	if req.CheckedInAt != nil && !req.CheckedInAt.IsZero() && req.CheckedInAt.Before(time.Now()) {
+		scanned := *req.CheckedInAt
+		attendance.CheckInTime = &scanned
+	}
+	attendance.UpdatedBy = userID
+	attendance.UpdatedAt = time.Now()
+	if err := uc.attendanceRepo.Update(ctx, attendance); err != nil {
+		return nil, err
+	}
+	return attendance, nil
+}
+
+// refreshAttendance recounts the checked-in seats into the event statistics and
+// returns the count
+func (uc *TicketUseCase) refreshAttendance(ctx context.Context, eventID primitive.ObjectID) int {
+	seats, err := uc.attendanceRepo.CountCheckedInSeats(ctx, eventID)
+	if err != nil {
+		return 0
+	}
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return seats
+	}
+	_ = uc.eventRepo.UpdateEventStatistics(ctx, eventID, seats, event.VolunteerCount, event.FundsRaised, event.AnimalsAdopted)
+	return seats
+}
+
+// isConflict reports whether a check-in was refused because the ticket was checked in before
+func isConflict(err error) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == http.StatusConflict
+}
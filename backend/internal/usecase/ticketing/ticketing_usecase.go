@@ -0,0 +1,663 @@
+package ticketing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/payment"
+	"github.com/sainaif/animalsys/backend/pkg/qrcode"
+	"github.com/sainaif/animalsys/backend/pkg/security"
+	"github.com/sainaif/animalsys/backend/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// referencePrefix marks the payments of event registrations at the payment gateway
+const referencePrefix = "event_attendance:"
+
+// minPaymentWindow is the shortest time a checkout can stay open at the providers
+const minPaymentWindow = 30 * time.Minute
+
+// qrScale is the size of a QR code module in pixels
+const qrScale = 8
+
+// paymentAttempts is how many times a payment is applied to a registration that keeps
+// changing before the notification is left for the provider to deliver again
+const paymentAttempts = 3
+
+// Registrar takes and releases the seats of event registrations
+type Registrar interface {
+	RegisterForEvent(ctx context.Context, eventID primitive.ObjectID, req entities.EventAttendance, userID primitive.ObjectID) (*entities.EventAttendance, error)
+	CancelRegistration(ctx context.Context, eventID, attendanceID, userID primitive.ObjectID) (*entities.EventAttendance, error)
+}
+
+// Messenger queues email messages to attendees
+type Messenger interface {
+	CreateCommunication(ctx context.Context, communication *entities.Communication, userID primitive.ObjectID) error
+}
+
+// TicketUseCase sells tickets for public events: it registers the public, takes
+// payment through the payment gateway, emails tickets with a signed QR code and
+// checks the tickets in at the door
+type TicketUseCase struct {
+	eventRepo      repositories.EventRepository
+	attendanceRepo repositories.EventAttendanceRepository
+	auditLogRepo   repositories.AuditLogRepository
+	storageService *storage.StorageService
+	registrar      Registrar
+	gateway        payment.Gateway
+	signer         *security.TicketSigner
+	messenger      Messenger
+	ticketURL      string // the ticket page of the web app; the ticket code is appended
+	currency       string // of fees without their own currency
+	paymentWindow  time.Duration
+}
+
+// NewTicketUseCase creates a new ticket use case
+func NewTicketUseCase(
+	eventRepo repositories.EventRepository,
+	attendanceRepo repositories.EventAttendanceRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	storageService *storage.StorageService,
+	registrar Registrar,
+	gateway payment.Gateway,
+	signer *security.TicketSigner,
+	messenger Messenger,
+	ticketURL string,
+	currency string,
+	paymentWindow time.Duration,
+) *TicketUseCase {
+	if paymentWindow < minPaymentWindow {
+		paymentWindow = minPaymentWindow
+	}
+	return &TicketUseCase{
+		eventRepo:      eventRepo,
+		attendanceRepo: attendanceRepo,
+		auditLogRepo:   auditLogRepo,
+		storageService: storageService,
+		registrar:      registrar,
+		gateway:        gateway,
+		signer:         signer,
+		messenger:      messenger,
+		ticketURL:      strings.TrimRight(ticketURL, "/"),
+		currency:       currency,
+		paymentWindow:  paymentWindow,
+	}
+}
+
+// PublicEvent is what the public website shows of an event
+type PublicEvent struct {
+	ID                   primitive.ObjectID        `json:"id"`
+	Name                 entities.MultilingualName `json:"name"`
+	Description          entities.MultilingualName `json:"description"`
+	Type                 entities.EventType        `json:"type"`
+	Status               entities.EventStatus      `json:"status"`
+	StartDate            time.Time                 `json:"start_date"`
+	EndDate              *time.Time                `json:"end_date,omitempty"`
+	Location             entities.EventLocation    `json:"location"`
+	VirtualLink          string                    `json:"virtual_link,omitempty"`
+	ImageURL             string                    `json:"image_url,omitempty"`
+	Fee                  float64                   `json:"fee"` // Per seat
+	Currency             string                    `json:"currency"`
+	SeatsLeft            *int                      `json:"seats_left,omitempty"` // Unset for events without a capacity
+	Waitlist             bool                      `json:"waitlist"`
+	RegistrationDeadline *time.Time                `json:"registration_deadline,omitempty"`
+	RegistrationOpen     bool                      `json:"registration_open"`
+}
+
+// PublicRegistrationRequest is a registration from the public website
+type PublicRegistrationRequest struct {
+	Name                string `json:"name" validate:"required,max=200"`
+	Email               string `json:"email" validate:"required,email"`
+	Phone               string `json:"phone,omitempty" validate:"max=30"`
+	NumberOfGuests      int    `json:"number_of_guests" validate:"min=0,max=20"`
+	SpecialRequirements string `json:"special_requirements,omitempty" validate:"max=1000"`
+	DietaryRestrictions string `json:"dietary_restrictions,omitempty" validate:"max=1000"`
+}
+
+// PublicRegistration tells the registrant what happens next: pay at the checkout,
+// wait for a seat, or use the ticket
+type PublicRegistration struct {
+	RegistrationID primitive.ObjectID        `json:"registration_id"`
+	Status         entities.AttendanceStatus `json:"status"`
+	Seats          int                       `json:"seats"`
+	Amount         float64                   `json:"amount"`
+	Currency       string                    `json:"currency"`
+	CheckoutURL    string                    `json:"checkout_url,omitempty"`
+	PaymentDueAt   *time.Time                `json:"payment_due_at,omitempty"`
+	TicketURL      string                    `json:"ticket_url"`
+}
+
+// TicketView is the ticket page of an attendee
+type TicketView struct {
+	EventName   string                    `json:"event_name"`
+	StartDate   time.Time                 `json:"start_date"`
+	Location    entities.EventLocation    `json:"location"`
+	Name        string                    `json:"name"`
+	Seats       int                       `json:"seats"`
+	Status      entities.AttendanceStatus `json:"status"`
+	Paid        bool                      `json:"paid"`
+	Amount      float64                   `json:"amount"`
+	Currency    string                    `json:"currency"`
+	CheckedInAt *time.Time                `json:"checked_in_at,omitempty"`
+}
+
+// ListPublicEvents returns the upcoming public events
+func (uc *TicketUseCase) ListPublicEvents(ctx context.Context) ([]*PublicEvent, error) {
+	events, err := uc.eventRepo.GetPublicEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := []*PublicEvent{}
+	for _, event := range events {
+		if !listed(event) || event.EndTime().Before(now) {
+			continue
+		}
+		result = append(result, uc.publicEvent(event, now))
+	}
+	return result, nil
+}
+
+// GetPublicEvent returns a public event
+func (uc *TicketUseCase) GetPublicEvent(ctx context.Context, eventID primitive.ObjectID) (*PublicEvent, error) {
+	event, err := uc.findPublicEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.publicEvent(event, time.Now()), nil
+}
+
+// Register registers a member of the public for a public event. Free registrations
+// get their ticket by email right away; paid ones get a checkout link and the ticket
+// once the payment arrives. Registrations that are not paid in time are cancelled.
+func (uc *TicketUseCase) Register(ctx context.Context, eventID primitive.ObjectID, req *PublicRegistrationRequest) (*PublicRegistration, error) {
+	event, err := uc.findPublicEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registrations from the website are on behalf of the staff member who created the event
+	attendance, err := uc.registrar.RegisterForEvent(ctx, eventID, entities.EventAttendance{
+		AttendeeType:        entities.AttendeeTypePublic,
+		GuestName:           strings.TrimSpace(req.Name),
+		GuestEmail:          strings.TrimSpace(req.Email),
+		GuestPhone:          strings.TrimSpace(req.Phone),
+		NumberOfGuests:      req.NumberOfGuests,
+		SpecialRequirements: strings.TrimSpace(req.SpecialRequirements),
+		DietaryRestrictions: strings.TrimSpace(req.DietaryRestrictions),
+	}, event.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PublicRegistration{
+		RegistrationID: attendance.ID,
+		Status:         attendance.Status,
+		Seats:          attendance.Seats(),
+		Amount:         attendance.RegistrationFee,
+		Currency:       uc.eventCurrency(event),
+		TicketURL:      uc.ticketLink(attendance.ID),
+	}
+
+	switch {
+	case attendance.Status == entities.AttendanceStatusWaitlisted:
+		uc.sendWaitlisted(ctx, event, attendance)
+	case !attendance.IsPaid():
+		checkout, err := uc.openCheckout(ctx, event, attendance)
+		if err != nil {
+			// Free the seats; the registrant can try again
+			_, _ = uc.registrar.CancelRegistration(ctx, eventID, attendance.ID, event.CreatedBy)
+			return nil, err
+		}
+		result.CheckoutURL = checkout.URL
+		result.PaymentDueAt = attendance.PaymentDueAt
+	default:
+		uc.sendTicket(ctx, event, attendance, event.CreatedBy)
+	}
+	return result, nil
+}
+
+// Checkout opens a new checkout for an unpaid ticket, e.g. after the registration
+// moved off the waitlist or the first checkout was abandoned
+func (uc *TicketUseCase) Checkout(ctx context.Context, code string) (*payment.Checkout, error) {
+	event, attendance, err := uc.openTicket(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if attendance.IsPaid() {
+		return nil, errors.NewConflict("the ticket is already paid")
+	}
+	if !attendance.HoldsSeats() {
+		return nil, errors.NewBadRequest("the registration has no seats to pay for")
+	}
+	return uc.openCheckout(ctx, event, attendance)
+}
+
+// ViewTicket returns the ticket of a code
+func (uc *TicketUseCase) ViewTicket(ctx context.Context, code string) (*TicketView, error) {
+	event, attendance, err := uc.openTicket(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return &TicketView{
+		EventName:   event.DisplayName(),
+		StartDate:   event.StartDate,
+		Location:    event.Location,
+		Name:        attendance.GuestName,
+		Seats:       attendance.Seats(),
+		Status:      attendance.Status,
+		Paid:        attendance.IsPaid(),
+		Amount:      attendance.RegistrationFee,
+		Currency:    uc.eventCurrency(event),
+		CheckedInAt: attendance.CheckInTime,
+	}, nil
+}
+
+// TicketQRCode returns the QR code of a ticket code as a PNG image
+func (uc *TicketUseCase) TicketQRCode(ctx context.Context, code string) ([]byte, error) {
+	if _, _, err := uc.openTicket(ctx, code); err != nil {
+		return nil, err
+	}
+	return qrPNG(code)
+}
+
+// HandlePaymentNotification records the outcome of a ticket checkout reported by the
+// payment gateway. Paid tickets are confirmed and emailed; failed and expired checkouts
+// cancel the registration. Notifications about other payments are ignored.
+func (uc *TicketUseCase) HandlePaymentNotification(ctx context.Context, payload []byte, header map[string][]string) error {
+	notification, err := uc.gateway.ParseNotification(payload, header)
+	if err != nil || notification == nil {
+		return err
+	}
+	if !strings.HasPrefix(notification.Reference, referencePrefix) {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(strings.TrimPrefix(notification.Reference, referencePrefix))
+	if err != nil {
+		return nil
+	}
+
+	attendance, err := uc.attendanceRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if attendance.IsPaid() {
+		return nil // Notifications are delivered at least once
+	}
+	event, err := uc.eventRepo.FindByID(ctx, attendance.EventID)
+	if err != nil {
+		return err
+	}
+
+	if notification.Status != payment.StatusPaid {
+		// A newer checkout of the same ticket may still be paid
+		if notification.SessionID != attendance.CheckoutSessionID || !attendance.HoldsSeats() {
+			return nil
+		}
+		_, err := uc.registrar.CancelRegistration(ctx, event.ID, attendance.ID, event.CreatedBy)
+		return err
+	}
+	if math.Round(notification.Amount*100) != math.Round(attendance.RegistrationFee*100) {
+		_ = uc.auditLogRepo.Create(ctx,
+			entities.NewAuditLog(event.CreatedBy, entities.ActionUpdate, "event_attendance", "", "ticket payment rejected: the amount differs from the fee").
+				WithEntityID(attendance.ID).
+				WithChanges(map[string]interface{}{
+					"event_id":       event.ID,
+					"amount":         notification.Amount,
+					"fee":            attendance.RegistrationFee,
+					"transaction_id": notification.TransactionID,
+				}))
+		return errors.NewBadRequest("the amount paid does not match the ticket fee")
+	}
+	return uc.recordPayment(ctx, event, attendance, notification)
+}
+
+// ExpireUnpaidRegistrations is the scheduler job cancelling online registrations that
+// were not paid in time, which gives their seats to the waitlist
+func (uc *TicketUseCase) ExpireUnpaidRegistrations(ctx context.Context) error {
+	now := time.Now()
+	unpaid, _, err := uc.attendanceRepo.List(ctx, &repositories.EventAttendanceFilter{
+		Status:           string(entities.AttendanceStatusRegistered),
+		PaymentStatus:    "pending",
+		PaymentDueBefore: &now,
+		SortBy:           "payment_due_at",
+		SortOrder:        "asc",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, attendance := range unpaid {
+		event, err := uc.eventRepo.FindByID(ctx, attendance.EventID)
+		if err != nil {
+			continue
+		}
+		_, _ = uc.registrar.CancelRegistration(ctx, event.ID, attendance.ID, event.CreatedBy)
+	}
+	return nil
+}
+
+// SendTicket emails the ticket of a paid registration to the attendee again
+func (uc *TicketUseCase) SendTicket(ctx context.Context, eventID, attendanceID, userID primitive.ObjectID) (*entities.EventAttendance, error) {
+	attendance, err := uc.attendanceRepo.FindByID(ctx, attendanceID)
+	if err != nil {
+		return nil, err
+	}
+	if attendance.EventID != eventID {
+		return nil, errors.ErrNotFound
+	}
+	if !attendance.HoldsSeats() {
+		return nil, errors.NewBadRequest("the registration has no seats")
+	}
+	if !attendance.IsPaid() {
+		return nil, errors.NewBadRequest("the registration is not paid")
+	}
+	if attendance.GuestEmail == "" {
+		return nil, errors.NewBadRequest("the registration has no email address")
+	}
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !uc.sendTicket(ctx, event, attendance, userID) {
+		return nil, errors.NewInternalServer("the ticket could not be sent")
+	}
+	return attendance, nil
+}
+
+// recordPayment marks a ticket paid and emails it. A payment arriving after the unpaid
+// registration was cancelled gets its seats back if they are still free; otherwise the
+// registration stays cancelled and staff are left a note to refund it. When the
+// registration changes while the payment is recorded, it is read again and the payment
+// applied to what it became.
+func (uc *TicketUseCase) recordPayment(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance, notification *payment.Notification) error {
+	for attempt := 1; ; attempt++ {
+		saved, err := uc.applyPayment(ctx, event, attendance, notification)
+		if err != nil {
+			return err
+		}
+		if saved {
+			break
+		}
+		if attempt == paymentAttempts {
+			return errors.NewConflict("the registration keeps changing, the payment could not be recorded")
+		}
+		if attendance, err = uc.attendanceRepo.FindByID(ctx, attendance.ID); err != nil {
+			return err
+		}
+		if attendance.IsPaid() {
+			return nil // Recorded by a concurrent delivery of the same notification
+		}
+	}
+
+	_ = uc.auditLogRepo.Create(ctx,
+		entities.NewAuditLog(event.CreatedBy, entities.ActionUpdate, "event_attendance", "", "ticket paid online").
+			WithEntityID(attendance.ID).
+			WithChanges(map[string]interface{}{
+				"event_id":       event.ID,
+				"amount":         notification.Amount,
+				"transaction_id": notification.TransactionID,
+				"status":         attendance.Status,
+			}))
+
+	if attendance.Status == entities.AttendanceStatusConfirmed {
+		uc.sendTicket(ctx, event, attendance, event.CreatedBy)
+	}
+	return nil
+}
+
+// applyPayment marks the registration paid and saves it only if it still has the status
+// it was read with, so a cancellation made in between is not overwritten. The seats
+// reserved to reinstate a cancelled registration are given back when it is not saved.
+func (uc *TicketUseCase) applyPayment(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance, notification *payment.Notification) (bool, error) {
+	previous := attendance.Status
+	attendance.MarkPaid(uc.gateway.Name(), notification.TransactionID)
+	attendance.PaymentDueAt = nil
+	attendance.UpdatedAt = time.Now()
+
+	reinstated := false
+	if attendance.Status == entities.AttendanceStatusCancelled {
+		reserved, err := uc.eventRepo.ReserveSeats(ctx, event.ID, attendance.Seats())
+		if err != nil {
+			return false, err
+		}
+		if reserved {
+			attendance.CancellationDate = nil
+			reinstated = true
+		} else {
+			attendance.Notes = strings.TrimSpace(attendance.Notes + "\nPaid after the registration was cancelled for non-payment; the event was full by then, so the payment must be refunded.")
+		}
+	}
+	if attendance.HoldsSeats() || reinstated {
+		attendance.Confirm()
+	}
+
+	saved, err := uc.attendanceRepo.UpdateIfStatus(ctx, attendance, previous)
+	if (err != nil || !saved) && reinstated {
+		if releaseErr := uc.eventRepo.ReleaseSeats(ctx, event.ID, attendance.Seats()); releaseErr != nil {
+			log.Error().Err(releaseErr).Str("attendance_id", attendance.ID.Hex()).
+				Msg("failed to release the seats of a registration whose payment was not recorded")
+		}
+	}
+	return saved, err
+}
+
+// openCheckout opens a checkout for the fee of a registration and gives it until the
+// end of the payment window
+func (uc *TicketUseCase) openCheckout(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance) (*payment.Checkout, error) {
+	now := time.Now()
+	dueAt := now.Add(uc.paymentWindow)
+	link := uc.ticketLink(attendance.ID)
+
+	description := fmt.Sprintf("%s: 1 ticket", event.DisplayName())
+	if seats := attendance.Seats(); seats > 1 {
+		description = fmt.Sprintf("%s: %d tickets", event.DisplayName(), seats)
+	}
+	checkout, err := uc.gateway.CreateCheckout(ctx, payment.CheckoutRequest{
+		Reference:     referencePrefix + attendance.ID.Hex(),
+		Description:   description,
+		Amount:        attendance.RegistrationFee,
+		Currency:      uc.eventCurrency(event),
+		CustomerEmail: attendance.GuestEmail,
+		SuccessURL:    link,
+		CancelURL:     link,
+		ExpiresAt:     dueAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attendance.CheckoutSessionID = checkout.SessionID
+	attendance.PaymentDueAt = &dueAt
+	attendance.UpdatedAt = now
+	if err := uc.attendanceRepo.Update(ctx, attendance); err != nil {
+		return nil, err
+	}
+	return checkout, nil
+}
+
+// sendTicket emails the ticket with its QR code to the attendee and reports whether
+// it was queued
+func (uc *TicketUseCase) sendTicket(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance, userID primitive.ObjectID) bool {
+	if uc.messenger == nil || attendance.GuestEmail == "" {
+		return false
+	}
+
+	code := uc.signer.Sign(attendance.ID)
+	title := event.DisplayName()
+	seats := "1 seat"
+	if attendance.Seats() > 1 {
+		seats = fmt.Sprintf("%d seats", attendance.Seats())
+	}
+	body := fmt.Sprintf("%s,\n\nHere is your ticket for %s on %s (%s).\n\nPlease show the attached QR code at the entrance, or open your ticket at %s\n\nTicket code: %s",
+		greeting(attendance.GuestName), title, event.StartDate.Format("2006-01-02 15:04"), seats, uc.ticketLink(attendance.ID), code)
+	if where := event.Location.String(); where != "" {
+		body += "\nLocation: " + where
+	}
+
+	communication := entities.NewCommunication(entities.TemplateTypeEmail, entities.TemplateCategoryEvent, attendance.GuestEmail, "Your ticket for "+title, body, userID)
+	communication.RecipientName = attendance.GuestName
+	communication.RelatedType = "event"
+	communication.RelatedID = &event.ID
+	communication.Metadata["attendance_id"] = attendance.ID.Hex()
+	if attachment := uc.storeQRCode(ctx, event, attendance, code); attachment != nil {
+		communication.Attachments = append(communication.Attachments, *attachment)
+	}
+
+	if err := uc.messenger.CreateCommunication(ctx, communication, userID); err != nil {
+		return false
+	}
+	now := time.Now()
+	attendance.TicketSentAt = &now
+	_ = uc.attendanceRepo.Update(ctx, attendance)
+	return true
+}
+
+// sendWaitlisted tells a registrant they are on the waitlist
+func (uc *TicketUseCase) sendWaitlisted(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance) {
+	if uc.messenger == nil || attendance.GuestEmail == "" {
+		return
+	}
+
+	title := event.DisplayName()
+	body := fmt.Sprintf("%s,\n\n%s is full at the moment, so your registration is on the waitlist. We will let you know as soon as a seat opens up.\n\nYou can check your registration at %s",
+		greeting(attendance.GuestName), title, uc.ticketLink(attendance.ID))
+	communication := entities.NewCommunication(entities.TemplateTypeEmail, entities.TemplateCategoryEvent, attendance.GuestEmail, "You're on the waitlist for "+title, body, event.CreatedBy)
+	communication.RecipientName = attendance.GuestName
+	communication.RelatedType = "event"
+	communication.RelatedID = &event.ID
+	communication.Metadata["attendance_id"] = attendance.ID.Hex()
+	_ = uc.messenger.CreateCommunication(ctx, communication, event.CreatedBy)
+}
+
+// storeQRCode uploads the QR code image of a ticket to attach it to the ticket email
+func (uc *TicketUseCase) storeQRCode(ctx context.Context, event *entities.Event, attendance *entities.EventAttendance, code string) *entities.CommunicationAttachment {
+	if uc.storageService == nil {
+		return nil
+	}
+	image, err := qrPNG(code)
+	if err != nil {
+		return nil
+	}
+	url, err := uc.storageService.Upload(ctx, bytes.NewReader(image), int64(len(image)), "tickets/"+event.ID.Hex(), attendance.ID.Hex()+".png", "image/png")
+	if err != nil {
+		return nil
+	}
+	return &entities.CommunicationAttachment{Filename: "ticket.png", URL: url, ContentType: "image/png", Size: int64(len(image))}
+}
+
+// openTicket returns the registration of a ticket code and its event
+func (uc *TicketUseCase) openTicket(ctx context.Context, code string) (*entities.Event, *entities.EventAttendance, error) {
+	id, err := uc.signer.Verify(code)
+	if err != nil {
+		return nil, nil, errTicketInvalid
+	}
+	attendance, err := uc.attendanceRepo.FindByID(ctx, id)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, nil, errTicketInvalid
+		}
+		return nil, nil, err
+	}
+	event, err := uc.eventRepo.FindByID(ctx, attendance.EventID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, attendance, nil
+}
+
+// findPublicEvent returns an event the public can see and register for
+func (uc *TicketUseCase) findPublicEvent(ctx context.Context, eventID primitive.ObjectID) (*entities.Event, error) {
+	event, err := uc.eventRepo.FindByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.Public || !listed(event) {
+		return nil, errors.ErrNotFound
+	}
+	return event, nil
+}
+
+func (uc *TicketUseCase) publicEvent(event *entities.Event, now time.Time) *PublicEvent {
+	registration := event.Registration
+	view := &PublicEvent{
+		ID:                   event.ID,
+		Name:                 event.Name,
+		Description:          event.Description,
+		Type:                 event.Type,
+		Status:               event.Status,
+		StartDate:            event.StartDate,
+		EndDate:              event.EndDate,
+		Location:             event.Location,
+		VirtualLink:          event.VirtualLink,
+		ImageURL:             event.ImageURL,
+		Fee:                  registration.RegistrationFee,
+		Currency:             uc.eventCurrency(event),
+		Waitlist:             registration.Waitlist,
+		RegistrationDeadline: registration.Deadline,
+		RegistrationOpen:     !event.RegistrationClosed(now) && event.Status != entities.EventStatusCompleted,
+	}
+	if registration.Required && registration.MaxAttendees > 0 {
+		left := registration.MaxAttendees - registration.CurrentCount
+		if left < 0 {
+			left = 0
+		}
+		view.SeatsLeft = &left
+		if left == 0 && !registration.Waitlist {
+			view.RegistrationOpen = false
+		}
+	}
+	return view
+}
+
+func (uc *TicketUseCase) eventCurrency(event *entities.Event) string {
+	if event.Registration.Currency != "" {
+		return event.Registration.Currency
+	}
+	return uc.currency
+}
+
+func (uc *TicketUseCase) ticketLink(attendanceID primitive.ObjectID) string {
+	return uc.ticketURL + "/" + uc.signer.Sign(attendanceID)
+}
+
+// listed reports whether an event is shown on the public website
+func listed(event *entities.Event) bool {
+	switch event.Status {
+	case entities.EventStatusScheduled, entities.EventStatusActive, entities.EventStatusPostponed:
+		return true
+	}
+	return false
+}
+
+// qrPNG renders a ticket code as a QR code image
+func qrPNG(code string) ([]byte, error) {
+	qr, err := qrcode.Encode([]byte(code), qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	return qr.PNG(qrScale)
+}
+
+func greeting(name string) string {
+	if name == "" {
+		return "Hello"
+	}
+	return "Hello " + name
+}
+
+// errTicketInvalid is returned for ticket codes that are forged or of deleted registrations
+var errTicketInvalid = errors.NewNotFound("the ticket is invalid")
@@ -0,0 +1,267 @@
+package ticketing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/internal/usecase/event"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/sainaif/animalsys/backend/pkg/payment"
+	"github.com/sainaif/animalsys/backend/pkg/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ticketMocks struct {
+	events      *mocks.EventRepository
+	attendances *mocks.EventAttendanceRepository
+	gateway     *payment.FakeGateway
+	signer      *security.TicketSigner
+	messenger   *testutil.Messenger
+}
+
+func newTicketUseCase() (*TicketUseCase, *ticketMocks) {
+	m := &ticketMocks{
+		events:      new(mocks.EventRepository),
+		attendances: new(mocks.EventAttendanceRepository),
+		gateway:     payment.NewFakeGateway(),
+		signer:      security.NewTicketSigner("test-key"),
+		messenger:   &testutil.Messenger{},
+	}
+	auditLogs := testutil.AuditLogs()
+
+	registrar := event.NewEventUseCase(m.events, m.attendances, nil, auditLogs, nil, nil, m.messenger, nil, nil)
+	uc := NewTicketUseCase(m.events, m.attendances, auditLogs, nil, registrar, m.gateway, m.signer, m.messenger,
+		"https://shelter.example.org/tickets/", "PLN", time.Hour)
+	return uc, m
+}
+
+func publicEvent(m *ticketMocks, fee float64) *entities.Event {
+	e := &entities.Event{
+		ID:        primitive.NewObjectID(),
+		Name:      entities.MultilingualName{English: "Adoption Day"},
+		Status:    entities.EventStatusScheduled,
+		Public:    true,
+		StartDate: time.Now().AddDate(0, 0, 7),
+		CreatedBy: primitive.NewObjectID(),
+		Registration: entities.EventRegistration{
+			Required:        true,
+			MaxAttendees:    50,
+			RegistrationFee: fee,
+		},
+	}
+	m.events.On("FindByID", mock.Anything, e.ID).Return(e, nil)
+	return e
+}
+
+// expectCreate gives created registrations an ID and makes them findable
+func expectCreate(m *ticketMocks) func() *entities.EventAttendance {
+	var created *entities.EventAttendance
+	m.attendances.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*entities.EventAttendance)
+		created.ID = primitive.NewObjectID()
+		m.attendances.On("FindByID", mock.Anything, created.ID).Return(created, nil)
+	}).Return(nil)
+	return func() *entities.EventAttendance { return created }
+}
+
+func TestRegister_PaidTicketIsEmailedOncePaid(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 20)
+	m.events.On("ReserveSeats", mock.Anything, e.ID, 2).Return(true, nil)
+	created := expectCreate(m)
+	m.attendances.On("Update", mock.Anything, mock.Anything).Return(nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, mock.Anything, []entities.AttendanceStatus{entities.AttendanceStatusRegistered}).Return(true, nil)
+
+	registration, err := uc.Register(context.Background(), e.ID, &PublicRegistrationRequest{
+		Name:           "Anna Nowak",
+		Email:          "anna@example.org",
+		NumberOfGuests: 1,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, registration.Amount)
+	assert.Equal(t, "PLN", registration.Currency)
+	assert.Equal(t, "https://shelter.example.org/tickets/"+m.signer.Sign(registration.RegistrationID), registration.TicketURL)
+	assert.Equal(t, registration.TicketURL, registration.CheckoutURL)
+	require.NotNil(t, registration.PaymentDueAt)
+	assert.Empty(t, m.messenger.Sent, "the ticket is sent once paid")
+
+	attendance := created()
+	payload := `{"session_id":"` + attendance.CheckoutSessionID + `","status":"paid"}`
+	require.NoError(t, uc.HandlePaymentNotification(context.Background(), []byte(payload), http.Header{}))
+
+	assert.Equal(t, entities.AttendanceStatusConfirmed, attendance.Status)
+	assert.True(t, attendance.IsPaid())
+	assert.Nil(t, attendance.PaymentDueAt)
+	require.Len(t, m.messenger.Sent, 1)
+	assert.Equal(t, "anna@example.org", m.messenger.Sent[0].RecipientEmail)
+	assert.Contains(t, m.messenger.Sent[0].Body, m.signer.Sign(attendance.ID))
+	assert.NotNil(t, attendance.TicketSentAt)
+
+	// Gateways deliver notifications at least once
+	require.NoError(t, uc.HandlePaymentNotification(context.Background(), []byte(payload), http.Header{}))
+	assert.Len(t, m.messenger.Sent, 1)
+}
+
+func TestHandlePaymentNotification_RejectsAnAmountOtherThanTheFee(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 20)
+	m.events.On("ReserveSeats", mock.Anything, e.ID, 1).Return(true, nil)
+	created := expectCreate(m)
+	m.attendances.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := uc.Register(context.Background(), e.ID, &PublicRegistrationRequest{Name: "Anna Nowak", Email: "anna@example.org"})
+	require.NoError(t, err)
+
+	// The checkout was opened for 20, the ticket costs 35 now
+	attendance := created()
+	attendance.RegistrationFee = 35
+	payload := `{"session_id":"` + attendance.CheckoutSessionID + `","status":"paid"}`
+	err = uc.HandlePaymentNotification(context.Background(), []byte(payload), http.Header{})
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.(*errors.AppError).Code)
+	assert.False(t, attendance.IsPaid())
+	assert.Empty(t, m.messenger.Sent)
+}
+
+func TestRecordPayment_ReinstatesARegistrationCancelledMeanwhile(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 10)
+	due := time.Now().Add(time.Minute)
+	read := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: e.ID, Status: entities.AttendanceStatusRegistered, GuestEmail: "anna@example.org", RegistrationFee: 10, PaymentStatus: "pending", PaymentDueAt: &due}
+	cancelledAt := time.Now()
+	stored := &entities.EventAttendance{ID: read.ID, EventID: e.ID, Status: entities.AttendanceStatusCancelled, GuestEmail: "anna@example.org", RegistrationFee: 10, PaymentStatus: "pending", CancellationDate: &cancelledAt}
+
+	// The registration expired after the notification read it
+	m.attendances.On("UpdateIfStatus", mock.Anything, read, []entities.AttendanceStatus{entities.AttendanceStatusRegistered}).Return(false, nil)
+	m.attendances.On("FindByID", mock.Anything, read.ID).Return(stored, nil)
+	m.events.On("ReserveSeats", mock.Anything, e.ID, 1).Return(true, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, stored, []entities.AttendanceStatus{entities.AttendanceStatusCancelled}).Return(true, nil)
+	m.attendances.On("Update", mock.Anything, stored).Return(nil)
+
+	err := uc.recordPayment(context.Background(), e, read, &payment.Notification{TransactionID: "tx_1", Amount: 10, Status: payment.StatusPaid})
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.AttendanceStatusConfirmed, stored.Status)
+	assert.True(t, stored.IsPaid())
+	assert.Nil(t, stored.CancellationDate)
+	require.Len(t, m.messenger.Sent, 1)
+	m.events.AssertNotCalled(t, "ReleaseSeats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordPayment_ReleasesTheSeatsWhenTheReinstatementIsNotSaved(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 10)
+	cancelledAt := time.Now()
+	read := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: e.ID, Status: entities.AttendanceStatusCancelled, GuestEmail: "anna@example.org", RegistrationFee: 10, PaymentStatus: "pending", CancellationDate: &cancelledAt}
+	paidAt := time.Now()
+	stored := &entities.EventAttendance{ID: read.ID, EventID: e.ID, Status: entities.AttendanceStatusConfirmed, RegistrationFee: 10, PaymentStatus: "paid", PaymentDate: &paidAt}
+
+	// Another delivery of the notification reinstated the registration first
+	m.events.On("ReserveSeats", mock.Anything, e.ID, 1).Return(true, nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, read, []entities.AttendanceStatus{entities.AttendanceStatusCancelled}).Return(false, nil)
+	m.events.On("ReleaseSeats", mock.Anything, e.ID, 1).Return(nil)
+	m.attendances.On("FindByID", mock.Anything, read.ID).Return(stored, nil)
+
+	err := uc.recordPayment(context.Background(), e, read, &payment.Notification{TransactionID: "tx_1", Amount: 10, Status: payment.StatusPaid})
+
+	require.NoError(t, err)
+	m.events.AssertCalled(t, "ReleaseSeats", mock.Anything, e.ID, 1)
+	assert.Empty(t, m.messenger.Sent)
+}
+
+func TestRegister_FreeTicketIsEmailedRightAway(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 0)
+	m.events.On("ReserveSeats", mock.Anything, e.ID, 1).Return(true, nil)
+	expectCreate(m)
+	m.attendances.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	registration, err := uc.Register(context.Background(), e.ID, &PublicRegistrationRequest{Name: "Jan", Email: "jan@example.org"})
+
+	require.NoError(t, err)
+	assert.Empty(t, registration.CheckoutURL)
+	require.Len(t, m.messenger.Sent, 1)
+	assert.Equal(t, "Your ticket for Adoption Day", m.messenger.Sent[0].Subject)
+}
+
+func TestRegister_RejectsEventsThatAreNotPublic(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 0)
+	e.Public = false
+
+	_, err := uc.Register(context.Background(), e.ID, &PublicRegistrationRequest{Name: "Jan", Email: "jan@example.org"})
+
+	assert.Equal(t, errors.ErrNotFound, err)
+}
+
+func TestSyncCheckIns(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 10)
+	userID := primitive.NewObjectID()
+
+	paid := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: e.ID, Status: entities.AttendanceStatusConfirmed, RegistrationFee: 20, PaymentStatus: "paid", NumberOfGuests: 1}
+	unpaid := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: e.ID, Status: entities.AttendanceStatusRegistered, RegistrationFee: 10, PaymentStatus: "pending"}
+	otherEvent := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: primitive.NewObjectID(), Status: entities.AttendanceStatusConfirmed}
+	for _, attendance := range []*entities.EventAttendance{paid, unpaid, otherEvent} {
+		m.attendances.On("FindByID", mock.Anything, attendance.ID).Return(attendance, nil)
+	}
+	m.attendances.On("Update", mock.Anything, paid).Return(nil).Once()
+	m.attendances.On("CountCheckedInSeats", mock.Anything, e.ID).Return(2, nil)
+	m.events.On("UpdateEventStatistics", mock.Anything, e.ID, 2, 0, 0.0, 0).Return(nil)
+
+	scannedAt := time.Now().Add(-10 * time.Minute)
+	forged := strings.Replace(m.signer.Sign(paid.ID), paid.ID.Hex(), unpaid.ID.Hex(), 1)
+	response, err := uc.SyncCheckIns(context.Background(), e.ID, &SyncCheckInsRequest{CheckIns: []CheckInRequest{
+		{Code: m.signer.Sign(paid.ID), CheckedInAt: &scannedAt},
+		{Code: m.signer.Sign(paid.ID)}, // Scanned again at another door
+		{Code: m.signer.Sign(unpaid.ID)},
+		{Code: m.signer.Sign(otherEvent.ID)},
+		{Code: forged},
+	}}, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.CheckedIn)
+	assert.Equal(t, 1, response.Duplicates)
+	assert.Equal(t, 3, response.Rejected)
+	assert.Equal(t, 2, response.AttendeeCount)
+	assert.Equal(t, []string{CheckInResultCheckedIn, CheckInResultDuplicate, CheckInResultRejected, CheckInResultRejected, CheckInResultRejected},
+		[]string{response.Results[0].Result, response.Results[1].Result, response.Results[2].Result, response.Results[3].Result, response.Results[4].Result})
+	assert.Equal(t, "the ticket is not paid", response.Results[2].Error)
+
+	assert.Equal(t, entities.AttendanceStatusAttended, paid.Status)
+	assert.True(t, scannedAt.Equal(*paid.CheckInTime), "offline scans keep the time they were scanned")
+	m.events.AssertCalled(t, "UpdateEventStatistics", mock.Anything, e.ID, 2, 0, 0.0, 0)
+}
+
+func TestExpireUnpaidRegistrations_FreesTheSeats(t *testing.T) {
+	uc, m := newTicketUseCase()
+	e := publicEvent(m, 10)
+	due := time.Now().Add(-time.Minute)
+	unpaid := &entities.EventAttendance{ID: primitive.NewObjectID(), EventID: e.ID, Status: entities.AttendanceStatusRegistered, RegistrationFee: 10, PaymentStatus: "pending", PaymentDueAt: &due}
+	m.attendances.On("FindByID", mock.Anything, unpaid.ID).Return(unpaid, nil)
+	m.attendances.On("List", mock.Anything, mock.MatchedBy(func(filter *repositories.EventAttendanceFilter) bool {
+		return filter.PaymentDueBefore != nil && filter.Status == "registered"
+	})).Return([]*entities.EventAttendance{unpaid}, int64(1), nil)
+	m.attendances.On("List", mock.Anything, mock.MatchedBy(func(filter *repositories.EventAttendanceFilter) bool {
+		return filter.Status == "waitlisted"
+	})).Return([]*entities.EventAttendance{}, int64(0), nil)
+	m.attendances.On("UpdateIfStatus", mock.Anything, unpaid, mock.Anything).Return(true, nil)
+	m.events.On("ReleaseSeats", mock.Anything, e.ID, 1).Return(nil)
+
+	require.NoError(t, uc.ExpireUnpaidRegistrations(context.Background()))
+
+	assert.Equal(t, entities.AttendanceStatusCancelled, unpaid.Status)
+	m.events.AssertCalled(t, "ReleaseSeats", mock.Anything, e.ID, 1)
+}
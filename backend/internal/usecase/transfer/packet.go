@@ -0,0 +1,35 @@
+package transfer
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MedicalPacketGenerator stores the printable medical history of an animal as a document
+type MedicalPacketGenerator interface {
+	GeneratePacket(ctx context.Context, animalID primitive.ObjectID, language string, userID primitive.ObjectID) (*entities.Document, error)
+}
+
+// attachMedicalPacket generates the medical packet of an animal leaving for a
+// partner and adds it to the transfer documents. Staff can still generate the
+// packet by hand, so a failure is logged rather than holding up the transfer.
+func (uc *TransferUseCase) attachMedicalPacket(ctx context.Context, transfer *entities.Transfer, userID primitive.ObjectID) {
+	if uc.packets == nil || transfer.Direction != entities.TransferDirectionOutgoing {
+		return
+	}
+
+	document, err := uc.packets.GeneratePacket(ctx, transfer.AnimalID, "", userID)
+	if err != nil {
+		log.Error().Err(err).Str("transfer_id", transfer.ID.Hex()).Msg("failed to generate the medical packet of a transfer")
+		return
+	}
+
+	transfer.Documents = append(transfer.Documents, document.ID.Hex())
+	if err := uc.transferRepo.Update(ctx, transfer); err != nil {
+		log.Error().Err(err).Str("transfer_id", transfer.ID.Hex()).Str("document_id", document.ID.Hex()).
+			Msg("failed to add the medical packet to the transfer documents")
+	}
+}
@@ -0,0 +1,50 @@
+package transfer
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AnimalQuarantiner puts animals arriving from partners into quarantine and tells
+// whether an animal about to leave is still in one
+type AnimalQuarantiner interface {
+	QuarantineArrival(ctx context.Context, animalID, transferID primitive.ObjectID, days int, userID primitive.ObjectID) (*entities.Quarantine, error)
+	HasActiveQuarantine(ctx context.Context, animalID primitive.ObjectID) (bool, error)
+}
+
+// quarantineArrival quarantines an incoming animal when the transfer requires
+// it. The animal has physically arrived by now, so undoing the transfer would
+// be wrong; a failure is logged for staff to start the quarantine by hand.
+func (uc *TransferUseCase) quarantineArrival(ctx context.Context, transfer *entities.Transfer, userID primitive.ObjectID) {
+	if uc.quarantines == nil || transfer.Direction != entities.TransferDirectionIncoming || !transfer.RequiresQuarantine {
+		return
+	}
+
+	if _, err := uc.quarantines.QuarantineArrival(ctx, transfer.AnimalID, transfer.ID, transfer.QuarantineDays, userID); err != nil {
+		log.Error().Err(err).Str("transfer_id", transfer.ID.Hex()).Str("animal_id", transfer.AnimalID.Hex()).
+			Msg("failed to quarantine an animal arriving from a partner")
+	}
+}
+
+// checkLeavingQuarantine refuses to send an animal in quarantine to a partner
+func (uc *TransferUseCase) checkLeavingQuarantine(ctx context.Context, transfer *entities.Transfer, animal *entities.Animal) error {
+	if transfer.Direction != entities.TransferDirectionOutgoing {
+		return nil
+	}
+	quarantined := animal.IsQuarantined()
+	if !quarantined && uc.quarantines != nil {
+		active, err := uc.quarantines.HasActiveQuarantine(ctx, animal.ID)
+		if err != nil {
+			return err
+		}
+		quarantined = active
+	}
+	if quarantined {
+		return errors.NewConflict("Animal is in quarantine and cannot be transferred until it is released")
+	}
+	return nil
+}
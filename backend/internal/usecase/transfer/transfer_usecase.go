@@ -15,6 +15,8 @@ type TransferUseCase struct {
 	animalRepo   repositories.AnimalRepository
 	partnerRepo  repositories.PartnerRepository
 	auditLogRepo repositories.AuditLogRepository
+	packets      MedicalPacketGenerator
+	quarantines  AnimalQuarantiner
 }
 
 func NewTransferUseCase(
@@ -22,12 +24,16 @@ func NewTransferUseCase(
 	animalRepo repositories.AnimalRepository,
 	partnerRepo repositories.PartnerRepository,
 	auditLogRepo repositories.AuditLogRepository,
+	packets MedicalPacketGenerator,
+	quarantines AnimalQuarantiner,
 ) *TransferUseCase {
 	return &TransferUseCase{
 		transferRepo: transferRepo,
 		animalRepo:   animalRepo,
 		partnerRepo:  partnerRepo,
 		auditLogRepo: auditLogRepo,
+		packets:      packets,
+		quarantines:  quarantines,
 	}
 }
 
@@ -64,6 +70,10 @@ func (uc *TransferUseCase) CreateTransfer(ctx context.Context, transfer *entitie
 		return err
 	}
 
+	if err := uc.checkLeavingQuarantine(ctx, transfer, animal); err != nil {
+		return err
+	}
+
 	// Check if partner exists
 	partner, err := uc.partnerRepo.FindByID(ctx, transfer.PartnerID)
 	if err != nil {
@@ -104,6 +114,9 @@ func (uc *TransferUseCase) CreateTransfer(ctx context.Context, transfer *entitie
 		return err
 	}
 
+	// Outgoing animals travel with their medical history
+	uc.attachMedicalPacket(ctx, transfer, userID)
+
 	// Create audit log
 	_ = uc.auditLogRepo.Create(ctx,
 		entities.NewAuditLog(userID, entities.ActionCreate, "transfer", animal.Name.English+" to/from "+partner.Name, "").
@@ -325,17 +338,20 @@ func (uc *TransferUseCase) StartTransit(ctx context.Context, transferID, userID
 		return errors.NewBadRequest("Only approved transfers can be started")
 	}
 
-	transfer.StartTransit()
-
-	if err := uc.transferRepo.Update(ctx, transfer); err != nil {
-		return err
-	}
-
 	// Get animal name for audit log
 	animal, _ := uc.animalRepo.FindByID(ctx, transfer.AnimalID)
 	animalName := "Animal"
 	if animal != nil {
 		animalName = animal.Name.English
+		if err := uc.checkLeavingQuarantine(ctx, transfer, animal); err != nil {
+			return err
+		}
+	}
+
+	transfer.StartTransit()
+
+	if err := uc.transferRepo.Update(ctx, transfer); err != nil {
+		return err
 	}
 
 	// Create audit log
@@ -378,6 +394,9 @@ func (uc *TransferUseCase) CompleteTransfer(ctx context.Context, transferID, use
 		_ = uc.partnerRepo.Update(ctx, partner)
 	}
 
+	// Arrivals that need it start their quarantine
+	uc.quarantineArrival(ctx, transfer, userID)
+
 	// Get animal name for audit log
 	animal, _ := uc.animalRepo.FindByID(ctx, transfer.AnimalID)
 	animalName := "Animal"
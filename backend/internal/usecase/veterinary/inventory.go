@@ -0,0 +1,187 @@
+package veterinary
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockConsumer takes vaccines and dispensed medication out of inventory, and puts
+// them back when the record they were taken out for cannot be saved
+type StockConsumer interface {
+	ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error)
+	ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error
+}
+
+// consumeVaccineStock takes one dose of a vaccine linked to an inventory item
+// out of stock. The requested lot is used if given, otherwise the lot expiring
+// first, and the vaccination records the lot actually used.
+func (uc *VeterinaryUseCase) consumeVaccineStock(ctx context.Context, vaccination *entities.Vaccination, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	if vaccination.InventoryItemID == nil || uc.stock == nil {
+		return nil, nil
+	}
+
+	transactions, err := uc.stock.ConsumeStock(ctx, &entities.StockConsumption{
+		ItemID:          *vaccination.InventoryItemID,
+		Quantity:        1,
+		LotNumber:       vaccination.LotNumber,
+		AnimalID:        &vaccination.AnimalID,
+		RelatedEntity:   "animal",
+		RelatedEntityID: &vaccination.AnimalID,
+		Reason:          "vaccination administered",
+		Reference:       "vaccination:" + vaccination.ID.Hex(),
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transactions) > 0 {
+		vaccination.LotNumber = transactions[0].LotNumber
+		vaccination.LotExpirationDate = transactions[0].ExpirationDate
+		vaccination.LotUnitCost = transactions[0].UnitCost
+	}
+
+	return transactions, nil
+}
+
+// returnVaccineDose puts the dose of a stored vaccination back into the lot it was taken from
+func (uc *VeterinaryUseCase) returnVaccineDose(ctx context.Context, vaccination *entities.Vaccination, userID primitive.ObjectID, reason string) {
+	if vaccination.InventoryItemID == nil || uc.stock == nil {
+		return
+	}
+
+	dose := entities.NewStockTransaction(*vaccination.InventoryItemID, entities.TransactionTypeOut, 1, 0, userID)
+	dose.SetCost(vaccination.LotUnitCost)
+	dose.LotNumber = vaccination.LotNumber
+	dose.ExpirationDate = vaccination.LotExpirationDate
+	dose.Reference = "vaccination:" + vaccination.ID.Hex()
+	uc.returnStock(ctx, []*entities.StockTransaction{dose}, userID, reason)
+}
+
+// saveVaccineLot takes the dose of a saved vaccination out of stock and stores the
+// lot used on it. The dose goes back into stock if the vaccination cannot be updated.
+func (uc *VeterinaryUseCase) saveVaccineLot(ctx context.Context, vaccination *entities.Vaccination, userID primitive.ObjectID) error {
+	transactions, err := uc.consumeVaccineStock(ctx, vaccination, userID)
+	if err != nil || len(transactions) == 0 {
+		return err
+	}
+
+	if err := uc.vaccinationRepo.Update(ctx, vaccination); err != nil {
+		uc.returnStock(ctx, transactions, userID, "vaccination "+vaccination.ID.Hex()+" was not saved")
+		return err
+	}
+
+	return nil
+}
+
+// dispensePrescriptions takes prescriptions linked to an inventory item out of
+// stock once, marking them dispensed with the lots used. When one of them cannot
+// be dispensed, the stock taken for the others is given back and the
+// prescriptions are left as they were.
+func (uc *VeterinaryUseCase) dispensePrescriptions(ctx context.Context, visit *entities.VeterinaryVisit, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	if uc.stock == nil {
+		return nil, nil
+	}
+
+	undispensed := append([]entities.Prescription(nil), visit.Prescriptions...)
+	var consumed []*entities.StockTransaction
+	for i := range visit.Prescriptions {
+		prescription := &visit.Prescriptions[i]
+		if prescription.InventoryItemID == nil || prescription.DispensedAt != nil {
+			continue
+		}
+
+		quantity := prescription.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+
+		transactions, err := uc.stock.ConsumeStock(ctx, &entities.StockConsumption{
+			ItemID:          *prescription.InventoryItemID,
+			Quantity:        quantity,
+			LotNumber:       prescription.LotNumber,
+			AnimalID:        &visit.AnimalID,
+			RelatedEntity:   "veterinary_visit",
+			RelatedEntityID: &visit.ID,
+			Reason:          "prescription dispensed",
+			Reference:       prescription.MedicationName,
+		}, userID)
+		if err != nil {
+			uc.returnStock(ctx, consumed, userID, "dispensing for visit "+visit.ID.Hex()+" failed")
+			copy(visit.Prescriptions, undispensed)
+			return nil, err
+		}
+		consumed = append(consumed, transactions...)
+
+		var lots []string
+		for _, transaction := range transactions {
+			if transaction.LotNumber != "" {
+				lots = append(lots, transaction.LotNumber)
+			}
+		}
+
+		now := time.Now()
+		prescription.Quantity = quantity
+		prescription.LotNumber = strings.Join(lots, ", ")
+		prescription.DispensedAt = &now
+	}
+
+	return consumed, nil
+}
+
+// saveDispensing dispenses the prescriptions of a saved visit and stores the lots
+// used on it. The stock goes back if the visit cannot be updated.
+func (uc *VeterinaryUseCase) saveDispensing(ctx context.Context, visit *entities.VeterinaryVisit, userID primitive.ObjectID) error {
+	transactions, err := uc.dispensePrescriptions(ctx, visit, userID)
+	if err != nil || len(transactions) == 0 {
+		return err
+	}
+
+	if err := uc.visitRepo.Update(ctx, visit); err != nil {
+		uc.returnStock(ctx, transactions, userID, "visit "+visit.ID.Hex()+" was not saved")
+		return err
+	}
+
+	return nil
+}
+
+// returnStock gives back stock consumed for a record that was not saved. A failure
+// leaves inventory short, so it is logged for someone to correct by hand.
+func (uc *VeterinaryUseCase) returnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) {
+	if len(transactions) == 0 {
+		return
+	}
+	if err := uc.stock.ReturnStock(ctx, transactions, userID, reason); err != nil {
+		log.Error().Err(err).Str("reason", reason).Msg("failed to return consumed stock")
+	}
+}
+
+// carryOverDispensing keeps the dispensing details of existing prescriptions on
+// a submitted list so resubmitted prescriptions are not dispensed twice.
+// Dispensing details sent by the client are ignored.
+func carryOverDispensing(existing, submitted []entities.Prescription) {
+	used := make([]bool, len(existing))
+	for i := range submitted {
+		prescription := &submitted[i]
+		prescription.DispensedAt = nil
+		if prescription.InventoryItemID == nil {
+			continue
+		}
+		for j, old := range existing {
+			if used[j] || old.DispensedAt == nil || old.InventoryItemID == nil {
+				continue
+			}
+			if *old.InventoryItemID == *prescription.InventoryItemID && old.MedicationName == prescription.MedicationName {
+				prescription.Quantity = old.Quantity
+				prescription.LotNumber = old.LotNumber
+				prescription.DispensedAt = old.DispensedAt
+				used[j] = true
+				break
+			}
+		}
+	}
+}
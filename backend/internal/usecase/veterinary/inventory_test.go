@@ -0,0 +1,243 @@
+package veterinary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type recordingStockConsumer struct {
+	consumptions []*entities.StockConsumption
+	returned     []*entities.StockTransaction
+	lotNumber    string
+	expiry       *time.Time
+	outOfStock   *primitive.ObjectID
+}
+
+func (s *recordingStockConsumer) ConsumeStock(ctx context.Context, consumption *entities.StockConsumption, userID primitive.ObjectID) ([]*entities.StockTransaction, error) {
+	if s.outOfStock != nil && consumption.ItemID == *s.outOfStock {
+		return nil, errors.NewBadRequest("Insufficient stock")
+	}
+	s.consumptions = append(s.consumptions, consumption)
+	transaction := entities.NewStockTransaction(consumption.ItemID, entities.TransactionTypeOut, consumption.Quantity, 10, userID)
+	transaction.LotNumber = s.lotNumber
+	transaction.ExpirationDate = s.expiry
+	return []*entities.StockTransaction{transaction}, nil
+}
+
+func (s *recordingStockConsumer) ReturnStock(ctx context.Context, transactions []*entities.StockTransaction, userID primitive.ObjectID, reason string) error {
+	s.returned = append(s.returned, transactions...)
+	return nil
+}
+
+// assignID gives a created record an ID the way the database does
+func assignID(args mock.Arguments) {
+	switch record := args.Get(1).(type) {
+	case *entities.Vaccination:
+		record.ID = primitive.NewObjectID()
+	case *entities.VeterinaryVisit:
+		record.ID = primitive.NewObjectID()
+	}
+}
+
+func TestVeterinaryUseCase_CreateVaccinationConsumesVaccineLot(t *testing.T) {
+	ctx := context.Background()
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockVaccinationRepo := new(mocks.VaccinationRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	expiry := time.Now().AddDate(0, 6, 0)
+	stock := &recordingStockConsumer{lotNumber: "RB-2291", expiry: &expiry}
+	useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, mockAuditLogRepo, nil, nil, stock, nil)
+
+	animalID := primitive.NewObjectID()
+	itemID := primitive.NewObjectID()
+	mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+	mockVaccinationRepo.On("Create", ctx, mock.AnythingOfType("*entities.Vaccination")).Run(assignID).Return(nil)
+	mockVaccinationRepo.On("Update", ctx, mock.AnythingOfType("*entities.Vaccination")).Return(nil)
+	mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	vaccination, err := useCase.CreateVaccination(ctx, &CreateVaccinationRequest{
+		AnimalID:         animalID.Hex(),
+		VaccineType:      entities.VaccineRabies,
+		VaccineName:      "Nobivac Rabies",
+		InventoryItemID:  itemID.Hex(),
+		DoseNumber:       1,
+		DateAdministered: time.Now(),
+		VeterinarianName: "Dr. Nowak",
+	}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, stock.consumptions, 1)
+	consumption := stock.consumptions[0]
+	assert.Equal(t, itemID, consumption.ItemID)
+	assert.Equal(t, 1.0, consumption.Quantity)
+	assert.Empty(t, consumption.LotNumber, "no lot requested, inventory picks the first to expire")
+	assert.Equal(t, &animalID, consumption.AnimalID)
+	assert.Equal(t, "vaccination:"+vaccination.ID.Hex(), consumption.Reference)
+
+	assert.Equal(t, "RB-2291", vaccination.LotNumber)
+	assert.Equal(t, &expiry, vaccination.LotExpirationDate)
+	assert.Equal(t, &itemID, vaccination.InventoryItemID)
+	mockVaccinationRepo.AssertCalled(t, "Update", ctx, vaccination)
+}
+
+func TestVeterinaryUseCase_DeleteVaccinationReturnsTheDose(t *testing.T) {
+	ctx := context.Background()
+	mockVaccinationRepo := new(mocks.VaccinationRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	stock := &recordingStockConsumer{}
+	useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, nil, mockAuditLogRepo, nil, nil, stock, nil)
+
+	itemID := primitive.NewObjectID()
+	expiry := time.Now().AddDate(0, 6, 0)
+	vaccination := &entities.Vaccination{
+		ID:                primitive.NewObjectID(),
+		InventoryItemID:   &itemID,
+		LotNumber:         "RB-2291",
+		LotExpirationDate: &expiry,
+		LotUnitCost:       12.5,
+	}
+	mockVaccinationRepo.On("FindByID", ctx, vaccination.ID).Return(vaccination, nil)
+	mockVaccinationRepo.On("Delete", ctx, vaccination.ID).Return(nil)
+	mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	require.NoError(t, useCase.DeleteVaccination(ctx, vaccination.ID, primitive.NewObjectID()))
+
+	require.Len(t, stock.returned, 1)
+	dose := stock.returned[0]
+	assert.Equal(t, itemID, dose.ItemID)
+	assert.Equal(t, 1.0, dose.Quantity)
+	assert.Equal(t, "RB-2291", dose.LotNumber)
+	assert.Equal(t, &expiry, dose.ExpirationDate)
+	assert.Equal(t, 12.5, dose.UnitCost)
+}
+
+func TestVeterinaryUseCase_CreateVaccinationIsRemovedWhenNoDoseIsInStock(t *testing.T) {
+	ctx := context.Background()
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockVaccinationRepo := new(mocks.VaccinationRepository)
+	itemID := primitive.NewObjectID()
+	stock := &recordingStockConsumer{outOfStock: &itemID}
+	useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, new(mocks.AuditLogRepository), nil, nil, stock, nil)
+
+	animalID := primitive.NewObjectID()
+	var created primitive.ObjectID
+	mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+	mockVaccinationRepo.On("Create", ctx, mock.AnythingOfType("*entities.Vaccination")).Run(func(args mock.Arguments) {
+		assignID(args)
+		created = args.Get(1).(*entities.Vaccination).ID
+	}).Return(nil)
+	mockVaccinationRepo.On("Delete", ctx, mock.Anything).Return(nil)
+
+	_, err := useCase.CreateVaccination(ctx, &CreateVaccinationRequest{
+		AnimalID:         animalID.Hex(),
+		VaccineType:      entities.VaccineRabies,
+		VaccineName:      "Nobivac Rabies",
+		InventoryItemID:  itemID.Hex(),
+		DoseNumber:       1,
+		DateAdministered: time.Now(),
+		VeterinarianName: "Dr. Nowak",
+	}, primitive.NewObjectID())
+
+	require.Error(t, err)
+	mockVaccinationRepo.AssertCalled(t, "Delete", ctx, created)
+}
+
+func TestVeterinaryUseCase_PartlyDispensedVisitGivesTheStockBack(t *testing.T) {
+	ctx := context.Background()
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockVisitRepo := new(mocks.VeterinaryVisitRepository)
+	missingItemID := primitive.NewObjectID()
+	stock := &recordingStockConsumer{lotNumber: "AMX-14", outOfStock: &missingItemID}
+	useCase := NewVeterinaryUseCase(mockVisitRepo, nil, mockAnimalRepo, new(mocks.AuditLogRepository), nil, nil, stock, nil)
+
+	animalID := primitive.NewObjectID()
+	itemID := primitive.NewObjectID()
+	var created *entities.VeterinaryVisit
+	mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+	mockVisitRepo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		assignID(args)
+		created = args.Get(1).(*entities.VeterinaryVisit)
+	}).Return(nil)
+	mockVisitRepo.On("Delete", ctx, mock.Anything).Return(nil)
+
+	_, err := useCase.CreateVisit(ctx, &CreateVisitRequest{
+		AnimalID:         animalID.Hex(),
+		VisitType:        entities.VisitTypeTreatment,
+		VisitDate:        time.Now(),
+		VeterinarianName: "Dr. Nowak",
+		Prescriptions: []entities.Prescription{
+			{MedicationName: "Amoxicillin", InventoryItemID: &itemID, Quantity: 14},
+			{MedicationName: "Meloxicam", InventoryItemID: &missingItemID},
+		},
+	}, primitive.NewObjectID())
+
+	require.Error(t, err)
+	require.Len(t, stock.returned, 1, "the amoxicillin already taken out goes back")
+	assert.Equal(t, itemID, stock.returned[0].ItemID)
+	assert.Nil(t, created.Prescriptions[0].DispensedAt)
+	mockVisitRepo.AssertCalled(t, "Delete", ctx, created.ID)
+	mockVisitRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestVeterinaryUseCase_PrescriptionsAreDispensedOnce(t *testing.T) {
+	ctx := context.Background()
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockVisitRepo := new(mocks.VeterinaryVisitRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	stock := &recordingStockConsumer{lotNumber: "AMX-14"}
+	useCase := NewVeterinaryUseCase(mockVisitRepo, nil, mockAnimalRepo, mockAuditLogRepo, nil, nil, stock, nil)
+
+	animalID := primitive.NewObjectID()
+	itemID := primitive.NewObjectID()
+	clientDispensed := time.Now()
+	mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+	mockVisitRepo.On("Create", ctx, mock.Anything).Run(assignID).Return(nil)
+	mockVisitRepo.On("Update", ctx, mock.Anything).Return(nil)
+	mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	visit, err := useCase.CreateVisit(ctx, &CreateVisitRequest{
+		AnimalID:         animalID.Hex(),
+		VisitType:        entities.VisitTypeTreatment,
+		VisitDate:        time.Now(),
+		VeterinarianName: "Dr. Nowak",
+		Prescriptions: []entities.Prescription{
+			{MedicationName: "Amoxicillin", InventoryItemID: &itemID, Quantity: 14, DispensedAt: &clientDispensed},
+			{MedicationName: "Rest", Instructions: "No walks for a week"},
+		},
+	}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, stock.consumptions, 1, "dispensing details from the client are ignored")
+	assert.Equal(t, "veterinary_visit", stock.consumptions[0].RelatedEntity)
+	assert.Equal(t, &visit.ID, stock.consumptions[0].RelatedEntityID)
+	assert.Equal(t, &animalID, stock.consumptions[0].AnimalID)
+	assert.Equal(t, 14.0, stock.consumptions[0].Quantity)
+	assert.Equal(t, "AMX-14", visit.Prescriptions[0].LotNumber)
+	require.NotNil(t, visit.Prescriptions[0].DispensedAt)
+	assert.Nil(t, visit.Prescriptions[1].DispensedAt)
+
+	// Resubmitting the same prescription keeps it dispensed, a new one is dispensed
+	mockVisitRepo.On("FindByID", ctx, visit.ID).Return(visit, nil)
+	otherItemID := primitive.NewObjectID()
+	resubmitted := []entities.Prescription{
+		{MedicationName: "Amoxicillin", InventoryItemID: &itemID, Quantity: 14},
+		{MedicationName: "Meloxicam", InventoryItemID: &otherItemID},
+	}
+	updated, err := useCase.UpdateVisit(ctx, visit.ID, &UpdateVisitRequest{Prescriptions: &resubmitted}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, stock.consumptions, 2)
+	assert.Equal(t, otherItemID, stock.consumptions[1].ItemID)
+	assert.Equal(t, 1.0, stock.consumptions[1].Quantity)
+	assert.Equal(t, "AMX-14", updated.Prescriptions[0].LotNumber)
+	assert.NotNil(t, updated.Prescriptions[1].DispensedAt)
+}
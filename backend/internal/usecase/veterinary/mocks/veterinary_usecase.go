@@ -82,3 +82,43 @@ func (m *VeterinaryUseCase) ListVeterinaryRecords(ctx context.Context, req *vete
 	args := m.Called(ctx, req)
 	return args.Get(0).([]*entities.VeterinaryRecord), args.Get(1).(int64), args.Error(2)
 }
+
+func (m *VeterinaryUseCase) CreateProtocol(ctx context.Context, req *veterinary.CreateProtocolRequest, creatorID primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	args := m.Called(ctx, req, creatorID)
+	return args.Get(0).(*entities.VaccinationProtocol), args.Error(1)
+}
+
+func (m *VeterinaryUseCase) GetProtocolByID(ctx context.Context, id primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*entities.VaccinationProtocol), args.Error(1)
+}
+
+func (m *VeterinaryUseCase) UpdateProtocol(ctx context.Context, id primitive.ObjectID, req *veterinary.UpdateProtocolRequest, updaterID primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	args := m.Called(ctx, id, req, updaterID)
+	return args.Get(0).(*entities.VaccinationProtocol), args.Error(1)
+}
+
+func (m *VeterinaryUseCase) DeleteProtocol(ctx context.Context, id primitive.ObjectID, deleterID primitive.ObjectID) error {
+	args := m.Called(ctx, id, deleterID)
+	return args.Error(0)
+}
+
+func (m *VeterinaryUseCase) ListProtocols(ctx context.Context, req *veterinary.ListProtocolsRequest) ([]*entities.VaccinationProtocol, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]*entities.VaccinationProtocol), args.Error(1)
+}
+
+func (m *VeterinaryUseCase) GetVaccinationCompliance(ctx context.Context, animalID primitive.ObjectID) (*entities.VaccinationCompliance, error) {
+	args := m.Called(ctx, animalID)
+	return args.Get(0).(*entities.VaccinationCompliance), args.Error(1)
+}
+
+func (m *VeterinaryUseCase) RegenerateVaccinationSchedule(ctx context.Context, animalID primitive.ObjectID, userID primitive.ObjectID) (*entities.VaccinationCompliance, error) {
+	args := m.Called(ctx, animalID, userID)
+	return args.Get(0).(*entities.VaccinationCompliance), args.Error(1)
+}
+
+func (m *VeterinaryUseCase) GetScheduledVaccinations(ctx context.Context, days int) ([]*entities.VaccinationScheduleItem, error) {
+	args := m.Called(ctx, days)
+	return args.Get(0).([]*entities.VaccinationScheduleItem), args.Error(1)
+}
@@ -0,0 +1,546 @@
+package veterinary
+
+import (
+	"context"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateProtocolRequest represents a request to create a vaccination protocol
+type CreateProtocolRequest struct {
+	Name                  string                   `json:"name" validate:"required"`
+	Species               string                   `json:"species" validate:"required"`
+	VaccineType           entities.VaccinationType `json:"vaccine_type" validate:"required"`
+	VaccineName           string                   `json:"vaccine_name,omitempty"`
+	Description           string                   `json:"description,omitempty"`
+	SeriesAgesWeeks       []int                    `json:"series_ages_weeks,omitempty" validate:"dive,min=0"`
+	AdultDoses            int                      `json:"adult_doses" validate:"min=0"`
+	DoseIntervalWeeks     int                      `json:"dose_interval_weeks,omitempty" validate:"min=0"`
+	MinAgeWeeks           int                      `json:"min_age_weeks,omitempty" validate:"min=0"`
+	BoosterIntervalMonths int                      `json:"booster_interval_months,omitempty" validate:"min=0"`
+	Active                *bool                    `json:"active,omitempty"`
+}
+
+// UpdateProtocolRequest represents a request to update a vaccination protocol
+type UpdateProtocolRequest struct {
+	Name                  *string `json:"name,omitempty"`
+	VaccineName           *string `json:"vaccine_name,omitempty"`
+	Description           *string `json:"description,omitempty"`
+	SeriesAgesWeeks       *[]int  `json:"series_ages_weeks,omitempty"`
+	AdultDoses            *int    `json:"adult_doses,omitempty" validate:"omitempty,min=0"`
+	DoseIntervalWeeks     *int    `json:"dose_interval_weeks,omitempty" validate:"omitempty,min=0"`
+	MinAgeWeeks           *int    `json:"min_age_weeks,omitempty" validate:"omitempty,min=0"`
+	BoosterIntervalMonths *int    `json:"booster_interval_months,omitempty" validate:"omitempty,min=0"`
+	Active                *bool   `json:"active,omitempty"`
+}
+
+// ListProtocolsRequest represents a request to list vaccination protocols
+type ListProtocolsRequest struct {
+	Species     string `form:"species"`
+	VaccineType string `form:"vaccine_type"`
+	ActiveOnly  bool   `form:"active_only"`
+}
+
+// CreateProtocol creates a new vaccination protocol
+func (uc *VeterinaryUseCase) CreateProtocol(ctx context.Context, req *CreateProtocolRequest, creatorID primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	protocol := &entities.VaccinationProtocol{
+		Name:                  req.Name,
+		Species:               entities.NormalizeSpecies(req.Species),
+		VaccineType:           req.VaccineType,
+		VaccineName:           req.VaccineName,
+		Description:           req.Description,
+		SeriesAgesWeeks:       req.SeriesAgesWeeks,
+		AdultDoses:            req.AdultDoses,
+		DoseIntervalWeeks:     req.DoseIntervalWeeks,
+		MinAgeWeeks:           req.MinAgeWeeks,
+		BoosterIntervalMonths: req.BoosterIntervalMonths,
+		Active:                active,
+		CreatedBy:             creatorID,
+		UpdatedBy:             creatorID,
+	}
+	if protocol.AdultDoses == 0 {
+		protocol.AdultDoses = 1
+	}
+
+	if err := uc.protocolRepo.Create(ctx, protocol); err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(creatorID, entities.ActionCreate, "vaccination_protocol", protocol.Name, "").
+		WithEntityID(protocol.ID)
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return protocol, nil
+}
+
+// GetProtocolByID retrieves a vaccination protocol by ID
+func (uc *VeterinaryUseCase) GetProtocolByID(ctx context.Context, id primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	return uc.protocolRepo.FindByID(ctx, id)
+}
+
+// UpdateProtocol updates a vaccination protocol. Existing schedules keep their dates
+// until they are regenerated.
+func (uc *VeterinaryUseCase) UpdateProtocol(ctx context.Context, id primitive.ObjectID, req *UpdateProtocolRequest, updaterID primitive.ObjectID) (*entities.VaccinationProtocol, error) {
+	protocol, err := uc.protocolRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]interface{})
+
+	if req.Name != nil {
+		protocol.Name = *req.Name
+		changes["name"] = *req.Name
+	}
+	if req.VaccineName != nil {
+		protocol.VaccineName = *req.VaccineName
+	}
+	if req.Description != nil {
+		protocol.Description = *req.Description
+	}
+	if req.SeriesAgesWeeks != nil {
+		protocol.SeriesAgesWeeks = *req.SeriesAgesWeeks
+		changes["series_ages_weeks"] = *req.SeriesAgesWeeks
+	}
+	if req.AdultDoses != nil {
+		protocol.AdultDoses = *req.AdultDoses
+		changes["adult_doses"] = *req.AdultDoses
+	}
+	if req.DoseIntervalWeeks != nil {
+		protocol.DoseIntervalWeeks = *req.DoseIntervalWeeks
+		changes["dose_interval_weeks"] = *req.DoseIntervalWeeks
+	}
+	if req.MinAgeWeeks != nil {
+		protocol.MinAgeWeeks = *req.MinAgeWeeks
+		changes["min_age_weeks"] = *req.MinAgeWeeks
+	}
+	if req.BoosterIntervalMonths != nil {
+		protocol.BoosterIntervalMonths = *req.BoosterIntervalMonths
+		changes["booster_interval_months"] = *req.BoosterIntervalMonths
+	}
+	if req.Active != nil {
+		protocol.Active = *req.Active
+		changes["active"] = *req.Active
+	}
+
+	protocol.UpdatedBy = updaterID
+
+	if err := uc.protocolRepo.Update(ctx, protocol); err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(updaterID, entities.ActionUpdate, "vaccination_protocol", protocol.Name, "").
+		WithEntityID(id).
+		WithChanges(changes)
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return protocol, nil
+}
+
+// DeleteProtocol deletes a vaccination protocol
+func (uc *VeterinaryUseCase) DeleteProtocol(ctx context.Context, id primitive.ObjectID, deleterID primitive.ObjectID) error {
+	protocol, err := uc.protocolRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.protocolRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(deleterID, entities.ActionDelete, "vaccination_protocol", protocol.Name, "").
+		WithEntityID(id)
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return nil
+}
+
+// ListProtocols lists vaccination protocols
+func (uc *VeterinaryUseCase) ListProtocols(ctx context.Context, req *ListProtocolsRequest) ([]*entities.VaccinationProtocol, error) {
+	filter := repositories.VaccinationProtocolFilter{
+		Species:     req.Species,
+		VaccineType: req.VaccineType,
+	}
+	if req.ActiveOnly {
+		active := true
+		filter.Active = &active
+	}
+
+	return uc.protocolRepo.List(ctx, filter)
+}
+
+// EnsureDefaultProtocols installs the default protocols when none are configured
+func (uc *VeterinaryUseCase) EnsureDefaultProtocols(ctx context.Context) error {
+	existing, err := uc.protocolRepo.List(ctx, repositories.VaccinationProtocolFilter{})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	for _, protocol := range entities.DefaultVaccinationProtocols() {
+		if err := uc.protocolRepo.Create(ctx, protocol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ScheduleIntakeVaccinations generates the expected vaccinations of a newly admitted animal
+// from the active protocols of its species
+func (uc *VeterinaryUseCase) ScheduleIntakeVaccinations(ctx context.Context, animal *entities.Animal, userID primitive.ObjectID) error {
+	start := animal.Shelter.IntakeDate
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	if _, err := uc.generateSchedule(ctx, animal, start, userID); err != nil {
+		return err
+	}
+
+	_, err := uc.syncCompliance(ctx, animal.ID)
+	return err
+}
+
+// RegenerateVaccinationSchedule replaces the pending doses of an animal, e.g. after its date of birth
+// was corrected or the protocols changed. Doses already given count towards the new series.
+func (uc *VeterinaryUseCase) RegenerateVaccinationSchedule(ctx context.Context, animalID primitive.ObjectID, userID primitive.ObjectID) (*entities.VaccinationCompliance, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.scheduleRepo.DeletePending(ctx, animalID); err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.generateSchedule(ctx, animal, time.Now(), userID); err != nil {
+		return nil, err
+	}
+
+	// Create audit log
+	auditLog := entities.NewAuditLog(userID, entities.ActionUpdate, "animal", "", "regenerated vaccination schedule").
+		WithEntityID(animalID)
+	_ = uc.auditLogRepo.Create(ctx, auditLog)
+
+	return uc.syncCompliance(ctx, animalID)
+}
+
+// GetVaccinationCompliance returns the schedule and compliance status of an animal
+func (uc *VeterinaryUseCase) GetVaccinationCompliance(ctx context.Context, animalID primitive.ObjectID) (*entities.VaccinationCompliance, error) {
+	if _, err := uc.animalRepo.FindByID(ctx, animalID); err != nil {
+		return nil, err
+	}
+
+	items, err := uc.scheduleRepo.GetByAnimalID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return entities.NewVaccinationCompliance(animalID, items, time.Now()), nil
+}
+
+// GetScheduledVaccinations returns the pending doses across all animals that are overdue
+// or due within the given number of days
+func (uc *VeterinaryUseCase) GetScheduledVaccinations(ctx context.Context, days int) ([]*entities.VaccinationScheduleItem, error) {
+	if days < 0 {
+		days = 0
+	}
+	return uc.scheduleRepo.ListPending(ctx, time.Now().AddDate(0, 0, days))
+}
+
+// RefreshVaccinationCompliance recomputes the compliance of every animal with pending doses,
+// so due and overdue statuses follow the calendar
+func (uc *VeterinaryUseCase) RefreshVaccinationCompliance(ctx context.Context) error {
+	animalIDs, err := uc.scheduleRepo.PendingAnimalIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, animalID := range animalIDs {
+		if _, err := uc.syncCompliance(ctx, animalID); err != nil && err != errors.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateSchedule creates the pending doses of every active protocol for the species,
+// skipping the doses the animal already received in that series
+func (uc *VeterinaryUseCase) generateSchedule(ctx context.Context, animal *entities.Animal, start time.Time, userID primitive.ObjectID) ([]*entities.VaccinationScheduleItem, error) {
+	active := true
+	protocols, err := uc.protocolRepo.List(ctx, repositories.VaccinationProtocolFilter{
+		Species: animal.Species,
+		Active:  &active,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(protocols) == 0 {
+		return nil, nil
+	}
+
+	existing, err := uc.scheduleRepo.GetByAnimalID(ctx, animal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*entities.VaccinationScheduleItem
+	for _, protocol := range protocols {
+		given := 0
+		var lastGiven *time.Time
+		for _, item := range existing {
+			if item.ProtocolID != protocol.ID || item.Status != entities.VaccinationScheduleCompleted {
+				continue
+			}
+			if !item.Booster {
+				given++
+			}
+			if item.CompletedAt != nil && (lastGiven == nil || item.CompletedAt.After(*lastGiven)) {
+				lastGiven = item.CompletedAt
+			}
+		}
+
+		dates := protocol.Plan(animal.DateOfBirth, start)
+		if given >= len(dates) {
+			// Series complete: only the next booster is pending
+			if lastGiven != nil {
+				if due := protocol.BoosterDue(*lastGiven); due != nil {
+					items = append(items, newScheduleItem(animal.ID, protocol, 0, len(dates), *due, userID))
+				}
+			}
+			continue
+		}
+
+		for i, due := range dates[given:] {
+			if i == 0 && lastGiven != nil {
+				due = protocol.NextDoseDue(*lastGiven, due)
+			}
+			items = append(items, newScheduleItem(animal.ID, protocol, given+i+1, len(dates), due, userID))
+		}
+	}
+
+	if err := uc.scheduleRepo.CreateMany(ctx, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// advanceSchedule matches a recorded dose to the pending dose of the same vaccine with its
+// dose number, moves the following dose so it keeps the minimum interval and schedules the
+// booster once the series is complete. It returns the items to update after the vaccination
+// is stored.
+func (uc *VeterinaryUseCase) advanceSchedule(ctx context.Context, vaccination *entities.Vaccination) ([]*entities.VaccinationScheduleItem, []*entities.VaccinationScheduleItem, error) {
+	items, err := uc.scheduleRepo.GetByAnimalID(ctx, vaccination.AnimalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := matchScheduleItem(items, vaccination)
+	if current == nil {
+		return nil, nil, nil
+	}
+
+	completedAt := vaccination.DateAdministered
+	current.Status = entities.VaccinationScheduleCompleted
+	current.CompletedAt = &completedAt
+	updated := []*entities.VaccinationScheduleItem{current}
+
+	if vaccination.TotalDoses == 0 && !current.Booster {
+		vaccination.TotalDoses = current.TotalDoses
+	}
+
+	protocol, err := uc.protocolRepo.FindByID(ctx, current.ProtocolID)
+	if err != nil && err != errors.ErrNotFound {
+		return nil, nil, err
+	}
+
+	// The following dose of the series; the booster is only added once no other dose is outstanding
+	var next *entities.VaccinationScheduleItem
+	outstanding := false
+	for _, item := range items {
+		if item == current || !item.IsPending() || item.ProtocolID != current.ProtocolID {
+			continue
+		}
+		if next == nil && !item.Booster && !current.Booster && item.DoseNumber > current.DoseNumber {
+			next = item
+			continue
+		}
+		outstanding = true
+	}
+
+	var created []*entities.VaccinationScheduleItem
+	switch {
+	case next != nil:
+		if protocol != nil {
+			if due := protocol.NextDoseDue(completedAt, next.DueDate); !due.Equal(next.DueDate) {
+				previous := next.DueDate
+				next.PreviousDueDate = &previous
+				next.DueDate = due
+				updated = append(updated, next)
+			}
+		}
+	case protocol != nil && !outstanding:
+		if due := protocol.BoosterDue(completedAt); due != nil {
+			next = newScheduleItem(vaccination.AnimalID, protocol, 0, current.TotalDoses, *due, vaccination.CreatedBy)
+			created = append(created, next)
+		}
+	}
+
+	if next != nil && vaccination.NextDueDate == nil {
+		due := next.DueDate
+		vaccination.NextDueDate = &due
+	}
+
+	return updated, created, nil
+}
+
+// saveSchedule stores the schedule changes of a saved vaccination and the animal's compliance.
+// The changed items are marked with the vaccination so undoSchedule can revert them.
+func (uc *VeterinaryUseCase) saveSchedule(ctx context.Context, vaccination *entities.Vaccination, updated, created []*entities.VaccinationScheduleItem) error {
+	for _, item := range updated {
+		if item.Status == entities.VaccinationScheduleCompleted {
+			item.VaccinationID = &vaccination.ID
+		} else {
+			item.ScheduledBy = &vaccination.ID
+		}
+		if err := uc.scheduleRepo.Update(ctx, item); err != nil {
+			return err
+		}
+	}
+	for _, item := range created {
+		item.ScheduledBy = &vaccination.ID
+	}
+	if err := uc.scheduleRepo.CreateMany(ctx, created); err != nil {
+		return err
+	}
+
+	_, err := uc.syncCompliance(ctx, vaccination.AnimalID)
+	return err
+}
+
+// matchScheduleItem returns the pending dose a vaccination completes: the dose of the series
+// with its dose number, or else the pending booster. Records without a dose number complete
+// the first pending dose of the vaccine.
+func matchScheduleItem(items []*entities.VaccinationScheduleItem, vaccination *entities.Vaccination) *entities.VaccinationScheduleItem {
+	var booster *entities.VaccinationScheduleItem
+	for _, item := range items {
+		if !item.IsPending() || item.VaccineType != vaccination.VaccineType {
+			continue
+		}
+		if vaccination.DoseNumber == 0 {
+			return item
+		}
+		if item.Booster {
+			if booster == nil {
+				booster = item
+			}
+			continue
+		}
+		if item.DoseNumber == vaccination.DoseNumber {
+			return item
+		}
+	}
+	return booster
+}
+
+// undoSchedule reverts what recording a vaccination changed in the schedule: the dose it
+// completed is due again, the following dose gets back its due date and the booster it
+// added is removed. Doses given since are left alone.
+func (uc *VeterinaryUseCase) undoSchedule(ctx context.Context, vaccinationID primitive.ObjectID) error {
+	completed, err := uc.scheduleRepo.FindByVaccinationID(ctx, vaccinationID)
+	if err == errors.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	completed.Status = entities.VaccinationSchedulePending
+	completed.VaccinationID = nil
+	completed.CompletedAt = nil
+	if err := uc.scheduleRepo.Update(ctx, completed); err != nil {
+		return err
+	}
+
+	items, err := uc.scheduleRepo.GetByAnimalID(ctx, completed.AnimalID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.ScheduledBy == nil || *item.ScheduledBy != vaccinationID || !item.IsPending() {
+			continue
+		}
+		if item.PreviousDueDate == nil {
+			if err := uc.scheduleRepo.Delete(ctx, item.ID); err != nil && err != errors.ErrNotFound {
+				return err
+			}
+			continue
+		}
+		item.DueDate = *item.PreviousDueDate
+		item.PreviousDueDate = nil
+		item.ScheduledBy = nil
+		if err := uc.scheduleRepo.Update(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	_, err = uc.syncCompliance(ctx, completed.AnimalID)
+	return err
+}
+
+// syncCompliance recomputes the compliance of an animal and stores it on the animal record.
+// Animals without a schedule keep their manually set vaccinated flag.
+func (uc *VeterinaryUseCase) syncCompliance(ctx context.Context, animalID primitive.ObjectID) (*entities.VaccinationCompliance, error) {
+	items, err := uc.scheduleRepo.GetByAnimalID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	compliance := entities.NewVaccinationCompliance(animalID, items, time.Now())
+	if len(items) == 0 {
+		return compliance, nil
+	}
+
+	if err := uc.animalRepo.UpdateVaccinationStatus(ctx, animalID, compliance.Vaccinated, compliance.Status, compliance.NextDueDate); err != nil {
+		return nil, err
+	}
+
+	return compliance, nil
+}
+
+func newScheduleItem(animalID primitive.ObjectID, protocol *entities.VaccinationProtocol, doseNumber, totalDoses int, due time.Time, userID primitive.ObjectID) *entities.VaccinationScheduleItem {
+	name := protocol.VaccineName
+	if name == "" {
+		name = protocol.Name
+	}
+
+	return &entities.VaccinationScheduleItem{
+		AnimalID:    animalID,
+		ProtocolID:  protocol.ID,
+		VaccineType: protocol.VaccineType,
+		VaccineName: name,
+		DoseNumber:  doseNumber,
+		TotalDoses:  totalDoses,
+		Booster:     doseNumber == 0,
+		DueDate:     due,
+		Status:      entities.VaccinationSchedulePending,
+		CreatedBy:   userID,
+	}
+}
@@ -0,0 +1,328 @@
+package veterinary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const week = 7 * 24 * time.Hour
+
+func dhppProtocol() *entities.VaccinationProtocol {
+	protocol := entities.DefaultVaccinationProtocols()[0]
+	protocol.ID = primitive.NewObjectID()
+	return protocol
+}
+
+func TestVaccinationProtocol_Plan(t *testing.T) {
+	protocol := dhppProtocol()
+	intake := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("puppy before the series follows the age schedule", func(t *testing.T) {
+		dob := intake.Add(-5 * week)
+		dates := protocol.Plan(&dob, intake)
+		assert.Equal(t, []time.Time{dob.Add(8 * week), dob.Add(12 * week), dob.Add(16 * week)}, dates)
+	})
+
+	t.Run("missed doses are replaced by a dose at intake", func(t *testing.T) {
+		dob := intake.Add(-10 * week)
+		dates := protocol.Plan(&dob, intake)
+		assert.Equal(t, []time.Time{intake, intake.Add(3 * week), dob.Add(16 * week)}, dates)
+	})
+
+	t.Run("adults and unknown ages get the adult series", func(t *testing.T) {
+		assert.Equal(t, []time.Time{intake, intake.Add(3 * week)}, protocol.Plan(nil, intake))
+
+		dob := intake.AddDate(-3, 0, 0)
+		assert.Equal(t, []time.Time{intake, intake.Add(3 * week)}, protocol.Plan(&dob, intake))
+	})
+
+	t.Run("minimum age delays the first dose", func(t *testing.T) {
+		rabies := entities.DefaultVaccinationProtocols()[1]
+		dob := intake.Add(-4 * week)
+		assert.Equal(t, []time.Time{dob.Add(12 * week)}, rabies.Plan(&dob, intake))
+	})
+}
+
+func TestVeterinaryUseCase_ScheduleIntakeVaccinations(t *testing.T) {
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockProtocolRepo := new(mocks.VaccinationProtocolRepository)
+	mockScheduleRepo := new(mocks.VaccinationScheduleRepository)
+	useCase := NewVeterinaryUseCase(nil, nil, mockAnimalRepo, nil, mockProtocolRepo, mockScheduleRepo, nil, nil)
+
+	ctx := context.Background()
+	protocol := dhppProtocol()
+	animal := &entities.Animal{
+		ID:      primitive.NewObjectID(),
+		Species: "Dog",
+		Shelter: entities.ShelterInfo{IntakeDate: time.Now()},
+	}
+
+	active := true
+	mockProtocolRepo.On("List", ctx, repositories.VaccinationProtocolFilter{Species: "Dog", Active: &active}).
+		Return([]*entities.VaccinationProtocol{protocol}, nil)
+	mockScheduleRepo.On("GetByAnimalID", ctx, animal.ID).Return([]*entities.VaccinationScheduleItem{}, nil).Once()
+
+	var created []*entities.VaccinationScheduleItem
+	mockScheduleRepo.On("CreateMany", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).([]*entities.VaccinationScheduleItem)
+	}).Return(nil)
+	stored := mockScheduleRepo.On("GetByAnimalID", ctx, animal.ID)
+	stored.Run(func(mock.Arguments) { stored.Return(created, nil) })
+	mockAnimalRepo.On("UpdateVaccinationStatus", ctx, animal.ID, false, entities.VaccinationStatusDue, mock.AnythingOfType("*time.Time")).Return(nil)
+
+	err := useCase.ScheduleIntakeVaccinations(ctx, animal, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, created, 2)
+	assert.Equal(t, 1, created[0].DoseNumber)
+	assert.Equal(t, 2, created[1].TotalDoses)
+	assert.Equal(t, entities.VaccineDHPP, created[0].VaccineType)
+	assert.Equal(t, entities.VaccinationSchedulePending, created[1].Status)
+	mockAnimalRepo.AssertExpectations(t)
+}
+
+func TestVeterinaryUseCase_CreateVaccinationAdvancesSeries(t *testing.T) {
+	ctx := context.Background()
+	protocol := dhppProtocol()
+	animalID := primitive.NewObjectID()
+	given := time.Now().Add(-24 * time.Hour)
+
+	t.Run("dose completes the scheduled item and moves the next one", func(t *testing.T) {
+		mockAnimalRepo := new(mocks.AnimalRepository)
+		mockVaccinationRepo := new(mocks.VaccinationRepository)
+		mockAuditLogRepo := new(mocks.AuditLogRepository)
+		mockProtocolRepo := new(mocks.VaccinationProtocolRepository)
+		mockScheduleRepo := new(mocks.VaccinationScheduleRepository)
+		useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, mockAuditLogRepo, mockProtocolRepo, mockScheduleRepo, nil, nil)
+
+		first := newScheduleItem(animalID, protocol, 1, 2, given, primitive.NilObjectID)
+		second := newScheduleItem(animalID, protocol, 2, 2, given.Add(week), primitive.NilObjectID)
+		items := []*entities.VaccinationScheduleItem{first, second}
+
+		mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+		mockScheduleRepo.On("GetByAnimalID", ctx, animalID).Return(items, nil)
+		mockProtocolRepo.On("FindByID", ctx, protocol.ID).Return(protocol, nil)
+		mockVaccinationRepo.On("Create", ctx, mock.AnythingOfType("*entities.Vaccination")).Run(func(args mock.Arguments) {
+			args.Get(1).(*entities.Vaccination).ID = primitive.NewObjectID()
+		}).Return(nil)
+		mockScheduleRepo.On("Update", ctx, mock.Anything).Return(nil)
+		mockScheduleRepo.On("CreateMany", ctx, mock.Anything).Return(nil)
+		mockAnimalRepo.On("UpdateVaccinationStatus", ctx, animalID, true, entities.VaccinationStatusDue, mock.AnythingOfType("*time.Time")).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		vaccination, err := useCase.CreateVaccination(ctx, &CreateVaccinationRequest{
+			AnimalID:         animalID.Hex(),
+			VaccineType:      entities.VaccineDHPP,
+			VaccineName:      "Nobivac DHPPi",
+			DoseNumber:       1,
+			DateAdministered: given,
+			VeterinarianName: "Dr. Nowak",
+		}, primitive.NewObjectID())
+		require.NoError(t, err)
+
+		assert.Equal(t, entities.VaccinationScheduleCompleted, first.Status)
+		assert.Equal(t, &vaccination.ID, first.VaccinationID)
+		assert.Equal(t, given.Add(3*week), second.DueDate)
+		assert.Equal(t, 2, vaccination.TotalDoses)
+		require.NotNil(t, vaccination.NextDueDate)
+		assert.Equal(t, second.DueDate, *vaccination.NextDueDate)
+		mockAnimalRepo.AssertExpectations(t)
+	})
+
+	t.Run("last dose schedules the booster", func(t *testing.T) {
+		mockAnimalRepo := new(mocks.AnimalRepository)
+		mockVaccinationRepo := new(mocks.VaccinationRepository)
+		mockAuditLogRepo := new(mocks.AuditLogRepository)
+		mockProtocolRepo := new(mocks.VaccinationProtocolRepository)
+		mockScheduleRepo := new(mocks.VaccinationScheduleRepository)
+		useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, mockAuditLogRepo, mockProtocolRepo, mockScheduleRepo, nil, nil)
+
+		last := newScheduleItem(animalID, protocol, 1, 1, given, primitive.NilObjectID)
+		items := []*entities.VaccinationScheduleItem{last}
+
+		var booster *entities.VaccinationScheduleItem
+		mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+		stored := mockScheduleRepo.On("GetByAnimalID", ctx, animalID).Return(items, nil)
+		stored.Run(func(mock.Arguments) {
+			if booster != nil {
+				stored.Return(append(items, booster), nil)
+			}
+		})
+		mockProtocolRepo.On("FindByID", ctx, protocol.ID).Return(protocol, nil)
+		mockVaccinationRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockScheduleRepo.On("Update", ctx, last).Return(nil)
+		mockScheduleRepo.On("CreateMany", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			booster = args.Get(1).([]*entities.VaccinationScheduleItem)[0]
+		}).Return(nil)
+		mockAnimalRepo.On("UpdateVaccinationStatus", ctx, animalID, true, entities.VaccinationStatusCurrent, mock.AnythingOfType("*time.Time")).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		vaccination, err := useCase.CreateVaccination(ctx, &CreateVaccinationRequest{
+			AnimalID:         animalID.Hex(),
+			VaccineType:      entities.VaccineDHPP,
+			VaccineName:      "Nobivac DHPPi",
+			DoseNumber:       1,
+			DateAdministered: given,
+			VeterinarianName: "Dr. Nowak",
+		}, primitive.NewObjectID())
+		require.NoError(t, err)
+
+		require.NotNil(t, booster)
+		assert.True(t, booster.Booster)
+		assert.Equal(t, given.AddDate(1, 0, 0), booster.DueDate)
+		assert.Equal(t, booster.DueDate, *vaccination.NextDueDate)
+		mockAnimalRepo.AssertExpectations(t)
+	})
+
+	t.Run("dose number picks its own dose and leaves the booster for later", func(t *testing.T) {
+		mockAnimalRepo := new(mocks.AnimalRepository)
+		mockVaccinationRepo := new(mocks.VaccinationRepository)
+		mockAuditLogRepo := new(mocks.AuditLogRepository)
+		mockProtocolRepo := new(mocks.VaccinationProtocolRepository)
+		mockScheduleRepo := new(mocks.VaccinationScheduleRepository)
+		useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, mockAuditLogRepo, mockProtocolRepo, mockScheduleRepo, nil, nil)
+
+		first := newScheduleItem(animalID, protocol, 1, 2, given, primitive.NilObjectID)
+		second := newScheduleItem(animalID, protocol, 2, 2, given.Add(week), primitive.NilObjectID)
+		items := []*entities.VaccinationScheduleItem{first, second}
+
+		mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+		mockScheduleRepo.On("GetByAnimalID", ctx, animalID).Return(items, nil)
+		mockProtocolRepo.On("FindByID", ctx, protocol.ID).Return(protocol, nil)
+		mockVaccinationRepo.On("Create", ctx, mock.Anything).Return(nil)
+		mockScheduleRepo.On("Update", ctx, second).Return(nil)
+		mockScheduleRepo.On("CreateMany", ctx, mock.Anything).Return(nil)
+		mockAnimalRepo.On("UpdateVaccinationStatus", ctx, animalID, false, mock.Anything, mock.Anything).Return(nil)
+		mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+		_, err := useCase.CreateVaccination(ctx, &CreateVaccinationRequest{
+			AnimalID:         animalID.Hex(),
+			VaccineType:      entities.VaccineDHPP,
+			VaccineName:      "Nobivac DHPPi",
+			DoseNumber:       2,
+			DateAdministered: given,
+			VeterinarianName: "Dr. Nowak",
+		}, primitive.NewObjectID())
+		require.NoError(t, err)
+
+		assert.Equal(t, entities.VaccinationScheduleCompleted, second.Status)
+		assert.True(t, first.IsPending(), "the first dose is still missing")
+		mockScheduleRepo.AssertNotCalled(t, "CreateMany", mock.Anything, mock.MatchedBy(func(items []*entities.VaccinationScheduleItem) bool {
+			return len(items) > 0
+		}))
+	})
+
+	t.Run("a schedule that cannot be saved removes the vaccination again", func(t *testing.T) {
+		mockAnimalRepo := new(mocks.AnimalRepository)
+		mockVaccinationRepo := new(mocks.VaccinationRepository)
+		mockProtocolRepo := new(mocks.VaccinationProtocolRepository)
+		mockScheduleRepo := new(mocks.VaccinationScheduleRepository)
+		useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, new(mocks.AuditLogRepository), mockProtocolRepo, mockScheduleRepo, nil, nil)
+
+		last := newScheduleItem(animalID, protocol, 1, 1, given, primitive.NilObjectID)
+
+		var vaccinationID primitive.ObjectID
+		mockAnimalRepo.On("FindByID", ctx, animalID).Return(&entities.Animal{ID: animalID}, nil)
+		mockScheduleRepo.On("GetByAnimalID", ctx, animalID).Return([]*entities.VaccinationScheduleItem{last}, nil)
+		mockProtocolRepo.On("FindByID", ctx, protocol.ID).Return(protocol, nil)
+		mockVaccinationRepo.On("Create", ctx, mock.AnythingOfType("*entities.Vaccination")).Run(func(args mock.Arguments) {
+			vaccinationID = primitive.NewObjectID()
+			args.Get(1).(*entities.Vaccination).ID = vaccinationID
+		}).Return(nil)
+		mockScheduleRepo.On("Update", ctx, last).Return(nil)
+		mockScheduleRepo.On("CreateMany", ctx, mock.Anything).Return(errors.NewInternalServer("write failed"))
+		mockScheduleRepo.On("FindByVaccinationID", ctx, mock.Anything).Return(last, nil)
+		mockAnimalRepo.On("UpdateVaccinationStatus", ctx, animalID, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockVaccinationRepo.On("Delete", ctx, mock.Anything).Return(nil)
+
+		_, err := useCase.CreateVaccination(ctx, &CreateVaccinationRequest{
+			AnimalID:         animalID.Hex(),
+			VaccineType:      entities.VaccineDHPP,
+			VaccineName:      "Nobivac DHPPi",
+			DoseNumber:       1,
+			DateAdministered: given,
+			VeterinarianName: "Dr. Nowak",
+		}, primitive.NewObjectID())
+
+		assert.Error(t, err)
+		assert.True(t, last.IsPending(), "the dose is due again")
+		assert.Nil(t, last.VaccinationID)
+		mockVaccinationRepo.AssertCalled(t, "Delete", ctx, vaccinationID)
+	})
+}
+
+func TestVeterinaryUseCase_DeleteVaccinationUndoesTheSchedule(t *testing.T) {
+	ctx := context.Background()
+	protocol := dhppProtocol()
+	animalID := primitive.NewObjectID()
+	vaccinationID := primitive.NewObjectID()
+	given := time.Now().Add(-24 * time.Hour)
+
+	mockAnimalRepo := new(mocks.AnimalRepository)
+	mockVaccinationRepo := new(mocks.VaccinationRepository)
+	mockAuditLogRepo := new(mocks.AuditLogRepository)
+	mockScheduleRepo := new(mocks.VaccinationScheduleRepository)
+	useCase := NewVeterinaryUseCase(nil, mockVaccinationRepo, mockAnimalRepo, mockAuditLogRepo, nil, mockScheduleRepo, nil, nil)
+
+	completed := newScheduleItem(animalID, protocol, 1, 2, given, primitive.NilObjectID)
+	completed.Status = entities.VaccinationScheduleCompleted
+	completed.VaccinationID = &vaccinationID
+	completed.CompletedAt = &given
+	originalDue := given.Add(week)
+	moved := newScheduleItem(animalID, protocol, 2, 2, given.Add(3*week), primitive.NilObjectID)
+	moved.ScheduledBy = &vaccinationID
+	moved.PreviousDueDate = &originalDue
+	booster := newScheduleItem(animalID, protocol, 0, 2, given.AddDate(1, 0, 0), primitive.NilObjectID)
+	booster.ID = primitive.NewObjectID()
+	booster.ScheduledBy = &vaccinationID
+	other := newScheduleItem(animalID, protocol, 0, 2, given.AddDate(1, 0, 0), primitive.NilObjectID)
+
+	mockVaccinationRepo.On("FindByID", ctx, vaccinationID).Return(&entities.Vaccination{ID: vaccinationID, AnimalID: animalID}, nil)
+	mockVaccinationRepo.On("Delete", ctx, vaccinationID).Return(nil)
+	mockScheduleRepo.On("FindByVaccinationID", ctx, vaccinationID).Return(completed, nil)
+	mockScheduleRepo.On("GetByAnimalID", ctx, animalID).Return([]*entities.VaccinationScheduleItem{completed, moved, booster, other}, nil)
+	mockScheduleRepo.On("Update", ctx, mock.Anything).Return(nil)
+	mockScheduleRepo.On("Delete", ctx, booster.ID).Return(nil)
+	mockAnimalRepo.On("UpdateVaccinationStatus", ctx, animalID, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockAuditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	require.NoError(t, useCase.DeleteVaccination(ctx, vaccinationID, primitive.NewObjectID()))
+
+	assert.True(t, completed.IsPending())
+	assert.Nil(t, completed.VaccinationID)
+	assert.Equal(t, originalDue, moved.DueDate, "the next dose gets its due date back")
+	assert.Nil(t, moved.ScheduledBy)
+	mockScheduleRepo.AssertCalled(t, "Delete", ctx, booster.ID)
+	mockScheduleRepo.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestVaccinationCompliance(t *testing.T) {
+	now := time.Now()
+	animalID := primitive.NewObjectID()
+	protocol := dhppProtocol()
+
+	completed := newScheduleItem(animalID, protocol, 1, 2, now.AddDate(0, -2, 0), primitive.NilObjectID)
+	completed.Status = entities.VaccinationScheduleCompleted
+	overdue := newScheduleItem(animalID, protocol, 2, 2, now.AddDate(0, 0, -3), primitive.NilObjectID)
+
+	compliance := entities.NewVaccinationCompliance(animalID, []*entities.VaccinationScheduleItem{completed, overdue}, now)
+	assert.Equal(t, entities.VaccinationStatusOverdue, compliance.Status)
+	assert.False(t, compliance.Vaccinated)
+	assert.Equal(t, 1, compliance.OverdueCount)
+
+	overdue.DueDate = now.AddDate(0, 3, 0)
+	compliance = entities.NewVaccinationCompliance(animalID, []*entities.VaccinationScheduleItem{completed, overdue}, now)
+	assert.Equal(t, entities.VaccinationStatusCurrent, compliance.Status)
+	assert.True(t, compliance.Vaccinated)
+	assert.Equal(t, overdue.DueDate, *compliance.NextDueDate)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/sainaif/animalsys/backend/internal/domain/entities"
 	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
 	"github.com/sainaif/animalsys/backend/pkg/errors"
@@ -26,6 +27,14 @@ type VeterinaryUseCaseInterface interface {
 	GetDueVaccinations(ctx context.Context, days int) ([]*entities.Vaccination, error)
 	GetUpcomingVisits(ctx context.Context, days int) ([]*entities.VeterinaryVisit, error)
 	ListVeterinaryRecords(ctx context.Context, req *ListRecordsRequest) ([]*entities.VeterinaryRecord, int64, error)
+	CreateProtocol(ctx context.Context, req *CreateProtocolRequest, creatorID primitive.ObjectID) (*entities.VaccinationProtocol, error)
+	GetProtocolByID(ctx context.Context, id primitive.ObjectID) (*entities.VaccinationProtocol, error)
+	UpdateProtocol(ctx context.Context, id primitive.ObjectID, req *UpdateProtocolRequest, updaterID primitive.ObjectID) (*entities.VaccinationProtocol, error)
+	DeleteProtocol(ctx context.Context, id primitive.ObjectID, deleterID primitive.ObjectID) error
+	ListProtocols(ctx context.Context, req *ListProtocolsRequest) ([]*entities.VaccinationProtocol, error)
+	GetVaccinationCompliance(ctx context.Context, animalID primitive.ObjectID) (*entities.VaccinationCompliance, error)
+	RegenerateVaccinationSchedule(ctx context.Context, animalID primitive.ObjectID, userID primitive.ObjectID) (*entities.VaccinationCompliance, error)
+	GetScheduledVaccinations(ctx context.Context, days int) ([]*entities.VaccinationScheduleItem, error)
 }
 
 // VeterinaryUseCase handles veterinary business logic
@@ -34,6 +43,10 @@ type VeterinaryUseCase struct {
 	vaccinationRepo  repositories.VaccinationRepository
 	animalRepo       repositories.AnimalRepository
 	auditLogRepo     repositories.AuditLogRepository
+	protocolRepo     repositories.VaccinationProtocolRepository
+	scheduleRepo     repositories.VaccinationScheduleRepository
+	stock            StockConsumer
+	vitals           VitalsRecorder
 }
 
 // NewVeterinaryUseCase creates a new veterinary use case
@@ -42,12 +55,20 @@ func NewVeterinaryUseCase(
 	vaccinationRepo repositories.VaccinationRepository,
 	animalRepo repositories.AnimalRepository,
 	auditLogRepo repositories.AuditLogRepository,
+	protocolRepo repositories.VaccinationProtocolRepository,
+	scheduleRepo repositories.VaccinationScheduleRepository,
+	stock StockConsumer,
+	vitals VitalsRecorder,
 ) *VeterinaryUseCase {
 	return &VeterinaryUseCase{
 		visitRepo:       visitRepo,
 		vaccinationRepo: vaccinationRepo,
 		animalRepo:      animalRepo,
 		auditLogRepo:    auditLogRepo,
+		protocolRepo:    protocolRepo,
+		scheduleRepo:    scheduleRepo,
+		stock:           stock,
+		vitals:          vitals,
 	}
 }
 
@@ -97,6 +118,7 @@ type CreateVaccinationRequest struct {
 	VaccineName      string                     `json:"vaccine_name" validate:"required"`
 	Manufacturer     string                     `json:"manufacturer,omitempty"`
 	LotNumber        string                     `json:"lot_number,omitempty"`
+	InventoryItemID  string                     `json:"inventory_item_id,omitempty"`
 	DoseNumber       int                        `json:"dose_number" validate:"required,min=1"`
 	TotalDoses       int                        `json:"total_doses,omitempty"`
 	DateAdministered time.Time                  `json:"date_administered" validate:"required"`
@@ -186,10 +208,21 @@ func (uc *VeterinaryUseCase) CreateVisit(ctx context.Context, req *CreateVisitRe
 		UpdatedBy:         creatorID,
 	}
 
+	carryOverDispensing(nil, visit.Prescriptions)
 	if err := uc.visitRepo.Create(ctx, visit); err != nil {
 		return nil, err
 	}
 
+	// Stock is only taken out for a saved visit; without the dispensing the visit is removed again
+	if err := uc.saveDispensing(ctx, visit, creatorID); err != nil {
+		if deleteErr := uc.visitRepo.Delete(ctx, visit.ID); deleteErr != nil {
+			log.Error().Err(deleteErr).Str("visit_id", visit.ID.Hex()).Msg("failed to remove visit whose prescriptions were not dispensed")
+		}
+		return nil, err
+	}
+
+	uc.recordVisitVitals(ctx, visit, creatorID)
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(creatorID, entities.ActionCreate, "veterinary_visit", "", "").
 		WithEntityID(visit.ID)
@@ -210,6 +243,8 @@ func (uc *VeterinaryUseCase) UpdateVisit(ctx context.Context, id primitive.Objec
 		return nil, err
 	}
 
+	previous := *visit
+
 	// Track changes
 	changes := make(map[string]interface{})
 
@@ -230,6 +265,7 @@ func (uc *VeterinaryUseCase) UpdateVisit(ctx context.Context, id primitive.Objec
 		visit.Treatment = *req.Treatment
 	}
 	if req.Prescriptions != nil {
+		carryOverDispensing(visit.Prescriptions, *req.Prescriptions)
 		visit.Prescriptions = *req.Prescriptions
 	}
 	if req.TestResults != nil {
@@ -254,6 +290,20 @@ func (uc *VeterinaryUseCase) UpdateVisit(ctx context.Context, id primitive.Objec
 		return nil, err
 	}
 
+	// New prescriptions are dispensed once the update is saved; if that fails the update is undone
+	if req.Prescriptions != nil {
+		if err := uc.saveDispensing(ctx, visit, updaterID); err != nil {
+			if restoreErr := uc.visitRepo.Update(ctx, &previous); restoreErr != nil {
+				log.Error().Err(restoreErr).Str("visit_id", id.Hex()).Msg("failed to restore visit whose prescriptions were not dispensed")
+			}
+			return nil, err
+		}
+	}
+
+	if req.VitalSigns != nil {
+		uc.recordVisitVitals(ctx, visit, updaterID)
+	}
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(updaterID, entities.ActionUpdate, "veterinary_visit", "", "").
 		WithEntityID(id).
@@ -328,12 +378,22 @@ func (uc *VeterinaryUseCase) CreateVaccination(ctx context.Context, req *CreateV
 		return nil, err
 	}
 
+	var inventoryItemID *primitive.ObjectID
+	if req.InventoryItemID != "" {
+		itemID, err := primitive.ObjectIDFromHex(req.InventoryItemID)
+		if err != nil {
+			return nil, errors.NewBadRequest("invalid inventory item ID")
+		}
+		inventoryItemID = &itemID
+	}
+
 	vaccination := &entities.Vaccination{
 		AnimalID:         animalID,
 		VaccineType:      req.VaccineType,
 		VaccineName:      req.VaccineName,
 		Manufacturer:     req.Manufacturer,
 		LotNumber:        req.LotNumber,
+		InventoryItemID:  inventoryItemID,
 		DoseNumber:       req.DoseNumber,
 		TotalDoses:       req.TotalDoses,
 		DateAdministered: req.DateAdministered,
@@ -347,10 +407,34 @@ func (uc *VeterinaryUseCase) CreateVaccination(ctx context.Context, req *CreateV
 		UpdatedBy:        creatorID,
 	}
 
+	// Advance the protocol series this dose belongs to
+	var updatedItems, createdItems []*entities.VaccinationScheduleItem
+	if uc.scheduleRepo != nil {
+		updatedItems, createdItems, err = uc.advanceSchedule(ctx, vaccination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err := uc.vaccinationRepo.Create(ctx, vaccination); err != nil {
 		return nil, err
 	}
 
+	// The dose is only taken out of stock for a saved vaccination; without it the record is removed again
+	if err := uc.saveVaccineLot(ctx, vaccination, creatorID); err != nil {
+		if deleteErr := uc.vaccinationRepo.Delete(ctx, vaccination.ID); deleteErr != nil {
+			log.Error().Err(deleteErr).Str("vaccination_id", vaccination.ID.Hex()).Msg("failed to remove vaccination whose dose was not taken from stock")
+		}
+		return nil, err
+	}
+
+	if len(updatedItems) > 0 || len(createdItems) > 0 {
+		if err := uc.saveSchedule(ctx, vaccination, updatedItems, createdItems); err != nil {
+			uc.discardVaccination(ctx, vaccination, creatorID)
+			return nil, err
+		}
+	}
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(creatorID, entities.ActionCreate, "vaccination", "", "").
 		WithEntityID(vaccination.ID)
@@ -359,6 +443,18 @@ func (uc *VeterinaryUseCase) CreateVaccination(ctx context.Context, req *CreateV
 	return vaccination, nil
 }
 
+// discardVaccination removes a stored vaccination whose schedule changes could not be saved,
+// together with the schedule changes that were, and puts its dose back into stock
+func (uc *VeterinaryUseCase) discardVaccination(ctx context.Context, vaccination *entities.Vaccination, userID primitive.ObjectID) {
+	if err := uc.undoSchedule(ctx, vaccination.ID); err != nil {
+		log.Error().Err(err).Str("vaccination_id", vaccination.ID.Hex()).Msg("failed to undo the schedule changes of a vaccination that was not saved")
+	}
+	if err := uc.vaccinationRepo.Delete(ctx, vaccination.ID); err != nil {
+		log.Error().Err(err).Str("vaccination_id", vaccination.ID.Hex()).Msg("failed to remove vaccination whose schedule was not saved")
+	}
+	uc.returnVaccineDose(ctx, vaccination, userID, "vaccination "+vaccination.ID.Hex()+" was not saved")
+}
+
 // GetVaccinationByID retrieves a vaccination by ID
 func (uc *VeterinaryUseCase) GetVaccinationByID(ctx context.Context, id primitive.ObjectID) (*entities.Vaccination, error) {
 	return uc.vaccinationRepo.FindByID(ctx, id)
@@ -366,7 +462,8 @@ func (uc *VeterinaryUseCase) GetVaccinationByID(ctx context.Context, id primitiv
 
 // DeleteVaccination deletes a vaccination
 func (uc *VeterinaryUseCase) DeleteVaccination(ctx context.Context, id primitive.ObjectID, deleterID primitive.ObjectID) error {
-	if _, err := uc.vaccinationRepo.FindByID(ctx, id); err != nil {
+	vaccination, err := uc.vaccinationRepo.FindByID(ctx, id)
+	if err != nil {
 		return err
 	}
 
@@ -374,6 +471,16 @@ func (uc *VeterinaryUseCase) DeleteVaccination(ctx context.Context, id primitive
 		return err
 	}
 
+	// The dose was never given, so it is back in stock
+	uc.returnVaccineDose(ctx, vaccination, deleterID, "vaccination "+id.Hex()+" was deleted")
+
+	// The scheduled dose this record completed is due again
+	if uc.scheduleRepo != nil {
+		if err := uc.undoSchedule(ctx, id); err != nil {
+			return err
+		}
+	}
+
 	// Create audit log
 	auditLog := entities.NewAuditLog(deleterID, entities.ActionDelete, "vaccination", "", "").
 		WithEntityID(id)
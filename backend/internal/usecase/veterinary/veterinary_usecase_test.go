@@ -16,7 +16,7 @@ func TestVeterinaryUseCase_ListVeterinaryRecords(t *testing.T) {
 	mockVisitRepo := new(mocks.VeterinaryVisitRepository)
 	mockVaccinationRepo := new(mocks.VaccinationRepository)
 
-	useCase := NewVeterinaryUseCase(mockVisitRepo, mockVaccinationRepo, nil, nil)
+	useCase := NewVeterinaryUseCase(mockVisitRepo, mockVaccinationRepo, nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	animalID := primitive.NewObjectID()
@@ -0,0 +1,28 @@
+package veterinary
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VitalsRecorder adds the weight and vitals measured at a visit to the animal's time series
+type VitalsRecorder interface {
+	RecordVitals(ctx context.Context, animalID primitive.ObjectID, vitals entities.VitalSigns, source entities.VitalSource, sourceID *primitive.ObjectID, recordedAt time.Time, userID primitive.ObjectID) (*entities.VitalSignReading, error)
+}
+
+// recordVisitVitals stores the vital signs of a visit as a reading of the
+// animal. A missing reading only leaves a gap in the weight and vitals charts,
+// which shouldn't fail the visit, so it is logged instead.
+func (uc *VeterinaryUseCase) recordVisitVitals(ctx context.Context, visit *entities.VeterinaryVisit, userID primitive.ObjectID) {
+	if uc.vitals == nil {
+		return
+	}
+
+	if _, err := uc.vitals.RecordVitals(ctx, visit.AnimalID, visit.VitalSigns, entities.VitalSourceVisit, &visit.ID, visit.VisitDate, userID); err != nil {
+		log.Error().Err(err).Str("visit_id", visit.ID.Hex()).Msg("failed to record the vital signs of a visit")
+	}
+}
@@ -0,0 +1,586 @@
+package vitals
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notifier delivers in-app notifications
+type Notifier interface {
+	CreateNotification(ctx context.Context, notification *entities.Notification) error
+}
+
+// VisitLister finds the veterinary visits of an animal
+type VisitLister interface {
+	List(ctx context.Context, filter repositories.VeterinaryVisitFilter) ([]*entities.VeterinaryVisit, int64, error)
+}
+
+// VitalsUseCase tracks weight and vital signs over time and raises alerts
+type VitalsUseCase struct {
+	vitalRepo         repositories.VitalSignRepository
+	animalRepo        repositories.AnimalRepository
+	taskRepo          repositories.TaskRepository
+	userRepo          repositories.UserRepository
+	visitRepo         VisitLister
+	volunteerRepo     repositories.VolunteerRepository
+	assignmentRepo    repositories.VolunteerAssignmentRepository
+	auditLogRepo      repositories.AuditLogRepository
+	notifier          Notifier
+	weightLossPercent float64       // a weight drop of this many percent raises an alert
+	weightLossWindow  time.Duration // the drop is measured from the highest weight in this window
+}
+
+// NewVitalsUseCase creates a new vitals use case
+func NewVitalsUseCase(
+	vitalRepo repositories.VitalSignRepository,
+	animalRepo repositories.AnimalRepository,
+	taskRepo repositories.TaskRepository,
+	userRepo repositories.UserRepository,
+	visitRepo VisitLister,
+	volunteerRepo repositories.VolunteerRepository,
+	assignmentRepo repositories.VolunteerAssignmentRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	notifier Notifier,
+	weightLossPercent float64,
+	weightLossWindow time.Duration,
+) *VitalsUseCase {
+	return &VitalsUseCase{
+		vitalRepo:         vitalRepo,
+		animalRepo:        animalRepo,
+		taskRepo:          taskRepo,
+		userRepo:          userRepo,
+		visitRepo:         visitRepo,
+		volunteerRepo:     volunteerRepo,
+		assignmentRepo:    assignmentRepo,
+		auditLogRepo:      auditLogRepo,
+		notifier:          notifier,
+		weightLossPercent: weightLossPercent,
+		weightLossWindow:  weightLossWindow,
+	}
+}
+
+// RecordReadingRequest represents a request to record weight and vitals
+type RecordReadingRequest struct {
+	RecordedAt      *time.Time `json:"recorded_at,omitempty"`
+	Weight          float64    `json:"weight,omitempty" validate:"omitempty,gt=0"`
+	Temperature     float64    `json:"temperature,omitempty" validate:"omitempty,gt=0"`
+	HeartRate       int        `json:"heart_rate,omitempty" validate:"omitempty,gt=0"`
+	RespiratoryRate int        `json:"respiratory_rate,omitempty" validate:"omitempty,gt=0"`
+	Notes           string     `json:"notes,omitempty"`
+}
+
+func (r *RecordReadingRequest) vitalSigns() entities.VitalSigns {
+	return entities.VitalSigns{
+		Weight:          r.Weight,
+		Temperature:     r.Temperature,
+		HeartRate:       r.HeartRate,
+		RespiratoryRate: r.RespiratoryRate,
+	}
+}
+
+func (r *RecordReadingRequest) recordedAt() time.Time {
+	if r.RecordedAt != nil {
+		return *r.RecordedAt
+	}
+	return time.Now()
+}
+
+// RecordReading records weight and vitals entered directly by staff
+func (uc *VitalsUseCase) RecordReading(ctx context.Context, animalID primitive.ObjectID, req *RecordReadingRequest, userID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	return uc.recordRequest(ctx, animalID, req, entities.VitalSourceManual, nil, userID)
+}
+
+// RecordFosterCheckIn records weight and vitals reported by the foster of an animal.
+// Only volunteers with an active fostering assignment for the animal may check in.
+func (uc *VitalsUseCase) RecordFosterCheckIn(ctx context.Context, animalID primitive.ObjectID, req *RecordReadingRequest, userID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	assignment, err := uc.findFosterAssignment(ctx, animalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.recordRequest(ctx, animalID, req, entities.VitalSourceFosterCheckIn, &assignment.ID, userID)
+}
+
+// RecordVitals stores the vitals captured by another record, such as a
+// veterinary visit or a daily care note. A record that is saved again updates
+// its reading. Records without weight or vitals are ignored.
+func (uc *VitalsUseCase) RecordVitals(ctx context.Context, animalID primitive.ObjectID, vitals entities.VitalSigns, source entities.VitalSource, sourceID *primitive.ObjectID, recordedAt time.Time, userID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	reading := entities.NewVitalSignReading(animal, vitals, source, recordedAt, userID)
+	reading.SourceID = sourceID
+
+	var previous *entities.VitalSignReading
+	if sourceID != nil {
+		previous, err = uc.vitalRepo.FindBySource(ctx, source, *sourceID)
+		if err != nil && err != errors.ErrNotFound {
+			return nil, err
+		}
+	}
+	if previous == nil && !reading.HasValues() {
+		return nil, nil
+	}
+
+	return uc.save(ctx, animal, reading, previous, userID)
+}
+
+func (uc *VitalsUseCase) recordRequest(ctx context.Context, animalID primitive.ObjectID, req *RecordReadingRequest, source entities.VitalSource, sourceID *primitive.ObjectID, userID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	reading := entities.NewVitalSignReading(animal, req.vitalSigns(), source, req.recordedAt(), userID)
+	reading.SourceID = sourceID
+	reading.Notes = req.Notes
+	if !reading.HasValues() {
+		return nil, errors.NewBadRequest("at least one of weight, temperature, heart rate or respiratory rate is required")
+	}
+	if reading.RecordedAt.After(time.Now().Add(time.Hour)) {
+		return nil, errors.NewBadRequest("recorded_at cannot be in the future")
+	}
+
+	return uc.save(ctx, animal, reading, nil, userID)
+}
+
+// save evaluates the alert rules, stores the reading and follows up on it.
+// previous is the stored reading of the same source record, if any.
+func (uc *VitalsUseCase) save(ctx context.Context, animal *entities.Animal, reading, previous *entities.VitalSignReading, userID primitive.ObjectID) (*entities.VitalSignReading, error) {
+	if previous != nil {
+		reading.ID = previous.ID
+		reading.CreatedAt = previous.CreatedAt
+	}
+
+	alerts, err := uc.evaluate(ctx, reading)
+	if err != nil {
+		return nil, err
+	}
+	reading.Alerts = alerts
+
+	action := entities.ActionCreate
+	if previous != nil {
+		action = entities.ActionUpdate
+		err = uc.vitalRepo.Update(ctx, reading)
+	} else {
+		err = uc.vitalRepo.Create(ctx, reading)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reading.Weight > 0 {
+		uc.syncAnimalWeight(ctx, animal, reading)
+	}
+
+	for _, alert := range newAlerts(reading.Alerts, previous) {
+		uc.raiseAlert(ctx, animal, reading, alert, userID)
+	}
+
+	if uc.auditLogRepo != nil {
+		_ = uc.auditLogRepo.Create(ctx, &entities.AuditLog{
+			UserID:     userID,
+			Action:     action,
+			EntityType: "vital_sign_reading",
+			EntityID:   &reading.ID,
+			Changes: map[string]interface{}{
+				"animal_id": animal.ID.Hex(),
+				"source":    reading.Source,
+				"alerts":    len(reading.Alerts),
+			},
+		})
+	}
+
+	return reading, nil
+}
+
+// evaluate applies the alert rules to a reading
+func (uc *VitalsUseCase) evaluate(ctx context.Context, reading *entities.VitalSignReading) ([]entities.VitalAlert, error) {
+	var alerts []entities.VitalAlert
+
+	if reference := entities.GetVitalReferenceRange(reading.Species); reference != nil {
+		for _, metric := range []entities.VitalMetric{entities.VitalMetricTemperature, entities.VitalMetricHeartRate, entities.VitalMetricRespiratoryRate} {
+			value, measured := reading.Value(metric)
+			rng, _ := reference.Range(metric)
+			if !measured || rng.Contains(value) {
+				continue
+			}
+			alerts = append(alerts, entities.VitalAlert{
+				Rule:    entities.VitalAlertOutOfRange,
+				Metric:  metric,
+				Value:   value,
+				Message: fmt.Sprintf("%s %g is outside the normal range %g-%g for a %s", metricLabel(metric), value, rng.Min, rng.Max, reference.Species),
+			})
+		}
+	}
+
+	if reading.Weight > 0 {
+		alert, err := uc.checkWeightLoss(ctx, reading)
+		if err != nil {
+			return nil, err
+		}
+		if alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+// checkWeightLoss compares a weight with the highest weight recorded in the
+// window before it
+func (uc *VitalsUseCase) checkWeightLoss(ctx context.Context, reading *entities.VitalSignReading) (*entities.VitalAlert, error) {
+	from := reading.RecordedAt.Add(-uc.weightLossWindow)
+	to := reading.RecordedAt
+	earlier, _, err := uc.vitalRepo.List(ctx, &repositories.VitalSignFilter{
+		AnimalID: &reading.AnimalID,
+		Metric:   string(entities.VitalMetricWeight),
+		From:     &from,
+		To:       &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var peak *entities.VitalSignReading
+	for _, other := range earlier {
+		if other.ID == reading.ID || !other.RecordedAt.Before(reading.RecordedAt) {
+			continue
+		}
+		if peak == nil || other.Weight > peak.Weight {
+			peak = other
+		}
+	}
+	if peak == nil {
+		return nil, nil
+	}
+
+	lost := (peak.Weight - reading.Weight) / peak.Weight * 100
+	if lost < uc.weightLossPercent {
+		return nil, nil
+	}
+
+	return &entities.VitalAlert{
+		Rule:   entities.VitalAlertWeightLoss,
+		Metric: entities.VitalMetricWeight,
+		Value:  reading.Weight,
+		Message: fmt.Sprintf("Weight dropped %.1f%% from %g kg on %s to %g kg",
+			lost, peak.Weight, peak.RecordedAt.Format("2006-01-02"), reading.Weight),
+	}, nil
+}
+
+// newAlerts returns the alerts a resaved reading did not raise before
+func newAlerts(alerts []entities.VitalAlert, previous *entities.VitalSignReading) []entities.VitalAlert {
+	if previous == nil {
+		return alerts
+	}
+
+	var fresh []entities.VitalAlert
+	for _, alert := range alerts {
+		known := false
+		for _, old := range previous.Alerts {
+			if old.Rule == alert.Rule && old.Metric == alert.Metric {
+				known = true
+				break
+			}
+		}
+		if !known {
+			fresh = append(fresh, alert)
+		}
+	}
+	return fresh
+}
+
+// syncAnimalWeight keeps Animal.Weight at the most recently recorded weight
+func (uc *VitalsUseCase) syncAnimalWeight(ctx context.Context, animal *entities.Animal, reading *entities.VitalSignReading) {
+	latest, _, err := uc.vitalRepo.List(ctx, &repositories.VitalSignFilter{
+		AnimalID: &animal.ID,
+		Metric:   string(entities.VitalMetricWeight),
+		Limit:    1,
+	})
+	if err != nil || len(latest) == 0 || latest[0].ID != reading.ID || animal.Weight == reading.Weight {
+		return
+	}
+
+	animal.Weight = reading.Weight
+	_ = uc.animalRepo.Update(ctx, animal)
+}
+
+// raiseAlert opens a medical task for the alert and notifies the caretaker
+// and medical staff. While a task for the same alert is still open, nothing
+// new is raised, so repeated readings don't notify again.
+func (uc *VitalsUseCase) raiseAlert(ctx context.Context, animal *entities.Animal, reading *entities.VitalSignReading, alert entities.VitalAlert, userID primitive.ObjectID) {
+	name := animalName(animal)
+	tag := fmt.Sprintf("vitals:%s:%s", alert.Rule, alert.Metric)
+
+	if uc.taskRepo != nil {
+		if uc.hasOpenTask(ctx, animal.ID, tag) {
+			return
+		}
+
+		task := entities.NewTask(fmt.Sprintf("Check %s: %s", alertLabel(alert), name), entities.TaskCategoryMedical, entities.TaskPriorityHigh, userID)
+		task.Description = alert.Message
+		task.RelatedEntity = "animal"
+		task.RelatedEntityID = &animal.ID
+		task.Tags = []string{"vitals", tag}
+		dueDate := time.Now().Add(24 * time.Hour)
+		task.DueDate = &dueDate
+		if animal.Shelter.AssignedCaretaker != nil {
+			task.AssignTo(*animal.Shelter.AssignedCaretaker)
+		}
+		_ = uc.taskRepo.Create(ctx, task)
+	}
+
+	if uc.notifier == nil {
+		return
+	}
+
+	title := fmt.Sprintf("Vitals alert: %s", name)
+	for recipient := range uc.alertRecipients(ctx, animal) {
+		notification := entities.NewWarningNotification(recipient, title, alert.Message)
+		notification.Category = "medical"
+		notification.RelatedType = "animal"
+		notification.RelatedID = &animal.ID
+		notification.GroupKey = fmt.Sprintf("%s:%s", tag, animal.ID.Hex())
+		notification.Metadata["reading_id"] = reading.ID.Hex()
+		notification.Metadata["rule"] = string(alert.Rule)
+		notification.Metadata["metric"] = string(alert.Metric)
+
+		_ = uc.notifier.CreateNotification(ctx, notification)
+	}
+}
+
+// hasOpenTask checks if an alert task with the tag is still open for the animal
+func (uc *VitalsUseCase) hasOpenTask(ctx context.Context, animalID primitive.ObjectID, tag string) bool {
+	tasks, _, err := uc.taskRepo.List(ctx, &repositories.TaskFilter{
+		RelatedEntity:   "animal",
+		RelatedEntityID: &animalID,
+		Tags:            []string{tag},
+	})
+	if err != nil {
+		return false
+	}
+
+	for _, task := range tasks {
+		if task.Status != entities.TaskStatusCompleted && task.Status != entities.TaskStatusCancelled {
+			return true
+		}
+	}
+	return false
+}
+
+// alertRecipients returns the caretaker of the animal and the staff of its
+// latest veterinary visit, or the admins when the animal has neither
+func (uc *VitalsUseCase) alertRecipients(ctx context.Context, animal *entities.Animal) map[primitive.ObjectID]bool {
+	recipients := make(map[primitive.ObjectID]bool)
+	if animal.Shelter.AssignedCaretaker != nil {
+		recipients[*animal.Shelter.AssignedCaretaker] = true
+	}
+
+	if uc.visitRepo != nil {
+		visits, _, err := uc.visitRepo.List(ctx, repositories.VeterinaryVisitFilter{
+			AnimalID:  &animal.ID,
+			Status:    string(entities.VisitStatusCompleted),
+			Limit:     1,
+			SortBy:    "visit_date",
+			SortOrder: "desc",
+		})
+		if err == nil && len(visits) > 0 {
+			for _, staffID := range visits[0].AssignedStaff {
+				recipients[staffID] = true
+			}
+			if !visits[0].CreatedBy.IsZero() {
+				recipients[visits[0].CreatedBy] = true
+			}
+		}
+	}
+
+	if len(recipients) > 0 || uc.userRepo == nil {
+		return recipients
+	}
+	for _, role := range []entities.UserRole{entities.RoleAdmin, entities.RoleSuperAdmin} {
+		users, _, err := uc.userRepo.List(ctx, repositories.UserFilter{Role: string(role), Status: string(entities.StatusActive), Limit: 100})
+		if err != nil {
+			continue
+		}
+		for _, user := range users {
+			recipients[user.ID] = true
+		}
+	}
+
+	return recipients
+}
+
+// findFosterAssignment returns the active fostering assignment of the user for the animal
+func (uc *VitalsUseCase) findFosterAssignment(ctx context.Context, animalID, userID primitive.ObjectID) (*entities.VolunteerAssignment, error) {
+	volunteer, err := uc.volunteerRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if err == errors.ErrNotFound {
+			return nil, errors.NewForbidden("only the foster of this animal can check in")
+		}
+		return nil, err
+	}
+
+	assignments, err := uc.assignmentRepo.GetAssignmentsByAnimal(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, assignment := range assignments {
+		if assignment.VolunteerID != volunteer.ID || assignment.Type != entities.AssignmentTypeFostering {
+			continue
+		}
+		if assignment.IsActive() || assignment.Status == entities.AssignmentStatusAssigned {
+			return assignment, nil
+		}
+	}
+
+	return nil, errors.NewForbidden("only the foster of this animal can check in")
+}
+
+// ListReadings returns the readings of an animal, newest first
+func (uc *VitalsUseCase) ListReadings(ctx context.Context, filter *repositories.VitalSignFilter) ([]*entities.VitalSignReading, int64, error) {
+	if filter.Metric != "" && !entities.IsValidVitalMetric(entities.VitalMetric(filter.Metric)) {
+		return nil, 0, errors.NewBadRequest("invalid metric")
+	}
+	return uc.vitalRepo.List(ctx, filter)
+}
+
+// VitalTrend is the time series of one metric with its reference range
+type VitalTrend struct {
+	AnimalID primitive.ObjectID   `json:"animal_id"`
+	Species  string               `json:"species"`
+	Metric   entities.VitalMetric `json:"metric"`
+	From     time.Time            `json:"from"`
+	To       time.Time            `json:"to"`
+	Range    *entities.VitalRange `json:"reference_range,omitempty"`
+	Points   []VitalTrendPoint    `json:"points"`
+
+	// Summary over the period, nil without points
+	Latest        *float64 `json:"latest,omitempty"`
+	Min           *float64 `json:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty"`
+	ChangePercent *float64 `json:"change_percent,omitempty"` // first to latest point
+}
+
+// VitalTrendPoint is one value of a trend
+type VitalTrendPoint struct {
+	RecordedAt time.Time            `json:"recorded_at"`
+	Value      float64              `json:"value"`
+	Source     entities.VitalSource `json:"source"`
+	OutOfRange bool                 `json:"out_of_range"`
+}
+
+// GetTrend returns the values of a metric over the last days
+func (uc *VitalsUseCase) GetTrend(ctx context.Context, animalID primitive.ObjectID, metric entities.VitalMetric, days int) (*VitalTrend, error) {
+	if !entities.IsValidVitalMetric(metric) {
+		return nil, errors.NewBadRequest("invalid metric")
+	}
+	if days <= 0 {
+		days = 90
+	}
+
+	animal, err := uc.animalRepo.FindByID(ctx, animalID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -days)
+	readings, _, err := uc.vitalRepo.List(ctx, &repositories.VitalSignFilter{
+		AnimalID:  &animalID,
+		Metric:    string(metric),
+		From:      &from,
+		To:        &to,
+		SortOrder: "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &VitalTrend{
+		AnimalID: animalID,
+		Species:  entities.NormalizeSpecies(animal.Species),
+		Metric:   metric,
+		From:     from,
+		To:       to,
+		Points:   []VitalTrendPoint{},
+	}
+	if reference := entities.GetVitalReferenceRange(animal.Species); reference != nil {
+		if rng, ok := reference.Range(metric); ok {
+			trend.Range = &rng
+		}
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, reading := range readings {
+		value, measured := reading.Value(metric)
+		if !measured {
+			continue
+		}
+		trend.Points = append(trend.Points, VitalTrendPoint{
+			RecordedAt: reading.RecordedAt,
+			Value:      value,
+			Source:     reading.Source,
+			OutOfRange: trend.Range != nil && !trend.Range.Contains(value),
+		})
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+
+	if len(trend.Points) > 0 {
+		first := trend.Points[0].Value
+		latest := trend.Points[len(trend.Points)-1].Value
+		change := math.Round((latest-first)/first*1000) / 10
+		trend.Latest = &latest
+		trend.Min = &low
+		trend.Max = &high
+		trend.ChangePercent = &change
+	}
+
+	return trend, nil
+}
+
+// GetReferenceRanges returns the normal vital ranges per species
+func (uc *VitalsUseCase) GetReferenceRanges() []entities.VitalReferenceRange {
+	return entities.GetVitalReferenceRanges()
+}
+
+func animalName(animal *entities.Animal) string {
+	if animal.Name.English != "" {
+		return animal.Name.English
+	}
+	if animal.Name.Polish != "" {
+		return animal.Name.Polish
+	}
+	return animal.ID.Hex()
+}
+
+func metricLabel(metric entities.VitalMetric) string {
+	switch metric {
+	case entities.VitalMetricTemperature:
+		return "Temperature"
+	case entities.VitalMetricHeartRate:
+		return "Heart rate"
+	case entities.VitalMetricRespiratoryRate:
+		return "Respiratory rate"
+	}
+	return "Weight"
+}
+
+func alertLabel(alert entities.VitalAlert) string {
+	if alert.Rule == entities.VitalAlertWeightLoss {
+		return "weight loss"
+	}
+	return "abnormal " + strings.ToLower(metricLabel(alert.Metric))
+}
@@ -0,0 +1,224 @@
+package vitals
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/internal/domain/entities"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories"
+	"github.com/sainaif/animalsys/backend/internal/domain/repositories/mocks"
+	"github.com/sainaif/animalsys/backend/internal/testutil"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func isLatestQuery(filter *repositories.VitalSignFilter) bool {
+	return filter.Limit == 1
+}
+
+func isWindowQuery(filter *repositories.VitalSignFilter) bool {
+	return filter.Limit != 1
+}
+
+func TestVitalsUseCase_RecordReadingWeightLoss(t *testing.T) {
+	ctx := context.Background()
+	vitalRepo := new(mocks.VitalSignRepository)
+	animalRepo := new(mocks.AnimalRepository)
+	taskRepo := new(mocks.TaskRepository)
+	auditLogRepo := new(mocks.AuditLogRepository)
+	visitRepo := new(mocks.VeterinaryVisitRepository)
+	notifier := &testutil.Notifier{}
+	useCase := NewVitalsUseCase(vitalRepo, animalRepo, taskRepo, nil, visitRepo, nil, nil, auditLogRepo, notifier, 10, 14*24*time.Hour)
+
+	caretaker := primitive.NewObjectID()
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Name: entities.MultilingualName{English: "Burek"}, Species: "Dog", Weight: 20}
+	animal.Shelter.AssignedCaretaker = &caretaker
+	vet := primitive.NewObjectID()
+	visitRepo.On("List", ctx, mock.MatchedBy(func(filter repositories.VeterinaryVisitFilter) bool {
+		return *filter.AnimalID == animal.ID && filter.Limit == 1
+	})).Return([]*entities.VeterinaryVisit{{AnimalID: animal.ID, AssignedStaff: []primitive.ObjectID{vet}, CreatedBy: vet}}, int64(1), nil)
+	earlier := &entities.VitalSignReading{ID: primitive.NewObjectID(), AnimalID: animal.ID, RecordedAt: time.Now().AddDate(0, 0, -10), Weight: 20}
+	older := &entities.VitalSignReading{ID: primitive.NewObjectID(), AnimalID: animal.ID, RecordedAt: time.Now().AddDate(0, 0, -3), Weight: 19}
+
+	var saved *entities.VitalSignReading
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	animalRepo.On("Update", ctx, animal).Return(nil)
+	vitalRepo.On("List", ctx, mock.MatchedBy(isWindowQuery)).Return([]*entities.VitalSignReading{older, earlier}, int64(2), nil)
+	vitalRepo.On("Create", ctx, mock.AnythingOfType("*entities.VitalSignReading")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*entities.VitalSignReading)
+		saved.ID = primitive.NewObjectID()
+	}).Return(nil)
+	latest := vitalRepo.On("List", ctx, mock.MatchedBy(isLatestQuery))
+	latest.Run(func(mock.Arguments) {
+		latest.Return([]*entities.VitalSignReading{saved}, int64(3), nil)
+	})
+	taskRepo.On("List", ctx, mock.Anything).Return([]*entities.Task{}, int64(0), nil)
+	taskRepo.On("Create", ctx, mock.AnythingOfType("*entities.Task")).Return(nil)
+	auditLogRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	reading, err := useCase.RecordReading(ctx, animal.ID, &RecordReadingRequest{Weight: 17.5, Temperature: 38.6}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, "dog", reading.Species)
+	assert.Equal(t, entities.VitalSourceManual, reading.Source)
+	require.Len(t, reading.Alerts, 1, "the temperature is normal for a dog")
+	assert.Equal(t, entities.VitalAlertWeightLoss, reading.Alerts[0].Rule)
+	assert.Contains(t, reading.Alerts[0].Message, "12.5%", "measured against the highest weight in the window")
+	assert.Equal(t, 17.5, animal.Weight)
+
+	taskRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(task *entities.Task) bool {
+		return task.Category == entities.TaskCategoryMedical &&
+			task.RelatedEntity == "animal" && *task.RelatedEntityID == animal.ID &&
+			task.AssignedTo != nil && *task.AssignedTo == caretaker &&
+			assert.ObjectsAreEqual([]string{"vitals", "vitals:weight_loss:weight"}, task.Tags)
+	}))
+	require.Len(t, notifier.Notifications, 2, "the caretaker and the vet of the latest visit")
+	recipients := []primitive.ObjectID{notifier.Notifications[0].UserID, notifier.Notifications[1].UserID}
+	assert.ElementsMatch(t, []primitive.ObjectID{caretaker, vet}, recipients)
+	assert.Equal(t, "vitals:weight_loss:weight:"+animal.ID.Hex(), notifier.Notifications[0].GroupKey)
+}
+
+func TestVitalsUseCase_OutOfRangeKeepsOneOpenTask(t *testing.T) {
+	ctx := context.Background()
+	vitalRepo := new(mocks.VitalSignRepository)
+	animalRepo := new(mocks.AnimalRepository)
+	taskRepo := new(mocks.TaskRepository)
+	notifier := &testutil.Notifier{}
+	useCase := NewVitalsUseCase(vitalRepo, animalRepo, taskRepo, nil, nil, nil, nil, nil, notifier, 10, 14*24*time.Hour)
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "cat"}
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	vitalRepo.On("Create", ctx, mock.Anything).Return(nil)
+	openTask := entities.NewTask("Check abnormal temperature", entities.TaskCategoryMedical, entities.TaskPriorityHigh, primitive.NewObjectID())
+	taskRepo.On("List", ctx, mock.MatchedBy(func(filter *repositories.TaskFilter) bool {
+		return filter.RelatedEntity == "animal" && *filter.RelatedEntityID == animal.ID &&
+			len(filter.Tags) == 1 && filter.Tags[0] == "vitals:out_of_range:temperature"
+	})).Return([]*entities.Task{openTask}, int64(1), nil)
+
+	reading, err := useCase.RecordReading(ctx, animal.ID, &RecordReadingRequest{Temperature: 40.4, HeartRate: 180}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	require.Len(t, reading.Alerts, 1)
+	assert.Equal(t, entities.VitalAlertOutOfRange, reading.Alerts[0].Rule)
+	assert.Equal(t, entities.VitalMetricTemperature, reading.Alerts[0].Metric)
+	taskRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	assert.Empty(t, notifier.Notifications, "the open task was already notified")
+
+	_, err = useCase.RecordReading(ctx, animal.ID, &RecordReadingRequest{Notes: "calm"}, primitive.NewObjectID())
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 400, appErr.Code)
+}
+
+func TestVitalsUseCase_RecordVitalsUpdatesVisitReading(t *testing.T) {
+	ctx := context.Background()
+	vitalRepo := new(mocks.VitalSignRepository)
+	animalRepo := new(mocks.AnimalRepository)
+	taskRepo := new(mocks.TaskRepository)
+	useCase := NewVitalsUseCase(vitalRepo, animalRepo, taskRepo, nil, nil, nil, nil, nil, nil, 10, 14*24*time.Hour)
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "dog"}
+	visitID := primitive.NewObjectID()
+	previous := &entities.VitalSignReading{
+		ID:          primitive.NewObjectID(),
+		AnimalID:    animal.ID,
+		Source:      entities.VitalSourceVisit,
+		SourceID:    &visitID,
+		Temperature: 40.1,
+		Alerts:      []entities.VitalAlert{{Rule: entities.VitalAlertOutOfRange, Metric: entities.VitalMetricTemperature}},
+	}
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	vitalRepo.On("FindBySource", ctx, entities.VitalSourceVisit, visitID).Return(previous, nil)
+	vitalRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+	reading, err := useCase.RecordVitals(ctx, animal.ID, entities.VitalSigns{Temperature: 40.3}, entities.VitalSourceVisit, &visitID, time.Now(), primitive.NewObjectID())
+	require.NoError(t, err)
+
+	assert.Equal(t, previous.ID, reading.ID)
+	assert.Len(t, reading.Alerts, 1)
+	vitalRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	taskRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+
+	otherVisitID := primitive.NewObjectID()
+	vitalRepo.On("FindBySource", ctx, entities.VitalSourceVisit, otherVisitID).Return(nil, errors.ErrNotFound)
+	reading, err = useCase.RecordVitals(ctx, animal.ID, entities.VitalSigns{BloodPressure: "120/80"}, entities.VitalSourceVisit, &otherVisitID, time.Now(), primitive.NewObjectID())
+	require.NoError(t, err)
+	assert.Nil(t, reading, "visits without tracked vitals add no reading")
+}
+
+func TestVitalsUseCase_RecordFosterCheckIn(t *testing.T) {
+	ctx := context.Background()
+	vitalRepo := new(mocks.VitalSignRepository)
+	animalRepo := new(mocks.AnimalRepository)
+	volunteerRepo := new(mocks.VolunteerRepository)
+	assignmentRepo := new(mocks.VolunteerAssignmentRepository)
+	useCase := NewVitalsUseCase(vitalRepo, animalRepo, nil, nil, nil, volunteerRepo, assignmentRepo, nil, nil, 10, 14*24*time.Hour)
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "rabbit"}
+	fosterUserID := primitive.NewObjectID()
+	otherUserID := primitive.NewObjectID()
+	foster := &entities.Volunteer{ID: primitive.NewObjectID()}
+	other := &entities.Volunteer{ID: primitive.NewObjectID()}
+	assignment := &entities.VolunteerAssignment{
+		ID:          primitive.NewObjectID(),
+		VolunteerID: foster.ID,
+		AnimalID:    &animal.ID,
+		Type:        entities.AssignmentTypeFostering,
+		Status:      entities.AssignmentStatusInProgress,
+	}
+	volunteerRepo.On("FindByUserID", ctx, fosterUserID).Return(foster, nil)
+	volunteerRepo.On("FindByUserID", ctx, otherUserID).Return(other, nil)
+	assignmentRepo.On("GetAssignmentsByAnimal", ctx, animal.ID).Return([]*entities.VolunteerAssignment{assignment}, nil)
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	vitalRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	_, err := useCase.RecordFosterCheckIn(ctx, animal.ID, &RecordReadingRequest{HeartRate: 200}, otherUserID)
+	var appErr *errors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 403, appErr.Code)
+
+	reading, err := useCase.RecordFosterCheckIn(ctx, animal.ID, &RecordReadingRequest{HeartRate: 200}, fosterUserID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.VitalSourceFosterCheckIn, reading.Source)
+	assert.Equal(t, &assignment.ID, reading.SourceID)
+	assert.Empty(t, reading.Alerts)
+}
+
+func TestVitalsUseCase_GetTrend(t *testing.T) {
+	ctx := context.Background()
+	vitalRepo := new(mocks.VitalSignRepository)
+	animalRepo := new(mocks.AnimalRepository)
+	useCase := NewVitalsUseCase(vitalRepo, animalRepo, nil, nil, nil, nil, nil, nil, nil, 10, 14*24*time.Hour)
+
+	animal := &entities.Animal{ID: primitive.NewObjectID(), Species: "Cat"}
+	now := time.Now()
+	readings := []*entities.VitalSignReading{
+		{RecordedAt: now.AddDate(0, 0, -20), Source: entities.VitalSourceVisit, Temperature: 38.5},
+		{RecordedAt: now.AddDate(0, 0, -10), Source: entities.VitalSourceDailyNote, Temperature: 39.6},
+		{RecordedAt: now.AddDate(0, 0, -1), Source: entities.VitalSourceManual, Temperature: 38.8},
+	}
+	animalRepo.On("FindByID", ctx, animal.ID).Return(animal, nil)
+	vitalRepo.On("List", ctx, mock.MatchedBy(func(filter *repositories.VitalSignFilter) bool {
+		return filter.Metric == "temperature" && filter.SortOrder == "asc" && filter.From != nil
+	})).Return(readings, int64(3), nil)
+
+	trend, err := useCase.GetTrend(ctx, animal.ID, entities.VitalMetricTemperature, 30)
+	require.NoError(t, err)
+
+	require.NotNil(t, trend.Range)
+	assert.Equal(t, 39.2, trend.Range.Max)
+	require.Len(t, trend.Points, 3)
+	assert.True(t, trend.Points[1].OutOfRange)
+	assert.False(t, trend.Points[2].OutOfRange)
+	assert.Equal(t, 38.8, *trend.Latest)
+	assert.Equal(t, 38.5, *trend.Min)
+	assert.Equal(t, 39.6, *trend.Max)
+	assert.Equal(t, 0.8, *trend.ChangePercent)
+
+	_, err = useCase.GetTrend(ctx, animal.ID, "blood_sugar", 30)
+	require.Error(t, err)
+}
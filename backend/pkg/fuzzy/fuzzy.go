@@ -0,0 +1,123 @@
+// Package fuzzy provides the text normalization and typo-tolerant matching used by search.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldSpecial covers letters that do not decompose into a base letter and a mark
+var foldSpecial = strings.NewReplacer("ł", "l", "Ł", "l", "ø", "o", "Ø", "o", "ß", "ss", "đ", "d", "Đ", "d")
+
+// Fold lowercases the text and strips diacritics, so "Łukasz Żółw" becomes "lukasz zolw"
+func Fold(s string) string {
+	s = foldSpecial.Replace(s)
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// Tokenize folds the text and splits it into words
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Digits returns only the digits of the text, used to compare phone numbers
+func Digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// MaxEdits returns the number of typos tolerated for a term of the given length
+func MaxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Distance returns the optimal string alignment distance (Levenshtein with transpositions)
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(ra)][len(rb)]
+}
+
+// PrefixDistance returns the smallest distance between the term and a prefix of the word,
+// so "kowalsk" matches "kowalski" with 0 and "kowlaski" matches it with 1
+func PrefixDistance(term, word string) int {
+	rt, rw := []rune(term), []rune(word)
+	best := Distance(term, word)
+	for n := len(rt) - 1; n <= len(rt)+1; n++ {
+		if n < 1 || n > len(rw) {
+			continue
+		}
+		if d := Distance(term, string(rw[:n])); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// Match scores how well a folded query term matches a folded word:
+// 1 for an exact match, 0.9 for a prefix, less for prefixes with typos and 0 for no match
+func Match(term, word string) float64 {
+	switch {
+	case term == "" || word == "":
+		return 0
+	case term == word:
+		return 1
+	case strings.HasPrefix(word, term):
+		return 0.9
+	}
+
+	maxEdits := MaxEdits(term)
+	if maxEdits == 0 {
+		return 0
+	}
+
+	if d := PrefixDistance(term, word); d <= maxEdits {
+		return 0.7 - 0.2*float64(d-1)
+	}
+
+	return 0
+}
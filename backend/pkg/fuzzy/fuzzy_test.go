@@ -0,0 +1,29 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFold(t *testing.T) {
+	assert.Equal(t, "lukasz zolwinski", Fold("Łukasz Żółwiński"))
+	assert.Equal(t, []string{"jan", "kowalski", "jan", "example", "com"}, Tokenize("Jan Kowalski <jan@Example.com>"))
+	assert.Equal(t, "48501234567", Digits("+48 501-234-567"))
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance("burek", "burek"))
+	assert.Equal(t, 1, Distance("burek", "burke"))
+	assert.Equal(t, 1, Distance("kowalski", "kowalsky"))
+	assert.Equal(t, 3, Distance("kitten", "sitting"))
+}
+
+func TestMatch(t *testing.T) {
+	assert.Equal(t, 1.0, Match("anna", "anna"))
+	assert.Equal(t, 0.9, Match("kowal", "kowalski"))
+	assert.Greater(t, Match("kowlaski", "kowalski"), 0.0)
+	assert.Greater(t, Match("nowk", "nowak"), 0.0)
+	assert.Equal(t, 0.0, Match("ann", "ala"))
+	assert.Equal(t, 0.0, Match("kowalski", "nowak"))
+}
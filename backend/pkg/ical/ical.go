@@ -0,0 +1,188 @@
+// Package ical writes iCalendar (RFC 5545) feeds and invitations and expands recurrence rules.
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Method is the iTIP method of a calendar object (RFC 5546)
+type Method string
+
+const (
+	MethodPublish Method = "PUBLISH"
+	MethodRequest Method = "REQUEST"
+	MethodCancel  Method = "CANCEL"
+)
+
+// Event statuses
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar is a set of events
+type Calendar struct {
+	Name   string
+	Method Method
+	Events []Event
+}
+
+// Event is a VEVENT
+type Event struct {
+	UID         string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string
+	Organizer   *Attendee
+	Attendees   []Attendee
+	Updated     time.Time
+}
+
+// Attendee is an ORGANIZER or ATTENDEE of an event
+type Attendee struct {
+	Name  string
+	Email string
+}
+
+const productID = "-//animalsys//animalsys//EN"
+
+// Bytes renders the calendar as text/calendar content
+func (c *Calendar) Bytes() []byte {
+	return []byte(c.String())
+}
+
+// String renders the calendar as text/calendar content
+func (c *Calendar) String() string {
+	var b strings.Builder
+	w := &writer{b: &b}
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", productID)
+	w.line("CALSCALE", "GREGORIAN")
+	method := c.Method
+	if method == "" {
+		method = MethodPublish
+	}
+	w.line("METHOD", string(method))
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", escape(c.Name))
+	}
+
+	for _, event := range c.Events {
+		w.event(event)
+	}
+
+	w.line("END", "VCALENDAR")
+	return b.String()
+}
+
+type writer struct {
+	b *strings.Builder
+}
+
+func (w *writer) event(e Event) {
+	stamp := e.Updated
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", e.UID)
+	w.line("DTSTAMP", formatTime(stamp))
+	if e.AllDay {
+		w.line("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+		end := e.End
+		if !end.After(e.Start) {
+			end = e.Start.AddDate(0, 0, 1)
+		}
+		w.line("DTEND;VALUE=DATE", end.Format("20060102"))
+	} else {
+		w.line("DTSTART", formatTime(e.Start))
+		if !e.End.IsZero() {
+			w.line("DTEND", formatTime(e.End))
+		}
+	}
+	if e.Sequence > 0 {
+		w.line("SEQUENCE", strconv.Itoa(e.Sequence))
+	}
+	w.line("SUMMARY", escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION", escape(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION", escape(e.Location))
+	}
+	if e.URL != "" {
+		w.line("URL", e.URL)
+	}
+	if e.Status != "" {
+		w.line("STATUS", e.Status)
+	}
+	if e.Organizer != nil && e.Organizer.Email != "" {
+		w.line("ORGANIZER"+commonName(e.Organizer.Name), "mailto:"+e.Organizer.Email)
+	}
+	for _, attendee := range e.Attendees {
+		if attendee.Email == "" {
+			continue
+		}
+		w.line("ATTENDEE"+commonName(attendee.Name)+";ROLE=REQ-PARTICIPANT;RSVP=FALSE", "mailto:"+attendee.Email)
+	}
+	if !e.Updated.IsZero() {
+		w.line("LAST-MODIFIED", formatTime(e.Updated))
+	}
+	w.line("END", "VEVENT")
+}
+
+// line writes a content line, folded at 75 octets as RFC 5545 requires
+func (w *writer) line(name, value string) {
+	content := name + ":" + value
+
+	// Continuation lines start with a space, which counts towards the limit
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		// Do not split a multi-byte UTF-8 sequence
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.b.WriteString(content[:cut])
+		w.b.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74
+	}
+	w.b.WriteString(content)
+	w.b.WriteString("\r\n")
+}
+
+func commonName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(name) + `"`
+}
+
+// escape escapes TEXT values (RFC 5545 section 3.3.11)
+func escape(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(value)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
@@ -0,0 +1,59 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar_String(t *testing.T) {
+	start := time.Date(2026, 3, 4, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	calendar := &Calendar{
+		Name: "Vet Clinic, Kraków",
+		Events: []Event{{
+			UID:         "visit-1@animalsys",
+			Sequence:    2,
+			Start:       start,
+			End:         start.Add(30 * time.Minute),
+			Summary:     "Checkup: Burek; room 2",
+			Description: "Bring the vaccination booklet\nFasting since 22:00",
+			Status:      StatusConfirmed,
+			Attendees:   []Attendee{{Name: "Anna \"Ania\" Nowak", Email: "anna@example.org"}},
+			Updated:     start,
+		}},
+	}
+
+	out := calendar.String()
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	assert.Contains(t, out, "METHOD:PUBLISH\r\n")
+	assert.Contains(t, out, "X-WR-CALNAME:Vet Clinic\\, Kraków\r\n")
+	assert.Contains(t, out, "DTSTART:20260304T090000Z\r\n", "times are written in UTC")
+	assert.Contains(t, out, "DTEND:20260304T093000Z\r\n")
+	assert.Contains(t, out, "SEQUENCE:2\r\n")
+	assert.Contains(t, out, "SUMMARY:Checkup: Burek\\; room 2\r\n")
+	assert.Contains(t, out, "DESCRIPTION:Bring the vaccination booklet\\nFasting since 22:00\r\n")
+	assert.Contains(t, out, "ATTENDEE;CN=\"Anna 'Ania' Nowak\";ROLE=REQ-PARTICIPANT;RSVP=FALSE:mailto:anna\r\n @example.org\r\n")
+}
+
+func TestCalendar_FoldsLongLines(t *testing.T) {
+	calendar := &Calendar{Events: []Event{{
+		UID:         "long",
+		Start:       time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		AllDay:      true,
+		Summary:     "Adoption day",
+		Description: strings.Repeat("źdźbło ", 40),
+	}}}
+
+	out := calendar.String()
+
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20260304\r\n")
+	assert.Contains(t, out, "DTEND;VALUE=DATE:20260305\r\n")
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "folding must not split UTF-8 sequences")
+	}
+}
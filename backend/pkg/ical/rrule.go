@@ -0,0 +1,365 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ of a recurrence rule
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry: a weekday, in monthly rules optionally the nth one of
+// the month, e.g. 2SA for the second Saturday or -1FR for the last Friday
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// RRule is a recurrence rule (RFC 5545 section 3.3.10) with the parts used for events
+// repeating on a calendar: FREQ, INTERVAL, COUNT, UNTIL, BYDAY and BYMONTHDAY. Weeks
+// start on Monday; yearly rules repeat on the date of the first occurrence.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// maxEmptyPeriods stops the expansion of rules that never match, e.g. every 12
+// months on the 31st starting in April
+const maxEmptyPeriods = 1000
+
+// ParseRRule parses a recurrence rule such as "FREQ=WEEKLY;BYDAY=SA;COUNT=10"; the
+// "RRULE:" prefix is optional
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+
+	rule := &RRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		name = strings.ToUpper(strings.TrimSpace(name))
+		val = strings.ToUpper(strings.TrimSpace(val))
+
+		switch name {
+		case "FREQ":
+			switch freq := Frequency(val); freq {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+				rule.Freq = freq
+			default:
+				return nil, fmt.Errorf("unsupported frequency %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive number")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("COUNT must be a positive number")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, code := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(code)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", code)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, fmt.Errorf("only weeks starting on Monday are supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	switch rule.Freq {
+	case FrequencyYearly:
+		if len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("yearly rules repeat on the date of the first occurrence; BYDAY and BYMONTHDAY are not supported")
+		}
+	case FrequencyWeekly:
+		if len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("BYMONTHDAY cannot be used in weekly rules")
+		}
+	}
+	if rule.Freq != FrequencyMonthly {
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return nil, fmt.Errorf("numbered weekdays such as %s need a monthly rule", day)
+			}
+		}
+	}
+	return rule, nil
+}
+
+// String returns the rule in its RRULE form, without the prefix
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+formatTime(r.Until))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String returns the BYDAY code of the weekday, e.g. "SA" or "-1FR"
+func (d WeekdayNum) String() string {
+	if d.N == 0 {
+		return weekdayCodes[d.Day]
+	}
+	return strconv.Itoa(d.N) + weekdayCodes[d.Day]
+}
+
+// Finite reports whether the rule ends, after a number of occurrences or at a date
+func (r *RRule) Finite() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// Occurrences returns the start times of the occurrences of the rule for a first
+// occurrence at start, up to and including until and at most limit of them (0 for no
+// limit; a zero until is no bound either). Occurrences keep the wall clock time of
+// start in its location, also across daylight saving time changes.
+func (r *RRule) Occurrences(start, until time.Time, limit int) []time.Time {
+	end := until
+	if !r.Until.IsZero() && (end.IsZero() || r.Until.Before(end)) {
+		end = r.Until
+	}
+	if end.IsZero() && r.Count == 0 && limit == 0 {
+		return nil
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var occurrences []time.Time
+	empty := 0
+	for period := 0; empty < maxEmptyPeriods; period++ {
+		candidates := r.period(start, period*interval)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+
+		for _, candidate := range candidates {
+			if candidate.Before(start) {
+				continue
+			}
+			if !end.IsZero() && candidate.After(end) {
+				return occurrences
+			}
+			occurrences = append(occurrences, candidate)
+			if (r.Count > 0 && len(occurrences) == r.Count) || (limit > 0 && len(occurrences) == limit) {
+				return occurrences
+			}
+		}
+	}
+	return occurrences
+}
+
+// period returns the candidate occurrences of the nth period after the one of start
+func (r *RRule) period(start time.Time, n int) []time.Time {
+	year, month, day := start.Date()
+	hour, minute, second := start.Clock()
+	loc := start.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	switch r.Freq {
+	case FrequencyDaily:
+		date := at(year, month, day+n)
+		last := daysIn(date.Year(), date.Month())
+		if r.matchesWeekday(date, last) && r.matchesMonthDay(date.Day(), last) {
+			return []time.Time{date}
+		}
+		return nil
+
+	case FrequencyWeekly:
+		monday := day - (int(start.Weekday())+6)%7 + 7*n
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Day: start.Weekday()}}
+		}
+		var dates []time.Time
+		for _, weekday := range days {
+			dates = append(dates, at(year, month, monday+(int(weekday.Day)+6)%7))
+		}
+		sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+		return dates
+
+	case FrequencyMonthly:
+		first := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, loc)
+		last := daysIn(first.Year(), first.Month())
+
+		var dates []time.Time
+		for d := 1; d <= last; d++ {
+			date := at(first.Year(), first.Month(), d)
+			matches := false
+			switch {
+			case len(r.ByMonthDay) > 0:
+				matches = r.matchesMonthDay(d, last) && r.matchesWeekday(date, last)
+			case len(r.ByDay) > 0:
+				matches = r.matchesWeekday(date, last)
+			default:
+				matches = d == day
+			}
+			if matches {
+				dates = append(dates, date)
+			}
+		}
+		return dates
+
+	case FrequencyYearly:
+		date := at(year+n, month, day)
+		if date.Day() != day {
+			// No 29 February this year
+			return nil
+		}
+		return []time.Time{date}
+	}
+	return nil
+}
+
+// matchesWeekday checks a date against BYDAY; numbered weekdays count within the month
+func (r *RRule) matchesWeekday(date time.Time, last int) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Day != date.Weekday() {
+			continue
+		}
+		switch {
+		case weekday.N == 0,
+			weekday.N > 0 && (date.Day()-1)/7+1 == weekday.N,
+			weekday.N < 0 && (last-date.Day())/7+1 == -weekday.N:
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay checks a day of the month against BYMONTHDAY; negative days count
+// from the end of the month
+func (r *RRule) matchesMonthDay(day, last int) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day || (monthDay < 0 && last+monthDay+1 == day) {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+
+	prefix, name := code[:len(code)-2], code[len(code)-2:]
+	for i, weekday := range weekdayCodes {
+		if weekday != name {
+			continue
+		}
+		day := WeekdayNum{Day: time.Weekday(i)}
+		if prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+			}
+			day.N = n
+		}
+		return day, nil
+	}
+	return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+}
+
+// parseUntil parses an UNTIL date or date-time; a date includes the whole day
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return until, nil
+		}
+	}
+	if date, err := time.Parse("20060102", value); err == nil {
+		return date.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
@@ -0,0 +1,111 @@
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dates(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format("2006-01-02 15:04 Mon")
+	}
+	return out
+}
+
+func TestParseRRule_RoundTrip(t *testing.T) {
+	rule, err := ParseRRule("RRULE:freq=monthly;interval=2;byday=-1FR,2SA;until=20270101")
+	require.NoError(t, err)
+
+	assert.Equal(t, FrequencyMonthly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, []WeekdayNum{{N: -1, Day: time.Friday}, {N: 2, Day: time.Saturday}}, rule.ByDay)
+	assert.Equal(t, time.Date(2027, 1, 1, 23, 59, 59, 0, time.UTC), rule.Until)
+	assert.True(t, rule.Finite())
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR,2SA;UNTIL=20270101T235959Z", rule.String())
+}
+
+func TestParseRRule_Rejects(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"BYDAY=SA",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;COUNT=3;UNTIL=20270101",
+		"FREQ=WEEKLY;BYDAY=2SA",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYHOUR=10",
+	} {
+		_, err := ParseRRule(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRRule_Occurrences_Weekly(t *testing.T) {
+	rule, err := ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SA;COUNT=5")
+	require.NoError(t, err)
+
+	// The first occurrence is a Saturday; the Tuesday of its week has passed
+	start := time.Date(2026, 10, 3, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2026-10-03 10:00 Sat",
+		"2026-10-13 10:00 Tue",
+		"2026-10-17 10:00 Sat",
+		"2026-10-27 10:00 Tue",
+		"2026-10-31 10:00 Sat",
+	}, dates(rule.Occurrences(start, time.Time{}, 0)))
+}
+
+func TestRRule_Occurrences_MonthlyByDay(t *testing.T) {
+	rule, err := ParseRRule("FREQ=MONTHLY;BYDAY=-1FR")
+	require.NoError(t, err)
+
+	start := time.Date(2026, 10, 30, 18, 0, 0, 0, time.UTC)
+	until := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2026-10-30 18:00 Fri",
+		"2026-11-27 18:00 Fri",
+		"2026-12-25 18:00 Fri",
+		"2027-01-29 18:00 Fri",
+	}, dates(rule.Occurrences(start, until, 0)))
+}
+
+func TestRRule_Occurrences_SkipsMissingDays(t *testing.T) {
+	rule, err := ParseRRule("FREQ=MONTHLY;COUNT=3")
+	require.NoError(t, err)
+
+	start := time.Date(2027, 1, 31, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2027-01-31 09:00 Sun",
+		"2027-03-31 09:00 Wed",
+		"2027-05-31 09:00 Mon",
+	}, dates(rule.Occurrences(start, time.Time{}, 0)))
+}
+
+func TestRRule_Occurrences_KeepsLocalTimeAcrossDST(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	rule, err := ParseRRule("FREQ=WEEKLY")
+	require.NoError(t, err)
+
+	// Clocks go back on 25 October 2026
+	start := time.Date(2026, 10, 17, 11, 0, 0, 0, warsaw)
+	occurrences := rule.Occurrences(start, time.Time{}, 3)
+
+	assert.Equal(t, []string{"2026-10-17 11:00 Sat", "2026-10-24 11:00 Sat", "2026-10-31 11:00 Sat"}, dates(occurrences))
+	assert.Equal(t, 9, occurrences[0].UTC().Hour())
+	assert.Equal(t, 10, occurrences[2].UTC().Hour())
+}
+
+func TestRRule_Occurrences_Unbounded(t *testing.T) {
+	rule, err := ParseRRule("FREQ=DAILY")
+	require.NoError(t, err)
+
+	assert.Nil(t, rule.Occurrences(time.Now(), time.Time{}, 0))
+}
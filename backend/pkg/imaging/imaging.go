@@ -0,0 +1,193 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"golang.org/x/image/draw"
+)
+
+// maxPixels guards against decompression bombs (about 50 megapixels)
+const maxPixels = 50_000_000
+
+// ContentTypeWebP is the content type of every generated variant
+const ContentTypeWebP = "image/webp"
+
+// allowedContentTypes lists the sniffed content types accepted for upload
+var allowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Size describes a named output size
+type Size struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+	Square    bool // Center-crop to a square before scaling (thumbnails)
+}
+
+// Standard size names
+const (
+	SizeLarge     = "large"
+	SizeMedium    = "medium"
+	SizeThumbnail = "thumbnail"
+)
+
+// DefaultSizes are the variants generated for animal photos
+var DefaultSizes = []Size{
+	{Name: SizeLarge, MaxWidth: 1600, MaxHeight: 1600},
+	{Name: SizeMedium, MaxWidth: 800, MaxHeight: 800},
+	{Name: SizeThumbnail, MaxWidth: 240, MaxHeight: 240, Square: true},
+}
+
+// Variant is a single encoded output size
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Result holds all variants generated from an upload
+type Result struct {
+	SourceType string // Sniffed content type of the upload
+	Width      int    // Width after orientation correction
+	Height     int    // Height after orientation correction
+	Variants   []Variant
+}
+
+// Processor decodes uploads, strips metadata and renders named sizes
+type Processor struct {
+	sizes []Size
+}
+
+// NewProcessor creates a new image processor for the given sizes
+func NewProcessor(sizes []Size) *Processor {
+	if len(sizes) == 0 {
+		sizes = DefaultSizes
+	}
+	return &Processor{sizes: sizes}
+}
+
+// SniffContentType detects the content type from the file contents, ignoring the file name
+func SniffContentType(data []byte) string {
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	return http.DetectContentType(head)
+}
+
+// Process reads an uploaded image and returns re-encoded WebP variants.
+// Decoding and re-encoding drops every metadata block (EXIF, GPS, XMP, ICC),
+// so only pixel data leaves this function.
+func (p *Processor) Process(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to read image")
+	}
+
+	contentType := SniffContentType(data)
+	if !allowedContentTypes[contentType] {
+		return nil, errors.NewBadRequest(fmt.Sprintf("unsupported image content type %q. Allowed types: jpeg, png, gif, webp", contentType))
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewBadRequest("file is not a valid image")
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, errors.NewBadRequest("image dimensions are too large")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewBadRequest("file is not a valid image")
+	}
+
+	// Bake the EXIF orientation into the pixels before the tag is discarded
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, readOrientation(data))
+	}
+
+	bounds := img.Bounds()
+	result := &Result{
+		SourceType: contentType,
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Variants:   make([]Variant, 0, len(p.sizes)),
+	}
+
+	for _, size := range p.sizes {
+		resized := resize(img, size)
+
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, resized, nil); err != nil {
+			return nil, errors.Wrap(err, 500, "failed to encode image")
+		}
+
+		result.Variants = append(result.Variants, Variant{
+			Name:   size.Name,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+			Data:   buf.Bytes(),
+		})
+	}
+
+	return result, nil
+}
+
+// resize scales the image to fit the size, never upscaling
+func resize(src image.Image, size Size) image.Image {
+	sr := src.Bounds()
+
+	if size.Square {
+		side := sr.Dx()
+		if sr.Dy() < side {
+			side = sr.Dy()
+		}
+		x0 := sr.Min.X + (sr.Dx()-side)/2
+		y0 := sr.Min.Y + (sr.Dy()-side)/2
+		sr = image.Rect(x0, y0, x0+side, y0+side)
+	}
+
+	width, height := fit(sr.Dx(), sr.Dy(), size.MaxWidth, size.MaxHeight)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, sr, draw.Src, nil)
+
+	return dst
+}
+
+// fit returns dimensions that fit within the bounds while keeping the aspect ratio
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	if maxWidth <= 0 || maxHeight <= 0 || (width <= maxWidth && height <= maxHeight) {
+		return width, height
+	}
+
+	ratio := float64(maxWidth) / float64(width)
+	if hr := float64(maxHeight) / float64(height); hr < ratio {
+		ratio = hr
+	}
+
+	w := int(float64(width)*ratio + 0.5)
+	h := int(float64(height)*ratio + 0.5)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// jpegWithOrientation encodes a JPEG and inserts an EXIF APP1 segment carrying the orientation and a GPS marker
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	encoded := buf.Bytes()
+
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("MM")
+	_ = binary.Write(tiff, binary.BigEndian, uint16(42))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(8))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(1))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(exifOrientationTag))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(3))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(1))
+	_ = binary.Write(tiff, binary.BigEndian, orientation)
+	_ = binary.Write(tiff, binary.BigEndian, uint16(0))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS-52.2297N-21.0122E")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, encoded[:2]...)
+	out = append(out, segment...)
+	return append(out, encoded[2:]...)
+}
+
+func TestProcess_StripsMetadataAndAppliesOrientation(t *testing.T) {
+	data := jpegWithOrientation(t, testImage(400, 200), 6)
+	assert.Equal(t, 6, readOrientation(data))
+
+	result, err := NewProcessor(nil).Process(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "image/jpeg", result.SourceType)
+	assert.Equal(t, 200, result.Width)
+	assert.Equal(t, 400, result.Height)
+	require.Len(t, result.Variants, len(DefaultSizes))
+
+	for _, variant := range result.Variants {
+		assert.False(t, bytes.Contains(variant.Data, []byte("GPS-52.2297N")))
+		assert.False(t, bytes.Contains(variant.Data, []byte("Exif")))
+
+		decoded, err := nativewebp.Decode(bytes.NewReader(variant.Data))
+		require.NoError(t, err)
+		assert.Equal(t, variant.Width, decoded.Bounds().Dx())
+		assert.Equal(t, variant.Height, decoded.Bounds().Dy())
+	}
+
+	thumbnail := result.Variants[2]
+	assert.Equal(t, SizeThumbnail, thumbnail.Name)
+	assert.Equal(t, 200, thumbnail.Width)
+	assert.Equal(t, 200, thumbnail.Height)
+}
+
+func TestProcess_ResizesLargeImages(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(2000, 1000)))
+
+	result, err := NewProcessor([]Size{{Name: SizeMedium, MaxWidth: 800, MaxHeight: 800}}).Process(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, "image/png", result.SourceType)
+	assert.Equal(t, 800, result.Variants[0].Width)
+	assert.Equal(t, 400, result.Variants[0].Height)
+}
+
+func TestProcess_RejectsNonImageContent(t *testing.T) {
+	_, err := NewProcessor(nil).Process(bytes.NewReader([]byte("<html><script>alert(1)</script></html>")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package microchip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// HTTPRegistry talks to a microchip registry over its REST API. A chip's registration is
+// read with GET {apiURL}/chips/{number} and replaced with PUT {apiURL}/chips/{number}/owner;
+// requests carry the API key as a bearer token.
+type HTTPRegistry struct {
+	name   string
+	apiURL string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPRegistry creates a client for the registry at the API URL; the name is stored
+// on the registrations it makes
+func NewHTTPRegistry(name, apiURL, apiKey string) *HTTPRegistry {
+	return &HTTPRegistry{
+		name:   name,
+		apiURL: strings.TrimRight(apiURL, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns the registry identifier
+func (r *HTTPRegistry) Name() string {
+	return r.name
+}
+
+// Lookup finds the current owner registration for a chip
+func (r *HTTPRegistry) Lookup(ctx context.Context, chipNumber string) (*Registration, error) {
+	normalized, err := Validate(chipNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.do(ctx, http.MethodGet, "/chips/"+normalized, nil)
+}
+
+// Register records a new owner for a chip
+func (r *HTTPRegistry) Register(ctx context.Context, chipNumber string, owner Owner) (*Registration, error) {
+	normalized, err := Validate(chipNumber)
+	if err != nil {
+		return nil, err
+	}
+	if owner.Name == "" {
+		return nil, errors.NewBadRequest("owner name is required")
+	}
+
+	payload, err := json.Marshal(owner)
+	if err != nil {
+		return nil, err
+	}
+	return r.do(ctx, http.MethodPut, "/chips/"+normalized+"/owner", payload)
+}
+
+// do sends a request to the registry and reads the registration it answers with
+func (r *HTTPRegistry) do(ctx context.Context, method, path string, payload []byte) (*Registration, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, r.apiURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.Wrap(err, http.StatusBadGateway, "microchip registry is unavailable")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.NewNotFound("microchip is not registered")
+	}
+
+	var body struct {
+		Registration
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, http.StatusBadGateway, "invalid response from the microchip registry")
+	}
+	if resp.StatusCode >= 300 {
+		message := resp.Status
+		if body.Error != "" {
+			message = body.Error
+		}
+		return nil, errors.New(http.StatusBadGateway, "microchip registry: "+message)
+	}
+
+	registration := body.Registration
+	registration.Registry = r.name
+	return &registration, nil
+}
@@ -0,0 +1,74 @@
+package microchip
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// ISO 11784/11785 FDX-B transponders carry a 15 digit decimal code made of a
+// 3 digit country or manufacturer code followed by a 12 digit identifier.
+const (
+	isoCodeLength = 15
+
+	// Codes 900-998 are assigned to manufacturers instead of countries
+	manufacturerCodeMin = 900
+	manufacturerCodeMax = 998
+
+	// Code 999 is reserved for test transponders and must never be implanted
+	testTransponderCode = 999
+)
+
+// Normalize strips separators commonly printed on chip labels and scanners
+func Normalize(number string) string {
+	var b strings.Builder
+	for _, r := range number {
+		if unicode.IsSpace(r) || r == '-' || r == '.' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Validate checks that a microchip number is a valid ISO 11784/11785 code
+// and returns its normalized form
+func Validate(number string) (string, error) {
+	normalized := Normalize(number)
+
+	if len(normalized) != isoCodeLength {
+		return "", errors.NewBadRequest("microchip number must have 15 digits (ISO 11784/11785)")
+	}
+
+	for _, r := range normalized {
+		if r < '0' || r > '9' {
+			return "", errors.NewBadRequest("microchip number must contain only digits (ISO 11784/11785)")
+		}
+	}
+
+	code, _ := strconv.Atoi(normalized[:3])
+	if code == 0 {
+		return "", errors.NewBadRequest("microchip number has an invalid country or manufacturer code")
+	}
+	if code == testTransponderCode {
+		return "", errors.NewBadRequest("microchip number belongs to a test transponder")
+	}
+
+	return normalized, nil
+}
+
+// IsManufacturerCode reports whether the chip carries a manufacturer code
+// instead of an ISO 3166 country code
+func IsManufacturerCode(number string) bool {
+	normalized := Normalize(number)
+	if len(normalized) < 3 {
+		return false
+	}
+	code, err := strconv.Atoi(normalized[:3])
+	if err != nil {
+		return false
+	}
+	return code >= manufacturerCodeMin && code <= manufacturerCodeMax
+}
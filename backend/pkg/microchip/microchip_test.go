@@ -0,0 +1,98 @@
+package microchip
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "country code", input: "616093900012345", expected: "616093900012345"},
+		{name: "manufacturer code with separators", input: "985 112-003.456789", expected: "985112003456789"},
+		{name: "too short", input: "61609390001234", wantErr: true},
+		{name: "letters", input: "61609390001234A", wantErr: true},
+		{name: "zero code", input: "000093900012345", wantErr: true},
+		{name: "test transponder", input: "999000000012345", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := Validate(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestIsManufacturerCode(t *testing.T) {
+	assert.True(t, IsManufacturerCode("985112003456789"))
+	assert.False(t, IsManufacturerCode("616093900012345"))
+}
+
+func TestLocalRegistry_RegisterAndLookup(t *testing.T) {
+	registry := NewLocalRegistry()
+	ctx := context.Background()
+
+	_, err := registry.Lookup(ctx, "616093900012345")
+	assert.Error(t, err)
+
+	registration, err := registry.Register(ctx, "616 093 900 012 345", Owner{Name: "Jan Kowalski", Email: "jan@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "616093900012345", registration.ChipNumber)
+	assert.Equal(t, "local", registration.Registry)
+
+	found, err := registry.Lookup(ctx, "616093900012345")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jan Kowalski", found.Owner.Name)
+
+	_, err = registry.Register(ctx, "616093900012345", Owner{})
+	assert.Error(t, err)
+}
+
+func TestHTTPRegistry_RegisterAndLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v1/chips/616093900012345/owner":
+			var owner Owner
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&owner))
+			json.NewEncoder(w).Encode(Registration{ChipNumber: "616093900012345", Reference: "R-1", Owner: owner, RegisteredAt: time.Now()})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/chips/985112003456789":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unexpected request"})
+		}
+	}))
+	defer server.Close()
+
+	registry := NewHTTPRegistry("europetnet", server.URL+"/v1/", "secret")
+	ctx := context.Background()
+
+	registration, err := registry.Register(ctx, "616 093 900 012 345", Owner{Name: "Jan Kowalski"})
+	assert.NoError(t, err)
+	assert.Equal(t, "europetnet", registration.Registry)
+	assert.Equal(t, "R-1", registration.Reference)
+	assert.Equal(t, "Jan Kowalski", registration.Owner.Name)
+
+	_, err = registry.Lookup(ctx, "985112003456789")
+	assert.Error(t, err)
+
+	_, err = registry.Lookup(ctx, "616093900012345")
+	assert.EqualError(t, err, "microchip registry: unexpected request")
+}
@@ -0,0 +1,101 @@
+package microchip
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Owner holds the owner details kept by a microchip registry
+type Owner struct {
+	Name    string `json:"name" validate:"required"`
+	Email   string `json:"email,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// Registration represents an owner registration for a microchip
+type Registration struct {
+	ChipNumber   string    `json:"chip_number"`
+	Registry     string    `json:"registry"`
+	Reference    string    `json:"reference"`
+	Owner        Owner     `json:"owner"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Registry is implemented by microchip registry clients
+type Registry interface {
+	// Name returns the registry identifier stored on registrations
+	Name() string
+
+	// Lookup finds the current owner registration for a chip
+	Lookup(ctx context.Context, chipNumber string) (*Registration, error)
+
+	// Register records a new owner for a chip, replacing any previous owner
+	Register(ctx context.Context, chipNumber string, owner Owner) (*Registration, error)
+}
+
+// LocalRegistry is an in-memory registry used for development and tests
+type LocalRegistry struct {
+	mu            sync.RWMutex
+	registrations map[string]Registration
+}
+
+// NewLocalRegistry creates a new in-memory registry
+func NewLocalRegistry() *LocalRegistry {
+	return &LocalRegistry{
+		registrations: make(map[string]Registration),
+	}
+}
+
+// Name returns the registry identifier
+func (r *LocalRegistry) Name() string {
+	return "local"
+}
+
+// Lookup finds the owner registration for a chip
+func (r *LocalRegistry) Lookup(ctx context.Context, chipNumber string) (*Registration, error) {
+	normalized, err := Validate(chipNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, ok := r.registrations[normalized]
+	if !ok {
+		return nil, errors.NewNotFound("microchip is not registered")
+	}
+
+	return &registration, nil
+}
+
+// Register records a new owner for a chip
+func (r *LocalRegistry) Register(ctx context.Context, chipNumber string, owner Owner) (*Registration, error) {
+	normalized, err := Validate(chipNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner.Name == "" {
+		return nil, errors.NewBadRequest("owner name is required")
+	}
+
+	registration := Registration{
+		ChipNumber:   normalized,
+		Registry:     r.Name(),
+		Reference:    primitive.NewObjectID().Hex(),
+		Owner:        owner,
+		RegisteredAt: time.Now(),
+	}
+
+	r.mu.Lock()
+	r.registrations[normalized] = registration
+	r.mu.Unlock()
+
+	return &registration, nil
+}
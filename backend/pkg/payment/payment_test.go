@@ -0,0 +1,122 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripeGateway_CreateCheckout(t *testing.T) {
+	expires := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		assert.Equal(t, "sk_test", user)
+		assert.Equal(t, "/v1/checkout/sessions", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "payment", r.PostForm.Get("mode"))
+		assert.Equal(t, "event_attendance:42", r.PostForm.Get("client_reference_id"))
+		assert.Equal(t, "pln", r.PostForm.Get("line_items[0][price_data][currency]"))
+		assert.Equal(t, "4590", r.PostForm.Get("line_items[0][price_data][unit_amount]"))
+		assert.Equal(t, fmt.Sprint(expires.Unix()), r.PostForm.Get("expires_at"))
+		fmt.Fprintf(w, `{"id":"cs_test_1","url":"https://checkout.stripe.com/c/pay/cs_test_1","expires_at":%d}`, expires.Unix())
+	}))
+	defer server.Close()
+
+	gateway := NewStripeGateway("sk_test", "whsec")
+	gateway.apiURL = server.URL
+
+	checkout, err := gateway.CreateCheckout(context.Background(), CheckoutRequest{
+		Reference:   "event_attendance:42",
+		Description: "Adoption Day: 3 tickets",
+		Amount:      45.90,
+		Currency:    "PLN",
+		SuccessURL:  "https://shelter.example.org/tickets/ok",
+		CancelURL:   "https://shelter.example.org/tickets/cancel",
+		ExpiresAt:   expires,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "cs_test_1", checkout.SessionID)
+	assert.Equal(t, "https://checkout.stripe.com/c/pay/cs_test_1", checkout.URL)
+	assert.True(t, expires.Equal(checkout.ExpiresAt))
+}
+
+func TestStripeGateway_CreateCheckout_ProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"Invalid currency"}}`)
+	}))
+	defer server.Close()
+
+	gateway := NewStripeGateway("sk_test", "whsec")
+	gateway.apiURL = server.URL
+
+	_, err := gateway.CreateCheckout(context.Background(), CheckoutRequest{Amount: 10, Currency: "xyz"})
+
+	assert.EqualError(t, err, "payment provider: Invalid currency")
+}
+
+func TestStripeGateway_ParseNotification(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	gateway := NewStripeGateway("sk_test", "whsec")
+	gateway.now = func() time.Time { return now }
+
+	sign := func(payload string, at time.Time, secret string) http.Header {
+		mac := hmac.New(sha256.New, []byte(secret))
+		fmt.Fprintf(mac, "%d.%s", at.Unix(), payload)
+		header := http.Header{}
+		header.Set("Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", at.Unix(), hex.EncodeToString(mac.Sum(nil))))
+		return header
+	}
+	completed := `{"type":"checkout.session.completed","data":{"object":{"id":"cs_1","client_reference_id":"event_attendance:42","payment_intent":"pi_1","payment_status":"paid","amount_total":4590}}}`
+
+	notification, err := gateway.ParseNotification([]byte(completed), sign(completed, now, "whsec"))
+	require.NoError(t, err)
+	assert.Equal(t, &Notification{SessionID: "cs_1", Reference: "event_attendance:42", Status: StatusPaid, TransactionID: "pi_1", Amount: 45.90}, notification)
+
+	_, err = gateway.ParseNotification([]byte(completed), sign(completed, now, "other"))
+	assert.EqualError(t, err, "invalid webhook signature")
+
+	_, err = gateway.ParseNotification([]byte(completed), sign(completed, now.Add(-10*time.Minute), "whsec"))
+	assert.EqualError(t, err, "webhook signature is too old")
+
+	unsigned := NewStripeGateway("sk_test", "")
+	unsigned.now = gateway.now
+	_, err = unsigned.ParseNotification([]byte(completed), sign(completed, now, ""))
+	assert.EqualError(t, err, "webhook signing secret is not configured")
+
+	expired := `{"type":"checkout.session.expired","data":{"object":{"id":"cs_2","client_reference_id":"event_attendance:43"}}}`
+	notification, err = gateway.ParseNotification([]byte(expired), sign(expired, now, "whsec"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, notification.Status)
+
+	other := `{"type":"customer.created","data":{"object":{"id":"cus_1"}}}`
+	notification, err = gateway.ParseNotification([]byte(other), sign(other, now, "whsec"))
+	require.NoError(t, err)
+	assert.Nil(t, notification)
+}
+
+func TestFakeGateway(t *testing.T) {
+	gateway := NewFakeGateway()
+
+	checkout, err := gateway.CreateCheckout(context.Background(), CheckoutRequest{Reference: "ref", Amount: 20, SuccessURL: "https://example.org/ok"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/ok", checkout.URL)
+
+	notification, err := gateway.ParseNotification([]byte(`{"session_id":"`+checkout.SessionID+`","status":"paid"}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ref", notification.Reference)
+	assert.Equal(t, StatusPaid, notification.Status)
+
+	_, err = gateway.ParseNotification([]byte(`{"session_id":"fake_unknown","status":"paid"}`), nil)
+	assert.Error(t, err)
+}
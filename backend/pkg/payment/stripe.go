@@ -0,0 +1,194 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// stripeAPIURL is the base URL of the Stripe API
+const stripeAPIURL = "https://api.stripe.com"
+
+// signatureTolerance is how old a signed webhook request may be, against replays
+const signatureTolerance = 5 * time.Minute
+
+// StripeGateway takes payments through Stripe Checkout
+type StripeGateway struct {
+	secretKey     string
+	webhookSecret string
+	apiURL        string
+	client        *http.Client
+	now           func() time.Time
+}
+
+// NewStripeGateway creates a Stripe client with the secret API key and the signing
+// secret of the webhook endpoint
+func NewStripeGateway(secretKey, webhookSecret string) *StripeGateway {
+	return &StripeGateway{
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		apiURL:        stripeAPIURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		now:           time.Now,
+	}
+}
+
+// Name returns the provider identifier
+func (g *StripeGateway) Name() string {
+	return "stripe"
+}
+
+// CreateCheckout creates a Checkout Session for a single line item
+func (g *StripeGateway) CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", req.SuccessURL)
+	form.Set("cancel_url", req.CancelURL)
+	form.Set("client_reference_id", req.Reference)
+	form.Set("metadata[reference]", req.Reference)
+	if req.CustomerEmail != "" {
+		form.Set("customer_email", req.CustomerEmail)
+	}
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", strings.ToLower(req.Currency))
+	form.Set("line_items[0][price_data][unit_amount]", strconv.FormatInt(int64(math.Round(req.Amount*100)), 10))
+	form.Set("line_items[0][price_data][product_data][name]", req.Description)
+	if !req.ExpiresAt.IsZero() {
+		form.Set("expires_at", strconv.FormatInt(req.ExpiresAt.Unix(), 10))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.apiURL+"/v1/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(g.secretKey, "")
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.Wrap(err, http.StatusBadGateway, "payment provider is unavailable")
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		ID        string `json:"id"`
+		URL       string `json:"url"`
+		ExpiresAt int64  `json:"expires_at"`
+		Error     *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, http.StatusBadGateway, "invalid response from the payment provider")
+	}
+	if resp.StatusCode >= 300 {
+		message := resp.Status
+		if body.Error != nil {
+			message = body.Error.Message
+		}
+		return nil, errors.New(http.StatusBadGateway, "payment provider: "+message)
+	}
+
+	return &Checkout{SessionID: body.ID, URL: body.URL, ExpiresAt: time.Unix(body.ExpiresAt, 0)}, nil
+}
+
+// ParseNotification verifies the Stripe-Signature header of a webhook request and
+// reads the Checkout Session events
+func (g *StripeGateway) ParseNotification(payload []byte, header http.Header) (*Notification, error) {
+	if err := g.verifySignature(payload, header.Get("Stripe-Signature")); err != nil {
+		return nil, err
+	}
+
+	var event struct {
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID                string `json:"id"`
+				ClientReferenceID string `json:"client_reference_id"`
+				PaymentIntent     string `json:"payment_intent"`
+				PaymentStatus     string `json:"payment_status"`
+				AmountTotal       int64  `json:"amount_total"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errors.NewBadRequest("invalid notification")
+	}
+
+	var status Status
+	session := event.Data.Object
+	switch event.Type {
+	case "checkout.session.completed":
+		// Delayed payment methods complete the session before the money arrives
+		if session.PaymentStatus != "paid" {
+			return nil, nil
+		}
+		status = StatusPaid
+	case "checkout.session.async_payment_succeeded":
+		status = StatusPaid
+	case "checkout.session.async_payment_failed":
+		status = StatusFailed
+	case "checkout.session.expired":
+		status = StatusExpired
+	default:
+		return nil, nil
+	}
+
+	return &Notification{
+		SessionID:     session.ID,
+		Reference:     session.ClientReferenceID,
+		Status:        status,
+		TransactionID: session.PaymentIntent,
+		Amount:        float64(session.AmountTotal) / 100,
+	}, nil
+}
+
+// verifySignature checks the HMAC-SHA256 signature of "timestamp.payload" against
+// the v1 signatures of the header
+func (g *StripeGateway) verifySignature(payload []byte, header string) error {
+	// With an empty secret anyone could sign a notification
+	if g.webhookSecret == "" {
+		return errors.NewUnauthorized("webhook signing secret is not configured")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errors.NewUnauthorized("missing webhook signature")
+	}
+	if age := g.now().Sub(time.Unix(seconds, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return errors.NewUnauthorized("webhook signature is too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(g.webhookSecret))
+	fmt.Fprintf(mac, "%s.%s", timestamp, payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.NewUnauthorized("invalid webhook signature")
+}
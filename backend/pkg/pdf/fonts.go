@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Font is one of the standard fonts documents are set in
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+func (f Font) resource() string {
+	if f == Bold {
+		return "F2"
+	}
+	return "F1"
+}
+
+func (f Font) baseFont() string {
+	if f == Bold {
+		return "Helvetica-Bold"
+	}
+	return "Helvetica"
+}
+
+// The fonts use WinAnsiEncoding with the Polish letters replacing the rarely
+// used glyphs at 128-143, so Latin-1 and Polish text can be set without
+// embedding a font.
+var differences = []string{
+	"Aogonek", "aogonek", "Cacute", "cacute", "Eogonek", "eogonek", "Lslash", "lslash",
+	"Nacute", "nacute", "Sacute", "sacute", "Zacute", "zacute", "Zdotaccent", "zdotaccent",
+}
+
+var special = map[rune]byte{
+	'Ą': 128, 'ą': 129, 'Ć': 130, 'ć': 131, 'Ę': 132, 'ę': 133, 'Ł': 134, 'ł': 135,
+	'Ń': 136, 'ń': 137, 'Ś': 138, 'ś': 139, 'Ź': 140, 'ź': 141, 'Ż': 142, 'ż': 143,
+	'‘': 145, '’': 146, '“': 147, '”': 148, '•': 149, '–': 150, '—': 151,
+}
+
+// baseLetters are the letters whose width the special glyphs share
+var baseLetters = map[byte]byte{
+	128: 'A', 129: 'a', 130: 'C', 131: 'c', 132: 'E', 133: 'e', 134: 'L', 135: 'l',
+	136: 'N', 137: 'n', 138: 'S', 139: 's', 140: 'Z', 141: 'z', 142: 'Z', 143: 'z',
+}
+
+// Advance widths of the printable ASCII characters (32-126) in 1/1000 em,
+// from the Adobe font metrics of the standard fonts
+var asciiWidths = [2][95]int{
+	{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// encodeRune maps a character to its code in the font encoding. Characters
+// outside of it are set without their accents, or as a question mark.
+func encodeRune(r rune) (byte, bool) {
+	switch {
+	case r == '\t':
+		return ' ', true
+	case r < 32 || r == 127:
+		return 0, false
+	case r < 127 || (r >= 160 && r <= 255):
+		return byte(r), true
+	}
+	if code, ok := special[r]; ok {
+		return code, true
+	}
+	for _, base := range norm.NFD.String(string(r)) {
+		if base < 127 && !unicode.Is(unicode.Mn, base) {
+			return byte(base), true
+		}
+		break
+	}
+	return '?', true
+}
+
+// encode converts text to the font encoding
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		if code, ok := encodeRune(r); ok {
+			out = append(out, code)
+		}
+	}
+	return out
+}
+
+// glyphWidth returns the advance width of a code in 1/1000 em
+func glyphWidth(font Font, code byte) int {
+	if code >= 32 && code <= 126 {
+		return asciiWidths[font][code-32]
+	}
+	if base, ok := baseLetters[code]; ok {
+		return asciiWidths[font][base-32]
+	}
+	switch code {
+	case 145, 146:
+		return [2]int{222, 278}[font]
+	case 147, 148:
+		return [2]int{333, 500}[font]
+	case 149:
+		return 350
+	case 150:
+		return 556
+	case 151:
+		return 1000
+	}
+	if code >= 192 {
+		// Accented Latin-1 letters are as wide as the letter itself
+		for _, base := range norm.NFD.String(string(rune(code))) {
+			if base >= 32 && base <= 126 {
+				return asciiWidths[font][base-32]
+			}
+			break
+		}
+	}
+	return 556
+}
+
+// TextWidth returns the width of text set in the font at the size, in points
+func TextWidth(font Font, size float64, text string) float64 {
+	total := 0
+	for _, code := range encode(text) {
+		total += glyphWidth(font, code)
+	}
+	return float64(total) * size / 1000
+}
@@ -0,0 +1,54 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+)
+
+// picture is an image embedded once and drawn by its resource name
+type picture struct {
+	width  int
+	height int
+	data   []byte // RGB samples, deflated
+}
+
+// Image adds an image scaled to fit within width by height points, keeping its
+// aspect ratio. Transparent areas are flattened onto white.
+func (d *Document) Image(img image.Image, width, height float64) {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || width <= 0 || height <= 0 {
+		return
+	}
+
+	scale := min(width/float64(bounds.Dx()), height/float64(bounds.Dy()))
+	w, h := float64(bounds.Dx())*scale, float64(bounds.Dy())*scale
+
+	d.images = append(d.images, encodeImage(img))
+	d.ensure(h)
+	fmt.Fprintf(d.page, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", num(w), num(h), num(margin), num(d.y-h), len(d.images))
+	d.y -= h
+	d.Space(3)
+}
+
+// encodeImage converts an image to deflated 8-bit RGB samples
+func encodeImage(img image.Image) picture {
+	bounds := img.Bounds()
+	samples := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Colors are alpha-premultiplied, so adding the uncovered share of white flattens them
+			white := 0xffff - a
+			samples = append(samples, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	var data bytes.Buffer
+	w := zlib.NewWriter(&data)
+	_, _ = w.Write(samples)
+	_ = w.Close()
+
+	return picture{width: bounds.Dx(), height: bounds.Dy(), data: data.Bytes()}
+}
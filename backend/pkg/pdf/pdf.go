@@ -0,0 +1,462 @@
+// Package pdf writes simple flowing A4 documents: titles, headings,
+// paragraphs, label/value fields and tables set in the standard PDF fonts.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 50.0
+	footerY    = 30.0
+	textWidth  = pageWidth - 2*margin
+
+	titleSize   = 18.0
+	headingSize = 13.0
+	bodySize    = 10.0
+	tableSize   = 9.0
+	smallSize   = 8.0
+
+	fieldLabelWidth = 150.0
+	cellPadding     = 3.0
+)
+
+// Column is a table column; Width is its share of the text width
+type Column struct {
+	Header string
+	Width  float64
+}
+
+// Document is a PDF document under construction. Content flows from the top
+// of the first page and continues on new pages as needed.
+type Document struct {
+	title   string
+	author  string
+	subject string
+	footer  string
+	created time.Time
+
+	pages  []*bytes.Buffer
+	page   *bytes.Buffer
+	y      float64
+	images []picture
+}
+
+// New creates an empty document with the title in its metadata
+func New(title string) *Document {
+	return &Document{title: title, created: time.Now()}
+}
+
+// SetAuthor sets the author in the document metadata
+func (d *Document) SetAuthor(author string) {
+	d.author = author
+}
+
+// SetSubject sets the subject in the document metadata
+func (d *Document) SetSubject(subject string) {
+	d.subject = subject
+}
+
+// SetCreated sets the creation date in the document metadata
+func (d *Document) SetCreated(created time.Time) {
+	d.created = created
+}
+
+// SetFooter sets the text printed at the bottom of every page, next to the page number
+func (d *Document) SetFooter(footer string) {
+	d.footer = footer
+}
+
+// PageCount returns the number of pages so far
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Title adds the document title
+func (d *Document) Title(text string) {
+	d.paragraph(Bold, titleSize, 0, text)
+	d.Space(6)
+}
+
+// Heading starts a section; it is kept on the same page as the first lines after it
+func (d *Document) Heading(text string) {
+	if d.page != nil && d.y < pageHeight-margin {
+		d.Space(10)
+	}
+	d.ensure(lineHeight(headingSize) + 4 + 2*lineHeight(bodySize))
+	d.paragraph(Bold, headingSize, 0, text)
+	fmt.Fprintf(d.page, "0.5 w 0.6 G %s %s m %s %s l S 0 G\n", num(margin), num(d.y+2), num(pageWidth-margin), num(d.y+2))
+	d.Space(4)
+}
+
+// Text adds a paragraph; line breaks in the text are kept
+func (d *Document) Text(text string) {
+	d.paragraph(Regular, bodySize, 0, text)
+	d.Space(3)
+}
+
+// Small adds a paragraph in small grey type, for notes and disclaimers
+func (d *Document) Small(text string) {
+	d.ensurePage()
+	fmt.Fprint(d.page, "0.4 g\n")
+	d.paragraph(Regular, smallSize, 0, text)
+	fmt.Fprint(d.page, "0 g\n")
+	d.Space(3)
+}
+
+// Field adds a label with its value beside it
+func (d *Document) Field(label, value string) {
+	if strings.TrimSpace(value) == "" {
+		value = "-"
+	}
+	labels := wrap(Bold, bodySize, label, fieldLabelWidth-8)
+	values := wrap(Regular, bodySize, value, textWidth-fieldLabelWidth)
+	for i := 0; i < max(len(labels), len(values)); i++ {
+		d.ensure(lineHeight(bodySize))
+		if i < len(labels) {
+			d.write(Bold, bodySize, margin, d.y-bodySize, labels[i])
+		}
+		if i < len(values) {
+			d.write(Regular, bodySize, margin+fieldLabelWidth, d.y-bodySize, values[i])
+		}
+		d.y -= lineHeight(bodySize)
+	}
+}
+
+// Table adds a table; the header row is repeated on every page the table spans
+func (d *Document) Table(columns []Column, rows [][]string) {
+	if len(columns) == 0 {
+		return
+	}
+
+	total := 0.0
+	for _, column := range columns {
+		total += column.Width
+	}
+	widths := make([]float64, len(columns))
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		if total > 0 {
+			widths[i] = textWidth * column.Width / total
+		} else {
+			widths[i] = textWidth / float64(len(columns))
+		}
+		headers[i] = column.Header
+	}
+
+	header := d.layoutRow(Bold, widths, headers)
+	first := header
+	if len(rows) > 0 {
+		first += d.layoutRow(Regular, widths, rows[0])
+	}
+	d.ensure(first)
+	d.drawRow(Bold, widths, headers, true)
+
+	for _, row := range rows {
+		if d.y-d.layoutRow(Regular, widths, row) < margin {
+			d.newPage()
+			d.drawRow(Bold, widths, headers, true)
+		}
+		d.drawRow(Regular, widths, row, false)
+	}
+	d.Space(6)
+}
+
+// Check adds a checkbox, ticked or empty, with its text beside it
+func (d *Document) Check(checked bool, text string) {
+	const box, indent = 8.0, 16.0
+	lines := wrap(Regular, bodySize, text, textWidth-indent)
+	d.ensure(lineHeight(bodySize))
+	top := d.y - (lineHeight(bodySize)-box)/2
+	fmt.Fprintf(d.page, "0.7 w %s %s %s %s re S\n", num(margin), num(top-box), num(box), num(box))
+	if checked {
+		fmt.Fprintf(d.page, "1 w %s %s m %s %s l %s %s l S\n",
+			num(margin+1.5), num(top-box/2), num(margin+box/2-0.5), num(top-box+1.5), num(margin+box-1), num(top-1))
+	}
+	for i, line := range lines {
+		if i > 0 {
+			d.ensure(lineHeight(bodySize))
+		}
+		d.write(Regular, bodySize, margin+indent, d.y-bodySize, line)
+		d.y -= lineHeight(bodySize)
+	}
+	d.Space(3)
+}
+
+// Space adds vertical space in points
+func (d *Document) Space(points float64) {
+	d.ensurePage()
+	d.y -= points
+}
+
+// PageBreak continues the document on a new page
+func (d *Document) PageBreak() {
+	d.newPage()
+}
+
+// Bytes renders the document as a PDF file
+func (d *Document) Bytes() []byte {
+	d.ensurePage()
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-6 are fixed; every page adds a page object and its content stream,
+	// and the images follow the pages
+	const firstPage = 7
+	firstImage := firstPage + 2*len(d.pages)
+	resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+	if len(d.images) > 0 {
+		names := make([]string, len(d.images))
+		for i := range d.images {
+			names[i] = fmt.Sprintf("/Im%d %d 0 R", i+1, firstImage+i)
+		}
+		resources += " /XObject << " + strings.Join(names, " ") + " >>"
+	}
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding 5 0 R >>", Regular.baseFont()))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding 5 0 R >>", Bold.baseFont()))
+	object(fmt.Sprintf("<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [128 /%s] >>", strings.Join(differences, " /")))
+
+	info := []string{"/Producer (animalsys)", "/CreationDate " + textString(pdfDate(d.created))}
+	if d.title != "" {
+		info = append(info, "/Title "+textString(d.title))
+	}
+	if d.author != "" {
+		info = append(info, "/Author "+textString(d.author))
+	}
+	if d.subject != "" {
+		info = append(info, "/Subject "+textString(d.subject))
+	}
+	object("<< " + strings.Join(info, " ") + " >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << %s >> /Contents %d 0 R >>",
+			num(pageWidth), num(pageHeight), resources, firstPage+2*i+1))
+
+		content := page.String() + d.footerContent(i+1)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+	for _, img := range d.images {
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			img.width, img.height, len(img.data), img.data))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// footerContent draws the footer text and the page number
+func (d *Document) footerContent(number int) string {
+	var footer bytes.Buffer
+	footer.WriteString("0.4 g\n")
+	if d.footer != "" {
+		text := d.footer
+		for TextWidth(Regular, smallSize, text) > textWidth-60 && len(text) > 1 {
+			_, size := utf8.DecodeLastRuneInString(text)
+			text = text[:len(text)-size]
+		}
+		fmt.Fprintf(&footer, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", num(smallSize), num(margin), num(footerY), escape(encode(text)))
+	}
+	pageNumber := fmt.Sprintf("%d / %d", number, len(d.pages))
+	x := pageWidth - margin - TextWidth(Regular, smallSize, pageNumber)
+	fmt.Fprintf(&footer, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", num(smallSize), num(x), num(footerY), escape(encode(pageNumber)))
+	footer.WriteString("0 g")
+	return footer.String()
+}
+
+// paragraph sets wrapped text at the left margin plus indent
+func (d *Document) paragraph(font Font, size, indent float64, text string) {
+	for _, line := range wrap(font, size, text, textWidth-indent) {
+		d.ensure(lineHeight(size))
+		d.write(font, size, margin+indent, d.y-size, line)
+		d.y -= lineHeight(size)
+	}
+}
+
+// layoutRow returns the height of a table row
+func (d *Document) layoutRow(font Font, widths []float64, cells []string) float64 {
+	lines := 1
+	for i, width := range widths {
+		if i < len(cells) {
+			if n := len(wrap(font, tableSize, cells[i], width-2*cellPadding)); n > lines {
+				lines = n
+			}
+		}
+	}
+	return float64(lines)*lineHeight(tableSize) + 2*cellPadding
+}
+
+// drawRow draws a table row at the current position
+func (d *Document) drawRow(font Font, widths []float64, cells []string, shaded bool) {
+	height := d.layoutRow(font, widths, cells)
+	if shaded {
+		fmt.Fprintf(d.page, "0.9 g %s %s %s %s re f 0 g\n", num(margin), num(d.y-height), num(textWidth), num(height))
+	}
+
+	x := margin
+	for i, width := range widths {
+		if i < len(cells) {
+			y := d.y - cellPadding - tableSize
+			for _, line := range wrap(font, tableSize, cells[i], width-2*cellPadding) {
+				d.write(font, tableSize, x+cellPadding, y, line)
+				y -= lineHeight(tableSize)
+			}
+		}
+		x += width
+	}
+
+	d.y -= height
+	fmt.Fprintf(d.page, "0.5 w 0.75 G %s %s m %s %s l S 0 G\n", num(margin), num(d.y), num(pageWidth-margin), num(d.y))
+}
+
+// write sets a line of text with its baseline at x, y
+func (d *Document) write(font Font, size, x, y float64, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(d.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font.resource(), num(size), num(x), num(y), escape(encode(text)))
+}
+
+// ensure starts a new page unless height fits above the bottom margin
+func (d *Document) ensure(height float64) {
+	if d.page == nil || d.y-height < margin {
+		d.newPage()
+	}
+}
+
+func (d *Document) ensurePage() {
+	if d.page == nil {
+		d.newPage()
+	}
+}
+
+func (d *Document) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pageHeight - margin
+}
+
+// wrap breaks text into lines no wider than width; words longer than a line are split
+func wrap(font Font, size float64, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for TextWidth(font, size, word) > width {
+				cut := fit(font, size, word, width)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit returns the length of the longest prefix of word that fits in width, at least one character
+func fit(font Font, size float64, word string, width float64) int {
+	cut := 0
+	for i, r := range word {
+		end := i + utf8.RuneLen(r)
+		if cut > 0 && TextWidth(font, size, word[:end]) > width {
+			break
+		}
+		cut = end
+	}
+	return cut
+}
+
+func lineHeight(size float64) float64 {
+	return size * 1.3
+}
+
+// escape writes encoded text as the contents of a PDF literal string
+func escape(text []byte) string {
+	var out strings.Builder
+	for _, b := range text {
+		switch {
+		case b == '(' || b == ')' || b == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(b)
+		case b < 32 || b > 126:
+			fmt.Fprintf(&out, "\\%03o", b)
+		default:
+			out.WriteByte(b)
+		}
+	}
+	return out.String()
+}
+
+// textString encodes metadata as a PDF text string, in UTF-16 when it is not ASCII
+func textString(text string) string {
+	ascii := true
+	for _, r := range text {
+		if r > 126 || r < 32 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + escape([]byte(text)) + ")"
+	}
+
+	var out strings.Builder
+	out.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&out, "%04X", unit)
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+func pdfDate(t time.Time) string {
+	return "D:" + t.UTC().Format("20060102150405") + "Z"
+}
+
+// num formats a coordinate with at most two decimals
+func num(value float64) string {
+	s := strconv.FormatFloat(value, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
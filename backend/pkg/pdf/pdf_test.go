@@ -0,0 +1,131 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Bytes(t *testing.T) {
+	doc := New("Karta zdrowia – Żabka")
+	doc.SetCreated(time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC))
+	doc.SetFooter("Schronisko (Kraków)")
+	doc.Title("Karta zdrowia")
+	doc.Field("Imię", "Żabka")
+	doc.Text("Łagodna, lubi dzieci \\ koty")
+
+	out := doc.Bytes()
+	content := string(out)
+
+	assert.True(t, strings.HasPrefix(content, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(content, "%%EOF\n"))
+	assert.Contains(t, content, "/BaseFont /Helvetica /Encoding 5 0 R")
+	assert.Contains(t, content, "/Differences [128 /Aogonek /aogonek")
+	assert.Contains(t, content, "/CreationDate (D:20260304100000Z)")
+	assert.Contains(t, content, "/Title <FEFF004B", "non-ASCII metadata is written in UTF-16")
+
+	assert.Contains(t, content, "(Imi\\205)", "Polish letters are mapped into the replaced glyphs")
+	assert.Contains(t, content, "(\\216abka)")
+	assert.Contains(t, content, "(\\206agodna, lubi dzieci \\\\ koty)")
+	assert.Contains(t, content, "(Schronisko \\(Krak\\363w\\))", "ó is set from the Latin-1 range")
+	assert.Contains(t, content, "(1 / 1)")
+
+	assertValidXref(t, out)
+}
+
+func TestDocument_TableRepeatsHeaderOnNewPages(t *testing.T) {
+	doc := New("Vaccinations")
+	columns := []Column{{Header: "Date", Width: 1}, {Header: "Vaccine", Width: 3}}
+	var rows [][]string
+	for i := 0; i < 80; i++ {
+		rows = append(rows, []string{"2026-01-01", fmt.Sprintf("Rabies dose %d", i+1)})
+	}
+	doc.Table(columns, rows)
+
+	out := doc.Bytes()
+	require.Greater(t, doc.PageCount(), 1)
+	assert.Equal(t, doc.PageCount(), strings.Count(string(out), "(Vaccine)"))
+	assert.Contains(t, string(out), "(Rabies dose 80)")
+	assert.Contains(t, string(out), fmt.Sprintf("(%d / %d)", doc.PageCount(), doc.PageCount()))
+	assertValidXref(t, out)
+}
+
+func TestDocument_Image(t *testing.T) {
+	signature := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	signature.Set(0, 0, color.NRGBA{A: 255})
+	signature.Set(1, 0, color.NRGBA{R: 255, A: 128})
+
+	doc := New("Contract")
+	doc.Check(true, "I agree to the return policy")
+	doc.Check(false, "I agree to follow-up visits")
+	doc.Image(signature, 200, 50)
+	doc.Text("Jan Kowalski")
+
+	out := doc.Bytes()
+	content := string(out)
+	assert.Contains(t, content, "/XObject << /Im1 9 0 R >>")
+	assert.Contains(t, content, "q 100 0 0 50 50 ", "the image is scaled to fit the height")
+	assert.Contains(t, content, "/Im1 Do Q")
+	assert.Contains(t, content, "/Subtype /Image /Width 4 /Height 2 /ColorSpace /DeviceRGB")
+	assert.Equal(t, 2, strings.Count(content, " re S\n"), "every consent gets a box")
+	assertValidXref(t, out)
+
+	stream := regexp.MustCompile(`(?s)/FlateDecode /Length (\d+) >>\nstream\n`).FindSubmatchIndex(out)
+	require.NotNil(t, stream)
+	length, _ := strconv.Atoi(string(out[stream[2]:stream[3]]))
+	r, err := zlib.NewReader(bytes.NewReader(out[stream[1] : stream[1]+length]))
+	require.NoError(t, err)
+	samples, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Len(t, samples, 4*2*3)
+	assert.Equal(t, []byte{0, 0, 0}, samples[0:3], "opaque black is kept")
+	assert.Equal(t, []byte{255, 127, 127}, samples[3:6], "half transparent red is blended onto white")
+	assert.Equal(t, []byte{255, 255, 255}, samples[6:9], "transparent pixels become white")
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap(Regular, 10, "one two three\n\nfour", TextWidth(Regular, 10, "one two"))
+	assert.Equal(t, []string{"one two", "three", "", "four"}, lines)
+
+	long := strings.Repeat("w", 40)
+	lines = wrap(Regular, 10, long, 100)
+	require.Greater(t, len(lines), 1)
+	assert.Equal(t, long, strings.Join(lines, ""))
+	for _, line := range lines {
+		assert.LessOrEqual(t, TextWidth(Regular, 10, line), 100.0)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.InDelta(t, 5.56, TextWidth(Regular, 10, "a"), 0.001)
+	assert.Equal(t, TextWidth(Bold, 10, "Zazolc"), TextWidth(Bold, 10, "Zażółć"), "accented letters are as wide as the base letter")
+}
+
+// assertValidXref checks that every cross-reference entry points at its object
+func assertValidXref(t *testing.T, out []byte) {
+	t.Helper()
+
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, start)
+	xref, err := strconv.Atoi(string(start[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
@@ -0,0 +1,555 @@
+// Package qrcode encodes short byte strings, such as signed ticket codes, as QR
+// codes (ISO/IEC 18004, byte mode, versions 1 to 10) and renders them as PNG images.
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// Level is the error correction level of a code
+type Level int
+
+const (
+	Low      Level = iota // Recovers 7% of the codewords
+	Medium                // Recovers 15% of the codewords
+	Quartile              // Recovers 25% of the codewords
+	High                  // Recovers 30% of the codewords
+)
+
+// formatBits returns the level as encoded in the format information
+func (l Level) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// MaxVersion is the largest code the package produces (57x57 modules)
+const MaxVersion = 10
+
+// quietZone is the light border around the code, in modules
+const quietZone = 4
+
+// ErrTooLong is returned for data that does not fit the largest supported code
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// blockLayout describes the error correction blocks of a version at a level
+type blockLayout struct {
+	eccPerBlock int
+	groups      [2][2]int // number of blocks and data codewords per block
+}
+
+// layouts is indexed by version, then level
+var layouts = [MaxVersion + 1][4]blockLayout{
+	1:  {{7, [2][2]int{{1, 19}}}, {10, [2][2]int{{1, 16}}}, {13, [2][2]int{{1, 13}}}, {17, [2][2]int{{1, 9}}}},
+	2:  {{10, [2][2]int{{1, 34}}}, {16, [2][2]int{{1, 28}}}, {22, [2][2]int{{1, 22}}}, {28, [2][2]int{{1, 16}}}},
+	3:  {{15, [2][2]int{{1, 55}}}, {26, [2][2]int{{1, 44}}}, {18, [2][2]int{{2, 17}}}, {22, [2][2]int{{2, 13}}}},
+	4:  {{20, [2][2]int{{1, 80}}}, {18, [2][2]int{{2, 32}}}, {26, [2][2]int{{2, 24}}}, {16, [2][2]int{{4, 9}}}},
+	5:  {{26, [2][2]int{{1, 108}}}, {24, [2][2]int{{2, 43}}}, {18, [2][2]int{{2, 15}, {2, 16}}}, {22, [2][2]int{{2, 11}, {2, 12}}}},
+	6:  {{18, [2][2]int{{2, 68}}}, {16, [2][2]int{{4, 27}}}, {24, [2][2]int{{4, 19}}}, {28, [2][2]int{{4, 15}}}},
+	7:  {{20, [2][2]int{{2, 78}}}, {18, [2][2]int{{4, 31}}}, {18, [2][2]int{{2, 14}, {4, 15}}}, {26, [2][2]int{{4, 13}, {1, 14}}}},
+	8:  {{24, [2][2]int{{2, 97}}}, {22, [2][2]int{{2, 38}, {2, 39}}}, {22, [2][2]int{{4, 18}, {2, 19}}}, {26, [2][2]int{{4, 14}, {2, 15}}}},
+	9:  {{30, [2][2]int{{2, 116}}}, {22, [2][2]int{{3, 36}, {2, 37}}}, {20, [2][2]int{{4, 16}, {4, 17}}}, {24, [2][2]int{{4, 12}, {4, 13}}}},
+	10: {{18, [2][2]int{{2, 68}, {2, 69}}}, {26, [2][2]int{{4, 43}, {1, 44}}}, {24, [2][2]int{{6, 19}, {2, 20}}}, {28, [2][2]int{{6, 15}, {2, 16}}}},
+}
+
+// alignmentPositions is indexed by version
+var alignmentPositions = [MaxVersion + 1][]int{
+	2: {6, 18}, 3: {6, 22}, 4: {6, 26}, 5: {6, 30}, 6: {6, 34},
+	7: {6, 22, 38}, 8: {6, 24, 42}, 9: {6, 26, 46}, 10: {6, 28, 50},
+}
+
+// dataCodewords returns the number of data codewords of a block layout
+func (b blockLayout) dataCodewords() int {
+	return b.groups[0][0]*b.groups[0][1] + b.groups[1][0]*b.groups[1][1]
+}
+
+// Code is an encoded QR code
+type Code struct {
+	Version  int
+	Level    Level
+	Size     int // Modules per side, without the quiet zone
+	modules  [][]bool
+	function [][]bool // Finder, timing, alignment and format modules that masks do not touch
+}
+
+// Encode encodes data in byte mode in the smallest version that fits at the level
+func Encode(data []byte, level Level) (*Code, error) {
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if bitLength(v, len(data)) <= layouts[v][level].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	code := newCode(version, level)
+	code.drawFunctionPatterns()
+	code.drawCodewords(code.interleave(encodeData(data, version, layouts[version][level].dataCodewords())))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormatBits(mask)
+		if penalty := code.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask) // Masks are their own inverse
+	}
+	code.applyMask(best)
+	code.drawFormatBits(best)
+	return code, nil
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Image renders the code with scale pixels per module and a quiet zone around it
+func (c *Code) Image(scale int) *image.Paletted {
+	if scale < 1 {
+		scale = 1
+	}
+	side := (c.Size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[((y+quietZone)*scale+dy)*img.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[(x+quietZone)*scale+dx] = 1
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG renders the code as a PNG image with scale pixels per module
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// countBits returns the size of the character count indicator in byte mode
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// bitLength returns the number of bits to encode n bytes in byte mode
+func bitLength(version, n int) int {
+	return 4 + countBits(version) + 8*n
+}
+
+// encodeData returns the data codewords: mode, length, data, terminator and padding
+func encodeData(data []byte, version, capacity int) []byte {
+	var bits bitBuffer
+	bits.append(0x4, 4) // Byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	terminator := capacity*8 - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := bits.bytes()
+	for pad := byte(0xEC); len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+	return codewords
+}
+
+// interleave splits the data codewords into blocks, adds their error correction
+// codewords and interleaves the blocks
+func (c *Code) interleave(data []byte) []byte {
+	layout := layouts[c.Version][c.Level]
+	divisor := rsDivisor(layout.eccPerBlock)
+
+	var blocks, eccs [][]byte
+	offset := 0
+	for _, group := range layout.groups {
+		for i := 0; i < group[0]; i++ {
+			block := data[offset : offset+group[1]]
+			offset += group[1]
+			blocks = append(blocks, block)
+			eccs = append(eccs, rsRemainder(block, divisor))
+		}
+	}
+
+	var result []byte
+	longest := layout.groups[0][1]
+	if layout.groups[1][1] > longest {
+		longest = layout.groups[1][1]
+	}
+	for i := 0; i < longest; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.eccPerBlock; i++ {
+		for _, ecc := range eccs {
+			result = append(result, ecc[i])
+		}
+	}
+	return result
+}
+
+func newCode(version int, level Level) *Code {
+	size := version*4 + 17
+	code := &Code{Version: version, Level: level, Size: size}
+	code.modules = make([][]bool, size)
+	code.function = make([][]bool, size)
+	for i := range code.modules {
+		code.modules[i] = make([]bool, size)
+		code.function[i] = make([]bool, size)
+	}
+	return code
+}
+
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the finder, timing, alignment and version patterns
+// and reserves the format information modules
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	positions := alignmentPositions[c.Version]
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// The corners with finder patterns have no alignment pattern
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(x, y)
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern and its separator centred on the module
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= c.Size || y < 0 || y >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centred on the module
+func (c *Code) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatInformation returns the 15 format bits of a level and mask
+func formatInformation(level Level, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionInformation returns the 18 version bits of versions 7 and up
+func versionInformation(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawFormatBits draws both copies of the format information and the dark module
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatInformation(c.Level, mask)
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(bits, i))
+	}
+	c.setFunction(8, 7, bit(bits, 6))
+	c.setFunction(8, 8, bit(bits, 7))
+	c.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(bits, i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+// drawVersion draws both copies of the version information of versions 7 and up
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionInformation(c.Version)
+	for i := 0; i < 18; i++ {
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, bit(bits, i))
+		c.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the two-module wide zigzag columns,
+// skipping the function modules. Remainder modules stay light.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // The vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y][x] = bit(int(codewords[i>>3]), 7-i&7)
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by a mask pattern
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to read; the mask with the lowest score is used
+func (c *Code) penalty() int {
+	penalty := 0
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < c.Size; i++ {
+			for j := 0; j < c.Size; j++ {
+				if vertical {
+					line[j] = c.modules[j][i]
+				} else {
+					line[j] = c.modules[i][j]
+				}
+			}
+			penalty += linePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				m := c.modules[y][x]
+				if c.modules[y-1][x] == m && c.modules[y][x-1] == m && c.modules[y-1][x-1] == m {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	percent := dark * 100 / (c.Size * c.Size)
+	return penalty + abs(percent-50)/5*10
+}
+
+// finderLike is the 1:1:3:1:1 pattern that readers mistake for a finder pattern
+var finderLike = []bool{true, false, true, true, true, false, true}
+
+// linePenalty scores runs of one colour and finder-like patterns in a row or column
+func linePenalty(line []bool) int {
+	penalty := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		if !matches(line[i:], finderLike) {
+			continue
+		}
+		if lightRun(line, i-4, i) || lightRun(line, i+len(finderLike), i+len(finderLike)+4) {
+			penalty += 40
+		}
+	}
+	return penalty
+}
+
+func matches(line, pattern []bool) bool {
+	for i, p := range pattern {
+		if line[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// lightRun reports whether the modules from start to end are light; modules
+// outside the code count as light as they are in the quiet zone
+func lightRun(line []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rsDivisor returns the generator polynomial of the Reed-Solomon code of a degree,
+// without the leading coefficient
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords of a block
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer collects bits most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, set := range b {
+		if set {
+			result[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return result
+}
+
+func bit(value, i int) bool {
+	return (value>>i)&1 == 1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,195 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" as version 1-M, from the worked example of the standard
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+
+	ecc := rsRemainder(data, rsDivisor(10))
+
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestFormatAndVersionInformation(t *testing.T) {
+	assert.Equal(t, 0b111011111000100, formatInformation(Low, 0))
+	assert.Equal(t, 0b101010000010010, formatInformation(Medium, 0))
+	assert.Equal(t, 0b011010101011111, formatInformation(Quartile, 0))
+	assert.Equal(t, 0b001011010001001, formatInformation(High, 0))
+	assert.Equal(t, 0b100000011001110, formatInformation(Medium, 5))
+	assert.Equal(t, 0b100101010100000, formatInformation(Medium, 7))
+	assert.Equal(t, 0b000111110010010100, versionInformation(7))
+	assert.Equal(t, 0b001010010011010011, versionInformation(10))
+}
+
+func TestEncode_PicksTheSmallestVersion(t *testing.T) {
+	code, err := Encode([]byte("hello"), Medium)
+	require.NoError(t, err)
+	assert.Equal(t, 1, code.Version)
+	assert.Equal(t, 21, code.Size)
+
+	code, err = Encode([]byte(strings.Repeat("x", 84)), Medium)
+	require.NoError(t, err)
+	assert.Equal(t, 5, code.Version)
+
+	_, err = Encode([]byte(strings.Repeat("x", 214)), Medium)
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestEncode_FunctionPatterns(t *testing.T) {
+	code, err := Encode([]byte("https://example.org/tickets/abc"), Medium)
+	require.NoError(t, err)
+
+	// Finder patterns in three corners: dark ring, light ring, dark 3x3 centre
+	for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+		x, y := corner[0], corner[1]
+		assert.True(t, code.Dark(x, y))
+		assert.False(t, code.Dark(x+1, y+1))
+		assert.True(t, code.Dark(x+3, y+3))
+	}
+	for i := 8; i < code.Size-8; i++ {
+		assert.Equal(t, i%2 == 0, code.Dark(i, 6), "horizontal timing pattern")
+		assert.Equal(t, i%2 == 0, code.Dark(6, i), "vertical timing pattern")
+	}
+	assert.True(t, code.Dark(8, code.Size-8), "dark module")
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, level := range []Level{Low, Medium, Quartile, High} {
+		for _, n := range []int{1, 17, 68, 120} {
+			data := []byte(strings.Repeat("Ticket-0123456789.", 10)[:n])
+			code, err := Encode(data, level)
+			if err == ErrTooLong {
+				continue
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data, decode(t, code), "level %d, %d bytes", level, n)
+		}
+	}
+}
+
+func TestCode_PNG(t *testing.T) {
+	code, err := Encode([]byte("hello"), Medium)
+	require.NoError(t, err)
+
+	out, err := code.PNG(4)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, (21+2*quietZone)*4, img.Bounds().Dx())
+	r, _, _, _ := img.At(quietZone*4, quietZone*4).RGBA()
+	assert.Zero(t, r, "the top left module is dark")
+	r, _, _, _ = img.At(0, 0).RGBA()
+	assert.NotZero(t, r, "the quiet zone is light")
+}
+
+// decode reads the data back from a code the way a reader would: it finds the mask
+// in the format information, unmasks the data modules, reads the zigzag, removes
+// the interleaving and parses the byte mode segment
+func decode(t *testing.T, code *Code) []byte {
+	t.Helper()
+
+	format := 0
+	for i := 0; i < 8; i++ {
+		if code.Dark(code.Size-1-i, 8) {
+			format |= 1 << i
+		}
+	}
+	for i := 8; i < 15; i++ {
+		if code.Dark(8, code.Size-15+i) {
+			format |= 1 << i
+		}
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatInformation(code.Level, m) == format {
+			mask = m
+		}
+	}
+	require.NotEqual(t, -1, mask, "format information")
+
+	unmasked := newCode(code.Version, code.Level)
+	unmasked.drawFunctionPatterns()
+	for y := range code.modules {
+		copy(unmasked.modules[y], code.modules[y])
+	}
+	unmasked.applyMask(mask)
+
+	var bits bitBuffer
+	for right := code.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < code.Size; vert++ {
+			y := vert
+			if upward {
+				y = code.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if x := right - j; !unmasked.function[y][x] {
+					bits = append(bits, unmasked.modules[y][x])
+				}
+			}
+		}
+	}
+	codewords := bits.bytes()
+
+	layout := layouts[code.Version][code.Level]
+	var lengths []int
+	for _, group := range layout.groups {
+		for i := 0; i < group[0]; i++ {
+			lengths = append(lengths, group[1])
+		}
+	}
+	blocks := make([][]byte, len(lengths))
+	next := 0
+	for i := 0; next < layout.dataCodewords(); i++ {
+		for b, length := range lengths {
+			if i < length {
+				blocks[b] = append(blocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	for b, block := range blocks {
+		var ecc []byte
+		for i := 0; i < layout.eccPerBlock; i++ {
+			ecc = append(ecc, codewords[layout.dataCodewords()+i*len(blocks)+b])
+		}
+		require.Equal(t, rsRemainder(block, rsDivisor(layout.eccPerBlock)), ecc, "error correction of block %d", b)
+	}
+
+	var data bitBuffer
+	for _, block := range blocks {
+		for _, b := range block {
+			data.append(int(b), 8)
+		}
+	}
+	read := func(offset, length int) int {
+		value := 0
+		for i := 0; i < length; i++ {
+			value <<= 1
+			if data[offset+i] {
+				value |= 1
+			}
+		}
+		return value
+	}
+	require.Equal(t, 0x4, read(0, 4), "byte mode")
+	n := read(4, countBits(code.Version))
+	result := make([]byte, n)
+	for i := range result {
+		result[i] = byte(read(4+countBits(code.Version)+8*i, 8))
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// clamdChunkSize is the size of the INSTREAM chunks sent to the daemon
+const clamdChunkSize = 64 << 10
+
+// ClamAVScanner scans content with a clamd daemon using the INSTREAM command
+type ClamAVScanner struct {
+	network string // "tcp" or "unix"
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates a scanner for a clamd address such as
+// "tcp://clamav:3310" or "unix:///var/run/clamav/clamd.ctl"
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network = "unix"
+		address = strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &ClamAVScanner{network: network, address: address, timeout: timeout}
+}
+
+// Name returns the scanner identifier
+func (s *ClamAVScanner) Name() string {
+	return "clamav"
+}
+
+// Scan streams the content to clamd and parses the verdict
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, errors.Wrap(err, 503, "malware scanner is unavailable")
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, errors.Wrap(err, 503, "failed to send file to malware scanner")
+	}
+
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, errors.Wrap(err, 503, "failed to send file to malware scanner")
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, errors.Wrap(err, 503, "failed to send file to malware scanner")
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, errors.Wrap(readErr, 500, "failed to read file for scanning")
+		}
+	}
+
+	// A zero-length chunk terminates the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, errors.Wrap(err, 503, "failed to send file to malware scanner")
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, 503, "failed to read malware scanner reply")
+	}
+
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply parses replies such as "stream: OK" and "stream: Eicar-Signature FOUND"
+func parseClamdReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+
+	switch {
+	case verdict == "OK":
+		return &Result{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Clean: false, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return nil, errors.New(503, fmt.Sprintf("malware scanner error: %s", verdict))
+	}
+}
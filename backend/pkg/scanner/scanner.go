@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// Result is the outcome of a malware scan
+type Result struct {
+	Clean     bool   `json:"clean"`
+	Signature string `json:"signature,omitempty"` // Name of the detected malware
+}
+
+// Scanner is implemented by malware scanning engines
+type Scanner interface {
+	// Name returns the scanner identifier stored on scanned files
+	Name() string
+
+	// Scan reads the content and reports whether it is clean
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// eicarSignature is the industry standard anti-virus test string
+var eicarSignature = []byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+
+// FakeScanner is a local scanner for development and tests.
+// It only detects the EICAR test file.
+type FakeScanner struct{}
+
+// NewFakeScanner creates a new fake scanner
+func NewFakeScanner() *FakeScanner {
+	return &FakeScanner{}
+}
+
+// Name returns the scanner identifier
+func (s *FakeScanner) Name() string {
+	return "fake"
+}
+
+// Scan flags content containing the EICAR test string
+func (s *FakeScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(data, eicarSignature) {
+		return &Result{Clean: false, Signature: "Eicar-Test-Signature"}, nil
+	}
+
+	return &Result{Clean: true}, nil
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeScanner(t *testing.T) {
+	s := NewFakeScanner()
+
+	result, err := s.Scan(context.Background(), strings.NewReader("%PDF-1.4 invoice"))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+
+	result, err = s.Scan(context.Background(), bytes.NewReader(eicarSignature))
+	require.NoError(t, err)
+	assert.False(t, result.Clean)
+	assert.NotEmpty(t, result.Signature)
+}
+
+// fakeClamd accepts one INSTREAM session and answers with FOUND when the EICAR string was sent
+func fakeClamd(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		command, err := r.ReadString(0)
+		if err != nil || command != "zINSTREAM\x00" {
+			return
+		}
+
+		var received bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(r, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&received, r, int64(n)); err != nil {
+				return
+			}
+		}
+
+		if bytes.Contains(received.Bytes(), eicarSignature) {
+			conn.Write([]byte("stream: Win.Test.EICAR_HDB-1 FOUND\x00"))
+			return
+		}
+		conn.Write([]byte("stream: OK\x00"))
+	}()
+
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestClamAVScanner_Clean(t *testing.T) {
+	s := NewClamAVScanner(fakeClamd(t), time.Second)
+
+	result, err := s.Scan(context.Background(), strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.True(t, result.Clean)
+}
+
+func TestClamAVScanner_Infected(t *testing.T) {
+	s := NewClamAVScanner(fakeClamd(t), time.Second)
+
+	result, err := s.Scan(context.Background(), bytes.NewReader(eicarSignature))
+	require.NoError(t, err)
+	assert.False(t, result.Clean)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", result.Signature)
+}
+
+func TestClamAVScanner_Unavailable(t *testing.T) {
+	s := NewClamAVScanner("tcp://127.0.0.1:1", 200*time.Millisecond)
+
+	_, err := s.Scan(context.Background(), strings.NewReader("hello"))
+	assert.Error(t, err)
+}
+
+func TestParseClamdReply(t *testing.T) {
+	_, err := parseClamdReply("INSTREAM size limit exceeded. ERROR")
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// JobFunc is the work of a periodic job
+type JobFunc func(ctx context.Context) error
+
+// ErrorHandler is called when a job run fails
+type ErrorHandler func(job string, err error)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs background jobs at fixed intervals until it is stopped
+type Scheduler struct {
+	jobs    []job
+	onError ErrorHandler
+	timeout time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler. Each run of a job is limited to the timeout.
+func New(timeout time.Duration, onError ErrorHandler) *Scheduler {
+	if onError == nil {
+		onError = func(string, error) {}
+	}
+	return &Scheduler{
+		onError: onError,
+		timeout: timeout,
+	}
+}
+
+// Every registers a job that runs once at start and then at every interval
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start launches the registered jobs
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				s.runOnce(ctx, j)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(j)
+	}
+}
+
+// Stop cancels the running jobs and waits for them to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, j job) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	runCtx := ctx
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	if err := j.run(runCtx); err != nil && ctx.Err() == nil {
+		s.onError(j.name, err)
+	}
+}
@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	var runs int32
+	var failures int32
+
+	s := New(time.Second, func(job string, err error) {
+		assert.Equal(t, "failing", job)
+		atomic.AddInt32(&failures, 1)
+	})
+	s.Every("counter", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	s.Every("failing", time.Hour, func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, 5*time.Millisecond)
+	s.Stop()
+
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&failures))
+}
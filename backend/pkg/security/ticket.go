@@ -0,0 +1,54 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ticketPrefix marks the version of the ticket code format
+const ticketPrefix = "T1"
+
+// TicketSigner signs event ticket codes, so a scanned code can be trusted without
+// storing it
+type TicketSigner struct {
+	key []byte
+}
+
+// NewTicketSigner creates a new ticket signer
+func NewTicketSigner(key string) *TicketSigner {
+	return &TicketSigner{key: []byte(key)}
+}
+
+// Sign returns the ticket code of a registration: "T1.<registration ID>.<signature>"
+func (s *TicketSigner) Sign(registrationID primitive.ObjectID) string {
+	return ticketPrefix + "." + registrationID.Hex() + "." + s.signature(registrationID.Hex())
+}
+
+// Verify returns the registration of a ticket code with a valid signature
+func (s *TicketSigner) Verify(code string) (primitive.ObjectID, error) {
+	parts := strings.Split(strings.TrimSpace(code), ".")
+	if len(parts) != 3 || parts[0] != ticketPrefix {
+		return primitive.NilObjectID, errors.NewBadRequest("invalid ticket code")
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(parts[1]))) {
+		return primitive.NilObjectID, errors.NewBadRequest("invalid ticket code")
+	}
+
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return primitive.NilObjectID, errors.NewBadRequest("invalid ticket code")
+	}
+	return id, nil
+}
+
+// signature returns the truncated HMAC-SHA256 of a registration ID
+func (s *TicketSigner) signature(id string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte("ticket:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
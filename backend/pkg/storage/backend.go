@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// ErrPresignNotSupported is returned by backends that cannot issue pre-signed URLs
+var ErrPresignNotSupported = errors.NewBadRequest("storage backend does not support pre-signed URLs")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Backend is implemented by the storage providers (local disk, S3-compatible buckets)
+type Backend interface {
+	// Name returns the backend identifier ("local", "s3")
+	Name() string
+
+	// Put stores the content under the key, replacing existing content
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the object for reading; the reader supports seeking for range requests
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error)
+
+	// Delete removes the object
+	Delete(ctx context.Context, key string) error
+
+	// DeletePrefix removes every object whose key starts with the prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+
+	// PresignGet returns a time-limited download URL for the object
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
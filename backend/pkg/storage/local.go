@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// LocalBackend stores files on the local disk
+type LocalBackend struct {
+	basePath string
+}
+
+// NewLocalBackend creates a new local disk backend
+func NewLocalBackend(basePath string) *LocalBackend {
+	return &LocalBackend{basePath: basePath}
+}
+
+// Name returns the backend identifier
+func (b *LocalBackend) Name() string {
+	return "local"
+}
+
+// Put writes the content to disk
+func (b *LocalBackend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, 500, "failed to create upload directory")
+	}
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to create destination file")
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, r); err != nil {
+		return errors.Wrap(err, 500, "failed to save file")
+	}
+
+	return nil
+}
+
+// Get opens a file from disk
+func (b *LocalBackend) Get(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errors.ErrNotFound
+		}
+		return nil, nil, errors.Wrap(err, 500, "failed to open file")
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, errors.Wrap(err, 500, "failed to read file info")
+	}
+
+	info := &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(path)),
+		LastModified: stat.ModTime(),
+	}
+
+	return file, info, nil
+}
+
+// Delete removes a file from disk
+func (b *LocalBackend) Delete(ctx context.Context, key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return errors.ErrNotFound
+	}
+
+	if err := os.Remove(path); err != nil {
+		return errors.Wrap(err, 500, "failed to delete file")
+	}
+
+	return nil
+}
+
+// DeletePrefix removes a folder and everything in it
+func (b *LocalBackend) DeletePrefix(ctx context.Context, prefix string) error {
+	path, err := b.path(prefix)
+	if err != nil {
+		return err
+	}
+
+	if path == filepath.Clean(b.basePath) {
+		return errors.NewBadRequest("refusing to delete storage root")
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return errors.Wrap(err, 500, "failed to delete folder")
+	}
+
+	return nil
+}
+
+// PresignGet is not supported for local storage; files are served by the web server
+func (b *LocalBackend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
+
+// Walk calls fn for every stored file with its key
+func (b *LocalBackend) Walk(fn func(key string, info os.FileInfo) error) error {
+	return filepath.Walk(b.basePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.basePath, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), info)
+	})
+}
+
+// path resolves a key to a path inside the base directory
+func (b *LocalBackend) path(key string) (string, error) {
+	base := filepath.Clean(b.basePath)
+	path := filepath.Clean(filepath.Join(base, filepath.FromSlash(key)))
+	if path != base && !strings.HasPrefix(path, base+string(filepath.Separator)) {
+		return "", errors.NewBadRequest("invalid file path")
+	}
+	return path, nil
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/sainaif/animalsys/backend/pkg/errors"
+)
+
+// PublicFolders hold the files whose URLs are handed out as is: animal photos, calendar
+// invites and ticket QR codes. Documents stay private and are downloaded through pre-signed URLs.
+var PublicFolders = []string{"animals/", "events/", "tickets/"}
+
+// S3Options holds the connection settings for an S3-compatible bucket
+type S3Options struct {
+	Endpoint  string // e.g. "https://s3.eu-central-1.amazonaws.com" or "http://minio:9000"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	// Folders readable without credentials, because their URLs are handed out as is.
+	// The read policy is only set on a bucket the backend creates; see the README
+	// for existing buckets.
+	PublicPrefixes []string
+}
+
+// S3Backend stores files in an S3-compatible bucket (AWS S3, MinIO)
+type S3Backend struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Backend connects to the bucket, creating it when it does not exist
+func NewS3Backend(ctx context.Context, opts S3Options) (*S3Backend, error) {
+	if opts.Bucket == "" {
+		return nil, errors.NewBadRequest("S3 bucket is required")
+	}
+
+	endpoint, secure, err := parseEndpoint(opts.Endpoint, opts.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: secure,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to create S3 client")
+	}
+
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, errors.Wrap(err, 500, "failed to check S3 bucket")
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, opts.Bucket, minio.MakeBucketOptions{Region: opts.Region}); err != nil {
+			return nil, errors.Wrap(err, 500, "failed to create S3 bucket")
+		}
+		if len(opts.PublicPrefixes) > 0 {
+			policy, err := publicReadPolicy(opts.Bucket, opts.PublicPrefixes)
+			if err != nil {
+				return nil, err
+			}
+			if err := client.SetBucketPolicy(ctx, opts.Bucket, policy); err != nil {
+				return nil, errors.Wrap(err, 500, "failed to set S3 bucket policy")
+			}
+		}
+	}
+
+	return &S3Backend{client: client, bucket: opts.Bucket}, nil
+}
+
+// Name returns the backend identifier
+func (b *S3Backend) Name() string {
+	return "s3"
+}
+
+// Put uploads the content to the bucket
+func (b *S3Backend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := b.client.PutObject(ctx, b.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return errors.Wrap(err, 500, "failed to upload file")
+	}
+	return nil
+}
+
+// Get opens an object from the bucket
+func (b *S3Backend) Get(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	object, err := b.client.GetObject(ctx, b.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, 500, "failed to open file")
+	}
+
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, nil, errors.ErrNotFound
+		}
+		return nil, nil, errors.Wrap(err, 500, "failed to read file info")
+	}
+
+	info := &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size,
+		ContentType:  stat.ContentType,
+		LastModified: stat.LastModified,
+	}
+
+	return object, info, nil
+}
+
+// Delete removes an object from the bucket
+func (b *S3Backend) Delete(ctx context.Context, key string) error {
+	if err := b.client.RemoveObject(ctx, b.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return errors.Wrap(err, 500, "failed to delete file")
+	}
+	return nil
+}
+
+// DeletePrefix removes every object under the prefix
+func (b *S3Backend) DeletePrefix(ctx context.Context, prefix string) error {
+	if strings.Trim(prefix, "/") == "" {
+		return errors.NewBadRequest("refusing to delete storage root")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// RemoveObjects skips listing errors, so the listing is passed through and its error kept
+	listed := b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{
+		Prefix:    strings.TrimSuffix(prefix, "/") + "/",
+		Recursive: true,
+	})
+	objects := make(chan minio.ObjectInfo)
+	var listErr error
+	go func() {
+		defer close(objects)
+		for object := range listed {
+			if object.Err != nil {
+				listErr = object.Err
+				return
+			}
+			select {
+			case objects <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for result := range b.client.RemoveObjects(ctx, b.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return errors.Wrap(result.Err, 500, "failed to delete folder")
+		}
+	}
+	if listErr != nil {
+		return errors.Wrap(listErr, 500, "failed to list folder")
+	}
+
+	return nil
+}
+
+// PresignGet returns a time-limited download URL for the object
+func (b *S3Backend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	presigned, err := b.client.PresignedGetObject(ctx, b.bucket, key, expiry, url.Values{})
+	if err != nil {
+		return "", errors.Wrap(err, 500, "failed to create download URL")
+	}
+	return presigned.String(), nil
+}
+
+// publicReadPolicy returns a bucket policy letting anyone download the objects under the prefixes
+func publicReadPolicy(bucket string, prefixes []string) (string, error) {
+	resources := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		resources = append(resources, "arn:aws:s3:::"+bucket+"/"+strings.Trim(prefix, "/")+"/*")
+	}
+
+	policy, err := json.Marshal(map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{{
+			"Effect":    "Allow",
+			"Principal": map[string][]string{"AWS": {"*"}},
+			"Action":    []string{"s3:GetObject"},
+			"Resource":  resources,
+		}},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, 500, "failed to build S3 bucket policy")
+	}
+	return string(policy), nil
+}
+
+// parseEndpoint splits an endpoint URL into the host and TLS flag expected by the client
+func parseEndpoint(endpoint, region string) (string, bool, error) {
+	if endpoint == "" {
+		if region == "" {
+			return "s3.amazonaws.com", true, nil
+		}
+		return "s3." + region + ".amazonaws.com", true, nil
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, true, nil
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return "", false, errors.NewBadRequest("invalid S3 endpoint")
+	}
+
+	return parsed.Host, parsed.Scheme == "https", nil
+}
@@ -1,11 +1,13 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
+	"net/http"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -16,20 +18,25 @@ import (
 
 // StorageService handles file uploads and management
 type StorageService struct {
-	basePath   string
-	baseURL    string
+	backend     Backend
+	baseURL     string
 	maxFileSize int64 // in bytes
 }
 
-// NewStorageService creates a new storage service
-func NewStorageService(basePath, baseURL string, maxFileSize int64) *StorageService {
+// NewStorageService creates a new storage service on top of a storage backend
+func NewStorageService(backend Backend, baseURL string, maxFileSize int64) *StorageService {
 	return &StorageService{
-		basePath:    basePath,
-		baseURL:     baseURL,
+		backend:     backend,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
 		maxFileSize: maxFileSize,
 	}
 }
 
+// Backend returns the underlying storage backend
+func (s *StorageService) Backend() Backend {
+	return s.backend
+}
+
 // UploadImage uploads an image file and returns the URL
 func (s *StorageService) UploadImage(ctx context.Context, file *multipart.FileHeader, folder string) (string, error) {
 	// Validate file size
@@ -49,32 +56,81 @@ func (s *StorageService) UploadImage(ctx context.Context, file *multipart.FileHe
 	}
 	defer src.Close()
 
+	// Validate the actual content, not just the extension
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(src, head)
+	if !s.isValidImageContent(head[:n]) {
+		return "", errors.NewBadRequest("file content is not a supported image")
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", errors.Wrap(err, 500, "failed to read uploaded file")
+	}
+
 	// Generate unique filename
 	filename := s.generateFilename(file.Filename)
 
-	// Create folder path
-	folderPath := filepath.Join(s.basePath, folder)
-	if err := os.MkdirAll(folderPath, 0755); err != nil {
-		return "", errors.Wrap(err, 500, "failed to create upload directory")
+	return s.Upload(ctx, src, file.Size, folder, filename, http.DetectContentType(head[:n]))
+}
+
+// Upload streams content to the backend under the folder and returns the URL
+func (s *StorageService) Upload(ctx context.Context, r io.Reader, size int64, folder, filename, contentType string) (string, error) {
+	key := path.Join(folder, filepath.Base(filename))
+	if err := s.backend.Put(ctx, key, r, size, contentType); err != nil {
+		return "", err
+	}
+
+	return s.URLForKey(key), nil
+}
+
+// ExceedsMaxFileSize reports whether a file of the given size is above the configured limit
+func (s *StorageService) ExceedsMaxFileSize(size int64) bool {
+	return size > s.maxFileSize
+}
+
+// SaveFile writes generated file contents under the folder and returns the URL
+func (s *StorageService) SaveFile(ctx context.Context, data []byte, folder, filename string) (string, error) {
+	return s.Upload(ctx, bytes.NewReader(data), int64(len(data)), folder, filename, http.DetectContentType(data))
+}
+
+// Open opens a stored file by URL for streaming
+func (s *StorageService) Open(ctx context.Context, url string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	key, err := s.KeyFromURL(url)
+	if err != nil {
+		return nil, nil, err
 	}
+	return s.backend.Get(ctx, key)
+}
 
-	// Create destination file
-	destPath := filepath.Join(folderPath, filename)
-	dst, err := os.Create(destPath)
+// PresignedURL returns a time-limited download URL for a stored file.
+// Backends without pre-signing (local disk) return the regular URL.
+func (s *StorageService) PresignedURL(ctx context.Context, url string, expiry time.Duration) (string, error) {
+	key, err := s.KeyFromURL(url)
 	if err != nil {
-		return "", errors.Wrap(err, 500, "failed to create destination file")
+		return "", err
 	}
-	defer dst.Close()
 
-	// Copy file
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", errors.Wrap(err, 500, "failed to save file")
+	presigned, err := s.backend.PresignGet(ctx, key, expiry)
+	if err == ErrPresignNotSupported {
+		return url, nil
+	}
+	if err != nil {
+		return "", err
 	}
 
-	// Generate URL
-	url := fmt.Sprintf("%s/%s/%s", s.baseURL, folder, filename)
+	return presigned, nil
+}
 
-	return url, nil
+// URLForKey builds the public URL of a stored object
+func (s *StorageService) URLForKey(key string) string {
+	return fmt.Sprintf("%s/%s", s.baseURL, strings.TrimPrefix(key, "/"))
+}
+
+// KeyFromURL extracts the object key from a URL produced by this service
+func (s *StorageService) KeyFromURL(url string) (string, error) {
+	if !strings.HasPrefix(url, s.baseURL+"/") {
+		return "", errors.NewBadRequest("file URL does not belong to this storage")
+	}
+	return strings.TrimPrefix(url, s.baseURL+"/"), nil
 }
 
 // UploadMultipleImages uploads multiple images
@@ -98,21 +154,13 @@ func (s *StorageService) UploadMultipleImages(ctx context.Context, files []*mult
 
 // DeleteFile deletes a file by URL
 func (s *StorageService) DeleteFile(ctx context.Context, url string) error {
-	// Extract file path from URL
-	relativePath := strings.TrimPrefix(url, s.baseURL+"/")
-	filePath := filepath.Join(s.basePath, relativePath)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	// Extract object key from URL
+	key, err := s.KeyFromURL(url)
+	if err != nil {
 		return errors.ErrNotFound
 	}
 
-	// Delete file
-	if err := os.Remove(filePath); err != nil {
-		return errors.Wrap(err, 500, "failed to delete file")
-	}
-
-	return nil
+	return s.backend.Delete(ctx, key)
 }
 
 // DeleteMultipleFiles deletes multiple files
@@ -123,6 +171,24 @@ func (s *StorageService) DeleteMultipleFiles(ctx context.Context, urls []string)
 	return nil
 }
 
+// DeleteFolder removes a folder and every file in it, including files no record points to anymore
+func (s *StorageService) DeleteFolder(ctx context.Context, folder string) error {
+	if strings.Trim(folder, "/.") == "" {
+		return errors.NewBadRequest("refusing to delete storage root")
+	}
+
+	return s.backend.DeletePrefix(ctx, folder)
+}
+
+// isValidImageContent checks the sniffed content type of the file header
+func (s *StorageService) isValidImageContent(head []byte) bool {
+	switch http.DetectContentType(head) {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
 // isValidImageType checks if the file extension is valid for images
 func (s *StorageService) isValidImageType(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
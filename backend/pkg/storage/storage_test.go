@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageService_LocalBackend(t *testing.T) {
+	ctx := context.Background()
+	service := NewStorageService(NewLocalBackend(t.TempDir()), "/uploads/", 1<<20)
+
+	url, err := service.SaveFile(ctx, []byte("%PDF-1.4 test"), "documents/abc", "contract.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/documents/abc/contract.pdf", url)
+
+	file, info, err := service.Open(ctx, url)
+	require.NoError(t, err)
+	data, err := io.ReadAll(file)
+	file.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4 test", string(data))
+	assert.Equal(t, int64(len(data)), info.Size)
+
+	// Local storage has no pre-signing, the regular URL is returned
+	presigned, err := service.PresignedURL(ctx, url, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, url, presigned)
+
+	require.NoError(t, service.DeleteFolder(ctx, "documents/abc"))
+	_, _, err = service.Open(ctx, url)
+	assert.Error(t, err)
+}
+
+func TestStorageService_RejectsForeignURLs(t *testing.T) {
+	service := NewStorageService(NewLocalBackend(t.TempDir()), "/uploads", 1<<20)
+
+	_, err := service.KeyFromURL("https://example.com/file.pdf")
+	assert.Error(t, err)
+
+	_, _, err = service.Open(context.Background(), "/uploads/../../etc/passwd")
+	assert.Error(t, err)
+
+	assert.Error(t, service.DeleteFolder(context.Background(), "/"))
+}
+
+func TestParseEndpoint(t *testing.T) {
+	host, secure, err := parseEndpoint("http://minio:9000", "")
+	require.NoError(t, err)
+	assert.Equal(t, "minio:9000", host)
+	assert.False(t, secure)
+
+	host, secure, err = parseEndpoint("", "eu-central-1")
+	require.NoError(t, err)
+	assert.Equal(t, "s3.eu-central-1.amazonaws.com", host)
+	assert.True(t, secure)
+}
+
+func TestPublicReadPolicy(t *testing.T) {
+	policy, err := publicReadPolicy("animalsys", []string{"animals/", "/tickets"})
+	require.NoError(t, err)
+
+	assert.Contains(t, policy, `"Action":["s3:GetObject"]`)
+	assert.Contains(t, policy, `"Resource":["arn:aws:s3:::animalsys/animals/*","arn:aws:s3:::animalsys/tickets/*"]`)
+	assert.NotContains(t, policy, "documents")
+}